@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	v2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// V1SpecAnnotation keeps spec of v1 SriovFecClusterConfig which configures other than exactly one physical function. Such
+// config is converted to a dry run of its first physical function (or of nothing if it has none), so the controller doesn't
+// deconfigure the other physical functions before the storage version migration splits it into v2 configs of each of them.
+const V1SpecAnnotation = "sriovfec.intel.com/v1-spec"
+
+// hostnameLabel selects the node v1 config of a physical function configures
+const hostnameLabel = "kubernetes.io/hostname"
+
+var _ conversion.Convertible = &SriovFecClusterConfig{}
+var _ conversion.Convertible = &SriovFecNodeConfig{}
+
+// ConvertTo converts the config to v2 SriovFecClusterConfig selecting the node and accelerator of its first physical function
+func (in *SriovFecClusterConfig) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v2.SriovFecClusterConfig)
+	dst.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	dst.Status = v2.SriovFecClusterConfigStatus{SyncStatus: v2.SyncStatus(in.Status.SyncStatus), LastSyncError: in.Status.LastSyncError}
+
+	var pfs []v2.SriovFecClusterConfigSpec
+	for _, node := range in.Spec.Nodes {
+		for _, pf := range node.PhysicalFunctions {
+			spec, err := clusterConfigSpecFor(node.NodeName, pf, in.Spec.DrainSkip)
+			if err != nil {
+				return err
+			}
+			pfs = append(pfs, spec)
+		}
+	}
+
+	if len(pfs) != 1 {
+		spec, err := json.Marshal(in.Spec)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[V1SpecAnnotation] = string(spec)
+	}
+	if len(pfs) == 0 {
+		// nothing is configured by the config
+		drainSkip := in.Spec.DrainSkip
+		dst.Spec = v2.SriovFecClusterConfigSpec{DrainSkip: &drainSkip, DryRun: true}
+		return nil
+	}
+	dst.Spec = pfs[0]
+	if len(pfs) > 1 {
+		// other physical functions stay configured until the migration splits the config
+		dst.Spec.DryRun = true
+	}
+	return nil
+}
+
+// ConvertFrom converts v2 SriovFecClusterConfig to the config of the node and accelerator it selects
+func (in *SriovFecClusterConfig) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v2.SriovFecClusterConfig)
+	in.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	in.Status = SriovFecClusterConfigStatus{SyncStatus: SyncStatus(src.Status.SyncStatus), LastSyncError: src.Status.LastSyncError}
+
+	if spec, ok := in.Annotations[V1SpecAnnotation]; ok {
+		delete(in.Annotations, V1SpecAnnotation)
+		in.Spec = SriovFecClusterConfigSpec{}
+		if err := json.Unmarshal([]byte(spec), &in.Spec); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", V1SpecAnnotation, err)
+		}
+		return nil
+	}
+
+	pf := PhysicalFunctionConfig{
+		PCIAddress: src.Spec.AcceleratorSelector.PCIAddress,
+		PFDriver:   src.Spec.PhysicalFunction.PFDriver,
+		VFDriver:   src.Spec.PhysicalFunction.VFDriver,
+		VFAmount:   src.Spec.PhysicalFunction.VFAmount,
+	}
+	if err := convertJSON(src.Spec.PhysicalFunction.BBDevConfig, &pf.BBDevConfig); err != nil {
+		return err
+	}
+	in.Spec = SriovFecClusterConfigSpec{
+		Nodes:     []NodeConfig{{NodeName: src.Spec.NodeSelector[hostnameLabel], PhysicalFunctions: []PhysicalFunctionConfig{pf}}},
+		DrainSkip: src.Spec.DrainSkip != nil && *src.Spec.DrainSkip,
+	}
+	return nil
+}
+
+// ConvertTo converts the config to v2 SriovFecNodeConfig
+func (in *SriovFecNodeConfig) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v2.SriovFecNodeConfig)
+	dst.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	dst.Spec = v2.SriovFecNodeConfigSpec{DrainSkip: in.Spec.DrainSkip, PhysicalFunctions: []v2.PhysicalFunctionConfigExt{}}
+	for _, pf := range in.Spec.PhysicalFunctions {
+		converted := v2.PhysicalFunctionConfigExt{PCIAddress: pf.PCIAddress, PFDriver: pf.PFDriver, VFDriver: pf.VFDriver, VFAmount: pf.VFAmount}
+		if err := convertJSON(pf.BBDevConfig, &converted.BBDevConfig); err != nil {
+			return err
+		}
+		dst.Spec.PhysicalFunctions = append(dst.Spec.PhysicalFunctions, converted)
+	}
+	dst.Status = v2.SriovFecNodeConfigStatus{Conditions: in.Status.Conditions}
+	return convertJSON(in.Status.Inventory, &dst.Status.Inventory)
+}
+
+// ConvertFrom converts v2 SriovFecNodeConfig to the config; configuration v1 doesn't know, e.g. of ACC200, is dropped
+func (in *SriovFecNodeConfig) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v2.SriovFecNodeConfig)
+	in.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	in.Spec = SriovFecNodeConfigSpec{DrainSkip: src.Spec.DrainSkip, PhysicalFunctions: []PhysicalFunctionConfig{}}
+	for _, pf := range src.Spec.PhysicalFunctions {
+		converted := PhysicalFunctionConfig{PCIAddress: pf.PCIAddress, PFDriver: pf.PFDriver, VFDriver: pf.VFDriver, VFAmount: pf.VFAmount}
+		if err := convertJSON(pf.BBDevConfig, &converted.BBDevConfig); err != nil {
+			return err
+		}
+		in.Spec.PhysicalFunctions = append(in.Spec.PhysicalFunctions, converted)
+	}
+	in.Status = SriovFecNodeConfigStatus{Conditions: src.Status.Conditions}
+	return convertJSON(src.Status.Inventory, &in.Status.Inventory)
+}
+
+// SplitClusterConfig returns v2 configs of each physical function of v1 config kept in V1SpecAnnotation of the converted
+// config; the first one keeps the name of the config, the rest are named <name>-<index>. Nil is returned for configs
+// without the annotation.
+func SplitClusterConfig(cc *v2.SriovFecClusterConfig) ([]v2.SriovFecClusterConfig, error) {
+	spec, ok := cc.Annotations[V1SpecAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	v1Spec := SriovFecClusterConfigSpec{}
+	if err := json.Unmarshal([]byte(spec), &v1Spec); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation of %s: %w", V1SpecAnnotation, cc.Name, err)
+	}
+
+	var configs []v2.SriovFecClusterConfig
+	for _, node := range v1Spec.Nodes {
+		for _, pf := range node.PhysicalFunctions {
+			spec, err := clusterConfigSpecFor(node.NodeName, pf, v1Spec.DrainSkip)
+			if err != nil {
+				return nil, err
+			}
+			config := v2.SriovFecClusterConfig{ObjectMeta: *cc.ObjectMeta.DeepCopy(), Spec: spec}
+			delete(config.Annotations, V1SpecAnnotation)
+			if len(configs) != 0 {
+				config.ObjectMeta = metaOfSplitConfig(cc, len(configs))
+			}
+			configs = append(configs, config)
+		}
+	}
+	if len(configs) == 0 {
+		// config without physical functions stays a dry run
+		config := *cc.DeepCopy()
+		delete(config.Annotations, V1SpecAnnotation)
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+func metaOfSplitConfig(cc *v2.SriovFecClusterConfig, index int) metav1.ObjectMeta {
+	meta := metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", cc.Name, index), Namespace: cc.Namespace, Labels: cc.Labels}
+	for k, v := range cc.Annotations {
+		if k == V1SpecAnnotation {
+			continue
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		meta.Annotations[k] = v
+	}
+	return meta
+}
+
+func clusterConfigSpecFor(nodeName string, pf PhysicalFunctionConfig, drainSkip bool) (v2.SriovFecClusterConfigSpec, error) {
+	spec := v2.SriovFecClusterConfigSpec{
+		NodeSelector:        map[string]string{hostnameLabel: nodeName},
+		AcceleratorSelector: v2.AcceleratorSelector{PCIAddress: pf.PCIAddress},
+		PhysicalFunction: v2.PhysicalFunctionConfig{
+			PFDriver: pf.PFDriver,
+			VFDriver: pf.VFDriver,
+			VFAmount: pf.VFAmount,
+		},
+		DrainSkip: &drainSkip,
+	}
+	return spec, convertJSON(pf.BBDevConfig, &spec.PhysicalFunction.BBDevConfig)
+}
+
+// convertJSON converts between versions of types which JSON representation is compatible, fields unknown to dst are dropped
+func convertJSON(src, dst interface{}) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
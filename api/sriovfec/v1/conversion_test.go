@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v1
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("Conversion", func() {
+	acc100 := func() *ACC100BBDevConfig {
+		queues := QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4}
+		return &ACC100BBDevConfig{NumVfBundles: 16, MaxQueueSize: 1024, Uplink5G: queues, Downlink5G: queues,
+			Uplink4G: QueueGroupConfig{NumAqsPerGroups: 16, AqDepthLog2: 4}, Downlink4G: QueueGroupConfig{NumAqsPerGroups: 16, AqDepthLog2: 4}}
+	}
+	pf := func(pciAddress string) PhysicalFunctionConfig {
+		return PhysicalFunctionConfig{PCIAddress: pciAddress, PFDriver: "pci-pf-stub", VFDriver: "vfio-pci", VFAmount: 16,
+			BBDevConfig: BBDevConfig{ACC100: acc100()}}
+	}
+
+	It("converts config of a single physical function to selectors of its node and accelerator", func() {
+		cc := &SriovFecClusterConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "vran-acceleration-operators"},
+			Spec: SriovFecClusterConfigSpec{DrainSkip: true,
+				Nodes: []NodeConfig{{NodeName: "node1", PhysicalFunctions: []PhysicalFunctionConfig{pf("0000:14:00.0")}}}},
+			Status: SriovFecClusterConfigStatus{SyncStatus: SucceededSync},
+		}
+
+		converted := new(v2.SriovFecClusterConfig)
+		Expect(cc.ConvertTo(converted)).To(Succeed())
+		Expect(converted.Annotations).ToNot(HaveKey(V1SpecAnnotation))
+		Expect(converted.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/hostname": "node1"}))
+		Expect(converted.Spec.AcceleratorSelector.PCIAddress).To(Equal("0000:14:00.0"))
+		Expect(converted.Spec.PhysicalFunction.VFAmount).To(Equal(16))
+		Expect(converted.Spec.PhysicalFunction.BBDevConfig.ACC100.Uplink5G.NumQueueGroups).To(Equal(4))
+		Expect(*converted.Spec.DrainSkip).To(BeTrue())
+		Expect(converted.Status.SyncStatus).To(Equal(v2.SucceededSync))
+
+		restored := new(SriovFecClusterConfig)
+		Expect(restored.ConvertFrom(converted)).To(Succeed())
+		Expect(restored).To(Equal(cc))
+	})
+
+	It("keeps spec of several physical functions for the migration to split it", func() {
+		cc := &SriovFecClusterConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "vran-acceleration-operators", Labels: map[string]string{"a": "b"}},
+			Spec: SriovFecClusterConfigSpec{Nodes: []NodeConfig{
+				{NodeName: "node1", PhysicalFunctions: []PhysicalFunctionConfig{pf("0000:14:00.0"), pf("0000:15:00.0")}},
+				{NodeName: "node2", PhysicalFunctions: []PhysicalFunctionConfig{pf("0000:14:00.0")}},
+			}},
+		}
+
+		converted := new(v2.SriovFecClusterConfig)
+		Expect(cc.ConvertTo(converted)).To(Succeed())
+		Expect(converted.Annotations).To(HaveKey(V1SpecAnnotation))
+		Expect(converted.Spec.AcceleratorSelector.PCIAddress).To(Equal("0000:14:00.0"))
+		Expect(converted.Spec.DryRun).To(BeTrue())
+
+		restored := new(SriovFecClusterConfig)
+		Expect(restored.ConvertFrom(converted)).To(Succeed())
+		Expect(restored.Spec).To(Equal(cc.Spec))
+		Expect(restored.Annotations).ToNot(HaveKey(V1SpecAnnotation))
+
+		configs, err := SplitClusterConfig(converted)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configs).To(HaveLen(3))
+		Expect(configs[0].Name).To(Equal("config"))
+		Expect(configs[0].Annotations).ToNot(HaveKey(V1SpecAnnotation))
+		Expect(configs[0].Spec.DryRun).To(BeFalse())
+		Expect(configs[1].Name).To(Equal("config-1"))
+		Expect(configs[1].Labels).To(Equal(map[string]string{"a": "b"}))
+		Expect(configs[1].Spec.AcceleratorSelector.PCIAddress).To(Equal("0000:15:00.0"))
+		Expect(configs[2].Name).To(Equal("config-2"))
+		Expect(configs[2].Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/hostname": "node2"}))
+	})
+
+	It("converts config without physical functions to a dry run", func() {
+		converted := new(v2.SriovFecClusterConfig)
+		Expect((&SriovFecClusterConfig{}).ConvertTo(converted)).To(Succeed())
+		Expect(converted.Spec.DryRun).To(BeTrue())
+		Expect(converted.Annotations).To(HaveKey(V1SpecAnnotation))
+	})
+
+	It("converts NodeConfig and its inventory", func() {
+		nc := &SriovFecNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Spec:       SriovFecNodeConfigSpec{PhysicalFunctions: []PhysicalFunctionConfig{pf("0000:14:00.0")}},
+			Status: SriovFecNodeConfigStatus{Inventory: NodeInventory{SriovAccelerators: []SriovAccelerator{
+				{PCIAddress: "0000:14:00.0", DeviceID: "0d5c", Driver: "pci-pf-stub", MaxVFs: 16,
+					VFs: []VF{{PCIAddress: "0000:15:00.0", Driver: "vfio-pci", DeviceID: "0d5d"}}},
+			}}},
+		}
+
+		converted := new(v2.SriovFecNodeConfig)
+		Expect(nc.ConvertTo(converted)).To(Succeed())
+		Expect(converted.Spec.PhysicalFunctions).To(HaveLen(1))
+		Expect(converted.Spec.PhysicalFunctions[0].BBDevConfig.ACC100).ToNot(BeNil())
+		Expect(converted.Status.Inventory.SriovAccelerators[0].PFDriver).To(Equal("pci-pf-stub"))
+		Expect(converted.Status.Inventory.SriovAccelerators[0].VFs).To(HaveLen(1))
+
+		restored := new(SriovFecNodeConfig)
+		Expect(restored.ConvertFrom(converted)).To(Succeed())
+		Expect(restored).To(Equal(nc))
+	})
+})
@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+// Hub marks SriovFecClusterConfig v2 as the version other versions are converted to by the conversion webhook
+func (*SriovFecClusterConfig) Hub() {}
+
+// Hub marks SriovFecNodeConfig v2 as the version other versions are converted to by the conversion webhook
+func (*SriovFecNodeConfig) Hub() {}
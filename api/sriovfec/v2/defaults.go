@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+// RecommendedACC100BBDevConfig returns the vendor recommended queue configuration for ACC100 (5G only, 16 VF bundles)
+func RecommendedACC100BBDevConfig() *ACC100BBDevConfig {
+	return &ACC100BBDevConfig{
+		NumVfBundles: 16,
+		MaxQueueSize: 1024,
+		Uplink4G:     QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		Downlink4G:   QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		Uplink5G:     QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		Downlink5G:   QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+	}
+}
+
+// RecommendedACC200BBDevConfig returns the vendor recommended queue configuration for ACC200 (4G/5G/FFT, 16 VF bundles)
+func RecommendedACC200BBDevConfig() *ACC200BBDevConfig {
+	return &ACC200BBDevConfig{
+		ACC100BBDevConfig: ACC100BBDevConfig{
+			NumVfBundles: 16,
+			MaxQueueSize: 1024,
+			Uplink4G:     QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+			Downlink4G:   QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+			Uplink5G:     QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+			Downlink5G:   QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		},
+		QFFT: QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+	}
+}
+
+// RecommendedN3000BBDevConfig returns the vendor recommended queue configuration for N3000 (2 VFs, 16 queues each)
+func RecommendedN3000BBDevConfig(networkType string) *N3000BBDevConfig {
+	link := UplinkDownlink{
+		Bandwidth:   3,
+		LoadBalance: 128,
+		Queues:      UplinkDownlinkQueues{VF0: 16, VF1: 16},
+	}
+	return &N3000BBDevConfig{
+		NetworkType: networkType,
+		FLRTimeOut:  610,
+		Downlink:    link,
+		Uplink:      link,
+	}
+}
+
+// defaultedDevices are accelerators which omitted bbDevConfig is defaulted for, keyed by device ID
+var defaultedDevices = map[string]string{
+	"0d8f": "FPGA_5GNR",
+	"5052": "FPGA_LTE",
+	"0d5c": "ACC100",
+	"57c0": "ACC200",
+}
+
+// Default fills omitted fields of the card's configuration with vendor recommended values; empty bbDevConfig is
+// defaulted when the accelerator selector selects a particular device. bbDevConfig with profile or profileRef is
+// expanded by the operator, so it's not defaulted.
+func (in *BBDevConfig) Default(deviceID string, vfAmount int) {
+	if in.Profile != "" || in.ProfileRef != "" {
+		return
+	}
+
+	if in.N3000 == nil && in.ACC100 == nil && in.ACC200 == nil {
+		switch device := defaultedDevices[deviceID]; device {
+		case "FPGA_5GNR", "FPGA_LTE":
+			in.N3000 = &N3000BBDevConfig{NetworkType: device}
+		case "ACC100":
+			in.ACC100 = &ACC100BBDevConfig{}
+		case "ACC200":
+			in.ACC200 = &ACC200BBDevConfig{}
+		}
+	}
+
+	if in.N3000 != nil {
+		defaultN3000BBDevConfig(in.N3000)
+	}
+	if in.ACC100 != nil {
+		defaultACC100BBDevConfig(in.ACC100, RecommendedACC100BBDevConfig(), vfAmount)
+	}
+	if in.ACC200 != nil {
+		recommended := RecommendedACC200BBDevConfig()
+		defaultACC100BBDevConfig(&in.ACC200.ACC100BBDevConfig, &recommended.ACC100BBDevConfig, vfAmount)
+		defaultQueueGroupConfig(&in.ACC200.QFFT, recommended.QFFT)
+	}
+}
+
+// defaultN3000BBDevConfig fills omitted links; flrTimeout is filled only along with both links, as zero is its valid value
+func defaultN3000BBDevConfig(config *N3000BBDevConfig) {
+	if config.NetworkType == "" {
+		return
+	}
+	recommended := RecommendedN3000BBDevConfig(config.NetworkType)
+	if config.Uplink == (UplinkDownlink{}) && config.Downlink == (UplinkDownlink{}) && config.FLRTimeOut == 0 {
+		config.FLRTimeOut = recommended.FLRTimeOut
+	}
+	if config.Uplink == (UplinkDownlink{}) {
+		config.Uplink = recommended.Uplink
+	}
+	if config.Downlink == (UplinkDownlink{}) {
+		config.Downlink = recommended.Downlink
+	}
+}
+
+// defaultACC100BBDevConfig fills omitted fields, numVfBundles defaults to amount of VFs of the PF
+func defaultACC100BBDevConfig(config, recommended *ACC100BBDevConfig, vfAmount int) {
+	if config.NumVfBundles == 0 {
+		config.NumVfBundles = vfAmount
+	}
+	if config.MaxQueueSize == 0 {
+		config.MaxQueueSize = recommended.MaxQueueSize
+	}
+	defaultQueueGroupConfig(&config.Uplink4G, recommended.Uplink4G)
+	defaultQueueGroupConfig(&config.Downlink4G, recommended.Downlink4G)
+	defaultQueueGroupConfig(&config.Uplink5G, recommended.Uplink5G)
+	defaultQueueGroupConfig(&config.Downlink5G, recommended.Downlink5G)
+}
+
+// defaultQueueGroupConfig replaces omitted queue group with the recommended one; as zero queue groups disable the
+// group, only numAqsPerGroups and aqDepthLog2, which cannot be zero, are filled in a group which is specified
+func defaultQueueGroupConfig(config *QueueGroupConfig, recommended QueueGroupConfig) {
+	if *config == (QueueGroupConfig{}) {
+		*config = recommended
+		return
+	}
+	if config.NumAqsPerGroups == 0 {
+		config.NumAqsPerGroups = recommended.NumAqsPerGroups
+	}
+	if config.AqDepthLog2 == 0 {
+		config.AqDepthLog2 = recommended.AqDepthLog2
+	}
+}
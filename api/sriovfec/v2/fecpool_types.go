@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FecPoolPartition assigns VFs of the pool to a single namespace
+type FecPoolPartition struct {
+	// Namespace allowed to consume VFs of the partition
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Namespace string `json:"namespace"`
+	// VFAmount is an amount of VFs assigned to the namespace on each node of the pool
+	// +kubebuilder:validation:Minimum=1
+	VFAmount int `json:"vfAmount"`
+}
+
+// FecPoolSpec defines the desired state of FecPool
+type FecPoolSpec struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Selector describes target nodes of the pool
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Selector describes accelerators which VFs are partitioned
+	AcceleratorSelector AcceleratorSelector `json:"acceleratorSelector,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Partitions of node's VFs among namespaces
+	// +kubebuilder:validation:MinItems=1
+	Partitions []FecPoolPartition `json:"partitions"`
+}
+
+// FecPoolStatus defines the observed state of FecPool
+type FecPoolStatus struct {
+	// Indicates the synchronization status of the CR
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	SyncStatus    SyncStatus `json:"syncStatus,omitempty"`
+	LastSyncError string     `json:"lastSyncError,omitempty"`
+	// Resources maps namespace of the partition to the device plugin resource exposing its VFs
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Resources map[string]string `json:"resources,omitempty"`
+	// AssignedVFs maps namespace of the partition to PCI addresses of its VFs last applied to the device plugin config.
+	// The device plugin is restarted only on nodes which VFs are assigned differently, even if the config was rewritten since.
+	AssignedVFs map[string][]string `json:"assignedVFs,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=fp
+// +kubebuilder:printcolumn:name="SyncStatus",type=string,JSONPath=`.status.syncStatus`
+
+// FecPool is the Schema for the fecpools API
+// +operator-sdk:csv:customresourcedefinitions:displayName="FecPool"
+type FecPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FecPoolSpec   `json:"spec,omitempty"`
+	Status FecPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FecPoolList contains a list of FecPool
+type FecPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FecPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FecPool{}, &FecPoolList{})
+}
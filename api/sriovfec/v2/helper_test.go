@@ -91,6 +91,18 @@ var _ = Describe("helperFunctionsTest", func() {
 					Expect(selector.Matches(accelerator)).To(BeTrue())
 				})
 			})
+
+			It("should match accelerators on the PCI bus", func() {
+				selector := AcceleratorSelector{PCIBus: "0000:3B"}
+
+				Expect(selector.Matches(SriovAccelerator{PCIAddress: "0000:3b:00.0"})).To(BeTrue())
+				Expect(selector.Matches(SriovAccelerator{PCIAddress: "0000:3c:00.0"})).To(BeFalse())
+			})
+
+			It("should match all accelerators with an empty selector", func() {
+				Expect(AcceleratorSelector{}.Matches(SriovAccelerator{VendorID: "8086", DeviceID: "0d5c", PCIAddress: "0000:14:00.0"})).To(BeTrue())
+				Expect(AcceleratorSelector{}.Matches(SriovAccelerator{VendorID: "8086", DeviceID: "57c0", PCIAddress: "0000:f7:00.0"})).To(BeTrue())
+			})
 		})
 	})
 
@@ -132,3 +144,60 @@ var _ = Describe("helperFunctionsTest", func() {
 		})
 	})
 })
+
+var _ = Describe("SriovFecNodeConfig protection", func() {
+	protected := func(pcis ...string) *SriovFecNodeConfig {
+		nc := &SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: map[string]string{ProtectAnnotation: "true"}}}
+		for _, pci := range pcis {
+			nc.Spec.PhysicalFunctions = append(nc.Spec.PhysicalFunctions, PhysicalFunctionConfigExt{PCIAddress: pci})
+		}
+		return nc
+	}
+
+	It("should reject deletion of protected node config", func() {
+		Expect(protected().ValidateDelete()).To(HaveOccurred())
+	})
+
+	It("should allow deletion once annotation is removed", func() {
+		nc := protected()
+		nc.Annotations = nil
+		Expect(nc.ValidateDelete()).To(Succeed())
+	})
+
+	It("should reject update which prunes physical functions of protected node config", func() {
+		Expect(protected("0000:af:00.0").ValidateUpdate(protected("0000:af:00.0", "0000:b0:00.0"))).To(HaveOccurred())
+	})
+
+	It("should allow update which keeps physical functions of protected node config", func() {
+		Expect(protected("0000:af:00.0", "0000:b0:00.0").ValidateUpdate(protected("0000:af:00.0"))).To(Succeed())
+	})
+
+	It("should allow removal of the annotation alone", func() {
+		nc := protected("0000:af:00.0")
+		nc.Annotations = nil
+		Expect(nc.ValidateUpdate(protected("0000:af:00.0"))).To(Succeed())
+	})
+})
+
+var _ = Describe("DuplicatePhysicalFunctions", func() {
+	nodeConfig := func(pcis ...string) *SriovFecNodeConfig {
+		nc := &SriovFecNodeConfig{}
+		for _, pci := range pcis {
+			nc.Spec.PhysicalFunctions = append(nc.Spec.PhysicalFunctions, PhysicalFunctionConfigExt{PCIAddress: pci})
+		}
+		return nc
+	}
+
+	It("should report physical functions configuring the same accelerator again", func() {
+		errs := DuplicatePhysicalFunctions(nodeConfig("0000:af:00.0", "0000:b0:00.0", "0000:af:00.0").Spec)
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0].Field).To(Equal("spec.physicalFunctions[2].pciAddress"))
+		Expect(DuplicatePhysicalFunctions(nodeConfig("0000:af:00.0", "0000:b0:00.0").Spec)).To(BeEmpty())
+	})
+
+	It("should reject node config configuring the same accelerator twice", func() {
+		Expect(nodeConfig("0000:af:00.0", "0000:af:00.0").ValidateCreate()).To(HaveOccurred())
+		Expect(nodeConfig("0000:af:00.0", "0000:af:00.0").ValidateUpdate(nodeConfig("0000:af:00.0"))).To(HaveOccurred())
+		Expect(nodeConfig("0000:af:00.0").ValidateCreate()).To(Succeed())
+	})
+})
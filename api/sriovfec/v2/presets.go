@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import "fmt"
+
+const (
+	// ProfileFlexRAN5GDefault configures 5G queues (and FFT queues of ACC200) for FlexRAN
+	ProfileFlexRAN5GDefault = "flexran-5g-default"
+	// ProfileLTEOnly configures 4G queues only
+	ProfileLTEOnly = "lte-only"
+
+	// maxProfileVfAmount is the maximum amount of VF bundles of accelerators the profiles expand to
+	maxProfileVfAmount = 16
+)
+
+// presets keep bbDevConfig of profiles keyed by profile and device name, for requested amount of VFs
+var presets = map[string]map[string]func(vfAmount int) BBDevConfig{
+	ProfileFlexRAN5GDefault: {
+		"ACC100": func(vfAmount int) BBDevConfig {
+			config := RecommendedACC100BBDevConfig()
+			config.NumVfBundles = vfAmount
+			return BBDevConfig{ACC100: config}
+		},
+		"ACC200": func(vfAmount int) BBDevConfig {
+			config := RecommendedACC200BBDevConfig()
+			config.NumVfBundles = vfAmount
+			return BBDevConfig{ACC200: config}
+		},
+		"FPGA_5GNR": func(int) BBDevConfig {
+			return BBDevConfig{N3000: RecommendedN3000BBDevConfig("FPGA_5GNR")}
+		},
+	},
+	ProfileLTEOnly: {
+		"ACC100": func(vfAmount int) BBDevConfig {
+			return BBDevConfig{ACC100: lteOnlyACC100BBDevConfig(vfAmount)}
+		},
+		"ACC200": func(vfAmount int) BBDevConfig {
+			return BBDevConfig{ACC200: &ACC200BBDevConfig{
+				ACC100BBDevConfig: *lteOnlyACC100BBDevConfig(vfAmount),
+				QFFT:              QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+			}}
+		},
+		"FPGA_LTE": func(int) BBDevConfig {
+			return BBDevConfig{N3000: RecommendedN3000BBDevConfig("FPGA_LTE")}
+		},
+	},
+}
+
+func lteOnlyACC100BBDevConfig(vfAmount int) *ACC100BBDevConfig {
+	return &ACC100BBDevConfig{
+		NumVfBundles: vfAmount,
+		MaxQueueSize: 1024,
+		Uplink4G:     QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		Downlink4G:   QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		Uplink5G:     QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		Downlink5G:   QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+	}
+}
+
+// Expand returns bbDevConfig the profile expands to for the device (ACC100, ACC200, FPGA_5GNR or FPGA_LTE) with given
+// amount of VFs; bbDevConfig without profile is returned as is
+func (in BBDevConfig) Expand(device string, vfAmount int) (BBDevConfig, error) {
+	if in.Profile == "" {
+		return in, nil
+	}
+	preset, ok := presets[in.Profile][device]
+	if !ok {
+		return in, fmt.Errorf("profile %s is not supported by '%s' accelerator", in.Profile, device)
+	}
+	return preset(vfAmount), nil
+}
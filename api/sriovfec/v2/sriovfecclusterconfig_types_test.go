@@ -8,7 +8,11 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
 )
 
 var _ = Describe("UplinkDownlinkQueues", func() {
@@ -165,3 +169,296 @@ var _ = Describe("BBDevConfig Validation", func() {
 		})
 	})
 })
+
+var _ = Describe("VFDriverOverrides", func() {
+	pf := PhysicalFunctionConfigExt{
+		VFDriver: "vfio-pci",
+		VFAmount: 8,
+		VFDriverOverrides: []VFDriverOverride{
+			{First: 4, Last: 7, Driver: "acc100"},
+		},
+	}
+
+	It("should resolve driver of VF by its index", func() {
+		Expect(pf.VFDriverFor(0)).To(Equal("vfio-pci"))
+		Expect(pf.VFDriverFor(3)).To(Equal("vfio-pci"))
+		Expect(pf.VFDriverFor(4)).To(Equal("acc100"))
+		Expect(pf.VFDriverFor(7)).To(Equal("acc100"))
+		Expect(pf.VFDriverFor(-1)).To(Equal("vfio-pci"))
+		Expect(pf.VFDrivers()).To(Equal([]string{"vfio-pci", "acc100"}))
+	})
+
+	validate := func(overrides ...VFDriverOverride) field.ErrorList {
+		return vfDriverOverridesValidator(SriovFecClusterConfigSpec{
+			PhysicalFunction: PhysicalFunctionConfig{VFAmount: 8, VFDriverOverrides: overrides},
+		})
+	}
+
+	It("should accept disjoint ranges", func() {
+		Expect(validate(VFDriverOverride{First: 0, Last: 3, Driver: "a"}, VFDriverOverride{First: 4, Last: 7, Driver: "b"})).To(BeEmpty())
+	})
+
+	It("should reject overlapping ranges", func() {
+		Expect(validate(VFDriverOverride{First: 0, Last: 4, Driver: "a"}, VFDriverOverride{First: 4, Last: 7, Driver: "b"})).To(HaveLen(1))
+	})
+
+	It("should reject range exceeding vfAmount", func() {
+		Expect(validate(VFDriverOverride{First: 4, Last: 8, Driver: "a"})).To(HaveLen(1))
+	})
+
+	It("should reject inverted range", func() {
+		Expect(validate(VFDriverOverride{First: 3, Last: 1, Driver: "a"})).To(HaveLen(1))
+	})
+
+	It("should reject range without driver", func() {
+		Expect(validate(VFDriverOverride{First: 0, Last: 1})).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("PFDriverFallbacks", func() {
+	validate := func(fallbacks ...string) field.ErrorList {
+		return pfDriverFallbacksValidator(SriovFecClusterConfigSpec{
+			PhysicalFunction: PhysicalFunctionConfig{PFDriver: "pci-pf-stub", PFDriverFallbacks: fallbacks},
+		})
+	}
+
+	It("should list PF driver followed by fallbacks", func() {
+		pf := PhysicalFunctionConfigExt{PFDriver: "pci-pf-stub", PFDriverFallbacks: []string{"igb_uio", "vfio-pci"}}
+		Expect(pf.PFDrivers()).To(Equal([]string{"pci-pf-stub", "igb_uio", "vfio-pci"}))
+	})
+
+	It("should accept known drivers", func() {
+		Expect(validate("igb_uio", "vfio-pci")).To(BeEmpty())
+	})
+
+	It("should reject unknown and repeated drivers", func() {
+		Expect(validate("unknown")).To(HaveLen(1))
+		Expect(validate("igb_uio", "pci-pf-stub", "igb_uio")).To(HaveLen(2))
+	})
+})
+
+var _ = Describe("featureGatesValidator", func() {
+	spec := SriovFecClusterConfigSpec{
+		PhysicalFunction: PhysicalFunctionConfig{BBDevConfig: BBDevConfig{ACC200: &ACC200BBDevConfig{}}},
+	}
+
+	AfterEach(func() {
+		Expect(featuregates.Set("")).To(Succeed())
+	})
+
+	It("should accept ACC200 configuration when ACC200 feature is enabled", func() {
+		Expect(bbDevConfigValidator(spec)).To(BeEmpty())
+	})
+
+	It("should reject ACC200 configuration when ACC200 feature is disabled", func() {
+		Expect(featuregates.Set("ACC200=false")).To(Succeed())
+		Expect(bbDevConfigValidator(spec)).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("BBDevConfig profile", func() {
+	It("should expand flexran-5g-default profile for ACC100", func() {
+		config, err := BBDevConfig{Profile: ProfileFlexRAN5GDefault}.Expand("ACC100", 8)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.Profile).To(BeEmpty())
+		Expect(config.ACC100).ToNot(BeNil())
+		Expect(config.ACC100.NumVfBundles).To(Equal(8))
+		Expect(config.ACC100.Validate()).To(Succeed())
+	})
+
+	It("should expand lte-only profile for ACC200 to 4G queues only", func() {
+		config, err := BBDevConfig{Profile: ProfileLTEOnly}.Expand("ACC200", 16)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config.ACC200).ToNot(BeNil())
+		Expect(config.ACC200.Uplink4G.NumQueueGroups).To(Equal(4))
+		Expect(config.ACC200.Uplink5G.NumQueueGroups).To(BeZero())
+		Expect(config.ACC200.QFFT.NumQueueGroups).To(BeZero())
+	})
+
+	It("should reject profile not supported by the device", func() {
+		_, err := BBDevConfig{Profile: ProfileLTEOnly}.Expand("FPGA_5GNR", 2)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return bbDevConfig without profile as is", func() {
+		config := BBDevConfig{ACC100: RecommendedACC100BBDevConfig()}
+		Expect(config.Expand("ACC100", 16)).To(Equal(config))
+	})
+
+	It("should reject profile along with configuration of a particular card", func() {
+		spec := SriovFecClusterConfigSpec{PhysicalFunction: PhysicalFunctionConfig{
+			VFAmount:    16,
+			BBDevConfig: BBDevConfig{Profile: ProfileFlexRAN5GDefault, ACC100: RecommendedACC100BBDevConfig()},
+		}}
+		Expect(ambiguousBBDevConfigValidator(spec)).To(HaveLen(1))
+	})
+
+	It("should accept profile without configuration of a particular card", func() {
+		spec := SriovFecClusterConfigSpec{PhysicalFunction: PhysicalFunctionConfig{
+			VFAmount:    16,
+			BBDevConfig: BBDevConfig{Profile: ProfileFlexRAN5GDefault},
+		}}
+		Expect(ambiguousBBDevConfigValidator(spec)).To(BeEmpty())
+	})
+
+	It("should reject profileRef along with preset profile", func() {
+		spec := SriovFecClusterConfigSpec{PhysicalFunction: PhysicalFunctionConfig{
+			VFAmount:    16,
+			BBDevConfig: BBDevConfig{ProfileRef: "acc100-5g-default", Profile: ProfileFlexRAN5GDefault},
+		}}
+		Expect(ambiguousBBDevConfigValidator(spec)).To(HaveLen(1))
+	})
+
+	It("should accept profileRef without configuration of a particular card", func() {
+		spec := SriovFecClusterConfigSpec{PhysicalFunction: PhysicalFunctionConfig{
+			VFAmount:    16,
+			BBDevConfig: BBDevConfig{ProfileRef: "acc100-5g-default"},
+		}}
+		Expect(ambiguousBBDevConfigValidator(spec)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("BBDevConfig defaulting", func() {
+	It("should fill omitted fields of ACC100 with recommended values", func() {
+		config := BBDevConfig{ACC100: &ACC100BBDevConfig{
+			Uplink5G: QueueGroupConfig{NumQueueGroups: 2},
+		}}
+		config.Default("", 8)
+		Expect(config.ACC100.NumVfBundles).To(Equal(8))
+		Expect(config.ACC100.MaxQueueSize).To(Equal(1024))
+		Expect(config.ACC100.Uplink5G).To(Equal(QueueGroupConfig{NumQueueGroups: 2, NumAqsPerGroups: 16, AqDepthLog2: 4}))
+		Expect(config.ACC100.Downlink5G).To(Equal(RecommendedACC100BBDevConfig().Downlink5G))
+		Expect(config.ACC100.Validate()).To(Succeed())
+	})
+
+	It("should keep disabled queue groups", func() {
+		config := BBDevConfig{ACC100: &ACC100BBDevConfig{
+			Uplink4G: QueueGroupConfig{NumQueueGroups: 0, NumAqsPerGroups: 16, AqDepthLog2: 4},
+		}}
+		config.Default("", 16)
+		Expect(config.ACC100.Uplink4G.NumQueueGroups).To(BeZero())
+	})
+
+	It("should default empty bbDevConfig of the selected device", func() {
+		config := BBDevConfig{}
+		config.Default("57c0", 4)
+		Expect(config.ACC200).ToNot(BeNil())
+		Expect(config.ACC200.NumVfBundles).To(Equal(4))
+		Expect(config.ACC200.QFFT).To(Equal(RecommendedACC200BBDevConfig().QFFT))
+
+		config = BBDevConfig{}
+		config.Default("0d8f", 2)
+		Expect(config.N3000).To(Equal(RecommendedN3000BBDevConfig("FPGA_5GNR")))
+
+		config = BBDevConfig{}
+		config.Default("", 2)
+		Expect(config).To(Equal(BBDevConfig{}))
+	})
+
+	It("should not default flrTimeout of N3000 with specified links", func() {
+		config := BBDevConfig{N3000: &N3000BBDevConfig{NetworkType: "FPGA_LTE", Uplink: UplinkDownlink{Bandwidth: 8}}}
+		config.Default("", 2)
+		Expect(config.N3000.FLRTimeOut).To(BeZero())
+		Expect(config.N3000.Uplink.Bandwidth).To(Equal(8))
+		Expect(config.N3000.Downlink).To(Equal(RecommendedN3000BBDevConfig("FPGA_LTE").Downlink))
+	})
+
+	It("should not default profiles", func() {
+		config := BBDevConfig{Profile: ProfileLTEOnly}
+		config.Default("0d5c", 2)
+		Expect(config).To(Equal(BBDevConfig{Profile: ProfileLTEOnly}))
+	})
+})
+
+var _ = Describe("pfModeValidator", func() {
+	It("should reject pfMode unless the config opts in", func() {
+		cc := &SriovFecClusterConfig{}
+		cc.Spec.PhysicalFunction.BBDevConfig.ACC100 = &ACC100BBDevConfig{PFMode: true}
+		Expect(pfModeValidator(cc)).To(HaveLen(1))
+		Expect(cc.Warnings()).To(HaveLen(1))
+
+		cc.Annotations = map[string]string{AllowPFModeAnnotation: "true"}
+		Expect(pfModeValidator(cc)).To(BeEmpty())
+		Expect(cc.Warnings()).To(HaveLen(1))
+
+		cc.Spec.PhysicalFunction.BBDevConfig.ACC100.PFMode = false
+		Expect(cc.Warnings()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("pfDriverChangeValidator", func() {
+	It("should reject in-place change of pfDriver unless the config forces it", func() {
+		previous := &SriovFecClusterConfig{}
+		previous.Spec.PhysicalFunction.PFDriver = "pci-pf-stub"
+		cc := previous.DeepCopy()
+		Expect(pfDriverChangeValidator(previous, cc)).To(BeEmpty())
+
+		cc.Spec.PhysicalFunction.PFDriver = "vfio-pci"
+		Expect(pfDriverChangeValidator(previous, cc)).To(HaveLen(1))
+
+		cc.Annotations = map[string]string{ForceDriverChangeAnnotation: "true"}
+		Expect(pfDriverChangeValidator(previous, cc)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("supportMatrixValidator", func() {
+	AfterEach(func() {
+		supportmatrix.SetOpenShiftVersion("")
+	})
+
+	It("should reject unsupported combinations unless the config allows them", func() {
+		cc := &SriovFecClusterConfig{}
+		cc.Spec.PhysicalFunction.PFDriver = "vfio-pci"
+		cc.Spec.PhysicalFunction.BBDevConfig.ACC100 = &ACC100BBDevConfig{}
+		Expect(supportMatrixValidator(cc)).To(BeEmpty())
+
+		supportmatrix.SetOpenShiftVersion("4.11.3")
+		Expect(supportMatrixValidator(cc)).To(HaveLen(1))
+		Expect(cc.Warnings()).To(BeEmpty())
+
+		cc.Annotations = map[string]string{UnsupportedOKAnnotation: "true"}
+		Expect(supportMatrixValidator(cc)).To(BeEmpty())
+		Expect(cc.Warnings()).To(HaveLen(1))
+	})
+
+	It("should check the support matrix only on updates of the accelerator or PF driver", func() {
+		previous := &SriovFecClusterConfig{}
+		previous.Spec.PhysicalFunction.PFDriver = "vfio-pci"
+		previous.Spec.PhysicalFunction.BBDevConfig.ACC100 = &ACC100BBDevConfig{}
+
+		cc := previous.DeepCopy()
+		cc.Finalizers = []string{"sriovfec.intel.com/finalizer"}
+		Expect(combinationChanged(previous, cc)).To(BeFalse())
+
+		cc.Spec.PhysicalFunction.PFDriver = "pci-pf-stub"
+		Expect(combinationChanged(previous, cc)).To(BeTrue())
+	})
+})
+
+var _ = Describe("SriovFecProfile validation", func() {
+	profile := func(bbDevConfig BBDevConfig) *SriovFecProfile {
+		return &SriovFecProfile{ObjectMeta: metav1.ObjectMeta{Name: "profile"}, Spec: SriovFecProfileSpec{BBDevConfig: bbDevConfig}}
+	}
+
+	It("should accept preset profile and configuration of a card", func() {
+		Expect(profile(BBDevConfig{Profile: ProfileFlexRAN5GDefault}).ValidateCreate()).To(Succeed())
+		Expect(profile(BBDevConfig{ACC100: &ACC100BBDevConfig{NumVfBundles: 16}}).ValidateCreate()).To(Succeed())
+	})
+
+	It("should reject bbDevConfig which ClusterConfigs couldn't be rendered with", func() {
+		queueGroups := QueueGroupConfig{NumQueueGroups: 4, NumAqsPerGroups: 16, AqDepthLog2: 4}
+		tooManyQueueGroups := &ACC100BBDevConfig{Uplink4G: queueGroups, Downlink4G: queueGroups, Uplink5G: queueGroups}
+
+		for _, bbDevConfig := range []BBDevConfig{
+			{},
+			{ProfileRef: "another"},
+			{Profile: ProfileFlexRAN5GDefault, ACC100: &ACC100BBDevConfig{}},
+			{ACC100: &ACC100BBDevConfig{}, ACC200: &ACC200BBDevConfig{}},
+			{ACC100: tooManyQueueGroups},
+			{ACC100: &ACC100BBDevConfig{PFMode: true}},
+		} {
+			Expect(profile(bbDevConfig).ValidateCreate()).ToNot(Succeed())
+			Expect(profile(bbDevConfig).ValidateUpdate(profile(BBDevConfig{}))).ToNot(Succeed())
+		}
+	})
+})
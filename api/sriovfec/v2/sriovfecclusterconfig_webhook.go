@@ -5,8 +5,12 @@ package v2
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	"github.com/intel/sriov-fec-operator/pkg/common/webhookwarnings"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,9 +23,27 @@ import (
 var sriovfecclusterconfiglog = utils.NewLogger()
 
 func (in *SriovFecClusterConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// validating webhook warning about discouraged settings is registered first, so the builder skips its own
+	mgr.GetWebhookServer().Register(validatingWebhookPath, webhookwarnings.ValidatingWebhookFor(in))
 	return ctrl.NewWebhookManagedBy(mgr).For(in).Complete()
 }
 
+//+kubebuilder:webhook:path=/mutate-sriovfec-intel-com-v2-sriovfecclusterconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=sriovfec.intel.com,resources=sriovfecclusterconfigs,verbs=create;update,versions=v2,name=msriovfecclusterconfig.kb.io,admissionReviewVersions={v1}
+
+var _ webhook.Defaulter = &SriovFecClusterConfig{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (in *SriovFecClusterConfig) Default() {
+	sriovfecclusterconfiglog.WithField("name", in.Name).Info("default")
+	// physicalFunction is not applied to deconfigured accelerators
+	if in.Spec.IsAbsent() {
+		return
+	}
+	in.Spec.PhysicalFunction.BBDevConfig.Default(in.Spec.AcceleratorSelector.DeviceID, in.Spec.PhysicalFunction.VFAmount)
+}
+
+const validatingWebhookPath = "/validate-sriovfec-intel-com-v2-sriovfecclusterconfig"
+
 //+kubebuilder:webhook:path=/validate-sriovfec-intel-com-v2-sriovfecclusterconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=sriovfec.intel.com,resources=sriovfecclusterconfigs,verbs=create;update,versions=v2,name=vsriovfecclusterconfig.kb.io,admissionReviewVersions={v1}
 
 var _ webhook.Validator = &SriovFecClusterConfig{}
@@ -29,39 +51,73 @@ var _ webhook.Validator = &SriovFecClusterConfig{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (in *SriovFecClusterConfig) ValidateCreate() error {
 	sriovfecclusterconfiglog.WithField("name", in.Name).Info("validate create")
-	if errs := validate(in.Spec); len(errs) != 0 {
+	if errs := append(append(validate(in.Spec), pfModeValidator(in)...), supportMatrixValidator(in)...); len(errs) != 0 {
 		return apierrors.NewInvalid(schema.GroupKind{Group: "sriovfec.intel.com", Kind: "SriovFecClusterConfig"}, in.Name, errs)
 	}
 	return nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (in *SriovFecClusterConfig) ValidateUpdate(_ runtime.Object) error {
+func (in *SriovFecClusterConfig) ValidateUpdate(old runtime.Object) error {
 	sriovfecclusterconfiglog.WithField("name", in.Name).Info("validate update")
-	if errs := validate(in.Spec); len(errs) != 0 {
+	errs := append(validate(in.Spec), pfModeValidator(in)...)
+	previous, ok := old.(*SriovFecClusterConfig)
+	if !ok || combinationChanged(previous, in) {
+		errs = append(errs, supportMatrixValidator(in)...)
+	}
+	if ok {
+		errs = append(errs, pfDriverChangeValidator(previous, in)...)
+	}
+	if len(errs) != 0 {
 		return apierrors.NewInvalid(schema.GroupKind{Group: "sriovfec.intel.com", Kind: "SriovFecClusterConfig"}, in.Name, errs)
 	}
 	return nil
 }
 
 func validate(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+	// physicalFunction is not applied to deconfigured accelerators
+	if spec.IsAbsent() {
+		return nil
+	}
 
 	validators := []func(spec SriovFecClusterConfigSpec) field.ErrorList{
 		ambiguousBBDevConfigValidator,
-		n3000LinkQueuesValidator,
+		bbDevConfigValidator,
 		acc100VfAmountValidator,
 		acc200VfAmountValidator,
+		vfDriverOverridesValidator,
+		pfDriverFallbacksValidator,
+		syncIntervalValidator,
+	}
+
+	for _, validate := range validators {
+		errs = append(errs, validate(spec)...)
+	}
+
+	return errs
+}
+
+// validateBBDevConfig runs validators which need nothing but the bbDevConfig, so they are shared by ClusterConfigs and
+// SriovFecProfiles
+func validateBBDevConfig(bbDevConfig BBDevConfig, path *field.Path) (errs field.ErrorList) {
+	validators := []func(bbDevConfig BBDevConfig, path *field.Path) field.ErrorList{
+		n3000LinkQueuesValidator,
 		acc200NumQueueGroupsValidator,
 		acc100NumQueueGroupsValidator,
+		featureGatesValidator,
 	}
 
 	for _, validate := range validators {
-		errs = append(errs, validate(spec)...)
+		errs = append(errs, validate(bbDevConfig, path)...)
 	}
 
 	return errs
 }
 
+func bbDevConfigValidator(spec SriovFecClusterConfigSpec) field.ErrorList {
+	return validateBBDevConfig(spec.PhysicalFunction.BBDevConfig, field.NewPath("spec", "physicalFunction", "bbDevConfig"))
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (in *SriovFecClusterConfig) ValidateDelete() error {
 	sriovfecclusterconfiglog.WithField("name", in.Name).Info("validate delete")
@@ -69,13 +125,22 @@ func (in *SriovFecClusterConfig) ValidateDelete() error {
 	return nil
 }
 
-func hasAmbiguousBBDevConfigs(bbDevConfig BBDevConfig) *field.Error {
+// featureGatesValidator rejects configuration of features disabled with feature gates
+func featureGatesValidator(bbDevConfig BBDevConfig, path *field.Path) (errs field.ErrorList) {
+	if bbDevConfig.ACC200 != nil && !featuregates.Enabled(featuregates.ACC200) {
+		errs = append(errs, field.Forbidden(path.Child("acc200"),
+			"configuration of ACC200 is disabled with ACC200 feature gate"))
+	}
+	return errs
+}
+
+func hasAmbiguousBBDevConfigs(bbDevConfig BBDevConfig, path *field.Path) *field.Error {
 
 	var found interface{}
 	for _, config := range []interface{}{bbDevConfig.N3000, bbDevConfig.ACC100, bbDevConfig.ACC200} {
 		if !isNil(config) && !isNil(found) {
 			return field.Forbidden(
-				field.NewPath("spec").Child("physicalFunction").Child("bbDevConfig"),
+				path,
 				"specified bbDevConfig cannot contain multiple configurations")
 		}
 		found = config
@@ -84,11 +149,19 @@ func hasAmbiguousBBDevConfigs(bbDevConfig BBDevConfig) *field.Error {
 }
 
 func ambiguousBBDevConfigValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
-	if err := hasAmbiguousBBDevConfigs(spec.PhysicalFunction.BBDevConfig); err != nil {
+	if err := hasAmbiguousBBDevConfigs(spec.PhysicalFunction.BBDevConfig, field.NewPath("spec", "physicalFunction", "bbDevConfig")); err != nil {
 		errs = append(errs, err)
 		return
 	}
 
+	if spec.PhysicalFunction.BBDevConfig.ProfileRef != "" {
+		return profileRefValidator(spec)
+	}
+
+	if spec.PhysicalFunction.BBDevConfig.Profile != "" {
+		return profileValidator(spec)
+	}
+
 	if spec.PhysicalFunction.BBDevConfig.N3000 == nil &&
 		spec.PhysicalFunction.BBDevConfig.ACC100 == nil &&
 		spec.PhysicalFunction.BBDevConfig.ACC200 == nil {
@@ -102,7 +175,37 @@ func ambiguousBBDevConfigValidator(spec SriovFecClusterConfigSpec) (errs field.E
 	return
 }
 
-func n3000LinkQueuesValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+// profileValidator forbids configuration of a particular card along with profile, which is expanded to such
+// configuration, and amount of VFs exceeding VF bundles supported by the profiles
+func profileValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+	bbDevConfig := spec.PhysicalFunction.BBDevConfig
+	if bbDevConfig.N3000 != nil || bbDevConfig.ACC100 != nil || bbDevConfig.ACC200 != nil {
+		errs = append(errs, field.Forbidden(
+			field.NewPath("spec").Child("physicalFunction").Child("bbDevConfig").Child("profile"),
+			"profile cannot be specified along with configuration of a particular card"))
+	}
+	if spec.PhysicalFunction.VFAmount < 1 || spec.PhysicalFunction.VFAmount > maxProfileVfAmount {
+		errs = append(errs, field.Invalid(
+			field.NewPath("spec").Child("physicalFunction").Child("vfAmount"),
+			spec.PhysicalFunction.VFAmount,
+			fmt.Sprintf("profile %s supports from 1 to %d VFs", bbDevConfig.Profile, maxProfileVfAmount)))
+	}
+	return errs
+}
+
+// profileRefValidator forbids configuration of a particular card or preset profile along with SriovFecProfile
+// reference, which replaces the whole bbDevConfig
+func profileRefValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+	bbDevConfig := spec.PhysicalFunction.BBDevConfig
+	if bbDevConfig.Profile != "" || bbDevConfig.N3000 != nil || bbDevConfig.ACC100 != nil || bbDevConfig.ACC200 != nil {
+		errs = append(errs, field.Forbidden(
+			field.NewPath("spec").Child("physicalFunction").Child("bbDevConfig").Child("profileRef"),
+			"profileRef cannot be specified along with profile or configuration of a particular card"))
+	}
+	return errs
+}
+
+func n3000LinkQueuesValidator(bbDevConfig BBDevConfig, path *field.Path) (errs field.ErrorList) {
 
 	validateN3000Queues := func(qID *field.Path, queues UplinkDownlinkQueues) *field.Error {
 		total := queues.VF0 + queues.VF1 + queues.VF2 + queues.VF3 + queues.VF4 + queues.VF5 + queues.VF5 + queues.VF6 + queues.VF7
@@ -112,18 +215,14 @@ func n3000LinkQueuesValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorL
 		return nil
 	}
 
-	if n3000Config := spec.PhysicalFunction.BBDevConfig.N3000; n3000Config != nil {
-		queuePath := field.NewPath("spec").
-			Child("physicalFunction").
-			Child("bbDevConfig", "n3000", "uplink", "queues")
+	if n3000Config := bbDevConfig.N3000; n3000Config != nil {
+		queuePath := path.Child("n3000", "uplink", "queues")
 
 		if err := validateN3000Queues(queuePath, n3000Config.Uplink.Queues); err != nil {
 			errs = append(errs, err)
 		}
 
-		queuePath = field.NewPath("spec").
-			Child("physicalFunction").
-			Child("bbDevConfig", "n3000", "downlink", "queues")
+		queuePath = path.Child("n3000", "downlink", "queues")
 
 		if err := validateN3000Queues(queuePath, n3000Config.Downlink.Queues); err != nil {
 			errs = append(errs, err)
@@ -187,7 +286,7 @@ func acc200VfAmountValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorLi
 	return
 }
 
-func acc100NumQueueGroupsValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+func acc100NumQueueGroupsValidator(bbDevConfig BBDevConfig, path *field.Path) (errs field.ErrorList) {
 
 	validate := func(accConfig *ACC100BBDevConfig, path *field.Path) *field.Error {
 		if accConfig == nil {
@@ -201,7 +300,7 @@ func acc100NumQueueGroupsValidator(spec SriovFecClusterConfigSpec) (errs field.E
 
 		if sum := downlink5g + uplink5g + downlink4g + uplink4g; sum > 8 {
 			return field.Invalid(
-				field.NewPath("spec", "physicalFunction", "bbDevConfig", "acc100", "[downlink4G|uplink4G|downlink5G|uplink5G]", "numQueueGroups"),
+				path,
 				sum,
 				"sum of all numQueueGroups should not be greater than 8",
 			)
@@ -209,14 +308,14 @@ func acc100NumQueueGroupsValidator(spec SriovFecClusterConfigSpec) (errs field.E
 		return nil
 	}
 
-	if err := validate(spec.PhysicalFunction.BBDevConfig.ACC100, field.NewPath("spec", "physicalFunction", "bbDevConfig", "acc100", "[downlink4G|uplink4G|downlink5G|uplink5G]", "numQueueGroups")); err != nil {
+	if err := validate(bbDevConfig.ACC100, path.Child("acc100", "[downlink4G|uplink4G|downlink5G|uplink5G]", "numQueueGroups")); err != nil {
 		errs = append(errs, err)
 	}
 
 	return
 }
 
-func acc200NumQueueGroupsValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+func acc200NumQueueGroupsValidator(bbDevConfig BBDevConfig, path *field.Path) (errs field.ErrorList) {
 
 	validate := func(accConfig *ACC200BBDevConfig, path *field.Path) *field.Error {
 		if accConfig == nil {
@@ -231,7 +330,7 @@ func acc200NumQueueGroupsValidator(spec SriovFecClusterConfigSpec) (errs field.E
 
 		if sum := downlink5g + uplink5g + downlink4g + uplink4g + qfft; sum > acc200maxQueueGroups {
 			return field.Invalid(
-				field.NewPath("spec", "physicalFunction", "bbDevConfig", "acc100", "[downlink4G|uplink4G|downlink5G|uplink5G]", "numQueueGroups"),
+				path,
 				sum,
 				fmt.Sprintf("sum of all numQueueGroups should not be greater than %d", acc200maxQueueGroups),
 			)
@@ -239,9 +338,114 @@ func acc200NumQueueGroupsValidator(spec SriovFecClusterConfigSpec) (errs field.E
 		return nil
 	}
 
-	if err := validate(spec.PhysicalFunction.BBDevConfig.ACC200, field.NewPath("spec", "physicalFunction", "bbDevConfig", "acc100", "[downlink4G|uplink4G|downlink5G|uplink5G|qfft]", "numQueueGroups")); err != nil {
+	if err := validate(bbDevConfig.ACC200, path.Child("acc200", "[downlink4G|uplink4G|downlink5G|uplink5G|qfft]", "numQueueGroups")); err != nil {
 		errs = append(errs, err)
 	}
 
 	return
 }
+
+func vfDriverOverridesValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+	path := field.NewPath("spec").Child("physicalFunction").Child("vfDriverOverrides")
+	overrides := spec.PhysicalFunction.VFDriverOverrides
+	for i, o := range overrides {
+		switch {
+		case o.Driver == "":
+			errs = append(errs, field.Required(path.Index(i).Child("driver"), "driver has to be specified"))
+		case o.First < 0 || o.First > o.Last:
+			errs = append(errs, field.Invalid(path.Index(i), o, "first should not be negative nor greater than last"))
+		case o.Last >= spec.PhysicalFunction.VFAmount:
+			errs = append(errs, field.Invalid(path.Index(i).Child("last"), o.Last, "value should be lower than physicalFunction.vfAmount"))
+		}
+
+		for j := 0; j < i; j++ {
+			if o.First <= overrides[j].Last && overrides[j].First <= o.Last {
+				errs = append(errs, field.Invalid(path.Index(i), o, fmt.Sprintf("range overlaps with vfDriverOverrides[%d]", j)))
+			}
+		}
+	}
+	return errs
+}
+
+func pfDriverFallbacksValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+	path := field.NewPath("spec").Child("physicalFunction").Child("pfDriverFallbacks")
+	pfDrivers := []string{utils.PCI_PF_STUB_DASH, utils.PCI_PF_STUB_UNDERSCORE, utils.IGB_UIO, utils.VFIO_PCI}
+	requested := []string{spec.PhysicalFunction.PFDriver}
+	for i, driver := range spec.PhysicalFunction.PFDriverFallbacks {
+		switch {
+		case !slices.Contains(pfDrivers, driver):
+			errs = append(errs, field.NotSupported(path.Index(i), driver, pfDrivers))
+		case slices.Contains(requested, driver):
+			errs = append(errs, field.Duplicate(path.Index(i), driver))
+		}
+		requested = append(requested, driver)
+	}
+	return errs
+}
+
+// minSyncInterval is the shortest period accelerators can be re-verified with
+const minSyncInterval = time.Minute
+
+func syncIntervalValidator(spec SriovFecClusterConfigSpec) (errs field.ErrorList) {
+	if spec.SyncInterval != nil && spec.SyncInterval.Duration < minSyncInterval {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("syncInterval"), spec.SyncInterval.Duration.String(),
+			fmt.Sprintf("value should be at least %s", minSyncInterval)))
+	}
+	return errs
+}
+
+// pfModeValidator rejects pfMode unless the config opts in with AllowPFModeAnnotation
+func pfModeValidator(in *SriovFecClusterConfig) field.ErrorList {
+	if in.Spec.IsAbsent() || !in.Spec.PhysicalFunction.BBDevConfig.PFModeEnabled() || in.AllowsPFMode() {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "physicalFunction", "bbDevConfig"),
+		fmt.Sprintf("pfMode bypasses SR-IOV and is not supported in production, set %s annotation to \"true\" to enable it", AllowPFModeAnnotation))}
+}
+
+// Warnings implements webhookwarnings.Warner, so admission of the config enabling pfMode or an unsupported combination
+// allowed by UnsupportedOKAnnotation warns about it
+func (in *SriovFecClusterConfig) Warnings() []string {
+	var warnings []string
+	if !in.Spec.IsAbsent() && in.Spec.PhysicalFunction.BBDevConfig.PFModeEnabled() {
+		warnings = append(warnings, "pfMode bypasses SR-IOV and is not supported in production")
+	}
+	if err := in.UnsupportedCombination(); err != nil && in.AllowsUnsupported() {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings
+}
+
+// supportMatrixValidator rejects combinations which are not listed in the support matrix unless the config allows them
+// with UnsupportedOKAnnotation
+func supportMatrixValidator(in *SriovFecClusterConfig) field.ErrorList {
+	err := in.UnsupportedCombination()
+	if err == nil || in.AllowsUnsupported() {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "physicalFunction"),
+		fmt.Sprintf("%s, set %s annotation to \"true\" to allow it", err, UnsupportedOKAnnotation))}
+}
+
+// combinationChanged returns true if the update changes the accelerator or PF driver checked in the support matrix. Other
+// updates, e.g. of finalizers or annotations, are not checked, so configs created before an upgrade of the cluster to
+// a version the matrix doesn't list yet can still be updated and deleted.
+func combinationChanged(previous, in *SriovFecClusterConfig) bool {
+	if previous.Spec.IsAbsent() || in.Spec.IsAbsent() {
+		return previous.Spec.IsAbsent() != in.Spec.IsAbsent()
+	}
+	return previous.Spec.PhysicalFunction.BBDevConfig.Device() != in.Spec.PhysicalFunction.BBDevConfig.Device() ||
+		previous.Spec.PhysicalFunction.PFDriver != in.Spec.PhysicalFunction.PFDriver
+}
+
+// pfDriverChangeValidator rejects in-place change of pfDriver, which resets the accelerators, unless the config forces
+// it with ForceDriverChangeAnnotation
+func pfDriverChangeValidator(previous, in *SriovFecClusterConfig) field.ErrorList {
+	if previous.Spec.IsAbsent() || in.Spec.IsAbsent() || previous.Spec.PhysicalFunction.PFDriver == in.Spec.PhysicalFunction.PFDriver ||
+		in.ForcesDriverChange() {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "physicalFunction", "pfDriver"),
+		fmt.Sprintf("changing pfDriver from %s to %s resets the accelerators and may require reboot, set %s annotation to \"true\" to force it",
+			previous.Spec.PhysicalFunction.PFDriver, in.Spec.PhysicalFunction.PFDriver, ForceDriverChangeAnnotation))}
+}
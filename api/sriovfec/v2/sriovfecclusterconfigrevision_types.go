@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterConfigLabel is set on SriovFecClusterConfigRevision to the name of the ClusterConfig it captures
+	ClusterConfigLabel = "sriovfec.intel.com/cluster-config"
+	// RevisionAnnotation is set on SriovFecClusterConfig to the number of its current revision
+	RevisionAnnotation = "sriovfec.intel.com/revision"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=sfccr
+// +kubebuilder:printcolumn:name="ClusterConfig",type=string,JSONPath=`.metadata.labels.sriovfec\.intel\.com/cluster-config`
+// +kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.revision`
+
+// SriovFecClusterConfigRevision is an immutable snapshot of SriovFecClusterConfig spec, captured by the operator
+// each time the spec changes. It's owned by the ClusterConfig and deleted along with it.
+// +operator-sdk:csv:customresourcedefinitions:displayName="SriovFecClusterConfigRevision"
+type SriovFecClusterConfigRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Revision is a number of the snapshot, increasing with each captured spec of the ClusterConfig
+	// +kubebuilder:validation:Minimum=1
+	Revision int64 `json:"revision"`
+	// Spec of the ClusterConfig captured by the revision
+	Spec SriovFecClusterConfigSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SriovFecClusterConfigRevisionList contains a list of SriovFecClusterConfigRevision
+type SriovFecClusterConfigRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovFecClusterConfigRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovFecClusterConfigRevision{}, &SriovFecClusterConfigRevisionList{})
+}
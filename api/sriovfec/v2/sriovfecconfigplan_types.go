@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PlannedAction is an action the daemon performs on an accelerator when the plan is applied
+type PlannedAction string
+
+const (
+	// PlannedConfigure creates VFs of the accelerator which has none and starts pf_bb_config
+	PlannedConfigure PlannedAction = "Configure"
+	// PlannedReconfigure recreates existing VFs of the accelerator and restarts pf_bb_config
+	PlannedReconfigure PlannedAction = "Reconfigure"
+	// PlannedDeconfigure stops pf_bb_config and removes VFs of the accelerator
+	PlannedDeconfigure PlannedAction = "Deconfigure"
+)
+
+// PlanPhase is a phase of SriovFecConfigPlan
+type PlanPhase string
+
+const (
+	// PlanPending plans wait for approval
+	PlanPending PlanPhase = "Pending"
+	// PlanApplied plans were written into the SriovFecNodeConfig of the node
+	PlanApplied PlanPhase = "Applied"
+)
+
+// AcceleratorPlan is an action planned for a single accelerator
+type AcceleratorPlan struct {
+	PCIAddress string        `json:"pciAddress"`
+	Action     PlannedAction `json:"action"`
+}
+
+// SriovFecConfigPlanSpec defines the desired state of SriovFecConfigPlan
+type SriovFecConfigPlanSpec struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Checksum of the SriovFecNodeConfig spec the plan applies; the plan is regenerated and its approval is reset
+	// when the rendered spec changes
+	Checksum string `json:"checksum"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Drain is true when the node is drained before the plan is applied
+	Drain bool `json:"drain"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Summary of changed fields of physical functions
+	// +optional
+	Summary string `json:"summary,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Actions performed on accelerators of the node
+	// +optional
+	Accelerators []AcceleratorPlan `json:"accelerators,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Approved allows the operator to apply the plan
+	// +optional
+	Approved bool `json:"approved,omitempty"`
+}
+
+// SriovFecConfigPlanStatus defines the observed state of SriovFecConfigPlan
+type SriovFecConfigPlanStatus struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	// Phase of the plan, Pending or Applied
+	Phase PlanPhase `json:"phase,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=sfcp
+// +kubebuilder:printcolumn:name="Drain",type=boolean,JSONPath=`.spec.drain`
+// +kubebuilder:printcolumn:name="Approved",type=boolean,JSONPath=`.spec.approved`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+
+// SriovFecConfigPlan details actions of a pending change of SriovFecNodeConfig of the node it's named after.
+// It's generated by the operator when approval of configuration changes is enabled, and the change is applied only
+// after the plan is approved.
+// +operator-sdk:csv:customresourcedefinitions:displayName="SriovFecConfigPlan"
+type SriovFecConfigPlan struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SriovFecConfigPlanSpec   `json:"spec,omitempty"`
+	Status SriovFecConfigPlanStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SriovFecConfigPlanList contains a list of SriovFecConfigPlan
+type SriovFecConfigPlanList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovFecConfigPlan `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovFecConfigPlan{}, &SriovFecConfigPlanList{})
+}
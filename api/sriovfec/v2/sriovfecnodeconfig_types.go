@@ -7,19 +7,61 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// VF is a virtual function created on the accelerator
 type VF struct {
+	// PCI address of the VF, as exposed by the device plugin in PCIDEVICE_<resource name> env var of allocating pods
 	PCIAddress string `json:"pciAddress"`
-	Driver     string `json:"driver"`
-	DeviceID   string `json:"deviceID"`
+	// Index of the VF in the SR-IOV capability of its PF (virtfn<index> link of the PF); unset when it can't be determined
+	// +optional
+	Index *int `json:"index,omitempty"`
+	// Driver the VF is bound to
+	Driver   string `json:"driver"`
+	DeviceID string `json:"deviceID"`
 }
 
+// SriovAccelerator is a physical function of the accelerator present on the node
 type SriovAccelerator struct {
 	VendorID   string `json:"vendorID"`
 	DeviceID   string `json:"deviceID"`
 	PCIAddress string `json:"pciAddress"`
 	PFDriver   string `json:"driver"`
 	MaxVFs     int    `json:"maxVirtualFunctions"`
-	VFs        []VF   `json:"virtualFunctions"`
+	// VFs owned by the physical function, listed after they are created
+	VFs []VF `json:"virtualFunctions"`
+	// SerialNumber is PCIe Device Serial Number of the accelerator, if it reports one
+	// +optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// Asset is metadata of the accelerator listed by its serial number in sriov-fec-asset-metadata ConfigMap
+	// +optional
+	Asset *AssetMetadata `json:"asset,omitempty"`
+}
+
+// AssetMetadata describes where the accelerator is deployed and its warranty, for fleet asset reports
+type AssetMetadata struct {
+	// +optional
+	Site string `json:"site,omitempty"`
+	// +optional
+	Rack string `json:"rack,omitempty"`
+	// WarrantyExpiry is a date the warranty of the accelerator expires, e.g. 2027-03-31
+	// +optional
+	WarrantyExpiry string `json:"warrantyExpiry,omitempty"`
+}
+
+// AcceleratorsUtilization summarizes how busy are accelerators of the node
+type AcceleratorsUtilization struct {
+	// Percentage of engines which processed code blocks since previous telemetry sample, averaged over node's accelerators
+	Percent int `json:"percent"`
+	// Time when the utilization was reported, it is updated only when utilization changes significantly
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// SkippedByAnnotation is a reason of accelerator excluded from management by the node's annotation
+const SkippedByAnnotation = "SkippedByAnnotation"
+
+// SkippedAccelerator is an accelerator present on the node, which is excluded from management
+type SkippedAccelerator struct {
+	PCIAddress string `json:"pciAddress"`
+	Reason     string `json:"reason"`
 }
 
 type NodeInventory struct {
@@ -35,6 +77,15 @@ type SriovFecNodeConfigSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// Skips drain process when true; default false. Should be true if operator is running on SNO
 	DrainSkip bool `json:"drainSkip,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// DrainPolicy decides when the node is drained unless drainSkip is true; empty value drains it before each configuration
+	DrainPolicy DrainPolicy `json:"drainPolicy,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// SyncInterval is a period the daemon re-verifies state of accelerators with, the shortest one of ClusterConfigs
+	// configuring accelerators of the node
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
 }
 
 // SriovFecNodeConfigStatus defines the observed state of SriovFecNodeConfig
@@ -45,6 +96,91 @@ type SriovFecNodeConfigStatus struct {
 	// Provides information about FPGA inventory on the node
 	// +operator-sdk:csv:customresourcedefinitions:type=status
 	Inventory NodeInventory `json:"inventory,omitempty"`
+	// Checksum of the last successfully applied spec.physicalFunctions, used to adopt
+	// already applied configuration without reconfiguring devices
+	AppliedConfigChecksum string `json:"appliedConfigChecksum,omitempty"`
+	// Last successfully applied spec.physicalFunctions, restored by the daemon when configuration of a new spec fails
+	LastKnownGoodPhysicalFunctions []PhysicalFunctionConfigExt `json:"lastKnownGoodPhysicalFunctions,omitempty"`
+	// Power management settings of PFs requesting spec.physicalFunctions[].powerManagement, read back by the daemon
+	PowerManagement []AppliedPowerManagement `json:"powerManagement,omitempty"`
+	// Accelerators present on the node, which are neither exposed in inventory nor configured
+	SkippedAccelerators []SkippedAccelerator `json:"skippedAccelerators,omitempty"`
+	// Summarized utilization of node's accelerators, reported by the daemon based on pf-bb-config telemetry
+	Utilization *AcceleratorsUtilization `json:"utilization,omitempty"`
+	// Capabilities of the node reported by the daemon, used by the operator to validate requested configuration
+	Capabilities *NodeCapabilities `json:"capabilities,omitempty"`
+	// Class of the error which failed the last configuration: ValidationError, PlatformError, TransientInfraError,
+	// DeviceError or UnknownError. Empty when the last configuration didn't fail.
+	ErrorClass string `json:"errorClass,omitempty"`
+	// ID of the node's boot the daemon verified configuration in, a new one triggers PostRebootVerification
+	BootID string `json:"bootID,omitempty"`
+	// PF drivers selected by the daemon out of spec.physicalFunctions[].pfDriverFallbacks
+	SelectedPFDrivers []SelectedPFDriver `json:"selectedPfDrivers,omitempty"`
+	// Progress of the last drain of the node before its configuration
+	Drain *DrainStatus `json:"drain,omitempty"`
+	// The last disruptive operation of each accelerator run by the daemon, sorted by PCI address
+	Operations []Operation `json:"operations,omitempty"`
+	// Generation of the spec the daemon reported the outcome of the configuration for; once it equals metadata.generation,
+	// Configured condition reports whether the latest spec was applied
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// Operation is a disruptive operation of the accelerator run by the daemon, e.g. creation of VFs along with pf_bb_config
+// run. The operation which is Started after restart of the daemon was interrupted and is run again.
+type Operation struct {
+	// Idempotency key of the operation, derived from the boot of the node, the accelerator and its requested configuration.
+	// The daemon doesn't run an operation again once it was completed under the same key.
+	Key string `json:"key"`
+	// Type of the operation: Configure, ReconfigureQueues or Clean
+	Type       string `json:"type"`
+	PCIAddress string `json:"pciAddress"`
+	// Phase of the operation: Started, Completed or Failed
+	Phase string `json:"phase"`
+	// Time the operation entered the phase
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// DrainStatus reports progress of the drain of the node
+type DrainStatus struct {
+	// Phase of the drain: Draining, Drained or Failed
+	Phase string `json:"phase"`
+	// Time the drain started, it's kept when the drain is resumed after restart of the daemon
+	StartedAt metav1.Time `json:"startedAt"`
+	// Number of pods evicted from the node and pods remaining on it
+	PodsToEvict int `json:"podsToEvict"`
+	// Number of pods evicted or deleted from the node
+	PodsEvicted int `json:"podsEvicted"`
+	// Pods remaining on the node as namespace/name, at most 10 of them are listed
+	PendingPods []string `json:"pendingPods,omitempty"`
+	// Reason of the failed drain, or a note on pods deleted according to the stuck eviction policy
+	Message string `json:"message,omitempty"`
+}
+
+// SelectedPFDriver is a driver the PF is bound to when the requested one is not available on the node
+type SelectedPFDriver struct {
+	PCIAddress string `json:"pciAddress"`
+	Driver     string `json:"driver"`
+}
+
+// AppliedPowerManagement reports power management settings of the PF
+type AppliedPowerManagement struct {
+	PCIAddress string `json:"pciAddress"`
+	// RuntimePM is power control of the PF: "on" keeps the PF powered on, "auto" allows the kernel to suspend it
+	RuntimePM string `json:"runtimePM,omitempty"`
+	// ASPMDisabled is true if all ASPM link power states of the PF are disabled
+	ASPMDisabled bool `json:"aspmDisabled"`
+	// D3ColdAllowed is true if the PF is allowed to enter D3cold power state
+	D3ColdAllowed bool `json:"d3ColdAllowed"`
+}
+
+// NodeCapabilities describes node's features required to configure accelerators
+type NodeCapabilities struct {
+	// IommuEnabled is true if IOMMU is enabled with kernel parameters
+	IommuEnabled bool `json:"iommuEnabled"`
+	// KernelLockdown is true if kernel lockdown is enabled, which allows only vfio-pci PF driver
+	KernelLockdown bool `json:"kernelLockdown"`
+	// AvailableDrivers are drivers out of vfio-pci, pci-pf-stub and igb_uio which are loaded or can be loaded on the node
+	AvailableDrivers []string `json:"availableDrivers,omitempty"`
 }
 
 // +kubebuilder:object:root=true
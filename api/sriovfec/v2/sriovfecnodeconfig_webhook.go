@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import (
+	"fmt"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var sriovfecnodeconfiglog = utils.NewLogger()
+
+func (in *SriovFecNodeConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(in).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-sriovfec-intel-com-v2-sriovfecnodeconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=sriovfec.intel.com,resources=sriovfecnodeconfigs,verbs=create;update;delete,versions=v2,name=vsriovfecnodeconfig.kb.io,admissionReviewVersions={v1}
+
+var _ webhook.Validator = &SriovFecNodeConfig{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (in *SriovFecNodeConfig) ValidateCreate() error {
+	if errs := DuplicatePhysicalFunctions(in.Spec); len(errs) != 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovFecNodeConfig").GroupKind(), in.Name, errs)
+	}
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (in *SriovFecNodeConfig) ValidateUpdate(old runtime.Object) error {
+	if _, err := in.ManualOverrideTTL(); err != nil {
+		path := field.NewPath("metadata", "annotations").Key(ManualOverrideAnnotation)
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovFecNodeConfig").GroupKind(), in.Name,
+			field.ErrorList{field.Invalid(path, in.Annotations[ManualOverrideAnnotation], err.Error())})
+	}
+
+	if errs := DuplicatePhysicalFunctions(in.Spec); len(errs) != 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovFecNodeConfig").GroupKind(), in.Name, errs)
+	}
+
+	previous, ok := old.(*SriovFecNodeConfig)
+	if !ok || !previous.IsProtected() {
+		return nil
+	}
+
+	if pruned := PrunedPhysicalFunctions(previous.Spec, in.Spec); len(pruned) != 0 {
+		sriovfecnodeconfiglog.WithField("name", in.Name).WithField("pruned", pruned).Info("rejecting update of protected SriovFecNodeConfig")
+		return apierrors.NewForbidden(GroupVersion.WithResource("sriovfecnodeconfigs").GroupResource(), in.Name,
+			fmt.Errorf("physical functions %v cannot be removed while %s annotation is set", pruned, ProtectAnnotation))
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (in *SriovFecNodeConfig) ValidateDelete() error {
+	if in.IsProtected() {
+		sriovfecnodeconfiglog.WithField("name", in.Name).Info("rejecting deletion of protected SriovFecNodeConfig")
+		return apierrors.NewForbidden(GroupVersion.WithResource("sriovfecnodeconfigs").GroupResource(), in.Name,
+			fmt.Errorf("remove %s annotation first", ProtectAnnotation))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DegradedCondition is true when configuration of the ClusterConfig cannot be rendered, e.g. SriovFecProfile it
+	// references doesn't exist (nodes keep their current configuration then), or configuration of any node it selects failed
+	DegradedCondition = "Degraded"
+	// ProfileNotFoundReason is a reason of DegradedCondition of ClusterConfig referencing missing SriovFecProfile
+	ProfileNotFoundReason = "ProfileNotFound"
+)
+
+// SriovFecProfileSpec defines the desired state of SriovFecProfile
+type SriovFecProfileSpec struct {
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// BBDevConfig referenced by ClusterConfigs with bbDevConfig.profileRef; it may name preset profile, but not
+	// another SriovFecProfile
+	BBDevConfig BBDevConfig `json:"bbDevConfig"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=sfp
+
+// SriovFecProfile is a named bbDevConfig reused by ClusterConfigs instead of inlining configuration of queues
+// +operator-sdk:csv:customresourcedefinitions:displayName="SriovFecProfile"
+type SriovFecProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SriovFecProfileSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SriovFecProfileList contains a list of SriovFecProfile
+type SriovFecProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SriovFecProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SriovFecProfile{}, &SriovFecProfileList{})
+}
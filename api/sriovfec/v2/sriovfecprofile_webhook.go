@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v2
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// log is for logging in this package.
+var sriovfecprofilelog = utils.NewLogger()
+
+func (in *SriovFecProfile) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(in).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-sriovfec-intel-com-v2-sriovfecprofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=sriovfec.intel.com,resources=sriovfecprofiles,verbs=create;update,versions=v2,name=vsriovfecprofile.kb.io,admissionReviewVersions={v1}
+
+var _ webhook.Validator = &SriovFecProfile{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (in *SriovFecProfile) ValidateCreate() error {
+	sriovfecprofilelog.WithField("name", in.Name).Info("validate create")
+	return in.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (in *SriovFecProfile) ValidateUpdate(_ runtime.Object) error {
+	sriovfecprofilelog.WithField("name", in.Name).Info("validate update")
+	return in.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (in *SriovFecProfile) ValidateDelete() error {
+	return nil
+}
+
+// validate rejects bbDevConfig of the profile which ClusterConfigs referencing it couldn't be rendered with, instead
+// of reporting it on ClusterConfigs once the profile is referenced
+func (in *SriovFecProfile) validate() error {
+	bbDevConfig := in.Spec.BBDevConfig
+	path := field.NewPath("spec", "bbDevConfig")
+
+	errs := validateBBDevConfig(bbDevConfig, path)
+	if err := hasAmbiguousBBDevConfigs(bbDevConfig, path); err != nil {
+		errs = append(errs, err)
+	}
+
+	hasCardConfig := bbDevConfig.N3000 != nil || bbDevConfig.ACC100 != nil || bbDevConfig.ACC200 != nil
+	switch {
+	case bbDevConfig.ProfileRef != "":
+		errs = append(errs, field.Forbidden(path.Child("profileRef"), "SriovFecProfile cannot reference another SriovFecProfile"))
+	case bbDevConfig.Profile != "" && hasCardConfig:
+		errs = append(errs, field.Forbidden(path.Child("profile"), "profile cannot be specified along with configuration of a particular card"))
+	case bbDevConfig.Profile == "" && !hasCardConfig:
+		errs = append(errs, field.Forbidden(path, "bbDevConfig section cannot be empty"))
+	}
+
+	// pfMode requires opt-in of the ClusterConfig, so it can't be enabled by the profile
+	if bbDevConfig.PFModeEnabled() {
+		errs = append(errs, field.Forbidden(path, "SriovFecProfile cannot enable pfMode"))
+	}
+
+	if len(errs) != 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovFecProfile").GroupKind(), in.Name, errs)
+	}
+	return nil
+}
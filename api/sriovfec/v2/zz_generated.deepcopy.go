@@ -49,6 +49,21 @@ func (in *ACC200BBDevConfig) DeepCopy() *ACC200BBDevConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcceleratorPlan) DeepCopyInto(out *AcceleratorPlan) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcceleratorPlan.
+func (in *AcceleratorPlan) DeepCopy() *AcceleratorPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(AcceleratorPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AcceleratorSelector) DeepCopyInto(out *AcceleratorSelector) {
 	*out = *in
@@ -64,6 +79,52 @@ func (in *AcceleratorSelector) DeepCopy() *AcceleratorSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcceleratorsUtilization) DeepCopyInto(out *AcceleratorsUtilization) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcceleratorsUtilization.
+func (in *AcceleratorsUtilization) DeepCopy() *AcceleratorsUtilization {
+	if in == nil {
+		return nil
+	}
+	out := new(AcceleratorsUtilization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedPowerManagement) DeepCopyInto(out *AppliedPowerManagement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedPowerManagement.
+func (in *AppliedPowerManagement) DeepCopy() *AppliedPowerManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedPowerManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssetMetadata) DeepCopyInto(out *AssetMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetMetadata.
+func (in *AssetMetadata) DeepCopy() *AssetMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(AssetMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BBDevConfig) DeepCopyInto(out *BBDevConfig) {
 	*out = *in
@@ -115,6 +176,64 @@ func (in ByPriority) DeepCopy() ByPriority {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRollout) DeepCopyInto(out *CanaryRollout) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRollout.
+func (in *CanaryRollout) DeepCopy() *CanaryRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainStatus) DeepCopyInto(out *DrainStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.PendingPods != nil {
+		in, out := &in.PendingPods, &out.PendingPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainStatus.
+func (in *DrainStatus) DeepCopy() *DrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunNodeChange) DeepCopyInto(out *DryRunNodeChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunNodeChange.
+func (in *DryRunNodeChange) DeepCopy() *DryRunNodeChange {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunNodeChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FFTLutParam) DeepCopyInto(out *FFTLutParam) {
 	*out = *in
@@ -130,6 +249,145 @@ func (in *FFTLutParam) DeepCopy() *FFTLutParam {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FecPool) DeepCopyInto(out *FecPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FecPool.
+func (in *FecPool) DeepCopy() *FecPool {
+	if in == nil {
+		return nil
+	}
+	out := new(FecPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FecPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FecPoolList) DeepCopyInto(out *FecPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FecPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FecPoolList.
+func (in *FecPoolList) DeepCopy() *FecPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(FecPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FecPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FecPoolPartition) DeepCopyInto(out *FecPoolPartition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FecPoolPartition.
+func (in *FecPoolPartition) DeepCopy() *FecPoolPartition {
+	if in == nil {
+		return nil
+	}
+	out := new(FecPoolPartition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FecPoolSpec) DeepCopyInto(out *FecPoolSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.AcceleratorSelector = in.AcceleratorSelector
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]FecPoolPartition, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FecPoolSpec.
+func (in *FecPoolSpec) DeepCopy() *FecPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FecPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FecPoolStatus) DeepCopyInto(out *FecPoolStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AssignedVFs != nil {
+		in, out := &in.AssignedVFs, &out.AssignedVFs
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FecPoolStatus.
+func (in *FecPoolStatus) DeepCopy() *FecPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FecPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *N3000BBDevConfig) DeepCopyInto(out *N3000BBDevConfig) {
 	*out = *in
@@ -147,6 +405,41 @@ func (in *N3000BBDevConfig) DeepCopy() *N3000BBDevConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeCapabilities) DeepCopyInto(out *NodeCapabilities) {
+	*out = *in
+	if in.AvailableDrivers != nil {
+		in, out := &in.AvailableDrivers, &out.AvailableDrivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCapabilities.
+func (in *NodeCapabilities) DeepCopy() *NodeCapabilities {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeCapabilities)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFailure) DeepCopyInto(out *NodeFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFailure.
+func (in *NodeFailure) DeepCopy() *NodeFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeInventory) DeepCopyInto(out *NodeInventory) {
 	*out = *in
@@ -169,49 +462,185 @@ func (in *NodeInventory) DeepCopy() *NodeInventory {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeValidationError) DeepCopyInto(out *NodeValidationError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeValidationError.
+func (in *NodeValidationError) DeepCopy() *NodeValidationError {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeValidationError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Operation) DeepCopyInto(out *Operation) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Operation.
+func (in *Operation) DeepCopy() *Operation {
+	if in == nil {
+		return nil
+	}
+	out := new(Operation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverriddenAccelerator) DeepCopyInto(out *OverriddenAccelerator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverriddenAccelerator.
+func (in *OverriddenAccelerator) DeepCopy() *OverriddenAccelerator {
+	if in == nil {
+		return nil
+	}
+	out := new(OverriddenAccelerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PhysicalFunctionConfig) DeepCopyInto(out *PhysicalFunctionConfig) {
 	*out = *in
-	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
+	if in.PFDriverFallbacks != nil {
+		in, out := &in.PFDriverFallbacks, &out.PFDriverFallbacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VFDriverOverrides != nil {
+		in, out := &in.VFDriverOverrides, &out.VFDriverOverrides
+		*out = make([]VFDriverOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.PowerManagement != nil {
+		in, out := &in.PowerManagement, &out.PowerManagement
+		*out = new(PowerManagement)
+		**out = **in
+	}
+	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalFunctionConfig.
+func (in *PhysicalFunctionConfig) DeepCopy() *PhysicalFunctionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalFunctionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PhysicalFunctionConfigExt) DeepCopyInto(out *PhysicalFunctionConfigExt) {
+	*out = *in
+	if in.PFDriverFallbacks != nil {
+		in, out := &in.PFDriverFallbacks, &out.PFDriverFallbacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VFDriverOverrides != nil {
+		in, out := &in.VFDriverOverrides, &out.VFDriverOverrides
+		*out = make([]VFDriverOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.PowerManagement != nil {
+		in, out := &in.PowerManagement, &out.PowerManagement
+		*out = new(PowerManagement)
+		**out = **in
+	}
+	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalFunctionConfigExt.
+func (in *PhysicalFunctionConfigExt) DeepCopy() *PhysicalFunctionConfigExt {
+	if in == nil {
+		return nil
+	}
+	out := new(PhysicalFunctionConfigExt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerManagement) DeepCopyInto(out *PowerManagement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerManagement.
+func (in *PowerManagement) DeepCopy() *PowerManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueueGroupConfig) DeepCopyInto(out *QueueGroupConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueGroupConfig.
+func (in *QueueGroupConfig) DeepCopy() *QueueGroupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QueueGroupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectedPFDriver) DeepCopyInto(out *SelectedPFDriver) {
+	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalFunctionConfig.
-func (in *PhysicalFunctionConfig) DeepCopy() *PhysicalFunctionConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectedPFDriver.
+func (in *SelectedPFDriver) DeepCopy() *SelectedPFDriver {
 	if in == nil {
 		return nil
 	}
-	out := new(PhysicalFunctionConfig)
+	out := new(SelectedPFDriver)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PhysicalFunctionConfigExt) DeepCopyInto(out *PhysicalFunctionConfigExt) {
+func (in *SkippedAccelerator) DeepCopyInto(out *SkippedAccelerator) {
 	*out = *in
-	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PhysicalFunctionConfigExt.
-func (in *PhysicalFunctionConfigExt) DeepCopy() *PhysicalFunctionConfigExt {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedAccelerator.
+func (in *SkippedAccelerator) DeepCopy() *SkippedAccelerator {
 	if in == nil {
 		return nil
 	}
-	out := new(PhysicalFunctionConfigExt)
+	out := new(SkippedAccelerator)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *QueueGroupConfig) DeepCopyInto(out *QueueGroupConfig) {
+func (in *SkippedNode) DeepCopyInto(out *SkippedNode) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueueGroupConfig.
-func (in *QueueGroupConfig) DeepCopy() *QueueGroupConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedNode.
+func (in *SkippedNode) DeepCopy() *SkippedNode {
 	if in == nil {
 		return nil
 	}
-	out := new(QueueGroupConfig)
+	out := new(SkippedNode)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -222,7 +651,14 @@ func (in *SriovAccelerator) DeepCopyInto(out *SriovAccelerator) {
 	if in.VFs != nil {
 		in, out := &in.VFs, &out.VFs
 		*out = make([]VF, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Asset != nil {
+		in, out := &in.Asset, &out.Asset
+		*out = new(AssetMetadata)
+		**out = **in
 	}
 }
 
@@ -242,7 +678,7 @@ func (in *SriovFecClusterConfig) DeepCopyInto(out *SriovFecClusterConfig) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecClusterConfig.
@@ -295,6 +731,64 @@ func (in *SriovFecClusterConfigList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecClusterConfigRevision) DeepCopyInto(out *SriovFecClusterConfigRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecClusterConfigRevision.
+func (in *SriovFecClusterConfigRevision) DeepCopy() *SriovFecClusterConfigRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecClusterConfigRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovFecClusterConfigRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecClusterConfigRevisionList) DeepCopyInto(out *SriovFecClusterConfigRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovFecClusterConfigRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecClusterConfigRevisionList.
+func (in *SriovFecClusterConfigRevisionList) DeepCopy() *SriovFecClusterConfigRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecClusterConfigRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovFecClusterConfigRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SriovFecClusterConfigSpec) DeepCopyInto(out *SriovFecClusterConfigSpec) {
 	*out = *in
@@ -312,6 +806,26 @@ func (in *SriovFecClusterConfigSpec) DeepCopyInto(out *SriovFecClusterConfigSpec
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryRollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(int64)
+		**out = **in
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecClusterConfigSpec.
@@ -327,6 +841,38 @@ func (in *SriovFecClusterConfigSpec) DeepCopy() *SriovFecClusterConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SriovFecClusterConfigStatus) DeepCopyInto(out *SriovFecClusterConfigStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeValidationErrors != nil {
+		in, out := &in.NodeValidationErrors, &out.NodeValidationErrors
+		*out = make([]NodeValidationError, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeFailures != nil {
+		in, out := &in.NodeFailures, &out.NodeFailures
+		*out = make([]NodeFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.OverriddenAccelerators != nil {
+		in, out := &in.OverriddenAccelerators, &out.OverriddenAccelerators
+		*out = make([]OverriddenAccelerator, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = make([]DryRunNodeChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedNodes != nil {
+		in, out := &in.SkippedNodes, &out.SkippedNodes
+		*out = make([]SkippedNode, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecClusterConfigStatus.
@@ -339,6 +885,100 @@ func (in *SriovFecClusterConfigStatus) DeepCopy() *SriovFecClusterConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecConfigPlan) DeepCopyInto(out *SriovFecConfigPlan) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecConfigPlan.
+func (in *SriovFecConfigPlan) DeepCopy() *SriovFecConfigPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecConfigPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovFecConfigPlan) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecConfigPlanList) DeepCopyInto(out *SriovFecConfigPlanList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovFecConfigPlan, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecConfigPlanList.
+func (in *SriovFecConfigPlanList) DeepCopy() *SriovFecConfigPlanList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecConfigPlanList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovFecConfigPlanList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecConfigPlanSpec) DeepCopyInto(out *SriovFecConfigPlanSpec) {
+	*out = *in
+	if in.Accelerators != nil {
+		in, out := &in.Accelerators, &out.Accelerators
+		*out = make([]AcceleratorPlan, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecConfigPlanSpec.
+func (in *SriovFecConfigPlanSpec) DeepCopy() *SriovFecConfigPlanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecConfigPlanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecConfigPlanStatus) DeepCopyInto(out *SriovFecConfigPlanStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecConfigPlanStatus.
+func (in *SriovFecConfigPlanStatus) DeepCopy() *SriovFecConfigPlanStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecConfigPlanStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SriovFecNodeConfig) DeepCopyInto(out *SriovFecNodeConfig) {
 	*out = *in
@@ -408,6 +1048,11 @@ func (in *SriovFecNodeConfigSpec) DeepCopyInto(out *SriovFecNodeConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecNodeConfigSpec.
@@ -431,6 +1076,50 @@ func (in *SriovFecNodeConfigStatus) DeepCopyInto(out *SriovFecNodeConfigStatus)
 		}
 	}
 	in.Inventory.DeepCopyInto(&out.Inventory)
+	if in.LastKnownGoodPhysicalFunctions != nil {
+		in, out := &in.LastKnownGoodPhysicalFunctions, &out.LastKnownGoodPhysicalFunctions
+		*out = make([]PhysicalFunctionConfigExt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PowerManagement != nil {
+		in, out := &in.PowerManagement, &out.PowerManagement
+		*out = make([]AppliedPowerManagement, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedAccelerators != nil {
+		in, out := &in.SkippedAccelerators, &out.SkippedAccelerators
+		*out = make([]SkippedAccelerator, len(*in))
+		copy(*out, *in)
+	}
+	if in.Utilization != nil {
+		in, out := &in.Utilization, &out.Utilization
+		*out = new(AcceleratorsUtilization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = new(NodeCapabilities)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SelectedPFDrivers != nil {
+		in, out := &in.SelectedPFDrivers, &out.SelectedPFDrivers
+		*out = make([]SelectedPFDriver, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]Operation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecNodeConfigStatus.
@@ -443,6 +1132,80 @@ func (in *SriovFecNodeConfigStatus) DeepCopy() *SriovFecNodeConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecProfile) DeepCopyInto(out *SriovFecProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecProfile.
+func (in *SriovFecProfile) DeepCopy() *SriovFecProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovFecProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecProfileList) DeepCopyInto(out *SriovFecProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SriovFecProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecProfileList.
+func (in *SriovFecProfileList) DeepCopy() *SriovFecProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SriovFecProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SriovFecProfileSpec) DeepCopyInto(out *SriovFecProfileSpec) {
+	*out = *in
+	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovFecProfileSpec.
+func (in *SriovFecProfileSpec) DeepCopy() *SriovFecProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SriovFecProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UplinkDownlink) DeepCopyInto(out *UplinkDownlink) {
 	*out = *in
@@ -477,6 +1240,11 @@ func (in *UplinkDownlinkQueues) DeepCopy() *UplinkDownlinkQueues {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VF) DeepCopyInto(out *VF) {
 	*out = *in
+	if in.Index != nil {
+		in, out := &in.Index, &out.Index
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VF.
@@ -488,3 +1256,18 @@ func (in *VF) DeepCopy() *VF {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VFDriverOverride) DeepCopyInto(out *VFDriverOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VFDriverOverride.
+func (in *VFDriverOverride) DeepCopy() *VFDriverOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(VFDriverOverride)
+	in.DeepCopyInto(out)
+	return out
+}
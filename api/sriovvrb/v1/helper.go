@@ -4,10 +4,17 @@
 package v1
 
 import (
+	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
 )
 
 type ByPriority []SriovVrbClusterConfig
@@ -28,7 +35,7 @@ func (a ByPriority) Swap(i, j int) {
 }
 
 func (s AcceleratorSelector) Matches(a SriovAccelerator) bool {
-	return s.isVendorMatching(a) && s.isPciAddressMatching(a) &&
+	return s.isVendorMatching(a) && s.isPciAddressMatching(a) && s.isPciBusMatching(a) &&
 		s.isPFDriverMatching(a) && s.isMaxVFsMatching(a) && s.isDeviceIDMatching(a)
 }
 
@@ -40,6 +47,10 @@ func (s AcceleratorSelector) isPciAddressMatching(a SriovAccelerator) bool {
 	return s.PCIAddress == "" || s.PCIAddress == a.PCIAddress
 }
 
+func (s AcceleratorSelector) isPciBusMatching(a SriovAccelerator) bool {
+	return s.PCIBus == "" || strings.HasPrefix(strings.ToLower(a.PCIAddress), strings.ToLower(s.PCIBus)+":")
+}
+
 func (s AcceleratorSelector) isPFDriverMatching(a SriovAccelerator) bool {
 	return s.PFDriver == "" || s.PFDriver == a.PFDriver
 }
@@ -52,6 +63,11 @@ func (s AcceleratorSelector) isDeviceIDMatching(a SriovAccelerator) bool {
 	return s.DeviceID == "" || s.DeviceID == a.DeviceID
 }
 
+// IsAbsent returns true if accelerators selected by the config are requested to be deconfigured
+func (in *SriovVrbClusterConfigSpec) IsAbsent() bool {
+	return in.State == CardAbsent
+}
+
 func (in *SriovVrbNodeConfig) FindCondition(conditionType string) *metav1.Condition {
 	return meta.FindStatusCondition(in.Status.Conditions, conditionType)
 }
@@ -59,3 +75,195 @@ func (in *SriovVrbNodeConfig) FindCondition(conditionType string) *metav1.Condit
 func isNil(v interface{}) bool {
 	return v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil())
 }
+
+// ProtectAnnotation set to "true" on SriovVrbNodeConfig prevents its deletion and pruning of its physical functions
+// until the annotation is removed
+const ProtectAnnotation = "sriovfec.intel.com/protect"
+
+// IsProtected returns true if SriovVrbNodeConfig is annotated with ProtectAnnotation
+func (in *SriovVrbNodeConfig) IsProtected() bool {
+	return in.GetAnnotations()[ProtectAnnotation] == "true"
+}
+
+// AdoptAnnotation set to "true" on SriovVrbNodeConfig allows the daemon to adopt VFs created out-of-band (e.g. by scripts used
+// before the operator was deployed) instead of recreating them, as long as they exactly match the requested spec.
+// Queues configuration can't be read back from the device, so adoption has to be requested explicitly.
+const AdoptAnnotation = "sriovfec.intel.com/adopt-existing"
+
+// IsAdoptionRequested returns true if SriovVrbNodeConfig is annotated with AdoptAnnotation
+func (in *SriovVrbNodeConfig) IsAdoptionRequested() bool {
+	return in.GetAnnotations()[AdoptAnnotation] == "true"
+}
+
+// OrphanedAnnotation is set to "true" on SriovVrbNodeConfig recreated by the daemon after deletion while accelerators
+// remained configured. The daemon keeps the current configuration until the operator renders the spec again and removes the annotation.
+const OrphanedAnnotation = "sriovfec.intel.com/orphaned"
+
+// IsOrphaned returns true if SriovVrbNodeConfig is annotated with OrphanedAnnotation
+func (in *SriovVrbNodeConfig) IsOrphaned() bool {
+	return in.GetAnnotations()[OrphanedAnnotation] == "true"
+}
+
+// ManualOverrideAnnotation set to a duration (e.g. "2h") on SriovVrbNodeConfig suspends enforcement of its spec by the daemon for
+// that long, so the node can be configured manually. The daemon resumes enforcement and removes the annotation once it expires.
+const ManualOverrideAnnotation = "sriovfec.intel.com/manual-override"
+
+// ManualOverrideSinceAnnotation records when the daemon observed ManualOverrideAnnotation, the override expires relative to it
+const ManualOverrideSinceAnnotation = "sriovfec.intel.com/manual-override-since"
+
+// MaxManualOverrideTTL bounds duration of the manual override
+const MaxManualOverrideTTL = 24 * time.Hour
+
+// ManualOverrideTTL returns duration of the manual override requested with ManualOverrideAnnotation, zero if not requested
+func (in *SriovVrbNodeConfig) ManualOverrideTTL() (time.Duration, error) {
+	value, ok := in.GetAnnotations()[ManualOverrideAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %w", ManualOverrideAnnotation, err)
+	}
+	if ttl <= 0 || ttl > MaxManualOverrideTTL {
+		return 0, fmt.Errorf("%s annotation must be a positive duration up to %s", ManualOverrideAnnotation, MaxManualOverrideTTL)
+	}
+	return ttl, nil
+}
+
+// ChangeSummaryAnnotation keeps human-readable summary of the last change of SriovVrbNodeConfig spec made by the operator,
+// e.g. "0000:14:00.0 vfAmount 4→8", so the intent of the change is visible in audit trails
+const ChangeSummaryAnnotation = "sriovfec.intel.com/last-change"
+
+// RenderedSpecAnnotation keeps checksum of SriovVrbNodeConfig spec last written by the operator, so modifications of the spec
+// made by anyone else are told apart from changes of ClusterConfigs
+const RenderedSpecAnnotation = "sriovfec.intel.com/rendered-spec"
+
+// MigratedFromAnnotation records name of the replaced node, which SriovVrbNodeConfig was migrated from by the operator
+// after the node was reprovisioned under a new name
+const MigratedFromAnnotation = "sriovfec.intel.com/migrated-from"
+
+// ClearHardwareFaultAnnotation set to "true" on SriovVrbNodeConfig lifts the freeze of its configuration caused by uncorrectable
+// PCIe errors of its accelerators, once the hardware was checked. The daemon removes the annotation.
+const ClearHardwareFaultAnnotation = "sriovfec.intel.com/clear-hardware-fault"
+
+// HardwareFaultBaselineAnnotation records uncorrectable PCIe errors of accelerators of SriovVrbNodeConfig at the time the freeze
+// was lifted, keyed by PCI address; only errors reported afterwards freeze the configuration again
+const HardwareFaultBaselineAnnotation = "sriovfec.intel.com/hardware-fault-baseline"
+
+// PrunedPhysicalFunctions returns PCI addresses of physical functions configured in current spec which are missing in requested one
+func PrunedPhysicalFunctions(current, requested SriovVrbNodeConfigSpec) []string {
+	requestedPCIs := map[string]bool{}
+	for _, pf := range requested.PhysicalFunctions {
+		requestedPCIs[pf.PCIAddress] = true
+	}
+
+	var pruned []string
+	for _, pf := range current.PhysicalFunctions {
+		if !requestedPCIs[pf.PCIAddress] {
+			pruned = append(pruned, pf.PCIAddress)
+		}
+	}
+	return pruned
+}
+
+// DuplicatePhysicalFunctions returns field errors of physical functions whose PCI address is already configured by
+// a preceding physical function of the spec
+func DuplicatePhysicalFunctions(spec SriovVrbNodeConfigSpec) field.ErrorList {
+	var errs field.ErrorList
+	seen := map[string]bool{}
+	for i, pf := range spec.PhysicalFunctions {
+		if seen[pf.PCIAddress] {
+			errs = append(errs, field.Duplicate(field.NewPath("spec", "physicalFunctions").Index(i).Child("pciAddress"), pf.PCIAddress))
+		}
+		seen[pf.PCIAddress] = true
+	}
+	return errs
+}
+
+// VFDriverFor returns driver the VF of given index should be bound to
+func (in *PhysicalFunctionConfigExt) VFDriverFor(index int) string {
+	for _, o := range in.VFDriverOverrides {
+		if index >= o.First && index <= o.Last {
+			return o.Driver
+		}
+	}
+	return in.VFDriver
+}
+
+// PFDrivers returns PFDriver followed by its fallbacks, in order of preference
+func (in *PhysicalFunctionConfigExt) PFDrivers() []string {
+	return append([]string{in.PFDriver}, in.PFDriverFallbacks...)
+}
+
+// VFDrivers returns all distinct drivers VFs should be bound to
+func (in *PhysicalFunctionConfigExt) VFDrivers() []string {
+	drivers := []string{in.VFDriver}
+	for _, o := range in.VFDriverOverrides {
+		if !slices.Contains(drivers, o.Driver) {
+			drivers = append(drivers, o.Driver)
+		}
+	}
+	return drivers
+}
+
+// AllowPFModeAnnotation set to "true" on SriovVrbClusterConfig allows it to enable pfMode, which bypasses SR-IOV and is
+// not supported in production
+const AllowPFModeAnnotation = "sriovfec.intel.com/allow-pf-mode"
+
+// AllowsPFMode returns true if SriovVrbClusterConfig is annotated with AllowPFModeAnnotation
+func (in *SriovVrbClusterConfig) AllowsPFMode() bool {
+	return in.GetAnnotations()[AllowPFModeAnnotation] == "true"
+}
+
+// PFModeEnabled returns true if the configuration of any card enables pfMode
+func (in *BBDevConfig) PFModeEnabled() bool {
+	return (in.VRB1 != nil && in.VRB1.PFMode) || (in.VRB2 != nil && in.VRB2.PFMode)
+}
+
+// ForceDriverChangeAnnotation set to "true" on SriovVrbClusterConfig allows its pfDriver to be changed in place, which resets
+// the accelerators and may require reboot of their nodes
+const ForceDriverChangeAnnotation = "sriovfec.intel.com/force-driver-change"
+
+// ForcesDriverChange returns true if SriovVrbClusterConfig is annotated with ForceDriverChangeAnnotation
+func (in *SriovVrbClusterConfig) ForcesDriverChange() bool {
+	return in.GetAnnotations()[ForceDriverChangeAnnotation] == "true"
+}
+
+// UnsupportedOKAnnotation set to "true" on SriovVrbClusterConfig or SriovVrbNodeConfig allows combinations of OpenShift version, accelerator,
+// PF driver and pf_bb_config version which are not listed in the support matrix, e.g. in labs
+const UnsupportedOKAnnotation = "sriovfec.intel.com/unsupported-ok"
+
+// AllowsUnsupported returns true if SriovVrbClusterConfig is annotated with UnsupportedOKAnnotation
+func (in *SriovVrbClusterConfig) AllowsUnsupported() bool {
+	return in.GetAnnotations()[UnsupportedOKAnnotation] == "true"
+}
+
+// AllowsUnsupported returns true if SriovVrbNodeConfig is annotated with UnsupportedOKAnnotation
+func (in *SriovVrbNodeConfig) AllowsUnsupported() bool {
+	return in.GetAnnotations()[UnsupportedOKAnnotation] == "true"
+}
+
+// Device returns name of the accelerator configured by bbDevConfig, as named in the support matrix, or empty string
+// when it configures none or several of them
+func (in *BBDevConfig) Device() string {
+	switch {
+	case in.VRB1 != nil && in.VRB2 == nil:
+		return "VRB1"
+	case in.VRB2 != nil && in.VRB1 == nil:
+		return "VRB2"
+	}
+	return ""
+}
+
+// UnsupportedCombination returns an error if the combination of OpenShift version of the cluster, the accelerator and PF
+// driver requested by SriovVrbClusterConfig is not listed in the support matrix
+func (in *SriovVrbClusterConfig) UnsupportedCombination() error {
+	if in.Spec.IsAbsent() {
+		return nil
+	}
+	return supportmatrix.Check(supportmatrix.Combination{
+		OpenShift: supportmatrix.OpenShiftVersion(),
+		Device:    in.Spec.PhysicalFunction.BBDevConfig.Device(),
+		PFDriver:  in.Spec.PhysicalFunction.PFDriver,
+	})
+}
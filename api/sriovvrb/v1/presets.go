@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v1
+
+import "fmt"
+
+const (
+	// ProfileFlexRAN5GDefault configures 5G, FFT (and MLD queues of VRB2) for FlexRAN
+	ProfileFlexRAN5GDefault = "flexran-5g-default"
+	// ProfileLTEOnly configures 4G queues only
+	ProfileLTEOnly = "lte-only"
+
+	// maxProfileVfAmount is the maximum amount of VF bundles of accelerators the profiles expand to
+	maxProfileVfAmount = vrb1maxVfNums
+)
+
+// presets keep bbDevConfig of profiles keyed by profile and device name, for requested amount of VFs
+var presets = map[string]map[string]func(vfAmount int) BBDevConfig{
+	ProfileFlexRAN5GDefault: {
+		"VRB1": func(vfAmount int) BBDevConfig {
+			return BBDevConfig{VRB1: &VRB1BBDevConfig{
+				ACC100BBDevConfig: presetACC100BBDevConfig(vfAmount, 0, 4),
+				QFFT:              presetQueueGroupConfig(4),
+			}}
+		},
+		"VRB2": func(vfAmount int) BBDevConfig {
+			return BBDevConfig{VRB2: &VRB2BBDevConfig{
+				ACC100BBDevConfig: presetACC100BBDevConfig(vfAmount, 0, 4),
+				QFFT:              presetQueueGroupConfig(4),
+				QMLD:              presetQueueGroupConfig(4),
+			}}
+		},
+	},
+	ProfileLTEOnly: {
+		"VRB1": func(vfAmount int) BBDevConfig {
+			return BBDevConfig{VRB1: &VRB1BBDevConfig{
+				ACC100BBDevConfig: presetACC100BBDevConfig(vfAmount, 4, 0),
+				QFFT:              presetQueueGroupConfig(0),
+			}}
+		},
+		"VRB2": func(vfAmount int) BBDevConfig {
+			return BBDevConfig{VRB2: &VRB2BBDevConfig{
+				ACC100BBDevConfig: presetACC100BBDevConfig(vfAmount, 4, 0),
+				QFFT:              presetQueueGroupConfig(0),
+				QMLD:              presetQueueGroupConfig(0),
+			}}
+		},
+	},
+}
+
+func presetACC100BBDevConfig(vfAmount, queueGroups4G, queueGroups5G int) ACC100BBDevConfig {
+	return ACC100BBDevConfig{
+		NumVfBundles: vfAmount,
+		MaxQueueSize: 1024,
+		Uplink4G:     presetQueueGroupConfig(queueGroups4G),
+		Downlink4G:   presetQueueGroupConfig(queueGroups4G),
+		Uplink5G:     presetQueueGroupConfig(queueGroups5G),
+		Downlink5G:   presetQueueGroupConfig(queueGroups5G),
+	}
+}
+
+func presetQueueGroupConfig(numQueueGroups int) QueueGroupConfig {
+	return QueueGroupConfig{NumQueueGroups: numQueueGroups, NumAqsPerGroups: 16, AqDepthLog2: 4}
+}
+
+// Expand returns bbDevConfig the profile expands to for the device (VRB1 or VRB2) with given amount of VFs;
+// bbDevConfig without profile is returned as is
+func (in BBDevConfig) Expand(device string, vfAmount int) (BBDevConfig, error) {
+	if in.Profile == "" {
+		return in, nil
+	}
+	preset, ok := presets[in.Profile][device]
+	if !ok {
+		return in, fmt.Errorf("profile %s is not supported by '%s' accelerator", in.Profile, device)
+	}
+	return preset(vfAmount), nil
+}
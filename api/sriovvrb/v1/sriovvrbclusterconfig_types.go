@@ -42,9 +42,10 @@ type QueueGroupConfig struct {
 
 // ACC100BBDevConfig specifies variables to configure ACC100 with
 type ACC100BBDevConfig struct {
+	// PFMode bypasses SR-IOV, so the accelerator is used by the PF only; it's not supported in production and requires
+	// sriovfec.intel.com/allow-pf-mode annotation of the config
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:false
-	// +kubebuilder:validation:Enum=false
 	PFMode bool `json:"pfMode,omitempty"`
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=64
@@ -102,7 +103,14 @@ func (in *VRB2BBDevConfig) Validate() error {
 
 // BBDevConfig is a struct containing configuration for various FEC cards
 type BBDevConfig struct {
+	// Profile is a name of preset configuration the operator expands to configuration of the matched accelerator;
+	// it cannot be specified along with configuration of a particular card
+	// +kubebuilder:validation:Enum=flexran-5g-default;lte-only
+	// +optional
+	Profile string `json:"profile,omitempty"`
+	// +kubebuilder:validation:XValidation:rule="self.uplink4G.numQueueGroups + self.downlink4G.numQueueGroups + self.uplink5G.numQueueGroups + self.downlink5G.numQueueGroups + self.qfft.numQueueGroups <= 16",message="total number of requested queue groups (4G/5G/QFFT) exceeds the maximum (16)"
 	VRB1 *VRB1BBDevConfig `json:"vrb1,omitempty"`
+	// +kubebuilder:validation:XValidation:rule="self.uplink4G.numQueueGroups + self.downlink4G.numQueueGroups + self.uplink5G.numQueueGroups + self.downlink5G.numQueueGroups + self.qfft.numQueueGroups + self.qmld.numQueueGroups <= 32",message="total number of requested queue groups (4G/5G/QFFT/QMLD) exceeds the maximum (32)"
 	VRB2 *VRB2BBDevConfig `json:"vrb2,omitempty"`
 }
 
@@ -127,20 +135,67 @@ func (in *BBDevConfig) Validate() error {
 	return nil
 }
 
+// PowerManagement controls power states of the accelerator's PF. Power states which are not disabled are left
+// as configured by the platform.
+type PowerManagement struct {
+	// DisableRuntimePM keeps the PF powered on instead of letting the kernel suspend it when idle
+	// +optional
+	DisableRuntimePM bool `json:"disableRuntimePM,omitempty"`
+	// DisableASPM disables ASPM link power states (L0s, L1 and its substates) of the PF. Requires ASPM link control
+	// in sysfs (kernel 5.5 or newer)
+	// +optional
+	DisableASPM bool `json:"disableASPM,omitempty"`
+	// DisableD3Cold prevents the PF from entering D3cold power state
+	// +optional
+	DisableD3Cold bool `json:"disableD3Cold,omitempty"`
+}
+
+// VFDriverOverride binds range of VFs (by VF index) to the given driver
+type VFDriverOverride struct {
+	// First is an index of the first VF in the range
+	// +kubebuilder:validation:Minimum=0
+	First int `json:"first"`
+	// Last is an index of the last VF in the range (inclusive)
+	// +kubebuilder:validation:Minimum=0
+	Last int `json:"last"`
+	// Driver to bind VFs from the range to
+	Driver string `json:"driver"`
+}
+
 // PhysicalFunctionConfig defines a possible configuration of a single Physical Function (PF), i.e. card
+// +kubebuilder:validation:XValidation:rule="!has(self.bbDevConfig.vrb1) || self.bbDevConfig.vrb1.numVfBundles == self.vfAmount",message="bbDevConfig.vrb1.numVfBundles should be the same as vfAmount"
+// +kubebuilder:validation:XValidation:rule="!has(self.bbDevConfig.vrb2) || self.bbDevConfig.vrb2.numVfBundles == self.vfAmount",message="bbDevConfig.vrb2.numVfBundles should be the same as vfAmount"
 type PhysicalFunctionConfig struct {
 	// PFDriver to bound the PFs to
 	//+kubebuilder:validation:Pattern=`(pci-pf-stub|pci_pf_stub|igb_uio|vfio-pci)`
 	PFDriver string `json:"pfDriver"`
+	// PFDriverFallbacks are drivers tried in order when PFDriver is not available on the node, e.g. in clusters
+	// mixing OS versions; the daemon binds the first available one and reports it in the NodeConfig status
+	// +optional
+	PFDriverFallbacks []string `json:"pfDriverFallbacks,omitempty"`
 	// VFDriver to bound the VFs to
 	VFDriver string `json:"vfDriver"`
 	// VFAmount is an amount of VFs to be created
 	// +kubebuilder:validation:Minimum=1
 	VFAmount int `json:"vfAmount"`
+	// VFDriverOverrides binds selected ranges of VFs to drivers other than VFDriver
+	// +optional
+	VFDriverOverrides []VFDriverOverride `json:"vfDriverOverrides,omitempty"`
+	// VFMsixCount is an amount of MSI-X interrupt vectors assigned to each VF. Requires support of the PF driver
+	// (sriov_vf_msix_count); when not set, VFs keep the device's default allocation
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	VFMsixCount int `json:"vfMsixCount,omitempty"`
+	// PowerManagement controls power states of the PF, e.g. disables deep power states for latency-critical
+	// deployments; when not set, power states are left as configured by the platform
+	// +optional
+	PowerManagement *PowerManagement `json:"powerManagement,omitempty"`
 	// BBDevConfig is a config for PF's queues
 	BBDevConfig BBDevConfig `json:"bbDevConfig"`
 }
 
+// +kubebuilder:validation:XValidation:rule="!has(self.bbDevConfig.vrb1) || self.bbDevConfig.vrb1.numVfBundles == self.vfAmount",message="bbDevConfig.vrb1.numVfBundles should be the same as vfAmount"
+// +kubebuilder:validation:XValidation:rule="!has(self.bbDevConfig.vrb2) || self.bbDevConfig.vrb2.numVfBundles == self.vfAmount",message="bbDevConfig.vrb2.numVfBundles should be the same as vfAmount"
 type PhysicalFunctionConfigExt struct {
 	// PCIAdress is a Physical Functions's PCI address that will be configured according to this spec
 	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{4}:[a-fA-F0-9]{2}:[01][a-fA-F0-9]\.[0-7]$`
@@ -149,6 +204,10 @@ type PhysicalFunctionConfigExt struct {
 	// PFDriver to bound the PFs to
 	//+kubebuilder:validation:Pattern=`(pci-pf-stub|pci_pf_stub|igb_uio|vfio-pci)`
 	PFDriver string `json:"pfDriver"`
+	// PFDriverFallbacks are drivers tried in order when PFDriver is not available on the node, e.g. in clusters
+	// mixing OS versions; the daemon binds the first available one and reports it in the NodeConfig status
+	// +optional
+	PFDriverFallbacks []string `json:"pfDriverFallbacks,omitempty"`
 
 	// VFDriver to bound the VFs to
 	VFDriver string `json:"vfDriver"`
@@ -157,6 +216,21 @@ type PhysicalFunctionConfigExt struct {
 	// +kubebuilder:validation:Minimum=0
 	VFAmount int `json:"vfAmount"`
 
+	// VFDriverOverrides binds selected ranges of VFs to drivers other than VFDriver
+	// +optional
+	VFDriverOverrides []VFDriverOverride `json:"vfDriverOverrides,omitempty"`
+
+	// VFMsixCount is an amount of MSI-X interrupt vectors assigned to each VF. Requires support of the PF driver
+	// (sriov_vf_msix_count); when not set, VFs keep the device's default allocation
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	VFMsixCount int `json:"vfMsixCount,omitempty"`
+
+	// PowerManagement controls power states of the PF, e.g. disables deep power states for latency-critical
+	// deployments; when not set, power states are left as configured by the platform
+	// +optional
+	PowerManagement *PowerManagement `json:"powerManagement,omitempty"`
+
 	// BBDevConfig is a config for PF's queues
 	BBDevConfig BBDevConfig `json:"bbDevConfig"`
 }
@@ -176,8 +250,16 @@ type SriovVrbClusterConfigSpec struct {
 	AcceleratorSelector AcceleratorSelector `json:"acceleratorSelector,omitempty"`
 
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
-	// Physical function (card) config
-	PhysicalFunction PhysicalFunctionConfig `json:"physicalFunction"`
+	// Physical function (card) config; it is not required when state is Absent
+	// +kubebuilder:validation:Optional
+	PhysicalFunction PhysicalFunctionConfig `json:"physicalFunction,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// State of selected accelerators. Absent deconfigures them, i.e. pf_bb_config is stopped and VFs are unbound and removed
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Present;Absent
+	// +kubebuilder:default:=Present
+	State CardState `json:"state,omitempty"`
 
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// Higher priority policies can override lower ones.
@@ -186,14 +268,116 @@ type SriovVrbClusterConfigSpec struct {
 	// +operator-sdk:csv:customresourcedefinitions:type=spec
 	// Skips drain process when true; default false. Should be true if operator is running on SNO
 	DrainSkip *bool `json:"drainSkip,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// DrainPolicy decides when the node is drained unless drainSkip is true. Always drains it before each configuration;
+	// IfDisruptive only when accelerators of the node already have VFs, which may be used by workloads. The node is
+	// drained if any config selecting its accelerators requires it; default Always
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Always;IfDisruptive
+	DrainPolicy DrainPolicy `json:"drainPolicy,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// Canary rolls the configuration out to canary nodes first; the rest of nodes is configured after canary nodes
+	// stay successfully configured for the soak period
+	// +kubebuilder:validation:Optional
+	Canary *CanaryRollout `json:"canary,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// DryRun renders the config into NodeConfigs of selected nodes without applying it; changes it would make are
+	// reported in status.dryRun
+	// +kubebuilder:validation:Optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// +operator-sdk:csv:customresourcedefinitions:type=spec
+	// SyncInterval is a period the operator and daemons of selected nodes re-verify state of accelerators against
+	// the spec with, including drivers PFs and VFs are bound to; at least 1m. When not set, only amount of VFs and
+	// pf_bb_config are verified every minute
+	// +kubebuilder:validation:Optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
 }
 
+// CanaryRollout selects canary nodes of the config
+type CanaryRollout struct {
+	// NodeSelector selects canary nodes among nodes targeted by the config
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+	// SoakPeriodSeconds is a time canary nodes have to stay successfully configured before the configuration is rolled
+	// out to the rest of nodes
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default:=300
+	// +optional
+	SoakPeriodSeconds int `json:"soakPeriodSeconds,omitempty"`
+}
+
+const (
+	// CanaryReadyCondition is true when all canary nodes are configured, its lastTransitionTime starts the soak period
+	CanaryReadyCondition = "CanaryReady"
+	// CanaryFailedCondition is true when configuration of any canary node failed; rollout to the rest of nodes is
+	// halted until the config is changed
+	CanaryFailedCondition = "CanaryFailed"
+	// PausedCondition is true while the config is paused with PausedAnnotation
+	PausedCondition = "Paused"
+	// ManualModificationRevertedCondition is true when the operator reverted manual modification of any SriovVrbNodeConfig
+	// the config is rendered into in the last audit interval, false once the interval passed
+	ManualModificationRevertedCondition = "ManualModificationReverted"
+
+	// Standard conditions are reported by ClusterConfigs and NodeConfigs along with legacy status fields, so they can be
+	// consumed by generic tooling
+
+	// ReadyCondition is true when configuration is applied to all nodes and none of them failed
+	ReadyCondition = "Ready"
+	// ProgressingCondition is true while configuration is being applied to any node
+	ProgressingCondition = "Progressing"
+	// DegradedCondition is true when the config cannot be rendered or configuration of any node it selects failed
+	DegradedCondition = "Degraded"
+	// IgnoredCondition is true when configuration is not applied to any accelerator, e.g. the config doesn't select
+	// any or it's a dry run
+	IgnoredCondition = "Ignored"
+	// PFModeEnabledCondition is true when the config enables pfMode, which bypasses SR-IOV and is not supported in production
+	PFModeEnabledCondition = "PFModeEnabled"
+)
+
+// PausedAnnotation set to "true" on SriovVrbClusterConfig stops propagation of its configuration into SriovVrbNodeConfigs of nodes it selects,
+// e.g. during maintenance windows when accelerators are configured manually
+const PausedAnnotation = "sriovfec.intel.com/paused"
+
+// IsPaused returns true if SriovVrbClusterConfig is annotated with PausedAnnotation
+func (in *SriovVrbClusterConfig) IsPaused() bool {
+	return in.GetAnnotations()[PausedAnnotation] == "true"
+}
+
+// DrainPolicy decides when the node is drained before configuration of its accelerators
+type DrainPolicy string
+
+const (
+	// DrainAlways drains the node before each configuration of its accelerators
+	DrainAlways DrainPolicy = "Always"
+	// DrainIfDisruptive drains the node only when its accelerators already have VFs, i.e. VFs which may be used by
+	// workloads are recreated or removed
+	DrainIfDisruptive DrainPolicy = "IfDisruptive"
+)
+
+// CardState declares whether accelerators selected by the config are configured or deconfigured
+type CardState string
+
+const (
+	// CardPresent accelerators are configured according to physicalFunction
+	CardPresent CardState = "Present"
+	// CardAbsent accelerators are deconfigured without deleting the config
+	CardAbsent CardState = "Absent"
+)
+
 type AcceleratorSelector struct {
 	VendorID string `json:"vendorID,omitempty"`
 	DeviceID string `json:"deviceID,omitempty"`
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{4}:[a-fA-F0-9]{2}:[01][a-fA-F0-9]\.[0-7]$`
 	PCIAddress string `json:"pciAddress,omitempty"`
+	// PCIBus selects all accelerators on the bus (domain:bus, e.g. 0000:3b)
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{4}:[a-fA-F0-9]{2}$`
+	PCIBus string `json:"pciBus,omitempty"`
 	//+kubebuilder:validation:Pattern=`(pci-pf-stub|pci_pf_stub|igb_uio|vfio-pci)`
 	PFDriver string `json:"driver,omitempty"`
 	MaxVFs   int    `json:"maxVirtualFunctions,omitempty"`
@@ -202,9 +386,102 @@ type AcceleratorSelector struct {
 // SriovVrbClusterConfigStatus defines the observed state of SriovVrbClusterConfig
 type SriovVrbClusterConfigStatus struct {
 	// Indicates the synchronization status of the CR
+	// Deprecated: use Ready and Degraded conditions, syncStatus is removed in the next release
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	SyncStatus SyncStatus `json:"syncStatus,omitempty"`
+	// Deprecated: use message of Degraded condition, lastSyncError is removed in the next release
+	LastSyncError string `json:"lastSyncError,omitempty"`
+	// Class of the LastSyncError: ValidationError, PlatformError, TransientInfraError, DeviceError or UnknownError
+	// Deprecated: use reason of Degraded condition, lastSyncErrorClass is removed in the next release
+	LastSyncErrorClass string `json:"lastSyncErrorClass,omitempty"`
+	// Ready, Progressing, Degraded and Ignored conditions; conditions of the canary rollout are reported only when
+	// spec.canary is set
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Nodes the configuration is not propagated to, as it isn't supported by capabilities reported by their daemons
 	// +operator-sdk:csv:customresourcedefinitions:type=status
-	SyncStatus    SyncStatus `json:"syncStatus,omitempty"`
-	LastSyncError string     `json:"lastSyncError,omitempty"`
+	NodeValidationErrors []NodeValidationError `json:"nodeValidationErrors,omitempty"`
+
+	// NodesConfigured is a number of nodes which configured accelerators selected by the config out of all such nodes,
+	// e.g. 8/10
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	NodesConfigured string `json:"nodesConfigured,omitempty"`
+
+	// Nodes which failed to configure accelerators selected by the config, sorted by node
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	NodeFailures []NodeFailure `json:"nodeFailures,omitempty"`
+
+	// Accelerators selected by the config, which are configured by another config of higher priority (or of the same
+	// priority, but created later)
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	OverriddenAccelerators []OverriddenAccelerator `json:"overriddenAccelerators,omitempty"`
+
+	// Changes of SriovVrbNodeConfigs the config would make if it wasn't a dry run, reported only when spec.dryRun is set
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	DryRun []DryRunNodeChange `json:"dryRun,omitempty"`
+
+	// Nodes selected by nodeSelector of the config, which are not configured as they don't exist or have no accelerators
+	// reported by NFD, sorted by node
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	SkippedNodes []SkippedNode `json:"skippedNodes,omitempty"`
+
+	// Generation of the config the status reports on; once it equals metadata.generation, conditions and nodesConfigured
+	// report rollout of the latest spec
+	// +operator-sdk:csv:customresourcedefinitions:type=status
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// Reasons of SkippedNode
+const (
+	// NodeNotFoundReason is reported for a node named in kubernetes.io/hostname label of nodeSelector, which doesn't exist
+	NodeNotFoundReason = "NodeNotFound"
+	// NoAcceleratorsReason is reported for a node without fpga.intel.com/intel-accelerator-present label set by NFD
+	NoAcceleratorsReason = "NoAccelerators"
+)
+
+// SkippedNode is a node selected by the config, which is not configured
+type SkippedNode struct {
+	NodeName string `json:"nodeName"`
+	// Reason of skipping the node, NodeNotFound or NoAccelerators
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// DryRunNodeChange is a change of NodeConfig of a single node rendered by dry run of the config
+type DryRunNodeChange struct {
+	NodeName string `json:"nodeName"`
+	// Changes summarizes changed fields of physical functions, in the format of sriovfec.intel.com/last-change annotation
+	Changes string `json:"changes"`
+}
+
+// OverriddenAccelerator is an accelerator selected by the config, which is configured by another config
+type OverriddenAccelerator struct {
+	NodeName   string `json:"nodeName"`
+	PCIAddress string `json:"pciAddress"`
+	// OverriddenBy is a name of the config the accelerator is configured by
+	OverriddenBy string `json:"overriddenBy"`
+}
+
+// UnschedulableReason is the reason of NodeValidationError reported for configuration not supported by the node
+const UnschedulableReason = "Unschedulable"
+
+// NodeFailure is a failure of configuration of a single node, reported by its daemon or by propagation of the
+// configuration into its NodeConfig
+type NodeFailure struct {
+	NodeName string `json:"nodeName"`
+	// Reason is the class of the error: ValidationError, PlatformError, TransientInfraError, DeviceError or UnknownError
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// NodeValidationError is an error of validation of the configuration against capabilities of a single node
+type NodeValidationError struct {
+	NodeName string `json:"nodeName"`
+	// Reason of the error, Unschedulable
+	Reason string `json:"reason"`
+	// Message lists accelerators of the node which don't support the configuration
+	Message string `json:"message"`
 }
 
 // +kubebuilder:object:root=true
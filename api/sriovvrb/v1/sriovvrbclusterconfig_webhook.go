@@ -5,8 +5,11 @@ package v1
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	"github.com/intel/sriov-fec-operator/pkg/common/webhookwarnings"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,11 +22,15 @@ import (
 var vrbclusterconfiglog = utils.NewLogger()
 
 func (r *SriovVrbClusterConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// validating webhook warning about discouraged settings is registered first, so the builder skips its own
+	mgr.GetWebhookServer().Register(validatingWebhookPath, webhookwarnings.ValidatingWebhookFor(r))
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
 
+const validatingWebhookPath = "/validate-sriovvrb-intel-com-v1-sriovvrbclusterconfig"
+
 //+kubebuilder:webhook:path=/validate-sriovvrb-intel-com-v1-sriovvrbclusterconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=sriovvrb.intel.com,resources=sriovvrbclusterconfigs,verbs=create;update,versions=v1,name=vsriovvrbclusterconfig.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &SriovVrbClusterConfig{}
@@ -31,22 +38,34 @@ var _ webhook.Validator = &SriovVrbClusterConfig{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *SriovVrbClusterConfig) ValidateCreate() error {
 	vrbclusterconfiglog.WithField("name", r.Name).Info("validate create")
-	if errs := validate(r.Spec); len(errs) != 0 {
+	if errs := append(append(validate(r.Spec), pfModeValidator(r)...), supportMatrixValidator(r)...); len(errs) != 0 {
 		return apierrors.NewInvalid(schema.GroupKind{Group: "sriovvrb.intel.com", Kind: "SriovVrbClusterConfig"}, r.Name, errs)
 	}
 	return nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *SriovVrbClusterConfig) ValidateUpdate(_ runtime.Object) error {
+func (r *SriovVrbClusterConfig) ValidateUpdate(old runtime.Object) error {
 	vrbclusterconfiglog.WithField("name", r.Name).Info("validate update")
-	if errs := validate(r.Spec); len(errs) != 0 {
+	errs := append(validate(r.Spec), pfModeValidator(r)...)
+	previous, ok := old.(*SriovVrbClusterConfig)
+	if !ok || combinationChanged(previous, r) {
+		errs = append(errs, supportMatrixValidator(r)...)
+	}
+	if ok {
+		errs = append(errs, pfDriverChangeValidator(previous, r)...)
+	}
+	if len(errs) != 0 {
 		return apierrors.NewInvalid(schema.GroupKind{Group: "sriovvrb.intel.com", Kind: "SriovVrbClusterConfig"}, r.Name, errs)
 	}
 	return nil
 }
 
 func validate(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
+	// physicalFunction is not applied to deconfigured accelerators
+	if spec.IsAbsent() {
+		return nil
+	}
 
 	validators := []func(spec SriovVrbClusterConfigSpec) field.ErrorList{
 		ambiguousBBDevConfigValidator,
@@ -55,6 +74,9 @@ func validate(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
 		vrb1NumAqsPerGroupsValidator,
 		vrb2VfAmountValidator,
 		vrb2NumQueueGroupsValidator,
+		vfDriverOverridesValidator,
+		pfDriverFallbacksValidator,
+		syncIntervalValidator,
 	}
 
 	for _, validate := range validators {
@@ -92,6 +114,10 @@ func ambiguousBBDevConfigValidator(spec SriovVrbClusterConfigSpec) (errs field.E
 		return
 	}
 
+	if spec.PhysicalFunction.BBDevConfig.Profile != "" {
+		return profileValidator(spec)
+	}
+
 	if spec.PhysicalFunction.BBDevConfig.VRB1 == nil &&
 		spec.PhysicalFunction.BBDevConfig.VRB2 == nil {
 
@@ -104,6 +130,24 @@ func ambiguousBBDevConfigValidator(spec SriovVrbClusterConfigSpec) (errs field.E
 	return
 }
 
+// profileValidator forbids configuration of a particular card along with profile, which is expanded to such
+// configuration, and amount of VFs exceeding VF bundles supported by the profiles
+func profileValidator(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
+	bbDevConfig := spec.PhysicalFunction.BBDevConfig
+	if bbDevConfig.VRB1 != nil || bbDevConfig.VRB2 != nil {
+		errs = append(errs, field.Forbidden(
+			field.NewPath("spec").Child("physicalFunction").Child("bbDevConfig").Child("profile"),
+			"profile cannot be specified along with configuration of a particular card"))
+	}
+	if spec.PhysicalFunction.VFAmount < 1 || spec.PhysicalFunction.VFAmount > maxProfileVfAmount {
+		errs = append(errs, field.Invalid(
+			field.NewPath("spec").Child("physicalFunction").Child("vfAmount"),
+			spec.PhysicalFunction.VFAmount,
+			fmt.Sprintf("profile %s supports from 1 to %d VFs", bbDevConfig.Profile, maxProfileVfAmount)))
+	}
+	return errs
+}
+
 func vrb1VfAmountValidator(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
 
 	validate := func(accConfig *VRB1BBDevConfig, vfAmount int, path *field.Path) *field.Error {
@@ -258,3 +302,108 @@ func vrb2NumQueueGroupsValidator(spec SriovVrbClusterConfigSpec) (errs field.Err
 
 	return
 }
+
+func vfDriverOverridesValidator(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
+	path := field.NewPath("spec").Child("physicalFunction").Child("vfDriverOverrides")
+	overrides := spec.PhysicalFunction.VFDriverOverrides
+	for i, o := range overrides {
+		switch {
+		case o.Driver == "":
+			errs = append(errs, field.Required(path.Index(i).Child("driver"), "driver has to be specified"))
+		case o.First < 0 || o.First > o.Last:
+			errs = append(errs, field.Invalid(path.Index(i), o, "first should not be negative nor greater than last"))
+		case o.Last >= spec.PhysicalFunction.VFAmount:
+			errs = append(errs, field.Invalid(path.Index(i).Child("last"), o.Last, "value should be lower than physicalFunction.vfAmount"))
+		}
+
+		for j := 0; j < i; j++ {
+			if o.First <= overrides[j].Last && overrides[j].First <= o.Last {
+				errs = append(errs, field.Invalid(path.Index(i), o, fmt.Sprintf("range overlaps with vfDriverOverrides[%d]", j)))
+			}
+		}
+	}
+	return errs
+}
+
+func pfDriverFallbacksValidator(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
+	path := field.NewPath("spec").Child("physicalFunction").Child("pfDriverFallbacks")
+	pfDrivers := []string{utils.PCI_PF_STUB_DASH, utils.PCI_PF_STUB_UNDERSCORE, utils.IGB_UIO, utils.VFIO_PCI}
+	requested := []string{spec.PhysicalFunction.PFDriver}
+	for i, driver := range spec.PhysicalFunction.PFDriverFallbacks {
+		switch {
+		case !slices.Contains(pfDrivers, driver):
+			errs = append(errs, field.NotSupported(path.Index(i), driver, pfDrivers))
+		case slices.Contains(requested, driver):
+			errs = append(errs, field.Duplicate(path.Index(i), driver))
+		}
+		requested = append(requested, driver)
+	}
+	return errs
+}
+
+// minSyncInterval is the shortest period accelerators can be re-verified with
+const minSyncInterval = time.Minute
+
+func syncIntervalValidator(spec SriovVrbClusterConfigSpec) (errs field.ErrorList) {
+	if spec.SyncInterval != nil && spec.SyncInterval.Duration < minSyncInterval {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("syncInterval"), spec.SyncInterval.Duration.String(),
+			fmt.Sprintf("value should be at least %s", minSyncInterval)))
+	}
+	return errs
+}
+
+// pfModeValidator rejects pfMode unless the config opts in with AllowPFModeAnnotation
+func pfModeValidator(r *SriovVrbClusterConfig) field.ErrorList {
+	if r.Spec.IsAbsent() || !r.Spec.PhysicalFunction.BBDevConfig.PFModeEnabled() || r.AllowsPFMode() {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "physicalFunction", "bbDevConfig"),
+		fmt.Sprintf("pfMode bypasses SR-IOV and is not supported in production, set %s annotation to \"true\" to enable it", AllowPFModeAnnotation))}
+}
+
+// Warnings implements webhookwarnings.Warner, so admission of the config enabling pfMode or an unsupported combination
+// allowed by UnsupportedOKAnnotation warns about it
+func (r *SriovVrbClusterConfig) Warnings() []string {
+	var warnings []string
+	if !r.Spec.IsAbsent() && r.Spec.PhysicalFunction.BBDevConfig.PFModeEnabled() {
+		warnings = append(warnings, "pfMode bypasses SR-IOV and is not supported in production")
+	}
+	if err := r.UnsupportedCombination(); err != nil && r.AllowsUnsupported() {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings
+}
+
+// supportMatrixValidator rejects combinations which are not listed in the support matrix unless the config allows them
+// with UnsupportedOKAnnotation
+func supportMatrixValidator(r *SriovVrbClusterConfig) field.ErrorList {
+	err := r.UnsupportedCombination()
+	if err == nil || r.AllowsUnsupported() {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "physicalFunction"),
+		fmt.Sprintf("%s, set %s annotation to \"true\" to allow it", err, UnsupportedOKAnnotation))}
+}
+
+// combinationChanged returns true if the update changes the accelerator or PF driver checked in the support matrix. Other
+// updates, e.g. of finalizers or annotations, are not checked, so configs created before an upgrade of the cluster to
+// a version the matrix doesn't list yet can still be updated and deleted.
+func combinationChanged(previous, r *SriovVrbClusterConfig) bool {
+	if previous.Spec.IsAbsent() || r.Spec.IsAbsent() {
+		return previous.Spec.IsAbsent() != r.Spec.IsAbsent()
+	}
+	return previous.Spec.PhysicalFunction.BBDevConfig.Device() != r.Spec.PhysicalFunction.BBDevConfig.Device() ||
+		previous.Spec.PhysicalFunction.PFDriver != r.Spec.PhysicalFunction.PFDriver
+}
+
+// pfDriverChangeValidator rejects in-place change of pfDriver, which resets the accelerators, unless the config forces
+// it with ForceDriverChangeAnnotation
+func pfDriverChangeValidator(previous, r *SriovVrbClusterConfig) field.ErrorList {
+	if previous.Spec.IsAbsent() || r.Spec.IsAbsent() || previous.Spec.PhysicalFunction.PFDriver == r.Spec.PhysicalFunction.PFDriver ||
+		r.ForcesDriverChange() {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "physicalFunction", "pfDriver"),
+		fmt.Sprintf("changing pfDriver from %s to %s resets the accelerators and may require reboot, set %s annotation to \"true\" to force it",
+			previous.Spec.PhysicalFunction.PFDriver, r.Spec.PhysicalFunction.PFDriver, ForceDriverChangeAnnotation))}
+}
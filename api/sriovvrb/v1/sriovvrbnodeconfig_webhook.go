@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var vrbnodeconfiglog = utils.NewLogger()
+
+func (r *SriovVrbNodeConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-sriovvrb-intel-com-v1-sriovvrbnodeconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=sriovvrb.intel.com,resources=sriovvrbnodeconfigs,verbs=create;update;delete,versions=v1,name=vsriovvrbnodeconfig.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &SriovVrbNodeConfig{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *SriovVrbNodeConfig) ValidateCreate() error {
+	if errs := DuplicatePhysicalFunctions(r.Spec); len(errs) != 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovVrbNodeConfig").GroupKind(), r.Name, errs)
+	}
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *SriovVrbNodeConfig) ValidateUpdate(old runtime.Object) error {
+	if _, err := r.ManualOverrideTTL(); err != nil {
+		path := field.NewPath("metadata", "annotations").Key(ManualOverrideAnnotation)
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovVrbNodeConfig").GroupKind(), r.Name,
+			field.ErrorList{field.Invalid(path, r.Annotations[ManualOverrideAnnotation], err.Error())})
+	}
+
+	if errs := DuplicatePhysicalFunctions(r.Spec); len(errs) != 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("SriovVrbNodeConfig").GroupKind(), r.Name, errs)
+	}
+
+	previous, ok := old.(*SriovVrbNodeConfig)
+	if !ok || !previous.IsProtected() {
+		return nil
+	}
+
+	if pruned := PrunedPhysicalFunctions(previous.Spec, r.Spec); len(pruned) != 0 {
+		vrbnodeconfiglog.WithField("name", r.Name).WithField("pruned", pruned).Info("rejecting update of protected SriovVrbNodeConfig")
+		return apierrors.NewForbidden(GroupVersion.WithResource("sriovvrbnodeconfigs").GroupResource(), r.Name,
+			fmt.Errorf("physical functions %v cannot be removed while %s annotation is set", pruned, ProtectAnnotation))
+	}
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *SriovVrbNodeConfig) ValidateDelete() error {
+	if r.IsProtected() {
+		vrbnodeconfiglog.WithField("name", r.Name).Info("rejecting deletion of protected SriovVrbNodeConfig")
+		return apierrors.NewForbidden(GroupVersion.WithResource("sriovvrbnodeconfigs").GroupResource(), r.Name,
+			fmt.Errorf("remove %s annotation first", ProtectAnnotation))
+	}
+	return nil
+}
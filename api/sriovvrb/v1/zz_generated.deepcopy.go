@@ -46,6 +46,52 @@ func (in *AcceleratorSelector) DeepCopy() *AcceleratorSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AcceleratorsUtilization) DeepCopyInto(out *AcceleratorsUtilization) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AcceleratorsUtilization.
+func (in *AcceleratorsUtilization) DeepCopy() *AcceleratorsUtilization {
+	if in == nil {
+		return nil
+	}
+	out := new(AcceleratorsUtilization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedPowerManagement) DeepCopyInto(out *AppliedPowerManagement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedPowerManagement.
+func (in *AppliedPowerManagement) DeepCopy() *AppliedPowerManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedPowerManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssetMetadata) DeepCopyInto(out *AssetMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetMetadata.
+func (in *AssetMetadata) DeepCopy() *AssetMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(AssetMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BBDevConfig) DeepCopyInto(out *BBDevConfig) {
 	*out = *in
@@ -92,6 +138,64 @@ func (in ByPriority) DeepCopy() ByPriority {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryRollout) DeepCopyInto(out *CanaryRollout) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryRollout.
+func (in *CanaryRollout) DeepCopy() *CanaryRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainStatus) DeepCopyInto(out *DrainStatus) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.PendingPods != nil {
+		in, out := &in.PendingPods, &out.PendingPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainStatus.
+func (in *DrainStatus) DeepCopy() *DrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunNodeChange) DeepCopyInto(out *DryRunNodeChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunNodeChange.
+func (in *DryRunNodeChange) DeepCopy() *DryRunNodeChange {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunNodeChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FFTLutParam) DeepCopyInto(out *FFTLutParam) {
 	*out = *in
@@ -107,6 +211,41 @@ func (in *FFTLutParam) DeepCopy() *FFTLutParam {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeCapabilities) DeepCopyInto(out *NodeCapabilities) {
+	*out = *in
+	if in.AvailableDrivers != nil {
+		in, out := &in.AvailableDrivers, &out.AvailableDrivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCapabilities.
+func (in *NodeCapabilities) DeepCopy() *NodeCapabilities {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeCapabilities)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFailure) DeepCopyInto(out *NodeFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFailure.
+func (in *NodeFailure) DeepCopy() *NodeFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeInventory) DeepCopyInto(out *NodeInventory) {
 	*out = *in
@@ -129,9 +268,70 @@ func (in *NodeInventory) DeepCopy() *NodeInventory {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeValidationError) DeepCopyInto(out *NodeValidationError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeValidationError.
+func (in *NodeValidationError) DeepCopy() *NodeValidationError {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeValidationError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Operation) DeepCopyInto(out *Operation) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Operation.
+func (in *Operation) DeepCopy() *Operation {
+	if in == nil {
+		return nil
+	}
+	out := new(Operation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverriddenAccelerator) DeepCopyInto(out *OverriddenAccelerator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverriddenAccelerator.
+func (in *OverriddenAccelerator) DeepCopy() *OverriddenAccelerator {
+	if in == nil {
+		return nil
+	}
+	out := new(OverriddenAccelerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PhysicalFunctionConfig) DeepCopyInto(out *PhysicalFunctionConfig) {
 	*out = *in
+	if in.PFDriverFallbacks != nil {
+		in, out := &in.PFDriverFallbacks, &out.PFDriverFallbacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VFDriverOverrides != nil {
+		in, out := &in.VFDriverOverrides, &out.VFDriverOverrides
+		*out = make([]VFDriverOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.PowerManagement != nil {
+		in, out := &in.PowerManagement, &out.PowerManagement
+		*out = new(PowerManagement)
+		**out = **in
+	}
 	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
 }
 
@@ -148,6 +348,21 @@ func (in *PhysicalFunctionConfig) DeepCopy() *PhysicalFunctionConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PhysicalFunctionConfigExt) DeepCopyInto(out *PhysicalFunctionConfigExt) {
 	*out = *in
+	if in.PFDriverFallbacks != nil {
+		in, out := &in.PFDriverFallbacks, &out.PFDriverFallbacks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VFDriverOverrides != nil {
+		in, out := &in.VFDriverOverrides, &out.VFDriverOverrides
+		*out = make([]VFDriverOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.PowerManagement != nil {
+		in, out := &in.PowerManagement, &out.PowerManagement
+		*out = new(PowerManagement)
+		**out = **in
+	}
 	in.BBDevConfig.DeepCopyInto(&out.BBDevConfig)
 }
 
@@ -161,6 +376,21 @@ func (in *PhysicalFunctionConfigExt) DeepCopy() *PhysicalFunctionConfigExt {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerManagement) DeepCopyInto(out *PowerManagement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerManagement.
+func (in *PowerManagement) DeepCopy() *PowerManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QueueGroupConfig) DeepCopyInto(out *QueueGroupConfig) {
 	*out = *in
@@ -176,13 +406,65 @@ func (in *QueueGroupConfig) DeepCopy() *QueueGroupConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectedPFDriver) DeepCopyInto(out *SelectedPFDriver) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectedPFDriver.
+func (in *SelectedPFDriver) DeepCopy() *SelectedPFDriver {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectedPFDriver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedAccelerator) DeepCopyInto(out *SkippedAccelerator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedAccelerator.
+func (in *SkippedAccelerator) DeepCopy() *SkippedAccelerator {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedAccelerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedNode) DeepCopyInto(out *SkippedNode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedNode.
+func (in *SkippedNode) DeepCopy() *SkippedNode {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SriovAccelerator) DeepCopyInto(out *SriovAccelerator) {
 	*out = *in
 	if in.VFs != nil {
 		in, out := &in.VFs, &out.VFs
 		*out = make([]VF, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Asset != nil {
+		in, out := &in.Asset, &out.Asset
+		*out = new(AssetMetadata)
+		**out = **in
 	}
 }
 
@@ -202,7 +484,7 @@ func (in *SriovVrbClusterConfig) DeepCopyInto(out *SriovVrbClusterConfig) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovVrbClusterConfig.
@@ -272,6 +554,16 @@ func (in *SriovVrbClusterConfigSpec) DeepCopyInto(out *SriovVrbClusterConfigSpec
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryRollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovVrbClusterConfigSpec.
@@ -287,6 +579,38 @@ func (in *SriovVrbClusterConfigSpec) DeepCopy() *SriovVrbClusterConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SriovVrbClusterConfigStatus) DeepCopyInto(out *SriovVrbClusterConfigStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeValidationErrors != nil {
+		in, out := &in.NodeValidationErrors, &out.NodeValidationErrors
+		*out = make([]NodeValidationError, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeFailures != nil {
+		in, out := &in.NodeFailures, &out.NodeFailures
+		*out = make([]NodeFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.OverriddenAccelerators != nil {
+		in, out := &in.OverriddenAccelerators, &out.OverriddenAccelerators
+		*out = make([]OverriddenAccelerator, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = make([]DryRunNodeChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedNodes != nil {
+		in, out := &in.SkippedNodes, &out.SkippedNodes
+		*out = make([]SkippedNode, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovVrbClusterConfigStatus.
@@ -368,6 +692,11 @@ func (in *SriovVrbNodeConfigSpec) DeepCopyInto(out *SriovVrbNodeConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovVrbNodeConfigSpec.
@@ -391,6 +720,50 @@ func (in *SriovVrbNodeConfigStatus) DeepCopyInto(out *SriovVrbNodeConfigStatus)
 		}
 	}
 	in.Inventory.DeepCopyInto(&out.Inventory)
+	if in.LastKnownGoodPhysicalFunctions != nil {
+		in, out := &in.LastKnownGoodPhysicalFunctions, &out.LastKnownGoodPhysicalFunctions
+		*out = make([]PhysicalFunctionConfigExt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PowerManagement != nil {
+		in, out := &in.PowerManagement, &out.PowerManagement
+		*out = make([]AppliedPowerManagement, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedAccelerators != nil {
+		in, out := &in.SkippedAccelerators, &out.SkippedAccelerators
+		*out = make([]SkippedAccelerator, len(*in))
+		copy(*out, *in)
+	}
+	if in.Utilization != nil {
+		in, out := &in.Utilization, &out.Utilization
+		*out = new(AcceleratorsUtilization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capabilities != nil {
+		in, out := &in.Capabilities, &out.Capabilities
+		*out = new(NodeCapabilities)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SelectedPFDrivers != nil {
+		in, out := &in.SelectedPFDrivers, &out.SelectedPFDrivers
+		*out = make([]SelectedPFDriver, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(DrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]Operation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SriovVrbNodeConfigStatus.
@@ -406,6 +779,11 @@ func (in *SriovVrbNodeConfigStatus) DeepCopy() *SriovVrbNodeConfigStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VF) DeepCopyInto(out *VF) {
 	*out = *in
+	if in.Index != nil {
+		in, out := &in.Index, &out.Index
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VF.
@@ -418,6 +796,21 @@ func (in *VF) DeepCopy() *VF {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VFDriverOverride) DeepCopyInto(out *VFDriverOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VFDriverOverride.
+func (in *VFDriverOverride) DeepCopy() *VFDriverOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(VFDriverOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VRB1BBDevConfig) DeepCopyInto(out *VRB1BBDevConfig) {
 	*out = *in
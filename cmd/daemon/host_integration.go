@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+//go:build !lab
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/google/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	"github.com/intel/sriov-fec-operator/pkg/daemon"
+)
+
+// runCliCommand runs pf_bb_config CLI command passed with -C flag; returns false if none was passed
+func runCliCommand(ctx context.Context, nodeName, ns string, directClient client.Client) bool {
+	pfBbConfigCliCmd := flag.String("C", "", "CLI command string")
+	flag.Usage = func() {
+		daemon.ShowHelp()
+	}
+	flag.Parse()
+	if *pfBbConfigCliCmd == "" {
+		return false
+	}
+	// Get the additional arguments after CLI command
+	args := flag.Args()
+	daemon.StartPfBbConfigCli(ctx, nodeName, ns, directClient, *pfBbConfigCliCmd, args, setupLog)
+	return true
+}
+
+// setupHostIntegration sets up telemetry of pf_bb_config, the commander running commands on the host and collection of
+// artifacts left on the host; returns VFIO token shared by pf_bb_config and workloads
+func setupHostIntegration(ctx context.Context, mgr manager.Manager, nodeName, ns string, directClient client.Client) (string, error) {
+	if featuregates.Enabled(featuregates.Telemetry) {
+		daemon.StartTelemetryDaemon(ctx, mgr, nodeName, ns, directClient, setupLog)
+	}
+
+	daemon.ApplyPfBBConfigWorkdir(setupLog)
+
+	if err := daemon.ApplyHostCommander(setupLog); err != nil {
+		return "", err
+	}
+
+	if err := mgr.Add(daemon.NewArtifactsCollector(utils.NewLogger())); err != nil {
+		setupLog.WithError(err).Error("unable to add artifacts collector")
+		return "", err
+	}
+
+	vfioTokenBytes, err := os.ReadFile("/sriov_config/vfiotoken")
+	if err != nil {
+		setupLog.Error(err)
+		return "", err
+	}
+
+	vfioToken, err := uuid.ParseBytes(vfioTokenBytes)
+	if err != nil {
+		setupLog.Errorf("provided vfioToken(%s) is not in UUID format: %s", vfioTokenBytes, err)
+		return "", err
+	}
+	return vfioToken.String(), nil
+}
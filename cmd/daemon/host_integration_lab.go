@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+//go:build lab
+
+package main
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/intel/sriov-fec-operator/pkg/daemon"
+)
+
+// runCliCommand is a no-op in lab build, which has no pf_bb_config to run CLI commands against
+func runCliCommand(_ context.Context, _, _ string, _ client.Client) bool {
+	return false
+}
+
+// setupHostIntegration sets up the lab environment instead of integration with the host: telemetry, the pf_bb_config
+// CLI and collection of artifacts are compiled out, queues of emulated devices are not configured by pf_bb_config and
+// no VFIO token is shared
+func setupHostIntegration(_ context.Context, _ manager.Manager, _, _ string, _ client.Client) (string, error) {
+	return "", daemon.ApplyLabEnvironment(setupLog)
+}
@@ -4,13 +4,13 @@
 package main
 
 import (
-	"flag"
+	"context"
 	"os"
 	"syscall"
 
 	"github.com/go-logr/logr"
-	"github.com/google/uuid"
 	"github.com/intel/sriov-fec-operator/pkg/common/drainhelper"
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -39,7 +39,7 @@ func init() {
 	utilruntime.Must(vrbv1.AddToScheme(scheme))
 }
 
-func initFecReconciler(mgr manager.Manager, drainHelper *drainhelper.DrainHelper, nodeNameRef types.NamespacedName,
+func initFecReconciler(ctx context.Context, mgr manager.Manager, drainer daemon.DrainAndExecute, nodeNameRef types.NamespacedName,
 	nodeConfigurer *daemon.NodeConfigurator, devicePluginController *daemon.DevicePluginController, directClient client.Client) error {
 
 	isFecDevice, _, err := utils.FindAccelerator(daemon.FecConfigPath)
@@ -51,7 +51,7 @@ func initFecReconciler(mgr manager.Manager, drainHelper *drainhelper.DrainHelper
 		return nil
 	}
 
-	reconciler, err := daemon.FecNewNodeConfigReconciler(mgr.GetClient(), drainHelper.Run, nodeNameRef, nodeConfigurer, devicePluginController.RestartDevicePlugin)
+	reconciler, err := daemon.FecNewNodeConfigReconciler(mgr.GetClient(), drainer, nodeNameRef, nodeConfigurer, devicePluginController.RestartDevicePlugin)
 	if err != nil {
 		return err
 	}
@@ -60,14 +60,14 @@ func initFecReconciler(mgr manager.Manager, drainHelper *drainhelper.DrainHelper
 		return err
 	}
 
-	if err := reconciler.CreateEmptyNodeConfigIfNeeded(directClient); err != nil {
+	if err := reconciler.CreateEmptyNodeConfigIfNeeded(ctx, directClient); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func initVrbReconciler(mgr manager.Manager, drainHelper *drainhelper.DrainHelper, nodeNameRef types.NamespacedName,
+func initVrbReconciler(ctx context.Context, mgr manager.Manager, drainer daemon.DrainAndExecute, nodeNameRef types.NamespacedName,
 	nodeConfigurer *daemon.NodeConfigurator, devicePluginController *daemon.DevicePluginController, directClient client.Client) error {
 
 	isVrbDevice, _, err := utils.FindAccelerator(daemon.VrbConfigPath)
@@ -79,7 +79,7 @@ func initVrbReconciler(mgr manager.Manager, drainHelper *drainhelper.DrainHelper
 		return nil
 	}
 
-	reconciler, err := daemon.VrbNewNodeConfigReconciler(mgr.GetClient(), drainHelper.Run, nodeNameRef, nodeConfigurer, devicePluginController.RestartDevicePlugin)
+	reconciler, err := daemon.VrbNewNodeConfigReconciler(mgr.GetClient(), drainer, nodeNameRef, nodeConfigurer, devicePluginController.RestartDevicePlugin)
 	if err != nil {
 		return err
 	}
@@ -88,7 +88,7 @@ func initVrbReconciler(mgr manager.Manager, drainHelper *drainhelper.DrainHelper
 		return err
 	}
 
-	if err := reconciler.CreateEmptyNodeConfigIfNeeded(directClient); err != nil {
+	if err := reconciler.CreateEmptyNodeConfigIfNeeded(ctx, directClient); err != nil {
 		return err
 	}
 
@@ -99,10 +99,18 @@ func main() {
 	syscall.Umask(0077)
 
 	ctrl.SetLogger(logr.New(utils.NewLogWrapper()))
+	// cancelled on SIGTERM, so the startup and in-flight configuration of the node are interrupted as well
+	ctx := ctrl.SetupSignalHandler()
 
 	nodeName := getNodeNameFromEnvOrDie()
 	ns := getSriovFecNameSpaceFromEnvOrDie()
 
+	if err := featuregates.SetFromEnv(); err != nil {
+		setupLog.WithError(err).Error("invalid feature gates")
+		os.Exit(1)
+	}
+	setupLog.WithField("featureGates", featuregates.String()).Info("feature gates")
+
 	config := ctrl.GetConfigOrDie()
 	directClient, err := client.New(config, client.Options{Scheme: scheme})
 	if err != nil {
@@ -110,15 +118,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	pfBbConfigCliCmd := flag.String("C", "", "CLI command string")
-	flag.Usage = func() {
-		daemon.ShowHelp()
-	}
-	flag.Parse()
-	if *pfBbConfigCliCmd != "" {
-		// Get the additional arguments after CLI command
-		args := flag.Args()
-		daemon.StartPfBbConfigCli(nodeName, ns, directClient, *pfBbConfigCliCmd, args, setupLog)
+	if runCliCommand(ctx, nodeName, ns, directClient) {
 		return
 	}
 
@@ -127,27 +127,24 @@ func main() {
 		setupLog.WithError(err).Error("failed to create clientset")
 		os.Exit(1)
 	}
-	mgr, err := daemon.CreateManager(config, scheme, ns, 8080, 8081, setupLog)
+	mgr, err := daemon.CreateManager(config, scheme, ns, nodeName, 8080, 8081, setupLog)
 	if err != nil {
 		setupLog.WithError(err).Error("unable to start manager")
 		os.Exit(1)
 	}
 
-	daemon.StartTelemetryDaemon(mgr, nodeName, ns, directClient, setupLog)
-
-	vfioTokenBytes, err := os.ReadFile("/sriov_config/vfiotoken")
-	if err != nil {
-		setupLog.Error(err)
-		os.Exit(1)
+	if featuregates.Enabled(featuregates.DiscoveryAPI) {
+		if err := daemon.SetupDiscovery(mgr, nodeName, setupLog); err != nil {
+			os.Exit(1)
+		}
 	}
 
-	vfioToken, err := uuid.ParseBytes(vfioTokenBytes)
+	vfioToken, err := setupHostIntegration(ctx, mgr, nodeName, ns, directClient)
 	if err != nil {
-		setupLog.Errorf("provided vfioToken(%s) is not in UUID format: %s", vfioTokenBytes, err)
 		os.Exit(1)
 	}
 
-	isSingleNodeCluster, err := utils.IsSingleNodeCluster(directClient)
+	isSingleNodeCluster, err := utils.IsSingleNodeCluster(ctx, directClient)
 	if err != nil {
 		setupLog.WithError(err).Errorf("failed to determine cluster type")
 		os.Exit(1)
@@ -155,21 +152,38 @@ func main() {
 
 	nodeNameRef := types.NamespacedName{Namespace: ns, Name: nodeName}
 	drainHelper := drainhelper.NewDrainHelper(utils.NewLogger(), cset, nodeName, ns, isSingleNodeCluster)
-	pfBBConfigController := daemon.NewPfBBConfigController(utils.NewLogger(), vfioToken.String())
+	// FEC and VRB reconcilers share the coordinator, so their reconfiguration of the node is done within a single drain
+	drainer := daemon.NewDrainCoordinator(drainHelper.Run)
+	pfBBConfigController := daemon.NewPfBBConfigController(utils.NewLogger(), vfioToken)
 	nodeConfigurer := daemon.NewNodeConfigurator(utils.NewLogger(), pfBBConfigController, mgr.GetClient(), nodeNameRef)
 	devicePluginController := daemon.NewDevicePluginController(mgr.GetClient(), utils.NewLogger(), nodeNameRef)
 
-	if err := initFecReconciler(mgr, drainHelper, nodeNameRef, nodeConfigurer, devicePluginController, directClient); err != nil {
+	if err := initFecReconciler(ctx, mgr, drainer, nodeNameRef, nodeConfigurer, devicePluginController, directClient); err != nil {
 		setupLog.WithError(err).Error("Fail to start FEC Reconciler")
 		os.Exit(1)
 	}
 
-	if err := initVrbReconciler(mgr, drainHelper, nodeNameRef, nodeConfigurer, devicePluginController, directClient); err != nil {
+	if err := initVrbReconciler(ctx, mgr, drainer, nodeNameRef, nodeConfigurer, devicePluginController, directClient); err != nil {
 		setupLog.WithError(err).Error("Fail to start VRB Reconciler")
 		os.Exit(1)
 	}
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Add(daemon.ResumeInterruptedDrain(drainer, drainHelper.IsDrainInterrupted, utils.NewLogger())); err != nil {
+		setupLog.WithError(err).Error("failed to set up resume of interrupted drain")
+		os.Exit(1)
+	}
+
+	if err := daemon.SetupStartupTaint(ctx, mgr, directClient, nodeNameRef, utils.NewLogger()); err != nil {
+		setupLog.WithError(err).Error("failed to set up startup taint")
+		os.Exit(1)
+	}
+
+	if err := daemon.SetupDevicePluginHealth(mgr, nodeNameRef, devicePluginController.RestartDevicePlugin, utils.NewLogger()); err != nil {
+		setupLog.WithError(err).Error("failed to set up device plugin health")
+		os.Exit(1)
+	}
+
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.WithError(err).Error("problem running manager")
 		os.Exit(1)
 	}
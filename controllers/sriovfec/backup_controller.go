@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// BackupActionAnnotation set on ConfigMap requests export of FEC configuration into that ConfigMap (BackupActionExport)
+	// or restoring configuration from snapshot kept in it (BackupActionRestore). Annotation is removed once action is done.
+	BackupActionAnnotation = "sriovfec.intel.com/backup-action"
+	BackupActionExport     = "export"
+	BackupActionRestore    = "restore"
+
+	// BackupSnapshotKey is a ConfigMap's data key holding the snapshot
+	BackupSnapshotKey = "snapshot.yaml"
+
+	backupConfigMapName = "sriovfec-backup"
+)
+
+// ConfigurationSnapshot is a portable copy of FEC configuration of a cluster
+type ConfigurationSnapshot struct {
+	// ClusterConfigs are restored as they are
+	ClusterConfigs []sriovfecv2.SriovFecClusterConfig `json:"clusterConfigs"`
+	// NodeConfigs are kept for reference only, they are rendered from ClusterConfigs after restore
+	NodeConfigs []NodeConfigSnapshot `json:"nodeConfigs,omitempty"`
+}
+
+// NodeConfigSnapshot is an effective configuration of a single node
+type NodeConfigSnapshot struct {
+	NodeName string                            `json:"nodeName"`
+	Spec     sriovfecv2.SriovFecNodeConfigSpec `json:"spec"`
+}
+
+// BackupReconciler exports and restores FEC configuration using ConfigMaps, which can be copied between clusters
+type BackupReconciler struct {
+	client.Client
+	Log *logrus.Logger
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;update
+
+func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cm := new(corev1.ConfigMap)
+	if err := r.Get(ctx, req.NamespacedName, cm); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	log := r.Log.WithField("configMap", cm.Name)
+
+	action := cm.GetAnnotations()[BackupActionAnnotation]
+	switch action {
+	case BackupActionExport:
+		snapshot, err := r.export(ctx)
+		if err != nil {
+			log.WithError(err).Error("failed to export configuration")
+			return ctrl.Result{}, err
+		}
+		content, err := yaml.Marshal(snapshot)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[BackupSnapshotKey] = string(content)
+		utils.SetStandardLabels(cm, backupConfigMapName, cm.Name)
+		log.WithField("clusterConfigs", len(snapshot.ClusterConfigs)).Info("configuration exported")
+	case BackupActionRestore:
+		snapshot := new(ConfigurationSnapshot)
+		if err := yaml.UnmarshalStrict([]byte(cm.Data[BackupSnapshotKey]), snapshot); err != nil {
+			log.WithError(err).Error("invalid snapshot - skipping restore")
+			// retrying would not help, annotation is kept so user can fix the snapshot
+			return ctrl.Result{}, nil
+		}
+		if err := r.restore(ctx, snapshot); err != nil {
+			log.WithError(err).Error("failed to restore configuration")
+			return ctrl.Result{}, err
+		}
+		log.WithField("clusterConfigs", len(snapshot.ClusterConfigs)).Info("configuration restored")
+	default:
+		log.WithField("action", action).Info("unsupported backup action - ignoring")
+		return ctrl.Result{}, nil
+	}
+
+	delete(cm.Annotations, BackupActionAnnotation)
+	return ctrl.Result{}, r.Update(ctx, cm)
+}
+
+func (r *BackupReconciler) export(ctx context.Context) (*ConfigurationSnapshot, error) {
+	ccl := new(sriovfecv2.SriovFecClusterConfigList)
+	if err := r.List(ctx, ccl, client.InNamespace(NAMESPACE)); err != nil {
+		return nil, err
+	}
+
+	ncl := new(sriovfecv2.SriovFecNodeConfigList)
+	if err := r.List(ctx, ncl, client.InNamespace(NAMESPACE)); err != nil {
+		return nil, err
+	}
+
+	snapshot := &ConfigurationSnapshot{ClusterConfigs: []sriovfecv2.SriovFecClusterConfig{}}
+	for _, cc := range ccl.Items {
+		snapshot.ClusterConfigs = append(snapshot.ClusterConfigs, portableClusterConfig(cc))
+	}
+	for _, nc := range ncl.Items {
+		snapshot.NodeConfigs = append(snapshot.NodeConfigs, NodeConfigSnapshot{NodeName: nc.Name, Spec: nc.Spec})
+	}
+	return snapshot, nil
+}
+
+func (r *BackupReconciler) restore(ctx context.Context, snapshot *ConfigurationSnapshot) error {
+	for _, cc := range snapshot.ClusterConfigs {
+		cc := portableClusterConfig(cc)
+		cc.Namespace = NAMESPACE
+
+		current := new(sriovfecv2.SriovFecClusterConfig)
+		err := r.Get(ctx, client.ObjectKeyFromObject(&cc), current)
+		switch {
+		case errors.IsNotFound(err):
+			if err := r.Create(ctx, &cc); err != nil {
+				return fmt.Errorf("failed to create SriovFecClusterConfig %s: %w", cc.Name, err)
+			}
+		case err != nil:
+			return err
+		default:
+			current.Spec = cc.Spec
+			if err := r.Update(ctx, current); err != nil {
+				return fmt.Errorf("failed to update SriovFecClusterConfig %s: %w", cc.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// portableClusterConfig strips all cluster specific fields from given SriovFecClusterConfig
+func portableClusterConfig(cc sriovfecv2.SriovFecClusterConfig) sriovfecv2.SriovFecClusterConfig {
+	return sriovfecv2.SriovFecClusterConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: sriovfecv2.GroupVersion.String(),
+			Kind:       "SriovFecClusterConfig",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        cc.Name,
+			Namespace:   cc.Namespace,
+			Labels:      cc.Labels,
+			Annotations: cc.Annotations,
+		},
+		Spec: cc.Spec,
+	}
+}
+
+func (r *BackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("backup").
+		For(&corev1.ConfigMap{}).
+		WithEventFilter(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			_, ok := o.GetAnnotations()[BackupActionAnnotation]
+			return ok
+		})).
+		Complete(r)
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BackupReconciler", func() {
+	reconcileBackup := func(cm *corev1.ConfigMap) {
+		reconciler := BackupReconciler{Client: k8sClient, Log: logrus.New()}
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+	}
+
+	It("exported configuration can be restored", func() {
+		cc := clusterConfigPrototype.DeepCopy()
+		cc.Name = "backup-config"
+		Expect(k8sClient.Create(context.TODO(), cc)).To(Succeed())
+
+		cm := &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "sriovfec-backup",
+				Namespace:   NAMESPACE,
+				Annotations: map[string]string{BackupActionAnnotation: BackupActionExport},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), cm)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), cm)).To(Succeed()) }()
+
+		reconcileBackup(cm)
+		Expect(cm.Annotations).ToNot(HaveKey(BackupActionAnnotation))
+
+		snapshot := new(ConfigurationSnapshot)
+		Expect(yaml.Unmarshal([]byte(cm.Data[BackupSnapshotKey]), snapshot)).To(Succeed())
+		Expect(snapshot.ClusterConfigs).To(HaveLen(1))
+		Expect(snapshot.ClusterConfigs[0].Name).To(Equal(cc.Name))
+		Expect(snapshot.ClusterConfigs[0].ResourceVersion).To(BeEmpty())
+		Expect(snapshot.ClusterConfigs[0].Spec).To(Equal(cc.Spec))
+
+		Expect(k8sClient.Delete(context.TODO(), cc)).To(Succeed())
+
+		cm.Annotations = map[string]string{BackupActionAnnotation: BackupActionRestore}
+		Expect(k8sClient.Update(context.TODO(), cm)).To(Succeed())
+		reconcileBackup(cm)
+		Expect(cm.Annotations).ToNot(HaveKey(BackupActionAnnotation))
+
+		restored := new(sriovv2.SriovFecClusterConfig)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), restored)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), restored)).To(Succeed()) }()
+		Expect(restored.Spec).To(Equal(cc.Spec))
+	})
+
+	It("keeps annotation when snapshot is invalid", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "invalid-backup",
+				Namespace:   NAMESPACE,
+				Annotations: map[string]string{BackupActionAnnotation: BackupActionRestore},
+			},
+			Data: map[string]string{BackupSnapshotKey: "clusterConfigs: {"},
+		}
+		Expect(k8sClient.Create(context.TODO(), cm)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), cm)).To(Succeed()) }()
+
+		reconcileBackup(cm)
+		Expect(cm.Annotations).To(HaveKeyWithValue(BackupActionAnnotation, BackupActionRestore))
+	})
+})
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// nodeConfiguredCondition and its reasons are reported by the daemon in SriovFecNodeConfig status
+	nodeConfiguredCondition = "Configured"
+	nodeConfiguredSucceeded = "Succeeded"
+	nodeConfiguredFailed    = "Failed"
+	// nodeConfiguredRolledBack is reported when configuration failed and the last-known-good one was restored
+	nodeConfiguredRolledBack = "RolledBack"
+	// nodeConfiguredTimeout is reported when configuration of a PF exceeded its timeout
+	nodeConfiguredTimeout = "Timeout"
+)
+
+// canaryRollout tracks rollout of a ClusterConfig with spec.canary to its canary nodes
+type canaryRollout struct {
+	cc *sriovfecv2.SriovFecClusterConfig
+	// promoted is true if canary nodes soaked, so the config is rolled out to the rest of nodes
+	promoted bool
+	// configured and inProgress are names of canary nodes
+	configured, inProgress []string
+	// failed keeps errors of failed canary nodes, keyed by node name
+	failed map[string]error
+}
+
+// canaryRollouts of ClusterConfigs with spec.canary, keyed by ClusterConfig name
+type canaryRollouts map[string]*canaryRollout
+
+func newCanaryRollouts(clusterConfigs []sriovfecv2.SriovFecClusterConfig, now time.Time) canaryRollouts {
+	rollouts := canaryRollouts{}
+	for i := range clusterConfigs {
+		cc := &clusterConfigs[i]
+		if cc.Spec.Canary == nil {
+			continue
+		}
+		rollouts[cc.Name] = &canaryRollout{cc: cc, promoted: isCanaryPromoted(cc, now), failed: map[string]error{}}
+	}
+	return rollouts
+}
+
+// isCanaryPromoted returns true if current generation of the config is configured on canary nodes for the soak period
+func isCanaryPromoted(cc *sriovfecv2.SriovFecClusterConfig, now time.Time) bool {
+	if isCanaryFailed(cc) {
+		return false
+	}
+	ready := meta.FindStatusCondition(cc.Status.Conditions, sriovfecv2.CanaryReadyCondition)
+	if ready == nil || ready.Status != metav1.ConditionTrue || ready.ObservedGeneration != cc.Generation {
+		return false
+	}
+	soakPeriod := time.Duration(cc.Spec.Canary.SoakPeriodSeconds) * time.Second
+	return !now.Before(ready.LastTransitionTime.Add(soakPeriod))
+}
+
+// isCanaryFailed returns true if current generation of the config failed on canary nodes
+func isCanaryFailed(cc *sriovfecv2.SriovFecClusterConfig) bool {
+	failed := meta.FindStatusCondition(cc.Status.Conditions, sriovfecv2.CanaryFailedCondition)
+	return failed != nil && failed.Status == metav1.ConditionTrue && failed.ObservedGeneration == cc.Generation
+}
+
+func isCanaryNode(cc sriovfecv2.SriovFecClusterConfig, node corev1.Node) bool {
+	return labels.SelectorFromSet(cc.Spec.Canary.NodeSelector).Matches(labels.Set(node.Labels))
+}
+
+// heldBy returns name of a ClusterConfig rendered into the node, which is not rolled out to the node until its canary
+// nodes soak; empty string is returned if the node can be synchronized
+func (rollouts canaryRollouts) heldBy(node corev1.Node, ncc NodeConfigurationCtx) string {
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		if rollout, ok := rollouts[cc.Name]; ok && !rollout.promoted && !isCanaryNode(cc, node) {
+			return cc.Name
+		}
+	}
+	return ""
+}
+
+// observe records state of the node in rollouts of ClusterConfigs it's a canary of
+func (rollouts canaryRollouts) observe(node corev1.Node, ncc NodeConfigurationCtx, updated bool, err error) {
+	inProgress, failure := nodeConfigurationState(ncc, updated, err)
+	observed := map[string]bool{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		rollout, ok := rollouts[cc.Name]
+		if !ok || observed[cc.Name] || !isCanaryNode(cc, node) {
+			continue
+		}
+		observed[cc.Name] = true
+
+		switch {
+		case failure != nil:
+			rollout.failed[node.Name] = failure
+		case inProgress:
+			rollout.inProgress = append(rollout.inProgress, node.Name)
+		default:
+			rollout.configured = append(rollout.configured, node.Name)
+		}
+	}
+}
+
+// conditions returns canary conditions of ClusterConfigs with spec.canary, keyed by ClusterConfig name.
+// Failures of canary nodes are recorded in syncErrors.
+func (rollouts canaryRollouts) conditions(syncErrors map[string][]error) utils.Conditions {
+	conditions := utils.Conditions{}
+	for name, rollout := range rollouts {
+		cc := rollout.cc
+		ready := metav1.Condition{Type: sriovfecv2.CanaryReadyCondition, Status: metav1.ConditionFalse, ObservedGeneration: cc.Generation}
+		failed := metav1.Condition{Type: sriovfecv2.CanaryFailedCondition, Status: metav1.ConditionFalse, ObservedGeneration: cc.Generation,
+			Reason: "Healthy", Message: "no canary node failed"}
+
+		switch {
+		case isCanaryFailed(cc):
+			previous := meta.FindStatusCondition(cc.Status.Conditions, sriovfecv2.CanaryFailedCondition)
+			failed = *previous
+			ready.Reason, ready.Message = "CanaryFailed", "rollout is halted until the config is changed"
+			syncErrors[name] = append(syncErrors[name], errclass.New(errclass.Class(previous.Reason), "%s", previous.Message))
+		case len(rollout.failed) != 0:
+			err := rollout.failure()
+			failed.Status, failed.Reason, failed.Message = metav1.ConditionTrue, string(errclass.Of(err)), err.Error()
+			ready.Reason, ready.Message = "CanaryFailed", "rollout is halted until the config is changed"
+			syncErrors[name] = append(syncErrors[name], err)
+		case len(rollout.configured)+len(rollout.inProgress) == 0:
+			ready.Reason, ready.Message = "NoCanaryNodes", "no node matches canary nodeSelector, rollout is halted"
+		case len(rollout.inProgress) != 0:
+			sort.Strings(rollout.inProgress)
+			ready.Reason = "InProgress"
+			ready.Message = fmt.Sprintf("waiting for canary nodes: %s", strings.Join(rollout.inProgress, ", "))
+		default:
+			ready.Status, ready.Reason = metav1.ConditionTrue, "Configured"
+			ready.Message = fmt.Sprintf("%d canary nodes configured, soak period %ds", len(rollout.configured), cc.Spec.Canary.SoakPeriodSeconds)
+		}
+
+		conditions.Set(name, cc.Status.Conditions, ready)
+		conditions.Set(name, cc.Status.Conditions, failed)
+	}
+	return conditions
+}
+
+// failure returns an error summarizing failed canary nodes; class of the first failed node is the class of the error
+func (r *canaryRollout) failure() error {
+	var nodes []string
+	for node := range r.failed {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	var messages []string
+	for _, node := range nodes {
+		messages = append(messages, fmt.Sprintf("node %s: %s", node, r.failed[node].Error()))
+	}
+	return errclass.New(errclass.Of(r.failed[nodes[0]]), "canary nodes failed: %s", strings.Join(messages, "; "))
+}
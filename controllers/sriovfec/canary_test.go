@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"time"
+
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+var _ = Describe("canaryRollouts", func() {
+	const pciAddress = "0000:14:00.0"
+
+	var (
+		cc        sriovfecv2.SriovFecClusterConfig
+		canary    corev1.Node
+		regular   corev1.Node
+		nodeReady = func(generation int64, reason string) NodeConfigurationCtx {
+			ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+			ncc.Generation = generation
+			ncc.Status.Conditions = []v1.Condition{{Type: nodeConfiguredCondition, Reason: reason, ObservedGeneration: generation, Message: "pf_bb_config failed"}}
+			ncc.AcceleratorConfigContext.Set(pciAddress, cc)
+			return ncc
+		}
+	)
+
+	BeforeEach(func() {
+		cc = sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "config", Namespace: NAMESPACE, Generation: 2},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				Canary: &sriovfecv2.CanaryRollout{NodeSelector: map[string]string{"canary": ""}, SoakPeriodSeconds: 60},
+			},
+		}
+		canary = corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "canary", Labels: map[string]string{"canary": ""}}}
+		regular = corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "regular"}}
+	})
+
+	It("holds non-canary nodes until canary nodes are configured", func() {
+		rollouts := newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now())
+
+		Expect(rollouts.heldBy(canary, nodeReady(1, nodeConfiguredSucceeded))).To(BeEmpty())
+		Expect(rollouts.heldBy(regular, nodeReady(1, nodeConfiguredSucceeded))).To(Equal("config"))
+
+		rollouts.observe(canary, nodeReady(1, nodeConfiguredSucceeded), true, nil)
+		conditions := rollouts.conditions(map[string][]error{})
+
+		ready := meta.FindStatusCondition(conditions["config"], sriovfecv2.CanaryReadyCondition)
+		Expect(ready).ToNot(BeNil())
+		Expect(ready.Status).To(Equal(v1.ConditionFalse))
+		Expect(ready.Reason).To(Equal("InProgress"))
+	})
+
+	It("promotes the config after canary nodes soak", func() {
+		rollouts := newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now())
+		rollouts.observe(canary, nodeReady(1, nodeConfiguredSucceeded), false, nil)
+		cc.Status.Conditions = rollouts.conditions(map[string][]error{})["config"]
+		Expect(meta.IsStatusConditionTrue(cc.Status.Conditions, sriovfecv2.CanaryReadyCondition)).To(BeTrue())
+
+		Expect(newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now()).heldBy(regular, nodeReady(1, ""))).To(Equal("config"))
+		Expect(newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now().Add(time.Minute)).heldBy(regular, nodeReady(1, ""))).To(BeEmpty())
+
+		cc.Generation++
+		Expect(newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now().Add(time.Minute)).heldBy(regular, nodeReady(1, ""))).To(Equal("config"))
+	})
+
+	It("halts the rollout when a canary node fails", func() {
+		rollouts := newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now())
+		ncc := nodeReady(1, nodeConfiguredFailed)
+		ncc.Status.ErrorClass = string(errclass.Device)
+		rollouts.observe(canary, ncc, false, nil)
+
+		syncErrors := map[string][]error{}
+		cc.Status.Conditions = rollouts.conditions(syncErrors)["config"]
+		Expect(syncErrors["config"]).To(HaveLen(1))
+		Expect(errclass.Of(syncErrors["config"][0])).To(Equal(errclass.Device))
+
+		failed := meta.FindStatusCondition(cc.Status.Conditions, sriovfecv2.CanaryFailedCondition)
+		Expect(failed.Status).To(Equal(v1.ConditionTrue))
+		Expect(failed.Reason).To(Equal(string(errclass.Device)))
+		Expect(failed.Message).To(ContainSubstring("node canary: pf_bb_config failed"))
+
+		// failure is kept until the config is changed, even if the canary node recovers
+		rollouts = newCanaryRollouts([]sriovfecv2.SriovFecClusterConfig{cc}, time.Now().Add(time.Hour))
+		rollouts.observe(canary, nodeReady(1, nodeConfiguredSucceeded), false, nil)
+		Expect(rollouts.heldBy(regular, nodeReady(1, ""))).To(Equal("config"))
+		Expect(meta.IsStatusConditionTrue(rollouts.conditions(map[string][]error{})["config"], sriovfecv2.CanaryFailedCondition)).To(BeTrue())
+	})
+})
@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// nodeCapabilities are capabilities of a single node as reported by the daemon in SriovFecNodeConfig status
+type nodeCapabilities struct {
+	// accelerators keyed by PCI address
+	accelerators map[string]sriovfecv2.SriovAccelerator
+	// features is nil if not reported by the daemon yet
+	features *sriovfecv2.NodeCapabilities
+}
+
+// nodeCapabilitiesCache keeps capabilities of nodes reported by daemons, so NodeConfigs rendered from ClusterConfigs
+// are validated before they are created or updated, instead of failing on the node
+type nodeCapabilitiesCache struct {
+	mu    sync.RWMutex
+	nodes map[string]nodeCapabilities
+}
+
+func (c *nodeCapabilitiesCache) update(nc *sriovfecv2.SriovFecNodeConfig) {
+	capabilities := nodeCapabilities{
+		accelerators: map[string]sriovfecv2.SriovAccelerator{},
+		features:     nc.Status.Capabilities.DeepCopy(),
+	}
+	for _, acc := range nc.Status.Inventory.SriovAccelerators {
+		capabilities.accelerators[acc.PCIAddress] = acc
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nodes == nil {
+		c.nodes = map[string]nodeCapabilities{}
+	}
+	c.nodes[nc.Name] = capabilities
+}
+
+func (c *nodeCapabilitiesCache) remove(nodeName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nodes, nodeName)
+}
+
+func (c *nodeCapabilitiesCache) contains(nodeName string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.nodes[nodeName]
+	return ok
+}
+
+// validate returns errors of physical functions which cannot be configured on the node, keyed by PCI address
+func (c *nodeCapabilitiesCache) validate(nodeName string, pfs []sriovfecv2.PhysicalFunctionConfigExt) map[string]error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	capabilities, ok := c.nodes[nodeName]
+	if !ok {
+		return nil
+	}
+
+	errs := map[string]error{}
+	for _, pf := range pfs {
+		if err := capabilities.validate(pf); err != nil {
+			errs[pf.PCIAddress] = &unschedulableError{nodeName: nodeName, pciAddress: pf.PCIAddress, err: err}
+		}
+	}
+	return errs
+}
+
+// validatePFDrivers returns nil if any of PF drivers, i.e. PFDriver or its fallbacks, can be bound on the node,
+// otherwise error of the requested PFDriver
+func (n nodeCapabilities) validatePFDrivers(pf sriovfecv2.PhysicalFunctionConfigExt) error {
+	var requestedErr error
+	for _, driver := range pf.PFDrivers() {
+		var err error
+		if n.features.KernelLockdown && driver != utils.VFIO_PCI {
+			err = errclass.New(errclass.Platform, "kernel lockdown is enabled, '%s' driver is not supported, use 'vfio-pci'", driver)
+		} else {
+			err = n.validateDriverAvailable(driver)
+		}
+		if err == nil {
+			return nil
+		}
+		if requestedErr == nil {
+			requestedErr = err
+		}
+	}
+	return requestedErr
+}
+
+func (n nodeCapabilities) validateDriverAvailable(driver string) error {
+	if n.features.AvailableDrivers != nil && slices.Contains(utils.KnownDrivers, driver) &&
+		!slices.Contains(n.features.AvailableDrivers, driver) {
+		return errclass.New(errclass.Platform, "'%s' driver is not available on the node", driver)
+	}
+	return nil
+}
+
+func (n nodeCapabilities) validate(pf sriovfecv2.PhysicalFunctionConfigExt) error {
+	if n.features != nil {
+		if !n.features.IommuEnabled {
+			return errclass.New(errclass.Platform, "IOMMU is not enabled")
+		}
+		if err := n.validatePFDrivers(pf); err != nil {
+			return err
+		}
+		for _, driver := range pf.VFDrivers() {
+			if err := n.validateDriverAvailable(driver); err != nil {
+				return err
+			}
+		}
+	}
+
+	acc, ok := n.accelerators[pf.PCIAddress]
+	if !ok {
+		return nil
+	}
+	if acc.MaxVFs > 0 && pf.VFAmount > acc.MaxVFs {
+		return errclass.New(errclass.Validation, "requested %d VFs exceeds %d supported by the accelerator", pf.VFAmount, acc.MaxVFs)
+	}
+	if device, known := knownDevices[acc.DeviceID]; known && !isBBDevConfigFor(device, pf.BBDevConfig) {
+		return errclass.New(errclass.Validation, "bbDevConfig does not match %s accelerator", device)
+	}
+	return nil
+}
+
+func isBBDevConfigFor(device string, config sriovfecv2.BBDevConfig) bool {
+	switch device {
+	case "FPGA_5GNR", "FPGA_LTE":
+		return config.N3000 != nil
+	case "ACC100":
+		return config.ACC100 != nil
+	case "ACC200":
+		return config.ACC200 != nil
+	}
+	return true
+}
+
+// unschedulableError is an error of configuration of the accelerator not supported by capabilities of the node
+type unschedulableError struct {
+	nodeName   string
+	pciAddress string
+	err        error
+}
+
+func (e *unschedulableError) Error() string {
+	return fmt.Sprintf("node %s, %s", e.nodeName, e.message())
+}
+
+func (e *unschedulableError) Unwrap() error {
+	return e.err
+}
+
+func (e *unschedulableError) message() string {
+	return fmt.Sprintf("accelerator %s: %v", e.pciAddress, e.err)
+}
+
+// nodeValidationErrors returns errors of nodes which capabilities don't support the configuration, one per node
+func nodeValidationErrors(errs []error) []sriovfecv2.NodeValidationError {
+	var nodeErrors []sriovfecv2.NodeValidationError
+	indexes := map[string]int{}
+	for _, err := range errs {
+		var unschedulable *unschedulableError
+		if !errors.As(err, &unschedulable) {
+			continue
+		}
+		if i, ok := indexes[unschedulable.nodeName]; ok {
+			nodeErrors[i].Message += "; " + unschedulable.message()
+			continue
+		}
+		indexes[unschedulable.nodeName] = len(nodeErrors)
+		nodeErrors = append(nodeErrors, sriovfecv2.NodeValidationError{
+			NodeName: unschedulable.nodeName,
+			Reason:   sriovfecv2.UnschedulableReason,
+			Message:  unschedulable.message(),
+		})
+	}
+	return nodeErrors
+}
+
+// eventHandler keeps the cache in sync with SriovFecNodeConfigs; it doesn't enqueue any request
+func (c *nodeCapabilitiesCache) eventHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, _ workqueue.RateLimitingInterface) {
+			if nc, ok := e.Object.(*sriovfecv2.SriovFecNodeConfig); ok {
+				c.update(nc)
+			}
+		},
+		UpdateFunc: func(e event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+			if nc, ok := e.ObjectNew.(*sriovfecv2.SriovFecNodeConfig); ok {
+				c.update(nc)
+			}
+		},
+		DeleteFunc: func(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			c.remove(e.Object.GetName())
+		},
+	}
+}
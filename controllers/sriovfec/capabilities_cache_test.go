@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var _ = Describe("nodeCapabilitiesCache", func() {
+	const pciAddress = "0000:14:00.0"
+
+	var cache nodeCapabilitiesCache
+
+	nodeConfig := func(capabilities *sriovfecv2.NodeCapabilities) *sriovfecv2.SriovFecNodeConfig {
+		nc := &sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node-1", Namespace: NAMESPACE}}
+		nc.Status.Capabilities = capabilities
+		nc.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{
+			{PCIAddress: pciAddress, DeviceID: "0d5c", MaxVFs: 16},
+		}
+		return nc
+	}
+
+	pf := func(pfDriver string, vfAmount int) sriovfecv2.PhysicalFunctionConfigExt {
+		return sriovfecv2.PhysicalFunctionConfigExt{
+			PCIAddress:  pciAddress,
+			PFDriver:    pfDriver,
+			VFAmount:    vfAmount,
+			BBDevConfig: sriovfecv2.BBDevConfig{ACC100: &sriovfecv2.ACC100BBDevConfig{}},
+		}
+	}
+
+	BeforeEach(func() {
+		cache = nodeCapabilitiesCache{}
+	})
+
+	It("accepts configuration supported by the node", func() {
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{IommuEnabled: true}))
+		Expect(cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{pf(utils.PCI_PF_STUB_DASH, 16)})).To(BeEmpty())
+	})
+
+	It("does not validate nodes which are not cached", func() {
+		Expect(cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{pf(utils.PCI_PF_STUB_DASH, 100)})).To(BeEmpty())
+	})
+
+	It("rejects configuration not supported by the node", func() {
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{IommuEnabled: true, KernelLockdown: true}))
+
+		errs := cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{pf(utils.PCI_PF_STUB_DASH, 1)})
+		Expect(errs).To(HaveKey(pciAddress))
+		Expect(errs[pciAddress].Error()).To(ContainSubstring("kernel lockdown is enabled"))
+		Expect(errclass.Of(errs[pciAddress])).To(Equal(errclass.Platform))
+
+		errs = cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{pf(utils.VFIO_PCI, 17)})
+		Expect(errs[pciAddress].Error()).To(Equal("node node-1, accelerator 0000:14:00.0: requested 17 VFs exceeds 16 supported by the accelerator"))
+		Expect(errclass.Of(errs[pciAddress])).To(Equal(errclass.Validation))
+
+		mismatched := pf(utils.VFIO_PCI, 1)
+		mismatched.BBDevConfig = sriovfecv2.BBDevConfig{ACC200: &sriovfecv2.ACC200BBDevConfig{}}
+		errs = cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{mismatched})
+		Expect(errs[pciAddress].Error()).To(ContainSubstring("bbDevConfig does not match ACC100 accelerator"))
+	})
+
+	It("rejects configuration requesting drivers not available on the node", func() {
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{IommuEnabled: true, AvailableDrivers: []string{utils.VFIO_PCI}}))
+
+		requested := pf(utils.VFIO_PCI, 1)
+		requested.VFDriver = utils.IGB_UIO
+		errs := cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{requested})
+		Expect(errs[pciAddress].Error()).To(Equal("node node-1, accelerator 0000:14:00.0: 'igb_uio' driver is not available on the node"))
+		Expect(errclass.Of(errs[pciAddress])).To(Equal(errclass.Platform))
+
+		requested.VFDriver = "custom-driver"
+		Expect(cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{requested})).To(BeEmpty())
+	})
+
+	It("accepts configuration which PF driver fallback is available on the node", func() {
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{IommuEnabled: true, AvailableDrivers: []string{utils.VFIO_PCI, utils.IGB_UIO}}))
+
+		requested := pf(utils.PCI_PF_STUB_DASH, 1)
+		requested.PFDriverFallbacks = []string{utils.IGB_UIO}
+		Expect(cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{requested})).To(BeEmpty())
+
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{IommuEnabled: true, KernelLockdown: true, AvailableDrivers: []string{utils.VFIO_PCI, utils.IGB_UIO}}))
+		errs := cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{requested})
+		Expect(errs[pciAddress].Error()).To(ContainSubstring("kernel lockdown is enabled, 'pci-pf-stub' driver is not supported"))
+
+		requested.PFDriverFallbacks = append(requested.PFDriverFallbacks, utils.VFIO_PCI)
+		Expect(cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{requested})).To(BeEmpty())
+	})
+
+	It("reports one validation error per node", func() {
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{}))
+		errs := cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{pf(utils.VFIO_PCI, 1)})
+		Expect(nodeValidationErrors([]error{errs[pciAddress], errs[pciAddress], errclass.New(errclass.Unknown, "other")})).To(Equal(
+			[]sriovfecv2.NodeValidationError{{
+				NodeName: "node-1",
+				Reason:   sriovfecv2.UnschedulableReason,
+				Message:  "accelerator 0000:14:00.0: IOMMU is not enabled; accelerator 0000:14:00.0: IOMMU is not enabled",
+			}},
+		))
+	})
+
+	It("rejects any configuration when IOMMU is disabled", func() {
+		cache.update(nodeConfig(&sriovfecv2.NodeCapabilities{}))
+		Expect(cache.validate("node-1", []sriovfecv2.PhysicalFunctionConfigExt{pf(utils.VFIO_PCI, 1)})).To(HaveKey(pciAddress))
+
+		cache.remove("node-1")
+		Expect(cache.contains("node-1")).To(BeFalse())
+	})
+})
+
+var _ = Describe("expandProfile", func() {
+	inventory := sriovfecv2.NodeInventory{SriovAccelerators: []sriovfecv2.SriovAccelerator{
+		{PCIAddress: "0000:14:00.0", DeviceID: "0d5c"},
+	}}
+
+	It("expands profile to configuration of the accelerator", func() {
+		pf := sriovfecv2.PhysicalFunctionConfigExt{PCIAddress: "0000:14:00.0", VFAmount: 4,
+			BBDevConfig: sriovfecv2.BBDevConfig{Profile: sriovfecv2.ProfileFlexRAN5GDefault}}
+		Expect(expandProfile(&pf, inventory)).To(Succeed())
+		Expect(pf.BBDevConfig.Profile).To(BeEmpty())
+		Expect(pf.BBDevConfig.ACC100).ToNot(BeNil())
+		Expect(pf.BBDevConfig.ACC100.NumVfBundles).To(Equal(4))
+	})
+
+	It("rejects profile of accelerator missing in the inventory", func() {
+		pf := sriovfecv2.PhysicalFunctionConfigExt{PCIAddress: "0000:15:00.0", VFAmount: 4,
+			BBDevConfig: sriovfecv2.BBDevConfig{Profile: sriovfecv2.ProfileLTEOnly}}
+		err := expandProfile(&pf, inventory)
+		Expect(err).To(HaveOccurred())
+		Expect(errclass.Of(err)).To(Equal(errclass.Validation))
+	})
+})
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// CertificateExpiringReason is a reason of the event emitted when serving certificate is about to expire
+	CertificateExpiringReason = "CertificateExpiring"
+	// CertificateRotatedReason is a reason of the event emitted when serving certificate was requested to be reissued
+	CertificateRotatedReason = "CertificateRotated"
+
+	DefaultCertificateRotationThreshold = 30 * 24 * time.Hour
+	DefaultCertificateCheckInterval     = time.Hour
+
+	// annotations set by issuers, which reissue certificate once its secret is removed
+	certManagerCertificateAnnotation = "cert-manager.io/certificate-name"
+	serviceCAOriginAnnotation        = "service.beta.openshift.io/originating-service-name"
+)
+
+var (
+	certificateExpiryGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sriov_fec_operator_certificate_expiry_timestamp_seconds",
+		Help: "Expiration time of the certificate serving operator's webhooks, in seconds since epoch",
+	})
+	certificateExpiringGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sriov_fec_operator_certificate_expiring",
+		Help: "Set to 1 when the certificate serving operator's webhooks expires within the rotation threshold",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(certificateExpiryGauge, certificateExpiringGauge)
+}
+
+// CertificateMonitor periodically inspects the certificate serving webhooks. It exposes its expiry as a metric,
+// emits CertificateExpiring event when expiry is closer than RotationThreshold and, if the certificate's secret is
+// managed by cert-manager or service-ca, removes the secret so the issuer rotates certificate ahead of time.
+type CertificateMonitor struct {
+	client.Client
+	// APIReader reads the secret directly, so secrets don't have to be cached by the manager
+	APIReader client.Reader
+	Log       *logrus.Logger
+	Recorder  record.EventRecorder
+
+	// CertDir is a directory the serving certificate (tls.crt) is mounted in
+	CertDir string
+	// SecretName is a name of the secret holding serving certificate in Namespace
+	SecretName string
+	Namespace  string
+
+	RotationThreshold time.Duration
+	Interval          time.Duration
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// NeedLeaderElection makes sure only one operator instance rotates the certificate
+func (m *CertificateMonitor) NeedLeaderElection() bool {
+	return true
+}
+
+func (m *CertificateMonitor) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := m.check(ctx, time.Now()); err != nil {
+			m.Log.WithError(err).Error("failed to check serving certificate")
+		}
+	}, m.Interval)
+	return nil
+}
+
+func (m *CertificateMonitor) check(ctx context.Context, now time.Time) error {
+	cert, err := readCertificate(filepath.Join(m.CertDir, corev1.TLSCertKey))
+	if err != nil {
+		return err
+	}
+
+	certificateExpiryGauge.Set(float64(cert.NotAfter.Unix()))
+	left := cert.NotAfter.Sub(now)
+	if left > m.RotationThreshold {
+		certificateExpiringGauge.Set(0)
+		return nil
+	}
+	certificateExpiringGauge.Set(1)
+
+	log := m.Log.WithField("secret", m.SecretName).WithField("notAfter", cert.NotAfter)
+	log.Warn("serving certificate is about to expire")
+
+	secret := new(corev1.Secret)
+	if err := m.APIReader.Get(ctx, client.ObjectKey{Namespace: m.Namespace, Name: m.SecretName}, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	message := fmt.Sprintf("serving certificate expires at %s", cert.NotAfter.Format(time.RFC3339))
+	m.Recorder.Event(secret, corev1.EventTypeWarning, CertificateExpiringReason, message)
+
+	if !isRotatedByIssuer(secret) {
+		log.Warn("certificate is not managed by known issuer, it has to be rotated manually")
+		return nil
+	}
+
+	if err := m.Delete(ctx, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.Info("serving certificate secret removed to be reissued")
+	m.Recorder.Event(secret, corev1.EventTypeNormal, CertificateRotatedReason, "serving certificate secret removed to be reissued")
+	return nil
+}
+
+func isRotatedByIssuer(secret *corev1.Secret) bool {
+	annotations := secret.GetAnnotations()
+	_, certManager := annotations[certManagerCertificateAnnotation]
+	_, serviceCA := annotations[serviceCAOriginAnnotation]
+	return certManager || serviceCA
+}
+
+func readCertificate(path string) (*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateMonitor", func() {
+	var (
+		certDir  string
+		recorder *record.FakeRecorder
+		monitor  *CertificateMonitor
+	)
+
+	writeCertificate := func(notAfter time.Time) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "webhook-service"},
+			NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+			NotAfter:     notAfter,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ToNot(HaveOccurred())
+		content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		Expect(os.WriteFile(filepath.Join(certDir, corev1.TLSCertKey), content, 0600)).To(Succeed())
+	}
+
+	createSecret := func(annotations map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: "webhook-server-cert", Namespace: NAMESPACE, Annotations: annotations},
+		}
+		Expect(k8sClient.Create(context.TODO(), secret)).To(Succeed())
+		return secret
+	}
+
+	BeforeEach(func() {
+		var err error
+		certDir, err = os.MkdirTemp("", "serving-certs")
+		Expect(err).ToNot(HaveOccurred())
+		recorder = record.NewFakeRecorder(10)
+		monitor = &CertificateMonitor{
+			Client:            k8sClient,
+			APIReader:         k8sClient,
+			Log:               logrus.New(),
+			Recorder:          recorder,
+			CertDir:           certDir,
+			SecretName:        "webhook-server-cert",
+			Namespace:         NAMESPACE,
+			RotationThreshold: DefaultCertificateRotationThreshold,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(certDir)).To(Succeed())
+		secret := &corev1.Secret{ObjectMeta: v1.ObjectMeta{Name: "webhook-server-cert", Namespace: NAMESPACE}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), secret))).To(Succeed())
+	})
+
+	It("reports expiry of valid certificate", func() {
+		notAfter := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+		writeCertificate(notAfter)
+		createSecret(map[string]string{certManagerCertificateAnnotation: "serving-cert"})
+
+		Expect(monitor.check(context.TODO(), time.Now())).To(Succeed())
+		Expect(testutil.ToFloat64(certificateExpiryGauge)).To(Equal(float64(notAfter.Unix())))
+		Expect(testutil.ToFloat64(certificateExpiringGauge)).To(BeZero())
+		Expect(recorder.Events).To(BeEmpty())
+
+		secret := new(corev1.Secret)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: "webhook-server-cert", Namespace: NAMESPACE}, secret)).To(Succeed())
+	})
+
+	It("rotates expiring certificate managed by cert-manager", func() {
+		writeCertificate(time.Now().Add(24 * time.Hour))
+		secret := createSecret(map[string]string{certManagerCertificateAnnotation: "serving-cert"})
+
+		Expect(monitor.check(context.TODO(), time.Now())).To(Succeed())
+		Expect(testutil.ToFloat64(certificateExpiringGauge)).To(Equal(float64(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(CertificateExpiringReason)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(CertificateRotatedReason)))
+
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("keeps expiring certificate not managed by known issuer", func() {
+		writeCertificate(time.Now().Add(24 * time.Hour))
+		secret := createSecret(nil)
+
+		Expect(monitor.check(context.TODO(), time.Now())).To(Succeed())
+		Expect(testutil.ToFloat64(certificateExpiringGauge)).To(Equal(float64(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(CertificateExpiringReason)))
+		Expect(recorder.Events).To(BeEmpty())
+
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	})
+
+	It("fails when certificate is missing", func() {
+		Expect(monitor.check(context.TODO(), time.Now())).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// configApprovalEnv enables approval of configuration changes with SriovFecConfigPlans
+const configApprovalEnv = "SRIOV_FEC_CONFIG_APPROVAL"
+
+// +kubebuilder:rbac:groups=sriovfec.intel.com,resources=sriovfecconfigplans,verbs=get;list;watch;create;update;patch;delete
+
+// configApprovalEnabled returns true if changes of NodeConfigs have to be approved with SriovFecConfigPlan
+func configApprovalEnabled() bool {
+	return strings.EqualFold(os.Getenv(configApprovalEnv), "true")
+}
+
+// newConfigPlan returns plan of applying the requested spec to the node of current NodeConfig
+func newConfigPlan(current, requested *sriovfecv2.SriovFecNodeConfig, summary string) (*sriovfecv2.SriovFecConfigPlan, error) {
+	spec, err := json.Marshal(requested.Spec)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(spec)
+
+	plan := &sriovfecv2.SriovFecConfigPlan{}
+	plan.Name = current.Name
+	plan.Namespace = NAMESPACE
+	plan.Spec.Checksum = hex.EncodeToString(checksum[:])
+	plan.Spec.Summary = summary
+	plan.Status.Phase = sriovfecv2.PlanPending
+
+	configured := map[string]bool{}
+	for _, acc := range current.Status.Inventory.SriovAccelerators {
+		configured[acc.PCIAddress] = len(acc.VFs) > 0
+	}
+
+	// the daemon configures all physical functions of the spec on any change
+	for _, pf := range requested.Spec.PhysicalFunctions {
+		action := sriovfecv2.PlannedConfigure
+		if configured[pf.PCIAddress] {
+			action = sriovfecv2.PlannedReconfigure
+		}
+		delete(configured, pf.PCIAddress)
+		plan.Spec.Accelerators = append(plan.Spec.Accelerators, sriovfecv2.AcceleratorPlan{PCIAddress: pf.PCIAddress, Action: action})
+	}
+	for _, acc := range current.Status.Inventory.SriovAccelerators {
+		if configured[acc.PCIAddress] {
+			plan.Spec.Accelerators = append(plan.Spec.Accelerators, sriovfecv2.AcceleratorPlan{PCIAddress: acc.PCIAddress, Action: sriovfecv2.PlannedDeconfigure})
+		}
+	}
+
+	plan.Spec.Drain = !requested.Spec.DrainSkip && (requested.Spec.DrainPolicy != sriovfecv2.DrainIfDisruptive ||
+		hasVFs(&current.Status.Inventory))
+	return plan, nil
+}
+
+// isChangeApproved returns true if the change of NodeConfig is approved with SriovFecConfigPlan of the node. The plan
+// is created when it doesn't exist, and regenerated with approval reset when it doesn't match the requested spec.
+func (r *SriovFecClusterConfigReconciler) isChangeApproved(ctx context.Context, current, requested *sriovfecv2.SriovFecNodeConfig, summary string) (bool, error) {
+	plan, err := newConfigPlan(current, requested, summary)
+	if err != nil {
+		return false, err
+	}
+
+	existing := new(sriovfecv2.SriovFecConfigPlan)
+	if err := r.Get(ctx, client.ObjectKeyFromObject(plan), existing); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+		r.Log.WithField("node", plan.Name).Info("SriovFecConfigPlan created, waiting for approval")
+		return false, r.Create(ctx, plan)
+	}
+
+	if existing.Spec.Checksum != plan.Spec.Checksum || existing.Status.Phase != sriovfecv2.PlanPending {
+		existing.Spec = plan.Spec
+		existing.Status = plan.Status
+		r.Log.WithField("node", plan.Name).Info("SriovFecConfigPlan regenerated, waiting for approval")
+		return false, r.Update(ctx, existing)
+	}
+	return existing.Spec.Approved, nil
+}
+
+// markPlanApplied sets Applied phase of SriovFecConfigPlan of the node once its change is written into the NodeConfig
+func (r *SriovFecClusterConfigReconciler) markPlanApplied(ctx context.Context, nodeName string) error {
+	plan := new(sriovfecv2.SriovFecConfigPlan)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: nodeName}, plan); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	plan.Status.Phase = sriovfecv2.PlanApplied
+	return r.Update(ctx, plan)
+}
+
+// deleteStalePlan removes pending SriovFecConfigPlan of the node which NodeConfig doesn't need to change anymore
+func (r *SriovFecClusterConfigReconciler) deleteStalePlan(ctx context.Context, nodeName string) error {
+	plan := new(sriovfecv2.SriovFecConfigPlan)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: nodeName}, plan); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if plan.Status.Phase != sriovfecv2.PlanPending {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, plan))
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SriovFecConfigPlan", func() {
+	var (
+		reconciler         *SriovFecClusterConfigReconciler
+		current, requested *sriovfecv2.SriovFecNodeConfig
+	)
+
+	BeforeEach(func() {
+		reconciler = &SriovFecClusterConfigReconciler{Client: k8sClient, Log: logrus.New()}
+		current = &sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node", Namespace: NAMESPACE}}
+		current.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{
+			{PCIAddress: "0000:14:00.0", VFs: []sriovfecv2.VF{{PCIAddress: "0000:14:00.1"}}},
+			{PCIAddress: "0000:15:00.0"},
+			{PCIAddress: "0000:16:00.0", VFs: []sriovfecv2.VF{{PCIAddress: "0000:16:00.1"}}},
+		}
+		requested = current.DeepCopy()
+		requested.Spec.PhysicalFunctions = []sriovfecv2.PhysicalFunctionConfigExt{
+			{PCIAddress: "0000:14:00.0", PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2},
+			{PCIAddress: "0000:15:00.0", PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.DeleteAllOf(context.TODO(), &sriovfecv2.SriovFecConfigPlan{}, client.InNamespace(NAMESPACE))).To(Succeed())
+	})
+
+	It("details actions performed on accelerators of the node", func() {
+		plan, err := newConfigPlan(current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Spec.Accelerators).To(Equal([]sriovfecv2.AcceleratorPlan{
+			{PCIAddress: "0000:14:00.0", Action: sriovfecv2.PlannedReconfigure},
+			{PCIAddress: "0000:15:00.0", Action: sriovfecv2.PlannedConfigure},
+			{PCIAddress: "0000:16:00.0", Action: sriovfecv2.PlannedDeconfigure},
+		}))
+		Expect(plan.Spec.Drain).To(BeTrue())
+
+		requested.Spec.DrainSkip = true
+		plan, err = newConfigPlan(current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Spec.Drain).To(BeFalse())
+	})
+
+	It("plans drain with IfDisruptive policy only when VFs are recreated", func() {
+		current.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{{PCIAddress: "0000:14:00.0"}, {PCIAddress: "0000:15:00.0"}}
+		requested.Spec.DrainPolicy = sriovfecv2.DrainIfDisruptive
+		plan, err := newConfigPlan(current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(plan.Spec.Drain).To(BeFalse())
+	})
+
+	It("approves change only after the plan is approved", func() {
+		approved, err := reconciler.isChangeApproved(context.TODO(), current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+
+		plan := new(sriovfecv2.SriovFecConfigPlan)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: "node"}, plan)).To(Succeed())
+		Expect(plan.Status.Phase).To(Equal(sriovfecv2.PlanPending))
+		plan.Spec.Approved = true
+		Expect(k8sClient.Update(context.TODO(), plan)).To(Succeed())
+
+		approved, err = reconciler.isChangeApproved(context.TODO(), current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeTrue())
+
+		Expect(reconciler.markPlanApplied(context.TODO(), "node")).To(Succeed())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: "node"}, plan)).To(Succeed())
+		Expect(plan.Status.Phase).To(Equal(sriovfecv2.PlanApplied))
+	})
+
+	It("resets approval when requested configuration changes", func() {
+		_, err := reconciler.isChangeApproved(context.TODO(), current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		plan := new(sriovfecv2.SriovFecConfigPlan)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: "node"}, plan)).To(Succeed())
+		plan.Spec.Approved = true
+		Expect(k8sClient.Update(context.TODO(), plan)).To(Succeed())
+
+		requested.Spec.PhysicalFunctions[0].VFAmount = 4
+		approved, err := reconciler.isChangeApproved(context.TODO(), current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(approved).To(BeFalse())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: "node"}, plan)).To(Succeed())
+		Expect(plan.Spec.Approved).To(BeFalse())
+	})
+
+	It("deletes pending plan of node which doesn't need to change", func() {
+		_, err := reconciler.isChangeApproved(context.TODO(), current, requested, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.deleteStalePlan(context.TODO(), "node")).To(Succeed())
+		plans := new(sriovfecv2.SriovFecConfigPlanList)
+		Expect(k8sClient.List(context.TODO(), plans, client.InNamespace(NAMESPACE))).To(Succeed())
+		Expect(plans.Items).To(BeEmpty())
+	})
+})
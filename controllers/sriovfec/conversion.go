@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv1 "github.com/intel/sriov-fec-operator/api/sriovfec/v1"
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// SplitConvertedClusterConfigs replaces SriovFecClusterConfigs converted from v1 configs of several physical functions
+// with a config per physical function. Configs of the rest of physical functions are created before the converted one is
+// updated, so the split can be retried.
+func SplitConvertedClusterConfigs(ctx context.Context, c client.Client, log *logrus.Logger) error {
+	clusterConfigs := new(sriovfecv2.SriovFecClusterConfigList)
+	if err := c.List(ctx, clusterConfigs); err != nil {
+		return err
+	}
+
+	for i := range clusterConfigs.Items {
+		configs, err := sriovfecv1.SplitClusterConfig(&clusterConfigs.Items[i])
+		if err != nil {
+			return err
+		}
+		if len(configs) == 0 {
+			continue
+		}
+		for j := 1; j < len(configs); j++ {
+			if err := c.Create(ctx, &configs[j]); client.IgnoreAlreadyExists(err) != nil {
+				return err
+			}
+		}
+		if err := c.Update(ctx, &configs[0]); err != nil {
+			return err
+		}
+		log.WithField("name", configs[0].Name).WithField("configs", len(configs)).
+			Info("SriovFecClusterConfig converted from v1 split into configs of its physical functions")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv1 "github.com/intel/sriov-fec-operator/api/sriovfec/v1"
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("SplitConvertedClusterConfigs", func() {
+	It("splits configs converted from v1 into configs of their physical functions", func() {
+		converted := &sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "config", Namespace: NAMESPACE,
+			Annotations: map[string]string{sriovfecv1.V1SpecAnnotation: `{"nodes":[{"nodeName":"node1","physicalFunctions":[` +
+				`{"pciAddress":"0000:14:00.0","pfDriver":"pci-pf-stub","vfDriver":"vfio-pci","vfAmount":2,"bbDevConfig":{}},` +
+				`{"pciAddress":"0000:15:00.0","pfDriver":"pci-pf-stub","vfDriver":"vfio-pci","vfAmount":4,"bbDevConfig":{}}]}]}`}}}
+		untouched := &sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "untouched", Namespace: NAMESPACE}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(converted, untouched).Build()
+
+		Expect(SplitConvertedClusterConfigs(context.TODO(), c, logrus.New())).To(Succeed())
+		// the split is idempotent
+		Expect(SplitConvertedClusterConfigs(context.TODO(), c, logrus.New())).To(Succeed())
+
+		configs := new(sriovfecv2.SriovFecClusterConfigList)
+		Expect(c.List(context.TODO(), configs, client.InNamespace(NAMESPACE))).To(Succeed())
+		Expect(configs.Items).To(HaveLen(3))
+		for _, cc := range configs.Items {
+			Expect(cc.Annotations).ToNot(HaveKey(sriovfecv1.V1SpecAnnotation))
+			switch cc.Name {
+			case "config":
+				Expect(cc.Spec.PhysicalFunction.VFAmount).To(Equal(2))
+			case "config-1":
+				Expect(cc.Spec.PhysicalFunction.VFAmount).To(Equal(4))
+				Expect(cc.Spec.AcceleratorSelector.PCIAddress).To(Equal("0000:15:00.0"))
+			default:
+				Expect(cc.Name).To(Equal("untouched"))
+			}
+		}
+	})
+})
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// hasVFs returns true if any accelerator of the inventory has VFs. The daemon configures all accelerators of the node
+// on any change of its NodeConfig spec, so the change recreates or removes VFs which may be used by workloads.
+func hasVFs(inventory *sriovfecv2.NodeInventory) bool {
+	for _, acc := range inventory.SriovAccelerators {
+		if len(acc.VFs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DryRun", func() {
+	var (
+		reconciler *SriovFecClusterConfigReconciler
+		matcher    *clusterConfigMatcher
+		node       corev1.Node
+		applied    sriovfecv2.SriovFecClusterConfig
+		dryRun     sriovfecv2.SriovFecClusterConfig
+	)
+
+	clusterConfig := func(name string, priority, vfAmount int) sriovfecv2.SriovFecClusterConfig {
+		return sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				Priority:         priority,
+				PhysicalFunction: sriovfecv2.PhysicalFunctionConfig{PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: vfAmount},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		reconciler = &SriovFecClusterConfigReconciler{Log: logrus.New()}
+		node = corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node"}}
+		applied = clusterConfig("applied", 1, 2)
+		dryRun = clusterConfig("preview", 2, 4)
+		dryRun.Spec.DryRun = true
+
+		nodeConfig := &sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node"}}
+		nodeConfig.Spec.PhysicalFunctions = []sriovfecv2.PhysicalFunctionConfigExt{
+			{PCIAddress: "0000:14:00.0", PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2},
+		}
+		nodeConfig.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{{PCIAddress: "0000:14:00.0", MaxVFs: 16}}
+		matcher = createClusterConfigMatcher(func(string) (*sriovfecv2.SriovFecNodeConfig, error) { return nodeConfig, nil }, logrus.New())
+	})
+
+	It("separates dry runs from applied configs", func() {
+		configs, dryRuns := splitDryRuns([]sriovfecv2.SriovFecClusterConfig{applied, dryRun})
+		Expect(configs).To(ConsistOf(applied))
+		Expect(dryRuns).To(ConsistOf(dryRun))
+	})
+
+	It("reports changes of NodeConfig the dry run would make", func() {
+		changes, syncErrors := dryRunChanges{}, map[string][]error{}
+		reconciler.preview(node, matcher, []sriovfecv2.SriovFecClusterConfig{applied}, dryRun, fecProfiles{}, RetainLostAccelerators, changes, syncErrors)
+		Expect(syncErrors).To(BeEmpty())
+		Expect(changes.of("preview")).To(Equal([]sriovfecv2.DryRunNodeChange{{NodeName: "node", Changes: "0000:14:00.0 vfAmount 2→4"}}))
+	})
+
+	It("doesn't report nodes where the dry run is overridden", func() {
+		dryRun.Spec.Priority = 0
+		changes, syncErrors := dryRunChanges{}, map[string][]error{}
+		reconciler.preview(node, matcher, []sriovfecv2.SriovFecClusterConfig{applied}, dryRun, fecProfiles{}, RetainLostAccelerators, changes, syncErrors)
+		Expect(changes.of("preview")).To(BeEmpty())
+	})
+})
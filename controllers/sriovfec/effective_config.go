@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// EffectiveConfigKey is a ConfigMap's data key holding the effective configuration of all nodes
+	EffectiveConfigKey = "effective-config.yaml"
+
+	effectiveConfigMapName = "sriovfec-effective-config"
+)
+
+// EffectiveConfiguration is a fully resolved configuration of all accelerated nodes, rendered from ClusterConfigs
+type EffectiveConfiguration struct {
+	NodeConfigs []EffectiveNodeConfig `json:"nodeConfigs"`
+}
+
+// EffectiveNodeConfig is a spec of NodeConfig of a single node after resolution of selectors, priorities, profiles and
+// lost accelerators
+type EffectiveNodeConfig struct {
+	NodeName string `json:"nodeName"`
+	// ClusterConfigs maps PCI address of accelerators to names of ClusterConfigs configuring them
+	ClusterConfigs map[string]string                 `json:"clusterConfigs,omitempty"`
+	Spec           sriovfecv2.SriovFecNodeConfigSpec `json:"spec"`
+	// Applied is true if the spec is already written into NodeConfig of the node
+	Applied bool `json:"applied"`
+}
+
+// record renders effective configuration of the node; nodes which configuration cannot be rendered are not recorded,
+// their errors are reported in conditions
+func (e *EffectiveConfiguration) record(ncc NodeConfigurationCtx, profiles fecProfiles, policy LostAcceleratorPolicy) {
+	rendered, err := renderNodeConfig(ncc, profiles, policy)
+	if err != nil {
+		return
+	}
+
+	clusterConfigs := map[string]string{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		clusterConfigs[pciAddress] = cc.Name
+	}
+
+	e.NodeConfigs = append(e.NodeConfigs, EffectiveNodeConfig{
+		NodeName:       ncc.Name,
+		ClusterConfigs: clusterConfigs,
+		Spec:           rendered.Spec,
+		Applied:        equality.Semantic.DeepEqual(rendered.Spec, ncc.Spec),
+	})
+}
+
+// publishEffectiveConfig writes the effective configuration into ConfigMap as a single document, sorted by node
+func (r *SriovFecClusterConfigReconciler) publishEffectiveConfig(ctx context.Context, effective *EffectiveConfiguration) error {
+	sort.Slice(effective.NodeConfigs, func(i, j int) bool {
+		return effective.NodeConfigs[i].NodeName < effective.NodeConfigs[j].NodeName
+	})
+	if effective.NodeConfigs == nil {
+		effective.NodeConfigs = []EffectiveNodeConfig{}
+	}
+	content, err := yaml.Marshal(effective)
+	if err != nil {
+		return err
+	}
+
+	cm := new(corev1.ConfigMap)
+	err = r.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: effectiveConfigMapName}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if cm.Data[EffectiveConfigKey] == string(content) {
+		return nil
+	}
+
+	cm.Data = map[string]string{EffectiveConfigKey: string(content)}
+	utils.SetStandardLabels(cm, effectiveConfigMapName, NAMESPACE)
+	if errors.IsNotFound(err) {
+		cm.Name = effectiveConfigMapName
+		cm.Namespace = NAMESPACE
+		return r.Create(ctx, cm)
+	}
+	return r.Update(ctx, cm)
+}
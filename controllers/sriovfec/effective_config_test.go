@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EffectiveConfiguration", func() {
+	nodeConfigCtx := func(nodeName string, vfAmount int) NodeConfigurationCtx {
+		cc := sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "config"},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				PhysicalFunction: sriovfecv2.PhysicalFunctionConfig{PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: vfAmount},
+			},
+		}
+		nodeConfig := &sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: nodeName}}
+		nodeConfig.Spec.DrainSkip = true
+		nodeConfig.Spec.PhysicalFunctions = []sriovfecv2.PhysicalFunctionConfigExt{
+			{PCIAddress: "0000:14:00.0", PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2},
+		}
+		nodeConfig.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{{PCIAddress: "0000:14:00.0", MaxVFs: 16}}
+
+		matcher := createClusterConfigMatcher(func(string) (*sriovfecv2.SriovFecNodeConfig, error) { return nodeConfig, nil }, logrus.New())
+		ncc, err := matcher.match(corev1.Node{ObjectMeta: v1.ObjectMeta{Name: nodeName}}, []sriovfecv2.SriovFecClusterConfig{cc})
+		Expect(err).ToNot(HaveOccurred())
+		return *ncc
+	}
+
+	It("publishes configuration of all nodes sorted by node", func() {
+		effective := new(EffectiveConfiguration)
+		effective.record(nodeConfigCtx("node-b", 4), fecProfiles{}, RetainLostAccelerators)
+		effective.record(nodeConfigCtx("node-a", 2), fecProfiles{}, RetainLostAccelerators)
+
+		reconciler := &SriovFecClusterConfigReconciler{Client: k8sClient, Log: logrus.New()}
+		Expect(reconciler.publishEffectiveConfig(context.TODO(), effective)).To(Succeed())
+
+		cm := new(corev1.ConfigMap)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: effectiveConfigMapName}, cm)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), cm)).To(Succeed()) }()
+
+		published := new(EffectiveConfiguration)
+		Expect(yaml.Unmarshal([]byte(cm.Data[EffectiveConfigKey]), published)).To(Succeed())
+		Expect(published.NodeConfigs).To(HaveLen(2))
+
+		Expect(published.NodeConfigs[0].NodeName).To(Equal("node-a"))
+		Expect(published.NodeConfigs[0].ClusterConfigs).To(Equal(map[string]string{"0000:14:00.0": "config"}))
+		Expect(published.NodeConfigs[0].Applied).To(BeTrue())
+
+		Expect(published.NodeConfigs[1].NodeName).To(Equal("node-b"))
+		Expect(published.NodeConfigs[1].Spec.PhysicalFunctions).To(HaveLen(1))
+		Expect(published.NodeConfigs[1].Spec.PhysicalFunctions[0].VFAmount).To(Equal(4))
+		Expect(published.NodeConfigs[1].Applied).To(BeFalse())
+	})
+})
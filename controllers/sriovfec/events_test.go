@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("recordNodeConfigRendered", func() {
+	It("records Event on each ClusterConfig configuring accelerators of the node once", func() {
+		recorder := record.NewFakeRecorder(10)
+		r := &SriovFecClusterConfigReconciler{Recorder: recorder}
+
+		clusterConfig := func(name string) sriovfecv2.SriovFecClusterConfig {
+			return sriovfecv2.SriovFecClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		}
+		ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+		ncc.Name = "node-1"
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", clusterConfig("a"))
+		ncc.AcceleratorConfigContext.Set("0000:15:00.0", clusterConfig("b"))
+		ncc.AcceleratorConfigContext.Set("0000:16:00.0", clusterConfig("a"))
+
+		r.recordNodeConfigRendered(ncc, "0000:14:00.0 vfAmount 4→8")
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(Equal("Normal NodeConfigRendered SriovFecNodeConfig rendered for node node-1: 0000:14:00.0 vfAmount 4→8"))
+
+		(&SriovFecClusterConfigReconciler{}).recordNodeConfigRendered(ncc, "")
+	})
+})
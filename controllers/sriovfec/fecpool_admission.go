@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const fecPoolPodWebhookPath = "/validate-v1-pod-fecpool"
+
+//+kubebuilder:webhook:path=/validate-v1-pod-fecpool,mutating=false,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=vfecpoolpod.kb.io,admissionReviewVersions={v1}
+
+// FecPoolPodValidator refuses Pods requesting FecPool resources of partitions of other namespaces. ResourceQuota created
+// in partitions' namespaces doesn't limit namespaces which are not partitions of any FecPool, so pool resources are
+// restricted to their namespaces on admission of Pods.
+type FecPoolPodValidator struct {
+	Log     *logrus.Logger
+	decoder *admission.Decoder
+}
+
+func (v *FecPoolPodValidator) SetupWebhookWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register(fecPoolPodWebhookPath, &webhook.Admission{Handler: v})
+}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *FecPoolPodValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *FecPoolPodValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	pod := new(corev1.Pod)
+	if err := v.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	namespace := pod.Namespace
+	if namespace == "" {
+		namespace = req.Namespace
+	}
+	if foreign := foreignFecPoolResources(pod, namespace); len(foreign) != 0 {
+		v.Log.WithField("pod", pod.Name).WithField("namespace", namespace).WithField("resources", foreign).
+			Info("refusing pod requesting FecPool resources of other namespaces")
+		return admission.Denied(fmt.Sprintf("FecPool resources of other namespaces requested: %s", strings.Join(foreign, ", ")))
+	}
+	return admission.Allowed("")
+}
+
+// foreignFecPoolResources returns sorted names of FecPool resources requested by containers of the pod which belong to
+// partitions of namespaces other than the given one
+func foreignFecPoolResources(pod *corev1.Pod, namespace string) []string {
+	var containers []corev1.Container
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+
+	foreign := map[string]bool{}
+	for _, container := range containers {
+		for _, resources := range []corev1.ResourceList{container.Resources.Requests, container.Resources.Limits} {
+			for name := range resources {
+				if owner, ok := fecPoolResourceNamespace(string(name)); ok && owner != namespace {
+					foreign[string(name)] = true
+				}
+			}
+		}
+	}
+
+	var names []string
+	for name := range foreign {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fecPoolResourceNamespace returns namespace of the partition owning FecPool resource; the namespace follows the last
+// underscore of the resource name, since names of namespaces don't contain underscores
+func fecPoolResourceNamespace(resourceName string) (string, bool) {
+	name := resourceName[strings.LastIndex(resourceName, "/")+1:]
+	if !strings.HasPrefix(name, FecPoolResourcePrefix) {
+		return "", false
+	}
+	return name[strings.LastIndex(name, "_")+1:], true
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("FecPoolPodValidator", func() {
+	var validator *FecPoolPodValidator
+
+	admit := func(namespace string, resources ...corev1.ResourceName) admission.Response {
+		requests := corev1.ResourceList{}
+		for _, name := range resources {
+			requests[name] = resource.MustParse("1")
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "app", Namespace: namespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Resources: corev1.ResourceRequirements{Requests: requests, Limits: requests}},
+			}},
+		}
+		raw, err := json.Marshal(pod)
+		Expect(err).ToNot(HaveOccurred())
+
+		return validator.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: namespace,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	BeforeEach(func() {
+		decoder, err := admission.NewDecoder(scheme.Scheme)
+		Expect(err).ToNot(HaveOccurred())
+		validator = &FecPoolPodValidator{Log: logrus.New()}
+		Expect(validator.InjectDecoder(decoder)).To(Succeed())
+	})
+
+	It("refuses pod requesting FecPool resource in namespace which is not its partition", func() {
+		response := admit("default", "intel.com/intel_fec_pool_shared_tenant-a")
+		Expect(response.Allowed).To(BeFalse())
+		Expect(string(response.Result.Reason)).To(ContainSubstring("intel.com/intel_fec_pool_shared_tenant-a"))
+	})
+
+	It("admits pod requesting FecPool resource of its namespace", func() {
+		Expect(admit("tenant-a", "intel.com/intel_fec_pool_shared_tenant-a").Allowed).To(BeTrue())
+		Expect(admit("tenant-a", "intel.com/intel_fec_pool_shared_acc_tenant-a").Allowed).To(BeTrue())
+	})
+
+	It("admits pod requesting no FecPool resources", func() {
+		Expect(admit("default", "intel.com/intel_fec_acc100", corev1.ResourceCPU).Allowed).To(BeTrue())
+	})
+})
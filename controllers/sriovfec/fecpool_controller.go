@@ -0,0 +1,544 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// FecPoolResourcePrefix prefixes names of device plugin resources generated for FecPool partitions
+	FecPoolResourcePrefix = "intel_fec_pool_"
+
+	// FecPoolQuotaName is a name of ResourceQuota limiting FecPool resources in partition's namespace
+	FecPoolQuotaName = "fec-pool-quota"
+
+	devicePluginConfigMapName = "sriovdp-config"
+	devicePluginConfigKey     = "config.json"
+	devicePluginLabel         = "sriov-device-plugin-daemonset"
+	// defaultResourcePrefix is used by the device plugin unless resourcePrefix is set in its config
+	defaultResourcePrefix = "intel.com"
+)
+
+// FecPoolReconciler partitions VFs of accelerators among namespaces. Each partition is exposed by the device plugin
+// as a separate resource, and usage of pool resources is limited in partitions' namespaces with ResourceQuota.
+type FecPoolReconciler struct {
+	client.Client
+	// APIReader reads ResourceQuotas outside of operator's namespace, which are not cached by the manager
+	APIReader client.Reader
+	Log       *logrus.Logger
+}
+
+// fecPoolAllocation keeps VFs assigned to partitions of a single FecPool
+type fecPoolAllocation struct {
+	// vfs maps namespace of the partition to PCI addresses of its VFs on all nodes
+	vfs map[string][]string
+	// nodes is an amount of nodes on which partitions were allocated
+	nodes int
+	errs  []string
+}
+
+// +kubebuilder:rbac:groups=sriovfec.intel.com,resources=fecpools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=sriovfec.intel.com,resources=fecpools/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;create;update;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list;watch;delete
+
+// Reconcile synchronizes all FecPools at once, because VFs of a node are shared between pools
+func (r *FecPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Log.Infof("Reconcile(...) triggered by %s", req.NamespacedName.String())
+
+	pools := new(sriovfecv2.FecPoolList)
+	if err := r.List(ctx, pools, client.InNamespace(NAMESPACE)); err != nil {
+		return ctrl.Result{}, err
+	}
+	sort.Slice(pools.Items, func(i, j int) bool { return pools.Items[i].Name < pools.Items[j].Name })
+
+	nodeConfigs := new(sriovfecv2.SriovFecNodeConfigList)
+	if err := r.List(ctx, nodeConfigs, client.InNamespace(NAMESPACE)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	nodes, err := r.configuredNodes(ctx, nodeConfigs.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	allocations := allocateFecPools(pools.Items, nodes, nodeConfigs.Items)
+
+	resourcePrefix, applied, err := r.updateDevicePluginConfig(ctx, pools.Items, allocations, nodeConfigs.Items)
+	if err != nil {
+		r.Log.WithError(err).Error("failed to update device plugin config")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.updateQuotas(ctx, pools.Items, allocations, resourcePrefix); err != nil {
+		r.Log.WithError(err).Error("failed to update FecPool quotas")
+		return ctrl.Result{}, err
+	}
+
+	for i := range pools.Items {
+		if err := r.updateStatus(ctx, &pools.Items[i], allocations[pools.Items[i].Name], resourcePrefix, applied); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// configuredNodes returns nodes having NodeConfig, i.e. nodes managed by the operator, sorted by their names
+func (r *FecPoolReconciler) configuredNodes(ctx context.Context, nodeConfigs []sriovfecv2.SriovFecNodeConfig) ([]corev1.Node, error) {
+	var nodes []corev1.Node
+	for _, nc := range nodeConfigs {
+		node := new(corev1.Node)
+		if err := r.Get(ctx, client.ObjectKey{Name: nc.Name}, node); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		nodes = append(nodes, *node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+// allocateFecPools assigns VFs of matching accelerators to partitions of pools, in order of pools. VFs are assigned
+// in order of their PCI addresses, so nodes with the same hardware get the same assignment. Partitions of a pool are
+// allocated on a node only if the node has enough unassigned VFs for all of them.
+func allocateFecPools(pools []sriovfecv2.FecPool, nodes []corev1.Node, nodeConfigs []sriovfecv2.SriovFecNodeConfig) map[string]*fecPoolAllocation {
+	inventories := map[string][]sriovfecv2.SriovAccelerator{}
+	for _, nc := range nodeConfigs {
+		inventories[nc.Name] = nc.Status.Inventory.SriovAccelerators
+	}
+
+	// device plugin config is shared by all nodes, so VF with the same PCI address has to belong to the same resource
+	owners := map[string]string{}
+	assigned := map[string]map[string]bool{}
+	allocations := map[string]*fecPoolAllocation{}
+
+	for _, pool := range pools {
+		allocation := &fecPoolAllocation{vfs: map[string][]string{}}
+		allocations[pool.Name] = allocation
+
+		requested := 0
+		for _, partition := range pool.Spec.Partitions {
+			requested += partition.VFAmount
+			if msgs := validation.IsQualifiedName(fecPoolResourceName(pool.Name, partition.Namespace)); len(msgs) != 0 {
+				allocation.errs = append(allocation.errs, fmt.Sprintf("invalid resource name of %s partition: %s", partition.Namespace, strings.Join(msgs, ", ")))
+			}
+		}
+		if len(allocation.errs) != 0 {
+			continue
+		}
+
+		nodeSelector := labels.Set(pool.Spec.NodeSelector).AsSelector()
+		for _, node := range nodes {
+			if !nodeSelector.Matches(labels.Set(node.Labels)) {
+				continue
+			}
+			if assigned[node.Name] == nil {
+				assigned[node.Name] = map[string]bool{}
+			}
+
+			var free []string
+			matching := false
+			for _, acc := range inventories[node.Name] {
+				if !pool.Spec.AcceleratorSelector.Matches(acc) {
+					continue
+				}
+				matching = true
+				for _, vf := range acc.VFs {
+					if !assigned[node.Name][vf.PCIAddress] {
+						free = append(free, vf.PCIAddress)
+					}
+				}
+			}
+			if !matching {
+				continue
+			}
+			sort.Strings(free)
+
+			if len(free) < requested {
+				allocation.errs = append(allocation.errs, fmt.Sprintf("node %s has %d unassigned VFs, %d requested", node.Name, len(free), requested))
+				continue
+			}
+
+			if conflict := findOwnershipConflict(pool, free, owners); conflict != "" {
+				allocation.errs = append(allocation.errs, fmt.Sprintf("node %s: %s", node.Name, conflict))
+				continue
+			}
+
+			for _, partition := range pool.Spec.Partitions {
+				resourceName := fecPoolResourceName(pool.Name, partition.Namespace)
+				for _, pciAddress := range free[:partition.VFAmount] {
+					assigned[node.Name][pciAddress] = true
+					if owners[pciAddress] == "" {
+						owners[pciAddress] = resourceName
+						allocation.vfs[partition.Namespace] = append(allocation.vfs[partition.Namespace], pciAddress)
+					}
+				}
+				free = free[partition.VFAmount:]
+			}
+			allocation.nodes++
+		}
+	}
+	return allocations
+}
+
+// findOwnershipConflict checks if VFs would be assigned to the same partitions as VFs with same PCI addresses on other nodes
+func findOwnershipConflict(pool sriovfecv2.FecPool, free []string, owners map[string]string) string {
+	for _, partition := range pool.Spec.Partitions {
+		resourceName := fecPoolResourceName(pool.Name, partition.Namespace)
+		for _, pciAddress := range free[:partition.VFAmount] {
+			if owner := owners[pciAddress]; owner != "" && owner != resourceName {
+				return fmt.Sprintf("VF %s is assigned to %s on another node", pciAddress, owner)
+			}
+		}
+		free = free[partition.VFAmount:]
+	}
+	return ""
+}
+
+func fecPoolResourceName(pool, namespace string) string {
+	return FecPoolResourcePrefix + strings.ReplaceAll(pool, ".", "_") + "_" + namespace
+}
+
+// updateDevicePluginConfig replaces FecPool resources in the device plugin config and restarts the device plugin on
+// nodes which VFs were moved between resources. FecPool resources precede other resources, because the device plugin
+// assigns device to the first matching resource. Returns resource prefix used by the device plugin and whether
+// the allocations are applied to the config.
+func (r *FecPoolReconciler) updateDevicePluginConfig(ctx context.Context, pools []sriovfecv2.FecPool, allocations map[string]*fecPoolAllocation,
+	nodeConfigs []sriovfecv2.SriovFecNodeConfig) (string, bool, error) {
+	cm := new(corev1.ConfigMap)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: devicePluginConfigMapName}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			r.Log.Info("device plugin config does not exist yet")
+			return defaultResourcePrefix, false, nil
+		}
+		return "", false, err
+	}
+
+	var current map[string]interface{}
+	if err := json.Unmarshal([]byte(cm.Data[devicePluginConfigKey]), &current); err != nil {
+		return "", false, fmt.Errorf("failed to parse device plugin config - %v", err)
+	}
+
+	resourcePrefix := defaultResourcePrefix
+	if prefix, ok := current["resourcePrefix"].(string); ok && prefix != "" {
+		resourcePrefix = prefix
+	}
+
+	var poolResources, otherResources []interface{}
+	var additionalInfo interface{}
+	// previousOwners maps PCI addresses of VFs to FecPool resources they were last assigned to - by the current config,
+	// or by the assignment recorded in status of pools if the config was rewritten without FecPool resources, e.g. from
+	// its template on restart of the operator
+	previousOwners := assignedOwners(pools)
+	resourceList, _ := current["resourceList"].([]interface{})
+	for _, res := range resourceList {
+		name, _ := res.(map[string]interface{})["resourceName"].(string)
+		if strings.HasPrefix(name, FecPoolResourcePrefix) {
+			selectors, _ := res.(map[string]interface{})["selectors"].(map[string]interface{})
+			pciAddresses, _ := selectors["pciAddresses"].([]interface{})
+			for _, pciAddress := range pciAddresses {
+				if pciAddress, ok := pciAddress.(string); ok {
+					previousOwners[pciAddress] = name
+				}
+			}
+			continue
+		}
+		if additionalInfo == nil {
+			additionalInfo = res.(map[string]interface{})["additionalInfo"]
+		}
+		otherResources = append(otherResources, res)
+	}
+
+	owners := map[string]string{}
+	for _, pool := range pools {
+		for _, partition := range pool.Spec.Partitions {
+			resourceName := fecPoolResourceName(pool.Name, partition.Namespace)
+			vfs := allocations[pool.Name].vfs[partition.Namespace]
+			for _, pciAddress := range vfs {
+				owners[pciAddress] = resourceName
+			}
+			// resource without selectors would take all devices
+			if len(vfs) == 0 {
+				continue
+			}
+			res := map[string]interface{}{
+				"resourceName": resourceName,
+				"deviceType":   "accelerator",
+				"selectors":    map[string]interface{}{"pciAddresses": vfs},
+			}
+			if additionalInfo != nil {
+				res["additionalInfo"] = additionalInfo
+			}
+			poolResources = append(poolResources, res)
+		}
+	}
+
+	requested := map[string]interface{}{}
+	for k, v := range current {
+		requested[k] = v
+	}
+	requested["resourceList"] = append(poolResources, otherResources...)
+
+	content, err := json.MarshalIndent(requested, "", "    ")
+	if err != nil {
+		return "", false, err
+	}
+	// compare parsed configs, so formatting of the rendered asset doesn't trigger the update
+	var normalized map[string]interface{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return "", false, err
+	}
+	if reflect.DeepEqual(normalized, current) {
+		return resourcePrefix, true, nil
+	}
+
+	r.Log.WithField("resources", len(poolResources)).Info("updating FecPool resources of device plugin")
+	cm.Data[devicePluginConfigKey] = string(content)
+	if err := r.Update(ctx, cm); err != nil {
+		return "", false, err
+	}
+
+	// device plugin reads its config on start only, other nodes keep exposing the same devices
+	return resourcePrefix, true, r.restartDevicePlugin(ctx, nodesWithMovedVFs(nodeConfigs, previousOwners, owners))
+}
+
+// assignedOwners maps PCI addresses of VFs to FecPool resources they were assigned to according to status of pools
+func assignedOwners(pools []sriovfecv2.FecPool) map[string]string {
+	owners := map[string]string{}
+	for _, pool := range pools {
+		for namespace, vfs := range pool.Status.AssignedVFs {
+			for _, pciAddress := range vfs {
+				owners[pciAddress] = fecPoolResourceName(pool.Name, namespace)
+			}
+		}
+	}
+	return owners
+}
+
+// nodesWithMovedVFs returns names of nodes having VFs which FecPool resource differs between given assignments
+func nodesWithMovedVFs(nodeConfigs []sriovfecv2.SriovFecNodeConfig, previousOwners, owners map[string]string) map[string]bool {
+	nodes := map[string]bool{}
+	for _, nc := range nodeConfigs {
+		for _, acc := range nc.Status.Inventory.SriovAccelerators {
+			for _, vf := range acc.VFs {
+				if previousOwners[vf.PCIAddress] != owners[vf.PCIAddress] {
+					nodes[nc.Name] = true
+				}
+			}
+		}
+	}
+	return nodes
+}
+
+// restartDevicePlugin deletes device plugin pods running on given nodes
+func (r *FecPoolReconciler) restartDevicePlugin(ctx context.Context, nodes map[string]bool) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	pods := new(corev1.PodList)
+	if err := r.List(ctx, pods, client.InNamespace(NAMESPACE), client.MatchingLabels{"app": devicePluginLabel}); err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !nodes[pod.Spec.NodeName] {
+			continue
+		}
+		r.Log.WithField("node", pod.Spec.NodeName).Info("restarting device plugin")
+		if err := client.IgnoreNotFound(r.Delete(ctx, pod)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateQuotas keeps single ResourceQuota in each partition's namespace. It limits namespace's own FecPool resources
+// to amount of allocated VFs and forbids usage of FecPool resources of other namespaces.
+func (r *FecPoolReconciler) updateQuotas(ctx context.Context, pools []sriovfecv2.FecPool, allocations map[string]*fecPoolAllocation, resourcePrefix string) error {
+	quotaKey := func(resourceName string) corev1.ResourceName {
+		return corev1.ResourceName("requests." + resourcePrefix + "/" + resourceName)
+	}
+
+	allResources := corev1.ResourceList{}
+	for _, pool := range pools {
+		for _, partition := range pool.Spec.Partitions {
+			allResources[quotaKey(fecPoolResourceName(pool.Name, partition.Namespace))] = resource.MustParse("0")
+		}
+	}
+
+	requested := map[string]corev1.ResourceList{}
+	for _, pool := range pools {
+		for _, partition := range pool.Spec.Partitions {
+			if requested[partition.Namespace] == nil {
+				requested[partition.Namespace] = allResources.DeepCopy()
+			}
+			amount := int64(partition.VFAmount * allocations[pool.Name].nodes)
+			requested[partition.Namespace][quotaKey(fecPoolResourceName(pool.Name, partition.Namespace))] = *resource.NewQuantity(amount, resource.DecimalSI)
+		}
+	}
+
+	existing := new(corev1.ResourceQuotaList)
+	if err := r.APIReader.List(ctx, existing, utils.ManagedObjects(FecPoolQuotaName)); err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		quota := &existing.Items[i]
+		hard, ok := requested[quota.Namespace]
+		if !ok {
+			r.Log.WithField("namespace", quota.Namespace).Info("removing FecPool quota")
+			if err := client.IgnoreNotFound(r.Delete(ctx, quota)); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(requested, quota.Namespace)
+		if equality.Semantic.DeepEqual(quota.Spec.Hard, hard) {
+			continue
+		}
+		quota.Spec.Hard = hard
+		r.Log.WithField("namespace", quota.Namespace).Info("updating FecPool quota")
+		if err := r.Update(ctx, quota); err != nil {
+			return err
+		}
+	}
+
+	for namespace, hard := range requested {
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: FecPoolQuotaName, Namespace: namespace},
+			Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+		}
+		utils.SetStandardLabels(quota, FecPoolQuotaName, namespace)
+		r.Log.WithField("namespace", namespace).Info("creating FecPool quota")
+		if err := r.Create(ctx, quota); err != nil {
+			if errors.IsNotFound(err) {
+				r.Log.WithField("namespace", namespace).Warn("namespace of FecPool partition does not exist")
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// updateStatus reports allocation of the pool; VFs assigned to its partitions are recorded only once they are applied
+// to the device plugin config
+func (r *FecPoolReconciler) updateStatus(ctx context.Context, pool *sriovfecv2.FecPool, allocation *fecPoolAllocation, resourcePrefix string, applied bool) error {
+	status := sriovfecv2.FecPoolStatus{SyncStatus: sriovfecv2.SucceededSync, Resources: map[string]string{}, AssignedVFs: pool.Status.AssignedVFs}
+	if applied {
+		status.AssignedVFs = nil
+		if len(allocation.vfs) != 0 {
+			status.AssignedVFs = allocation.vfs
+		}
+	}
+	if len(allocation.errs) != 0 {
+		status.SyncStatus = sriovfecv2.FailedSync
+		status.LastSyncError = strings.Join(allocation.errs, "; ")
+	}
+	for _, partition := range pool.Spec.Partitions {
+		status.Resources[partition.Namespace] = resourcePrefix + "/" + fecPoolResourceName(pool.Name, partition.Namespace)
+	}
+
+	if equality.Semantic.DeepEqual(pool.Status, status) {
+		return nil
+	}
+	pool.Status = status
+	return r.Status().Update(ctx, pool)
+}
+
+// allPools returns function mapping change of an object affecting all pools to reconcile requests of all pools
+func (r *FecPoolReconciler) allPools(ctx context.Context) handler.MapFunc {
+	return func(_ client.Object) []reconcile.Request {
+		pools := new(sriovfecv2.FecPoolList)
+		if err := r.List(ctx, pools, client.InNamespace(NAMESPACE)); err != nil {
+			r.Log.WithError(err).Error("failed to list FecPools")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, pool := range pools.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&pool)})
+		}
+		return requests
+	}
+}
+
+func (r *FecPoolReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	isDevicePluginConfig := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetNamespace() == NAMESPACE && o.GetName() == devicePluginConfigMapName
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		// status written by the reconciler itself doesn't trigger reconciliation, annotations set by users do
+		For(&sriovfecv2.FecPool{},
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&source.Kind{Type: &sriovfecv2.SriovFecNodeConfig{}},
+			handler.EnqueueRequestsFromMapFunc(r.allPools(ctx)),
+			builder.WithPredicates(isVFInventoryChange)).
+		// pools select nodes by their labels
+		Watches(&source.Kind{Type: &corev1.Node{}},
+			handler.EnqueueRequestsFromMapFunc(r.allPools(ctx)),
+			builder.WithPredicates(isAcceleratedNodeChange)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.allPools(ctx)),
+			builder.WithPredicates(isDevicePluginConfig)).
+		Complete(r)
+}
+
+// isVFInventoryChange passes deletion of NodeConfig and update of NodeConfig which inventory reports different VFs,
+// e.g. after the daemon configured accelerators of the node, so VFs are assigned to partitions immediately
+var isVFInventoryChange = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNc, ok := e.ObjectOld.(*sriovfecv2.SriovFecNodeConfig)
+		if !ok {
+			return false
+		}
+		newNc, ok := e.ObjectNew.(*sriovfecv2.SriovFecNodeConfig)
+		if !ok {
+			return false
+		}
+		return !slices.Equal(vfPCIAddresses(oldNc), vfPCIAddresses(newNc))
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+func vfPCIAddresses(nc *sriovfecv2.SriovFecNodeConfig) []string {
+	var pciAddresses []string
+	for _, acc := range nc.Status.Inventory.SriovAccelerators {
+		for _, vf := range acc.VFs {
+			pciAddresses = append(pciAddresses, vf.PCIAddress)
+		}
+	}
+	return pciAddresses
+}
@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("FecPoolReconciler", func() {
+	const devicePluginConfig = `{"resourceList": [{"resourceName": "intel_fec_acc100", "selectors": {"devices": ["0d5d"]}, "additionalInfo": {"*": {"VFIO_TOKEN": "token"}}}]}`
+
+	accelerator := func(pciAddress string, vfs ...string) sriovv2.SriovAccelerator {
+		acc := sriovv2.SriovAccelerator{PCIAddress: pciAddress, DeviceID: "0d5c"}
+		for _, vf := range vfs {
+			acc.VFs = append(acc.VFs, sriovv2.VF{PCIAddress: vf})
+		}
+		return acc
+	}
+
+	pool := func(name string, partitions ...sriovv2.FecPoolPartition) sriovv2.FecPool {
+		return sriovv2.FecPool{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: NAMESPACE},
+			Spec: sriovv2.FecPoolSpec{
+				AcceleratorSelector: sriovv2.AcceleratorSelector{DeviceID: "0d5c"},
+				Partitions:          partitions,
+			},
+		}
+	}
+
+	nodeConfig := func(name string, accelerators ...sriovv2.SriovAccelerator) sriovv2.SriovFecNodeConfig {
+		nc := sriovv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: NAMESPACE}}
+		nc.Status.Inventory.SriovAccelerators = accelerators
+		return nc
+	}
+
+	node := func(name string) corev1.Node {
+		return corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name, Labels: map[string]string{"fpga.intel.com/intel-accelerator-present": ""}}}
+	}
+
+	Describe("allocateFecPools", func() {
+		It("assigns VFs to partitions in order of PCI addresses", func() {
+			allocations := allocateFecPools(
+				[]sriovv2.FecPool{pool("p1", sriovv2.FecPoolPartition{Namespace: "a", VFAmount: 2}, sriovv2.FecPoolPartition{Namespace: "b", VFAmount: 1})},
+				[]corev1.Node{node("n1"), node("n2")},
+				[]sriovv2.SriovFecNodeConfig{
+					nodeConfig("n1", accelerator("0000:14:00.0", "0000:15:00.3", "0000:15:00.0", "0000:15:00.1", "0000:15:00.2")),
+					nodeConfig("n2", accelerator("0000:14:00.0", "0000:15:00.0", "0000:15:00.1", "0000:15:00.2")),
+				},
+			)
+
+			Expect(allocations["p1"].errs).To(BeEmpty())
+			Expect(allocations["p1"].nodes).To(Equal(2))
+			Expect(allocations["p1"].vfs).To(Equal(map[string][]string{
+				"a": {"0000:15:00.0", "0000:15:00.1"},
+				"b": {"0000:15:00.2"},
+			}))
+		})
+
+		It("does not share VFs between pools", func() {
+			allocations := allocateFecPools(
+				[]sriovv2.FecPool{
+					pool("p1", sriovv2.FecPoolPartition{Namespace: "a", VFAmount: 1}),
+					pool("p2", sriovv2.FecPoolPartition{Namespace: "b", VFAmount: 2}),
+				},
+				[]corev1.Node{node("n1")},
+				[]sriovv2.SriovFecNodeConfig{nodeConfig("n1", accelerator("0000:14:00.0", "0000:15:00.0", "0000:15:00.1"))},
+			)
+
+			Expect(allocations["p1"].vfs).To(HaveKeyWithValue("a", []string{"0000:15:00.0"}))
+			Expect(allocations["p2"].vfs).To(BeEmpty())
+			Expect(allocations["p2"].errs).To(ConsistOf("node n1 has 1 unassigned VFs, 2 requested"))
+		})
+	})
+
+	Describe("nodesWithMovedVFs", func() {
+		It("returns only nodes which VFs changed their FecPool resource", func() {
+			nodeConfigs := []sriovv2.SriovFecNodeConfig{
+				nodeConfig("n1", accelerator("0000:14:00.0", "0000:15:00.0", "0000:15:00.1")),
+				nodeConfig("n2", accelerator("0000:16:00.0", "0000:17:00.0")),
+			}
+			previous := map[string]string{"0000:15:00.0": "intel_fec_pool_p1_a", "0000:17:00.0": "intel_fec_pool_p1_a"}
+			current := map[string]string{"0000:15:00.0": "intel_fec_pool_p1_a", "0000:15:00.1": "intel_fec_pool_p1_a", "0000:17:00.0": "intel_fec_pool_p1_a"}
+
+			Expect(nodesWithMovedVFs(nodeConfigs, previous, current)).To(Equal(map[string]bool{"n1": true}))
+			Expect(nodesWithMovedVFs(nodeConfigs, current, current)).To(BeEmpty())
+		})
+	})
+
+	Describe("assignedOwners", func() {
+		It("maps VFs recorded in status of pools to their FecPool resources", func() {
+			p := pool("p1", sriovv2.FecPoolPartition{Namespace: "a", VFAmount: 1}, sriovv2.FecPoolPartition{Namespace: "b", VFAmount: 1})
+			p.Status.AssignedVFs = map[string][]string{"a": {"0000:15:00.0"}, "b": {"0000:15:00.1"}}
+
+			Expect(assignedOwners([]sriovv2.FecPool{p, pool("p2")})).To(Equal(map[string]string{
+				"0000:15:00.0": "intel_fec_pool_p1_a",
+				"0000:15:00.1": "intel_fec_pool_p1_b",
+			}))
+		})
+	})
+
+	Describe("isVFInventoryChange", func() {
+		It("passes updates of NodeConfig changing its VFs", func() {
+			unconfigured := nodeConfig("n1", accelerator("0000:14:00.0"))
+			configured := nodeConfig("n1", accelerator("0000:14:00.0", "0000:15:00.0"))
+			relabeled := configured.DeepCopy()
+			relabeled.Labels = map[string]string{"label": "value"}
+
+			Expect(isVFInventoryChange.Update(event.UpdateEvent{ObjectOld: &unconfigured, ObjectNew: &configured})).To(BeTrue())
+			Expect(isVFInventoryChange.Update(event.UpdateEvent{ObjectOld: &configured, ObjectNew: relabeled})).To(BeFalse())
+			Expect(isVFInventoryChange.Create(event.CreateEvent{Object: &configured})).To(BeFalse())
+			Expect(isVFInventoryChange.Delete(event.DeleteEvent{Object: &configured})).To(BeTrue())
+		})
+	})
+
+	Describe("Reconcile", func() {
+		var (
+			reconciler *FecPoolReconciler
+			objects    []client.Object
+		)
+
+		create := func(obj client.Object) {
+			Expect(k8sClient.Create(context.TODO(), obj)).To(Succeed())
+			objects = append(objects, obj)
+		}
+
+		BeforeEach(func() {
+			objects = nil
+			reconciler = &FecPoolReconciler{Client: k8sClient, APIReader: k8sClient, Log: logrus.New()}
+
+			ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "tenant-a"}}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(context.TODO(), ns))).To(Succeed())
+
+			create(&corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{Name: devicePluginConfigMapName, Namespace: NAMESPACE},
+				Data:       map[string]string{devicePluginConfigKey: devicePluginConfig},
+			})
+
+			n := node("fecpool-node")
+			create(&n)
+
+			nc := nodeConfig("fecpool-node")
+			create(&nc)
+			nc.Status.Inventory.SriovAccelerators = []sriovv2.SriovAccelerator{accelerator("0000:14:00.0", "0000:15:00.0", "0000:15:00.1")}
+			Expect(k8sClient.Status().Update(context.TODO(), &nc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			for _, obj := range objects {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), obj))).To(Succeed())
+			}
+			quota := &corev1.ResourceQuota{ObjectMeta: v1.ObjectMeta{Name: FecPoolQuotaName, Namespace: "tenant-a"}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), quota))).To(Succeed())
+		})
+
+		It("exposes partitions as device plugin resources limited by quota", func() {
+			p := pool("shared", sriovv2.FecPoolPartition{Namespace: "tenant-a", VFAmount: 2})
+			create(&p)
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+			Expect(err).ToNot(HaveOccurred())
+
+			cm := new(corev1.ConfigMap)
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: devicePluginConfigMapName, Namespace: NAMESPACE}, cm)).To(Succeed())
+			config := struct {
+				ResourceList []struct {
+					ResourceName string `json:"resourceName"`
+					Selectors    struct {
+						PCIAddresses []string `json:"pciAddresses"`
+					} `json:"selectors"`
+				} `json:"resourceList"`
+			}{}
+			Expect(json.Unmarshal([]byte(cm.Data[devicePluginConfigKey]), &config)).To(Succeed())
+			Expect(config.ResourceList).To(HaveLen(2))
+			Expect(config.ResourceList[0].ResourceName).To(Equal("intel_fec_pool_shared_tenant-a"))
+			Expect(config.ResourceList[0].Selectors.PCIAddresses).To(Equal([]string{"0000:15:00.0", "0000:15:00.1"}))
+			Expect(config.ResourceList[1].ResourceName).To(Equal("intel_fec_acc100"))
+
+			quota := new(corev1.ResourceQuota)
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: FecPoolQuotaName, Namespace: "tenant-a"}, quota)).To(Succeed())
+			Expect(quota.Spec.Hard).To(HaveKeyWithValue(corev1.ResourceName("requests.intel.com/intel_fec_pool_shared_tenant-a"), resource.MustParse("2")))
+
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(&p), &p)).To(Succeed())
+			Expect(p.Status.SyncStatus).To(Equal(sriovv2.SucceededSync))
+			Expect(p.Status.Resources).To(HaveKeyWithValue("tenant-a", "intel.com/intel_fec_pool_shared_tenant-a"))
+			Expect(p.Status.AssignedVFs).To(HaveKeyWithValue("tenant-a", []string{"0000:15:00.0", "0000:15:00.1"}))
+
+			Expect(k8sClient.Delete(context.TODO(), &p)).To(Succeed())
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: devicePluginConfigMapName, Namespace: NAMESPACE}, cm)).To(Succeed())
+			Expect(json.Unmarshal([]byte(cm.Data[devicePluginConfigKey]), &config)).To(Succeed())
+			Expect(config.ResourceList).To(HaveLen(1))
+			err = k8sClient.Get(context.TODO(), client.ObjectKey{Name: FecPoolQuotaName, Namespace: "tenant-a"}, quota)
+			Expect(client.IgnoreNotFound(err)).To(Succeed())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("doesn't restart device plugin when config rewritten from its template assigns VFs as before", func() {
+			p := pool("shared", sriovv2.FecPoolPartition{Namespace: "tenant-a", VFAmount: 2})
+			create(&p)
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+			Expect(err).ToNot(HaveOccurred())
+
+			devicePlugin := &corev1.Pod{
+				ObjectMeta: v1.ObjectMeta{Name: "device-plugin", Namespace: NAMESPACE, Labels: map[string]string{"app": devicePluginLabel}},
+				Spec:       corev1.PodSpec{NodeName: "fecpool-node", Containers: []corev1.Container{{Name: "device-plugin", Image: "device-plugin"}}},
+			}
+			create(devicePlugin)
+
+			// operator restarted, its asset manager rendered the config again
+			cm := new(corev1.ConfigMap)
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: devicePluginConfigMapName, Namespace: NAMESPACE}, cm)).To(Succeed())
+			cm.Data[devicePluginConfigKey] = devicePluginConfig
+			Expect(k8sClient.Update(context.TODO(), cm)).To(Succeed())
+
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: devicePluginConfigMapName, Namespace: NAMESPACE}, cm)).To(Succeed())
+			Expect(cm.Data[devicePluginConfigKey]).To(ContainSubstring("intel_fec_pool_shared_tenant-a"))
+			Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(devicePlugin), devicePlugin)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// deconfigureFinalizer keeps deleted ClusterConfig until accelerators it configured are deconfigured by daemons
+const deconfigureFinalizer = "sriovfec.intel.com/deconfigure"
+
+// +kubebuilder:rbac:groups=sriovfec.intel.com,resources=sriovfecclusterconfigs/finalizers,verbs=update
+
+// manageFinalizers adds the finalizer to ClusterConfigs which are not deleted and returns ClusterConfigs to be rendered
+// into NodeConfigs: deleted ClusterConfigs keeping the finalizer are rendered as Absent, so accelerators they configured
+// are deconfigured, while deleted dry runs, which never configured anything, are released immediately
+func (r *SriovFecClusterConfigReconciler) manageFinalizers(ctx context.Context, clusterConfigs []sriovfecv2.SriovFecClusterConfig) []sriovfecv2.SriovFecClusterConfig {
+	var rendered []sriovfecv2.SriovFecClusterConfig
+	for i := range clusterConfigs {
+		cc := &clusterConfigs[i]
+		switch {
+		case cc.DeletionTimestamp.IsZero():
+			if controllerutil.AddFinalizer(cc, deconfigureFinalizer) {
+				if err := r.Update(ctx, cc); err != nil {
+					r.Log.WithError(err).WithField("name", cc.Name).Error("failed to add finalizer to SriovFecClusterConfig")
+				}
+			}
+			rendered = append(rendered, *cc)
+		case !controllerutil.ContainsFinalizer(cc, deconfigureFinalizer):
+			// the finalizer was removed manually, accelerators keep their configuration
+		case cc.Spec.DryRun:
+			r.releaseFinalizer(ctx, cc)
+		default:
+			deconfigured := cc.DeepCopy()
+			deconfigured.Spec.State = sriovfecv2.CardAbsent
+			deconfigured.Spec.Canary = nil
+			rendered = append(rendered, *deconfigured)
+		}
+	}
+	return rendered
+}
+
+// releaseDeconfigured removes the finalizer from deleted ClusterConfigs which accelerators are deconfigured on all
+// nodes. ClusterConfig which deconfiguration failed on any node is kept, so the failure is reported in its status.
+func (r *SriovFecClusterConfigReconciler) releaseDeconfigured(ctx context.Context, clusterConfigs []sriovfecv2.SriovFecClusterConfig, rollouts configRollouts) {
+	for i := range clusterConfigs {
+		cc := &clusterConfigs[i]
+		if cc.DeletionTimestamp.IsZero() || cc.Spec.DryRun || !controllerutil.ContainsFinalizer(cc, deconfigureFinalizer) {
+			continue
+		}
+		if rollout, ok := rollouts[cc.Name]; ok && (len(rollout.inProgress) != 0 || len(rollout.failed) != 0) {
+			r.Log.WithField("name", cc.Name).WithField("nodes", len(rollout.inProgress)+len(rollout.failed)).
+				Info("waiting for deconfiguration of nodes of deleted SriovFecClusterConfig")
+			continue
+		}
+		r.releaseFinalizer(ctx, cc)
+	}
+}
+
+// releaseFinalizer removes the finalizer, so deletion of the ClusterConfig is completed
+func (r *SriovFecClusterConfigReconciler) releaseFinalizer(ctx context.Context, cc *sriovfecv2.SriovFecClusterConfig) {
+	controllerutil.RemoveFinalizer(cc, deconfigureFinalizer)
+	if err := r.Update(ctx, cc); err != nil {
+		r.Log.WithError(err).WithField("name", cc.Name).Error("failed to remove finalizer from SriovFecClusterConfig")
+		return
+	}
+	r.Log.WithField("name", cc.Name).Info("SriovFecClusterConfig deconfigured, finalizer removed")
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("ClusterConfig finalizer", func() {
+	var (
+		reconciler *SriovFecClusterConfigReconciler
+		cc         *sriovfecv2.SriovFecClusterConfig
+	)
+
+	list := func() []sriovfecv2.SriovFecClusterConfig {
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), cc)).To(Succeed())
+		return []sriovfecv2.SriovFecClusterConfig{*cc}
+	}
+
+	BeforeEach(func() {
+		reconciler = &SriovFecClusterConfigReconciler{Client: k8sClient, Log: logrus.New()}
+		cc = &sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "finalized", Namespace: NAMESPACE},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				Priority:         1,
+				PhysicalFunction: clusterConfigPrototype.Spec.PhysicalFunction,
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), cc)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), cc); err == nil {
+			cc.Finalizers = nil
+			Expect(k8sClient.Update(context.TODO(), cc)).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), cc))).To(Succeed())
+		}
+	})
+
+	It("renders deleted config as absent until its nodes are deconfigured", func() {
+		rendered := reconciler.manageFinalizers(context.TODO(), list())
+		Expect(rendered).To(HaveLen(1))
+		Expect(rendered[0].Spec.IsAbsent()).To(BeFalse())
+		Expect(list()[0].Finalizers).To(ContainElement(deconfigureFinalizer))
+
+		Expect(k8sClient.Delete(context.TODO(), cc)).To(Succeed())
+		rendered = reconciler.manageFinalizers(context.TODO(), list())
+		Expect(rendered).To(HaveLen(1))
+		Expect(rendered[0].Spec.IsAbsent()).To(BeTrue())
+
+		rollouts := configRollouts{"finalized": {inProgress: []string{"node1"}, failed: map[string]error{}}}
+		reconciler.releaseDeconfigured(context.TODO(), list(), rollouts)
+		Expect(list()[0].Finalizers).To(ContainElement(deconfigureFinalizer))
+
+		rollouts = configRollouts{"finalized": {configured: []string{"node1"}, failed: map[string]error{}}}
+		reconciler.releaseDeconfigured(context.TODO(), list(), rollouts)
+		Expect(errors.IsNotFound(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), cc))).To(BeTrue())
+	})
+
+	It("reports deconfiguration of deleted config in its conditions", func() {
+		reconciler.manageFinalizers(context.TODO(), list())
+		Expect(k8sClient.Delete(context.TODO(), cc)).To(Succeed())
+
+		ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", list()[0])
+		rollouts := configRollouts{}
+		rollouts.hold(corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node1"}}, ncc)
+		conditions := map[string][]v1.Condition{}
+		rollouts.standardConditions(list(), map[string][]error{}, overriddenAccelerators{}, conditions)
+
+		Expect(conditions["finalized"]).To(ContainElement(And(
+			HaveField("Type", sriovfecv2.ProgressingCondition),
+			HaveField("Reason", "Deconfiguring"),
+			HaveField("Message", "waiting for deconfiguration of nodes: node1"))))
+		Expect(conditions["finalized"]).To(ContainElement(And(
+			HaveField("Type", sriovfecv2.ReadyCondition),
+			HaveField("Reason", "Deleting"))))
+	})
+})
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// LostAcceleratorPolicy defines handling of accelerators configured in a NodeConfig which disappeared from the node,
+// either from its inventory or along with accelerator label of the node
+type LostAcceleratorPolicy string
+
+const (
+	// RetainLostAccelerators keeps configuration of lost accelerators in the NodeConfig, so it's applied again when
+	// they are back
+	RetainLostAccelerators LostAcceleratorPolicy = "Retain"
+	// CleanupLostAccelerators removes configuration of lost accelerators from the NodeConfig immediately
+	CleanupLostAccelerators LostAcceleratorPolicy = "Cleanup"
+	// AlertOnLostAccelerators keeps configuration of lost accelerators and emits a Warning Event on the NodeConfig
+	AlertOnLostAccelerators LostAcceleratorPolicy = "AlertOnly"
+
+	// ConditionAcceleratorsLost is set on NodeConfig of a node which lost accelerators configured in its spec, reason of
+	// the condition is the applied policy
+	ConditionAcceleratorsLost = "AcceleratorsLost"
+
+	lostAcceleratorPolicyEnv = "SRIOV_FEC_LOST_ACCELERATOR_POLICY"
+)
+
+// lostAcceleratorPolicy returns policy configured with SRIOV_FEC_LOST_ACCELERATOR_POLICY, Retain by default
+func lostAcceleratorPolicy(log *logrus.Logger) LostAcceleratorPolicy {
+	switch policy := LostAcceleratorPolicy(os.Getenv(lostAcceleratorPolicyEnv)); policy {
+	case RetainLostAccelerators, CleanupLostAccelerators, AlertOnLostAccelerators:
+		return policy
+	case "":
+		return RetainLostAccelerators
+	default:
+		log.WithField("value", policy).Warnf("invalid %s, using %s", lostAcceleratorPolicyEnv, RetainLostAccelerators)
+		return RetainLostAccelerators
+	}
+}
+
+// lostPhysicalFunctions returns physical functions of the NodeConfig spec which accelerators are missing in its
+// inventory; all of them if the node is not accelerated anymore
+func lostPhysicalFunctions(nc *sriovfecv2.SriovFecNodeConfig, accelerated bool) []sriovfecv2.PhysicalFunctionConfigExt {
+	var lost []sriovfecv2.PhysicalFunctionConfigExt
+	for _, pf := range nc.Spec.PhysicalFunctions {
+		present := slices.ContainsFunc(nc.Status.Inventory.SriovAccelerators, func(acc sriovfecv2.SriovAccelerator) bool {
+			return acc.PCIAddress == pf.PCIAddress
+		})
+		if !accelerated || !present {
+			lost = append(lost, pf)
+		}
+	}
+	return lost
+}
+
+// handleNodesWithoutAccelerators applies the policy to NodeConfigs of nodes which are not accelerated anymore, e.g.
+// because the accelerator label disappeared
+func (r *SriovFecClusterConfigReconciler) handleNodesWithoutAccelerators(ctx context.Context, acceleratedNodes []corev1.Node, policy LostAcceleratorPolicy) {
+	nodeConfigs := new(sriovfecv2.SriovFecNodeConfigList)
+	if err := r.List(ctx, nodeConfigs, client.InNamespace(NAMESPACE)); err != nil {
+		r.Log.WithError(err).Error("failed to list SriovFecNodeConfigs to find nodes without accelerators")
+		return
+	}
+
+	for i := range nodeConfigs.Items {
+		nc := &nodeConfigs.Items[i]
+		if slices.ContainsFunc(acceleratedNodes, func(node corev1.Node) bool { return node.Name == nc.Name }) {
+			continue
+		}
+		lost := lostPhysicalFunctions(nc, false)
+		if len(lost) != 0 && policy == CleanupLostAccelerators && !nc.IsProtected() {
+			r.Log.WithField("node", nc.Name).Info("node is not accelerated anymore - cleaning up its SriovFecNodeConfig")
+			nc.Spec.PhysicalFunctions = []sriovfecv2.PhysicalFunctionConfigExt{}
+			if err := r.Update(ctx, nc); err != nil {
+				r.Log.WithError(err).WithField("node", nc.Name).Error("failed to clean up SriovFecNodeConfig")
+				continue
+			}
+		}
+		r.reportLostAccelerators(ctx, nc.Name, lost, policy)
+	}
+}
+
+// reportLostAccelerators reflects lost accelerators and the applied policy in AcceleratorsLost condition of the NodeConfig
+func (r *SriovFecClusterConfigReconciler) reportLostAccelerators(ctx context.Context, nodeName string, lost []sriovfecv2.PhysicalFunctionConfigExt, policy LostAcceleratorPolicy) {
+	nc := new(sriovfecv2.SriovFecNodeConfig)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: nodeName}, nc); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			r.Log.WithError(err).WithField("node", nodeName).Error("failed to get SriovFecNodeConfig to report lost accelerators")
+		}
+		return
+	}
+
+	current := meta.FindStatusCondition(nc.Status.Conditions, ConditionAcceleratorsLost)
+	if len(lost) == 0 {
+		// cleanup is reported until the NodeConfig changes again, as cleaned up accelerators are not lost anymore
+		if current == nil || (current.Reason == string(CleanupLostAccelerators) && current.ObservedGeneration == nc.GetGeneration()) {
+			return
+		}
+		meta.RemoveStatusCondition(&nc.Status.Conditions, ConditionAcceleratorsLost)
+	} else {
+		var addresses []string
+		for _, pf := range lost {
+			addresses = append(addresses, pf.PCIAddress)
+		}
+		message := fmt.Sprintf("accelerators %s are not present on the node", strings.Join(addresses, ", "))
+		if current != nil && current.Reason == string(policy) && current.Message == message {
+			return
+		}
+		meta.SetStatusCondition(&nc.Status.Conditions, metav1.Condition{
+			Type:               ConditionAcceleratorsLost,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: nc.GetGeneration(),
+			Reason:             string(policy),
+			Message:            message,
+		})
+		if policy == AlertOnLostAccelerators && r.Recorder != nil {
+			r.Recorder.Event(nc, corev1.EventTypeWarning, ConditionAcceleratorsLost, message)
+		}
+	}
+
+	if err := r.Status().Update(ctx, nc); err != nil {
+		r.Log.WithError(err).WithField("node", nodeName).Error("failed to report lost accelerators in SriovFecNodeConfig")
+	}
+}
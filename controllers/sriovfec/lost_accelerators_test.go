@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LostAccelerators", func() {
+	var (
+		recorder   *record.FakeRecorder
+		reconciler *SriovFecClusterConfigReconciler
+	)
+
+	newNodeConfig := func(name string, inventory ...string) *sriovfecv2.SriovFecNodeConfig {
+		nc := &sriovfecv2.SriovFecNodeConfig{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: NAMESPACE},
+			Spec: sriovfecv2.SriovFecNodeConfigSpec{PhysicalFunctions: []sriovfecv2.PhysicalFunctionConfigExt{
+				{PCIAddress: "0000:14:00.0", PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2},
+			}},
+		}
+		for _, pci := range inventory {
+			nc.Status.Inventory.SriovAccelerators = append(nc.Status.Inventory.SriovAccelerators, sriovfecv2.SriovAccelerator{PCIAddress: pci})
+		}
+		return nc
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &SriovFecClusterConfigReconciler{Client: k8sClient, Log: logrus.New(), Recorder: recorder}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv(lostAcceleratorPolicyEnv)).To(Succeed())
+		Expect(k8sClient.DeleteAllOf(context.TODO(), &sriovfecv2.SriovFecNodeConfig{}, client.InNamespace(NAMESPACE))).To(Succeed())
+	})
+
+	It("uses Retain policy by default and for invalid values", func() {
+		Expect(lostAcceleratorPolicy(logrus.New())).To(Equal(RetainLostAccelerators))
+		Expect(os.Setenv(lostAcceleratorPolicyEnv, "Invalid")).To(Succeed())
+		Expect(lostAcceleratorPolicy(logrus.New())).To(Equal(RetainLostAccelerators))
+		Expect(os.Setenv(lostAcceleratorPolicyEnv, "Cleanup")).To(Succeed())
+		Expect(lostAcceleratorPolicy(logrus.New())).To(Equal(CleanupLostAccelerators))
+	})
+
+	It("finds physical functions missing in inventory", func() {
+		Expect(lostPhysicalFunctions(newNodeConfig("node", "0000:14:00.0"), true)).To(BeEmpty())
+		Expect(lostPhysicalFunctions(newNodeConfig("node", "0000:15:00.0"), true)).To(HaveLen(1))
+		Expect(lostPhysicalFunctions(newNodeConfig("node", "0000:14:00.0"), false)).To(HaveLen(1))
+	})
+
+	It("cleans up NodeConfig of node which is not accelerated anymore", func() {
+		Expect(k8sClient.Create(context.TODO(), newNodeConfig("node", "0000:14:00.0"))).To(Succeed())
+
+		reconciler.handleNodesWithoutAccelerators(context.TODO(), nil, CleanupLostAccelerators)
+
+		nc := new(sriovfecv2.SriovFecNodeConfig)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: "node"}, nc)).To(Succeed())
+		Expect(nc.Spec.PhysicalFunctions).To(BeEmpty())
+		condition := nc.FindCondition(ConditionAcceleratorsLost)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(string(CleanupLostAccelerators)))
+		Expect(condition.Message).To(ContainSubstring("0000:14:00.0"))
+	})
+
+	It("retains configuration of protected NodeConfig", func() {
+		nc := newNodeConfig("node", "0000:14:00.0")
+		nc.Annotations = map[string]string{sriovfecv2.ProtectAnnotation: "true"}
+		Expect(k8sClient.Create(context.TODO(), nc)).To(Succeed())
+
+		reconciler.handleNodesWithoutAccelerators(context.TODO(), nil, CleanupLostAccelerators)
+
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+		Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+	})
+
+	It("skips nodes which are still accelerated", func() {
+		Expect(k8sClient.Create(context.TODO(), newNodeConfig("node", "0000:14:00.0"))).To(Succeed())
+
+		reconciler.handleNodesWithoutAccelerators(context.TODO(), []corev1.Node{{ObjectMeta: v1.ObjectMeta{Name: "node"}}}, CleanupLostAccelerators)
+
+		nc := new(sriovfecv2.SriovFecNodeConfig)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: "node"}, nc)).To(Succeed())
+		Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+		Expect(nc.FindCondition(ConditionAcceleratorsLost)).To(BeNil())
+	})
+
+	It("emits Warning Event under AlertOnly policy and clears condition once accelerator is back", func() {
+		nc := newNodeConfig("node")
+		Expect(k8sClient.Create(context.TODO(), nc)).To(Succeed())
+
+		reconciler.reportLostAccelerators(context.TODO(), "node", lostPhysicalFunctions(nc, true), AlertOnLostAccelerators)
+
+		Expect(recorder.Events).To(Receive(ContainSubstring(ConditionAcceleratorsLost)))
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+		Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+		Expect(nc.FindCondition(ConditionAcceleratorsLost).Reason).To(Equal(string(AlertOnLostAccelerators)))
+
+		reconciler.reportLostAccelerators(context.TODO(), "node", nil, AlertOnLostAccelerators)
+
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+		Expect(nc.FindCondition(ConditionAcceleratorsLost)).To(BeNil())
+	})
+})
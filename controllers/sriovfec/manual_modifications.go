@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/audit"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const nodeConfigKind = "SriovFecNodeConfig"
+
+// isManuallyModified returns true if spec of the NodeConfig differs from the one last written by the operator; spec of
+// orphaned NodeConfigs is written by the daemon, so it's not a manual modification
+func isManuallyModified(nc *sriovfecv2.SriovFecNodeConfig) bool {
+	return !nc.IsOrphaned() && audit.IsModified(nc.Annotations[sriovfecv2.RenderedSpecAnnotation], nc.Spec)
+}
+
+// recordRevertedModification records that manual modification of the node's NodeConfig was reverted on behalf of
+// ClusterConfigs rendered into it
+func (r *SriovFecClusterConfigReconciler) recordRevertedModification(ncc NodeConfigurationCtx, now time.Time) {
+	var configs []string
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		configs = append(configs, cc.Name)
+	}
+	r.Log.WithField("node", ncc.Name).WithField("SriovFecClusterConfigs", configs).
+		Info("manual modification of SriovFecNodeConfig spec reverted")
+	r.reverts.Record(nodeConfigKind, ncc.Name, configs, now)
+}
+
+// manualModificationConditions sets ManualModificationReverted condition of ClusterConfigs which NodeConfigs were
+// manually modified and reverted in the last audit interval, the condition is set to false once the interval passes;
+// the metric of detected modifications is reported along with them
+func manualModificationConditions(clusterConfigs []sriovfecv2.SriovFecClusterConfig, conditions utils.Conditions,
+	reverts *audit.Reverts, now time.Time) {
+	detected := false
+	for _, cc := range clusterConfigs {
+		nodes := reverts.Of(cc.Name, now)
+		previous := meta.FindStatusCondition(cc.Status.Conditions, sriovfecv2.ManualModificationRevertedCondition)
+
+		switch {
+		case len(nodes) != 0:
+			detected = true
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: sriovfecv2.ManualModificationRevertedCondition,
+				Status: metav1.ConditionTrue, ObservedGeneration: cc.Generation, Reason: "ManualModificationReverted",
+				Message: fmt.Sprintf("manual modifications of SriovFecNodeConfigs of nodes %s were reverted", strings.Join(nodes, ", "))})
+		case previous == nil:
+			// the condition is reported once a modification of the ClusterConfig's NodeConfigs is reverted
+		case previous.Status == metav1.ConditionTrue && now.Sub(previous.LastTransitionTime.Time) < audit.Interval:
+			// reverts are kept in memory only, so the condition reported before restart of the operator is kept
+			detected = true
+		default:
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: sriovfecv2.ManualModificationRevertedCondition,
+				Status: metav1.ConditionFalse, ObservedGeneration: cc.Generation, Reason: "NoManualModifications",
+				Message: fmt.Sprintf("no manual modification of SriovFecNodeConfigs was reverted in the last %s", audit.Interval)})
+		}
+	}
+	audit.Report(nodeConfigKind, detected)
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"time"
+
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/audit"
+)
+
+var _ = Describe("Manual modifications", func() {
+	const pciAddress = "0000:14:00.0"
+
+	spec := func(vfAmount int) sriovfecv2.SriovFecNodeConfigSpec {
+		return sriovfecv2.SriovFecNodeConfigSpec{DrainSkip: true, PhysicalFunctions: []sriovfecv2.PhysicalFunctionConfigExt{
+			{PCIAddress: pciAddress, PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: vfAmount},
+		}}
+	}
+
+	It("reverts modified NodeConfig and reports it in conditions of its ClusterConfigs", func() {
+		nc := &sriovfecv2.SriovFecNodeConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "node1", Namespace: NAMESPACE,
+				Annotations: map[string]string{sriovfecv2.RenderedSpecAnnotation: audit.Checksum(spec(2))}},
+			Spec: spec(4),
+		}
+		nc.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{{PCIAddress: pciAddress, DeviceID: "0d5c"}}
+		Expect(isManuallyModified(nc)).To(BeTrue())
+
+		r := &SriovFecClusterConfigReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nc).Build(),
+			Log: logrus.New()}
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+
+		cc := sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "config", Namespace: NAMESPACE, Generation: 1}}
+		cc.Spec.PhysicalFunction = sriovfecv2.PhysicalFunctionConfig{PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2}
+		ncc := NodeConfigurationCtx{SriovFecNodeConfig: *nc,
+			AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+		ncc.AcceleratorConfigContext.Set(pciAddress, cc)
+		updated, err := r.synchronizeNodeConfigSpec(context.TODO(), ncc, fecProfiles{}, RetainLostAccelerators)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updated).To(BeTrue())
+
+		reverted := new(sriovfecv2.SriovFecNodeConfig)
+		Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(nc), reverted)).To(Succeed())
+		Expect(reverted.Spec.PhysicalFunctions[0].VFAmount).To(Equal(2))
+		Expect(isManuallyModified(reverted)).To(BeFalse())
+
+		conditions := map[string][]v1.Condition{}
+		manualModificationConditions([]sriovfecv2.SriovFecClusterConfig{cc}, conditions, &r.reverts, time.Now())
+		condition := meta.FindStatusCondition(conditions["config"], sriovfecv2.ManualModificationRevertedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(v1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("node1"))
+	})
+
+	It("doesn't report NodeConfigs without spec rendered by the operator", func() {
+		nc := &sriovfecv2.SriovFecNodeConfig{Spec: spec(4)}
+		Expect(isManuallyModified(nc)).To(BeFalse())
+
+		nc.Annotations = map[string]string{sriovfecv2.RenderedSpecAnnotation: audit.Checksum(spec(4))}
+		Expect(isManuallyModified(nc)).To(BeFalse())
+	})
+
+	It("sets the condition to false once the audit interval passed", func() {
+		cc := sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "config"}}
+		cc.Status.Conditions = []v1.Condition{{Type: sriovfecv2.ManualModificationRevertedCondition, Status: v1.ConditionTrue,
+			LastTransitionTime: v1.NewTime(time.Now().Add(-2 * audit.Interval))}}
+		untouched := sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "untouched"}}
+
+		conditions := map[string][]v1.Condition{}
+		manualModificationConditions([]sriovfecv2.SriovFecClusterConfig{cc, untouched}, conditions, &audit.Reverts{}, time.Now())
+		Expect(meta.IsStatusConditionFalse(conditions["config"], sriovfecv2.ManualModificationRevertedCondition)).To(BeTrue())
+		Expect(conditions).ToNot(HaveKey("untouched"))
+	})
+})
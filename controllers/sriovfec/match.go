@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+)
+
+// SelectorMatch is an accelerator selected by node and accelerator selectors of a ClusterConfig
+type SelectorMatch struct {
+	NodeName   string
+	PCIAddress string
+	DeviceID   string
+	// ConfiguredBy is a name of the config the accelerator would be configured by; it differs from the matched config
+	// when the accelerator is overridden by a config of higher priority
+	ConfiguredBy string
+}
+
+// MatchClusterConfig returns accelerators of the live cluster selected by the ClusterConfig, as if it was applied along
+// with existing ClusterConfigs; existing ClusterConfig of the same name is replaced. Nothing is changed in the cluster.
+func MatchClusterConfig(ctx context.Context, c client.Reader, cc sriovfecv2.SriovFecClusterConfig, log *logrus.Logger) ([]SelectorMatch, error) {
+	clusterConfigList := new(sriovfecv2.SriovFecClusterConfigList)
+	if err := c.List(ctx, clusterConfigList, client.InNamespace(NAMESPACE)); err != nil {
+		return nil, err
+	}
+
+	// the config is matched as if it was created now, unless it replaces an existing one
+	cc.CreationTimestamp = metav1.Now()
+	configs := []sriovfecv2.SriovFecClusterConfig{cc}
+	applied, _ := splitDryRuns(clusterConfigList.Items)
+	for _, existing := range applied {
+		if existing.Name == cc.Name {
+			configs[0].CreationTimestamp = existing.CreationTimestamp
+			continue
+		}
+		configs = append(configs, existing)
+	}
+
+	nodes := new(corev1.NodeList)
+	labelsToMatch, err := nodescope.AcceleratedNodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.List(ctx, nodes, labelsToMatch); err != nil {
+		return nil, err
+	}
+	sort.Slice(nodes.Items, func(i, j int) bool {
+		return nodes.Items[i].Name < nodes.Items[j].Name
+	})
+
+	matcher := createClusterConfigMatcher(func(nodeName string) (*sriovfecv2.SriovFecNodeConfig, error) {
+		nc := new(sriovfecv2.SriovFecNodeConfig)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: nodeName}, nc); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			// the node's inventory isn't reported yet, so no accelerator is matched
+			nc.Name = nodeName
+		}
+		return nc, nil
+	}, log)
+
+	var matches []SelectorMatch
+	for _, node := range nodes.Items {
+		if len(matchConfigsForNode(&node, []sriovfecv2.SriovFecClusterConfig{cc})) == 0 {
+			continue
+		}
+		ncc, err := matcher.match(node, configs)
+		if err != nil {
+			return nil, err
+		}
+		for _, acc := range ncc.Status.Inventory.SriovAccelerators {
+			if !cc.Spec.AcceleratorSelector.Matches(acc) {
+				continue
+			}
+			configuredBy, _ := ncc.AcceleratorConfigContext.Get(acc.PCIAddress)
+			matches = append(matches, SelectorMatch{NodeName: node.Name, PCIAddress: acc.PCIAddress, DeviceID: acc.DeviceID,
+				ConfiguredBy: configuredBy.Name})
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+)
+
+var _ = Describe("MatchClusterConfig", func() {
+	node := func(name string, labels map[string]string) *corev1.Node {
+		labels["fpga.intel.com/intel-accelerator-present"] = ""
+		return &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	nodeConfig := func(name string, pciAddresses ...string) *sriovfecv2.SriovFecNodeConfig {
+		nc := &sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: NAMESPACE}}
+		for _, pciAddress := range pciAddresses {
+			nc.Status.Inventory.SriovAccelerators = append(nc.Status.Inventory.SriovAccelerators,
+				sriovfecv2.SriovAccelerator{PCIAddress: pciAddress, DeviceID: "0d5c"})
+		}
+		return nc
+	}
+
+	It("lists accelerators selected by the config and configs they would be configured by", func() {
+		existing := &sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "card", Namespace: NAMESPACE, CreationTimestamp: v1.NewTime(time.Now().Add(-time.Hour))},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				Priority:            10,
+				AcceleratorSelector: sriovfecv2.AcceleratorSelector{PCIAddress: "0000:15:00.0"},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			node("edge-b", map[string]string{"site": "edge"}), node("edge-a", map[string]string{"site": "edge"}),
+			node("core", map[string]string{"site": "core"}), node("new", map[string]string{"site": "edge"}),
+			nodeConfig("edge-a", "0000:14:00.0", "0000:15:00.0"), nodeConfig("edge-b", "0000:14:00.0"),
+			nodeConfig("core", "0000:14:00.0"), existing).Build()
+
+		cc := sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "edge", Namespace: NAMESPACE},
+			Spec:       sriovfecv2.SriovFecClusterConfigSpec{Priority: 1, NodeSelector: map[string]string{"site": "edge"}},
+		}
+		matches, err := MatchClusterConfig(context.TODO(), c, cc, logrus.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(Equal([]SelectorMatch{
+			{NodeName: "edge-a", PCIAddress: "0000:14:00.0", DeviceID: "0d5c", ConfiguredBy: "edge"},
+			{NodeName: "edge-a", PCIAddress: "0000:15:00.0", DeviceID: "0d5c", ConfiguredBy: "card"},
+			{NodeName: "edge-b", PCIAddress: "0000:14:00.0", DeviceID: "0d5c", ConfiguredBy: "edge"},
+		}))
+
+		// the config replaces existing one of the same name
+		cc.Name = "card"
+		matches, err = MatchClusterConfig(context.TODO(), c, cc, logrus.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(HaveLen(3))
+		Expect(matches[1].ConfiguredBy).To(Equal("card"))
+	})
+
+	It("doesn't select nodes out of the scope of the operator", func() {
+		Expect(os.Setenv(nodescope.EnvName, "ran-vendor=a")).To(Succeed())
+		defer os.Unsetenv(nodescope.EnvName)
+
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			node("pool-a", map[string]string{"ran-vendor": "a"}), node("pool-b", map[string]string{"ran-vendor": "b"}),
+			nodeConfig("pool-a", "0000:14:00.0"), nodeConfig("pool-b", "0000:14:00.0")).Build()
+
+		cc := sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "all", Namespace: NAMESPACE}}
+		matches, err := MatchClusterConfig(context.TODO(), c, cc, logrus.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matches).To(Equal([]SelectorMatch{
+			{NodeName: "pool-a", PCIAddress: "0000:14:00.0", DeviceID: "0d5c", ConfiguredBy: "all"},
+		}))
+	})
+})
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NodeMigration", func() {
+	newNodeConfig := func(name string, serialNumbers ...string) sriovfecv2.SriovFecNodeConfig {
+		nc := sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: NAMESPACE}}
+		for _, serialNumber := range serialNumbers {
+			nc.Status.Inventory.SriovAccelerators = append(nc.Status.Inventory.SriovAccelerators,
+				sriovfecv2.SriovAccelerator{PCIAddress: "0000:14:00.0", SerialNumber: serialNumber})
+		}
+		return nc
+	}
+	newNode := func(name string) corev1.Node {
+		return corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name}}
+	}
+
+	It("matches NodeConfigs of removed nodes with accelerated nodes by serial number", func() {
+		nodes := []corev1.Node{newNode("node-new"), newNode("node-other")}
+		replacements := findNodeReplacements([]sriovfecv2.SriovFecNodeConfig{
+			newNodeConfig("node-new", "00-01"),
+			newNodeConfig("node-old", "00-01"),
+			newNodeConfig("node-other", "00-02"),
+			newNodeConfig("node-removed", "00-03"),
+		}, nodes, nodes)
+
+		Expect(replacements).To(HaveLen(1))
+		Expect(replacements[0].previous.Name).To(Equal("node-old"))
+		Expect(replacements[0].current.Name).To(Equal("node-new"))
+	})
+
+	It("doesn't migrate to nodes without accelerators or by accelerators without serial number", func() {
+		nodes := []corev1.Node{newNode("node-new"), newNode("node-unaccelerated")}
+		Expect(findNodeReplacements([]sriovfecv2.SriovFecNodeConfig{
+			newNodeConfig("node-new", ""),
+			newNodeConfig("node-old", ""),
+			newNodeConfig("node-unaccelerated", "00-01"),
+			newNodeConfig("node-removed", "00-01"),
+		}, nodes, []corev1.Node{newNode("node-new")})).To(BeEmpty())
+	})
+
+	It("migrates NodeConfig of removed node to a single node", func() {
+		nodes := []corev1.Node{newNode("node-a"), newNode("node-b")}
+		replacements := findNodeReplacements([]sriovfecv2.SriovFecNodeConfig{
+			newNodeConfig("node-a", "00-01"),
+			newNodeConfig("node-b", "00-02"),
+			newNodeConfig("node-old", "00-01", "00-02"),
+		}, nodes, nodes)
+
+		Expect(replacements).To(HaveLen(1))
+		Expect(replacements[0].current.Name).To(Equal("node-a"))
+	})
+
+	It("keeps outcome of the last configuration and the last change of replaced node in the message", func() {
+		previous := newNodeConfig("node-old", "00-01")
+		Expect(migratedMessage(&previous)).To(Equal("migrated from SriovFecNodeConfig of replaced node node-old"))
+
+		previous.Annotations = map[string]string{sriovfecv2.ChangeSummaryAnnotation: "0000:14:00.0: numVfs 0 -> 2"}
+		previous.Status.Conditions = []v1.Condition{{Type: nodeConfiguredCondition, Status: v1.ConditionTrue, Reason: "Succeeded",
+			Message: "Configured successfully"}}
+		Expect(migratedMessage(&previous)).To(Equal("migrated from SriovFecNodeConfig of replaced node node-old; " +
+			"its last configuration: Succeeded (Configured successfully); its last change: 0000:14:00.0: numVfs 0 -> 2"))
+	})
+})
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/notification"
+)
+
+// criticalNotifications returns notifications of nodes which configuration failed since the previous status of the
+// ClusterConfig, e.g. pf_bb_config failed or the daemon rolled back to the last-known-good configuration, and of the
+// ClusterConfig becoming degraded for other reasons than failed nodes
+func criticalNotifications(cc *sriovfecv2.SriovFecClusterConfig, status sriovfecv2.SriovFecClusterConfigStatus) []notification.Notification {
+	previous := map[string]string{}
+	for _, failure := range cc.Status.NodeFailures {
+		previous[failure.NodeName] = failure.Message
+	}
+
+	var notifications []notification.Notification
+	for _, failure := range status.NodeFailures {
+		if message, ok := previous[failure.NodeName]; ok && message == failure.Message {
+			continue
+		}
+		notifications = append(notifications, notification.Notification{Reason: notification.NodeFailedReason,
+			Kind: "SriovFecClusterConfig", Name: cc.Name, Node: failure.NodeName, Class: failure.Reason, Message: failure.Message})
+	}
+
+	degraded := meta.FindStatusCondition(status.Conditions, sriovfecv2.DegradedCondition)
+	if len(status.NodeFailures) == 0 && degraded != nil && degraded.Status == metav1.ConditionTrue &&
+		!meta.IsStatusConditionTrue(cc.Status.Conditions, sriovfecv2.DegradedCondition) {
+		notifications = append(notifications, notification.Notification{Reason: notification.DegradedReason,
+			Kind: "SriovFecClusterConfig", Name: cc.Name, Class: degraded.Reason, Message: degraded.Message})
+	}
+	return notifications
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/notification"
+)
+
+var _ = Describe("criticalNotifications", func() {
+	degraded := func(status metav1.ConditionStatus, reason, message string) []metav1.Condition {
+		return []metav1.Condition{{Type: sriovfecv2.DegradedCondition, Status: status, Reason: reason, Message: message}}
+	}
+
+	It("notifies nodes failed since the previous status", func() {
+		cc := &sriovfecv2.SriovFecClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "config"}}
+		cc.Status.NodeFailures = []sriovfecv2.NodeFailure{
+			{NodeName: "node-1", Reason: "DeviceError", Message: "pf_bb_config failed"},
+			{NodeName: "node-2", Reason: "DeviceError", Message: "pf_bb_config failed"},
+		}
+		status := sriovfecv2.SriovFecClusterConfigStatus{
+			Conditions: degraded(metav1.ConditionTrue, "DeviceError", "node node-1: pf_bb_config failed"),
+			NodeFailures: []sriovfecv2.NodeFailure{
+				{NodeName: "node-1", Reason: "DeviceError", Message: "pf_bb_config failed"},
+				{NodeName: "node-2", Reason: "DeviceError", Message: "Configuration failed, last-known-good configuration restored"},
+				{NodeName: "node-3", Reason: "PlatformError", Message: "IOMMU disabled"},
+			},
+		}
+
+		Expect(criticalNotifications(cc, status)).To(Equal([]notification.Notification{
+			{Reason: notification.NodeFailedReason, Kind: "SriovFecClusterConfig", Name: "config", Node: "node-2",
+				Class: "DeviceError", Message: "Configuration failed, last-known-good configuration restored"},
+			{Reason: notification.NodeFailedReason, Kind: "SriovFecClusterConfig", Name: "config", Node: "node-3",
+				Class: "PlatformError", Message: "IOMMU disabled"},
+		}))
+	})
+
+	It("notifies the config becoming degraded without failed nodes once", func() {
+		cc := &sriovfecv2.SriovFecClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "config"}}
+		cc.Status.Conditions = degraded(metav1.ConditionFalse, "AsExpected", "")
+		status := sriovfecv2.SriovFecClusterConfigStatus{Conditions: degraded(metav1.ConditionTrue, "ValidationError", "profile not found")}
+
+		Expect(criticalNotifications(cc, status)).To(Equal([]notification.Notification{
+			{Reason: notification.DegradedReason, Kind: "SriovFecClusterConfig", Name: "config", Class: "ValidationError", Message: "profile not found"},
+		}))
+
+		cc.Status = status
+		Expect(criticalNotifications(cc, status)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+const clusterConfigKind = "SriovFecClusterConfig"
+
+// ownerReferences returns owner references of the NodeConfig: ClusterConfigs configuring any of its accelerators, sorted
+// by name, followed by owners which are not ClusterConfigs, e.g. set by the user. ClusterConfigs being deleted are not
+// owners anymore, so the NodeConfig isn't garbage collected along with the last ClusterConfig of the node.
+func ownerReferences(ncc NodeConfigurationCtx) []metav1.OwnerReference {
+	owners := map[string]metav1.OwnerReference{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		if !cc.DeletionTimestamp.IsZero() || cc.UID == "" {
+			continue
+		}
+		owners[cc.Name] = metav1.OwnerReference{
+			APIVersion: sriovfecv2.GroupVersion.String(),
+			Kind:       clusterConfigKind,
+			Name:       cc.Name,
+			UID:        cc.UID,
+		}
+	}
+
+	var references []metav1.OwnerReference
+	for _, owner := range owners {
+		references = append(references, owner)
+	}
+	sort.Slice(references, func(i, j int) bool {
+		return references[i].Name < references[j].Name
+	})
+
+	for _, ref := range ncc.SriovFecNodeConfig.OwnerReferences {
+		if ref.Kind != clusterConfigKind || ref.APIVersion != sriovfecv2.GroupVersion.String() {
+			references = append(references, ref)
+		}
+	}
+	return references
+}
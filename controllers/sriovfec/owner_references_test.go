@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("NodeConfig owner references", func() {
+	clusterConfig := func(name, uid string) sriovfecv2.SriovFecClusterConfig {
+		return sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: name, UID: types.UID("uid-" + uid)}}
+	}
+
+	It("references ClusterConfigs configuring accelerators once and keeps foreign owners", func() {
+		ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", clusterConfig("second", "2"))
+		ncc.AcceleratorConfigContext.Set("0000:15:00.0", clusterConfig("first", "1"))
+		ncc.AcceleratorConfigContext.Set("0000:16:00.0", clusterConfig("second", "2"))
+		ncc.SriovFecNodeConfig.OwnerReferences = []v1.OwnerReference{
+			{APIVersion: sriovfecv2.GroupVersion.String(), Kind: clusterConfigKind, Name: "removed", UID: "uid-0"},
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "foreign", UID: "uid-3"},
+		}
+
+		Expect(ownerReferences(ncc)).To(Equal([]v1.OwnerReference{
+			{APIVersion: sriovfecv2.GroupVersion.String(), Kind: clusterConfigKind, Name: "first", UID: "uid-1"},
+			{APIVersion: sriovfecv2.GroupVersion.String(), Kind: clusterConfigKind, Name: "second", UID: "uid-2"},
+			{APIVersion: "v1", Kind: "ConfigMap", Name: "foreign", UID: "uid-3"},
+		}))
+	})
+
+	It("drops ClusterConfigs being deleted", func() {
+		deleted := clusterConfig("deleted", "1")
+		now := v1.Now()
+		deleted.DeletionTimestamp = &now
+		ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", deleted)
+		ncc.SriovFecNodeConfig.OwnerReferences = []v1.OwnerReference{
+			{APIVersion: sriovfecv2.GroupVersion.String(), Kind: clusterConfigKind, Name: "deleted", UID: "uid-1"},
+		}
+
+		Expect(ownerReferences(ncc)).To(BeEmpty())
+	})
+})
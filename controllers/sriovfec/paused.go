@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// pausedBy returns name of a paused ClusterConfig rendered into the node, which NodeConfig is not synchronized until the
+// config is resumed; empty string is returned if none of them is paused
+func pausedBy(ncc NodeConfigurationCtx) string {
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		if cc.IsPaused() {
+			return cc.Name
+		}
+	}
+	return ""
+}
+
+// pausedConditions sets Paused condition of paused ClusterConfigs and removes it from the rest of them
+func pausedConditions(clusterConfigs []sriovfecv2.SriovFecClusterConfig, conditions utils.Conditions) {
+	for _, cc := range clusterConfigs {
+		if cc.IsPaused() {
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: sriovfecv2.PausedCondition, Status: metav1.ConditionTrue,
+				ObservedGeneration: cc.Generation, Reason: "Paused",
+				Message: "configuration is not propagated into NodeConfigs until " + sriovfecv2.PausedAnnotation + " annotation is removed"})
+		} else if meta.FindStatusCondition(conditions.Of(cc.Name, cc.Status.Conditions), sriovfecv2.PausedCondition) != nil {
+			conditions.Remove(cc.Name, cc.Status.Conditions, sriovfecv2.PausedCondition)
+		}
+	}
+}
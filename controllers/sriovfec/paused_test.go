@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("PausedClusterConfig", func() {
+	var cc sriovfecv2.SriovFecClusterConfig
+
+	BeforeEach(func() {
+		cc = sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "config", Namespace: NAMESPACE,
+			Annotations: map[string]string{sriovfecv2.PausedAnnotation: "true"}}}
+	})
+
+	It("holds nodes of paused config", func() {
+		ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", cc)
+		Expect(pausedBy(ncc)).To(Equal("config"))
+
+		cc.Annotations[sriovfecv2.PausedAnnotation] = "false"
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", cc)
+		Expect(pausedBy(ncc)).To(BeEmpty())
+	})
+
+	It("sets Paused condition until the config is resumed", func() {
+		conditions := map[string][]v1.Condition{}
+		pausedConditions([]sriovfecv2.SriovFecClusterConfig{cc}, conditions)
+		Expect(meta.IsStatusConditionTrue(conditions["config"], sriovfecv2.PausedCondition)).To(BeTrue())
+
+		cc.Status.Conditions = conditions["config"]
+		delete(cc.Annotations, sriovfecv2.PausedAnnotation)
+		conditions = map[string][]v1.Condition{}
+		pausedConditions([]sriovfecv2.SriovFecClusterConfig{cc}, conditions)
+		Expect(meta.FindStatusCondition(conditions["config"], sriovfecv2.PausedCondition)).To(BeNil())
+	})
+})
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// errProfileNotFound is returned when bbDevConfig references SriovFecProfile which doesn't exist
+var errProfileNotFound = errors.New("SriovFecProfile not found")
+
+// fecProfiles keeps bbDevConfig of SriovFecProfiles keyed by their names
+type fecProfiles map[string]sriovfecv2.BBDevConfig
+
+// +kubebuilder:rbac:groups=sriovfec.intel.com,resources=sriovfecprofiles,verbs=get;list;watch
+
+func (r *SriovFecClusterConfigReconciler) listProfiles(ctx context.Context) (fecProfiles, error) {
+	list := new(sriovfecv2.SriovFecProfileList)
+	if err := r.List(ctx, list, client.InNamespace(NAMESPACE)); err != nil {
+		return nil, err
+	}
+	profiles := fecProfiles{}
+	for _, profile := range list.Items {
+		profiles[profile.Name] = profile.Spec.BBDevConfig
+	}
+	return profiles, nil
+}
+
+// resolve replaces bbDevConfig of the physical function referencing SriovFecProfile with bbDevConfig of the profile
+func (profiles fecProfiles) resolve(pf *sriovfecv2.PhysicalFunctionConfigExt) error {
+	name := pf.BBDevConfig.ProfileRef
+	if name == "" {
+		return nil
+	}
+	config, ok := profiles[name]
+	if !ok {
+		return errclass.Wrap(errclass.Validation, fmt.Errorf("%w: %s", errProfileNotFound, name))
+	}
+	if config.ProfileRef != "" {
+		return errclass.New(errclass.Validation, "SriovFecProfile %s cannot reference another SriovFecProfile", name)
+	}
+	// pfMode requires opt-in of the config, so it has to be enabled in the config itself
+	if config.PFModeEnabled() {
+		return errclass.New(errclass.Validation, "SriovFecProfile %s cannot enable pfMode", name)
+	}
+	pf.BBDevConfig = config
+	return nil
+}
+
+// profileConditions sets Degraded condition of ClusterConfigs which reference missing SriovFecProfile and removes it
+// from the rest of them
+func profileConditions(clusterConfigs []sriovfecv2.SriovFecClusterConfig, syncErrors map[string][]error, conditions utils.Conditions) {
+	for _, cc := range clusterConfigs {
+		var missing error
+		for _, err := range syncErrors[cc.Name] {
+			if errors.Is(err, errProfileNotFound) {
+				missing = err
+				break
+			}
+		}
+
+		if missing != nil {
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: sriovfecv2.DegradedCondition, Status: metav1.ConditionTrue,
+				ObservedGeneration: cc.Generation, Reason: sriovfecv2.ProfileNotFoundReason, Message: missing.Error()})
+			continue
+		}
+		// Degraded condition of failed nodes is kept
+		degraded := meta.FindStatusCondition(conditions.Of(cc.Name, cc.Status.Conditions), sriovfecv2.DegradedCondition)
+		if degraded != nil && degraded.Reason == sriovfecv2.ProfileNotFoundReason {
+			conditions.Remove(cc.Name, cc.Status.Conditions, sriovfecv2.DegradedCondition)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SriovFecProfile", func() {
+	profiles := fecProfiles{
+		"acc100-5g-default": sriovfecv2.BBDevConfig{ACC100: sriovfecv2.RecommendedACC100BBDevConfig()},
+		"nested":            sriovfecv2.BBDevConfig{ProfileRef: "acc100-5g-default"},
+	}
+
+	pf := func(profileRef string) sriovfecv2.PhysicalFunctionConfigExt {
+		return sriovfecv2.PhysicalFunctionConfigExt{PCIAddress: "0000:14:00.0", VFAmount: 16,
+			BBDevConfig: sriovfecv2.BBDevConfig{ProfileRef: profileRef}}
+	}
+
+	It("replaces bbDevConfig with the one of referenced profile", func() {
+		p := pf("acc100-5g-default")
+		Expect(profiles.resolve(&p)).To(Succeed())
+		Expect(p.BBDevConfig.ProfileRef).To(BeEmpty())
+		Expect(p.BBDevConfig.ACC100).ToNot(BeNil())
+	})
+
+	It("rejects missing and nested profiles", func() {
+		p := pf("missing")
+		err := profiles.resolve(&p)
+		Expect(err).To(MatchError(errProfileNotFound))
+		Expect(errclass.Of(err)).To(Equal(errclass.Validation))
+
+		p = pf("nested")
+		Expect(profiles.resolve(&p)).ToNot(Succeed())
+	})
+
+	It("sets Degraded condition of ClusterConfig referencing missing profile until it's created", func() {
+		clusterConfigs := []sriovfecv2.SriovFecClusterConfig{{ObjectMeta: metav1.ObjectMeta{Name: "config"}}}
+		p := pf("missing")
+		err := &unschedulableError{nodeName: "node", pciAddress: p.PCIAddress, err: profiles.resolve(&p)}
+
+		conditions := map[string][]metav1.Condition{}
+		profileConditions(clusterConfigs, map[string][]error{"config": {err}}, conditions)
+		condition := meta.FindStatusCondition(conditions["config"], sriovfecv2.DegradedCondition)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(sriovfecv2.ProfileNotFoundReason))
+
+		clusterConfigs[0].Status.Conditions = conditions["config"]
+		conditions = map[string][]metav1.Condition{"config": clusterConfigs[0].Status.Conditions}
+		profileConditions(clusterConfigs, map[string][]error{}, conditions)
+		Expect(meta.FindStatusCondition(conditions["config"], sriovfecv2.DegradedCondition)).To(BeNil())
+	})
+})
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+const defaultRevisionHistoryLimit = 10
+
+// +kubebuilder:rbac:groups=sriovfec.intel.com,resources=sriovfecclusterconfigrevisions,verbs=get;list;watch;create;delete
+
+// manageRevisions restores spec of the ClusterConfig requesting rollback, captures its current spec as a new revision
+// if it differs from the latest one and prunes revisions exceeding the history limit
+func (r *SriovFecClusterConfigReconciler) manageRevisions(ctx context.Context, cc *sriovfecv2.SriovFecClusterConfig) error {
+	revisions, err := r.listRevisions(ctx, cc)
+	if err != nil {
+		return err
+	}
+
+	if cc.Spec.RollbackTo != nil {
+		if err := r.rollback(ctx, cc, revisions); err != nil {
+			return err
+		}
+	}
+
+	spec := revisionSpec(cc.Spec)
+	if len(revisions) == 0 || !equality.Semantic.DeepEqual(revisions[len(revisions)-1].Spec, spec) {
+		var number int64 = 1
+		if len(revisions) != 0 {
+			number = revisions[len(revisions)-1].Revision + 1
+		}
+		revision := &sriovfecv2.SriovFecClusterConfigRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", cc.Name, number),
+				Namespace: cc.Namespace,
+				Labels:    map[string]string{sriovfecv2.ClusterConfigLabel: cc.Name},
+			},
+			Revision: number,
+			Spec:     spec,
+		}
+		if err := controllerutil.SetControllerReference(cc, revision, r.Scheme()); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, revision); err != nil {
+			return err
+		}
+		r.Log.WithField("name", cc.Name).WithField("revision", number).Info("captured SriovFecClusterConfig revision")
+		revisions = append(revisions, *revision)
+	}
+
+	current := strconv.FormatInt(revisions[len(revisions)-1].Revision, 10)
+	if cc.Annotations[sriovfecv2.RevisionAnnotation] != current {
+		patch := client.MergeFrom(cc.DeepCopy())
+		if cc.Annotations == nil {
+			cc.Annotations = map[string]string{}
+		}
+		cc.Annotations[sriovfecv2.RevisionAnnotation] = current
+		if err := r.Patch(ctx, cc, patch); err != nil {
+			return err
+		}
+	}
+
+	return r.pruneRevisions(ctx, cc, revisions)
+}
+
+// rollback restores spec of the ClusterConfig from the requested revision and clears spec.rollbackTo; request of
+// a revision which doesn't exist is reported in a Warning Event and dropped
+func (r *SriovFecClusterConfigReconciler) rollback(ctx context.Context, cc *sriovfecv2.SriovFecClusterConfig, revisions []sriovfecv2.SriovFecClusterConfigRevision) error {
+	requested := *cc.Spec.RollbackTo
+	spec := cc.Spec.DeepCopy()
+	spec.RollbackTo = nil
+
+	found := false
+	for _, revision := range revisions {
+		if revision.Revision == requested {
+			restored := revision.Spec.DeepCopy()
+			restored.RevisionHistoryLimit = spec.RevisionHistoryLimit
+			spec, found = restored, true
+			break
+		}
+	}
+
+	log := r.Log.WithField("name", cc.Name).WithField("revision", requested)
+	if found {
+		log.Info("rolling SriovFecClusterConfig back to revision")
+	} else {
+		log.Error("requested revision of SriovFecClusterConfig not found, rollback is dropped")
+		if r.Recorder != nil {
+			r.Recorder.Eventf(cc, corev1.EventTypeWarning, "RollbackRevisionNotFound", "revision %d not found", requested)
+		}
+	}
+
+	updated := cc.DeepCopy()
+	updated.Spec = *spec
+	if err := r.Update(ctx, updated); err != nil {
+		return err
+	}
+	*cc = *updated
+	return nil
+}
+
+// pruneRevisions deletes the oldest revisions exceeding the history limit of the ClusterConfig
+func (r *SriovFecClusterConfigReconciler) pruneRevisions(ctx context.Context, cc *sriovfecv2.SriovFecClusterConfig, revisions []sriovfecv2.SriovFecClusterConfigRevision) error {
+	limit := defaultRevisionHistoryLimit
+	if cc.Spec.RevisionHistoryLimit != nil {
+		limit = int(*cc.Spec.RevisionHistoryLimit)
+	}
+	for i := 0; i < len(revisions)-limit; i++ {
+		if err := r.Delete(ctx, &revisions[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listRevisions returns revisions of the ClusterConfig sorted by revision number
+func (r *SriovFecClusterConfigReconciler) listRevisions(ctx context.Context, cc *sriovfecv2.SriovFecClusterConfig) ([]sriovfecv2.SriovFecClusterConfigRevision, error) {
+	list := new(sriovfecv2.SriovFecClusterConfigRevisionList)
+	if err := r.List(ctx, list, client.InNamespace(cc.Namespace), client.MatchingLabels{sriovfecv2.ClusterConfigLabel: cc.Name}); err != nil {
+		return nil, err
+	}
+
+	var revisions []sriovfecv2.SriovFecClusterConfigRevision
+	for _, revision := range list.Items {
+		// revision of a deleted ClusterConfig recreated with the same name is not garbage collected yet
+		if metav1.IsControlledBy(&revision, cc) {
+			revisions = append(revisions, revision)
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+// revisionSpec returns spec of the ClusterConfig captured by revisions, i.e. without rollback request
+func revisionSpec(spec sriovfecv2.SriovFecClusterConfigSpec) sriovfecv2.SriovFecClusterConfigSpec {
+	captured := spec.DeepCopy()
+	captured.RollbackTo = nil
+	return *captured
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("ClusterConfig revisions", func() {
+	var (
+		reconciler *SriovFecClusterConfigReconciler
+		cc         *sriovfecv2.SriovFecClusterConfig
+	)
+
+	revisions := func() []sriovfecv2.SriovFecClusterConfigRevision {
+		list, err := reconciler.listRevisions(context.TODO(), cc)
+		Expect(err).ToNot(HaveOccurred())
+		return list
+	}
+
+	update := func(mutate func(spec *sriovfecv2.SriovFecClusterConfigSpec)) {
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), cc)).To(Succeed())
+		mutate(&cc.Spec)
+		Expect(k8sClient.Update(context.TODO(), cc)).To(Succeed())
+		Expect(reconciler.manageRevisions(context.TODO(), cc)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		reconciler = &SriovFecClusterConfigReconciler{Client: k8sClient, Log: logrus.New()}
+		cc = &sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: "revisioned", Namespace: NAMESPACE},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				Priority:         1,
+				PhysicalFunction: clusterConfigPrototype.Spec.PhysicalFunction,
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), cc)).To(Succeed())
+		Expect(reconciler.manageRevisions(context.TODO(), cc)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), cc))).To(Succeed())
+		for _, revision := range revisions() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), &revision))).To(Succeed())
+		}
+	})
+
+	It("captures each spec of the ClusterConfig", func() {
+		Expect(revisions()).To(HaveLen(1))
+		Expect(cc.Annotations).To(HaveKeyWithValue(sriovfecv2.RevisionAnnotation, "1"))
+
+		Expect(reconciler.manageRevisions(context.TODO(), cc)).To(Succeed())
+		Expect(revisions()).To(HaveLen(1))
+
+		update(func(spec *sriovfecv2.SriovFecClusterConfigSpec) { spec.Priority = 2 })
+		Expect(revisions()).To(HaveLen(2))
+		Expect(revisions()[1].Spec.Priority).To(Equal(2))
+		Expect(cc.Annotations).To(HaveKeyWithValue(sriovfecv2.RevisionAnnotation, "2"))
+	})
+
+	It("rolls the ClusterConfig back to the requested revision", func() {
+		update(func(spec *sriovfecv2.SriovFecClusterConfigSpec) { spec.Priority = 2 })
+		update(func(spec *sriovfecv2.SriovFecClusterConfigSpec) { spec.RollbackTo = pointer.Int64(1) })
+
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), cc)).To(Succeed())
+		Expect(cc.Spec.RollbackTo).To(BeNil())
+		Expect(cc.Spec.Priority).To(Equal(1))
+		Expect(revisions()).To(HaveLen(3))
+		Expect(cc.Annotations).To(HaveKeyWithValue(sriovfecv2.RevisionAnnotation, "3"))
+	})
+
+	It("drops rollback to a revision which does not exist", func() {
+		update(func(spec *sriovfecv2.SriovFecClusterConfigSpec) { spec.RollbackTo = pointer.Int64(7) })
+
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cc), cc)).To(Succeed())
+		Expect(cc.Spec.RollbackTo).To(BeNil())
+		Expect(revisions()).To(HaveLen(1))
+	})
+
+	It("keeps revisions up to the history limit", func() {
+		update(func(spec *sriovfecv2.SriovFecClusterConfigSpec) { spec.RevisionHistoryLimit = pointer.Int32(2) })
+		update(func(spec *sriovfecv2.SriovFecClusterConfigSpec) { spec.Priority = 2 })
+
+		Expect(revisions()).To(HaveLen(2))
+		Expect(revisions()[0].Revision).To(Equal(int64(2)))
+	})
+
+	It("passes changes of annotations other than the revision annotation", func() {
+		oldCc, newCc := cc.DeepCopy(), cc.DeepCopy()
+		newCc.Annotations = map[string]string{sriovfecv2.RevisionAnnotation: "2"}
+		Expect(isUserAnnotationChange.Update(event.UpdateEvent{ObjectOld: oldCc, ObjectNew: newCc})).To(BeFalse())
+
+		oldCc = newCc.DeepCopy()
+		newCc.Annotations[sriovfecv2.PausedAnnotation] = "true"
+		Expect(isUserAnnotationChange.Update(event.UpdateEvent{ObjectOld: oldCc, ObjectNew: newCc})).To(BeTrue())
+	})
+})
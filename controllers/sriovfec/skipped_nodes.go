@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+)
+
+// skippedNodes keeps nodes selected by nodeSelector of ClusterConfigs, which are not configured, keyed by name of the
+// ClusterConfig
+type skippedNodes map[string][]sriovfecv2.SkippedNode
+
+// findSkippedNodes lists nodes within the scope of the operator to find nodes selected by ClusterConfigs, which are not
+// configured as they don't exist or have no accelerators, so typos in nodeSelector are discoverable in the status
+func (r *SriovFecClusterConfigReconciler) findSkippedNodes(ctx context.Context, acceleratedNodes []corev1.Node,
+	clusterConfigs []sriovfecv2.SriovFecClusterConfig) skippedNodes {
+	nl := new(corev1.NodeList)
+	scope, err := nodescope.Labels()
+	if err == nil {
+		err = r.List(ctx, nl, client.MatchingLabels(scope))
+	}
+	if err != nil {
+		r.Log.WithError(err).Error("cannot obtain list of nodes to find nodes skipped by SriovFecClusterConfigs")
+		return skippedNodes{}
+	}
+	return newSkippedNodes(nl.Items, acceleratedNodes, clusterConfigs)
+}
+
+// newSkippedNodes reports a node named by kubernetes.io/hostname label of nodeSelector, which doesn't exist, and nodes
+// selected by non-empty nodeSelector, which are not accelerated. Nodes selected by an empty nodeSelector are not
+// reported, as it selects also nodes which are not supposed to have accelerators, e.g. control plane nodes.
+func newSkippedNodes(nodes []corev1.Node, acceleratedNodes []corev1.Node, clusterConfigs []sriovfecv2.SriovFecClusterConfig) skippedNodes {
+	skipped := skippedNodes{}
+	for _, cc := range clusterConfigs {
+		if len(cc.Spec.NodeSelector) == 0 {
+			continue
+		}
+
+		if hostname, ok := cc.Spec.NodeSelector[corev1.LabelHostname]; ok &&
+			!slices.ContainsFunc(nodes, func(node corev1.Node) bool { return node.Labels[corev1.LabelHostname] == hostname }) {
+			skipped[cc.Name] = append(skipped[cc.Name], sriovfecv2.SkippedNode{
+				NodeName: hostname,
+				Reason:   sriovfecv2.NodeNotFoundReason,
+				Message:  fmt.Sprintf("node with %s=%s label doesn't exist", corev1.LabelHostname, hostname),
+			})
+		}
+
+		selector := labels.Set(cc.Spec.NodeSelector).AsSelector()
+		for _, node := range nodes {
+			if !selector.Matches(labels.Set(node.Labels)) ||
+				slices.ContainsFunc(acceleratedNodes, func(accelerated corev1.Node) bool { return accelerated.Name == node.Name }) {
+				continue
+			}
+			skipped[cc.Name] = append(skipped[cc.Name], sriovfecv2.SkippedNode{
+				NodeName: node.Name,
+				Reason:   sriovfecv2.NoAcceleratorsReason,
+				Message:  "NFD doesn't report accelerators of the node",
+			})
+		}
+	}
+	return skipped
+}
+
+// of returns nodes skipped by the ClusterConfig sorted by node, so the status isn't updated when nodes are listed in
+// different order
+func (s skippedNodes) of(name string) []sriovfecv2.SkippedNode {
+	nodes := s[name]
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeName < nodes[j].NodeName })
+	return nodes
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SkippedNodes", func() {
+	newNode := func(name string, labels map[string]string) corev1.Node {
+		node := corev1.Node{ObjectMeta: v1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelHostname: name}}}
+		for key, value := range labels {
+			node.Labels[key] = value
+		}
+		return node
+	}
+	newClusterConfig := func(name string, nodeSelector map[string]string) sriovfecv2.SriovFecClusterConfig {
+		return sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec:       sriovfecv2.SriovFecClusterConfigSpec{NodeSelector: nodeSelector},
+		}
+	}
+
+	accelerated := newNode("node-b", map[string]string{"site": "a"})
+	notAccelerated := newNode("node-a", map[string]string{"site": "a"})
+	nodes := []corev1.Node{accelerated, notAccelerated}
+
+	It("reports nodes without accelerators and nonexistent nodes", func() {
+		skipped := newSkippedNodes(nodes, []corev1.Node{accelerated}, []sriovfecv2.SriovFecClusterConfig{
+			newClusterConfig("site", map[string]string{"site": "a"}),
+			newClusterConfig("typo", map[string]string{corev1.LabelHostname: "node-c"}),
+			newClusterConfig("configured", map[string]string{corev1.LabelHostname: "node-b"}),
+		})
+
+		Expect(skipped.of("site")).To(Equal([]sriovfecv2.SkippedNode{
+			{NodeName: "node-a", Reason: sriovfecv2.NoAcceleratorsReason, Message: "NFD doesn't report accelerators of the node"},
+		}))
+		Expect(skipped.of("typo")).To(Equal([]sriovfecv2.SkippedNode{
+			{NodeName: "node-c", Reason: sriovfecv2.NodeNotFoundReason, Message: "node with kubernetes.io/hostname=node-c label doesn't exist"},
+		}))
+		Expect(skipped.of("configured")).To(BeEmpty())
+	})
+
+	It("doesn't report nodes selected by empty nodeSelector", func() {
+		skipped := newSkippedNodes(nodes, []corev1.Node{accelerated}, []sriovfecv2.SriovFecClusterConfig{
+			newClusterConfig("all", nil),
+		})
+		Expect(skipped.of("all")).To(BeEmpty())
+	})
+})
@@ -22,8 +22,10 @@ package sriovfec
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/elliotchance/orderedmap/v2"
@@ -35,12 +37,25 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/audit"
+	"github.com/intel/sriov-fec-operator/pkg/common/backoff"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+	"github.com/intel/sriov-fec-operator/pkg/common/notification"
+	"github.com/intel/sriov-fec-operator/pkg/common/upgradeguard"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 )
 
 var NAMESPACE = os.Getenv("SRIOV_FEC_NAMESPACE")
@@ -49,6 +64,17 @@ var NAMESPACE = os.Getenv("SRIOV_FEC_NAMESPACE")
 type SriovFecClusterConfigReconciler struct {
 	client.Client
 	Log *logrus.Logger
+	// Recorder emits Events on ClusterConfigs and NodeConfigs about rendered configuration and failures, optional
+	Recorder record.EventRecorder
+	// UpgradeGuard defers disruptive reconfigurations of nodes while the cluster is being upgraded, optional
+	UpgradeGuard *upgradeguard.Guard
+	// Notifier pushes failures of nodes and degradation of ClusterConfigs to configured notification sinks, optional
+	Notifier *notification.Notifier
+	// Backoff delays retries of failed reconciliations, errors are returned to controller-runtime if not set
+	Backoff *backoff.Backoff
+
+	capabilities nodeCapabilitiesCache
+	reverts      audit.Reverts
 }
 
 // +kubebuilder:rbac:groups=sriovfec.intel.com,resources=sriovfecclusterconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -59,39 +85,99 @@ type SriovFecClusterConfigReconciler struct {
 // +kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts;secrets;configmaps,verbs=get;list;create;update
 // +kubebuilder:rbac:groups=apps,resources=daemonsets;deployments;deployments/finalizers,verbs=get;list;create;update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings;clusterroles;clusterrolebindings,verbs=get;list;create;update
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;create;update;delete
 // +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=use,resourceNames=privileged
 // +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
 
-func (r *SriovFecClusterConfigReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *SriovFecClusterConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.Log.Infof("Reconcile(...) triggered by %s", req.NamespacedName.String())
 
 	clusterConfigList := new(sriovfecv2.SriovFecClusterConfigList)
-	if err := r.List(context.TODO(), clusterConfigList, client.InNamespace(NAMESPACE)); err != nil {
+	if err := r.List(ctx, clusterConfigList, client.InNamespace(NAMESPACE)); err != nil {
 		r.Log.WithError(err).Error("cannot obtain list of SriovFecClusterConfig, rescheduling rescheduling reconcile call")
-		return ctrl.Result{}, err
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
 	}
 
-	nodes, err := r.getAcceleratedNodes()
+	for i := range clusterConfigList.Items {
+		cc := &clusterConfigList.Items[i]
+		if err := r.manageRevisions(ctx, cc); err != nil {
+			r.Log.WithError(err).WithField("name", cc.Name).Error("failed to manage revisions of SriovFecClusterConfig")
+		}
+	}
+
+	nodes, err := r.getAcceleratedNodes(ctx)
 	if err != nil {
 		r.Log.WithError(err).Info("cannot obtain list of accelerated nodes, rescheduling rescheduling reconcile call")
-		return reconcile.Result{}, err
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
+	}
+	r.migrateReplacedNodes(ctx, nodes)
+
+	profiles, err := r.listProfiles(ctx)
+	if err != nil {
+		r.Log.WithError(err).Error("cannot obtain list of SriovFecProfiles, rescheduling reconcile call")
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
 	}
 
-	clusterConfigurationMatcher := createClusterConfigMatcher(r.getOrInitializeSriovFecNodeConfig, r.Log)
+	// syncErrors keeps errors of ClusterConfigs, keyed by ClusterConfig name
+	syncErrors := map[string][]error{}
+
+	// ClusterConfigs with spec.dryRun are not applied, changes they would make are only reported in their status
+	applied, dryRuns := splitDryRuns(r.manageFinalizers(ctx, clusterConfigList.Items))
+	previews := dryRunChanges{}
+
+	canaries := newCanaryRollouts(applied, time.Now())
+	overridden := overriddenAccelerators{}
+	effective := new(EffectiveConfiguration)
+	rollouts := configRollouts{}
+
+	policy := lostAcceleratorPolicy(r.Log)
+	clusterConfigurationMatcher := createClusterConfigMatcher(func(nodeName string) (*sriovfecv2.SriovFecNodeConfig, error) {
+		return r.getOrInitializeSriovFecNodeConfig(ctx, nodeName)
+	}, r.Log)
 	for _, node := range nodes {
-		configurationContextProvider, err := clusterConfigurationMatcher.match(node, clusterConfigList.Items)
+		for _, dryRun := range dryRuns {
+			r.preview(node, clusterConfigurationMatcher, applied, dryRun, profiles, policy, previews, syncErrors)
+		}
+
+		configurationContextProvider, err := clusterConfigurationMatcher.match(node, applied)
 		if err != nil {
 			r.Log.WithField("node", node.Name).WithField("error", err).Info("Error when matching SriovFecClusterConfigs")
 			continue
 		}
 
-		if err := r.synchronizeNodeConfigSpec(*configurationContextProvider); err != nil {
-			r.Log.WithField("name", node.Name).WithField("error", err).Info("failed to propagate configuration into SriovFecNodeConfig")
+		overridden.record(&node, *configurationContextProvider, applied)
+		effective.record(*configurationContextProvider, profiles, policy)
+
+		if cc := pausedBy(*configurationContextProvider); cc != "" {
+			r.Log.WithField("node", node.Name).WithField("SriovFecClusterConfig", cc).Info("SriovFecClusterConfig is paused, configuration is not propagated")
+			rollouts.hold(node, *configurationContextProvider)
+			continue
+		}
+
+		if cc := canaries.heldBy(node, *configurationContextProvider); cc != "" {
+			r.Log.WithField("node", node.Name).WithField("SriovFecClusterConfig", cc).Info("waiting for canary nodes, configuration is not propagated")
+			rollouts.hold(node, *configurationContextProvider)
+			continue
+		}
+
+		updated := false
+		err = r.validateNodeCapabilities(*configurationContextProvider, profiles, syncErrors)
+		if err == nil {
+			updated, err = r.synchronizeNodeConfigSpec(ctx, *configurationContextProvider, profiles, policy)
+			rollouts.observe(node, *configurationContextProvider, updated, err)
+		} else {
+			// validation errors are already recorded for responsible configs
+			rollouts.hold(node, *configurationContextProvider)
+		}
+		canaries.observe(node, *configurationContextProvider, updated, err)
+		if err != nil {
+			r.Log.WithField("name", node.Name).WithField("error", err).WithField("class", errclass.Record(errclass.Operator, err)).
+				Info("failed to propagate configuration into SriovFecNodeConfig")
 
 			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 				snc := new(sriovfecv2.SriovFecNodeConfig)
-				if err := r.Get(context.TODO(), types.NamespacedName{Namespace: NAMESPACE, Name: node.Name}, snc); err != nil {
+				if err := r.Get(ctx, types.NamespacedName{Namespace: NAMESPACE, Name: node.Name}, snc); err != nil {
 					return err
 				}
 
@@ -99,7 +185,7 @@ func (r *SriovFecClusterConfigReconciler) Reconcile(_ context.Context, req ctrl.
 				r.Log.
 					WithField("sfnc", snc).
 					Info("updating svnc status")
-				return r.Status().Update(context.TODO(), snc)
+				return r.Status().Update(ctx, snc)
 			})
 
 			if err != nil {
@@ -107,14 +193,158 @@ func (r *SriovFecClusterConfigReconciler) Reconcile(_ context.Context, req ctrl.
 			}
 			continue
 		}
+		r.reportLostAccelerators(ctx, node.Name, lostPhysicalFunctions(&configurationContextProvider.SriovFecNodeConfig, true), policy)
+	}
+	r.handleNodesWithoutAccelerators(ctx, nodes, policy)
+	if err := r.publishEffectiveConfig(ctx, effective); err != nil {
+		r.Log.WithError(err).Error("failed to publish effective configuration")
 	}
 
-	return r.requeueIfClusterConfigExists(req.NamespacedName)
+	conditions := canaries.conditions(syncErrors)
+	pausedConditions(clusterConfigList.Items, conditions)
+	rollouts.standardConditions(clusterConfigList.Items, syncErrors, overridden, conditions)
+	profileConditions(clusterConfigList.Items, syncErrors, conditions)
+	manualModificationConditions(clusterConfigList.Items, conditions, &r.reverts, time.Now())
+	skipped := r.findSkippedNodes(ctx, nodes, clusterConfigList.Items)
+	r.updateClusterConfigsStatus(ctx, clusterConfigList.Items, syncErrors, conditions, rollouts, overridden, previews, skipped)
+	r.releaseDeconfigured(ctx, clusterConfigList.Items, rollouts)
+
+	result, err := r.requeueIfClusterConfigExists(ctx, req.NamespacedName)
+	if err != nil {
+		r.Log.WithError(err).Error("cannot determine whether reconcile is needed, rescheduling reconcile call")
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
+	}
+	r.Backoff.Reset(req.NamespacedName.String())
+	return result, nil
 }
 
-func (r *SriovFecClusterConfigReconciler) requeueIfClusterConfigExists(cc types.NamespacedName) (ctrl.Result, error) {
+// validateNodeCapabilities validates physical functions rendered for the node against capabilities reported by its daemon,
+// so misconfiguration fails fast instead of being rejected by the daemon. Errors are recorded for responsible ClusterConfigs.
+func (r *SriovFecClusterConfigReconciler) validateNodeCapabilities(ncc NodeConfigurationCtx, profiles fecProfiles, syncErrors map[string][]error) error {
+	if !r.capabilities.contains(ncc.Name) {
+		r.capabilities.update(&ncc.SriovFecNodeConfig)
+	}
+
+	var pfs []sriovfecv2.PhysicalFunctionConfigExt
+	errs := map[string]error{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		if cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress); !cc.Spec.IsAbsent() {
+			pf := physicalFunctionFor(pciAddress, cc)
+			err := profiles.resolve(&pf)
+			if err == nil {
+				err = expandProfile(&pf, ncc.Status.Inventory)
+			}
+			if err != nil {
+				errs[pciAddress] = &unschedulableError{nodeName: ncc.Name, pciAddress: pciAddress, err: err}
+			}
+			pfs = append(pfs, pf)
+		}
+	}
+
+	for pciAddress, err := range r.capabilities.validate(ncc.Name, pfs) {
+		if _, ok := errs[pciAddress]; !ok {
+			errs[pciAddress] = err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var messages []string
+	class := errclass.Unknown
+	for _, pf := range pfs {
+		if err, ok := errs[pf.PCIAddress]; ok {
+			cc, _ := ncc.AcceleratorConfigContext.Get(pf.PCIAddress)
+			syncErrors[cc.Name] = append(syncErrors[cc.Name], err)
+			messages = append(messages, err.Error())
+			if class == errclass.Unknown {
+				class = errclass.Of(err)
+			}
+		}
+	}
+	return errclass.New(class, "requested configuration is not supported by the node: %s", strings.Join(messages, "; "))
+}
+
+// updateClusterConfigsStatus reports sync errors, conditions, state of nodes, overridden accelerators, dry run changes
+// and skipped nodes of ClusterConfigs in their status and Events; class of the first error is reported as the class of the ClusterConfig's error
+func (r *SriovFecClusterConfigReconciler) updateClusterConfigsStatus(ctx context.Context, clusterConfigs []sriovfecv2.SriovFecClusterConfig,
+	syncErrors map[string][]error, conditions utils.Conditions, rollouts configRollouts, overridden overriddenAccelerators,
+	previews dryRunChanges, skipped skippedNodes) {
+	for i := range clusterConfigs {
+		cc := &clusterConfigs[i]
+		status := sriovfecv2.SriovFecClusterConfigStatus{SyncStatus: sriovfecv2.SucceededSync}
+		if errs, ok := syncErrors[cc.Name]; ok {
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			status = sriovfecv2.SriovFecClusterConfigStatus{
+				SyncStatus:           sriovfecv2.FailedSync,
+				LastSyncError:        strings.Join(messages, "; "),
+				LastSyncErrorClass:   string(errclass.Of(errs[0])),
+				NodeValidationErrors: nodeValidationErrors(errs),
+			}
+		}
+		status.Conditions = conditions[cc.Name]
+		status.NodesConfigured = rollouts.nodesConfigured(cc.Name)
+		status.NodeFailures = rollouts.nodeFailures(cc.Name)
+		status.OverriddenAccelerators = overridden.of(cc.Name)
+		status.DryRun = previews.of(cc.Name)
+		status.SkippedNodes = skipped.of(cc.Name)
+		status.ObservedGeneration = cc.GetGeneration()
+		if equality.Semantic.DeepEqual(cc.Status, status) {
+			continue
+		}
+		notifications := criticalNotifications(cc, status)
+		cc.Status = status
+		if err := r.Status().Update(ctx, cc); err != nil {
+			r.Log.WithError(err).WithField("name", cc.Name).Error("failed to update SriovFecClusterConfig status")
+		} else {
+			r.Notifier.Notify(notifications...)
+		}
+		if r.Recorder != nil && status.SyncStatus == sriovfecv2.FailedSync {
+			r.Recorder.Event(cc, corev1.EventTypeWarning, status.LastSyncErrorClass, status.LastSyncError)
+		}
+	}
+}
+
+func physicalFunctionFor(pciAddress string, cc sriovfecv2.SriovFecClusterConfig) sriovfecv2.PhysicalFunctionConfigExt {
+	return sriovfecv2.PhysicalFunctionConfigExt{
+		PCIAddress:        pciAddress,
+		PFDriver:          cc.Spec.PhysicalFunction.PFDriver,
+		PFDriverFallbacks: cc.Spec.PhysicalFunction.PFDriverFallbacks,
+		VFDriver:          cc.Spec.PhysicalFunction.VFDriver,
+		VFAmount:          cc.Spec.PhysicalFunction.VFAmount,
+		VFDriverOverrides: cc.Spec.PhysicalFunction.VFDriverOverrides,
+		VFMsixCount:       cc.Spec.PhysicalFunction.VFMsixCount,
+		PowerManagement:   cc.Spec.PhysicalFunction.PowerManagement,
+		BBDevConfig:       cc.Spec.PhysicalFunction.BBDevConfig,
+	}
+}
+
+// expandProfile replaces profile of the physical function's bbDevConfig with configuration the profile expands to for
+// the accelerator reported in the inventory
+func expandProfile(pf *sriovfecv2.PhysicalFunctionConfigExt, inventory sriovfecv2.NodeInventory) error {
+	if pf.BBDevConfig.Profile == "" {
+		return nil
+	}
+	for _, acc := range inventory.SriovAccelerators {
+		if acc.PCIAddress != pf.PCIAddress {
+			continue
+		}
+		config, err := pf.BBDevConfig.Expand(knownDevices[acc.DeviceID], pf.VFAmount)
+		if err != nil {
+			return errclass.Wrap(errclass.Validation, err)
+		}
+		pf.BBDevConfig = config
+		return nil
+	}
+	return errclass.New(errclass.Validation, "accelerator is not reported in the inventory, profile %s cannot be expanded", pf.BBDevConfig.Profile)
+}
+
+func (r *SriovFecClusterConfigReconciler) requeueIfClusterConfigExists(ctx context.Context, cc types.NamespacedName) (ctrl.Result, error) {
 	sfcc := &sriovfecv2.SriovFecClusterConfig{}
-	err := r.Get(context.TODO(), cc, sfcc)
+	err := r.Get(ctx, cc, sfcc)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return ctrl.Result{}, nil
@@ -122,10 +352,22 @@ func (r *SriovFecClusterConfigReconciler) requeueIfClusterConfigExists(cc types.
 		return ctrl.Result{}, fmt.Errorf("failed to get ClusterConfig to determine whenever reconcile is needed - %v", err)
 	}
 
+	if sfcc.Spec.SyncInterval != nil {
+		return ctrl.Result{RequeueAfter: sfcc.Spec.SyncInterval.Duration}, nil
+	}
 	return ctrl.Result{RequeueAfter: time.Minute}, nil
 }
 
-func (r *SriovFecClusterConfigReconciler) synchronizeNodeConfigSpec(ncc NodeConfigurationCtx) error {
+// shorterSyncInterval returns shorter of sync intervals, nil if none is set
+func shorterSyncInterval(a, b *metav1.Duration) *metav1.Duration {
+	if a == nil || (b != nil && b.Duration < a.Duration) {
+		return b
+	}
+	return a
+}
+
+// renderNodeConfig returns NodeConfig with configuration rendered from ClusterConfigs of the node
+func renderNodeConfig(ncc NodeConfigurationCtx, profiles fecProfiles, policy LostAcceleratorPolicy) (*sriovfecv2.SriovFecNodeConfig, error) {
 	copyWithEmptySpec := func(nc sriovfecv2.SriovFecNodeConfig) *sriovfecv2.SriovFecNodeConfig {
 		newNC := nc.DeepCopy()
 		newNC.Spec = sriovfecv2.SriovFecNodeConfigSpec{
@@ -139,50 +381,143 @@ func (r *SriovFecClusterConfigReconciler) synchronizeNodeConfigSpec(ncc NodeConf
 
 	newNodeConfig := copyWithEmptySpec(ncc.SriovFecNodeConfig)
 
+	// the node is drained only if configuration is disruptive when all configs of its accelerators allow it
+	drainIfDisruptive := true
+
 	// Use orederedmap for iteration
 	for _, pciAddress := range acceleratorConfigContext.Keys() {
 		cc, _ := acceleratorConfigContext.Get(pciAddress)
-		pf := sriovfecv2.PhysicalFunctionConfigExt{
-			PCIAddress:  pciAddress,
-			PFDriver:    cc.Spec.PhysicalFunction.PFDriver,
-			VFDriver:    cc.Spec.PhysicalFunction.VFDriver,
-			VFAmount:    cc.Spec.PhysicalFunction.VFAmount,
-			BBDevConfig: cc.Spec.PhysicalFunction.BBDevConfig,
-		}
+		drainIfDisruptive = drainIfDisruptive && cc.Spec.DrainPolicy == sriovfecv2.DrainIfDisruptive
+		pf := physicalFunctionFor(pciAddress, cc)
 		if cc.Spec.DrainSkip == nil {
 			newNodeConfig.Spec.DrainSkip = true
 		} else if cc.Spec.DrainSkip != nil {
 			newNodeConfig.Spec.DrainSkip = newNodeConfig.Spec.DrainSkip || *cc.Spec.DrainSkip
 		}
+		newNodeConfig.Spec.SyncInterval = shorterSyncInterval(newNodeConfig.Spec.SyncInterval, cc.Spec.SyncInterval)
+		// accelerator not listed in NodeConfig is deconfigured by the daemon
+		if cc.Spec.IsAbsent() {
+			continue
+		}
+		if err := profiles.resolve(&pf); err != nil {
+			return nil, err
+		}
+		if err := expandProfile(&pf, ncc.Status.Inventory); err != nil {
+			return nil, err
+		}
 		newNodeConfig.Spec.PhysicalFunctions = append(newNodeConfig.Spec.PhysicalFunctions, pf)
 	}
 
-	// copy latest known drainSkip from NodeConfig for cleanup
+	// accelerators lost by the node are not matched by ClusterConfigs, their configuration is kept unless the policy
+	// requires cleanup
+	if policy != CleanupLostAccelerators {
+		newNodeConfig.Spec.PhysicalFunctions = append(newNodeConfig.Spec.PhysicalFunctions, lostPhysicalFunctions(&currentNodeConfig, true)...)
+	}
+
+	if drainIfDisruptive {
+		newNodeConfig.Spec.DrainPolicy = sriovfecv2.DrainIfDisruptive
+	}
+
+	// copy latest known drainSkip and drainPolicy from NodeConfig for cleanup
 	if acceleratorConfigContext.Len() == 0 {
 		newNodeConfig.Spec.DrainSkip = ncc.Spec.DrainSkip
+		newNodeConfig.Spec.DrainPolicy = ncc.Spec.DrainPolicy
 	}
 
-	if !equality.Semantic.DeepEqual(newNodeConfig.Spec, currentNodeConfig.Spec) {
-		r.Log.Info("Node Config Changed")
-		return r.Update(context.TODO(), newNodeConfig)
+	// the webhook rejects duplicates in NodeConfigs, rendering must not produce them either
+	if errs := sriovfecv2.DuplicatePhysicalFunctions(newNodeConfig.Spec); len(errs) != 0 {
+		return nil, errclass.New(errclass.Validation, "rendered SriovFecNodeConfig configures an accelerator more than once: %s", errs.ToAggregate())
 	}
-	return nil
+
+	if currentNodeConfig.IsProtected() {
+		if pruned := sriovfecv2.PrunedPhysicalFunctions(currentNodeConfig.Spec, newNodeConfig.Spec); len(pruned) != 0 {
+			return nil, errclass.New(errclass.Validation, "SriovFecNodeConfig is protected by %s annotation, physical functions %v cannot be removed", sriovfecv2.ProtectAnnotation, pruned)
+		}
+	}
+
+	// NodeConfigs created before labels were introduced are labeled on first synchronization
+	utils.SetStandardLabels(newNodeConfig, utils.SriovFecNodeConfigName, newNodeConfig.Name)
+
+	// NodeConfig recreated by the daemon after deletion is orphaned until configuration is rendered again
+	delete(newNodeConfig.Annotations, sriovfecv2.OrphanedAnnotation)
+
+	newNodeConfig.OwnerReferences = ownerReferences(ncc)
+
+	return newNodeConfig, nil
 }
 
-func (r *SriovFecClusterConfigReconciler) getAcceleratedNodes() ([]corev1.Node, error) {
+// synchronizeNodeConfigSpec updates NodeConfig with configuration rendered from ClusterConfigs; it returns true if
+// NodeConfig was updated
+func (r *SriovFecClusterConfigReconciler) synchronizeNodeConfigSpec(ctx context.Context, ncc NodeConfigurationCtx, profiles fecProfiles, policy LostAcceleratorPolicy) (bool, error) {
+	currentNodeConfig := ncc.SriovFecNodeConfig
+	newNodeConfig, err := renderNodeConfig(ncc, profiles, policy)
+	if err != nil {
+		return false, err
+	}
+
+	renderedSpec := audit.Checksum(newNodeConfig.Spec)
+	if !equality.Semantic.DeepEqual(newNodeConfig.Spec, currentNodeConfig.Spec) || currentNodeConfig.IsOrphaned() ||
+		!utils.HasStandardLabels(&currentNodeConfig, utils.SriovFecNodeConfigName, currentNodeConfig.Name) ||
+		!equality.Semantic.DeepEqual(newNodeConfig.OwnerReferences, currentNodeConfig.OwnerReferences) ||
+		currentNodeConfig.Annotations[sriovfecv2.RenderedSpecAnnotation] != renderedSpec {
+		if newNodeConfig.Annotations == nil {
+			newNodeConfig.Annotations = map[string]string{}
+		}
+		newNodeConfig.Annotations[sriovfecv2.RenderedSpecAnnotation] = renderedSpec
+		summary := utils.ChangeSummary(currentNodeConfig.Spec.PhysicalFunctions, newNodeConfig.Spec.PhysicalFunctions)
+		if summary != "" {
+			newNodeConfig.Annotations[sriovfecv2.ChangeSummaryAnnotation] = summary
+		}
+		// orphaned NodeConfigs are restored immediately, without approval
+		specChanged := !currentNodeConfig.IsOrphaned() && !equality.Semantic.DeepEqual(newNodeConfig.Spec, currentNodeConfig.Spec)
+		if specChanged && r.UpgradeGuard.InProgress() && hasVFs(&currentNodeConfig.Status.Inventory) {
+			r.Log.WithField("node", currentNodeConfig.Name).Info("cluster upgrade in progress - disruptive reconfiguration deferred")
+			return false, nil
+		}
+		// changes of the spec wait for approval of SriovFecConfigPlan
+		approval := specChanged && configApprovalEnabled()
+		if approval {
+			if approved, err := r.isChangeApproved(ctx, &currentNodeConfig, newNodeConfig, summary); err != nil || !approved {
+				return false, err
+			}
+		}
+		r.Log.WithField("changes", summary).Info("Node Config Changed")
+		if err := r.Update(ctx, newNodeConfig); err != nil {
+			return true, err
+		}
+		if specChanged {
+			r.recordNodeConfigRendered(ncc, summary)
+		}
+		if specChanged && isManuallyModified(&currentNodeConfig) {
+			r.recordRevertedModification(ncc, time.Now())
+		}
+		if approval {
+			return true, r.markPlanApplied(ctx, newNodeConfig.Name)
+		}
+		return true, nil
+	}
+	if configApprovalEnabled() {
+		return false, r.deleteStalePlan(ctx, currentNodeConfig.Name)
+	}
+	return false, nil
+}
+
+func (r *SriovFecClusterConfigReconciler) getAcceleratedNodes(ctx context.Context) ([]corev1.Node, error) {
 	nl := new(corev1.NodeList)
-	labelsToMatch := &client.MatchingLabels{
-		"fpga.intel.com/intel-accelerator-present": "",
+	// nodes out of the scope are managed by another installation of the operator
+	labelsToMatch, err := nodescope.AcceleratedNodes()
+	if err != nil {
+		return nil, err
 	}
-	if err := r.List(context.TODO(), nl, labelsToMatch); err != nil {
+	if err := r.List(ctx, nl, labelsToMatch); err != nil {
 		return nil, err
 	}
 	return nl.Items, nil
 }
 
-func (r *SriovFecClusterConfigReconciler) getOrInitializeSriovFecNodeConfig(name string) (*sriovfecv2.SriovFecNodeConfig, error) {
+func (r *SriovFecClusterConfigReconciler) getOrInitializeSriovFecNodeConfig(ctx context.Context, name string) (*sriovfecv2.SriovFecNodeConfig, error) {
 	nc := new(sriovfecv2.SriovFecNodeConfig)
-	if err := r.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: NAMESPACE}, nc); err != nil {
+	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: NAMESPACE}, nc); err != nil {
 		if !errors.IsNotFound(err) {
 			return nil, err
 		}
@@ -193,13 +528,150 @@ func (r *SriovFecClusterConfigReconciler) getOrInitializeSriovFecNodeConfig(name
 	return nc, nil
 }
 
-func (r *SriovFecClusterConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *SriovFecClusterConfigReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	// Add NodeConfigs & DaemonSet
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&sriovfecv2.SriovFecClusterConfig{}).
+		For(&sriovfecv2.SriovFecClusterConfig{},
+			// status and revision annotation written by the reconciler itself don't trigger reconciliation,
+			// annotations set by users (e.g. pausing the config) do
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, isUserAnnotationChange))).
+		Watches(&source.Kind{Type: &sriovfecv2.SriovFecNodeConfig{}}, r.capabilities.eventHandler()).
+		Watches(&source.Kind{Type: &sriovfecv2.SriovFecNodeConfig{}},
+			handler.EnqueueRequestsFromMapFunc(r.allClusterConfigs(ctx)),
+			builder.WithPredicates(predicate.Or(isOrphanedNodeConfigCreation, isInventoryChange, isConfiguredConditionChange))).
+		// accelerated nodes joining the cluster get NodeConfig immediately instead of on next change of ClusterConfig
+		Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterConfigs(ctx)),
+			builder.WithPredicates(isAcceleratedNodeChange)).
+		// ClusterConfigs referencing changed SriovFecProfile are rendered again immediately instead of on next resync
+		Watches(&source.Kind{Type: &sriovfecv2.SriovFecProfile{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterConfigs(ctx))).
+		// approved SriovFecConfigPlans are applied immediately
+		Watches(&source.Kind{Type: &sriovfecv2.SriovFecConfigPlan{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterConfigs(ctx))).
 		Complete(r)
 }
 
+// isUserAnnotationChange passes update of ClusterConfig which annotations other than the revision annotation written
+// by the reconciler itself were changed
+var isUserAnnotationChange = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		withoutRevision := func(annotations map[string]string) map[string]string {
+			annotations = maps.Clone(annotations)
+			delete(annotations, sriovfecv2.RevisionAnnotation)
+			return annotations
+		}
+		return !maps.Equal(withoutRevision(e.ObjectOld.GetAnnotations()), withoutRevision(e.ObjectNew.GetAnnotations()))
+	},
+}
+
+// isOrphanedNodeConfigCreation passes creation of NodeConfig recreated by the daemon after it was deleted by mistake,
+// so configuration is rendered again immediately instead of on next resync
+var isOrphanedNodeConfigCreation = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		nc, ok := e.Object.(*sriovfecv2.SriovFecNodeConfig)
+		return ok && nc.IsOrphaned()
+	},
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isInventoryChange passes update of NodeConfig which inventory reports accelerators hot-plugged or removed by
+// the daemon, so configuration is rendered for them immediately instead of on next resync
+var isInventoryChange = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNc, ok := e.ObjectOld.(*sriovfecv2.SriovFecNodeConfig)
+		if !ok {
+			return false
+		}
+		newNc, ok := e.ObjectNew.(*sriovfecv2.SriovFecNodeConfig)
+		if !ok || len(oldNc.Status.Inventory.SriovAccelerators) != len(newNc.Status.Inventory.SriovAccelerators) {
+			return ok
+		}
+		for i := range oldNc.Status.Inventory.SriovAccelerators {
+			if oldNc.Status.Inventory.SriovAccelerators[i].PCIAddress != newNc.Status.Inventory.SriovAccelerators[i].PCIAddress {
+				return true
+			}
+		}
+		return false
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isConfiguredConditionChange passes update of NodeConfig which Configured condition was changed by the daemon, so state
+// of nodes is rolled up into status of ClusterConfigs immediately instead of on next resync. Failed configuration is
+// passed also when only its message or error class changes, e.g. when the retry fails differently, so ClusterConfigs
+// don't report a stale failure.
+var isConfiguredConditionChange = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNc, ok := e.ObjectOld.(*sriovfecv2.SriovFecNodeConfig)
+		if !ok {
+			return false
+		}
+		newNc, ok := e.ObjectNew.(*sriovfecv2.SriovFecNodeConfig)
+		if !ok {
+			return false
+		}
+		oldConfigured := meta.FindStatusCondition(oldNc.Status.Conditions, nodeConfiguredCondition)
+		newConfigured := meta.FindStatusCondition(newNc.Status.Conditions, nodeConfiguredCondition)
+		if oldConfigured == nil || newConfigured == nil {
+			return oldConfigured != newConfigured
+		}
+		if oldConfigured.Reason != newConfigured.Reason || oldConfigured.ObservedGeneration != newConfigured.ObservedGeneration {
+			return true
+		}
+		return isFailedConfiguration(newConfigured.Reason) &&
+			(oldConfigured.Message != newConfigured.Message || oldNc.Status.ErrorClass != newNc.Status.ErrorClass)
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isAcceleratedNodeChange passes creation and deletion of accelerated node and update of node which labels were
+// changed while it's accelerated before or after the change, so NodeConfig of the node joining the cluster or the scope
+// of the operator is rendered immediately instead of on next change of ClusterConfig
+var isAcceleratedNodeChange = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return isAcceleratedNode(e.Object)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if labels.Equals(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+			return false
+		}
+		return isAcceleratedNode(e.ObjectOld) || isAcceleratedNode(e.ObjectNew)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return isAcceleratedNode(e.Object)
+	},
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isAcceleratedNode returns true if node has labels of nodes with accelerators within the scope of the operator
+func isAcceleratedNode(node client.Object) bool {
+	matching, err := nodescope.AcceleratedNodes()
+	if err != nil {
+		return false
+	}
+	return labels.SelectorFromSet(labels.Set(matching)).Matches(labels.Set(node.GetLabels()))
+}
+
+func (r *SriovFecClusterConfigReconciler) allClusterConfigs(ctx context.Context) handler.MapFunc {
+	return func(_ client.Object) []reconcile.Request {
+		clusterConfigs := new(sriovfecv2.SriovFecClusterConfigList)
+		if err := r.List(ctx, clusterConfigs, client.InNamespace(NAMESPACE)); err != nil {
+			r.Log.WithError(err).Error("failed to list SriovFecClusterConfigs")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, cc := range clusterConfigs.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cc)})
+		}
+		return requests
+	}
+}
+
 // key: accelerator pciAddress
 type NodeConfigurationCtx struct {
 	sriovfecv2.SriovFecNodeConfig
@@ -253,7 +725,8 @@ func (pm *clusterConfigMatcher) prepareAcceleratorConfigContext(nodeConfig *srio
 					acceleratorConfigContext.Set(accelerator.PCIAddress, current)
 				case current.Spec.Priority == previous.Spec.Priority: //multiple configs with same priority; drop older one
 					//TODO: Update Timestamp would be better than CreationTime
-					if current.CreationTimestamp.After(previous.CreationTimestamp.Time) {
+					// configs are sorted, so the newer one or, if created at the same time, the one with greater name wins
+					if current.CreationTimestamp.After(previous.CreationTimestamp.Time) || current.CreationTimestamp.Equal(&previous.CreationTimestamp) {
 						pm.log.WithFields(logrus.Fields{
 							"Node":                  nodeConfig.Name,
 							"SriovFecClusterConfig": previous.Name,
@@ -286,14 +759,53 @@ func matchConfigsForNode(node *corev1.Node, allConfigs []sriovfecv2.SriovFecClus
 		}
 	}
 
-	// Sort existing SriovFecCluster configurations based on CreationTimestamp to keep the order
+	// Sort existing ClusterConfigs by CreationTimestamp and name, so configs of the same priority are merged in the same
+	// order regardless of the order they are listed in
 	sort.Slice(nodeConfigs, func(i, j int) bool {
-		return allConfigs[i].CreationTimestamp.Before(&allConfigs[j].CreationTimestamp)
+		if !nodeConfigs[i].CreationTimestamp.Equal(&nodeConfigs[j].CreationTimestamp) {
+			return nodeConfigs[i].CreationTimestamp.Before(&nodeConfigs[j].CreationTimestamp)
+		}
+		return nodeConfigs[i].Name < nodeConfigs[j].Name
 	})
 
 	return
 }
 
+// overriddenAccelerators keeps accelerators selected by ClusterConfigs, which are configured by another ClusterConfig,
+// keyed by name of the overridden ClusterConfig
+type overriddenAccelerators map[string][]sriovfecv2.OverriddenAccelerator
+
+// record records accelerators of the node selected by ClusterConfigs other than the one they are configured by
+func (o overriddenAccelerators) record(node *corev1.Node, ncc NodeConfigurationCtx, allConfigs []sriovfecv2.SriovFecClusterConfig) {
+	for _, cc := range matchConfigsForNode(node, allConfigs) {
+		for _, accelerator := range ncc.Status.Inventory.SriovAccelerators {
+			if !cc.Spec.AcceleratorSelector.Matches(accelerator) {
+				continue
+			}
+			if winner, ok := ncc.AcceleratorConfigContext.Get(accelerator.PCIAddress); ok && winner.Name != cc.Name {
+				o[cc.Name] = append(o[cc.Name], sriovfecv2.OverriddenAccelerator{
+					NodeName:     node.Name,
+					PCIAddress:   accelerator.PCIAddress,
+					OverriddenBy: winner.Name,
+				})
+			}
+		}
+	}
+}
+
+// of returns accelerators overridden for the ClusterConfig sorted by node and PCI address, so the status isn't updated
+// when nodes are listed in different order
+func (o overriddenAccelerators) of(name string) []sriovfecv2.OverriddenAccelerator {
+	accelerators := o[name]
+	sort.Slice(accelerators, func(i, j int) bool {
+		if accelerators[i].NodeName != accelerators[j].NodeName {
+			return accelerators[i].NodeName < accelerators[j].NodeName
+		}
+		return accelerators[i].PCIAddress < accelerators[j].PCIAddress
+	})
+	return accelerators
+}
+
 func setConfigurationPropagationConditionFailed(conditions *[]metav1.Condition, generation int64, msg string) {
 	meta.SetStatusCondition(conditions, metav1.Condition{
 		Type:               "ConfigurationPropagationCondition",
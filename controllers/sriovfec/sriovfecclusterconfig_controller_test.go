@@ -30,10 +30,12 @@ import (
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
+	"github.com/elliotchance/orderedmap/v2"
 	"github.com/onsi/gomega/gstruct"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
 	. "github.com/onsi/ginkgo"
@@ -159,7 +161,7 @@ var _ = Describe("SriovControllerTest", func() {
 		}
 
 		reconcile := func(ccName string) *SriovFecClusterConfigReconciler {
-			reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+			reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 			_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest(ccName))
 			Expect(err).ToNot(HaveOccurred())
 			return &reconciler
@@ -169,6 +171,9 @@ var _ = Describe("SriovControllerTest", func() {
 			ccl := new(sriovv2.SriovFecClusterConfigList)
 			Expect(k8sClient.List(context.TODO(), ccl)).ToNot(HaveOccurred())
 			for _, item := range ccl.Items {
+				// deconfiguration isn't awaited, as there is no daemon in the test environment
+				item.Finalizers = nil
+				Expect(k8sClient.Update(context.TODO(), &item)).ToNot(HaveOccurred())
 				Expect(k8sClient.Delete(context.TODO(), &item)).ToNot(HaveOccurred())
 			}
 
@@ -419,7 +424,7 @@ var _ = Describe("SriovControllerTest", func() {
 					}
 				})
 
-				reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("cc1"))
 				Expect(err).ToNot(HaveOccurred())
@@ -467,7 +472,7 @@ var _ = Describe("SriovControllerTest", func() {
 					}
 				})
 
-				reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 				ccs := []string{"cc1", "cc2"}
 				for i := 0; i < 100; i++ {
 					cc := ccs[i%len(ccs)]
@@ -648,6 +653,91 @@ var _ = Describe("SriovControllerTest", func() {
 
 		})
 
+		When("cc with higher priority requests accelerator to be absent", func() {
+			It("accelerator should be removed from matching nc", func() {
+				n1 := createNode("n1")
+
+				createNodeInventory(n1.Name, []sriovv2.SriovAccelerator{
+					{
+						PCIAddress: "0000:15:00.1",
+						VendorID:   "testvendor",
+						VFs:        []sriovv2.VF{},
+					},
+					{
+						PCIAddress: "0000:16:00.1",
+						VendorID:   "testvendor",
+						VFs:        []sriovv2.VF{},
+					},
+				})
+
+				_ = createAcceleratorConfig("config1", func(cc *sriovv2.SriovFecClusterConfig) {
+					cc.Spec.AcceleratorSelector = sriovv2.AcceleratorSelector{
+						VendorID: "testvendor",
+					}
+					cc.Spec.PhysicalFunction = sriovv2.PhysicalFunctionConfig{
+						PFDriver: utils.PCI_PF_STUB_DASH,
+						VFDriver: "vfDriver",
+						VFAmount: 1,
+					}
+					cc.Spec.Priority = 1
+				})
+
+				_ = createAcceleratorConfig("teardown", func(cc *sriovv2.SriovFecClusterConfig) {
+					cc.Spec.AcceleratorSelector = sriovv2.AcceleratorSelector{
+						PCIAddress: "0000:15:00.1",
+					}
+					cc.Spec.State = sriovv2.CardAbsent
+					cc.Spec.Priority = 2
+				})
+
+				_ = reconcile("teardown")
+
+				cl := new(sriovv2.SriovFecNodeConfigList)
+				Expect(k8sClient.List(context.TODO(), cl)).ToNot(HaveOccurred())
+				Expect(cl.Items).To(HaveLen(1))
+				nc := cl.Items[0]
+				Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+				Expect(nc.Spec.PhysicalFunctions[0].PCIAddress).Should(Equal("0000:16:00.1"))
+			})
+		})
+
+		When("nc was recreated by the daemon as orphaned", func() {
+			It("cc.spec should be propagated and orphaned annotation removed", func() {
+				n1 := createNode("n1")
+
+				createNodeInventory(n1.Name, []sriovv2.SriovAccelerator{
+					{
+						PCIAddress: "0000:15:00.1",
+						VendorID:   "testvendor",
+						VFs:        []sriovv2.VF{{PCIAddress: "0000:15:00.2"}},
+					},
+				})
+
+				nc := new(sriovv2.SriovFecNodeConfig)
+				Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: n1.Name, Namespace: NAMESPACE}, nc)).ToNot(HaveOccurred())
+				nc.Annotations = map[string]string{sriovv2.OrphanedAnnotation: "true"}
+				Expect(k8sClient.Update(context.TODO(), nc)).ToNot(HaveOccurred())
+
+				_ = createAcceleratorConfig("config", func(cc *sriovv2.SriovFecClusterConfig) {
+					cc.Spec.AcceleratorSelector = sriovv2.AcceleratorSelector{
+						VendorID: "testvendor",
+					}
+					cc.Spec.PhysicalFunction = sriovv2.PhysicalFunctionConfig{
+						PFDriver: utils.PCI_PF_STUB_DASH,
+						VFDriver: "vfDriver",
+						VFAmount: 1,
+					}
+				})
+
+				_ = reconcile("config")
+
+				nc = new(sriovv2.SriovFecNodeConfig)
+				Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: n1.Name, Namespace: NAMESPACE}, nc)).ToNot(HaveOccurred())
+				Expect(nc.IsOrphaned()).To(BeFalse())
+				Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+			})
+		})
+
 		When("cc has no node selector", func() {
 			It("cc.spec should be propagated to all nodes having matching accelerator", func() {
 				n1 := createNode("n1")
@@ -680,7 +770,7 @@ var _ = Describe("SriovControllerTest", func() {
 					}
 				})
 
-				reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("cc"))
 				Expect(err).ToNot(HaveOccurred())
 
@@ -716,7 +806,7 @@ var _ = Describe("SriovControllerTest", func() {
 						}
 					})
 
-					reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+					reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 					_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("config"))
 					Expect(err).ToNot(HaveOccurred())
 
@@ -771,7 +861,7 @@ var _ = Describe("SriovControllerTest", func() {
 						}
 					})
 
-					reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+					reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 					_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("config"))
 					Expect(err).ToNot(HaveOccurred())
 
@@ -821,7 +911,7 @@ var _ = Describe("SriovControllerTest", func() {
 					cc.Spec.DrainSkip = &val
 				})
 
-				reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("config"))
 				Expect(err).ToNot(HaveOccurred())
 
@@ -840,7 +930,7 @@ var _ = Describe("SriovControllerTest", func() {
 				cc.Namespace = v1.NamespaceSystem
 				Expect(k8sClient.Create(context.TODO(), cc)).ToNot(HaveOccurred())
 
-				reconciler := SriovFecClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovFecClusterConfigReconciler{Client: k8sClient, Log: log}
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest(clusterConfigPrototype.Name))
 				Expect(err).ToNot(HaveOccurred())
 
@@ -855,7 +945,7 @@ var _ = Describe("SriovControllerTest", func() {
 		var _ = It("setup with invalid manager", func() {
 			var m ctrl.Manager
 			var reconciler SriovFecClusterConfigReconciler
-			err := reconciler.SetupWithManager(m)
+			err := reconciler.SetupWithManager(context.TODO(), m)
 			Expect(err).To(HaveOccurred())
 		})
 	})
@@ -920,3 +1010,101 @@ var _ = Describe("SriovControllerTest", func() {
 		})
 	})
 })
+
+var _ = Describe("clusterConfigMatcher", func() {
+	now := v1.Now()
+	clusterConfig := func(name string, priority int, created v1.Time) sriovv2.SriovFecClusterConfig {
+		return sriovv2.SriovFecClusterConfig{
+			ObjectMeta: v1.ObjectMeta{Name: name, CreationTimestamp: created},
+			Spec:       sriovv2.SriovFecClusterConfigSpec{Priority: priority},
+		}
+	}
+
+	match := func(configs ...sriovv2.SriovFecClusterConfig) string {
+		nodeConfig := &sriovv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node"}}
+		nodeConfig.Status.Inventory.SriovAccelerators = []sriovv2.SriovAccelerator{{PCIAddress: "0000:14:00.0"}}
+		matcher := createClusterConfigMatcher(func(string) (*sriovv2.SriovFecNodeConfig, error) { return nodeConfig, nil }, logrus.New())
+
+		ctx, err := matcher.match(corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node"}}, configs)
+		Expect(err).ToNot(HaveOccurred())
+		cc, ok := ctx.AcceleratorConfigContext.Get("0000:14:00.0")
+		Expect(ok).To(BeTrue())
+		return cc.Name
+	}
+
+	It("selects config with the highest priority", func() {
+		Expect(match(clusterConfig("high", 2, now), clusterConfig("low", 1, now))).To(Equal("high"))
+	})
+
+	It("selects the newest config of the same priority", func() {
+		older := v1.NewTime(now.Add(-time.Hour))
+		Expect(match(clusterConfig("new", 1, now), clusterConfig("old", 1, older))).To(Equal("new"))
+		Expect(match(clusterConfig("old", 1, older), clusterConfig("new", 1, now))).To(Equal("new"))
+	})
+
+	It("selects config of the same priority and creation time by name regardless of their order", func() {
+		Expect(match(clusterConfig("b", 1, now), clusterConfig("a", 1, now))).To(Equal("b"))
+		Expect(match(clusterConfig("a", 1, now), clusterConfig("b", 1, now))).To(Equal("b"))
+	})
+
+	It("records accelerators overridden by config of higher priority", func() {
+		configs := []sriovv2.SriovFecClusterConfig{clusterConfig("high", 2, now), clusterConfig("low", 1, now)}
+		nodeConfig := &sriovv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node"}}
+		nodeConfig.Status.Inventory.SriovAccelerators = []sriovv2.SriovAccelerator{{PCIAddress: "0000:15:00.0"}, {PCIAddress: "0000:14:00.0"}}
+		matcher := createClusterConfigMatcher(func(string) (*sriovv2.SriovFecNodeConfig, error) { return nodeConfig, nil }, logrus.New())
+		node := corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node"}}
+		ctx, err := matcher.match(node, configs)
+		Expect(err).ToNot(HaveOccurred())
+
+		overridden := overriddenAccelerators{}
+		overridden.record(&node, *ctx, configs)
+
+		Expect(overridden.of("high")).To(BeEmpty())
+		Expect(overridden.of("low")).To(Equal([]sriovv2.OverriddenAccelerator{
+			{NodeName: "node", PCIAddress: "0000:14:00.0", OverriddenBy: "high"},
+			{NodeName: "node", PCIAddress: "0000:15:00.0", OverriddenBy: "high"},
+		}))
+	})
+})
+
+var _ = Describe("renderNodeConfig", func() {
+	It("renders the shortest sync interval of configs of the node", func() {
+		clusterConfig := func(name string, syncInterval *v1.Duration) sriovv2.SriovFecClusterConfig {
+			cc := sriovv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: name}, Spec: sriovv2.SriovFecClusterConfigSpec{SyncInterval: syncInterval}}
+			cc.Spec.State = sriovv2.CardAbsent
+			return cc
+		}
+		ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovv2.SriovFecClusterConfig]()}
+		ncc.AcceleratorConfigContext.Set("0000:14:00.0", clusterConfig("unset", nil))
+		ncc.AcceleratorConfigContext.Set("0000:15:00.0", clusterConfig("long", &v1.Duration{Duration: time.Hour}))
+		ncc.AcceleratorConfigContext.Set("0000:16:00.0", clusterConfig("short", &v1.Duration{Duration: 15 * time.Minute}))
+
+		rendered, err := renderNodeConfig(ncc, fecProfiles{}, RetainLostAccelerators)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.Spec.SyncInterval).To(Equal(&v1.Duration{Duration: 15 * time.Minute}))
+	})
+})
+
+var _ = Describe("isAcceleratedNodeChange", func() {
+	node := func(nodeLabels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node", Labels: nodeLabels}}
+	}
+	accelerated := map[string]string{"fpga.intel.com/intel-accelerator-present": ""}
+	relabeled := map[string]string{"fpga.intel.com/intel-accelerator-present": "", "zone": "a"}
+	plain := map[string]string{"zone": "a"}
+
+	It("passes creation and deletion of accelerated nodes only", func() {
+		Expect(isAcceleratedNodeChange.Create(event.CreateEvent{Object: node(accelerated)})).To(BeTrue())
+		Expect(isAcceleratedNodeChange.Create(event.CreateEvent{Object: node(plain)})).To(BeFalse())
+		Expect(isAcceleratedNodeChange.Delete(event.DeleteEvent{Object: node(accelerated)})).To(BeTrue())
+		Expect(isAcceleratedNodeChange.Delete(event.DeleteEvent{Object: node(plain)})).To(BeFalse())
+	})
+
+	It("passes label changes of nodes accelerated before or after the change", func() {
+		Expect(isAcceleratedNodeChange.Update(event.UpdateEvent{ObjectOld: node(plain), ObjectNew: node(relabeled)})).To(BeTrue())
+		Expect(isAcceleratedNodeChange.Update(event.UpdateEvent{ObjectOld: node(relabeled), ObjectNew: node(plain)})).To(BeTrue())
+		Expect(isAcceleratedNodeChange.Update(event.UpdateEvent{ObjectOld: node(accelerated), ObjectNew: node(relabeled)})).To(BeTrue())
+		Expect(isAcceleratedNodeChange.Update(event.UpdateEvent{ObjectOld: node(accelerated), ObjectNew: node(accelerated)})).To(BeFalse())
+		Expect(isAcceleratedNodeChange.Update(event.UpdateEvent{ObjectOld: node(nil), ObjectNew: node(plain)})).To(BeFalse())
+	})
+})
@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// nodeConfigurationState returns whether configuration of the node is in progress or failed. Node which spec was
+// updated by the current synchronization is in progress until the daemon reports configuration of the new generation.
+func nodeConfigurationState(ncc NodeConfigurationCtx, updated bool, err error) (inProgress bool, failure error) {
+	configured := meta.FindStatusCondition(ncc.Status.Conditions, nodeConfiguredCondition)
+	switch {
+	case err != nil:
+		return false, err
+	case updated || configured == nil || configured.ObservedGeneration != ncc.Generation:
+		return true, nil
+	case isFailedConfiguration(configured.Reason):
+		class := errclass.Class(ncc.Status.ErrorClass)
+		if class == "" {
+			class = errclass.Unknown
+		}
+		return false, errclass.New(class, "%s", configured.Message)
+	case configured.Reason == nodeConfiguredSucceeded:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// isFailedConfiguration returns true if the reason of Configured condition reported by the daemon means that configuration
+// of the node failed
+func isFailedConfiguration(reason string) bool {
+	return reason == nodeConfiguredFailed || reason == nodeConfiguredRolledBack || reason == nodeConfiguredTimeout
+}
+
+// configRollout tracks rollout of a ClusterConfig to nodes which accelerators it configures
+type configRollout struct {
+	// configured and inProgress are names of nodes
+	configured, inProgress []string
+	// failed keeps errors of failed nodes, keyed by node name
+	failed map[string]error
+}
+
+// configRollouts of ClusterConfigs configuring any accelerator, keyed by ClusterConfig name
+type configRollouts map[string]*configRollout
+
+// observe records state of the node in rollouts of ClusterConfigs configuring its accelerators
+func (rollouts configRollouts) observe(node corev1.Node, ncc NodeConfigurationCtx, updated bool, err error) {
+	inProgress, failure := nodeConfigurationState(ncc, updated, err)
+	for _, rollout := range rollouts.of(ncc) {
+		switch {
+		case failure != nil:
+			rollout.failed[node.Name] = failure
+		case inProgress:
+			rollout.inProgress = append(rollout.inProgress, node.Name)
+		default:
+			rollout.configured = append(rollout.configured, node.Name)
+		}
+	}
+}
+
+// hold records the node, which isn't synchronized (e.g. it waits for canary nodes), as in progress in rollouts of
+// ClusterConfigs configuring its accelerators
+func (rollouts configRollouts) hold(node corev1.Node, ncc NodeConfigurationCtx) {
+	for _, rollout := range rollouts.of(ncc) {
+		rollout.inProgress = append(rollout.inProgress, node.Name)
+	}
+}
+
+// of returns rollouts of ClusterConfigs configuring accelerators of the node, each one once
+func (rollouts configRollouts) of(ncc NodeConfigurationCtx) []*configRollout {
+	var selected []*configRollout
+	seen := map[string]bool{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		if seen[cc.Name] {
+			continue
+		}
+		seen[cc.Name] = true
+		if _, ok := rollouts[cc.Name]; !ok {
+			rollouts[cc.Name] = &configRollout{failed: map[string]error{}}
+		}
+		selected = append(selected, rollouts[cc.Name])
+	}
+	return selected
+}
+
+// failedNodes returns names of failed nodes, sorted
+func (r *configRollout) failedNodes() []string {
+	var nodes []string
+	for node := range r.failed {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// failures returns errors of failed nodes, sorted by node
+func (r *configRollout) failures() []error {
+	var errs []error
+	for _, node := range r.failedNodes() {
+		errs = append(errs, errclass.New(errclass.Of(r.failed[node]), "node %s: %s", node, r.failed[node].Error()))
+	}
+	return errs
+}
+
+// nodesConfigured returns number of configured nodes out of all nodes of the ClusterConfig, e.g. 8/10; it's empty if
+// the ClusterConfig doesn't configure any accelerator
+func (rollouts configRollouts) nodesConfigured(name string) string {
+	rollout, ok := rollouts[name]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", len(rollout.configured), len(rollout.configured)+len(rollout.inProgress)+len(rollout.failed))
+}
+
+// nodeFailures returns failures of nodes of the ClusterConfig, sorted by node
+func (rollouts configRollouts) nodeFailures(name string) []sriovfecv2.NodeFailure {
+	rollout, ok := rollouts[name]
+	if !ok {
+		return nil
+	}
+	var failures []sriovfecv2.NodeFailure
+	for _, node := range rollout.failedNodes() {
+		err := rollout.failed[node]
+		failures = append(failures, sriovfecv2.NodeFailure{NodeName: node, Reason: string(errclass.Of(err)), Message: err.Error()})
+	}
+	return failures
+}
+
+// standardConditions sets Ready, Progressing, Degraded, Ignored, PFModeEnabled and UnsupportedCombination conditions of
+// ClusterConfigs. Previous conditions are kept, so lastTransitionTime changes only when status of the condition changes.
+func (rollouts configRollouts) standardConditions(clusterConfigs []sriovfecv2.SriovFecClusterConfig, syncErrors map[string][]error,
+	overridden overriddenAccelerators, conditions utils.Conditions) {
+	for _, cc := range clusterConfigs {
+		set := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: conditionType, Status: status,
+				ObservedGeneration: cc.Generation, Reason: reason, Message: message})
+		}
+
+		rollout, selected := rollouts[cc.Name]
+		if !selected {
+			rollout = &configRollout{failed: map[string]error{}}
+		}
+
+		ignored := ""
+		switch {
+		case cc.Spec.DryRun:
+			ignored = "DryRun"
+			set(sriovfecv2.IgnoredCondition, metav1.ConditionTrue, ignored, "configuration is rendered into status only")
+		case !selected && len(overridden.of(cc.Name)) != 0:
+			ignored = "Overridden"
+			set(sriovfecv2.IgnoredCondition, metav1.ConditionTrue, ignored, "all selected accelerators are configured by other configs")
+		case !selected:
+			ignored = "NoAcceleratorsSelected"
+			set(sriovfecv2.IgnoredCondition, metav1.ConditionTrue, ignored, "no accelerator matches the config's selectors")
+		default:
+			set(sriovfecv2.IgnoredCondition, metav1.ConditionFalse, "AcceleratorsSelected", "")
+		}
+
+		errs := append(append([]error{}, syncErrors[cc.Name]...), rollout.failures()...)
+		if len(errs) != 0 {
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			set(sriovfecv2.DegradedCondition, metav1.ConditionTrue, string(errclass.Of(errs[0])), strings.Join(messages, "; "))
+		} else {
+			set(sriovfecv2.DegradedCondition, metav1.ConditionFalse, "AsExpected", "")
+		}
+
+		if !cc.Spec.IsAbsent() && cc.Spec.PhysicalFunction.BBDevConfig.PFModeEnabled() {
+			set(sriovfecv2.PFModeEnabledCondition, metav1.ConditionTrue, "PFModeEnabled", "pfMode bypasses SR-IOV and is not supported in production")
+		} else {
+			set(sriovfecv2.PFModeEnabledCondition, metav1.ConditionFalse, "SRIOVMode", "")
+		}
+
+		// combinations are checked again, as they become unsupported when the cluster is upgraded
+		switch err := cc.UnsupportedCombination(); {
+		case err == nil:
+			set(supportmatrix.ConditionUnsupportedCombination, metav1.ConditionFalse, supportmatrix.SupportedReason, "")
+		case cc.AllowsUnsupported():
+			set(supportmatrix.ConditionUnsupportedCombination, metav1.ConditionTrue, supportmatrix.AllowedByAnnotationReason, err.Error())
+		default:
+			set(supportmatrix.ConditionUnsupportedCombination, metav1.ConditionTrue, supportmatrix.UnsupportedReason, err.Error())
+		}
+
+		sort.Strings(rollout.inProgress)
+		switch {
+		case cc.IsPaused():
+			set(sriovfecv2.ProgressingCondition, metav1.ConditionFalse, "Paused", "")
+		case !cc.DeletionTimestamp.IsZero() && len(rollout.inProgress) != 0:
+			set(sriovfecv2.ProgressingCondition, metav1.ConditionTrue, "Deconfiguring",
+				fmt.Sprintf("waiting for deconfiguration of nodes: %s", strings.Join(rollout.inProgress, ", ")))
+		case len(rollout.inProgress) != 0:
+			set(sriovfecv2.ProgressingCondition, metav1.ConditionTrue, "InProgress",
+				fmt.Sprintf("waiting for nodes: %s", strings.Join(rollout.inProgress, ", ")))
+		default:
+			set(sriovfecv2.ProgressingCondition, metav1.ConditionFalse, "Completed", "")
+		}
+
+		switch {
+		case cc.IsPaused():
+			set(sriovfecv2.ReadyCondition, metav1.ConditionFalse, "Paused", "")
+		case !cc.DeletionTimestamp.IsZero():
+			set(sriovfecv2.ReadyCondition, metav1.ConditionFalse, "Deleting", "accelerators are deconfigured before the config is deleted")
+		case len(errs) != 0:
+			set(sriovfecv2.ReadyCondition, metav1.ConditionFalse, "Degraded", "see Degraded condition")
+		case ignored != "":
+			set(sriovfecv2.ReadyCondition, metav1.ConditionFalse, ignored, "see Ignored condition")
+		case len(rollout.inProgress) != 0:
+			set(sriovfecv2.ReadyCondition, metav1.ConditionFalse, "InProgress", "see Progressing condition")
+		default:
+			set(sriovfecv2.ReadyCondition, metav1.ConditionTrue, "Configured", fmt.Sprintf("%d nodes configured", len(rollout.configured)))
+		}
+	}
+}
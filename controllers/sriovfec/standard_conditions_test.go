@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"errors"
+
+	"github.com/elliotchance/orderedmap/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+var _ = Describe("configRollouts", func() {
+	const pciAddress = "0000:14:00.0"
+
+	var (
+		cc        sriovfecv2.SriovFecClusterConfig
+		node      corev1.Node
+		nodeState = func(generation int64, reason string) NodeConfigurationCtx {
+			ncc := NodeConfigurationCtx{AcceleratorConfigContext: orderedmap.NewOrderedMap[string, sriovfecv2.SriovFecClusterConfig]()}
+			ncc.Generation = generation
+			ncc.Status.Conditions = []v1.Condition{{Type: nodeConfiguredCondition, Reason: reason, ObservedGeneration: generation}}
+			ncc.AcceleratorConfigContext.Set(pciAddress, cc)
+			return ncc
+		}
+		conditionOf = func(conditions map[string][]v1.Condition, conditionType string) *v1.Condition {
+			condition := meta.FindStatusCondition(conditions["config"], conditionType)
+			Expect(condition).ToNot(BeNil())
+			return condition
+		}
+	)
+
+	BeforeEach(func() {
+		cc = sriovfecv2.SriovFecClusterConfig{ObjectMeta: v1.ObjectMeta{Name: "config", Namespace: NAMESPACE, Generation: 1}}
+		node = corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "node"}}
+	})
+
+	It("reports the config as ready once its nodes are configured", func() {
+		rollouts := configRollouts{}
+		rollouts.observe(node, nodeState(1, nodeConfiguredSucceeded), false, nil)
+
+		conditions := map[string][]v1.Condition{}
+		rollouts.standardConditions([]sriovfecv2.SriovFecClusterConfig{cc}, map[string][]error{}, overriddenAccelerators{}, conditions)
+
+		Expect(conditionOf(conditions, sriovfecv2.ReadyCondition).Status).To(Equal(v1.ConditionTrue))
+		Expect(conditionOf(conditions, sriovfecv2.ReadyCondition).Message).To(Equal("1 nodes configured"))
+		Expect(conditionOf(conditions, sriovfecv2.ProgressingCondition).Status).To(Equal(v1.ConditionFalse))
+		Expect(conditionOf(conditions, sriovfecv2.DegradedCondition).Status).To(Equal(v1.ConditionFalse))
+		Expect(conditionOf(conditions, sriovfecv2.IgnoredCondition).Status).To(Equal(v1.ConditionFalse))
+	})
+
+	It("reports the config as progressing while its nodes are updated", func() {
+		rollouts := configRollouts{}
+		rollouts.observe(node, nodeState(1, nodeConfiguredSucceeded), true, nil)
+
+		conditions := map[string][]v1.Condition{}
+		rollouts.standardConditions([]sriovfecv2.SriovFecClusterConfig{cc}, map[string][]error{}, overriddenAccelerators{}, conditions)
+
+		progressing := conditionOf(conditions, sriovfecv2.ProgressingCondition)
+		Expect(progressing.Status).To(Equal(v1.ConditionTrue))
+		Expect(progressing.Message).To(Equal("waiting for nodes: node"))
+		Expect(conditionOf(conditions, sriovfecv2.ReadyCondition).Reason).To(Equal("InProgress"))
+	})
+
+	It("reports the config as ignored when it selects no accelerator", func() {
+		conditions := map[string][]v1.Condition{}
+		configRollouts{}.standardConditions([]sriovfecv2.SriovFecClusterConfig{cc}, map[string][]error{}, overriddenAccelerators{}, conditions)
+
+		Expect(conditionOf(conditions, sriovfecv2.IgnoredCondition).Status).To(Equal(v1.ConditionTrue))
+		Expect(conditionOf(conditions, sriovfecv2.IgnoredCondition).Reason).To(Equal("NoAcceleratorsSelected"))
+		Expect(conditionOf(conditions, sriovfecv2.ReadyCondition).Reason).To(Equal("NoAcceleratorsSelected"))
+	})
+
+	It("reports the config as degraded with class of the first error", func() {
+		rollouts := configRollouts{}
+		rollouts.observe(node, nodeState(1, nodeConfiguredSucceeded), false, nil)
+
+		conditions := map[string][]v1.Condition{}
+		syncErrors := map[string][]error{"config": {errclass.New(errclass.Validation, "invalid queues"), errors.New("other")}}
+		rollouts.standardConditions([]sriovfecv2.SriovFecClusterConfig{cc}, syncErrors, overriddenAccelerators{}, conditions)
+
+		degraded := conditionOf(conditions, sriovfecv2.DegradedCondition)
+		Expect(degraded.Status).To(Equal(v1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(string(errclass.Validation)))
+		Expect(degraded.Message).To(Equal("invalid queues; other"))
+		Expect(conditionOf(conditions, sriovfecv2.ReadyCondition).Reason).To(Equal("Degraded"))
+	})
+
+	It("reports the config enabling pfMode", func() {
+		conditions := map[string][]v1.Condition{}
+		configRollouts{}.standardConditions([]sriovfecv2.SriovFecClusterConfig{cc}, map[string][]error{}, overriddenAccelerators{}, conditions)
+		Expect(conditionOf(conditions, sriovfecv2.PFModeEnabledCondition).Status).To(Equal(v1.ConditionFalse))
+
+		cc.Spec.PhysicalFunction.BBDevConfig.ACC100 = &sriovfecv2.ACC100BBDevConfig{PFMode: true}
+		configRollouts{}.standardConditions([]sriovfecv2.SriovFecClusterConfig{cc}, map[string][]error{}, overriddenAccelerators{}, conditions)
+		Expect(conditionOf(conditions, sriovfecv2.PFModeEnabledCondition).Status).To(Equal(v1.ConditionTrue))
+	})
+
+	It("rolls up state of nodes", func() {
+		rollouts := configRollouts{}
+		rollouts.observe(node, nodeState(1, nodeConfiguredSucceeded), false, nil)
+		failed := nodeState(1, nodeConfiguredFailed)
+		failed.Status.Conditions[0].Message = "pf_bb_config failed"
+		failed.Status.ErrorClass = string(errclass.Device)
+		rollouts.observe(corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "failed"}}, failed, false, nil)
+		rollouts.observe(corev1.Node{ObjectMeta: v1.ObjectMeta{Name: "updated"}}, nodeState(1, nodeConfiguredSucceeded), true, nil)
+
+		Expect(rollouts.nodesConfigured("config")).To(Equal("1/3"))
+		Expect(rollouts.nodeFailures("config")).To(Equal([]sriovfecv2.NodeFailure{
+			{NodeName: "failed", Reason: string(errclass.Device), Message: "pf_bb_config failed"},
+		}))
+		Expect(rollouts.nodesConfigured("other")).To(BeEmpty())
+		Expect(rollouts.nodeFailures("other")).To(BeEmpty())
+	})
+
+	It("passes changes of Configured condition of NodeConfigs", func() {
+		oldNc := &sriovfecv2.SriovFecNodeConfig{}
+		oldNc.Status.Conditions = []v1.Condition{{Type: nodeConfiguredCondition, Reason: "InProgress", ObservedGeneration: 1}}
+		newNc := oldNc.DeepCopy()
+		Expect(isConfiguredConditionChange.Update(event.UpdateEvent{ObjectOld: oldNc, ObjectNew: newNc})).To(BeFalse())
+
+		newNc.Status.Conditions[0].Reason = nodeConfiguredSucceeded
+		Expect(isConfiguredConditionChange.Update(event.UpdateEvent{ObjectOld: oldNc, ObjectNew: newNc})).To(BeTrue())
+	})
+
+	It("passes changes of message and error class of failed configuration only", func() {
+		oldNc := &sriovfecv2.SriovFecNodeConfig{}
+		oldNc.Status.Conditions = []v1.Condition{{Type: nodeConfiguredCondition, Reason: "InProgress", ObservedGeneration: 1, Message: "draining"}}
+		newNc := oldNc.DeepCopy()
+		newNc.Status.Conditions[0].Message = "configuring"
+		Expect(isConfiguredConditionChange.Update(event.UpdateEvent{ObjectOld: oldNc, ObjectNew: newNc})).To(BeFalse())
+
+		oldNc.Status.Conditions[0].Reason, newNc.Status.Conditions[0].Reason = nodeConfiguredFailed, nodeConfiguredFailed
+		Expect(isConfiguredConditionChange.Update(event.UpdateEvent{ObjectOld: oldNc, ObjectNew: newNc})).To(BeTrue())
+
+		newNc.Status.Conditions[0].Message = oldNc.Status.Conditions[0].Message
+		newNc.Status.ErrorClass = string(errclass.Device)
+		Expect(isConfiguredConditionChange.Update(event.UpdateEvent{ObjectOld: oldNc, ObjectNew: newNc})).To(BeTrue())
+	})
+})
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// SuggestConfigAnnotation set to "true" on SriovFecNodeConfig requests generation of suggested SriovFecClusterConfigs
+	// for accelerators discovered on that node. Annotation is removed once suggestion is published.
+	SuggestConfigAnnotation = "sriovfec.intel.com/suggest-config"
+
+	suggestedConfigMapPrefix = "suggested-sriovfecclusterconfig-"
+	// suggestedConfigMapName is a value of app.kubernetes.io/name label of ConfigMaps with suggestions
+	suggestedConfigMapName = "suggested-sriovfecclusterconfig"
+)
+
+// knownDevices maps accelerator deviceID to device name as reported in supported-accelerators config
+var knownDevices = map[string]string{
+	"0d8f": "FPGA_5GNR",
+	"5052": "FPGA_LTE",
+	"0d5c": "ACC100",
+	"57c0": "ACC200",
+}
+
+// SuggestedConfigReconciler publishes suggested SriovFecClusterConfigs into a ConfigMap on user request
+type SuggestedConfigReconciler struct {
+	client.Client
+	Log *logrus.Logger
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;create;update;delete
+
+func (r *SuggestedConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	nc := new(sriovfecv2.SriovFecNodeConfig)
+	if err := r.Get(ctx, req.NamespacedName, nc); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, r.removeSuggestions(ctx, req.NamespacedName)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !isSuggestionRequested(nc) {
+		return ctrl.Result{}, nil
+	}
+
+	r.Log.WithField("node", nc.Name).Info("generating suggested SriovFecClusterConfigs")
+
+	data := map[string]string{}
+	for _, cc := range suggestClusterConfigs(nc) {
+		content, err := yaml.Marshal(cc)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		data[cc.Name+".yaml"] = string(content)
+	}
+
+	if err := r.publishSuggestion(ctx, nc, data); err != nil {
+		r.Log.WithError(err).WithField("node", nc.Name).Error("failed to publish suggested SriovFecClusterConfigs")
+		return ctrl.Result{}, err
+	}
+
+	patch := client.MergeFrom(nc.DeepCopy())
+	delete(nc.Annotations, SuggestConfigAnnotation)
+	return ctrl.Result{}, r.Patch(ctx, nc, patch)
+}
+
+func (r *SuggestedConfigReconciler) publishSuggestion(ctx context.Context, nc *sriovfecv2.SriovFecNodeConfig, data map[string]string) error {
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, client.ObjectKey{Name: suggestedConfigMapPrefix + nc.Name, Namespace: nc.Namespace}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	cm.Data = data
+	utils.SetStandardLabels(cm, suggestedConfigMapName, nc.Name)
+	if errors.IsNotFound(err) {
+		cm.Name = suggestedConfigMapPrefix + nc.Name
+		cm.Namespace = nc.Namespace
+		return r.Create(ctx, cm)
+	}
+	return r.Update(ctx, cm)
+}
+
+// removeSuggestions deletes ConfigMaps published for SriovFecNodeConfig which no longer exists
+func (r *SuggestedConfigReconciler) removeSuggestions(ctx context.Context, nc client.ObjectKey) error {
+	cms := new(corev1.ConfigMapList)
+	if err := r.List(ctx, cms, client.InNamespace(nc.Namespace), utils.ManagedObjects(suggestedConfigMapName),
+		client.MatchingLabels{utils.LabelInstance: nc.Name}); err != nil {
+		return err
+	}
+
+	for i := range cms.Items {
+		r.Log.WithField("configMap", cms.Items[i].Name).Info("removing suggestion of deleted SriovFecNodeConfig")
+		if err := r.Delete(ctx, &cms.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// suggestClusterConfigs returns one SriovFecClusterConfig per known accelerator discovered on the node
+func suggestClusterConfigs(nc *sriovfecv2.SriovFecNodeConfig) []sriovfecv2.SriovFecClusterConfig {
+	var suggestions []sriovfecv2.SriovFecClusterConfig
+	for _, acc := range nc.Status.Inventory.SriovAccelerators {
+		pf, ok := recommendedPhysicalFunctionConfig(acc)
+		if !ok {
+			continue
+		}
+
+		suggestions = append(suggestions, sriovfecv2.SriovFecClusterConfig{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: sriovfecv2.GroupVersion.String(),
+				Kind:       "SriovFecClusterConfig",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%s", nc.Name, strings.NewReplacer(":", "-", ".", "-").Replace(acc.PCIAddress)),
+				Namespace: nc.Namespace,
+			},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				NodeSelector: map[string]string{corev1.LabelHostname: nc.Name},
+				AcceleratorSelector: sriovfecv2.AcceleratorSelector{
+					PCIAddress: acc.PCIAddress,
+				},
+				PhysicalFunction: pf,
+			},
+		})
+	}
+	return suggestions
+}
+
+func recommendedPhysicalFunctionConfig(acc sriovfecv2.SriovAccelerator) (sriovfecv2.PhysicalFunctionConfig, bool) {
+	pf := sriovfecv2.PhysicalFunctionConfig{
+		PFDriver: utils.VFIO_PCI,
+		VFDriver: utils.VFIO_PCI,
+	}
+
+	switch device := knownDevices[acc.DeviceID]; device {
+	case "ACC100":
+		pf.BBDevConfig.ACC100 = sriovfecv2.RecommendedACC100BBDevConfig()
+		pf.VFAmount = pf.BBDevConfig.ACC100.NumVfBundles
+	case "ACC200":
+		pf.BBDevConfig.ACC200 = sriovfecv2.RecommendedACC200BBDevConfig()
+		pf.VFAmount = pf.BBDevConfig.ACC200.NumVfBundles
+	case "FPGA_5GNR", "FPGA_LTE":
+		pf.BBDevConfig.N3000 = sriovfecv2.RecommendedN3000BBDevConfig(device)
+		pf.VFAmount = 2
+	default:
+		return pf, false
+	}
+
+	if acc.MaxVFs > 0 && pf.VFAmount > acc.MaxVFs {
+		pf.VFAmount = acc.MaxVFs
+		if pf.BBDevConfig.ACC100 != nil {
+			pf.BBDevConfig.ACC100.NumVfBundles = acc.MaxVFs
+		}
+		if pf.BBDevConfig.ACC200 != nil {
+			pf.BBDevConfig.ACC200.NumVfBundles = acc.MaxVFs
+		}
+	}
+	return pf, true
+}
+
+func (r *SuggestedConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("suggestedconfig").
+		For(&sriovfecv2.SriovFecNodeConfig{}).
+		WithEventFilter(predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return isSuggestionRequested(e.Object) },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return isSuggestionRequested(e.ObjectNew) },
+			GenericFunc: func(e event.GenericEvent) bool { return isSuggestionRequested(e.Object) },
+			// deletion of SriovFecNodeConfig triggers cleanup of its suggestions
+			DeleteFunc: func(event.DeleteEvent) bool { return true },
+		}).
+		Complete(r)
+}
+
+func isSuggestionRequested(o client.Object) bool {
+	return o.GetAnnotations()[SuggestConfigAnnotation] == "true"
+}
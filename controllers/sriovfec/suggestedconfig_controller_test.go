@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SuggestedConfigReconciler", func() {
+	inventory := []sriovv2.SriovAccelerator{
+		{VendorID: "8086", DeviceID: "0d5c", PCIAddress: "0000:af:00.0", MaxVFs: 16},
+		{VendorID: "8086", DeviceID: "57c0", PCIAddress: "0000:f7:00.0", MaxVFs: 8},
+		{VendorID: "8086", DeviceID: "ffff", PCIAddress: "0000:18:00.0", MaxVFs: 16},
+	}
+
+	It("suggests config for every known accelerator", func() {
+		nc := &sriovv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node1", Namespace: NAMESPACE}}
+		nc.Status.Inventory.SriovAccelerators = inventory
+
+		suggestions := suggestClusterConfigs(nc)
+		Expect(suggestions).To(HaveLen(2))
+
+		Expect(suggestions[0].Name).To(Equal("node1-0000-af-00-0"))
+		Expect(suggestions[0].Spec.NodeSelector).To(HaveKeyWithValue(corev1.LabelHostname, "node1"))
+		Expect(suggestions[0].Spec.AcceleratorSelector.PCIAddress).To(Equal("0000:af:00.0"))
+		Expect(suggestions[0].Spec.PhysicalFunction.PFDriver).To(Equal(utils.VFIO_PCI))
+		Expect(suggestions[0].Spec.PhysicalFunction.VFAmount).To(Equal(16))
+		Expect(suggestions[0].Spec.PhysicalFunction.BBDevConfig.ACC100).ToNot(BeNil())
+
+		Expect(suggestions[1].Spec.PhysicalFunction.VFAmount).To(Equal(8))
+		Expect(suggestions[1].Spec.PhysicalFunction.BBDevConfig.ACC200).ToNot(BeNil())
+		Expect(suggestions[1].Spec.PhysicalFunction.BBDevConfig.ACC200.NumVfBundles).To(Equal(8))
+
+		for _, s := range suggestions {
+			Expect(s.Spec.PhysicalFunction.BBDevConfig.Validate()).To(Succeed())
+		}
+	})
+
+	It("publishes suggestion into ConfigMap and removes annotation", func() {
+		nc := &sriovv2.SriovFecNodeConfig{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        "suggest-node",
+				Namespace:   NAMESPACE,
+				Annotations: map[string]string{SuggestConfigAnnotation: "true"},
+			},
+			Spec: sriovv2.SriovFecNodeConfigSpec{PhysicalFunctions: []sriovv2.PhysicalFunctionConfigExt{}},
+		}
+		Expect(k8sClient.Create(context.TODO(), nc)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), nc)).To(Succeed()) }()
+
+		nc.Status.Inventory.SriovAccelerators = inventory
+		Expect(k8sClient.Status().Update(context.TODO(), nc)).To(Succeed())
+
+		reconciler := SuggestedConfigReconciler{Client: k8sClient, Log: logrus.New()}
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: NAMESPACE, Name: nc.Name}})
+		Expect(err).ToNot(HaveOccurred())
+
+		cm := new(corev1.ConfigMap)
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: suggestedConfigMapPrefix + nc.Name, Namespace: NAMESPACE}, cm)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(context.TODO(), cm)).To(Succeed()) }()
+		Expect(cm.Data).To(HaveLen(2))
+		Expect(cm.Labels).To(HaveKeyWithValue(utils.LabelName, suggestedConfigMapName))
+		Expect(cm.Labels).To(HaveKeyWithValue(utils.LabelInstance, nc.Name))
+
+		suggested := new(sriovv2.SriovFecClusterConfig)
+		Expect(yaml.Unmarshal([]byte(cm.Data["suggest-node-0000-af-00-0.yaml"]), suggested)).To(Succeed())
+		Expect(suggested.Spec.PhysicalFunction.BBDevConfig.ACC100).ToNot(BeNil())
+
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+		Expect(nc.Annotations).ToNot(HaveKey(SuggestConfigAnnotation))
+	})
+	It("removes suggestions of deleted SriovFecNodeConfig", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      suggestedConfigMapPrefix + "removed-node",
+				Namespace: NAMESPACE,
+				Labels:    utils.StandardLabels(suggestedConfigMapName, "removed-node"),
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), cm)).To(Succeed())
+
+		reconciler := SuggestedConfigReconciler{Client: k8sClient, Log: logrus.New()}
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: NAMESPACE, Name: "removed-node"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cm), cm)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+})
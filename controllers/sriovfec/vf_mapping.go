@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// vfMappingConfigMapName is a value of app.kubernetes.io/name label of ConfigMaps with VF mapping of a node
+	vfMappingConfigMapName   = "sriovfec-vf-mapping"
+	vfMappingConfigMapPrefix = vfMappingConfigMapName + "-"
+	// VFMappingKey is a key of the VF mapping in data of the ConfigMap
+	VFMappingKey = "mapping.json"
+
+	vfMappingNamespacesEnv = "SRIOV_FEC_VF_MAPPING_NAMESPACES"
+)
+
+// VFMappingEntry is a VF in the published mapping of physical functions to their VFs
+type VFMappingEntry struct {
+	// Index is unset when it can't be determined
+	Index      *int   `json:"index,omitempty"`
+	PCIAddress string `json:"pciAddress"`
+	Driver     string `json:"driver"`
+}
+
+// VFMappingReconciler publishes VFs of each physical function of the node, ordered by their index, in a ConfigMap per
+// node, which workloads pinning particular VF indices can mount. ConfigMaps are published in operator's namespace and in
+// namespaces listed in SRIOV_FEC_VF_MAPPING_NAMESPACES.
+type VFMappingReconciler struct {
+	client.Client
+	// APIReader reads ConfigMaps outside of operator's namespace, which are not cached by the manager
+	APIReader client.Reader
+	Log       *logrus.Logger
+
+	// swept holds nodes which mappings left in namespaces no longer listed in SRIOV_FEC_VF_MAPPING_NAMESPACES were
+	// removed since start of the operator; the list can't change without restart, so they're looked for once per node
+	swept sync.Map
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+
+func (r *VFMappingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	nc := new(sriovfecv2.SriovFecNodeConfig)
+	if err := r.Get(ctx, req.NamespacedName, nc); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		// mapping in operator's namespace is removed by the garbage collector, the others aren't owned by the NodeConfig
+		r.swept.Delete(req.Name)
+		return ctrl.Result{}, r.removeMappings(ctx, req.Name, nil)
+	}
+
+	mapping, err := json.Marshal(vfMapping(nc.Status.Inventory))
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	data := map[string]string{VFMappingKey: string(mapping)}
+
+	namespaces := vfMappingNamespaces()
+	if _, swept := r.swept.Load(req.Name); !swept {
+		if err := r.removeMappings(ctx, req.Name, namespaces); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.swept.Store(req.Name, true)
+	}
+	for _, namespace := range namespaces {
+		if err := r.publishMapping(ctx, nc, namespace, data); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// publishMapping creates or updates the mapping of the node in the namespace. Mapping in operator's namespace is
+// controlled by the NodeConfig, so its changes are watched and it's removed along with the NodeConfig.
+func (r *VFMappingReconciler) publishMapping(ctx context.Context, nc *sriovfecv2.SriovFecNodeConfig, namespace string, data map[string]string) error {
+	owned := namespace == nc.Namespace
+	reader := r.APIReader
+	if owned {
+		reader = r.Client
+	}
+
+	cm := new(corev1.ConfigMap)
+	err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: vfMappingConfigMapPrefix + nc.Name}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists && cm.Data[VFMappingKey] == data[VFMappingKey] && (!owned || metav1.IsControlledBy(cm, nc)) {
+		return nil
+	}
+
+	cm.Name, cm.Namespace, cm.Data = vfMappingConfigMapPrefix+nc.Name, namespace, data
+	utils.SetStandardLabels(cm, vfMappingConfigMapName, nc.Name)
+	if owned {
+		if err := controllerutil.SetControllerReference(nc, cm, r.Scheme()); err != nil {
+			return err
+		}
+	}
+
+	log := r.Log.WithField("configMap", cm.Namespace+"/"+cm.Name)
+	if exists {
+		log.Info("updating VF mapping")
+		return r.Update(ctx, cm)
+	}
+	log.Info("creating VF mapping")
+	if err := r.Create(ctx, cm); err != nil {
+		if errors.IsNotFound(err) {
+			r.Log.WithField("namespace", namespace).Warn("namespace of VF mapping does not exist")
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// removeMappings removes mappings of the node published in namespaces other than given ones
+func (r *VFMappingReconciler) removeMappings(ctx context.Context, node string, namespaces []string) error {
+	existing := new(corev1.ConfigMapList)
+	if err := r.APIReader.List(ctx, existing, utils.ManagedObjects(vfMappingConfigMapName),
+		client.MatchingLabels{utils.LabelInstance: node}); err != nil {
+		return err
+	}
+	for i := range existing.Items {
+		cm := &existing.Items[i]
+		if slices.Contains(namespaces, cm.Namespace) {
+			continue
+		}
+		r.Log.WithField("configMap", cm.Namespace+"/"+cm.Name).Info("removing VF mapping")
+		if err := client.IgnoreNotFound(r.Delete(ctx, cm)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vfMapping returns VFs of each physical function of the inventory ordered by their index, VFs which index can't be
+// determined are listed last
+func vfMapping(inventory sriovfecv2.NodeInventory) map[string][]VFMappingEntry {
+	mapping := map[string][]VFMappingEntry{}
+	for _, acc := range inventory.SriovAccelerators {
+		vfs := []VFMappingEntry{}
+		for _, vf := range acc.VFs {
+			vfs = append(vfs, VFMappingEntry{Index: vf.Index, PCIAddress: vf.PCIAddress, Driver: vf.Driver})
+		}
+		sort.SliceStable(vfs, func(i, j int) bool {
+			if vfs[i].Index == nil || vfs[j].Index == nil {
+				return vfs[j].Index == nil && vfs[i].Index != nil
+			}
+			return *vfs[i].Index < *vfs[j].Index
+		})
+		mapping[acc.PCIAddress] = vfs
+	}
+	return mapping
+}
+
+// vfMappingNamespaces returns operator's namespace followed by namespaces listed in SRIOV_FEC_VF_MAPPING_NAMESPACES
+func vfMappingNamespaces() []string {
+	namespaces := []string{NAMESPACE}
+	for _, namespace := range strings.Split(os.Getenv(vfMappingNamespacesEnv), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+func (r *VFMappingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("vfmapping").
+		For(&sriovfecv2.SriovFecNodeConfig{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("VF mapping", func() {
+	const workloads = "ran-workloads"
+
+	var (
+		c  client.Client
+		r  *VFMappingReconciler
+		nc *sriovfecv2.SriovFecNodeConfig
+	)
+
+	mappingOf := func(namespace string) map[string][]VFMappingEntry {
+		cm := new(corev1.ConfigMap)
+		ExpectWithOffset(1, c.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: vfMappingConfigMapPrefix + "node1"}, cm)).To(Succeed())
+		mapping := map[string][]VFMappingEntry{}
+		ExpectWithOffset(1, json.Unmarshal([]byte(cm.Data[VFMappingKey]), &mapping)).To(Succeed())
+		return mapping
+	}
+	reconcile := func() {
+		_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nc)})
+		ExpectWithOffset(1, err).ToNot(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		Expect(os.Setenv(vfMappingNamespacesEnv, workloads)).To(Succeed())
+		nc = &sriovfecv2.SriovFecNodeConfig{ObjectMeta: v1.ObjectMeta{Name: "node1", Namespace: NAMESPACE}}
+		nc.Status.Inventory.SriovAccelerators = []sriovfecv2.SriovAccelerator{{PCIAddress: "0000:f7:00.0", VFs: []sriovfecv2.VF{
+			{PCIAddress: "0000:f7:01.2", Index: pointer.Int(10), Driver: "vfio-pci"},
+			{PCIAddress: "0000:f7:00.1", Index: pointer.Int(0), Driver: "vfio-pci"},
+			{PCIAddress: "0000:f7:00.2", Index: pointer.Int(1), Driver: "vfio-pci"},
+			{PCIAddress: "0000:f7:01.3", Driver: "vfio-pci"},
+		}}}
+		c = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nc,
+			&corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: workloads}}).Build()
+		r = &VFMappingReconciler{Client: c, APIReader: c, Log: logrus.New()}
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv(vfMappingNamespacesEnv)).To(Succeed())
+	})
+
+	It("publishes VFs ordered by index in operator's namespace and listed namespaces", func() {
+		reconcile()
+
+		expected := map[string][]VFMappingEntry{"0000:f7:00.0": {
+			{Index: pointer.Int(0), PCIAddress: "0000:f7:00.1", Driver: "vfio-pci"},
+			{Index: pointer.Int(1), PCIAddress: "0000:f7:00.2", Driver: "vfio-pci"},
+			{Index: pointer.Int(10), PCIAddress: "0000:f7:01.2", Driver: "vfio-pci"},
+			{PCIAddress: "0000:f7:01.3", Driver: "vfio-pci"},
+		}}
+		Expect(mappingOf(NAMESPACE)).To(Equal(expected))
+		Expect(mappingOf(workloads)).To(Equal(expected))
+
+		cm := new(corev1.ConfigMap)
+		Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: NAMESPACE, Name: vfMappingConfigMapPrefix + "node1"}, cm)).To(Succeed())
+		Expect(v1.IsControlledBy(cm, nc)).To(BeTrue())
+		Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: workloads, Name: vfMappingConfigMapPrefix + "node1"}, cm)).To(Succeed())
+		Expect(cm.OwnerReferences).To(BeEmpty())
+	})
+
+	It("updates the mapping when VFs are reconfigured and removes it with the NodeConfig", func() {
+		reconcile()
+
+		nc.Status.Inventory.SriovAccelerators[0].VFs = []sriovfecv2.VF{{PCIAddress: "0000:f7:00.1", Index: pointer.Int(0), Driver: "igb_uio"}}
+		Expect(c.Update(context.TODO(), nc)).To(Succeed())
+		reconcile()
+		Expect(mappingOf(workloads)).To(Equal(map[string][]VFMappingEntry{"0000:f7:00.0": {
+			{Index: pointer.Int(0), PCIAddress: "0000:f7:00.1", Driver: "igb_uio"},
+		}}))
+
+		// the list of namespaces changes only with restart of the operator
+		Expect(os.Unsetenv(vfMappingNamespacesEnv)).To(Succeed())
+		r = &VFMappingReconciler{Client: c, APIReader: c, Log: logrus.New()}
+		reconcile()
+		cms := new(corev1.ConfigMapList)
+		Expect(c.List(context.TODO(), cms, client.InNamespace(workloads))).To(Succeed())
+		Expect(cms.Items).To(BeEmpty())
+
+		Expect(c.Delete(context.TODO(), nc)).To(Succeed())
+		reconcile()
+		Expect(c.List(context.TODO(), cms)).To(Succeed())
+		Expect(cms.Items).To(BeEmpty())
+	})
+})
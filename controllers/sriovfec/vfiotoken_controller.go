@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	// VfioTokenConsumerLabel set to "true" on a Namespace requests projection of VFIO token into VfioTokenSecretName
+	// Secret of that namespace, so DPDK workloads can consume the token without copying it manually
+	VfioTokenConsumerLabel = "sriovfec.intel.com/vfio-token-consumer"
+
+	// VfioTokenSecretName is a name of the Secret holding VFIO token in operator's namespace and in consumer namespaces
+	VfioTokenSecretName = "vfio-token"
+	VfioTokenKey        = "VFIO_TOKEN"
+
+	// projectedVfioTokenName is a value of app.kubernetes.io/name label of projected Secrets
+	projectedVfioTokenName = "projected-vfio-token"
+)
+
+// VfioTokenReconciler keeps VFIO token Secrets in consumer namespaces in sync with the one created by the operator
+type VfioTokenReconciler struct {
+	client.Client
+	// APIReader reads Secrets outside of operator's namespace, which are not cached by the manager
+	APIReader client.Reader
+	Log       *logrus.Logger
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+
+func (r *VfioTokenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Name == NAMESPACE {
+		return ctrl.Result{}, nil
+	}
+
+	ns := new(corev1.Namespace)
+	if err := r.Get(ctx, client.ObjectKey{Name: req.Name}, ns); err != nil {
+		// projected Secret is removed together with its namespace
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	log := r.Log.WithField("namespace", ns.Name)
+
+	projected := new(corev1.Secret)
+	err := r.APIReader.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: VfioTokenSecretName}, projected)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	exists := err == nil
+
+	if exists && !utils.HasStandardLabels(projected, projectedVfioTokenName, NAMESPACE) {
+		log.Warnf("secret %s is not managed by the operator - skipping VFIO token projection", VfioTokenSecretName)
+		return ctrl.Result{}, nil
+	}
+
+	if !isVfioTokenConsumer(ns) {
+		if !exists {
+			return ctrl.Result{}, nil
+		}
+		log.Info("namespace is no longer VFIO token consumer - removing projected token")
+		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, projected))
+	}
+
+	origin := new(corev1.Secret)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: NAMESPACE, Name: VfioTokenSecretName}, origin); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("VFIO token secret does not exist yet - projection postponed")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !exists {
+		projected = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: VfioTokenSecretName, Namespace: ns.Name},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{VfioTokenKey: origin.Data[VfioTokenKey]},
+		}
+		utils.SetStandardLabels(projected, projectedVfioTokenName, NAMESPACE)
+		log.Info("projecting VFIO token")
+		return ctrl.Result{}, r.Create(ctx, projected)
+	}
+
+	if bytes.Equal(projected.Data[VfioTokenKey], origin.Data[VfioTokenKey]) {
+		return ctrl.Result{}, nil
+	}
+	projected.Data = map[string][]byte{VfioTokenKey: origin.Data[VfioTokenKey]}
+	log.Info("updating projected VFIO token")
+	return ctrl.Result{}, r.Update(ctx, projected)
+}
+
+// consumerNamespaces returns function mapping change of VFIO token to reconcile requests of all consumer namespaces
+func (r *VfioTokenReconciler) consumerNamespaces(ctx context.Context) handler.MapFunc {
+	return func(_ client.Object) []reconcile.Request {
+		namespaces := new(corev1.NamespaceList)
+		if err := r.List(ctx, namespaces, client.MatchingLabels{VfioTokenConsumerLabel: "true"}); err != nil {
+			r.Log.WithError(err).Error("failed to list VFIO token consumer namespaces")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, ns := range namespaces.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: ns.Name}})
+		}
+		return requests
+	}
+}
+
+func (r *VfioTokenReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	isVfioTokenSecret := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetNamespace() == NAMESPACE && o.GetName() == VfioTokenSecretName
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("vfiotoken").
+		For(&corev1.Namespace{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.consumerNamespaces(ctx)),
+			builder.WithPredicates(isVfioTokenSecret)).
+		Complete(r)
+}
+
+func isVfioTokenConsumer(ns *corev1.Namespace) bool {
+	return ns.GetLabels()[VfioTokenConsumerLabel] == "true"
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovfec
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VfioTokenReconciler", func() {
+	const consumerNamespace = "vfio-token-consumer"
+
+	var (
+		reconciler *VfioTokenReconciler
+		origin     *corev1.Secret
+		consumer   *corev1.Namespace
+	)
+
+	reconcileConsumer := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKey{Name: consumerNamespace}})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	projectedToken := func() (*corev1.Secret, error) {
+		secret := new(corev1.Secret)
+		return secret, k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: consumerNamespace, Name: VfioTokenSecretName}, secret)
+	}
+
+	BeforeEach(func() {
+		reconciler = &VfioTokenReconciler{Client: k8sClient, APIReader: k8sClient, Log: logrus.New()}
+
+		origin = &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: VfioTokenSecretName, Namespace: NAMESPACE},
+			Data:       map[string][]byte{VfioTokenKey: []byte("02bddbbf-bbb0-4d79-886b-91bad3fbb510")},
+		}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(context.TODO(), origin))).To(Succeed())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(origin), origin)).To(Succeed())
+
+		consumer = &corev1.Namespace{ObjectMeta: v1.ObjectMeta{
+			Name:   consumerNamespace,
+			Labels: map[string]string{VfioTokenConsumerLabel: "true"},
+		}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(context.TODO(), consumer))).To(Succeed())
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(consumer), consumer)).To(Succeed())
+		consumer.Labels = map[string]string{VfioTokenConsumerLabel: "true"}
+		Expect(k8sClient.Update(context.TODO(), consumer)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		projected, err := projectedToken()
+		if err == nil {
+			Expect(k8sClient.Delete(context.TODO(), projected)).To(Succeed())
+		}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(context.TODO(), origin))).To(Succeed())
+	})
+
+	It("projects VFIO token into consumer namespace and keeps it in sync", func() {
+		reconcileConsumer()
+		projected, err := projectedToken()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(projected.Data).To(HaveKeyWithValue(VfioTokenKey, origin.Data[VfioTokenKey]))
+
+		origin.Data[VfioTokenKey] = []byte("4cd0ae5b-2c6c-4f2c-9d57-7f0a5d0e38c1")
+		Expect(k8sClient.Update(context.TODO(), origin)).To(Succeed())
+		reconcileConsumer()
+		projected, err = projectedToken()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(projected.Data).To(HaveKeyWithValue(VfioTokenKey, origin.Data[VfioTokenKey]))
+	})
+
+	It("removes projected token when namespace is no longer consumer", func() {
+		reconcileConsumer()
+		_, err := projectedToken()
+		Expect(err).ToNot(HaveOccurred())
+
+		consumer.Labels = nil
+		Expect(k8sClient.Update(context.TODO(), consumer)).To(Succeed())
+		reconcileConsumer()
+		_, err = projectedToken()
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("does not overwrite secret not managed by the operator", func() {
+		foreign := &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: VfioTokenSecretName, Namespace: consumerNamespace},
+			Data:       map[string][]byte{VfioTokenKey: []byte("user-provided")},
+		}
+		Expect(k8sClient.Create(context.TODO(), foreign)).To(Succeed())
+
+		reconcileConsumer()
+		projected, err := projectedToken()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(projected.Data).To(HaveKeyWithValue(VfioTokenKey, []byte("user-provided")))
+	})
+})
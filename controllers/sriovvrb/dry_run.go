@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovvrb
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// dryRunChanges keeps changes of NodeConfigs rendered by dry runs of ClusterConfigs, keyed by ClusterConfig name
+type dryRunChanges map[string][]vrbv1.DryRunNodeChange
+
+// splitDryRuns returns ClusterConfigs applied to nodes and ClusterConfigs with spec.dryRun, which are only previewed
+func splitDryRuns(clusterConfigs []vrbv1.SriovVrbClusterConfig) (applied, dryRuns []vrbv1.SriovVrbClusterConfig) {
+	for _, cc := range clusterConfigs {
+		if cc.Spec.DryRun {
+			dryRuns = append(dryRuns, cc)
+		} else {
+			applied = append(applied, cc)
+		}
+	}
+	return applied, dryRuns
+}
+
+// preview renders NodeConfig of the node as if the dry run config was applied along with applied ones and records how
+// the NodeConfig would change; errors of the dry run config are recorded in syncErrors
+func (r *SriovVrbClusterConfigReconciler) preview(node corev1.Node, matcher *clusterConfigMatcher, applied []vrbv1.SriovVrbClusterConfig,
+	dryRun vrbv1.SriovVrbClusterConfig, policy LostAcceleratorPolicy, changes dryRunChanges, syncErrors map[string][]error) {
+	ncc, err := matcher.match(node, append(append([]vrbv1.SriovVrbClusterConfig{}, applied...), dryRun))
+	if err != nil {
+		r.Log.WithField("node", node.Name).WithField("error", err).Info("Error when matching SriovVrbClusterConfigs")
+		return
+	}
+
+	selected := false
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		if cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress); cc.Name == dryRun.Name {
+			selected = true
+		}
+	}
+	if !selected {
+		return
+	}
+
+	// errors of applied configs are reported by their synchronization
+	validationErrors := map[string][]error{}
+	if err := r.validateNodeCapabilities(*ncc, validationErrors); err != nil {
+		syncErrors[dryRun.Name] = append(syncErrors[dryRun.Name], validationErrors[dryRun.Name]...)
+		return
+	}
+
+	rendered, err := renderNodeConfig(*ncc, policy)
+	if err != nil {
+		syncErrors[dryRun.Name] = append(syncErrors[dryRun.Name], fmt.Errorf("node %s: %w", node.Name, err))
+		return
+	}
+	if summary := utils.ChangeSummary(ncc.Spec.PhysicalFunctions, rendered.Spec.PhysicalFunctions); summary != "" {
+		changes[dryRun.Name] = append(changes[dryRun.Name], vrbv1.DryRunNodeChange{NodeName: node.Name, Changes: summary})
+	}
+}
+
+// of returns changes rendered by dry run of the ClusterConfig, sorted by node
+func (changes dryRunChanges) of(name string) []vrbv1.DryRunNodeChange {
+	nodes := changes[name]
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].NodeName < nodes[j].NodeName
+	})
+	return nodes
+}
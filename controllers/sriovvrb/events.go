@@ -0,0 +1,33 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovvrb
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeConfigRenderedReason is a reason of Event recorded on ClusterConfigs which configuration was rendered into changed
+// spec of NodeConfig
+const NodeConfigRenderedReason = "NodeConfigRendered"
+
+// recordNodeConfigRendered records Event on each ClusterConfig configuring accelerators of the node, so users see where
+// their configuration was propagated in kubectl describe instead of logs of the operator
+func (r *SriovVrbClusterConfigReconciler) recordNodeConfigRendered(ncc NodeConfigurationCtx, summary string) {
+	if r.Recorder == nil {
+		return
+	}
+	message := "SriovVrbNodeConfig rendered for node " + ncc.Name
+	if summary != "" {
+		message += ": " + summary
+	}
+	seen := map[string]bool{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		if seen[cc.Name] {
+			continue
+		}
+		seen[cc.Name] = true
+		r.Recorder.Event(&cc, corev1.EventTypeNormal, NodeConfigRenderedReason, message)
+	}
+}
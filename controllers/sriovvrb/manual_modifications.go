@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovvrb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/audit"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const nodeConfigKind = "SriovVrbNodeConfig"
+
+// isManuallyModified returns true if spec of the NodeConfig differs from the one last written by the operator; spec of
+// orphaned NodeConfigs is written by the daemon, so it's not a manual modification
+func isManuallyModified(nc *vrbv1.SriovVrbNodeConfig) bool {
+	return !nc.IsOrphaned() && audit.IsModified(nc.Annotations[vrbv1.RenderedSpecAnnotation], nc.Spec)
+}
+
+// recordRevertedModification records that manual modification of the node's NodeConfig was reverted on behalf of
+// ClusterConfigs rendered into it
+func (r *SriovVrbClusterConfigReconciler) recordRevertedModification(ncc NodeConfigurationCtx, now time.Time) {
+	var configs []string
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress)
+		configs = append(configs, cc.Name)
+	}
+	r.Log.WithField("node", ncc.Name).WithField("SriovVrbClusterConfigs", configs).
+		Info("manual modification of SriovVrbNodeConfig spec reverted")
+	r.reverts.Record(nodeConfigKind, ncc.Name, configs, now)
+}
+
+// manualModificationConditions sets ManualModificationReverted condition of ClusterConfigs which NodeConfigs were
+// manually modified and reverted in the last audit interval, the condition is set to false once the interval passes;
+// the metric of detected modifications is reported along with them
+func manualModificationConditions(clusterConfigs []vrbv1.SriovVrbClusterConfig, conditions utils.Conditions,
+	reverts *audit.Reverts, now time.Time) {
+	detected := false
+	for _, cc := range clusterConfigs {
+		nodes := reverts.Of(cc.Name, now)
+		previous := meta.FindStatusCondition(cc.Status.Conditions, vrbv1.ManualModificationRevertedCondition)
+
+		switch {
+		case len(nodes) != 0:
+			detected = true
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: vrbv1.ManualModificationRevertedCondition,
+				Status: metav1.ConditionTrue, ObservedGeneration: cc.Generation, Reason: "ManualModificationReverted",
+				Message: fmt.Sprintf("manual modifications of SriovVrbNodeConfigs of nodes %s were reverted", strings.Join(nodes, ", "))})
+		case previous == nil:
+			// the condition is reported once a modification of the ClusterConfig's NodeConfigs is reverted
+		case previous.Status == metav1.ConditionTrue && now.Sub(previous.LastTransitionTime.Time) < audit.Interval:
+			// reverts are kept in memory only, so the condition reported before restart of the operator is kept
+			detected = true
+		default:
+			conditions.Set(cc.Name, cc.Status.Conditions, metav1.Condition{Type: vrbv1.ManualModificationRevertedCondition,
+				Status: metav1.ConditionFalse, ObservedGeneration: cc.Generation, Reason: "NoManualModifications",
+				Message: fmt.Sprintf("no manual modification of SriovVrbNodeConfigs was reverted in the last %s", audit.Interval)})
+		}
+	}
+	audit.Report(nodeConfigKind, detected)
+}
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package sriovvrb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
+)
+
+// ConditionMigrated is set on NodeConfig migrated from NodeConfig of a replaced node, the message names the replaced node
+const ConditionMigrated = "Migrated"
+
+// annotations of NodeConfig which are specific to the node or managed by the operator and the daemon, so they are not
+// migrated to the replacement node
+var nodeSpecificAnnotations = map[string]bool{
+	vrbv1.RenderedSpecAnnotation:          true,
+	vrbv1.ChangeSummaryAnnotation:         true,
+	vrbv1.OrphanedAnnotation:              true,
+	vrbv1.AdoptAnnotation:                 true,
+	vrbv1.ManualOverrideAnnotation:        true,
+	vrbv1.ManualOverrideSinceAnnotation:   true,
+	vrbv1.MigratedFromAnnotation:          true,
+	vrbv1.ClearHardwareFaultAnnotation:    true,
+	vrbv1.HardwareFaultBaselineAnnotation: true,
+}
+
+// nodeReplacement is NodeConfig of a removed node and NodeConfig of an accelerated node reporting any of its accelerators
+type nodeReplacement struct {
+	previous *vrbv1.SriovVrbNodeConfig
+	current  *vrbv1.SriovVrbNodeConfig
+}
+
+// findNodeReplacements matches NodeConfigs of removed nodes with NodeConfigs of accelerated nodes by serial numbers of
+// accelerators reported in their inventory. NodeConfig of a removed node is migrated to at most one node.
+func findNodeReplacements(nodeConfigs []vrbv1.SriovVrbNodeConfig, nodes []corev1.Node, acceleratedNodes []corev1.Node) []nodeReplacement {
+	existing := map[string]bool{}
+	for _, node := range nodes {
+		existing[node.Name] = true
+	}
+	accelerated := map[string]bool{}
+	for _, node := range acceleratedNodes {
+		accelerated[node.Name] = true
+	}
+
+	sort.Slice(nodeConfigs, func(i, j int) bool { return nodeConfigs[i].Name < nodeConfigs[j].Name })
+
+	// removed nodes keyed by serial numbers of their accelerators
+	removed := map[string]*vrbv1.SriovVrbNodeConfig{}
+	for i := range nodeConfigs {
+		if existing[nodeConfigs[i].Name] {
+			continue
+		}
+		for _, acc := range nodeConfigs[i].Status.Inventory.SriovAccelerators {
+			if acc.SerialNumber != "" {
+				removed[acc.SerialNumber] = &nodeConfigs[i]
+			}
+		}
+	}
+
+	var replacements []nodeReplacement
+	migrated := map[string]bool{}
+	for i := range nodeConfigs {
+		current := &nodeConfigs[i]
+		if !accelerated[current.Name] {
+			continue
+		}
+		for _, acc := range current.Status.Inventory.SriovAccelerators {
+			if previous, ok := removed[acc.SerialNumber]; ok && acc.SerialNumber != "" && !migrated[previous.Name] {
+				migrated[previous.Name] = true
+				replacements = append(replacements, nodeReplacement{previous: previous, current: current})
+				break
+			}
+		}
+	}
+	return replacements
+}
+
+// migrateReplacedNodes migrates NodeConfigs of nodes removed from the cluster to nodes reporting the same accelerators,
+// e.g. after the node was reprovisioned under a new name, and deletes them, so reinstalled nodes don't require manual
+// cleanup of NodeConfigs. Configuration of accelerators is rendered for the new node from ClusterConfigs as usual.
+func (r *SriovVrbClusterConfigReconciler) migrateReplacedNodes(ctx context.Context, acceleratedNodes []corev1.Node) {
+	if !featuregates.Enabled(featuregates.NodeMigration) {
+		return
+	}
+
+	nodes := new(corev1.NodeList)
+	if err := r.List(ctx, nodes); err != nil {
+		r.Log.WithError(err).Error("failed to list nodes to find replaced nodes")
+		return
+	}
+	nodeConfigs := new(vrbv1.SriovVrbNodeConfigList)
+	if err := r.List(ctx, nodeConfigs, client.InNamespace(NAMESPACE)); err != nil {
+		r.Log.WithError(err).Error("failed to list SriovVrbNodeConfigs to find replaced nodes")
+		return
+	}
+
+	for _, replacement := range findNodeReplacements(nodeConfigs.Items, nodes.Items, acceleratedNodes) {
+		log := r.Log.WithField("previous", replacement.previous.Name).WithField("node", replacement.current.Name)
+		if err := r.migrateNodeConfig(ctx, replacement); err != nil {
+			log.WithError(err).Error("failed to migrate SriovVrbNodeConfig of replaced node")
+			continue
+		}
+		log.Info("SriovVrbNodeConfig of replaced node migrated")
+	}
+}
+
+// migrateNodeConfig copies annotations of the previous NodeConfig which are not specific to the node, e.g. protection,
+// records the migration in MigratedFromAnnotation and Migrated condition of the current NodeConfig and deletes the
+// previous one. Status of the previous NodeConfig isn't copied: its conditions, inventory and drain describe the removed
+// node and its operations are keyed by boot of the removed node, while the daemon of the current node reports them on its
+// own. The outcome of the last configuration and the last change of the previous NodeConfig are kept in the message of
+// Migrated condition instead.
+func (r *SriovVrbClusterConfigReconciler) migrateNodeConfig(ctx context.Context, replacement nodeReplacement) error {
+	previous, current := replacement.previous.DeepCopy(), replacement.current.DeepCopy()
+
+	if current.Annotations == nil {
+		current.Annotations = map[string]string{}
+	}
+	for key, value := range previous.Annotations {
+		if _, ok := current.Annotations[key]; !ok && !nodeSpecificAnnotations[key] {
+			current.Annotations[key] = value
+		}
+	}
+	current.Annotations[vrbv1.MigratedFromAnnotation] = previous.Name
+	if err := r.Update(ctx, current); err != nil {
+		return err
+	}
+
+	message := migratedMessage(previous)
+	meta.SetStatusCondition(&current.Status.Conditions, metav1.Condition{
+		Type:               ConditionMigrated,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: current.GetGeneration(),
+		Reason:             "NodeReplaced",
+		Message:            message,
+	})
+	if err := r.Status().Update(ctx, current); err != nil {
+		return err
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(current, corev1.EventTypeNormal, "NodeReplaced", message)
+	}
+
+	// the webhook rejects deletion of protected NodeConfig, the protection was migrated along with other annotations
+	if previous.IsProtected() {
+		delete(previous.Annotations, vrbv1.ProtectAnnotation)
+		if err := r.Update(ctx, previous); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, previous))
+}
+
+// migratedMessage names the replaced node along with the outcome of its last configuration and its last change
+func migratedMessage(previous *vrbv1.SriovVrbNodeConfig) string {
+	message := fmt.Sprintf("migrated from SriovVrbNodeConfig of replaced node %s", previous.Name)
+	if configured := meta.FindStatusCondition(previous.Status.Conditions, nodeConfiguredCondition); configured != nil {
+		message += fmt.Sprintf("; its last configuration: %s", configured.Reason)
+		if configured.Message != "" {
+			message += fmt.Sprintf(" (%s)", configured.Message)
+		}
+	}
+	if summary := previous.Annotations[vrbv1.ChangeSummaryAnnotation]; summary != "" {
+		message += fmt.Sprintf("; its last change: %s", summary)
+	}
+	return message
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/elliotchance/orderedmap/v2"
@@ -19,12 +20,25 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/audit"
+	"github.com/intel/sriov-fec-operator/pkg/common/backoff"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+	"github.com/intel/sriov-fec-operator/pkg/common/notification"
+	"github.com/intel/sriov-fec-operator/pkg/common/upgradeguard"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 )
 
 var NAMESPACE = os.Getenv("SRIOV_FEC_NAMESPACE")
@@ -33,6 +47,17 @@ var NAMESPACE = os.Getenv("SRIOV_FEC_NAMESPACE")
 type SriovVrbClusterConfigReconciler struct {
 	client.Client
 	Log *logrus.Logger
+	// Recorder emits Events on ClusterConfigs and NodeConfigs about rendered configuration and failures, optional
+	Recorder record.EventRecorder
+	// UpgradeGuard defers disruptive reconfigurations of nodes while the cluster is being upgraded, optional
+	UpgradeGuard *upgradeguard.Guard
+	// Notifier pushes failures of nodes and degradation of ClusterConfigs to configured notification sinks, optional
+	Notifier *notification.Notifier
+	// Backoff delays retries of failed reconciliations, errors are returned to controller-runtime if not set
+	Backoff *backoff.Backoff
+
+	capabilities nodeCapabilitiesCache
+	reverts      audit.Reverts
 }
 
 // +kubebuilder:rbac:groups=sriovvrb.intel.com,resources=sriovvrbclusterconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -43,6 +68,7 @@ type SriovVrbClusterConfigReconciler struct {
 // +kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts;secrets;configmaps,verbs=get;list;create;update
 // +kubebuilder:rbac:groups=apps,resources=daemonsets;deployments;deployments/finalizers,verbs=get;list;create;update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings;clusterroles;clusterrolebindings,verbs=get;list;create;update
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;create;update;delete
 // +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=use,resourceNames=privileged
 // +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
@@ -55,31 +81,77 @@ func (r *SriovVrbClusterConfigReconciler) Reconcile(ctx context.Context, req ctr
 	r.Log.Infof("Reconcile(...) triggered by %s", req.NamespacedName.String())
 
 	clusterConfigList := new(vrbv1.SriovVrbClusterConfigList)
-	if err := r.List(context.TODO(), clusterConfigList, client.InNamespace(NAMESPACE)); err != nil {
+	if err := r.List(ctx, clusterConfigList, client.InNamespace(NAMESPACE)); err != nil {
 		r.Log.WithError(err).Error("cannot obtain list of SriovVrbClusterConfig, rescheduling rescheduling reconcile call")
-		return ctrl.Result{}, err
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
 	}
 
-	nodes, err := r.getAcceleratedNodes()
+	nodes, err := r.getAcceleratedNodes(ctx)
 	if err != nil {
 		r.Log.WithError(err).Info("cannot obtain list of accelerated nodes, rescheduling rescheduling reconcile call")
-		return reconcile.Result{}, err
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
 	}
+	r.migrateReplacedNodes(ctx, nodes)
 
-	clusterConfigurationMatcher := createClusterConfigMatcher(r.getOrInitializeSriovVrbNodeConfig, r.Log)
+	// syncErrors keeps errors of ClusterConfigs, keyed by ClusterConfig name
+	syncErrors := map[string][]error{}
+
+	// ClusterConfigs with spec.dryRun are not applied, changes they would make are only reported in their status
+	applied, dryRuns := splitDryRuns(r.manageFinalizers(ctx, clusterConfigList.Items))
+	previews := dryRunChanges{}
+
+	canaries := newCanaryRollouts(applied, time.Now())
+	overridden := overriddenAccelerators{}
+	effective := new(EffectiveConfiguration)
+	rollouts := configRollouts{}
+
+	policy := lostAcceleratorPolicy(r.Log)
+	clusterConfigurationMatcher := createClusterConfigMatcher(func(nodeName string) (*vrbv1.SriovVrbNodeConfig, error) {
+		return r.getOrInitializeSriovVrbNodeConfig(ctx, nodeName)
+	}, r.Log)
 	for _, node := range nodes {
-		configurationContextProvider, err := clusterConfigurationMatcher.match(node, clusterConfigList.Items)
+		for _, dryRun := range dryRuns {
+			r.preview(node, clusterConfigurationMatcher, applied, dryRun, policy, previews, syncErrors)
+		}
+
+		configurationContextProvider, err := clusterConfigurationMatcher.match(node, applied)
 		if err != nil {
 			r.Log.WithField("node", node.Name).WithField("error", err).Info("Error when matching SriovVrbClusterConfigs")
 			continue
 		}
 
-		if err := r.synchronizeNodeConfigSpec(*configurationContextProvider); err != nil {
-			r.Log.WithField("name", node.Name).WithField("error", err).Info("failed to propagate configuration into SriovVrbNodeConfig")
+		overridden.record(&node, *configurationContextProvider, applied)
+		effective.record(*configurationContextProvider, policy)
+
+		if cc := pausedBy(*configurationContextProvider); cc != "" {
+			r.Log.WithField("node", node.Name).WithField("SriovVrbClusterConfig", cc).Info("SriovVrbClusterConfig is paused, configuration is not propagated")
+			rollouts.hold(node, *configurationContextProvider)
+			continue
+		}
+
+		if cc := canaries.heldBy(node, *configurationContextProvider); cc != "" {
+			r.Log.WithField("node", node.Name).WithField("SriovVrbClusterConfig", cc).Info("waiting for canary nodes, configuration is not propagated")
+			rollouts.hold(node, *configurationContextProvider)
+			continue
+		}
+
+		updated := false
+		err = r.validateNodeCapabilities(*configurationContextProvider, syncErrors)
+		if err == nil {
+			updated, err = r.synchronizeNodeConfigSpec(ctx, *configurationContextProvider, policy)
+			rollouts.observe(node, *configurationContextProvider, updated, err)
+		} else {
+			// validation errors are already recorded for responsible configs
+			rollouts.hold(node, *configurationContextProvider)
+		}
+		canaries.observe(node, *configurationContextProvider, updated, err)
+		if err != nil {
+			r.Log.WithField("name", node.Name).WithField("error", err).WithField("class", errclass.Record(errclass.Operator, err)).
+				Info("failed to propagate configuration into SriovVrbNodeConfig")
 
 			err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 				snc := new(vrbv1.SriovVrbNodeConfig)
-				if err := r.Get(context.TODO(), types.NamespacedName{Namespace: NAMESPACE, Name: node.Name}, snc); err != nil {
+				if err := r.Get(ctx, types.NamespacedName{Namespace: NAMESPACE, Name: node.Name}, snc); err != nil {
 					return err
 				}
 
@@ -87,7 +159,7 @@ func (r *SriovVrbClusterConfigReconciler) Reconcile(ctx context.Context, req ctr
 				r.Log.
 					WithField("vrbnc", snc).
 					Info("updating svnc status")
-				return r.Status().Update(context.TODO(), snc)
+				return r.Status().Update(ctx, snc)
 			})
 
 			if err != nil {
@@ -95,14 +167,153 @@ func (r *SriovVrbClusterConfigReconciler) Reconcile(ctx context.Context, req ctr
 			}
 			continue
 		}
+		r.reportLostAccelerators(ctx, node.Name, lostPhysicalFunctions(&configurationContextProvider.SriovVrbNodeConfig, true), policy)
+	}
+	r.handleNodesWithoutAccelerators(ctx, nodes, policy)
+	if err := r.publishEffectiveConfig(ctx, effective); err != nil {
+		r.Log.WithError(err).Error("failed to publish effective configuration")
 	}
 
-	return r.requeueIfClusterConfigExists(req.NamespacedName)
+	conditions := canaries.conditions(syncErrors)
+	pausedConditions(clusterConfigList.Items, conditions)
+	rollouts.standardConditions(clusterConfigList.Items, syncErrors, overridden, conditions)
+	manualModificationConditions(clusterConfigList.Items, conditions, &r.reverts, time.Now())
+	skipped := r.findSkippedNodes(ctx, nodes, clusterConfigList.Items)
+	r.updateClusterConfigsStatus(ctx, clusterConfigList.Items, syncErrors, conditions, rollouts, overridden, previews, skipped)
+	r.releaseDeconfigured(ctx, clusterConfigList.Items, rollouts)
+
+	result, err := r.requeueIfClusterConfigExists(ctx, req.NamespacedName)
+	if err != nil {
+		r.Log.WithError(err).Error("cannot determine whether reconcile is needed, rescheduling reconcile call")
+		return r.Backoff.Requeue(req.NamespacedName.String(), err)
+	}
+	r.Backoff.Reset(req.NamespacedName.String())
+	return result, nil
+}
+
+// validateNodeCapabilities validates physical functions rendered for the node against capabilities reported by its daemon,
+// so misconfiguration fails fast instead of being rejected by the daemon. Errors are recorded for responsible ClusterConfigs.
+func (r *SriovVrbClusterConfigReconciler) validateNodeCapabilities(ncc NodeConfigurationCtx, syncErrors map[string][]error) error {
+	if !r.capabilities.contains(ncc.Name) {
+		r.capabilities.update(&ncc.SriovVrbNodeConfig)
+	}
+
+	var pfs []vrbv1.PhysicalFunctionConfigExt
+	errs := map[string]error{}
+	for _, pciAddress := range ncc.AcceleratorConfigContext.Keys() {
+		if cc, _ := ncc.AcceleratorConfigContext.Get(pciAddress); !cc.Spec.IsAbsent() {
+			pf := physicalFunctionFor(pciAddress, cc)
+			if err := expandProfile(&pf, ncc.Status.Inventory); err != nil {
+				errs[pciAddress] = &unschedulableError{nodeName: ncc.Name, pciAddress: pciAddress, err: err}
+			}
+			pfs = append(pfs, pf)
+		}
+	}
+
+	for pciAddress, err := range r.capabilities.validate(ncc.Name, pfs) {
+		if _, ok := errs[pciAddress]; !ok {
+			errs[pciAddress] = err
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	var messages []string
+	class := errclass.Unknown
+	for _, pf := range pfs {
+		if err, ok := errs[pf.PCIAddress]; ok {
+			cc, _ := ncc.AcceleratorConfigContext.Get(pf.PCIAddress)
+			syncErrors[cc.Name] = append(syncErrors[cc.Name], err)
+			messages = append(messages, err.Error())
+			if class == errclass.Unknown {
+				class = errclass.Of(err)
+			}
+		}
+	}
+	return errclass.New(class, "requested configuration is not supported by the node: %s", strings.Join(messages, "; "))
 }
 
-func (r *SriovVrbClusterConfigReconciler) requeueIfClusterConfigExists(cc types.NamespacedName) (ctrl.Result, error) {
+// updateClusterConfigsStatus reports sync errors, conditions, state of nodes, overridden accelerators, dry run changes
+// and skipped nodes of ClusterConfigs in their status and Events; class of the first error is reported as the class of the ClusterConfig's error
+func (r *SriovVrbClusterConfigReconciler) updateClusterConfigsStatus(ctx context.Context, clusterConfigs []vrbv1.SriovVrbClusterConfig,
+	syncErrors map[string][]error, conditions utils.Conditions, rollouts configRollouts, overridden overriddenAccelerators,
+	previews dryRunChanges, skipped skippedNodes) {
+	for i := range clusterConfigs {
+		cc := &clusterConfigs[i]
+		status := vrbv1.SriovVrbClusterConfigStatus{SyncStatus: vrbv1.SucceededSync}
+		if errs, ok := syncErrors[cc.Name]; ok {
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			status = vrbv1.SriovVrbClusterConfigStatus{
+				SyncStatus:           vrbv1.FailedSync,
+				LastSyncError:        strings.Join(messages, "; "),
+				LastSyncErrorClass:   string(errclass.Of(errs[0])),
+				NodeValidationErrors: nodeValidationErrors(errs),
+			}
+		}
+		status.Conditions = conditions[cc.Name]
+		status.NodesConfigured = rollouts.nodesConfigured(cc.Name)
+		status.NodeFailures = rollouts.nodeFailures(cc.Name)
+		status.OverriddenAccelerators = overridden.of(cc.Name)
+		status.DryRun = previews.of(cc.Name)
+		status.SkippedNodes = skipped.of(cc.Name)
+		status.ObservedGeneration = cc.GetGeneration()
+		if equality.Semantic.DeepEqual(cc.Status, status) {
+			continue
+		}
+		notifications := criticalNotifications(cc, status)
+		cc.Status = status
+		if err := r.Status().Update(ctx, cc); err != nil {
+			r.Log.WithError(err).WithField("name", cc.Name).Error("failed to update SriovVrbClusterConfig status")
+		} else {
+			r.Notifier.Notify(notifications...)
+		}
+		if r.Recorder != nil && status.SyncStatus == vrbv1.FailedSync {
+			r.Recorder.Event(cc, corev1.EventTypeWarning, status.LastSyncErrorClass, status.LastSyncError)
+		}
+	}
+}
+
+func physicalFunctionFor(pciAddress string, cc vrbv1.SriovVrbClusterConfig) vrbv1.PhysicalFunctionConfigExt {
+	return vrbv1.PhysicalFunctionConfigExt{
+		PCIAddress:        pciAddress,
+		PFDriver:          cc.Spec.PhysicalFunction.PFDriver,
+		PFDriverFallbacks: cc.Spec.PhysicalFunction.PFDriverFallbacks,
+		VFDriver:          cc.Spec.PhysicalFunction.VFDriver,
+		VFAmount:          cc.Spec.PhysicalFunction.VFAmount,
+		VFDriverOverrides: cc.Spec.PhysicalFunction.VFDriverOverrides,
+		VFMsixCount:       cc.Spec.PhysicalFunction.VFMsixCount,
+		PowerManagement:   cc.Spec.PhysicalFunction.PowerManagement,
+		BBDevConfig:       cc.Spec.PhysicalFunction.BBDevConfig,
+	}
+}
+
+// expandProfile replaces profile of the physical function's bbDevConfig with configuration the profile expands to for
+// the accelerator reported in the inventory
+func expandProfile(pf *vrbv1.PhysicalFunctionConfigExt, inventory vrbv1.NodeInventory) error {
+	if pf.BBDevConfig.Profile == "" {
+		return nil
+	}
+	for _, acc := range inventory.SriovAccelerators {
+		if acc.PCIAddress != pf.PCIAddress {
+			continue
+		}
+		config, err := pf.BBDevConfig.Expand(vrbDevices[acc.DeviceID], pf.VFAmount)
+		if err != nil {
+			return errclass.Wrap(errclass.Validation, err)
+		}
+		pf.BBDevConfig = config
+		return nil
+	}
+	return errclass.New(errclass.Validation, "accelerator is not reported in the inventory, profile %s cannot be expanded", pf.BBDevConfig.Profile)
+}
+
+func (r *SriovVrbClusterConfigReconciler) requeueIfClusterConfigExists(ctx context.Context, cc types.NamespacedName) (ctrl.Result, error) {
 	vrbcc := &vrbv1.SriovVrbClusterConfig{}
-	err := r.Get(context.TODO(), cc, vrbcc)
+	err := r.Get(ctx, cc, vrbcc)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return ctrl.Result{}, nil
@@ -110,10 +321,22 @@ func (r *SriovVrbClusterConfigReconciler) requeueIfClusterConfigExists(cc types.
 		return ctrl.Result{}, fmt.Errorf("failed to get ClusterConfig to determine whenever reconcile is needed - %v", err)
 	}
 
+	if vrbcc.Spec.SyncInterval != nil {
+		return ctrl.Result{RequeueAfter: vrbcc.Spec.SyncInterval.Duration}, nil
+	}
 	return ctrl.Result{RequeueAfter: time.Minute}, nil
 }
 
-func (r *SriovVrbClusterConfigReconciler) synchronizeNodeConfigSpec(ncc NodeConfigurationCtx) error {
+// shorterSyncInterval returns shorter of sync intervals, nil if none is set
+func shorterSyncInterval(a, b *metav1.Duration) *metav1.Duration {
+	if a == nil || (b != nil && b.Duration < a.Duration) {
+		return b
+	}
+	return a
+}
+
+// renderNodeConfig returns NodeConfig with configuration rendered from ClusterConfigs of the node
+func renderNodeConfig(ncc NodeConfigurationCtx, policy LostAcceleratorPolicy) (*vrbv1.SriovVrbNodeConfig, error) {
 	copyWithEmptySpec := func(nc vrbv1.SriovVrbNodeConfig) *vrbv1.SriovVrbNodeConfig {
 		newNC := nc.DeepCopy()
 		newNC.Spec = vrbv1.SriovVrbNodeConfigSpec{
@@ -127,50 +350,127 @@ func (r *SriovVrbClusterConfigReconciler) synchronizeNodeConfigSpec(ncc NodeConf
 
 	newNodeConfig := copyWithEmptySpec(ncc.SriovVrbNodeConfig)
 
+	// the node is drained only if configuration is disruptive when all configs of its accelerators allow it
+	drainIfDisruptive := true
+
 	// Use orederedmap for iteration
 	for _, pciAddress := range acceleratorConfigContext.Keys() {
 		cc, _ := acceleratorConfigContext.Get(pciAddress)
-		pf := vrbv1.PhysicalFunctionConfigExt{
-			PCIAddress:  pciAddress,
-			PFDriver:    cc.Spec.PhysicalFunction.PFDriver,
-			VFDriver:    cc.Spec.PhysicalFunction.VFDriver,
-			VFAmount:    cc.Spec.PhysicalFunction.VFAmount,
-			BBDevConfig: cc.Spec.PhysicalFunction.BBDevConfig,
-		}
+		drainIfDisruptive = drainIfDisruptive && cc.Spec.DrainPolicy == vrbv1.DrainIfDisruptive
+		pf := physicalFunctionFor(pciAddress, cc)
 		if cc.Spec.DrainSkip == nil {
 			newNodeConfig.Spec.DrainSkip = true
 		} else if cc.Spec.DrainSkip != nil {
 			newNodeConfig.Spec.DrainSkip = newNodeConfig.Spec.DrainSkip || *cc.Spec.DrainSkip
 		}
+		newNodeConfig.Spec.SyncInterval = shorterSyncInterval(newNodeConfig.Spec.SyncInterval, cc.Spec.SyncInterval)
+		// accelerator not listed in NodeConfig is deconfigured by the daemon
+		if cc.Spec.IsAbsent() {
+			continue
+		}
+		if err := expandProfile(&pf, ncc.Status.Inventory); err != nil {
+			return nil, err
+		}
 		newNodeConfig.Spec.PhysicalFunctions = append(newNodeConfig.Spec.PhysicalFunctions, pf)
 	}
 
-	// copy latest known drainSkip from NodeConfig for cleanup
+	// accelerators lost by the node are not matched by ClusterConfigs, their configuration is kept unless the policy
+	// requires cleanup
+	if policy != CleanupLostAccelerators {
+		newNodeConfig.Spec.PhysicalFunctions = append(newNodeConfig.Spec.PhysicalFunctions, lostPhysicalFunctions(&currentNodeConfig, true)...)
+	}
+
+	if drainIfDisruptive {
+		newNodeConfig.Spec.DrainPolicy = vrbv1.DrainIfDisruptive
+	}
+
+	// copy latest known drainSkip and drainPolicy from NodeConfig for cleanup
 	if acceleratorConfigContext.Len() == 0 {
 		newNodeConfig.Spec.DrainSkip = ncc.Spec.DrainSkip
+		newNodeConfig.Spec.DrainPolicy = ncc.Spec.DrainPolicy
+	}
+
+	// the webhook rejects duplicates in NodeConfigs, rendering must not produce them either
+	if errs := vrbv1.DuplicatePhysicalFunctions(newNodeConfig.Spec); len(errs) != 0 {
+		return nil, errclass.New(errclass.Validation, "rendered SriovVrbNodeConfig configures an accelerator more than once: %s", errs.ToAggregate())
+	}
+
+	if currentNodeConfig.IsProtected() {
+		if pruned := vrbv1.PrunedPhysicalFunctions(currentNodeConfig.Spec, newNodeConfig.Spec); len(pruned) != 0 {
+			return nil, errclass.New(errclass.Validation, "SriovVrbNodeConfig is protected by %s annotation, physical functions %v cannot be removed", vrbv1.ProtectAnnotation, pruned)
+		}
+	}
+
+	// NodeConfigs created before labels were introduced are labeled on first synchronization
+	utils.SetStandardLabels(newNodeConfig, utils.SriovVrbNodeConfigName, newNodeConfig.Name)
+
+	// NodeConfig recreated by the daemon after deletion is orphaned until configuration is rendered again
+	delete(newNodeConfig.Annotations, vrbv1.OrphanedAnnotation)
+
+	newNodeConfig.OwnerReferences = ownerReferences(ncc)
+
+	return newNodeConfig, nil
+}
+
+// synchronizeNodeConfigSpec updates NodeConfig with configuration rendered from ClusterConfigs; it returns true if
+// NodeConfig was updated
+func (r *SriovVrbClusterConfigReconciler) synchronizeNodeConfigSpec(ctx context.Context, ncc NodeConfigurationCtx, policy LostAcceleratorPolicy) (bool, error) {
+	currentNodeConfig := ncc.SriovVrbNodeConfig
+	newNodeConfig, err := renderNodeConfig(ncc, policy)
+	if err != nil {
+		return false, err
 	}
 
-	if !equality.Semantic.DeepEqual(newNodeConfig.Spec, currentNodeConfig.Spec) {
-		r.Log.Info("Node Config Changed")
-		return r.Update(context.TODO(), newNodeConfig)
+	renderedSpec := audit.Checksum(newNodeConfig.Spec)
+	if !equality.Semantic.DeepEqual(newNodeConfig.Spec, currentNodeConfig.Spec) || currentNodeConfig.IsOrphaned() ||
+		!utils.HasStandardLabels(&currentNodeConfig, utils.SriovVrbNodeConfigName, currentNodeConfig.Name) ||
+		!equality.Semantic.DeepEqual(newNodeConfig.OwnerReferences, currentNodeConfig.OwnerReferences) ||
+		currentNodeConfig.Annotations[vrbv1.RenderedSpecAnnotation] != renderedSpec {
+		if newNodeConfig.Annotations == nil {
+			newNodeConfig.Annotations = map[string]string{}
+		}
+		newNodeConfig.Annotations[vrbv1.RenderedSpecAnnotation] = renderedSpec
+		summary := utils.ChangeSummary(currentNodeConfig.Spec.PhysicalFunctions, newNodeConfig.Spec.PhysicalFunctions)
+		if summary != "" {
+			newNodeConfig.Annotations[vrbv1.ChangeSummaryAnnotation] = summary
+		}
+		// orphaned NodeConfigs are restored immediately
+		specChanged := !currentNodeConfig.IsOrphaned() && !equality.Semantic.DeepEqual(newNodeConfig.Spec, currentNodeConfig.Spec)
+		if specChanged && r.UpgradeGuard.InProgress() && hasVFs(&currentNodeConfig.Status.Inventory) {
+			r.Log.WithField("node", currentNodeConfig.Name).Info("cluster upgrade in progress - disruptive reconfiguration deferred")
+			return false, nil
+		}
+		r.Log.WithField("changes", summary).Info("Node Config Changed")
+		if err := r.Update(ctx, newNodeConfig); err != nil {
+			return true, err
+		}
+		if specChanged {
+			r.recordNodeConfigRendered(ncc, summary)
+		}
+		if specChanged && isManuallyModified(&currentNodeConfig) {
+			r.recordRevertedModification(ncc, time.Now())
+		}
+		return true, nil
 	}
-	return nil
+	return false, nil
 }
 
-func (r *SriovVrbClusterConfigReconciler) getAcceleratedNodes() ([]corev1.Node, error) {
+func (r *SriovVrbClusterConfigReconciler) getAcceleratedNodes(ctx context.Context) ([]corev1.Node, error) {
 	nl := new(corev1.NodeList)
-	labelsToMatch := &client.MatchingLabels{
-		"fpga.intel.com/intel-accelerator-present": "",
+	// nodes out of the scope are managed by another installation of the operator
+	labelsToMatch, err := nodescope.AcceleratedNodes()
+	if err != nil {
+		return nil, err
 	}
-	if err := r.List(context.TODO(), nl, labelsToMatch); err != nil {
+	if err := r.List(ctx, nl, labelsToMatch); err != nil {
 		return nil, err
 	}
 	return nl.Items, nil
 }
 
-func (r *SriovVrbClusterConfigReconciler) getOrInitializeSriovVrbNodeConfig(name string) (*vrbv1.SriovVrbNodeConfig, error) {
+func (r *SriovVrbClusterConfigReconciler) getOrInitializeSriovVrbNodeConfig(ctx context.Context, name string) (*vrbv1.SriovVrbNodeConfig, error) {
 	nc := new(vrbv1.SriovVrbNodeConfig)
-	if err := r.Get(context.TODO(), client.ObjectKey{Name: name, Namespace: NAMESPACE}, nc); err != nil {
+	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: NAMESPACE}, nc); err != nil {
 		if !errors.IsNotFound(err) {
 			return nil, err
 		}
@@ -182,12 +482,132 @@ func (r *SriovVrbClusterConfigReconciler) getOrInitializeSriovVrbNodeConfig(name
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *SriovVrbClusterConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *SriovVrbClusterConfigReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&vrbv1.SriovVrbClusterConfig{}).
+		For(&vrbv1.SriovVrbClusterConfig{},
+			// status written by the reconciler itself doesn't trigger reconciliation, annotations set by users
+			// (e.g. pausing the config) do
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Watches(&source.Kind{Type: &vrbv1.SriovVrbNodeConfig{}}, r.capabilities.eventHandler()).
+		Watches(&source.Kind{Type: &vrbv1.SriovVrbNodeConfig{}},
+			handler.EnqueueRequestsFromMapFunc(r.allClusterConfigs(ctx)),
+			builder.WithPredicates(predicate.Or(isOrphanedNodeConfigCreation, isInventoryChange, isConfiguredConditionChange))).
+		// accelerated nodes joining the cluster get NodeConfig immediately instead of on next change of ClusterConfig
+		Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterConfigs(ctx)),
+			builder.WithPredicates(isAcceleratedNodeChange)).
 		Complete(r)
 }
 
+// isOrphanedNodeConfigCreation passes creation of NodeConfig recreated by the daemon after it was deleted by mistake,
+// so configuration is rendered again immediately instead of on next resync
+var isOrphanedNodeConfigCreation = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		nc, ok := e.Object.(*vrbv1.SriovVrbNodeConfig)
+		return ok && nc.IsOrphaned()
+	},
+	UpdateFunc:  func(event.UpdateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isInventoryChange passes update of NodeConfig which inventory reports accelerators hot-plugged or removed by
+// the daemon, so configuration is rendered for them immediately instead of on next resync
+var isInventoryChange = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNc, ok := e.ObjectOld.(*vrbv1.SriovVrbNodeConfig)
+		if !ok {
+			return false
+		}
+		newNc, ok := e.ObjectNew.(*vrbv1.SriovVrbNodeConfig)
+		if !ok || len(oldNc.Status.Inventory.SriovAccelerators) != len(newNc.Status.Inventory.SriovAccelerators) {
+			return ok
+		}
+		for i := range oldNc.Status.Inventory.SriovAccelerators {
+			if oldNc.Status.Inventory.SriovAccelerators[i].PCIAddress != newNc.Status.Inventory.SriovAccelerators[i].PCIAddress {
+				return true
+			}
+		}
+		return false
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isConfiguredConditionChange passes update of NodeConfig which Configured condition was changed by the daemon, so state
+// of nodes is rolled up into status of ClusterConfigs immediately instead of on next resync. Failed configuration is
+// passed also when only its message or error class changes, e.g. when the retry fails differently, so ClusterConfigs
+// don't report a stale failure.
+var isConfiguredConditionChange = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNc, ok := e.ObjectOld.(*vrbv1.SriovVrbNodeConfig)
+		if !ok {
+			return false
+		}
+		newNc, ok := e.ObjectNew.(*vrbv1.SriovVrbNodeConfig)
+		if !ok {
+			return false
+		}
+		oldConfigured := meta.FindStatusCondition(oldNc.Status.Conditions, nodeConfiguredCondition)
+		newConfigured := meta.FindStatusCondition(newNc.Status.Conditions, nodeConfiguredCondition)
+		if oldConfigured == nil || newConfigured == nil {
+			return oldConfigured != newConfigured
+		}
+		if oldConfigured.Reason != newConfigured.Reason || oldConfigured.ObservedGeneration != newConfigured.ObservedGeneration {
+			return true
+		}
+		return isFailedConfiguration(newConfigured.Reason) &&
+			(oldConfigured.Message != newConfigured.Message || oldNc.Status.ErrorClass != newNc.Status.ErrorClass)
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isAcceleratedNodeChange passes creation and deletion of accelerated node and update of node which labels were
+// changed while it's accelerated before or after the change, so NodeConfig of the node joining the cluster or the scope
+// of the operator is rendered immediately instead of on next change of ClusterConfig
+var isAcceleratedNodeChange = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return isAcceleratedNode(e.Object)
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if labels.Equals(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+			return false
+		}
+		return isAcceleratedNode(e.ObjectOld) || isAcceleratedNode(e.ObjectNew)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return isAcceleratedNode(e.Object)
+	},
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// isAcceleratedNode returns true if node has labels of nodes with accelerators within the scope of the operator
+func isAcceleratedNode(node client.Object) bool {
+	matching, err := nodescope.AcceleratedNodes()
+	if err != nil {
+		return false
+	}
+	return labels.SelectorFromSet(labels.Set(matching)).Matches(labels.Set(node.GetLabels()))
+}
+
+func (r *SriovVrbClusterConfigReconciler) allClusterConfigs(ctx context.Context) handler.MapFunc {
+	return func(_ client.Object) []reconcile.Request {
+		clusterConfigs := new(vrbv1.SriovVrbClusterConfigList)
+		if err := r.List(ctx, clusterConfigs, client.InNamespace(NAMESPACE)); err != nil {
+			r.Log.WithError(err).Error("failed to list SriovVrbClusterConfigs")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, cc := range clusterConfigs.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cc)})
+		}
+		return requests
+	}
+}
+
 // key: accelerator pciAddress
 type NodeConfigurationCtx struct {
 	vrbv1.SriovVrbNodeConfig
@@ -241,7 +661,8 @@ func (pm *clusterConfigMatcher) prepareAcceleratorConfigContext(nodeConfig *vrbv
 					acceleratorConfigContext.Set(accelerator.PCIAddress, current)
 				case current.Spec.Priority == previous.Spec.Priority: //multiple configs with same priority; drop older one
 					//TODO: Update Timestamp would be better than CreationTime
-					if current.CreationTimestamp.After(previous.CreationTimestamp.Time) {
+					// configs are sorted, so the newer one or, if created at the same time, the one with greater name wins
+					if current.CreationTimestamp.After(previous.CreationTimestamp.Time) || current.CreationTimestamp.Equal(&previous.CreationTimestamp) {
 						pm.log.WithFields(logrus.Fields{
 							"Node":                  nodeConfig.Name,
 							"SriovVrbClusterConfig": previous.Name,
@@ -274,14 +695,53 @@ func matchConfigsForNode(node *corev1.Node, allConfigs []vrbv1.SriovVrbClusterCo
 		}
 	}
 
-	// Sort existing SriovFecCluster configurations based on CreationTimestamp to keep the order
+	// Sort existing ClusterConfigs by CreationTimestamp and name, so configs of the same priority are merged in the same
+	// order regardless of the order they are listed in
 	sort.Slice(nodeConfigs, func(i, j int) bool {
-		return allConfigs[i].CreationTimestamp.Before(&allConfigs[j].CreationTimestamp)
+		if !nodeConfigs[i].CreationTimestamp.Equal(&nodeConfigs[j].CreationTimestamp) {
+			return nodeConfigs[i].CreationTimestamp.Before(&nodeConfigs[j].CreationTimestamp)
+		}
+		return nodeConfigs[i].Name < nodeConfigs[j].Name
 	})
 
 	return
 }
 
+// overriddenAccelerators keeps accelerators selected by ClusterConfigs, which are configured by another ClusterConfig,
+// keyed by name of the overridden ClusterConfig
+type overriddenAccelerators map[string][]vrbv1.OverriddenAccelerator
+
+// record records accelerators of the node selected by ClusterConfigs other than the one they are configured by
+func (o overriddenAccelerators) record(node *corev1.Node, ncc NodeConfigurationCtx, allConfigs []vrbv1.SriovVrbClusterConfig) {
+	for _, cc := range matchConfigsForNode(node, allConfigs) {
+		for _, accelerator := range ncc.Status.Inventory.SriovAccelerators {
+			if !cc.Spec.AcceleratorSelector.Matches(accelerator) {
+				continue
+			}
+			if winner, ok := ncc.AcceleratorConfigContext.Get(accelerator.PCIAddress); ok && winner.Name != cc.Name {
+				o[cc.Name] = append(o[cc.Name], vrbv1.OverriddenAccelerator{
+					NodeName:     node.Name,
+					PCIAddress:   accelerator.PCIAddress,
+					OverriddenBy: winner.Name,
+				})
+			}
+		}
+	}
+}
+
+// of returns accelerators overridden for the ClusterConfig sorted by node and PCI address, so the status isn't updated
+// when nodes are listed in different order
+func (o overriddenAccelerators) of(name string) []vrbv1.OverriddenAccelerator {
+	accelerators := o[name]
+	sort.Slice(accelerators, func(i, j int) bool {
+		if accelerators[i].NodeName != accelerators[j].NodeName {
+			return accelerators[i].NodeName < accelerators[j].NodeName
+		}
+		return accelerators[i].PCIAddress < accelerators[j].PCIAddress
+	})
+	return accelerators
+}
+
 func setConfigurationPropagationConditionFailed(conditions *[]metav1.Condition, generation int64, msg string) {
 	meta.SetStatusCondition(conditions, metav1.Condition{
 		Type:               "ConfigurationPropagationCondition",
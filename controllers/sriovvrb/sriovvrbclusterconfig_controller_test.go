@@ -157,7 +157,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 		}
 
 		reconcile := func(ccName string) *SriovVrbClusterConfigReconciler {
-			reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+			reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 			_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest(ccName))
 			Expect(err).ToNot(HaveOccurred())
 			return &reconciler
@@ -167,6 +167,9 @@ var _ = Describe("SriovVrbControllerTest", func() {
 			ccl := new(vrbv1.SriovVrbClusterConfigList)
 			Expect(k8sClient.List(context.TODO(), ccl)).ToNot(HaveOccurred())
 			for _, item := range ccl.Items {
+				// deconfiguration isn't awaited, as there is no daemon in the test environment
+				item.Finalizers = nil
+				Expect(k8sClient.Update(context.TODO(), &item)).ToNot(HaveOccurred())
 				Expect(k8sClient.Delete(context.TODO(), &item)).ToNot(HaveOccurred())
 			}
 
@@ -417,7 +420,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 					}
 				})
 
-				reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("cc1"))
 				Expect(err).ToNot(HaveOccurred())
@@ -465,7 +468,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 					}
 				})
 
-				reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 				ccs := []string{"cc1", "cc2"}
 				for i := 0; i < 100; i++ {
 					cc := ccs[i%len(ccs)]
@@ -646,6 +649,91 @@ var _ = Describe("SriovVrbControllerTest", func() {
 
 		})
 
+		When("cc with higher priority requests accelerator to be absent", func() {
+			It("accelerator should be removed from matching nc", func() {
+				n1 := createNode("n1")
+
+				createNodeInventory(n1.Name, []vrbv1.SriovAccelerator{
+					{
+						PCIAddress: "0000:15:00.1",
+						VendorID:   "testvendor",
+						VFs:        []vrbv1.VF{},
+					},
+					{
+						PCIAddress: "0000:16:00.1",
+						VendorID:   "testvendor",
+						VFs:        []vrbv1.VF{},
+					},
+				})
+
+				_ = createAcceleratorConfig("config1", func(cc *vrbv1.SriovVrbClusterConfig) {
+					cc.Spec.AcceleratorSelector = vrbv1.AcceleratorSelector{
+						VendorID: "testvendor",
+					}
+					cc.Spec.PhysicalFunction = vrbv1.PhysicalFunctionConfig{
+						PFDriver: utils.PCI_PF_STUB_DASH,
+						VFDriver: "vfDriver",
+						VFAmount: 1,
+					}
+					cc.Spec.Priority = 1
+				})
+
+				_ = createAcceleratorConfig("teardown", func(cc *vrbv1.SriovVrbClusterConfig) {
+					cc.Spec.AcceleratorSelector = vrbv1.AcceleratorSelector{
+						PCIAddress: "0000:15:00.1",
+					}
+					cc.Spec.State = vrbv1.CardAbsent
+					cc.Spec.Priority = 2
+				})
+
+				_ = reconcile("teardown")
+
+				cl := new(vrbv1.SriovVrbNodeConfigList)
+				Expect(k8sClient.List(context.TODO(), cl)).ToNot(HaveOccurred())
+				Expect(cl.Items).To(HaveLen(1))
+				nc := cl.Items[0]
+				Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+				Expect(nc.Spec.PhysicalFunctions[0].PCIAddress).Should(Equal("0000:16:00.1"))
+			})
+		})
+
+		When("nc was recreated by the daemon as orphaned", func() {
+			It("cc.spec should be propagated and orphaned annotation removed", func() {
+				n1 := createNode("n1")
+
+				createNodeInventory(n1.Name, []vrbv1.SriovAccelerator{
+					{
+						PCIAddress: "0000:15:00.1",
+						VendorID:   "testvendor",
+						VFs:        []vrbv1.VF{{PCIAddress: "0000:15:00.2"}},
+					},
+				})
+
+				nc := new(vrbv1.SriovVrbNodeConfig)
+				Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: n1.Name, Namespace: NAMESPACE}, nc)).ToNot(HaveOccurred())
+				nc.Annotations = map[string]string{vrbv1.OrphanedAnnotation: "true"}
+				Expect(k8sClient.Update(context.TODO(), nc)).ToNot(HaveOccurred())
+
+				_ = createAcceleratorConfig("config", func(cc *vrbv1.SriovVrbClusterConfig) {
+					cc.Spec.AcceleratorSelector = vrbv1.AcceleratorSelector{
+						VendorID: "testvendor",
+					}
+					cc.Spec.PhysicalFunction = vrbv1.PhysicalFunctionConfig{
+						PFDriver: utils.PCI_PF_STUB_DASH,
+						VFDriver: "vfDriver",
+						VFAmount: 1,
+					}
+				})
+
+				_ = reconcile("config")
+
+				nc = new(vrbv1.SriovVrbNodeConfig)
+				Expect(k8sClient.Get(context.TODO(), client.ObjectKey{Name: n1.Name, Namespace: NAMESPACE}, nc)).ToNot(HaveOccurred())
+				Expect(nc.IsOrphaned()).To(BeFalse())
+				Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+			})
+		})
+
 		When("cc has no node selector", func() {
 			It("cc.spec should be propagated to all nodes having matching accelerator", func() {
 				n1 := createNode("n1")
@@ -678,7 +766,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 					}
 				})
 
-				reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("cc"))
 				Expect(err).ToNot(HaveOccurred())
 
@@ -714,7 +802,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 						}
 					})
 
-					reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+					reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 					_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("config"))
 					Expect(err).ToNot(HaveOccurred())
 
@@ -769,7 +857,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 						}
 					})
 
-					reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+					reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 					_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("config"))
 					Expect(err).ToNot(HaveOccurred())
 
@@ -819,7 +907,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 					cc.Spec.DrainSkip = &tmp
 				})
 
-				reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest("config"))
 				Expect(err).ToNot(HaveOccurred())
 
@@ -838,7 +926,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 				cc.Namespace = v1.NamespaceSystem
 				Expect(k8sClient.Create(context.TODO(), cc)).ToNot(HaveOccurred())
 
-				reconciler := SriovVrbClusterConfigReconciler{k8sClient, log}
+				reconciler := SriovVrbClusterConfigReconciler{Client: k8sClient, Log: log}
 				_, err := reconciler.Reconcile(context.TODO(), createDummyReconcileRequest(clusterConfigPrototype.Name))
 				Expect(err).ToNot(HaveOccurred())
 
@@ -853,7 +941,7 @@ var _ = Describe("SriovVrbControllerTest", func() {
 		var _ = It("setup with invalid manager", func() {
 			var m ctrl.Manager
 			var reconciler SriovVrbClusterConfigReconciler
-			err := reconciler.SetupWithManager(m)
+			err := reconciler.SetupWithManager(context.TODO(), m)
 			Expect(err).To(HaveOccurred())
 		})
 	})
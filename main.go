@@ -30,16 +30,25 @@ import (
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/intel/sriov-fec-operator/pkg/common/assets"
+	"github.com/intel/sriov-fec-operator/pkg/common/backoff"
 	"github.com/intel/sriov-fec-operator/pkg/common/drainhelper"
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
+	"github.com/intel/sriov-fec-operator/pkg/common/migration"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+	"github.com/intel/sriov-fec-operator/pkg/common/notification"
+	"github.com/intel/sriov-fec-operator/pkg/common/upgradeguard"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 
+	configv1 "github.com/openshift/api/config/v1"
 	secv1 "github.com/openshift/api/security/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -47,6 +56,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	sriovfecv1 "github.com/intel/sriov-fec-operator/api/sriovfec/v1"
 	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
 	sriovvrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
 	controllers "github.com/intel/sriov-fec-operator/controllers/sriovfec"
@@ -54,6 +64,9 @@ import (
 	// +kubebuilder:scaffold:imports
 )
 
+// webhookCertDir is where the serving certificate's secret is mounted, see config/default/manager_webhook_patch.yaml
+const webhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = utils.NewLogger()
@@ -63,6 +76,10 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(secv1.AddToScheme(scheme))
+	utilruntime.Must(configv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	// v1 objects are converted to v2 by the conversion webhook
+	utilruntime.Must(sriovfecv1.AddToScheme(scheme))
 	utilruntime.Must(sriovfecv2.AddToScheme(scheme))
 
 	utilruntime.Must(sriovvrbv1.AddToScheme(scheme))
@@ -70,34 +87,89 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == matchCommand {
+		os.Exit(runMatch(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == simulateCommand {
+		os.Exit(runSimulate(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	var metricsAddr string
 	var healthProbeAddr string
 	var enableLeaderElection bool
+	var leaderElectionID string
+	var leaderElectionNamespace string
+	var webhookCertSecret string
+	var certRotationThreshold time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionID, "leader-election-id", defaultLeaderElectionID,
+		"Name of the Lease held by the active controller manager. Installations in different namespaces sharing the lease namespace need different IDs.")
+	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "",
+		"Namespace of the Lease held by the active controller manager, the namespace of the operator if empty.")
 	flag.StringVar(&healthProbeAddr, "health-probe-bind-address", ":8081", "The address the controller binds to for serving health probes.")
+	flag.StringVar(&webhookCertSecret, "webhook-cert-secret", "webhook-server-cert", "The secret holding certificate serving webhooks.")
+	flag.DurationVar(&certRotationThreshold, "cert-rotation-threshold", controllers.DefaultCertificateRotationThreshold,
+		"Time before expiry of the serving certificate, when it is reported as expiring and rotated.")
 	flag.Parse()
 
 	ctrl.SetLogger(logr.New(utils.NewLogWrapper()))
+	// cancelled on SIGTERM, so the startup is interrupted as well
+	ctx := ctrl.SetupSignalHandler()
+
+	if err := featuregates.SetFromEnv(); err != nil {
+		setupLog.WithError(err).Error("invalid feature gates")
+		os.Exit(1)
+	}
+	setupLog.WithField("featureGates", featuregates.String()).Info("feature gates")
+	nodeScope, err := nodescope.Labels()
+	if err != nil {
+		setupLog.WithError(err).Error("invalid node scope")
+		os.Exit(1)
+	}
+	setupLog.WithField("nodeScope", nodeScope.String()).Info("nodes managed by the operator")
+	maxBackoff, err := backoff.CapFromEnv()
+	if err != nil {
+		setupLog.WithError(err).Error("invalid maximum backoff")
+		os.Exit(1)
+	}
 
 	config := ctrl.GetConfigOrDie()
-	mgr := createAndConfigureManager(config, metricsAddr, healthProbeAddr, enableLeaderElection)
+	if err := utils.SetClientRateLimits(config); err != nil {
+		setupLog.WithError(err).Error("invalid client rate limits")
+		os.Exit(1)
+	}
+	mgr := createAndConfigureManager(ctx, config, metricsAddr, healthProbeAddr, leaderElection{
+		enabled:   enableLeaderElection,
+		id:        leaderElectionID,
+		namespace: leaderElectionNamespace,
+	})
 
-	initializeSriovFecClusterConfigReconciler(mgr)
-	initializeVrbClusterConfigReconciler(mgr)
+	guard := initializeUpgradeGuard(mgr)
+	// sinks are read only when a notification is sent, so the Secret doesn't have to be cached by the manager
+	notifier := notification.NewNotifier(mgr.GetAPIReader(), controllers.NAMESPACE, utils.NewLogger())
+	if err := mgr.Add(notifier); err != nil {
+		setupLog.WithError(err).Error("unable to add notifier to the manager")
+		os.Exit(1)
+	}
+	initializeSriovFecClusterConfigReconciler(ctx, mgr, guard, notifier, maxBackoff)
+	initializeVrbClusterConfigReconciler(ctx, mgr, guard, notifier, maxBackoff)
+	initializeCertificateMonitor(mgr, webhookCertSecret, certRotationThreshold)
 	// +kubebuilder:scaffold:builder
 
 	c := createClient(config)
 
-	operatorDeployment := assets.FetchOperatorDeployment(c, setupLog)
+	operatorDeployment := assets.FetchOperatorDeployment(ctx, c, setupLog)
 
 	determineClusterType(config)
 
-	deployOperatorAssets(c, operatorDeployment)
+	initializeStorageVersionMigration(mgr, c)
+
+	deployOperatorAssets(ctx, c, operatorDeployment)
 
-	isSingleNode, err := utils.IsSingleNodeCluster(c)
+	isSingleNode, err := utils.IsSingleNodeCluster(ctx, c)
 	if err != nil {
 		setupLog.WithError(err).Error("failed to get Nodes information")
 		os.Exit(1)
@@ -105,20 +177,20 @@ func main() {
 
 	if !isSingleNode {
 		*operatorDeployment.Spec.Replicas = 2
-		err := c.Update(context.TODO(), operatorDeployment)
+		err := c.Update(ctx, operatorDeployment)
 		if err != nil {
 			setupLog.WithError(err).Error("failed to scale down number of replicas. Ignoring error.")
 		}
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.WithError(err).Error("problem running manager")
 		os.Exit(1)
 	}
 }
 
-func deployOperatorAssets(c client.Client, operatorDeployment *appsv1.Deployment) {
+func deployOperatorAssets(ctx context.Context, c client.Client, operatorDeployment *appsv1.Deployment) {
 	logger := utils.NewLogger()
 	assetsManager := &assets.Manager{
 		Client:    c,
@@ -144,17 +216,48 @@ func deployOperatorAssets(c client.Client, operatorDeployment *appsv1.Deployment
 		},
 	}
 
-	if err := assetsManager.DeployConfigMaps(context.Background(), false); err != nil {
+	if err := assetsManager.DeployConfigMaps(ctx, false); err != nil {
 		setupLog.WithError(err).Error("failed to deploy the assets")
 		os.Exit(1)
 	}
 
-	if err := assetsManager.LoadFromConfigMapAndDeploy(context.Background()); err != nil {
+	if err := assetsManager.LoadFromConfigMapAndDeploy(ctx); err != nil {
 		setupLog.WithError(err).Error("failed to deploy the assets")
 		os.Exit(1)
 	}
 }
 
+// initializeStorageVersionMigration rewrites stored SriovFec objects to v2 once the manager serves the conversion webhook
+func initializeStorageVersionMigration(mgr manager.Manager, c client.Client) {
+	log := utils.NewLogger()
+	job := &migration.Job{
+		Migrator: &migration.StorageVersionMigrator{
+			Client: c,
+			Log:    log,
+		},
+		Resources: []migration.Resource{
+			{
+				CRDName:        "sriovfecclusterconfigs.sriovfec.intel.com",
+				StorageVersion: sriovfecv2.GroupVersion.Version,
+				NewList:        func() client.ObjectList { return new(sriovfecv2.SriovFecClusterConfigList) },
+			},
+			{
+				CRDName:        "sriovfecnodeconfigs.sriovfec.intel.com",
+				StorageVersion: sriovfecv2.GroupVersion.Version,
+				NewList:        func() client.ObjectList { return new(sriovfecv2.SriovFecNodeConfigList) },
+			},
+		},
+		AfterMigration: func(ctx context.Context) error {
+			return controllers.SplitConvertedClusterConfigs(ctx, c, log)
+		},
+		Interval: migration.DefaultJobInterval,
+	}
+	if err := mgr.Add(job); err != nil {
+		setupLog.WithError(err).Error("unable to add storage version migration")
+		os.Exit(1)
+	}
+}
+
 func determineClusterType(config *rest.Config) {
 	if err := getClusterType(config); err != nil {
 		setupLog.Error(err, "unable to determine cluster type")
@@ -171,38 +274,163 @@ func createClient(config *rest.Config) client.Client {
 	return c
 }
 
-func initializeSriovFecClusterConfigReconciler(mgr manager.Manager) {
+func initializeSriovFecClusterConfigReconciler(ctx context.Context, mgr manager.Manager, guard *upgradeguard.Guard, notifier *notification.Notifier, maxBackoff time.Duration) {
 	log := utils.NewLogger()
 	if err := (&controllers.SriovFecClusterConfigReconciler{
+		Client:       mgr.GetClient(),
+		Log:          log,
+		Recorder:     mgr.GetEventRecorderFor("sriov-fec-operator"),
+		UpgradeGuard: guard,
+		Notifier:     notifier,
+		Backoff:      backoff.New(maxBackoff),
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.WithField("controller", "SriovFecClusterConfig").WithError(err).Error("unable to create controller")
+		os.Exit(1)
+	}
+	if err := (&controllers.SuggestedConfigReconciler{
 		Client: mgr.GetClient(),
 		Log:    log,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.WithField("controller", "SriovFecClusterConfig").WithError(err).Error("unable to create controller")
+		setupLog.WithField("controller", "SuggestedConfig").WithError(err).Error("unable to create controller")
+		os.Exit(1)
+	}
+	if err := (&controllers.VFMappingReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       log,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.WithField("controller", "VFMapping").WithError(err).Error("unable to create controller")
 		os.Exit(1)
 	}
+	if err := (&controllers.BackupReconciler{
+		Client: mgr.GetClient(),
+		Log:    log,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.WithField("controller", "Backup").WithError(err).Error("unable to create controller")
+		os.Exit(1)
+	}
+	if err := (&controllers.VfioTokenReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       log,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.WithField("controller", "VfioToken").WithError(err).Error("unable to create controller")
+		os.Exit(1)
+	}
+	if err := (&controllers.FecPoolReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       log,
+	}).SetupWithManager(ctx, mgr); err != nil {
+		setupLog.WithField("controller", "FecPool").WithError(err).Error("unable to create controller")
+		os.Exit(1)
+	}
+	(&controllers.FecPoolPodValidator{Log: log}).SetupWebhookWithManager(mgr)
 	if err := (&sriovfecv2.SriovFecClusterConfig{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.WithError(err).WithField("webhook", "SriovFecClusterConfig").Error("unable to create webhook")
 		os.Exit(1)
 	}
+	if err := (&sriovfecv2.SriovFecNodeConfig{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.WithError(err).WithField("webhook", "SriovFecNodeConfig").Error("unable to create webhook")
+		os.Exit(1)
+	}
+	if err := (&sriovfecv2.SriovFecProfile{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.WithError(err).WithField("webhook", "SriovFecProfile").Error("unable to create webhook")
+		os.Exit(1)
+	}
 }
 
-func initializeVrbClusterConfigReconciler(mgr manager.Manager) {
+func initializeVrbClusterConfigReconciler(ctx context.Context, mgr manager.Manager, guard *upgradeguard.Guard, notifier *notification.Notifier, maxBackoff time.Duration) {
 	log := utils.NewLogger()
 	if err := (&vrbcontrollers.SriovVrbClusterConfigReconciler{
-		Client: mgr.GetClient(),
-		Log:    log,
-	}).SetupWithManager(mgr); err != nil {
+		Client:       mgr.GetClient(),
+		Log:          log,
+		Recorder:     mgr.GetEventRecorderFor("sriov-fec-operator"),
+		UpgradeGuard: guard,
+		Notifier:     notifier,
+		Backoff:      backoff.New(maxBackoff),
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.WithField("controller", "SriovVrbClusterConfig").WithError(err).Error("unable to create controller")
 		os.Exit(1)
 	}
+	if err := (&vrbcontrollers.VFMappingReconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       log,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.WithField("controller", "VrbVFMapping").WithError(err).Error("unable to create controller")
+		os.Exit(1)
+	}
 	if err := (&sriovvrbv1.SriovVrbClusterConfig{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.WithError(err).WithField("webhook", "SriovVrbClusterConfig").Error("unable to create webhook")
 		os.Exit(1)
 	}
+	if err := (&sriovvrbv1.SriovVrbNodeConfig{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.WithError(err).WithField("webhook", "SriovVrbNodeConfig").Error("unable to create webhook")
+		os.Exit(1)
+	}
+}
+
+// initializeUpgradeGuard starts checking ClusterVersion, so disruptive reconfigurations are deferred during cluster upgrades
+func initializeUpgradeGuard(mgr manager.Manager) *upgradeguard.Guard {
+	guard := &upgradeguard.Guard{
+		Reader:   mgr.GetAPIReader(),
+		Log:      utils.NewLogger(),
+		Interval: upgradeguard.DefaultInterval,
+	}
+	if err := mgr.Add(guard); err != nil {
+		setupLog.WithError(err).Error("unable to add upgrade guard")
+		os.Exit(1)
+	}
+	// version of the cluster is read by webhooks of each replica, not only by the leader running the guard
+	if err := mgr.Add(&upgradeguard.VersionRecorder{
+		Reader:   mgr.GetAPIReader(),
+		Log:      utils.NewLogger(),
+		Interval: upgradeguard.DefaultInterval,
+	}); err != nil {
+		setupLog.WithError(err).Error("unable to add recorder of the cluster version")
+		os.Exit(1)
+	}
+	return guard
 }
 
-func createAndConfigureManager(config *rest.Config, metricsAddr string, healthProbeAddr string, enableLeaderElection bool) manager.Manager {
+func initializeCertificateMonitor(mgr manager.Manager, secretName string, rotationThreshold time.Duration) {
+	if err := mgr.Add(&controllers.CertificateMonitor{
+		Client:            mgr.GetClient(),
+		APIReader:         mgr.GetAPIReader(),
+		Log:               utils.NewLogger(),
+		Recorder:          mgr.GetEventRecorderFor("sriov-fec-operator"),
+		CertDir:           webhookCertDir,
+		SecretName:        secretName,
+		Namespace:         controllers.NAMESPACE,
+		RotationThreshold: rotationThreshold,
+		Interval:          controllers.DefaultCertificateCheckInterval,
+	}); err != nil {
+		setupLog.WithError(err).Error("unable to add certificate monitor")
+		os.Exit(1)
+	}
+}
+
+// defaultLeaderElectionID is the name of the Lease used by previous versions of the operator, so replicas of different
+// versions don't become active at the same time during the upgrade
+const defaultLeaderElectionID = "98e78623.intel.com"
+
+// leaderElection configures election of the active replica of the operator; other replicas serve webhooks only
+type leaderElection struct {
+	enabled   bool
+	id        string
+	namespace string
+}
+
+func createAndConfigureManager(ctx context.Context, config *rest.Config, metricsAddr string, healthProbeAddr string, election leaderElection) manager.Manager {
+	webhookHost, err := utils.BindHost()
+	if err != nil {
+		setupLog.WithError(err).Error("invalid bind address")
+		os.Exit(1)
+	}
 	ws := webhook.Server{
+		Host:          webhookHost,
+		CertDir:       webhookCertDir,
 		TLSMinVersion: "1.2",
 		TLSOpts: []func(*tls.Config){
 			func(cfg *tls.Config) {
@@ -222,7 +450,7 @@ func createAndConfigureManager(config *rest.Config, metricsAddr string, healthPr
 		os.Exit(1)
 	}
 
-	isSingleNodeCluster, err := utils.IsSingleNodeCluster(directClient)
+	isSingleNodeCluster, err := utils.IsSingleNodeCluster(ctx, directClient)
 	if err != nil {
 		setupLog.WithError(err).Errorf("failed to determine cluster type")
 		os.Exit(1)
@@ -232,19 +460,29 @@ func createAndConfigureManager(config *rest.Config, metricsAddr string, healthPr
 		drainhelper.LeaseDurationDefault,
 		isSingleNodeCluster)
 
+	workers, err := utils.MaxConcurrentReconciles()
+	if err != nil {
+		setupLog.WithError(err).Error("invalid number of concurrent reconciles")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:                        scheme,
 		MetricsBindAddress:            metricsAddr,
 		HealthProbeBindAddress:        healthProbeAddr,
 		Port:                          9443,
-		LeaderElection:                enableLeaderElection,
-		LeaderElectionID:              "98e78623.intel.com",
+		LeaderElection:                election.enabled,
+		LeaderElectionID:              election.id,
+		LeaderElectionNamespace:       election.namespace,
 		LeaseDuration:                 &LeaderElectionConfig.LeaseDuration,
 		RenewDeadline:                 &LeaderElectionConfig.RenewDeadline,
 		RetryPeriod:                   &LeaderElectionConfig.RetryPeriod,
 		Namespace:                     controllers.NAMESPACE,
 		WebhookServer:                 &ws,
 		LeaderElectionReleaseOnCancel: true,
+		Controller: v1alpha1.ControllerConfigurationSpec{
+			GroupKindConcurrency: concurrentReconciles(workers),
+		},
 	})
 	if err != nil {
 		setupLog.WithError(err).Error("unable to start manager")
@@ -298,3 +536,16 @@ func getClusterType(restConfig *rest.Config) error {
 
 	return nil
 }
+
+// concurrentReconciles returns workers of controllers by kinds they reconcile. Only controllers reconciling each object
+// on its own run concurrently; ClusterConfig controllers reconcile all ClusterConfigs on every request, so their workers
+// would render the same NodeConfigs concurrently.
+func concurrentReconciles(workers int) map[string]int {
+	return map[string]int{
+		"SriovFecNodeConfig." + sriovfecv2.GroupVersion.Group: workers,
+		"SriovVrbNodeConfig." + sriovvrbv1.GroupVersion.Group: workers,
+		"FecPool." + sriovfecv2.GroupVersion.Group:            workers,
+		"ConfigMap": workers,
+		"Namespace": workers,
+	}
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	sriovvrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	controllers "github.com/intel/sriov-fec-operator/controllers/sriovfec"
+	vrbcontrollers "github.com/intel/sriov-fec-operator/controllers/sriovvrb"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// matchCommand is a subcommand of the manager printing accelerators selected by a ClusterConfig in the live cluster
+const matchCommand = "match"
+
+// runMatch prints nodes and accelerators selected by SriovFecClusterConfig or SriovVrbClusterConfig read from a file,
+// as if it was applied along with existing ClusterConfigs; it returns exit code of the command
+func runMatch(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(matchCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "File with SriovFecClusterConfig or SriovVrbClusterConfig, - reads it from stdin")
+	namespace := flags.String("n", "", "Namespace of the operator, defaults to namespace of the config or SRIOV_FEC_NAMESPACE")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: manager %s -f <file> [-n <namespace>]\n", matchCommand)
+		fmt.Fprintln(stderr, "Prints nodes and accelerators the ClusterConfig would select in the cluster, nothing is changed in the cluster.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		flags.Usage()
+		return 2
+	}
+
+	var content []byte
+	var err error
+	if *file == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read %s: %v\n", *file, err)
+		return 1
+	}
+
+	object := metav1.PartialObjectMetadata{}
+	if err := yaml.Unmarshal(content, &object); err != nil {
+		fmt.Fprintf(stderr, "failed to parse %s: %v\n", *file, err)
+		return 1
+	}
+
+	ns := firstNonEmpty(*namespace, object.Namespace, os.Getenv("SRIOV_FEC_NAMESPACE"))
+	if ns == "" {
+		fmt.Fprintln(stderr, "namespace of the operator is not set, use -n flag")
+		return 2
+	}
+	controllers.NAMESPACE = ns
+	vrbcontrollers.NAMESPACE = ns
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to get kubeconfig: %v\n", err)
+		return 1
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to create client: %v\n", err)
+		return 1
+	}
+
+	log := utils.NewLogger()
+	log.SetLevel(logrus.WarnLevel)
+
+	var matches []controllers.SelectorMatch
+	switch object.Kind {
+	case "SriovFecClusterConfig":
+		cc := sriovfecv2.SriovFecClusterConfig{}
+		if err = yaml.UnmarshalStrict(content, &cc); err == nil {
+			matches, err = controllers.MatchClusterConfig(context.Background(), c, cc, log)
+		}
+	case "SriovVrbClusterConfig":
+		cc := sriovvrbv1.SriovVrbClusterConfig{}
+		if err = yaml.UnmarshalStrict(content, &cc); err == nil {
+			var vrbMatches []vrbcontrollers.SelectorMatch
+			vrbMatches, err = vrbcontrollers.MatchClusterConfig(context.Background(), c, cc, log)
+			for _, m := range vrbMatches {
+				matches = append(matches, controllers.SelectorMatch(m))
+			}
+		}
+	default:
+		fmt.Fprintf(stderr, "unsupported kind %q, expected SriovFecClusterConfig or SriovVrbClusterConfig\n", object.Kind)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to match %s: %v\n", object.Name, err)
+		return 1
+	}
+
+	printMatches(stdout, object.Name, matches)
+	return 0
+}
+
+func printMatches(out io.Writer, name string, matches []controllers.SelectorMatch) {
+	if len(matches) == 0 {
+		fmt.Fprintf(out, "no accelerator is selected by %s\n", name)
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tPCI ADDRESS\tDEVICE ID\tCONFIGURED BY")
+	for _, m := range matches {
+		configuredBy := m.ConfiguredBy
+		if configuredBy != name {
+			configuredBy += " (overrides " + name + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.NodeName, m.PCIAddress, m.DeviceID, configuredBy)
+	}
+	w.Flush()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
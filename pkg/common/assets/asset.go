@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	"github.com/sirupsen/logrus"
 	"os"
 	"path/filepath"
@@ -89,7 +91,7 @@ func (a *Asset) loadFromFile() error {
 		return err
 	}
 
-	t, err := template.New("asset").Funcs(template.FuncMap{"ToLower": strings.ToLower}).Option("missingkey=error").Parse(string(content))
+	t, err := template.New("asset").Funcs(template.FuncMap{"ToLower": strings.ToLower, "NodeScope": nodescope.Parse}).Option("missingkey=error").Parse(string(content))
 	if err != nil {
 		return err
 	}
@@ -179,6 +181,7 @@ func (a *Asset) createOrUpdateObject(ctx context.Context, c client.Client, toBeC
 	if err != nil {
 		return err
 	}
+	utils.SetStandardLabels(toBeCreated, toBeCreated.GetName(), owner.GetName())
 
 	gvk := toBeCreated.GetObjectKind().GroupVersionKind()
 	old := &unstructured.Unstructured{}
@@ -186,7 +189,7 @@ func (a *Asset) createOrUpdateObject(ctx context.Context, c client.Client, toBeC
 	key := client.ObjectKeyFromObject(toBeCreated)
 
 	if strings.EqualFold(gvk.Kind, "daemonset") {
-		toBeCreated, err = propagateTolerations(c, a.log, toBeCreated)
+		toBeCreated, err = propagateTolerations(ctx, c, a.log, toBeCreated)
 		if err != nil {
 			return err
 		}
@@ -209,10 +212,22 @@ func (a *Asset) createOrUpdateObject(ctx context.Context, c client.Client, toBeC
 				return nil
 			}
 		}
+		if strings.EqualFold(gvk.Kind, "priorityclass") && !equality.Semantic.DeepDerivative(toBeCreated, old) {
+			return a.recreateObject(ctx, c, toBeCreated, old, key, gvk)
+		}
 		return a.updateObject(ctx, c, toBeCreated, old, key, gvk)
 	}
 }
 
+// recreateObject replaces an object which fields can't be updated, e.g. value of PriorityClass
+func (a *Asset) recreateObject(ctx context.Context, c client.Client, toBeCreated, old client.Object, key client.ObjectKey, gvk schema.GroupVersionKind) error {
+	if err := c.Delete(ctx, old); err != nil && !apierr.IsNotFound(err) {
+		a.log.WithError(err).WithField("key", key).WithField("GroupVersionKind", gvk).Error("Delete failed")
+		return err
+	}
+	return a.createObject(ctx, c, toBeCreated, key, gvk)
+}
+
 func (a *Asset) createObject(ctx context.Context, c client.Client, toBeCreated client.Object, key client.ObjectKey, gvk schema.GroupVersionKind) error {
 	if err := c.Create(ctx, toBeCreated); err != nil {
 		a.log.WithError(err).WithField("key", key).WithField("GroupVersionKind", gvk).Error("Create failed")
@@ -236,8 +251,8 @@ func (a *Asset) updateObject(ctx context.Context, c client.Client, toBeCreated,
 	return nil
 }
 
-func propagateTolerations(c client.Client, log *logrus.Logger, toBeCreated client.Object) (client.Object, error) {
-	managerDeployment := FetchOperatorDeployment(c, log)
+func propagateTolerations(ctx context.Context, c client.Client, log *logrus.Logger, toBeCreated client.Object) (client.Object, error) {
+	managerDeployment := FetchOperatorDeployment(ctx, c, log)
 	log.WithField("name", toBeCreated.GetName()).WithField("tolerations", managerDeployment.Spec.Template.Spec.Tolerations).
 		Info("propagating tolerations to daemonset")
 	uns, err := runtime.DefaultUnstructuredConverter.ToUnstructured(toBeCreated)
@@ -250,6 +265,12 @@ func propagateTolerations(c client.Client, log *logrus.Logger, toBeCreated clien
 		return nil, err
 	}
 	ds.Spec.Template.Spec.Tolerations = managerDeployment.Spec.Template.Spec.Tolerations
+	// operator's pods configure accelerators, so they have to run on nodes tainted until accelerators are configured
+	ds.Spec.Template.Spec.Tolerations = append(ds.Spec.Template.Spec.Tolerations, corev1.Toleration{
+		Key:      utils.UNCONFIGURED_TAINT_KEY,
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	})
 	return ds, nil
 }
 
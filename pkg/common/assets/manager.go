@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/intel/sriov-fec-operator/pkg/common/nodescope"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	"github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
@@ -45,7 +46,7 @@ func (m *Manager) buildTemplateVars(ctx context.Context, setKernelVar bool) (map
 	tp := make(map[string]string)
 
 	for _, pair := range os.Environ() {
-		kv := strings.Split(pair, "=")
+		kv := strings.SplitN(pair, "=", 2)
 		if len(kv) == 2 && strings.HasPrefix(kv[0], m.EnvPrefix) {
 			tp[kv[0]] = kv[1]
 		}
@@ -55,12 +56,20 @@ func (m *Manager) buildTemplateVars(ctx context.Context, setKernelVar bool) (map
 	if err := m.validateUUID(tp); err != nil {
 		return tp, err
 	}
+	if _, err := nodescope.Parse(tp[nodescope.EnvName]); err != nil {
+		return tp, err
+	}
+	tp[m.EnvPrefix+"CLUSTER_RESOURCE_SUFFIX"] = nodescope.ClusterResourceSuffix(tp[nodescope.EnvName], m.Namespace)
 	if !setKernelVar {
 		return tp, nil
 	}
 
 	nodes := &corev1.NodeList{}
-	err := m.Client.List(ctx, nodes, &client.MatchingLabels{"fpga.intel.com/intel-accelerator-present": ""})
+	labelsToMatch, err := nodescope.AcceleratedNodes()
+	if err != nil {
+		return nil, err
+	}
+	err = m.Client.List(ctx, nodes, labelsToMatch)
 	if err != nil {
 		return nil, err
 	}
@@ -92,6 +101,32 @@ func (m *Manager) setDefaultValues(tp *map[string]string) {
 		m.EnvPrefix + "ACC100_RESOURCE_NAME": "intel_fec_acc100",
 		m.EnvPrefix + "ACC200_RESOURCE_NAME": "intel_fec_acc200",
 		"SRIOV_VRB_VRB2_RESOURCE_NAME":       "intel_vrb_vrb2",
+		// pf_bb_config limits are not applied unless configured
+		m.EnvPrefix + "PF_BB_CONFIG_WORKDIR":      "",
+		m.EnvPrefix + "PF_BB_CONFIG_NICE":         "",
+		m.EnvPrefix + "PF_BB_CONFIG_CPUSET":       "",
+		m.EnvPrefix + "PF_BB_CONFIG_MEMORY_LIMIT": "",
+		// the highest priority of user-defined PriorityClasses
+		m.EnvPrefix + "DAEMON_PRIORITY": "1000000000",
+		// nodes are not tainted until accelerators are configured unless enabled
+		m.EnvPrefix + "TAINT_UNCONFIGURED_NODES": "false",
+		// features keep their default state unless listed
+		m.EnvPrefix + "FEATURE_GATES": "",
+		// configuration of a single PF is aborted when it exceeds the timeout, drain excluded
+		m.EnvPrefix + "PF_CONFIGURATION_TIMEOUT": "5m",
+		// device plugin not advertising configured VFs is only reported unless enabled
+		m.EnvPrefix + "RESTART_UNHEALTHY_DEVICE_PLUGIN": "false",
+		// telemetry is exposed to Prometheus only unless the endpoint is configured
+		m.EnvPrefix + "TELEMETRY_PUSH_ENDPOINT": "",
+		m.EnvPrefix + "TELEMETRY_PUSH_INTERVAL": "1m",
+		// listeners bind all addresses of both IP families unless restricted
+		m.EnvPrefix + "BIND_ADDRESS": "",
+		// commands operating on the host run in the daemon's container unless chroot or nsenter is configured
+		m.EnvPrefix + "HOST_COMMANDER": "container",
+		// DaemonSets run on all nodes with accelerators unless the operator is restricted to a scope of nodes
+		m.EnvPrefix + "NODE_SCOPE": "",
+		// drain fails when PodDisruptionBudgets don't allow evictions within the drain deadline unless set to Delete
+		m.EnvPrefix + "DRAIN_STUCK_EVICTION_POLICY": "Fail",
 	}
 
 	for key, value := range defaults {
@@ -201,13 +236,13 @@ func (m *Manager) Deploy(ctx context.Context) error {
 	return nil
 }
 
-func FetchOperatorDeployment(c client.Client, log *logrus.Logger) *appsv1.Deployment {
+func FetchOperatorDeployment(ctx context.Context, c client.Client, log *logrus.Logger) *appsv1.Deployment {
 	n := os.Getenv("NAME")
 	operatorDeploymentName := n[:strings.LastIndex(n[:strings.LastIndex(n, "-")], "-")]
 
 	namespace := os.Getenv("SRIOV_FEC_NAMESPACE")
 	owner := &appsv1.Deployment{}
-	err := c.Get(context.Background(), client.ObjectKey{
+	err := c.Get(ctx, client.ObjectKey{
 		Namespace: namespace,
 		Name:      operatorDeploymentName,
 	}, owner)
@@ -386,7 +386,7 @@ var _ = Describe("Asset Tests", func() {
 			}
 			Expect(ds.Spec.Template.Spec.Tolerations).To(BeEmpty())
 
-			newObj, err := propagateTolerations(k8sClient, log, ds)
+			newObj, err := propagateTolerations(context.TODO(), k8sClient, log, ds)
 
 			Expect(err).To(Succeed())
 			uns, err := runtime.DefaultUnstructuredConverter.ToUnstructured(newObj)
@@ -400,6 +400,11 @@ var _ = Describe("Asset Tests", func() {
 			Expect(toleration.Key).To(Equal(tolerationKey))
 			Expect(toleration.Effect).To(Equal(tolerationEffect))
 			Expect(toleration.Operator).To(Equal(tolerationOperator))
+			Expect(newDs.Spec.Template.Spec.Tolerations).To(ContainElement(corev1.Toleration{
+				Key:      utils.UNCONFIGURED_TAINT_KEY,
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}))
 		})
 	})
 })
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Interval is a period manual modifications reverted by the operator are reported for
+const Interval = time.Hour
+
+var (
+	revertedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sriov_fec_manual_modifications_reverted_total",
+		Help: "Number of manual modifications of objects managed by the operator which were reverted, by kind of the object",
+	}, []string{"kind"})
+	detectedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sriov_fec_manual_modifications_detected",
+		Help: "Set to 1 when a manual modification of objects managed by the operator was reverted in the last audit interval",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(revertedCounter, detectedGauge)
+}
+
+// Checksum returns checksum of the spec, which is recorded on objects written by the operator
+func Checksum(spec interface{}) string {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	checksum := sha256.Sum256(raw)
+	return hex.EncodeToString(checksum[:])
+}
+
+// IsModified returns true if the spec differs from the one the operator recorded with its checksum; objects without
+// recorded checksum, e.g. created before auditing, are never reported as modified
+func IsModified(recorded string, spec interface{}) bool {
+	return recorded != "" && recorded != Checksum(spec)
+}
+
+// Reverts keeps manual modifications reverted by the operator in the last Interval, keyed by name of the config
+// responsible for reverted objects. Zero value is ready to use.
+type Reverts struct {
+	mu      sync.Mutex
+	configs map[string]revert
+}
+
+type revert struct {
+	last  time.Time
+	nodes map[string]bool
+}
+
+// Record records that the manual modification of the node's object of given kind was reverted on behalf of the configs
+func (r *Reverts) Record(kind, node string, configs []string, now time.Time) {
+	revertedCounter.WithLabelValues(kind).Inc()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.configs == nil {
+		r.configs = map[string]revert{}
+	}
+	for _, config := range configs {
+		current, ok := r.configs[config]
+		if !ok || now.Sub(current.last) > Interval {
+			current = revert{nodes: map[string]bool{}}
+		}
+		current.last = now
+		current.nodes[node] = true
+		r.configs[config] = current
+	}
+}
+
+// Of returns sorted nodes which manual modifications were reverted on behalf of the config in the last Interval
+func (r *Reverts) Of(config string, now time.Time) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.configs[config]
+	if !ok || now.Sub(current.last) > Interval {
+		return nil
+	}
+	var nodes []string
+	for node := range current.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Report sets the metric of manual modifications of the kind detected in the last Interval
+func Report(kind string, detected bool) {
+	value := 0.0
+	if detected {
+		value = 1
+	}
+	detectedGauge.WithLabelValues(kind).Set(value)
+}
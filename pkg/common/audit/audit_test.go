@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package audit
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+	type spec struct {
+		VFAmount int `json:"vfAmount"`
+	}
+
+	It("detects specs differing from the recorded one", func() {
+		recorded := Checksum(spec{VFAmount: 2})
+		Expect(IsModified(recorded, spec{VFAmount: 2})).To(BeFalse())
+		Expect(IsModified(recorded, spec{VFAmount: 4})).To(BeTrue())
+		Expect(IsModified("", spec{VFAmount: 4})).To(BeFalse())
+	})
+
+	It("reports reverts of the last interval", func() {
+		now := time.Now()
+		reverts := Reverts{}
+		reverts.Record("SriovFecNodeConfig", "node-b", []string{"config"}, now.Add(-2*Interval))
+		Expect(reverts.Of("config", now)).To(BeEmpty())
+
+		reverts.Record("SriovFecNodeConfig", "node-a", []string{"config", "other"}, now.Add(-time.Minute))
+		reverts.Record("SriovFecNodeConfig", "node-c", []string{"config"}, now)
+		Expect(reverts.Of("config", now)).To(Equal([]string{"node-a", "node-c"}))
+		Expect(reverts.Of("other", now)).To(Equal([]string{"node-a"}))
+		Expect(reverts.Of("other", now.Add(Interval))).To(BeEmpty())
+		Expect(reverts.Of("unknown", now)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package backoff computes delays of reconciliations retried after failures. Transient failures, e.g. conflicts or
+// unavailable API server, are retried after exponentially growing delay with jitter, permanent ones, e.g. missing RBAC
+// permissions or invalid configuration, after the maximum delay, so failing reconciliations don't storm the API server.
+package backoff
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+// EnvName is a name of env var configuring the maximum delay as Go duration, e.g. 10m
+const EnvName = "SRIOV_FEC_MAX_BACKOFF"
+
+const (
+	// DefaultBase is a delay of the first retry
+	DefaultBase = time.Second
+	// DefaultCap is the maximum delay of retries used when EnvName is not set
+	DefaultCap = 5 * time.Minute
+	// jitterFactor spreads retries of failures caused by the same outage
+	jitterFactor = 0.2
+)
+
+// Backoff tracks consecutive failures of reconciliations by their keys. Nil Backoff returns errors to controller-runtime,
+// which retries them with its default rate limiter.
+type Backoff struct {
+	Base time.Duration
+	Cap  time.Duration
+
+	mu       sync.Mutex
+	failures map[string]int
+}
+
+// New returns Backoff with the default base delay and given maximum delay
+func New(cap time.Duration) *Backoff {
+	return &Backoff{Base: DefaultBase, Cap: cap}
+}
+
+// CapFromEnv returns the maximum delay configured with SRIOV_FEC_MAX_BACKOFF env var, DefaultCap if it's not set
+func CapFromEnv() (time.Duration, error) {
+	value := os.Getenv(EnvName)
+	if value == "" {
+		return DefaultCap, nil
+	}
+	cap, err := time.ParseDuration(value)
+	if err != nil || cap <= 0 {
+		return 0, fmt.Errorf("invalid %s %q, expected positive duration, e.g. 10m", EnvName, value)
+	}
+	return cap, nil
+}
+
+// Permanent tells whether err is not expected to disappear on retry
+func Permanent(err error) bool {
+	switch errclass.Of(err) {
+	case errclass.Validation, errclass.Platform:
+		return true
+	case errclass.TransientInfra:
+		return false
+	}
+	return apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) || apierrors.IsInvalid(err) ||
+		apierrors.IsBadRequest(err) || apierrors.IsMethodNotSupported(err)
+}
+
+// Delay returns delay of the next retry of the reconciliation of key, which has just failed with err
+func (b *Backoff) Delay(key string, err error) time.Duration {
+	if Permanent(err) {
+		return b.Cap
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures == nil {
+		b.failures = map[string]int{}
+	}
+	failures := b.failures[key]
+	b.failures[key]++
+
+	delay := b.Base
+	for i := 0; i < failures && delay < b.Cap; i++ {
+		delay *= 2
+	}
+	delay = wait.Jitter(delay, jitterFactor)
+	if delay > b.Cap {
+		delay = b.Cap
+	}
+	return delay
+}
+
+// Requeue returns result of the reconciliation of key; failed one is retried after Delay. The error is not returned
+// to controller-runtime, because it ignores RequeueAfter of failed reconciliations, so callers log the error.
+func (b *Backoff) Requeue(key string, err error) (ctrl.Result, error) {
+	if b == nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: b.Delay(key, err)}, nil
+}
+
+// Reset forgets failures of the reconciliation of key, it's called once the reconciliation succeeds
+func (b *Backoff) Reset(key string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, key)
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package backoff
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+var _ = Describe("Backoff", func() {
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "sriovfecnodeconfigs"}, "node", errors.New("conflict"))
+
+	It("grows delay of transient failures exponentially up to the cap", func() {
+		b := New(10 * time.Second)
+		Expect(b.Delay("cc", conflict)).To(BeNumerically("~", time.Second, 200*time.Millisecond))
+		Expect(b.Delay("cc", conflict)).To(BeNumerically("~", 2*time.Second, 400*time.Millisecond))
+		Expect(b.Delay("cc", conflict)).To(BeNumerically("~", 4*time.Second, 800*time.Millisecond))
+		Expect(b.Delay("cc", conflict)).To(BeNumerically("~", 8*time.Second, 1600*time.Millisecond))
+		Expect(b.Delay("cc", conflict)).To(Equal(10 * time.Second))
+		Expect(b.Delay("other", conflict)).To(BeNumerically("<=", 1200*time.Millisecond))
+
+		b.Reset("cc")
+		Expect(b.Delay("cc", conflict)).To(BeNumerically("<=", 1200*time.Millisecond))
+	})
+
+	It("retries permanent failures after the cap", func() {
+		b := New(time.Minute)
+		forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("forbidden"))
+		Expect(b.Delay("cc", forbidden)).To(Equal(time.Minute))
+		Expect(b.Delay("cc", errclass.New(errclass.Validation, "invalid"))).To(Equal(time.Minute))
+		Expect(Permanent(errors.New("connection refused"))).To(BeFalse())
+		Expect(Permanent(conflict)).To(BeFalse())
+	})
+
+	It("requeues failed reconciliations without returning the error", func() {
+		result, err := New(time.Minute).Requeue("cc", conflict)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+		var b *Backoff
+		result, err = b.Requeue("cc", conflict)
+		Expect(err).To(Equal(conflict))
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("reads the cap from env", func() {
+		defer os.Unsetenv(EnvName)
+		Expect(CapFromEnv()).To(Equal(DefaultCap))
+
+		Expect(os.Setenv(EnvName, "10m")).To(Succeed())
+		Expect(CapFromEnv()).To(Equal(10 * time.Minute))
+
+		Expect(os.Setenv(EnvName, "-1s")).To(Succeed())
+		_, err := CapFromEnv()
+		Expect(err).To(HaveOccurred())
+	})
+})
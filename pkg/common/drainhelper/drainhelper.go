@@ -5,8 +5,11 @@ package drainhelper
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -14,6 +17,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/leaderelection"
@@ -24,10 +29,27 @@ import (
 const (
 	drainHelperTimeoutEnvVarName = "DRAIN_TIMEOUT_SECONDS"
 	drainHelperTimeoutDefault    = int64(90)
+	drainDeadlineEnvVarName      = "DRAIN_DEADLINE_SECONDS"
+	drainDeadlineDefault         = int64(600)
 	LeaseDurationEnvVarName      = "LEASE_DURATION_SECONDS"
 	LeaseDurationDefault         = int64(137)
+
+	stuckEvictionPolicyEnvVarName = "DRAIN_STUCK_EVICTION_POLICY"
+	// StuckEvictionPolicyFail fails the drain when pods are not evicted within the drain deadline, e.g. because
+	// PodDisruptionBudgets don't allow it; the node is uncordoned and configuration is not applied
+	StuckEvictionPolicyFail = "Fail"
+	// StuckEvictionPolicyDelete deletes pods which were not evicted within the drain deadline, bypassing
+	// PodDisruptionBudgets, and completes the drain
+	StuckEvictionPolicyDelete = "Delete"
+
+	// DrainStartedAnnotation is set on the node while it's drained for configuration, so the drain interrupted by
+	// restart of the daemon is resumed within the remaining drain deadline
+	DrainStartedAnnotation = "sriovfec.intel.com/drain-started-at"
 )
 
+// errDrainTimedOut is returned when pods are not evicted within the drain deadline
+var errDrainTimedOut = errors.New("pods were not evicted within the drain deadline")
+
 // logWriter is a wrapper around logrus log.Info() to allow drain.Helper logging
 type logWriter struct {
 	log *logrus.Logger
@@ -44,6 +66,8 @@ type DrainHelper struct {
 	nodeName  string
 
 	drainer              *drain.Helper
+	drainDeadline        time.Duration
+	stuckEvictionPolicy  string
 	leaseLock            *resourcelock.LeaseLock
 	leaderElectionConfig leaderelection.LeaderElectionConfig
 }
@@ -60,7 +84,28 @@ func NewDrainHelper(log *logrus.Logger, cs *clientset.Clientset, nodeName, names
 			drainTimeout = val
 		}
 	}
-	log.WithField("timeout seconds", drainTimeout).Info("drain settings")
+	drainDeadline := drainDeadlineDefault
+	if drainDeadlineStr := os.Getenv(drainDeadlineEnvVarName); drainDeadlineStr != "" {
+		val, err := strconv.ParseInt(drainDeadlineStr, 10, 64)
+		if err != nil {
+			log.WithError(err).WithField("variable", drainDeadlineEnvVarName).
+				Error("failed to parse env variable to int64 - using default value")
+		} else {
+			drainDeadline = val
+		}
+	}
+	stuckEvictionPolicy := os.Getenv(stuckEvictionPolicyEnvVarName)
+	switch stuckEvictionPolicy {
+	case StuckEvictionPolicyFail, StuckEvictionPolicyDelete:
+	case "":
+		stuckEvictionPolicy = StuckEvictionPolicyFail
+	default:
+		log.WithField("variable", stuckEvictionPolicyEnvVarName).WithField("value", stuckEvictionPolicy).
+			Error("unknown stuck eviction policy - using default value")
+		stuckEvictionPolicy = StuckEvictionPolicyFail
+	}
+	log.WithField("timeout seconds", drainTimeout).WithField("deadline seconds", drainDeadline).
+		WithField("stuck eviction policy", stuckEvictionPolicy).Info("drain settings")
 
 	leaseDur := LeaseDurationDefault
 	leaseDurStr := os.Getenv(LeaseDurationEnvVarName)
@@ -90,8 +135,8 @@ func NewDrainHelper(log *logrus.Logger, cs *clientset.Clientset, nodeName, names
 		clientSet: cs,
 		nodeName:  nodeName,
 
+		// Ctx of the drain helper is set by drainerWithContext
 		drainer: &drain.Helper{
-			Ctx:                 context.Background(),
 			Client:              cs,
 			Force:               true,
 			IgnoreAllDaemonSets: true,
@@ -109,6 +154,8 @@ func NewDrainHelper(log *logrus.Logger, cs *clientset.Clientset, nodeName, names
 			Out:    logWriter{log},
 			ErrOut: logWriter{log},
 		},
+		drainDeadline:       time.Duration(drainDeadline) * time.Second,
+		stuckEvictionPolicy: stuckEvictionPolicy,
 
 		leaseLock:            lock,
 		leaderElectionConfig: CustomizedLeaderElectionConfig(lock, leaseDur, isSingleNodeCluster),
@@ -139,7 +186,8 @@ func CustomizedLeaderElectionConfig(lock *resourcelock.LeaseLock, leaseDur int64
 // It should return true if uncordon should be performed(Only applicable if drain is set to true).
 // If `f` returns false, the uncordon does not take place. This is useful in 2-step scenario like sriov-fec-daemon where
 // reboot must be performed without loosing the leadership and without the uncordon.
-func (dh *DrainHelper) Run(f func(context.Context) bool, drain bool) error {
+// Cancellation of ctx ends the leadership, so the context passed to `f` and to (un)cordon and drain is cancelled as well.
+func (dh *DrainHelper) Run(ctx context.Context, f func(context.Context) bool, drain bool) error {
 	defer func() {
 		// Following mitigation is needed because of the bug in the leader election's release functionality
 		// Release fails because the input (leader election record) is created incomplete (missing fields):
@@ -152,18 +200,20 @@ func (dh *DrainHelper) Run(f func(context.Context) bool, drain bool) error {
 
 		dh.log.Info("releasing the lock (bug mitigation)")
 
-		leaderElectionRecord, _, err := dh.leaseLock.Get(context.Background())
+		// ctx is already cancelled here, the lock is released with a context detached from its cancellation
+		ctx := context.WithoutCancel(ctx)
+		leaderElectionRecord, _, err := dh.leaseLock.Get(ctx)
 		if err != nil {
 			dh.log.WithError(err).Error("failed to get the LeaderElectionRecord")
 			return
 		}
 		leaderElectionRecord.HolderIdentity = ""
-		if err := dh.leaseLock.Update(context.Background(), *leaderElectionRecord); err != nil {
+		if err := dh.leaseLock.Update(ctx, *leaderElectionRecord); err != nil {
 			dh.log.WithError(err).Error("failed to update the LeaderElectionRecord")
 		}
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	var innerErr error
@@ -232,24 +282,64 @@ func (dh *DrainHelper) onNewLeaderFunction(id string) {
 	}
 }
 
+// cordonAndDrain cordons and drains the node within the drain deadline, retrying attempts bounded by the drain timeout.
+// The drain started before restart of the daemon, as recorded by DrainStartedAnnotation, is resumed within the remaining
+// deadline. Pods which are not evicted within the deadline, e.g. because of PodDisruptionBudgets, are handled according
+// to the stuck eviction policy.
 func (dh *DrainHelper) cordonAndDrain(ctx context.Context) error {
+	drainer := dh.drainerWithContext(ctx)
 	node, nodeGetErr := dh.clientSet.CoreV1().Nodes().Get(ctx, dh.nodeName, metav1.GetOptions{})
 	if nodeGetErr != nil {
 		dh.log.WithError(nodeGetErr).Error("failed to get the node object")
 		return nodeGetErr
 	}
 
+	startedAt, resumed := drainStartedAt(node)
+	if resumed {
+		dh.log.WithField("startedAt", startedAt).Info("resuming drain interrupted by restart of the daemon")
+	} else if err := dh.annotateDrainStarted(ctx, &startedAt); err != nil {
+		dh.log.WithError(err).Error("failed to annotate the node with start of the drain")
+		return err
+	}
+
+	progress := newDrainProgress(ctx, startedAt)
+	drainer.OnPodDeletedOrEvicted = func(pod *corev1.Pod, usingEviction bool) {
+		dh.drainer.OnPodDeletedOrEvicted(pod, usingEviction)
+		progress.onPodDeletedOrEvicted(pod)
+	}
+
 	var e error
-	backoff := wait.Backoff{Steps: 5, Duration: 15 * time.Second, Factor: 2}
+	// attempts are retried until the deadline
+	backoff := wait.Backoff{Steps: math.MaxInt32, Duration: 15 * time.Second, Factor: 2, Cap: 2 * time.Minute}
 	f := func() (bool, error) {
-		if err := drain.RunCordonOrUncordon(dh.drainer, node, true); err != nil {
+		// deadline bounds the whole drain, including its retries and the time before restart of the daemon,
+		// while the timeout bounds each attempt
+		remaining := dh.drainDeadline - time.Since(startedAt)
+		if remaining <= 0 {
+			return false, errDrainTimedOut
+		}
+
+		if err := drain.RunCordonOrUncordon(drainer, node, true); err != nil {
 			dh.log.WithField("nodeName", dh.nodeName).WithField("reason", err.Error()).
 				Info("failed to cordon the node - retrying")
 			e = err
 			return false, nil
 		}
 
-		if err := drain.RunNodeDrain(dh.drainer, dh.nodeName); err != nil {
+		pods, errs := drainer.GetPodsForDeletion(dh.nodeName)
+		if len(errs) != 0 {
+			dh.log.WithField("nodeName", dh.nodeName).WithField("reason", utilerrors.NewAggregate(errs).Error()).
+				Info("failed to list pods of the node - retrying")
+			e = utilerrors.NewAggregate(errs)
+			return false, nil
+		}
+		progress.setPending(pods.Pods())
+		if len(pods.Pods()) == 0 {
+			return true, nil
+		}
+
+		drainer.Timeout = min(dh.drainer.Timeout, remaining)
+		if err := drain.RunNodeDrain(drainer, dh.nodeName); err != nil {
 			dh.log.WithField("nodeName", dh.nodeName).WithField("reason", err.Error()).
 				Info("failed to drain the node - retrying")
 			e = err
@@ -260,20 +350,47 @@ func (dh *DrainHelper) cordonAndDrain(ctx context.Context) error {
 	}
 
 	dh.log.Info("starting drain attempts")
-	if err := wait.ExponentialBackoff(backoff, f); err != nil {
+	if err := wait.ExponentialBackoffWithContext(ctx, backoff, f); err != nil {
+		if err == errDrainTimedOut && dh.stuckEvictionPolicy == StuckEvictionPolicyDelete {
+			return dh.deleteStuckPods(ctx, progress)
+		}
 		if err == wait.ErrWaitTimeout {
 			dh.log.WithError(e).Error("failed to drain node - timed out")
+			progress.finish(DrainPhaseFailed, e.Error())
 			return e
 		}
 		dh.log.WithError(err).Error("failed to drain node")
+		progress.finish(DrainPhaseFailed, err.Error())
+		return err
+	}
+
+	dh.log.Info("node drained")
+	progress.finish(DrainPhaseDrained, "")
+	return nil
+}
+
+// deleteStuckPods deletes pods which were not evicted within the drain deadline, bypassing PodDisruptionBudgets
+func (dh *DrainHelper) deleteStuckPods(ctx context.Context, progress *drainProgress) error {
+	dh.log.Warn("pods were not evicted within the drain deadline - deleting them according to the stuck eviction policy")
+	deleter := dh.drainerWithContext(ctx)
+	deleter.DisableEviction = true
+	deleter.OnPodDeletedOrEvicted = func(pod *corev1.Pod, usingEviction bool) {
+		dh.drainer.OnPodDeletedOrEvicted(pod, usingEviction)
+		progress.onPodDeletedOrEvicted(pod)
+	}
+	if err := drain.RunNodeDrain(deleter, dh.nodeName); err != nil {
+		dh.log.WithError(err).Error("failed to delete pods not evicted within the drain deadline")
+		progress.finish(DrainPhaseFailed, err.Error())
 		return err
 	}
 
 	dh.log.Info("node drained")
+	progress.finish(DrainPhaseDrained, "pods not evicted within the drain deadline were deleted")
 	return nil
 }
 
 func (dh *DrainHelper) uncordon(ctx context.Context) error {
+	drainer := dh.drainerWithContext(ctx)
 	node, err := dh.clientSet.CoreV1().Nodes().Get(ctx, dh.nodeName, metav1.GetOptions{})
 	if err != nil {
 		dh.log.WithError(err).Error("failed to get the node object")
@@ -283,7 +400,7 @@ func (dh *DrainHelper) uncordon(ctx context.Context) error {
 	var e error
 	backoff := wait.Backoff{Steps: 5, Duration: 15 * time.Second, Factor: 2}
 	f := func() (bool, error) {
-		if err := drain.RunCordonOrUncordon(dh.drainer, node, false); err != nil {
+		if err := drain.RunCordonOrUncordon(drainer, node, false); err != nil {
 			dh.log.WithField("nodeName", dh.nodeName).WithError(err).Error("failed to uncordon the node - retrying")
 			e = err
 			return false, nil
@@ -293,7 +410,7 @@ func (dh *DrainHelper) uncordon(ctx context.Context) error {
 	}
 
 	dh.log.Info("starting uncordon attempts")
-	if err := wait.ExponentialBackoff(backoff, f); err != nil {
+	if err := wait.ExponentialBackoffWithContext(ctx, backoff, f); err != nil {
 		if err == wait.ErrWaitTimeout {
 			dh.log.WithError(e).Error("failed to uncordon node - timed out")
 			return e
@@ -303,5 +420,61 @@ func (dh *DrainHelper) uncordon(ctx context.Context) error {
 	}
 	dh.log.Info("node uncordoned")
 
+	if err := dh.removeDrainStartedAnnotation(ctx); err != nil {
+		dh.log.WithError(err).Error("failed to remove annotation with start of the drain from the node")
+		return err
+	}
 	return nil
 }
+
+// IsDrainInterrupted returns true if the node was left cordoned by a drain interrupted by restart of the daemon
+func (dh *DrainHelper) IsDrainInterrupted(ctx context.Context) (bool, error) {
+	node, err := dh.clientSet.CoreV1().Nodes().Get(ctx, dh.nodeName, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	_, interrupted := drainStartedAt(node)
+	return interrupted, nil
+}
+
+// drainStartedAt returns start of the drain recorded on the node and true if it's recorded
+func drainStartedAt(node *corev1.Node) (time.Time, bool) {
+	value, ok := node.Annotations[DrainStartedAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	startedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// the drain is resumed, but with the whole timeout
+		return time.Now(), true
+	}
+	return startedAt, true
+}
+
+// annotateDrainStarted records the current time as start of the drain on the node
+func (dh *DrainHelper) annotateDrainStarted(ctx context.Context, startedAt *time.Time) error {
+	*startedAt = time.Now()
+	return dh.patchDrainStartedAnnotation(ctx, startedAt.Format(time.RFC3339))
+}
+
+func (dh *DrainHelper) removeDrainStartedAnnotation(ctx context.Context) error {
+	return dh.patchDrainStartedAnnotation(ctx, nil)
+}
+
+func (dh *DrainHelper) patchDrainStartedAnnotation(ctx context.Context, value interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{DrainStartedAnnotation: value}},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = dh.clientSet.CoreV1().Nodes().Patch(ctx, dh.nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// drainerWithContext returns copy of the drain helper bound to ctx, so evictions are cancelled along with it
+func (dh *DrainHelper) drainerWithContext(ctx context.Context) *drain.Helper {
+	drainer := *dh.drainer
+	drainer.Ctx = ctx
+	return &drainer
+}
@@ -56,6 +56,15 @@ var _ = Describe("DrainHelper Tests", func() {
 			Expect(dh.drainer.Timeout).ToNot(Equal(time.Duration(timeoutVal) * time.Second))
 		})
 
+		var _ = It("Create simple DrainHelper with drain deadline separate from timeout of attempts", func() {
+			Expect(os.Setenv("DRAIN_DEADLINE_SECONDS", "300")).To(Succeed())
+			defer os.Unsetenv("DRAIN_DEADLINE_SECONDS")
+
+			dh := NewDrainHelper(log, &clientSet, "node", "namespace", false)
+			Expect(dh.drainer.Timeout).To(Equal(5 * time.Second))
+			Expect(dh.drainDeadline).To(Equal(300 * time.Second))
+		})
+
 		var _ = It("Create simple DrainHelper with invalid lease time duration", func() {
 			var err error
 
@@ -80,7 +89,7 @@ var _ = Describe("DrainHelper Tests", func() {
 			dh := NewDrainHelper(log, cset, "node", "namespace", false)
 			Expect(dh).ToNot(Equal(nil))
 
-			err = dh.Run(func(c context.Context) bool { return true }, true)
+			err = dh.Run(context.TODO(), func(c context.Context) bool { return true }, true)
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -228,7 +237,7 @@ var _ = Describe("DrainHelper Tests", func() {
 			dh := NewDrainHelper(log, cset, "dummy", "default", false)
 			Expect(dh).ToNot(Equal(nil))
 
-			err = dh.Run(func(c context.Context) bool { return true }, true)
+			err = dh.Run(context.TODO(), func(c context.Context) bool { return true }, true)
 			Expect(err).ToNot(HaveOccurred())
 
 			// Cleanup
@@ -260,7 +269,7 @@ var _ = Describe("DrainHelper Tests", func() {
 			dh := NewDrainHelper(log, cset, "dummy", "default", false)
 			Expect(dh).ToNot(Equal(nil))
 
-			err = dh.Run(func(c context.Context) bool { return true }, false)
+			err = dh.Run(context.TODO(), func(c context.Context) bool { return true }, false)
 			Expect(err).ToNot(HaveOccurred())
 
 			// Cleanup
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package drainhelper
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DrainPhaseDraining = "Draining"
+	DrainPhaseDrained  = "Drained"
+	DrainPhaseFailed   = "Failed"
+
+	// maxReportedPendingPods limits number of pods listed in the reported progress
+	maxReportedPendingPods = 10
+)
+
+// Progress of the drain of the node
+type Progress struct {
+	Phase string
+	// StartedAt is a time the drain started, it's kept when the drain is resumed after restart of the daemon
+	StartedAt   time.Time
+	PodsToEvict int
+	PodsEvicted int
+	// PendingPods are namespace/name of pods not evicted yet, at most maxReportedPendingPods of them
+	PendingPods []string
+	Message     string
+}
+
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a context reporting progress of drains run with it to report
+func WithProgressReporter(ctx context.Context, report func(Progress)) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, report)
+}
+
+// ReportProgress reports progress of the drain to the reporter of ctx, if any
+func ReportProgress(ctx context.Context, progress Progress) {
+	if report, ok := ctx.Value(progressReporterKey{}).(func(Progress)); ok {
+		report(progress)
+	}
+}
+
+// drainProgress tracks evictions of pods and reports progress of the drain to the reporter of its context
+type drainProgress struct {
+	ctx context.Context
+
+	mu       sync.Mutex
+	progress Progress
+	evicted  map[string]bool
+	pending  map[string]bool
+}
+
+func newDrainProgress(ctx context.Context, startedAt time.Time) *drainProgress {
+	return &drainProgress{
+		ctx:      ctx,
+		progress: Progress{Phase: DrainPhaseDraining, StartedAt: startedAt},
+		evicted:  map[string]bool{},
+		pending:  map[string]bool{},
+	}
+}
+
+// setPending sets pods remaining on the node and reports the progress, pods evicted in the previous attempts
+// or before restart of the daemon are counted as evicted
+func (p *drainProgress) setPending(pods []corev1.Pod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending = map[string]bool{}
+	for i := range pods {
+		p.pending[podName(&pods[i])] = true
+	}
+	p.update()
+}
+
+func (p *drainProgress) onPodDeletedOrEvicted(pod *corev1.Pod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	name := podName(pod)
+	delete(p.pending, name)
+	p.evicted[name] = true
+	p.update()
+}
+
+// finish reports the final phase of the drain
+func (p *drainProgress) finish(phase, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.progress.Phase = phase
+	p.progress.Message = message
+	p.update()
+}
+
+func (p *drainProgress) update() {
+	p.progress.PodsEvicted = len(p.evicted)
+	p.progress.PodsToEvict = len(p.evicted) + len(p.pending)
+	p.progress.PendingPods = nil
+	for name := range p.pending {
+		p.progress.PendingPods = append(p.progress.PendingPods, name)
+	}
+	sort.Strings(p.progress.PendingPods)
+	if len(p.progress.PendingPods) > maxReportedPendingPods {
+		p.progress.PendingPods = p.progress.PendingPods[:maxReportedPendingPods]
+	}
+
+	ReportProgress(p.ctx, p.progress)
+}
+
+func podName(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
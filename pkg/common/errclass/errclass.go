@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package errclass defines classes of errors reported by the operator and the daemon. The class is exposed in statuses,
+// Events and metrics, so alerting and automation can act on it without parsing error messages.
+package errclass
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Class of the error
+type Class string
+
+const (
+	// Validation indicates that requested configuration is invalid or not applicable to the node
+	Validation Class = "ValidationError"
+	// Platform indicates that the node is not prepared for configuration, e.g. IOMMU disabled or kernel module missing
+	Platform Class = "PlatformError"
+	// TransientInfra indicates temporary failure of the infrastructure, e.g. API server unavailable or failed drain.
+	// Operation is expected to succeed on retry.
+	TransientInfra Class = "TransientInfraError"
+	// Device indicates failure of the accelerator configuration, e.g. failed pf_bb_config or sysfs write
+	Device Class = "DeviceError"
+	// Unknown is a class of errors which were not classified
+	Unknown Class = "UnknownError"
+)
+
+// Component reporting errors, used as a label of the errors metric
+const (
+	Operator = "operator"
+	Daemon   = "daemon"
+)
+
+var errorsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "sriov_fec_errors_total",
+	Help: "Number of configuration errors by component and class",
+}, []string{"component", "class"})
+
+func init() {
+	metrics.Registry.MustRegister(errorsCounter)
+}
+
+// Error is an error with assigned class
+type Error struct {
+	Class Class
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of given class formatted according to format specifier
+func New(class Class, format string, args ...interface{}) error {
+	return &Error{Class: class, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap assigns class to err; class of already classified error (see Of) is kept. Wrap returns nil for nil err.
+func Wrap(class Class, err error) error {
+	if err == nil || Of(err) != Unknown {
+		return err
+	}
+	return &Error{Class: class, Err: err}
+}
+
+// Of returns class of err. Errors returned by API server which are expected to disappear on retry are TransientInfra.
+func Of(err error) Class {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+	if isTransientAPIError(err) {
+		return TransientInfra
+	}
+	return Unknown
+}
+
+// Record counts err in the errors metric and returns its class
+func Record(component string, err error) Class {
+	class := Of(err)
+	errorsCounter.WithLabelValues(component, string(class)).Inc()
+	return class
+}
+
+func isTransientAPIError(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err)
+}
@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package errclass
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("errclass", func() {
+	It("returns class of classified error", func() {
+		err := New(Device, "pf_bb_config failed: %d", 1)
+		Expect(err.Error()).To(Equal("pf_bb_config failed: 1"))
+		Expect(Of(err)).To(Equal(Device))
+		Expect(Of(fmt.Errorf("wrapped: %w", err))).To(Equal(Device))
+	})
+
+	It("classifies transient API errors", func() {
+		conflict := apierrors.NewConflict(schema.GroupResource{Resource: "sriovfecnodeconfigs"}, "node", errors.New("conflict"))
+		Expect(Of(conflict)).To(Equal(TransientInfra))
+		Expect(Of(apierrors.NewNotFound(schema.GroupResource{Resource: "sriovfecnodeconfigs"}, "node"))).To(Equal(Unknown))
+		Expect(Of(errors.New("error"))).To(Equal(Unknown))
+	})
+
+	It("keeps class of already classified error", func() {
+		Expect(Wrap(Device, nil)).To(BeNil())
+		Expect(Of(Wrap(Device, errors.New("error")))).To(Equal(Device))
+		Expect(Of(Wrap(Device, New(Platform, "IOMMU is not enabled")))).To(Equal(Platform))
+		Expect(Of(Wrap(Device, apierrors.NewTooManyRequests("retry", 1)))).To(Equal(TransientInfra))
+	})
+
+	It("counts recorded errors", func() {
+		before := testutil.ToFloat64(errorsCounter.WithLabelValues(Daemon, string(Validation)))
+		Expect(Record(Daemon, New(Validation, "invalid"))).To(Equal(Validation))
+		Expect(testutil.ToFloat64(errorsCounter.WithLabelValues(Daemon, string(Validation)))).To(Equal(before + 1))
+	})
+})
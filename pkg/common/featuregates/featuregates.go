@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package featuregates allows shipping capabilities of the operator and the daemon disabled by default and toggling
+// them per cluster without separate builds. Gates are listed in SRIOV_FEC_FEATURE_GATES env var, e.g. "Telemetry=false".
+package featuregates
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// Feature which can be toggled with its gate
+type Feature string
+
+const (
+	// ACC200 allows configuration of ACC200 accelerators with SriovFecClusterConfig
+	ACC200 Feature = "ACC200"
+	// Telemetry enables collection of pf_bb_config telemetry by the daemon
+	Telemetry Feature = "Telemetry"
+	// DiscoveryAPI exposes inventory of accelerators of the node on the daemon's /discovery endpoint
+	DiscoveryAPI Feature = "DiscoveryAPI"
+	// QueueReconfiguration reconfigures queues of ACC100 accelerators without recreating their VFs, when nothing else
+	// changes
+	QueueReconfiguration Feature = "QueueReconfiguration"
+	// NodeMigration migrates NodeConfigs of removed nodes to nodes reporting the same accelerators, e.g. after the node
+	// was reprovisioned under a new name
+	NodeMigration Feature = "NodeMigration"
+)
+
+// EnvName is a name of env var listing feature gates of the operator and the daemon
+const EnvName = utils.SRIOV_PREFIX + "FEATURE_GATES"
+
+// defaults of feature gates; experimental features are added disabled
+var defaults = map[Feature]bool{
+	ACC200:               true,
+	Telemetry:            true,
+	DiscoveryAPI:         false,
+	QueueReconfiguration: false,
+	NodeMigration:        false,
+}
+
+var gates = withDefaults()
+
+func withDefaults() map[Feature]bool {
+	current := map[Feature]bool{}
+	for feature, enabled := range defaults {
+		current[feature] = enabled
+	}
+	return current
+}
+
+// Enabled returns true if the feature is enabled
+func Enabled(feature Feature) bool {
+	return gates[feature]
+}
+
+// Set overrides defaults with comma-separated list of Feature=true|false pairs. Unknown features and invalid values
+// are rejected, so a typo doesn't silently leave a feature in its default state.
+func Set(value string) error {
+	current := withDefaults()
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		feature, enabled, found := strings.Cut(pair, "=")
+		if !found {
+			return fmt.Errorf("feature gate %q has to be in Feature=true|false format", pair)
+		}
+		if _, ok := defaults[Feature(feature)]; !ok {
+			return fmt.Errorf("unknown feature gate %q, known are: %s", feature, knownFeatures())
+		}
+		isEnabled, err := strconv.ParseBool(enabled)
+		if err != nil {
+			return fmt.Errorf("invalid value %q of feature gate %q", enabled, feature)
+		}
+		current[Feature(feature)] = isEnabled
+	}
+	gates = current
+	return nil
+}
+
+// SetFromEnv overrides defaults with feature gates listed in SRIOV_FEC_FEATURE_GATES env var
+func SetFromEnv() error {
+	return Set(os.Getenv(EnvName))
+}
+
+// String returns state of all feature gates
+func String() string {
+	var pairs []string
+	for feature, enabled := range gates {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", feature, enabled))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func knownFeatures() string {
+	var features []string
+	for feature := range defaults {
+		features = append(features, string(feature))
+	}
+	sort.Strings(features)
+	return strings.Join(features, ", ")
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package featuregates
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("feature gates", func() {
+	AfterEach(func() {
+		Expect(Set("")).To(Succeed())
+	})
+
+	It("keeps defaults when no gate is set", func() {
+		Expect(Set("")).To(Succeed())
+		Expect(Enabled(ACC200)).To(BeTrue())
+		Expect(Enabled(Telemetry)).To(BeTrue())
+		Expect(Enabled(DiscoveryAPI)).To(BeFalse())
+		Expect(String()).To(Equal("ACC200=true,DiscoveryAPI=false,NodeMigration=false,QueueReconfiguration=false,Telemetry=true"))
+	})
+
+	It("overrides defaults with listed gates", func() {
+		Expect(Set(" Telemetry=false, ACC200=true")).To(Succeed())
+		Expect(Enabled(Telemetry)).To(BeFalse())
+		Expect(Enabled(ACC200)).To(BeTrue())
+
+		Expect(Set("ACC200=false")).To(Succeed())
+		Expect(Enabled(Telemetry)).To(BeTrue())
+		Expect(Enabled(ACC200)).To(BeFalse())
+	})
+
+	It("rejects invalid gates and keeps the current ones", func() {
+		Expect(Set("Telemetry=false")).To(Succeed())
+
+		Expect(Set("Telemetry")).To(MatchError(ContainSubstring("Feature=true|false")))
+		Expect(Set("Unknown=true")).To(MatchError(ContainSubstring("known are: ACC200, DiscoveryAPI, NodeMigration, QueueReconfiguration, Telemetry")))
+		Expect(Set("ACC200=maybe")).To(MatchError(ContainSubstring("invalid value")))
+		Expect(Enabled(Telemetry)).To(BeFalse())
+	})
+})
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package migration
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultJobInterval is a default period between attempts of the migration job
+const DefaultJobInterval = 30 * time.Second
+
+// Job runs storage version migration as a runnable of the manager, so objects stored in old versions are converted by
+// the conversion webhook the manager serves. The migration is retried with Interval until it succeeds, as the webhook is
+// reachable only once the operator's pod is ready.
+type Job struct {
+	Migrator  *StorageVersionMigrator
+	Resources []Resource
+	// AfterMigration is run once all resources are migrated, e.g. to split objects which were not converted one-to-one;
+	// the migration is retried while it fails, optional
+	AfterMigration func(ctx context.Context) error
+	Interval       time.Duration
+}
+
+// NeedLeaderElection makes only one replica of the operator migrate objects
+func (j *Job) NeedLeaderElection() bool {
+	return true
+}
+
+func (j *Job) Start(ctx context.Context) error {
+	_ = wait.PollImmediateUntilWithContext(ctx, j.Interval, func(ctx context.Context) (bool, error) {
+		if err := j.Migrator.Migrate(ctx, j.Resources...); err != nil {
+			j.Migrator.Log.WithError(err).Warn("storage version migration failed - retrying")
+			return false, nil
+		}
+		if j.AfterMigration != nil {
+			if err := j.AfterMigration(ctx); err != nil {
+				j.Migrator.Log.WithError(err).Warn("post-migration step failed - retrying")
+				return false, nil
+			}
+		}
+		j.Migrator.Log.Info("storage version migration completed")
+		return true, nil
+	})
+	return nil
+}
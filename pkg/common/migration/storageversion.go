@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Resource describes CRD which objects should be kept in the given storage version
+type Resource struct {
+	// CRDName is a name of CustomResourceDefinition, e.g. sriovfecclusterconfigs.sriovfec.intel.com
+	CRDName string
+	// StorageVersion is a version all stored objects should be rewritten to
+	StorageVersion string
+	// NewList returns an empty list of objects served in StorageVersion
+	NewList func() client.ObjectList
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update;patch
+
+// StorageVersionMigrator rewrites stored objects to the current storage version
+// and prunes old versions from CRD's status.storedVersions
+type StorageVersionMigrator struct {
+	client.Client
+	Log *logrus.Logger
+}
+
+// Migrate executes migration of all given resources, it stops on first failure
+func (m *StorageVersionMigrator) Migrate(ctx context.Context, resources ...Resource) error {
+	for _, r := range resources {
+		if err := m.migrate(ctx, r); err != nil {
+			return fmt.Errorf("failed to migrate %s to %s: %w", r.CRDName, r.StorageVersion, err)
+		}
+	}
+	return nil
+}
+
+func (m *StorageVersionMigrator) migrate(ctx context.Context, r Resource) error {
+	log := m.Log.WithField("crd", r.CRDName).WithField("storageVersion", r.StorageVersion)
+
+	crd := new(apiextensionsv1.CustomResourceDefinition)
+	if err := m.Get(ctx, client.ObjectKey{Name: r.CRDName}, crd); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("CRD does not exist - skipping storage version migration")
+			return nil
+		}
+		return err
+	}
+
+	if !requiresMigration(crd.Status.StoredVersions, r.StorageVersion) {
+		log.Debug("objects are already stored in expected version")
+		return nil
+	}
+
+	log.WithField("storedVersions", crd.Status.StoredVersions).Info("migrating stored objects")
+
+	list := r.NewList()
+	if err := m.List(ctx, list); err != nil {
+		return err
+	}
+
+	objects, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		obj, ok := o.(client.Object)
+		if !ok {
+			return fmt.Errorf("unexpected object type %T", o)
+		}
+		if err := m.rewrite(ctx, obj); err != nil {
+			return err
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.Get(ctx, client.ObjectKey{Name: r.CRDName}, crd); err != nil {
+			return err
+		}
+		crd.Status.StoredVersions = []string{r.StorageVersion}
+		return m.Status().Update(ctx, crd)
+	})
+}
+
+// rewrite issues a no-op update which makes api-server persist object in the current storage version
+func (m *StorageVersionMigrator) rewrite(ctx context.Context, obj client.Object) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := m.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		m.Log.WithField("namespace", obj.GetNamespace()).WithField("name", obj.GetName()).Info("rewriting object")
+		return client.IgnoreNotFound(m.Update(ctx, obj))
+	})
+}
+
+func requiresMigration(storedVersions []string, storageVersion string) bool {
+	for _, v := range storedVersions {
+		if v != storageVersion {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StorageVersionMigrator", func() {
+	const crdName = "sriovfecclusterconfigs.sriovfec.intel.com"
+
+	var (
+		scheme   *runtime.Scheme
+		resource = Resource{
+			CRDName:        crdName,
+			StorageVersion: "v2",
+			NewList:        func() client.ObjectList { return new(sriovfecv2.SriovFecClusterConfigList) },
+		}
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		Expect(sriovfecv2.AddToScheme(scheme)).To(Succeed())
+	})
+
+	newMigrator := func(objs ...client.Object) *StorageVersionMigrator {
+		return &StorageVersionMigrator{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+			Log:    logrus.New(),
+		}
+	}
+
+	crd := func(storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: crdName},
+			Status:     apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+		}
+	}
+
+	It("should prune old versions from storedVersions and rewrite objects", func() {
+		cc := &sriovfecv2.SriovFecClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+		m := newMigrator(crd("v1", "v2"), cc)
+
+		before := new(sriovfecv2.SriovFecClusterConfig)
+		Expect(m.Get(context.TODO(), client.ObjectKeyFromObject(cc), before)).To(Succeed())
+
+		Expect(m.Migrate(context.TODO(), resource)).To(Succeed())
+
+		after := new(sriovfecv2.SriovFecClusterConfig)
+		Expect(m.Get(context.TODO(), client.ObjectKeyFromObject(cc), after)).To(Succeed())
+		Expect(after.ResourceVersion).ToNot(Equal(before.ResourceVersion))
+
+		updated := new(apiextensionsv1.CustomResourceDefinition)
+		Expect(m.Get(context.TODO(), client.ObjectKey{Name: crdName}, updated)).To(Succeed())
+		Expect(updated.Status.StoredVersions).To(Equal([]string{"v2"}))
+	})
+
+	It("should not touch objects if already stored in expected version", func() {
+		cc := &sriovfecv2.SriovFecClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+		m := newMigrator(crd("v2"), cc)
+
+		before := new(sriovfecv2.SriovFecClusterConfig)
+		Expect(m.Get(context.TODO(), client.ObjectKeyFromObject(cc), before)).To(Succeed())
+
+		Expect(m.Migrate(context.TODO(), resource)).To(Succeed())
+
+		after := new(sriovfecv2.SriovFecClusterConfig)
+		Expect(m.Get(context.TODO(), client.ObjectKeyFromObject(cc), after)).To(Succeed())
+		Expect(after.ResourceVersion).To(Equal(before.ResourceVersion))
+	})
+
+	It("should skip migration if CRD does not exist", func() {
+		Expect(newMigrator().Migrate(context.TODO(), resource)).To(Succeed())
+	})
+
+	It("should retry the job until the step after migration succeeds", func() {
+		m := newMigrator(crd("v1", "v2"))
+		attempts := 0
+		job := &Job{Migrator: m, Resources: []Resource{resource}, Interval: time.Millisecond,
+			AfterMigration: func(context.Context) error {
+				if attempts++; attempts < 3 {
+					return fmt.Errorf("webhook is not reachable")
+				}
+				return nil
+			}}
+
+		Expect(job.Start(context.TODO())).To(Succeed())
+		Expect(attempts).To(Equal(3))
+
+		updated := new(apiextensionsv1.CustomResourceDefinition)
+		Expect(m.Get(context.TODO(), client.ObjectKey{Name: crdName}, updated)).To(Succeed())
+		Expect(updated.Status.StoredVersions).To(Equal([]string{"v2"}))
+	})
+})
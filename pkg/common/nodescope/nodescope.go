@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package nodescope restricts an installation of the operator to nodes having labels listed in SRIOV_FEC_NODE_SCOPE
+// env var, e.g. "ran-vendor=a". Installations in different namespaces with disjoint scopes don't manage the same nodes.
+package nodescope
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// EnvName is a name of env var listing labels of nodes managed by the operator
+const EnvName = utils.SRIOV_PREFIX + "NODE_SCOPE"
+
+const acceleratorPresentLabel = "fpga.intel.com/intel-accelerator-present"
+
+// Parse returns labels of the scope given as comma separated key=value pairs; an empty scope selects all nodes. Only
+// equality is supported, so the scope can be used as nodeSelector of the operator's DaemonSets.
+func Parse(scope string) (labels.Set, error) {
+	set, err := labels.ConvertSelectorToLabelsMap(scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, expected comma separated key=value labels: %v", EnvName, scope, err)
+	}
+	return set, nil
+}
+
+// Labels returns labels of the scope configured with SRIOV_FEC_NODE_SCOPE env var
+func Labels() (labels.Set, error) {
+	return Parse(os.Getenv(EnvName))
+}
+
+// AcceleratedNodes returns labels of nodes with accelerators within the configured scope
+func AcceleratedNodes() (client.MatchingLabels, error) {
+	scope, err := Labels()
+	if err != nil {
+		return nil, err
+	}
+	matching := client.MatchingLabels{acceleratorPresentLabel: ""}
+	for key, value := range scope {
+		matching[key] = value
+	}
+	return matching, nil
+}
+
+// ClusterResourceSuffix returns suffix of cluster scoped resources deployed by the operator, e.g. ClusterRoles, so they
+// don't collide between installations. It's empty without a scope, so names of a single installation don't change.
+func ClusterResourceSuffix(scope, namespace string) string {
+	if scope == "" {
+		return ""
+	}
+	return "-" + namespace
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package nodescope
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("node scope", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(EnvName)).To(Succeed())
+	})
+
+	It("selects all accelerated nodes without a scope", func() {
+		Expect(AcceleratedNodes()).To(Equal(client.MatchingLabels{acceleratorPresentLabel: ""}))
+		Expect(ClusterResourceSuffix("", "vendor-a")).To(BeEmpty())
+	})
+
+	It("selects accelerated nodes having labels of the scope", func() {
+		Expect(os.Setenv(EnvName, "ran-vendor=a, zone=edge")).To(Succeed())
+		Expect(AcceleratedNodes()).To(Equal(client.MatchingLabels{
+			acceleratorPresentLabel: "",
+			"ran-vendor":            "a",
+			"zone":                  "edge",
+		}))
+		Expect(ClusterResourceSuffix("ran-vendor=a", "vendor-a")).To(Equal("-vendor-a"))
+	})
+
+	It("rejects scopes which are not equality of labels", func() {
+		Expect(Parse("ran-vendor in (a,b)")).Error().To(MatchError(ContainSubstring("expected comma separated key=value labels")))
+		Expect(Parse("ran-vendor")).Error().To(HaveOccurred())
+		Expect(Parse("ran-vendor=a")).To(Equal(labels.Set{"ran-vendor": "a"}))
+	})
+})
@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package notification pushes critical conditions of accelerators, e.g. failed configuration of a node or a config
+// becoming degraded, to sinks listed in the sriov-fec-notification-sinks Secret, for teams which don't watch
+// Kubernetes Events.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// SecretName is a name of the Secret in the namespace of the operator listing sinks keyed by their names. URLs of
+// sinks, e.g. of Slack incoming webhooks, are credentials, so they're kept in a Secret.
+const SecretName = "sriov-fec-notification-sinks"
+
+const (
+	// NodeFailedReason is reported when configuration of a node fails, e.g. pf_bb_config fails or the daemon rolls back
+	// to the last-known-good configuration
+	NodeFailedReason = "NodeFailed"
+	// DegradedReason is reported when a config becomes degraded for reasons other than failed nodes
+	DegradedReason = "Degraded"
+)
+
+const (
+	sendTimeout = 10 * time.Second
+	queueSize   = 100
+)
+
+// Notification describes a critical condition of an object managed by the operator
+type Notification struct {
+	Reason string `json:"reason"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	// Node is set when the condition is specific to a node
+	Node string `json:"node,omitempty"`
+	// Class is a class of the error, e.g. DeviceError
+	Class     string    `json:"class,omitempty"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Text returns human-readable summary of the notification
+func (n Notification) Text() string {
+	subject := fmt.Sprintf("%s/%s", n.Kind, n.Name)
+	if n.Node != "" {
+		subject += " on node " + n.Node
+	}
+	reason := n.Reason
+	if n.Class != "" {
+		reason += " (" + n.Class + ")"
+	}
+	return fmt.Sprintf("%s: %s: %s", subject, reason, n.Message)
+}
+
+// SinkConfig is a value of the Secret entry configuring the sink
+type SinkConfig struct {
+	// Type of the sink, see SinkTypes
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// Reasons limits notifications sent to the sink, all are sent when empty
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Sink delivers notifications to a remote endpoint
+type Sink interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// SinkTypes creates sinks by their type; additional types are registered by adding them to the map
+var SinkTypes = map[string]func(config SinkConfig, httpClient *http.Client) Sink{
+	// webhook POSTs the notification as JSON
+	"webhook": func(config SinkConfig, httpClient *http.Client) Sink {
+		return &httpSink{url: config.URL, httpClient: httpClient, payload: func(n Notification) interface{} { return n }}
+	},
+	// slack POSTs the notification in format of Slack incoming webhooks, which is accepted by compatible chats too
+	"slack": func(config SinkConfig, httpClient *http.Client) Sink {
+		return &httpSink{url: config.URL, httpClient: httpClient, payload: func(n Notification) interface{} {
+			return map[string]string{"text": n.Text()}
+		}}
+	},
+}
+
+type httpSink struct {
+	url        string
+	httpClient *http.Client
+	payload    func(Notification) interface{}
+}
+
+func (s *httpSink) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(s.payload(n))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// Notifier sends notifications to sinks configured in the Secret. Sinks are read on every notification, so changes
+// of the Secret apply without restart of the operator. Notifications are sent in background by Start, so slow sinks
+// don't block reconciles. Nil Notifier sends nothing.
+type Notifier struct {
+	Reader    client.Reader
+	Namespace string
+	Log       *logrus.Logger
+	// HTTPClient is used by sinks, a client with default timeout is used if not set
+	HTTPClient *http.Client
+
+	queue chan []Notification
+}
+
+// NewNotifier returns Notifier reading sinks from the Secret in the namespace
+func NewNotifier(reader client.Reader, namespace string, log *logrus.Logger) *Notifier {
+	return &Notifier{Reader: reader, Namespace: namespace, Log: log, queue: make(chan []Notification, queueSize)}
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// Notify queues notifications for sending; they are dropped and logged if the queue is full
+func (n *Notifier) Notify(notifications ...Notification) {
+	if n == nil || len(notifications) == 0 {
+		return
+	}
+	select {
+	case n.queue <- notifications:
+	default:
+		n.Log.WithField("notifications", len(notifications)).Error("notification queue is full, dropping notifications")
+	}
+}
+
+// Start sends queued notifications until ctx is done, it implements manager.Runnable
+func (n *Notifier) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notifications := <-n.queue:
+			n.send(ctx, notifications)
+		}
+	}
+}
+
+// send sends notifications to all sinks accepting their reasons; failures are logged, notifications are not retried
+func (n *Notifier) send(ctx context.Context, notifications []Notification) {
+	sinks, err := n.sinks(ctx)
+	if err != nil {
+		n.Log.WithError(err).Error("failed to read notification sinks")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	for _, sink := range sinks {
+		for _, notification := range notifications {
+			if !sink.accepts(notification.Reason) {
+				continue
+			}
+			if notification.Timestamp.IsZero() {
+				notification.Timestamp = time.Now().UTC()
+			}
+			if err := sink.Send(ctx, notification); err != nil {
+				n.Log.WithError(err).WithField("sink", sink.name).WithField("reason", notification.Reason).Error("failed to send notification")
+			}
+		}
+	}
+}
+
+type namedSink struct {
+	Sink
+	name    string
+	reasons []string
+}
+
+func (s namedSink) accepts(reason string) bool {
+	if len(s.reasons) == 0 {
+		return true
+	}
+	for _, r := range s.reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// sinks returns sinks listed in the Secret sorted by name; invalid entries are skipped
+func (n *Notifier) sinks(ctx context.Context) ([]namedSink, error) {
+	secret := new(corev1.Secret)
+	if err := n.Reader.Get(ctx, client.ObjectKey{Name: SecretName, Namespace: n.Namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: sendTimeout}
+	}
+
+	var sinks []namedSink
+	for name, value := range secret.Data {
+		var config SinkConfig
+		if err := yaml.UnmarshalStrict(value, &config); err != nil {
+			n.Log.WithError(err).WithField("sink", name).Error("invalid notification sink, skipping")
+			continue
+		}
+		newSink, ok := SinkTypes[config.Type]
+		if !ok || config.URL == "" {
+			n.Log.WithField("sink", name).WithField("type", config.Type).Error("notification sink of unknown type or without url, skipping")
+			continue
+		}
+		sinks = append(sinks, namedSink{Sink: newSink(config, httpClient), name: name, reasons: config.Reasons})
+	}
+	sort.Slice(sinks, func(i, j int) bool { return sinks[i].name < sinks[j].name })
+	return sinks, nil
+}
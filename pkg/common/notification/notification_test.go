@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Notifier", func() {
+	var (
+		server   *httptest.Server
+		mu       sync.Mutex
+		received map[string][]map[string]interface{}
+		cancel   context.CancelFunc
+	)
+
+	receivedOf := func(path string) func() []map[string]interface{} {
+		return func() []map[string]interface{} {
+			mu.Lock()
+			defer mu.Unlock()
+			return received[path]
+		}
+	}
+
+	BeforeEach(func() {
+		received = map[string][]map[string]interface{}{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			payload := map[string]interface{}{}
+			Expect(json.Unmarshal(body, &payload)).To(Succeed())
+			mu.Lock()
+			received[r.URL.Path] = append(received[r.URL.Path], payload)
+			mu.Unlock()
+			if r.URL.Path == "/broken" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	notifier := func(objects ...client.Object) *Notifier {
+		n := NewNotifier(fake.NewClientBuilder().WithObjects(objects...).Build(), "default", logrus.New())
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.TODO())
+		go func() { _ = n.Start(ctx) }()
+		return n
+	}
+	sinks := func(data map[string]string) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: SecretName, Namespace: "default"}, Data: map[string][]byte{}}
+		for name, value := range data {
+			secret.Data[name] = []byte(value)
+		}
+		return secret
+	}
+	failed := Notification{Reason: NodeFailedReason, Kind: "SriovFecClusterConfig", Name: "config", Node: "node-1",
+		Class: "DeviceError", Message: "pf_bb_config failed"}
+	degraded := Notification{Reason: DegradedReason, Kind: "SriovFecClusterConfig", Name: "config", Message: "invalid profile"}
+
+	It("sends notifications to webhook and slack sinks accepting their reasons", func() {
+		notifier(sinks(map[string]string{
+			"noc":     "type: webhook\nurl: " + server.URL + "/noc",
+			"chat":    "type: slack\nurl: " + server.URL + "/chat\nreasons: [NodeFailed]",
+			"broken":  "type: webhook\nurl: " + server.URL + "/broken",
+			"unknown": "type: pager\nurl: " + server.URL + "/unknown",
+			"invalid": "type: webhook\nendpoint: " + server.URL + "/invalid",
+		})).Notify(failed, degraded)
+
+		// sinks are sent to in order of their names, so noc is the last one
+		Eventually(receivedOf("/noc")).Should(HaveLen(2))
+		Expect(received["/broken"]).To(HaveLen(2))
+		Expect(received["/noc"][0]).To(HaveKeyWithValue("reason", NodeFailedReason))
+		Expect(received["/noc"][0]).To(HaveKeyWithValue("node", "node-1"))
+		Expect(received["/noc"][0]).To(HaveKey("timestamp"))
+		Expect(received["/noc"][1]).To(HaveKeyWithValue("reason", DegradedReason))
+		Expect(received["/chat"]).To(Equal([]map[string]interface{}{
+			{"text": "SriovFecClusterConfig/config on node node-1: NodeFailed (DeviceError): pf_bb_config failed"},
+		}))
+		Expect(received).ToNot(HaveKey("/unknown"))
+		Expect(received).ToNot(HaveKey("/invalid"))
+	})
+
+	It("sends nothing without the ConfigMap", func() {
+		notifier().Notify(failed)
+		var nilNotifier *Notifier
+		nilNotifier.Notify(failed)
+		Consistently(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(received)
+		}).Should(BeZero())
+	})
+})
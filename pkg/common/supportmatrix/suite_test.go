@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package supportmatrix
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSupportMatrix(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SupportMatrix suite")
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package supportmatrix lists combinations of OpenShift version, accelerator, PF driver and pf_bb_config version validated
+// with this release of the operator, so unsupported combinations are flagged at admission and by the daemon.
+package supportmatrix
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ConditionUnsupportedCombination is set on configs and NodeConfigs requesting a combination which is not listed in the
+// support matrix
+const ConditionUnsupportedCombination = "UnsupportedCombination"
+
+// Reasons of UnsupportedCombination condition
+const (
+	// SupportedReason is reported when the requested combination is listed in the support matrix
+	SupportedReason = "Supported"
+	// UnsupportedReason is reported when the requested combination is not listed in the support matrix
+	UnsupportedReason = "Unsupported"
+	// AllowedByAnnotationReason is reported when an unsupported combination is allowed with unsupported-ok annotation
+	AllowedByAnnotationReason = "AllowedByAnnotation"
+)
+
+// entry lists versions and drivers supported with the device; versions are matched by prefix
+type entry struct {
+	Device     string   `json:"device"`
+	OpenShift  []string `json:"openShift"`
+	PFDrivers  []string `json:"pfDrivers"`
+	PfBbConfig []string `json:"pfBbConfig"`
+}
+
+//go:embed matrix.yaml
+var matrixYAML []byte
+
+var matrix = mustParse(matrixYAML)
+
+func mustParse(data []byte) []entry {
+	var entries []entry
+	if err := yaml.UnmarshalStrict(data, &entries); err != nil {
+		panic(fmt.Sprintf("invalid support matrix: %v", err))
+	}
+	return entries
+}
+
+// Combination is a requested combination; empty fields are unknown and are not checked, e.g. OpenShift version on
+// vanilla Kubernetes or pf_bb_config version before the daemon reports it
+type Combination struct {
+	OpenShift  string
+	Device     string
+	PFDriver   string
+	PfBbConfig string
+}
+
+func (c Combination) String() string {
+	var parts []string
+	for _, part := range []struct{ name, value string }{
+		{"OpenShift", c.OpenShift}, {"device", c.Device}, {"pfDriver", c.PFDriver}, {"pf_bb_config", c.PfBbConfig},
+	} {
+		if part.value != "" {
+			parts = append(parts, fmt.Sprintf("%s %s", part.name, part.value))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Check returns an error if the combination is not listed in the support matrix. Devices unknown to the matrix are not
+// checked, as they are not configured by the operator anyway.
+func Check(c Combination) error {
+	known := false
+	for _, e := range matrix {
+		if e.Device != c.Device {
+			continue
+		}
+		known = true
+		if matchesPrefix(e.OpenShift, c.OpenShift) && matchesDriver(e.PFDrivers, c.PFDriver) && matchesPrefix(e.PfBbConfig, c.PfBbConfig) {
+			return nil
+		}
+	}
+	if !known {
+		return nil
+	}
+	return fmt.Errorf("combination of %s is not supported", c)
+}
+
+func matchesPrefix(prefixes []string, version string) bool {
+	if version == "" {
+		return true
+	}
+	version = strings.TrimPrefix(version, "v")
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(version, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesDriver(drivers []string, driver string) bool {
+	if driver == "" {
+		return true
+	}
+	for _, d := range drivers {
+		// pci-pf-stub and vfio-pci are also spelled with underscores
+		if strings.ReplaceAll(d, "_", "-") == strings.ReplaceAll(driver, "_", "-") {
+			return true
+		}
+	}
+	return false
+}
+
+var openShiftVersion atomic.Value
+
+// SetOpenShiftVersion records version of the cluster, it's set by the operator from ClusterVersion
+func SetOpenShiftVersion(version string) {
+	openShiftVersion.Store(version)
+}
+
+// OpenShiftVersion returns version of the cluster, or empty string when it isn't known, e.g. on vanilla Kubernetes
+func OpenShiftVersion() string {
+	version, _ := openShiftVersion.Load().(string)
+	return version
+}
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package supportmatrix
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("support matrix", func() {
+	AfterEach(func() {
+		SetOpenShiftVersion("")
+	})
+
+	It("accepts listed combinations", func() {
+		Expect(Check(Combination{OpenShift: "4.14.12", Device: "ACC100", PFDriver: "vfio-pci", PfBbConfig: "v24.03-0"})).To(Succeed())
+		Expect(Check(Combination{OpenShift: "4.16.0", Device: "VRB1", PFDriver: "vfio_pci", PfBbConfig: "24.03"})).To(Succeed())
+		Expect(Check(Combination{Device: "ACC200", PFDriver: "pci_pf_stub"})).To(Succeed())
+	})
+
+	It("rejects combinations which are not listed", func() {
+		Expect(Check(Combination{OpenShift: "4.11.3", Device: "ACC100", PFDriver: "vfio-pci"})).
+			To(MatchError("combination of OpenShift 4.11.3, device ACC100, pfDriver vfio-pci is not supported"))
+		Expect(Check(Combination{Device: "VRB2", PfBbConfig: "23.11"})).To(HaveOccurred())
+		Expect(Check(Combination{Device: "ACC200", PFDriver: "uio_pci_generic"})).To(HaveOccurred())
+	})
+
+	It("doesn't check unknown devices and versions", func() {
+		Expect(Check(Combination{OpenShift: "4.11.3", Device: "", PFDriver: "vfio-pci"})).To(Succeed())
+		Expect(Check(Combination{OpenShift: "4.11.3", Device: "ACC300"})).To(Succeed())
+		Expect(Check(Combination{Device: "ACC100"})).To(Succeed())
+	})
+
+	It("keeps version of the cluster", func() {
+		Expect(OpenShiftVersion()).To(BeEmpty())
+		SetOpenShiftVersion("4.15.2")
+		Expect(OpenShiftVersion()).To(Equal("4.15.2"))
+	})
+})
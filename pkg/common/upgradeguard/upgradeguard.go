@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package upgradeguard
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultInterval is a default period of ClusterVersion checks
+const DefaultInterval = 30 * time.Second
+
+// clusterVersionName is a name of the singleton ClusterVersion of OpenShift cluster
+const clusterVersionName = "version"
+
+// Guard periodically checks OpenShift ClusterVersion and reports whether the cluster is being upgraded, so disruptive
+// reconfigurations of accelerators can be deferred until the upgrade completes. On clusters without ClusterVersion
+// (e.g. vanilla Kubernetes) upgrade is never reported.
+type Guard struct {
+	// Reader reads ClusterVersion directly, so it doesn't have to be cached by the manager
+	Reader   client.Reader
+	Log      *logrus.Logger
+	Interval time.Duration
+
+	inProgress atomic.Bool
+}
+
+// +kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get
+
+// NeedLeaderElection makes the guard run along with reconcilers consulting it
+func (g *Guard) NeedLeaderElection() bool {
+	return true
+}
+
+func (g *Guard) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, g.check, g.Interval)
+	return nil
+}
+
+// InProgress returns true while the cluster is being upgraded; nil Guard never reports upgrade
+func (g *Guard) InProgress() bool {
+	return g != nil && g.inProgress.Load()
+}
+
+func (g *Guard) check(ctx context.Context) {
+	upgrading, err := g.isUpgrading(ctx)
+	if err != nil {
+		// last known state is kept, so reconfigurations aren't released by a transient error
+		g.Log.WithError(err).Error("failed to check ClusterVersion")
+		return
+	}
+	if g.inProgress.Swap(upgrading) != upgrading {
+		if upgrading {
+			g.Log.Info("cluster upgrade started - disruptive reconfigurations of accelerators are deferred")
+		} else {
+			g.Log.Info("cluster upgrade completed - deferred reconfigurations of accelerators are resumed")
+		}
+	}
+}
+
+func (g *Guard) isUpgrading(ctx context.Context) (bool, error) {
+	cv, err := getClusterVersion(ctx, g.Reader)
+	if cv == nil || err != nil {
+		return false, err
+	}
+	for _, condition := range cv.Status.Conditions {
+		if condition.Type == configv1.OperatorProgressing {
+			return condition.Status == configv1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
+
+// getClusterVersion returns ClusterVersion of the cluster, nil if the cluster doesn't have it
+func getClusterVersion(ctx context.Context, reader client.Reader) (*configv1.ClusterVersion, error) {
+	cv := new(configv1.ClusterVersion)
+	if err := reader.Get(ctx, client.ObjectKey{Name: clusterVersionName}, cv); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return cv, nil
+}
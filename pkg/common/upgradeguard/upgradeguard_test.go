@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package upgradeguard
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Guard", func() {
+	newGuard := func(objects ...runtime.Object) *Guard {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		return &Guard{Reader: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build(), Log: logrus.New()}
+	}
+
+	clusterVersion := func(progressing configv1.ConditionStatus) *configv1.ClusterVersion {
+		return &configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterVersionName},
+			Status: configv1.ClusterVersionStatus{Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue},
+				{Type: configv1.OperatorProgressing, Status: progressing},
+			}},
+		}
+	}
+
+	It("reports upgrade while ClusterVersion is progressing", func() {
+		g := newGuard(clusterVersion(configv1.ConditionTrue))
+		g.check(context.TODO())
+		Expect(g.InProgress()).To(BeTrue())
+	})
+
+	It("doesn't report upgrade once ClusterVersion stops progressing", func() {
+		g := newGuard(clusterVersion(configv1.ConditionFalse))
+		g.inProgress.Store(true)
+		g.check(context.TODO())
+		Expect(g.InProgress()).To(BeFalse())
+	})
+
+	It("never reports upgrade without ClusterVersion", func() {
+		g := newGuard()
+		g.check(context.TODO())
+		Expect(g.InProgress()).To(BeFalse())
+
+		var nilGuard *Guard
+		Expect(nilGuard.InProgress()).To(BeFalse())
+	})
+
+	It("records OpenShift version of the cluster", func() {
+		defer supportmatrix.SetOpenShiftVersion("")
+		cv := clusterVersion(configv1.ConditionFalse)
+		cv.Status.Desired.Version = "4.17.1"
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		v := &VersionRecorder{Reader: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cv).Build(), Log: logrus.New()}
+		Expect(v.NeedLeaderElection()).To(BeFalse())
+		v.record(context.TODO())
+		Expect(supportmatrix.OpenShiftVersion()).To(Equal("4.17.1"))
+	})
+})
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package upgradeguard
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
+)
+
+// VersionRecorder periodically records OpenShift version of the cluster for checks of the support matrix. Unlike Guard,
+// it runs in every replica of the operator, as webhooks checking the support matrix are served by each of them.
+type VersionRecorder struct {
+	// Reader reads ClusterVersion directly, so it doesn't have to be cached by the manager
+	Reader   client.Reader
+	Log      *logrus.Logger
+	Interval time.Duration
+}
+
+// NeedLeaderElection makes the recorder run in replicas which are not the leader
+func (v *VersionRecorder) NeedLeaderElection() bool {
+	return false
+}
+
+func (v *VersionRecorder) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, v.record, v.Interval)
+	return nil
+}
+
+func (v *VersionRecorder) record(ctx context.Context) {
+	cv, err := getClusterVersion(ctx, v.Reader)
+	if err != nil {
+		// last known version is kept
+		v.Log.WithError(err).Error("failed to read ClusterVersion")
+		return
+	}
+	if cv != nil {
+		supportmatrix.SetOpenShiftVersion(cv.Status.Desired.Version)
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// BindAddressEnv selects the IP address listeners of the operator and the daemon bind to
+var BindAddressEnv = SRIOV_PREFIX + "BIND_ADDRESS"
+
+// BindHost returns the IP address listeners should bind to. Empty address binds all addresses of both families, so
+// listeners are reachable on IPv4-only, IPv6-only and dual-stack clusters. "0.0.0.0" or "::" restrict them to a
+// single family.
+func BindHost() (string, error) {
+	host := os.Getenv(BindAddressEnv)
+	if host == "" {
+		return "", nil
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("invalid %s %q, IP address expected", BindAddressEnv, host)
+	}
+	return host, nil
+}
+
+// BindAddress returns host:port address of a listener for the BindHost, IPv6 addresses are enclosed in brackets
+func BindAddress(port int) (string, error) {
+	host, err := BindHost()
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BindAddress", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(BindAddressEnv)).To(Succeed())
+	})
+
+	It("binds all addresses of both families by default", func() {
+		Expect(BindAddress(8080)).To(Equal(":8080"))
+	})
+
+	It("encloses IPv6 address in brackets", func() {
+		Expect(os.Setenv(BindAddressEnv, "::")).To(Succeed())
+		Expect(BindAddress(8080)).To(Equal("[::]:8080"))
+		Expect(os.Setenv(BindAddressEnv, "fd00::1")).To(Succeed())
+		Expect(BindAddress(8081)).To(Equal("[fd00::1]:8081"))
+	})
+
+	It("accepts IPv4 address", func() {
+		Expect(os.Setenv(BindAddressEnv, "0.0.0.0")).To(Succeed())
+		Expect(BindAddress(8080)).To(Equal("0.0.0.0:8080"))
+	})
+
+	It("rejects address which is not an IP", func() {
+		Expect(os.Setenv(BindAddressEnv, "localhost")).To(Succeed())
+		_, err := BindAddress(8080)
+		Expect(err).To(HaveOccurred())
+	})
+})
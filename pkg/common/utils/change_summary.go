@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxChangeSummaryLength keeps the summary readable and the annotation small
+const maxChangeSummaryLength = 1024
+
+// ChangeSummary returns human-readable summary of changes between current and requested physical functions (slices of
+// PhysicalFunctionConfigExt of any API version), e.g. "0000:14:00.0 vfAmount 4→8; 0000:15:00.0 added". Fields are
+// reported by their JSON path relative to the physical function.
+func ChangeSummary(current, requested interface{}) string {
+	currentPFs, requestedPFs := flattenPhysicalFunctions(current), flattenPhysicalFunctions(requested)
+
+	var changes []string
+	for _, pci := range sortedKeys(currentPFs) {
+		if _, ok := requestedPFs[pci]; !ok {
+			changes = append(changes, pci+" removed")
+		}
+	}
+	for _, pci := range sortedKeys(requestedPFs) {
+		currentFields, ok := currentPFs[pci]
+		if !ok {
+			changes = append(changes, pci+" added")
+			continue
+		}
+		requestedFields := requestedPFs[pci]
+		paths := map[string]bool{}
+		for path := range currentFields {
+			paths[path] = true
+		}
+		for path := range requestedFields {
+			paths[path] = true
+		}
+		for _, path := range sortedKeys(paths) {
+			if from, to := valueOrNone(currentFields, path), valueOrNone(requestedFields, path); from != to {
+				changes = append(changes, fmt.Sprintf("%s %s %s→%s", pci, path, from, to))
+			}
+		}
+	}
+
+	summary := strings.Join(changes, "; ")
+	if len(summary) > maxChangeSummaryLength {
+		summary = summary[:maxChangeSummaryLength] + "…"
+	}
+	return summary
+}
+
+// flattenPhysicalFunctions returns fields of physical functions keyed by their PCI address and JSON path
+func flattenPhysicalFunctions(pfs interface{}) map[string]map[string]string {
+	var decoded []map[string]interface{}
+	if raw, err := json.Marshal(pfs); err == nil {
+		_ = json.Unmarshal(raw, &decoded)
+	}
+
+	flattened := map[string]map[string]string{}
+	for _, pf := range decoded {
+		pci, _ := pf["pciAddress"].(string)
+		delete(pf, "pciAddress")
+		fields := map[string]string{}
+		flatten("", pf, fields)
+		flattened[pci] = fields
+	}
+	return flattened
+}
+
+func flatten(prefix string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, nested, fields)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), nested, fields)
+		}
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
+
+func valueOrNone(fields map[string]string, path string) string {
+	if value, ok := fields[path]; ok {
+		return value
+	}
+	return "<none>"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChangeSummary", func() {
+	type queueGroups struct {
+		NumQueueGroups int `json:"numQueueGroups"`
+	}
+	type physicalFunction struct {
+		PCIAddress  string                 `json:"pciAddress"`
+		VFAmount    int                    `json:"vfAmount"`
+		BBDevConfig map[string]queueGroups `json:"bbDevConfig,omitempty"`
+	}
+
+	It("summarizes changed fields by their path", func() {
+		current := []physicalFunction{{PCIAddress: "0000:14:00.0", VFAmount: 4, BBDevConfig: map[string]queueGroups{"uplink4G": {2}}}}
+		requested := []physicalFunction{{PCIAddress: "0000:14:00.0", VFAmount: 8, BBDevConfig: map[string]queueGroups{"uplink4G": {4}}}}
+		Expect(ChangeSummary(current, requested)).To(Equal("0000:14:00.0 bbDevConfig.uplink4G.numQueueGroups 2→4; 0000:14:00.0 vfAmount 4→8"))
+	})
+
+	It("summarizes added and removed physical functions", func() {
+		current := []physicalFunction{{PCIAddress: "0000:14:00.0", VFAmount: 4}}
+		requested := []physicalFunction{{PCIAddress: "0000:15:00.0", VFAmount: 4}}
+		Expect(ChangeSummary(current, requested)).To(Equal("0000:14:00.0 removed; 0000:15:00.0 added"))
+	})
+
+	It("reports fields missing on one side", func() {
+		current := []physicalFunction{{PCIAddress: "0000:14:00.0"}}
+		requested := []physicalFunction{{PCIAddress: "0000:14:00.0", BBDevConfig: map[string]queueGroups{"qfft": {4}}}}
+		Expect(ChangeSummary(current, requested)).To(Equal("0000:14:00.0 bbDevConfig.qfft.numQueueGroups <none>→4"))
+	})
+
+	It("returns empty summary of equal physical functions", func() {
+		pfs := []physicalFunction{{PCIAddress: "0000:14:00.0", VFAmount: 4}}
+		Expect(ChangeSummary(pfs, pfs)).To(BeEmpty())
+	})
+
+	It("truncates long summary", func() {
+		var requested []physicalFunction
+		for i := 0; i < 100; i++ {
+			requested = append(requested, physicalFunction{PCIAddress: strings.Repeat("0", i+1)})
+		}
+		Expect(len(ChangeSummary(nil, requested))).To(BeNumerically("<=", maxChangeSummaryLength+len("…")))
+	})
+})
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Conditions collects conditions of objects set during a reconcile, keyed by names of the objects. Conditions of an
+// object start from its current conditions, so lastTransitionTime of a condition which status doesn't change is kept
+// by meta.SetStatusCondition and the status isn't updated on each reconcile.
+type Conditions map[string][]metav1.Condition
+
+// Of returns conditions collected for the object, or a copy of its current conditions if none was set yet
+func (c Conditions) Of(name string, current []metav1.Condition) []metav1.Condition {
+	if conditions, ok := c[name]; ok {
+		return conditions
+	}
+	return append([]metav1.Condition{}, current...)
+}
+
+// Set sets the condition of the object
+func (c Conditions) Set(name string, current []metav1.Condition, condition metav1.Condition) {
+	conditions := c.Of(name, current)
+	meta.SetStatusCondition(&conditions, condition)
+	c[name] = conditions
+}
+
+// Remove removes the condition of given type from conditions of the object
+func (c Conditions) Remove(name string, current []metav1.Condition, conditionType string) {
+	conditions := c.Of(name, current)
+	meta.RemoveStatusCondition(&conditions, conditionType)
+	c[name] = conditions
+}
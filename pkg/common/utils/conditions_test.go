@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Conditions", func() {
+	transitioned := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	current := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Configured", LastTransitionTime: transitioned},
+		{Type: "Paused", Status: metav1.ConditionTrue, Reason: "Paused", LastTransitionTime: transitioned},
+	}
+
+	It("starts from current conditions and keeps lastTransitionTime of conditions which status doesn't change", func() {
+		conditions := Conditions{}
+		conditions.Set("config", current, metav1.Condition{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Configured", Message: "2 nodes configured"})
+		conditions.Remove("config", current, "Paused")
+
+		Expect(conditions["config"]).To(HaveLen(1))
+		ready := meta.FindStatusCondition(conditions["config"], "Ready")
+		Expect(ready.Message).To(Equal("2 nodes configured"))
+		Expect(ready.LastTransitionTime).To(Equal(transitioned))
+		Expect(current).To(HaveLen(2))
+	})
+
+	It("updates lastTransitionTime of conditions which status changes", func() {
+		conditions := Conditions{}
+		conditions.Set("config", current, metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "Paused"})
+
+		Expect(meta.FindStatusCondition(conditions["config"], "Ready").LastTransitionTime).ToNot(Equal(transitioned))
+		Expect(meta.FindStatusCondition(conditions["config"], "Paused")).ToNot(BeNil())
+		Expect(conditions.Of("other", current)).To(Equal(current))
+	})
+})
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"k8s.io/client-go/rest"
+)
+
+var (
+	// MaxConcurrentReconcilesEnv sets number of workers of controllers reconciling each object on its own
+	MaxConcurrentReconcilesEnv = SRIOV_PREFIX + "MAX_CONCURRENT_RECONCILES"
+	// ClientQPSEnv and ClientBurstEnv set client-side rate limits of requests to the API server
+	ClientQPSEnv   = SRIOV_PREFIX + "CLIENT_QPS"
+	ClientBurstEnv = SRIOV_PREFIX + "CLIENT_BURST"
+)
+
+// MaxConcurrentReconciles returns number of workers configured with MaxConcurrentReconcilesEnv, 1 if it's not set
+func MaxConcurrentReconciles() (int, error) {
+	value := os.Getenv(MaxConcurrentReconcilesEnv)
+	if value == "" {
+		return 1, nil
+	}
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 1 {
+		return 0, fmt.Errorf("invalid %s %q, positive integer expected", MaxConcurrentReconcilesEnv, value)
+	}
+	return workers, nil
+}
+
+// SetClientRateLimits overrides QPS and burst of the config with values configured with ClientQPSEnv and ClientBurstEnv,
+// client-go defaults are kept for values which are not set
+func SetClientRateLimits(config *rest.Config) error {
+	if value := os.Getenv(ClientQPSEnv); value != "" {
+		qps, err := strconv.ParseFloat(value, 32)
+		if err != nil || qps <= 0 {
+			return fmt.Errorf("invalid %s %q, positive number expected", ClientQPSEnv, value)
+		}
+		config.QPS = float32(qps)
+	}
+	if value := os.Getenv(ClientBurstEnv); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst < 1 {
+			return fmt.Errorf("invalid %s %q, positive integer expected", ClientBurstEnv, value)
+		}
+		config.Burst = burst
+	}
+	return nil
+}
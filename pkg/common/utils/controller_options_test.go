@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("ControllerOptions", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(MaxConcurrentReconcilesEnv)).To(Succeed())
+		Expect(os.Unsetenv(ClientQPSEnv)).To(Succeed())
+		Expect(os.Unsetenv(ClientBurstEnv)).To(Succeed())
+	})
+
+	It("runs single worker by default", func() {
+		Expect(MaxConcurrentReconciles()).To(Equal(1))
+		Expect(os.Setenv(MaxConcurrentReconcilesEnv, "4")).To(Succeed())
+		Expect(MaxConcurrentReconciles()).To(Equal(4))
+	})
+
+	It("rejects invalid number of workers", func() {
+		Expect(os.Setenv(MaxConcurrentReconcilesEnv, "0")).To(Succeed())
+		_, err := MaxConcurrentReconciles()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("overrides client rate limits which are set", func() {
+		config := &rest.Config{QPS: 5, Burst: 10}
+		Expect(SetClientRateLimits(config)).To(Succeed())
+		Expect(config.QPS).To(BeEquivalentTo(5))
+		Expect(config.Burst).To(Equal(10))
+
+		Expect(os.Setenv(ClientQPSEnv, "50.5")).To(Succeed())
+		Expect(os.Setenv(ClientBurstEnv, "100")).To(Succeed())
+		Expect(SetClientRateLimits(config)).To(Succeed())
+		Expect(config.QPS).To(BeEquivalentTo(50.5))
+		Expect(config.Burst).To(Equal(100))
+	})
+
+	It("rejects invalid client rate limits", func() {
+		Expect(os.Setenv(ClientBurstEnv, "many")).To(Succeed())
+		Expect(SetClientRateLimits(&rest.Config{})).ToNot(Succeed())
+	})
+})
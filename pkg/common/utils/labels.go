@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Recommended labels, see https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+const (
+	LabelName      = "app.kubernetes.io/name"
+	LabelInstance  = "app.kubernetes.io/instance"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelPartOf    = "app.kubernetes.io/part-of"
+
+	ManagedBy = "sriov-fec-operator"
+	PartOf    = "sriov-fec"
+
+	// values of LabelName for objects created by the operator
+	SriovFecNodeConfigName = "sriovfecnodeconfig"
+	SriovVrbNodeConfigName = "sriovvrbnodeconfig"
+)
+
+// StandardLabels returns labels stamped on every object created by the operator.
+// name identifies kind of the object, owner is a name of the CR the object was created for.
+func StandardLabels(name, owner string) map[string]string {
+	return map[string]string{
+		LabelName:      name,
+		LabelInstance:  owner,
+		LabelManagedBy: ManagedBy,
+		LabelPartOf:    PartOf,
+	}
+}
+
+// SetStandardLabels adds StandardLabels to obj, other labels are preserved
+func SetStandardLabels(obj metav1.Object, name, owner string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range StandardLabels(name, owner) {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+}
+
+// HasStandardLabels returns true if obj carries all StandardLabels
+func HasStandardLabels(obj metav1.Object, name, owner string) bool {
+	labels := obj.GetLabels()
+	for k, v := range StandardLabels(name, owner) {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// ManagedObjects returns selector matching objects of given name created by the operator
+func ManagedObjects(name string) client.MatchingLabels {
+	return client.MatchingLabels{
+		LabelName:      name,
+		LabelManagedBy: ManagedBy,
+	}
+}
@@ -32,8 +32,13 @@ const (
 	VFIO_PCI                        = "vfio-pci"
 	VFIO_PCI_UNDERSCORE             = "vfio_pci"
 	IGB_UIO                         = "igb_uio"
+	// UNCONFIGURED_TAINT_KEY taints nodes which accelerators are not configured yet, when enabled
+	UNCONFIGURED_TAINT_KEY = "fec.intel.com/unconfigured"
 )
 
+// KnownDrivers are PF and VF drivers which availability on the node is reported by the daemon
+var KnownDrivers = []string{VFIO_PCI, PCI_PF_STUB_DASH, IGB_UIO}
+
 func LoadDiscoveryConfig(cfgPath string) (AcceleratorDiscoveryConfig, error) {
 	var cfg AcceleratorDiscoveryConfig
 	file, err := os.Open(filepath.Clean(cfgPath))
@@ -75,9 +80,9 @@ func SetOsEnvIfNotSet(key, value string, logger logr.Logger) error {
 	return os.Setenv(key, value)
 }
 
-func IsSingleNodeCluster(c client.Client) (bool, error) {
+func IsSingleNodeCluster(ctx context.Context, c client.Client) (bool, error) {
 	nodeList := &corev1.NodeList{}
-	err := c.List(context.TODO(), nodeList)
+	err := c.List(ctx, nodeList)
 	if err != nil {
 		return false, err
 	}
@@ -9,6 +9,9 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func TestMain(t *testing.T) {
@@ -68,3 +71,17 @@ var _ = Describe("Utils", func() {
 		})
 	})
 })
+
+var _ = Describe("StandardLabels", func() {
+	var _ = It("will add standard labels preserving existing ones", func() {
+		obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}}}
+		Expect(HasStandardLabels(obj, "name", "owner")).To(BeFalse())
+
+		SetStandardLabels(obj, "name", "owner")
+
+		Expect(HasStandardLabels(obj, "name", "owner")).To(BeTrue())
+		Expect(obj.Labels).To(HaveKeyWithValue("app", "test"))
+		Expect(obj.Labels).To(HaveKeyWithValue(LabelManagedBy, ManagedBy))
+		Expect(labels.SelectorFromSet(labels.Set(ManagedObjects("name"))).Matches(labels.Set(obj.Labels))).To(BeTrue())
+	})
+})
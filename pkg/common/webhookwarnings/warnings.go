@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package webhookwarnings adds admission warnings to validating webhooks, which webhook.Validator cannot return
+package webhookwarnings
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Warner is an object which warns about its admitted content, e.g. discouraged settings
+type Warner interface {
+	runtime.Object
+	Warnings() []string
+}
+
+// handler adds warnings of the admitted object to responses of the wrapped handler which allow it
+type handler struct {
+	admission.Handler
+	object  Warner
+	decoder *admission.Decoder
+}
+
+// ValidatingWebhookFor returns validating webhook of the validator which warns about created and updated objects.
+// It has to be registered at the validating path of the type before the webhook builder, which skips it then.
+func ValidatingWebhookFor(validator interface {
+	admission.Validator
+	Warner
+}) *admission.Webhook {
+	webhook := admission.ValidatingWebhookFor(validator)
+	webhook.Handler = &handler{Handler: webhook.Handler, object: validator}
+	return webhook
+}
+
+// InjectDecoder injects the decoder into the wrapped handler as well
+func (h *handler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	_, err := admission.InjectDecoderInto(d, h.Handler)
+	return err
+}
+
+func (h *handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	response := h.Handler.Handle(ctx, req)
+	if !response.Allowed || (req.Operation != admissionv1.Create && req.Operation != admissionv1.Update) {
+		return response
+	}
+	object := h.object.DeepCopyObject().(Warner)
+	if err := h.decoder.DecodeRaw(req.Object, object); err != nil {
+		return response
+	}
+	return response.WithWarnings(object.Warnings()...)
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	pfBbConfigSocketDir = "/tmp"
+	artifactsGCInterval = 10 * time.Minute
+	// artifacts younger than staleArtifactMinAge are never collected, as they may belong to configuration in progress
+	staleArtifactMinAge = 10 * time.Minute
+)
+
+// ArtifactsCollector periodically removes leftovers of crashed or stopped pf_bb_config runs:
+// unix sockets, generated ini files and downloaded FFT archives
+type ArtifactsCollector struct {
+	log                 *logrus.Logger
+	isPfBbConfigRunning func(ctx context.Context, pciAddress string) bool
+}
+
+func NewArtifactsCollector(log *logrus.Logger) *ArtifactsCollector {
+	return &ArtifactsCollector{
+		log: log,
+		isPfBbConfigRunning: func(ctx context.Context, pciAddress string) bool {
+			return !pfBbConfigProcIsDead(ctx, log, pciAddress)
+		},
+	}
+}
+
+// Start implements manager.Runnable
+func (c *ArtifactsCollector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, c.Collect, artifactsGCInterval)
+	return nil
+}
+
+// Collect executes single garbage collection pass
+func (c *ArtifactsCollector) Collect(ctx context.Context) {
+	c.collect(filepath.Join(pfBbConfigSocketDir, "pf_bb_config.*.sock"), func(path string) bool {
+		pciAddress := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "pf_bb_config."), ".sock")
+		return !c.isPfBbConfigRunning(ctx, pciAddress)
+	})
+
+	c.collect(filepath.Join(workdir, "*.ini"), func(path string) bool {
+		pciAddress := strings.TrimSuffix(filepath.Base(path), ".ini")
+		return isOldEnough(path) && !c.isPfBbConfigRunning(ctx, pciAddress)
+	})
+
+	c.collect(filepath.Join(artifactsFolder, "*.tar.gz"), isOldEnough)
+}
+
+func (c *ArtifactsCollector) collect(pattern string, isStale func(path string) bool) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		c.log.WithError(err).WithField("pattern", pattern).Error("failed to list artifacts")
+		return
+	}
+
+	for _, path := range matches {
+		if !isStale(path) {
+			continue
+		}
+		if err := removeArtifact(path, c.log); err != nil {
+			c.log.WithError(err).WithField("path", path).Warn("failed to remove stale artifact")
+		}
+	}
+}
+
+func isOldEnough(path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+	return time.Since(info.ModTime()) > staleArtifactMinAge
+}
+
+func removeArtifact(path string, log *logrus.Logger) error {
+	log.WithField("path", path).Info("removing stale artifact")
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removePfBbConfigSocket removes socket file which pf_bb_config does not clean up when being killed
+func removePfBbConfigSocket(pciAddress string, log *logrus.Logger) error {
+	return removeArtifact(filepath.Join(pfBbConfigSocketDir, fmt.Sprintf("pf_bb_config.%s.sock", pciAddress)), log)
+}
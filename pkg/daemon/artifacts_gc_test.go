@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("ArtifactsCollector", func() {
+	var (
+		dir       string
+		collector *ArtifactsCollector
+		running   map[string]bool
+
+		origSocketDir, origWorkdir, origArtifactsFolder = pfBbConfigSocketDir, workdir, artifactsFolder
+	)
+
+	touch := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte{}, 0600)).To(Succeed())
+		mtime := time.Now().Add(-age)
+		Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp(testTmpFolder, "gc")
+		Expect(err).ToNot(HaveOccurred())
+
+		pfBbConfigSocketDir, workdir, artifactsFolder = dir, dir, dir
+		running = map[string]bool{}
+		collector = &ArtifactsCollector{
+			log: logrus.New(),
+			isPfBbConfigRunning: func(ctx context.Context, pciAddress string) bool {
+				return running[pciAddress]
+			},
+		}
+	})
+
+	AfterEach(func() {
+		pfBbConfigSocketDir, workdir, artifactsFolder = origSocketDir, origWorkdir, origArtifactsFolder
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("removes sockets of pf_bb_config which is not running", func() {
+		running["0000:af:00.0"] = true
+		alive := touch("pf_bb_config.0000:af:00.0.sock", 0)
+		stale := touch("pf_bb_config.0000:b0:00.0.sock", 0)
+
+		collector.Collect(context.TODO())
+
+		Expect(alive).To(BeAnExistingFile())
+		Expect(stale).ToNot(BeAnExistingFile())
+	})
+
+	It("removes old ini files of pf_bb_config which is not running", func() {
+		running["0000:af:00.0"] = true
+		inUse := touch("0000:af:00.0.ini", time.Hour)
+		fresh := touch("0000:b0:00.0.ini", 0)
+		orphaned := touch("0000:b1:00.0.ini", time.Hour)
+
+		collector.Collect(context.TODO())
+
+		Expect(inUse).To(BeAnExistingFile())
+		Expect(fresh).To(BeAnExistingFile())
+		Expect(orphaned).ToNot(BeAnExistingFile())
+	})
+
+	It("removes old downloaded archives", func() {
+		fresh := touch("fresh.tar.gz", 0)
+		old := touch("old.tar.gz", time.Hour)
+		other := touch("srs_fft_windows_coefficient.bin", time.Hour)
+
+		collector.Collect(context.TODO())
+
+		Expect(fresh).To(BeAnExistingFile())
+		Expect(old).ToNot(BeAnExistingFile())
+		Expect(other).To(BeAnExistingFile())
+	})
+})
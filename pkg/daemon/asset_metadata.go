@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+const (
+	// assetMetadataConfigMapName is a name of ConfigMap listing metadata of accelerators keyed by their serial numbers
+	assetMetadataConfigMapName = "sriov-fec-asset-metadata"
+
+	// extended capabilities of PCIe devices start after the legacy configuration space
+	pcieExtendedCapabilitiesOffset = 0x100
+	pcieDeviceSerialNumberCapID    = 0x0003
+)
+
+// readSerialNumber returns PCIe Device Serial Number of the device, formatted like by lspci, or empty string if the
+// device doesn't report it or its extended configuration space cannot be read
+func readSerialNumber(pciAddress string) string {
+	config, err := os.ReadFile(filepath.Join(sysBusPciDevices, pciAddress, "config"))
+	if err != nil {
+		return ""
+	}
+	return deviceSerialNumber(config)
+}
+
+// deviceSerialNumber looks up Device Serial Number capability in the list of extended capabilities of the PCIe
+// configuration space
+func deviceSerialNumber(config []byte) string {
+	// each capability is visited at most once, so a malformed list cannot loop forever
+	visited := map[int]bool{}
+	for offset := pcieExtendedCapabilitiesOffset; offset >= pcieExtendedCapabilitiesOffset && offset+12 <= len(config) && !visited[offset]; {
+		visited[offset] = true
+		header := binary.LittleEndian.Uint32(config[offset:])
+		if header == 0 || header == 0xffffffff {
+			return ""
+		}
+		if header&0xffff == pcieDeviceSerialNumberCapID {
+			serial := binary.LittleEndian.Uint64(config[offset+4:])
+			var formatted string
+			for i := 7; i >= 0; i-- {
+				formatted += fmt.Sprintf("%02x", byte(serial>>(8*i)))
+				if i != 0 {
+					formatted += "-"
+				}
+			}
+			return formatted
+		}
+		// the lowest two bits of the next capability offset are reserved
+		offset = int(header>>20) &^ 3
+	}
+	return ""
+}
+
+// readAssetMetadata returns metadata of accelerators listed in sriov-fec-asset-metadata ConfigMap, keyed by serial
+// number; invalid entries are logged and skipped, so they don't block reporting of the inventory
+func readAssetMetadata(ctx context.Context, c client.Reader, namespace string, log *logrus.Logger) map[string]fec.AssetMetadata {
+	cm := new(corev1.ConfigMap)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: assetMetadataConfigMapName}, cm); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.WithError(err).Warn("failed to read asset metadata of accelerators")
+		}
+		return nil
+	}
+
+	metadata := map[string]fec.AssetMetadata{}
+	for serialNumber, value := range cm.Data {
+		var asset fec.AssetMetadata
+		if err := yaml.UnmarshalStrict([]byte(value), &asset); err != nil {
+			log.WithError(err).WithField("serialNumber", serialNumber).Warn("invalid asset metadata of accelerator")
+			continue
+		}
+		metadata[serialNumber] = asset
+	}
+	return metadata
+}
+
+// withAssetMetadata merges asset metadata listed by serial numbers of accelerators into the inventory
+func withAssetMetadata(ctx context.Context, c client.Reader, namespace string, inv *fec.NodeInventory, log *logrus.Logger) {
+	metadata := readAssetMetadata(ctx, c, namespace, log)
+	for i := range inv.SriovAccelerators {
+		acc := &inv.SriovAccelerators[i]
+		if asset, ok := metadata[acc.SerialNumber]; ok && acc.SerialNumber != "" {
+			acc.Asset = asset.DeepCopy()
+		}
+	}
+}
+
+// vrbWithAssetMetadata merges asset metadata listed by serial numbers of accelerators into the inventory
+func vrbWithAssetMetadata(ctx context.Context, c client.Reader, namespace string, inv *vrbv1.NodeInventory, log *logrus.Logger) {
+	metadata := readAssetMetadata(ctx, c, namespace, log)
+	for i := range inv.SriovAccelerators {
+		acc := &inv.SriovAccelerators[i]
+		if asset, ok := metadata[acc.SerialNumber]; ok && acc.SerialNumber != "" {
+			acc.Asset = &vrbv1.AssetMetadata{Site: asset.Site, Rack: asset.Rack, WarrantyExpiry: asset.WarrantyExpiry}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("Asset metadata", func() {
+	It("reads Device Serial Number from extended capabilities", func() {
+		config := make([]byte, 0x1000)
+		// AER capability at 0x100 pointing to DSN capability at 0x148
+		copy(config[0x100:], []byte{0x01, 0x00, 0x81, 0x14})
+		copy(config[0x148:], []byte{0x03, 0x00, 0x01, 0x00, 0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01})
+		Expect(deviceSerialNumber(config)).To(Equal("01-02-03-04-05-06-07-08"))
+
+		// reserved bits of the next capability offset are set
+		copy(config[0x100:], []byte{0x01, 0x00, 0xb1, 0x14})
+		Expect(deviceSerialNumber(config)).To(Equal("01-02-03-04-05-06-07-08"))
+
+		Expect(deviceSerialNumber(config[:0x100])).To(BeEmpty())
+		Expect(deviceSerialNumber(make([]byte, 0x1000))).To(BeEmpty())
+
+		// capability pointing to itself
+		copy(config[0x100:], []byte{0x01, 0x00, 0x01, 0x10})
+		Expect(deviceSerialNumber(config)).To(BeEmpty())
+	})
+
+	It("merges asset metadata listed by serial number into the inventory", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: assetMetadataConfigMapName, Namespace: "default"},
+			Data: map[string]string{
+				"01-02-03-04-05-06-07-08": `{"site": "dc1", "rack": "r12", "warrantyExpiry": "2027-01-31"}`,
+				"11-12-13-14-15-16-17-18": "unknown: field",
+			},
+		}
+		c := fake.NewClientBuilder().WithObjects(cm).Build()
+		inv := &fec.NodeInventory{SriovAccelerators: []fec.SriovAccelerator{
+			{PCIAddress: "0000:14:00.0", SerialNumber: "01-02-03-04-05-06-07-08"},
+			{PCIAddress: "0000:15:00.0", SerialNumber: "11-12-13-14-15-16-17-18"},
+			{PCIAddress: "0000:16:00.0"},
+		}}
+
+		withAssetMetadata(context.TODO(), c, "default", inv, logrus.New())
+		Expect(inv.SriovAccelerators[0].Asset).To(Equal(&fec.AssetMetadata{Site: "dc1", Rack: "r12", WarrantyExpiry: "2027-01-31"}))
+		Expect(inv.SriovAccelerators[1].Asset).To(BeNil())
+		Expect(inv.SriovAccelerators[2].Asset).To(BeNil())
+
+		inv.SriovAccelerators[0].Asset = nil
+		withAssetMetadata(context.TODO(), fake.NewClientBuilder().Build(), "default", inv, logrus.New())
+		Expect(inv.SriovAccelerators[0].Asset).To(BeNil())
+	})
+})
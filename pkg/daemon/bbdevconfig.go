@@ -4,6 +4,7 @@
 package daemon
 
 import (
+	"context"
 	"crypto/x509"
 	"fmt"
 	"net/http"
@@ -50,6 +51,7 @@ func NewPfBBConfigController(log *logrus.Logger, sharedVfioToken string) *pfBBCo
 	return &pfBBConfigController{
 		log:             log,
 		sharedVfioToken: sharedVfioToken,
+		limits:          loadPfBBConfigLimits(log),
 		fftUpdater: &fftUpdater{
 			log:        log,
 			httpClient: httpClient,
@@ -60,6 +62,7 @@ func NewPfBBConfigController(log *logrus.Logger, sharedVfioToken string) *pfBBCo
 type pfBBConfigController struct {
 	log             *logrus.Logger
 	sharedVfioToken string
+	limits          pfBBConfigLimits
 	fftUpdater      *fftUpdater
 }
 
@@ -78,7 +81,7 @@ func getTlsCert(log *logrus.Logger) *x509.Certificate {
 	return cert
 }
 
-func (p *pfBBConfigController) initializePfBBConfig(acc sriovv2.SriovAccelerator, pf *sriovv2.PhysicalFunctionConfigExt) error {
+func (p *pfBBConfigController) initializePfBBConfig(ctx context.Context, acc sriovv2.SriovAccelerator, pf *sriovv2.PhysicalFunctionConfigExt) error {
 	if pf.BBDevConfig.N3000 != nil || pf.BBDevConfig.ACC100 != nil || pf.BBDevConfig.ACC200 != nil {
 		bbdevConfigFilepath := filepath.Join(workdir, fmt.Sprintf("%s.ini", pf.PCIAddress))
 		if err := generateBBDevConfigFile(pf.BBDevConfig, bbdevConfigFilepath); err != nil {
@@ -86,7 +89,7 @@ func (p *pfBBConfigController) initializePfBBConfig(acc sriovv2.SriovAccelerator
 			return err
 		}
 
-		if err := p.configureDevice(acc, pf, bbdevConfigFilepath); err != nil {
+		if err := p.configureDevice(ctx, acc, pf, bbdevConfigFilepath); err != nil {
 			p.log.WithError(err).WithField("pci", pf.PCIAddress).Error("failed to configure device's queues")
 			return err
 		}
@@ -97,7 +100,7 @@ func (p *pfBBConfigController) initializePfBBConfig(acc sriovv2.SriovAccelerator
 	return nil
 }
 
-func (p *pfBBConfigController) configureDevice(acc sriovv2.SriovAccelerator, pf *sriovv2.PhysicalFunctionConfigExt, bbdevConfigFilepath string) error {
+func (p *pfBBConfigController) configureDevice(ctx context.Context, acc sriovv2.SriovAccelerator, pf *sriovv2.PhysicalFunctionConfigExt, bbdevConfigFilepath string) error {
 	deviceName := supportedAccelerators.Devices[acc.DeviceID]
 	var err error
 	if deviceName == "ACC200" {
@@ -115,16 +118,16 @@ func (p *pfBBConfigController) configureDevice(acc sriovv2.SriovAccelerator, pf
 		pfConfigAppFilepath = "/sriov_workdir/pf_bb_config"
 	}
 	p.log.Infof("pf-bb-config file path is : %s", pfConfigAppFilepath)
-	logLinkStatus(pf.PCIAddress, p.log)
+	logLinkStatus(ctx, pf.PCIAddress, p.log)
 	var token *string
 	if strings.EqualFold(pf.PFDriver, utils.VFIO_PCI) {
 		token = &p.sharedVfioToken
 	}
 
-	return p.runPFConfig(deviceName, bbdevConfigFilepath, pf.PCIAddress, token)
+	return p.runPFConfig(ctx, deviceName, bbdevConfigFilepath, pf.PCIAddress, token)
 }
 
-func (p *pfBBConfigController) VrbinitializePfBBConfig(acc vrbv1.SriovAccelerator, pf *vrbv1.PhysicalFunctionConfigExt) error {
+func (p *pfBBConfigController) VrbinitializePfBBConfig(ctx context.Context, acc vrbv1.SriovAccelerator, pf *vrbv1.PhysicalFunctionConfigExt) error {
 	if pf.BBDevConfig.VRB1 != nil || pf.BBDevConfig.VRB2 != nil {
 		bbdevConfigFilepath := filepath.Join(workdir, fmt.Sprintf("%s.ini", pf.PCIAddress))
 		if err := generateVrbBBDevConfigFile(pf.BBDevConfig, bbdevConfigFilepath); err != nil {
@@ -132,7 +135,7 @@ func (p *pfBBConfigController) VrbinitializePfBBConfig(acc vrbv1.SriovAccelerato
 			return err
 		}
 
-		if err := p.configureVrbDevice(acc, pf, bbdevConfigFilepath); err != nil {
+		if err := p.configureVrbDevice(ctx, acc, pf, bbdevConfigFilepath); err != nil {
 			p.log.WithError(err).WithField("pci", pf.PCIAddress).Error("failed to configure device's queues")
 			return err
 		}
@@ -144,7 +147,7 @@ func (p *pfBBConfigController) VrbinitializePfBBConfig(acc vrbv1.SriovAccelerato
 	return nil
 }
 
-func (p *pfBBConfigController) configureVrbDevice(acc vrbv1.SriovAccelerator, pf *vrbv1.PhysicalFunctionConfigExt, bbdevConfigFilepath string) error {
+func (p *pfBBConfigController) configureVrbDevice(ctx context.Context, acc vrbv1.SriovAccelerator, pf *vrbv1.PhysicalFunctionConfigExt, bbdevConfigFilepath string) error {
 	deviceName := VrbsupportedAccelerators.Devices[acc.DeviceID]
 
 	switch deviceName {
@@ -170,13 +173,13 @@ func (p *pfBBConfigController) configureVrbDevice(acc vrbv1.SriovAccelerator, pf
 		pfConfigAppFilepath = "/sriov_workdir/pf_bb_config"
 	}
 
-	logLinkStatus(pf.PCIAddress, p.log)
+	logLinkStatus(ctx, pf.PCIAddress, p.log)
 	var token *string
 	if strings.EqualFold(pf.PFDriver, utils.VFIO_PCI) {
 		token = &p.sharedVfioToken
 	}
 
-	return p.runPFConfig(deviceName, bbdevConfigFilepath, pf.PCIAddress, token)
+	return p.runPFConfig(ctx, deviceName, bbdevConfigFilepath, pf.PCIAddress, token)
 }
 
 func (p *pfBBConfigController) updateFftWindowsCoefficientFilepath(deviceName string, fftLutConfig *vrbv1.FFTLutParam, defaultFilePath string) error {
@@ -197,7 +200,7 @@ func (p *pfBBConfigController) updateFftWindowsCoefficientFilepath(deviceName st
 // deviceName is one of: FPGA_LTE or FPGA_5GNR or ACC100
 // cfgFilepath is a filepath to the config
 // pciAddress points to a specific PF device
-func (p *pfBBConfigController) runPFConfig(deviceName, cfgFilepath, pciAddress string, token *string) error {
+func (p *pfBBConfigController) runPFConfig(ctx context.Context, deviceName, cfgFilepath, pciAddress string, token *string) error {
 	switch deviceName {
 	case "FPGA_LTE", "FPGA_5GNR", "ACC100", "ACC200", "VRB1", "VRB2":
 	default:
@@ -205,31 +208,31 @@ func (p *pfBBConfigController) runPFConfig(deviceName, cfgFilepath, pciAddress s
 	}
 	if token == nil {
 		if deviceName == "ACC200" || deviceName == "VRB1" {
-			_, err := runExecCmd([]string{pfConfigAppFilepath, "VRB1", "-c", cfgFilepath, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}, p.log)
+			_, err := runExecCmd(ctx, p.limits.wrap([]string{pfConfigAppFilepath, "VRB1", "-c", cfgFilepath, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}), p.log)
 			return err
 		} else if deviceName == "VRB2" {
-			_, err := runExecCmd([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}, p.log)
+			_, err := runExecCmd(ctx, p.limits.wrap([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}), p.log)
 			return err
 		} else {
-			_, err := runExecCmd([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-p", pciAddress}, p.log)
+			_, err := runExecCmd(ctx, p.limits.wrap([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-p", pciAddress}), p.log)
 			return err
 		}
 	} else {
 		if deviceName == "ACC200" || deviceName == "VRB1" {
-			_, err := runExecCmd([]string{pfConfigAppFilepath, "VRB1", "-c", cfgFilepath, "-v", *token, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}, p.log)
+			_, err := runExecCmd(ctx, p.limits.wrap([]string{pfConfigAppFilepath, "VRB1", "-c", cfgFilepath, "-v", *token, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}), p.log)
 			return err
 		} else if deviceName == "VRB2" {
-			_, err := runExecCmd([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-v", *token, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}, p.log)
+			_, err := runExecCmd(ctx, p.limits.wrap([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-v", *token, "-p", pciAddress, "-f", srsFftWindowsCoefficientFilepath}), p.log)
 			return err
 		} else {
-			_, err := runExecCmd([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-v", *token, "-p", pciAddress}, p.log)
+			_, err := runExecCmd(ctx, p.limits.wrap([]string{pfConfigAppFilepath, deviceName, "-c", cfgFilepath, "-v", *token, "-p", pciAddress}), p.log)
 			return err
 		}
 	}
 }
 
-func (p *pfBBConfigController) stopPfBBConfig(pciAddress string) error {
-	_, err := execAndSuppress([]string{
+func (p *pfBBConfigController) stopPfBBConfig(ctx context.Context, pciAddress string) error {
+	_, err := execAndSuppress(ctx, []string{
 		"pkill",
 		"-9",
 		"-f",
@@ -242,14 +245,9 @@ func (p *pfBBConfigController) stopPfBBConfig(pciAddress string) error {
 		return false
 	})
 
-	//TODO: Remove workaround
-	//Code below implements workaround problem related with pf_bb_config app. Ticket describing an issue SCSY-190446
-	sockFileToBeDeleted := fmt.Sprintf("/tmp/pf_bb_config.%s.sock", pciAddress)
-	p.log.WithField("applying-work-around", "SCSY-190446").Info("deleting", sockFileToBeDeleted)
-
-	if err := os.Remove(sockFileToBeDeleted); err != nil {
-		p.log.WithError(err).Infof("cannot remove (%s)) file: %s", sockFileToBeDeleted, err)
-		return nil
+	// killed pf_bb_config leaves its socket behind; remaining leftovers are handled by ArtifactsCollector
+	if rmErr := removePfBbConfigSocket(pciAddress, p.log); rmErr != nil {
+		p.log.WithError(rmErr).WithField("pciAddress", pciAddress).Info("cannot remove pf_bb_config socket")
 	}
 
 	return err
@@ -343,17 +341,16 @@ func (f *fftUpdater) VrbupdateFftFile(fft *vrbv1.FFTLutParam) (string, error) {
 	return newFftFile, nil
 }
 
-func logLinkStatus(pciAddr string, log *logrus.Logger) {
+func logLinkStatus(ctx context.Context, pciAddr string, log *logrus.Logger) {
 	// Execute the lspci command
-	cmd := exec.Command("lspci", "-vvs", pciAddr)
-	output, err := cmd.Output()
+	output, err := hostCommander.Run(ctx, []string{"lspci", "-vvs", pciAddr}, log)
 	if err != nil {
 		log.WithError(err).WithField("pciAddr", pciAddr).Warning("Error running lspci")
 		return
 	}
 
 	// Convert output to string
-	outputStr := strings.ToLower(string(output))
+	outputStr := strings.ToLower(output)
 
 	// Regular expression pattern for LnkSta case insensitive
 	re := regexp.MustCompile(`(?i)LnkSta:.+?(\n|$)`)
@@ -4,6 +4,7 @@
 package daemon
 
 import (
+	"context"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -13,15 +14,15 @@ var _ = Describe("common", func() {
 	log = utils.NewLogger()
 	var _ = Context("execCmd", func() {
 		var _ = It("will return error when args is empty ", func() {
-			_, err := execCmd([]string{}, log)
+			_, err := execCmd(context.TODO(), []string{}, log)
 			Expect(err).To(HaveOccurred())
 		})
 		var _ = It("will return error when exec doesn't exist ", func() {
-			_, err := execCmd([]string{"dummyExecFile"}, log)
+			_, err := execCmd(context.TODO(), []string{"dummyExecFile"}, log)
 			Expect(err).To(HaveOccurred())
 		})
 		var _ = It("will call exec ", func() {
-			_, err := execCmd([]string{"ls"}, log)
+			_, err := execCmd(context.TODO(), []string{"ls"}, log)
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var pfConfigurationTimeoutEnv = utils.SRIOV_PREFIX + "PF_CONFIGURATION_TIMEOUT"
+
+// defaultPFConfigurationTimeout is used when SRIOV_FEC_PF_CONFIGURATION_TIMEOUT is not set or invalid
+const defaultPFConfigurationTimeout = 5 * time.Minute
+
+// errConfigurationTimeout is returned when configuration of a PF exceeds its timeout
+var errConfigurationTimeout = errors.New("configuration timed out")
+
+func isConfigurationTimeout(err error) bool {
+	return errors.Is(err, errConfigurationTimeout)
+}
+
+// pfConfigurationTimeout returns timeout of configuration of a single PF, 0 disables the timeout
+func pfConfigurationTimeout(log *logrus.Logger) time.Duration {
+	value := os.Getenv(pfConfigurationTimeoutEnv)
+	if value == "" {
+		return defaultPFConfigurationTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.WithField("value", value).Warnf("invalid %s, using default %v", pfConfigurationTimeoutEnv, defaultPFConfigurationTimeout)
+		return defaultPFConfigurationTimeout
+	}
+	return timeout
+}
+
+// configureWithTimeout runs configuration steps of the PF until all of them succeed, one of them fails or the timeout
+// is exceeded. Running step is not interrupted - the attempt is aborted before the next step and the PF is cleaned,
+// so it's left without VFs and pf_bb_config instead of being partially configured. Cancellation of ctx (e.g. on daemon's
+// shutdown) aborts the attempt before the next step as well.
+func (n *NodeConfigurator) configureWithTimeout(ctx context.Context, pciAddress string, clean func() error, steps ...func() error) error {
+	timeout := pfConfigurationTimeout(n.Log)
+	start := time.Now()
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return errclass.Wrap(errclass.TransientInfra, fmt.Errorf("configuration of PF (%s) interrupted: %w", pciAddress, err))
+		}
+		if timeout > 0 && time.Since(start) > timeout {
+			n.Log.WithField("pci", pciAddress).WithField("timeout", timeout).Error("configuration of PF timed out - cleaning it")
+			if err := clean(); err != nil {
+				n.Log.WithError(err).WithField("pci", pciAddress).Error("failed to clean PF after configuration timed out")
+			}
+			return errclass.Wrap(errclass.Device, fmt.Errorf("configuration of PF (%s) exceeded %v: %w", pciAddress, timeout, errConfigurationTimeout))
+		}
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+var _ = Describe("NodeConfigurator.configureWithTimeout()", func() {
+	var (
+		configurator *NodeConfigurator
+		cleaned      bool
+		executed     []int
+	)
+
+	clean := func() error {
+		cleaned = true
+		return nil
+	}
+	step := func(index int, duration time.Duration) func() error {
+		return func() error {
+			executed = append(executed, index)
+			time.Sleep(duration)
+			return nil
+		}
+	}
+
+	BeforeEach(func() {
+		configurator = &NodeConfigurator{Log: logrus.New()}
+		cleaned, executed = false, nil
+	})
+
+	AfterEach(func() {
+		Expect(os.Unsetenv(pfConfigurationTimeoutEnv)).To(Succeed())
+	})
+
+	It("runs all steps within the timeout", func() {
+		Expect(configurator.configureWithTimeout(context.TODO(), "0000:14:00.1", clean, step(1, 0), step(2, 0))).To(Succeed())
+		Expect(executed).To(Equal([]int{1, 2}))
+		Expect(cleaned).To(BeFalse())
+	})
+
+	It("aborts configuration and cleans the PF when timeout is exceeded", func() {
+		Expect(os.Setenv(pfConfigurationTimeoutEnv, "10ms")).To(Succeed())
+		err := configurator.configureWithTimeout(context.TODO(), "0000:14:00.1", clean, step(1, 20*time.Millisecond), step(2, 0))
+		Expect(err).To(HaveOccurred())
+		Expect(isConfigurationTimeout(err)).To(BeTrue())
+		Expect(errclass.Of(err)).To(Equal(errclass.Device))
+		Expect(failureReason(err)).To(Equal(ConfigurationTimeout))
+		Expect(isRollbackApplicable(err)).To(BeFalse())
+		Expect(executed).To(Equal([]int{1}))
+		Expect(cleaned).To(BeTrue())
+	})
+
+	It("doesn't abort configuration when timeout is disabled", func() {
+		Expect(os.Setenv(pfConfigurationTimeoutEnv, "0")).To(Succeed())
+		Expect(configurator.configureWithTimeout(context.TODO(), "0000:14:00.1", clean, step(1, time.Millisecond), step(2, 0))).To(Succeed())
+		Expect(executed).To(Equal([]int{1, 2}))
+	})
+
+	It("returns error of the failed step", func() {
+		failing := func() error { return fmt.Errorf("failed") }
+		Expect(configurator.configureWithTimeout(context.TODO(), "0000:14:00.1", clean, failing, step(2, 0))).To(MatchError("failed"))
+		Expect(executed).To(BeEmpty())
+	})
+
+	It("uses default timeout when configured one is invalid", func() {
+		Expect(os.Setenv(pfConfigurationTimeoutEnv, "-1s")).To(Succeed())
+		Expect(pfConfigurationTimeout(configurator.Log)).To(Equal(defaultPFConfigurationTimeout))
+	})
+})
@@ -5,22 +5,33 @@ package daemon
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 )
 
 type ConfigurationConditionReason string
@@ -31,6 +42,12 @@ const (
 	ConfigurationFailed       ConfigurationConditionReason = "Failed"
 	ConfigurationNotRequested ConfigurationConditionReason = "NotRequested"
 	ConfigurationSucceeded    ConfigurationConditionReason = "Succeeded"
+	ConfigurationOrphaned     ConfigurationConditionReason = "Orphaned"
+	ConfigurationRolledBack   ConfigurationConditionReason = "RolledBack"
+	ConfigurationTimeout      ConfigurationConditionReason = "Timeout"
+
+	configurationAdoptedMessage  = "Configuration adopted - devices already reflect requested spec"
+	configurationOrphanedMessage = "NodeConfig was recreated while accelerators remain configured - waiting for the operator to render configuration again"
 )
 
 var (
@@ -40,12 +57,61 @@ var (
 	kernelParams        = []string{"intel_iommu=on", "iommu=pt"}
 )
 
-type DrainAndExecute func(configurer func(ctx context.Context) bool, drain bool) error
+// isRolledBack returns true if the requested generation failed and the last-known-good configuration was restored,
+// such generation isn't applied again
+func isRolledBack(condition metav1.Condition, generation int64) bool {
+	return condition.Reason == string(ConfigurationRolledBack) && condition.ObservedGeneration == generation
+}
+
+// isRollbackApplicable returns true if configuration failure is caused by the device, other failures are not expected
+// to be fixed by restoring previous configuration. PF which configuration timed out is left cleaned instead.
+func isRollbackApplicable(err error) bool {
+	class := errclass.Of(err)
+	return (class == errclass.Device || class == errclass.Unknown) && !isConfigurationTimeout(err)
+}
+
+// failureReason returns reason of Configured condition reporting failed configuration
+func failureReason(err error) ConfigurationConditionReason {
+	if isConfigurationTimeout(err) {
+		return ConfigurationTimeout
+	}
+	return ConfigurationFailed
+}
+
+type DrainAndExecute func(ctx context.Context, configurer func(ctx context.Context) bool, drain bool) error
+
+type RestartDevicePluginFunction func(ctx context.Context) error
+
+// configChecksum returns checksum of requested physical functions configuration, it is stored in status
+// when configuration succeeds to recognize already applied configuration e.g. after operator upgrade
+func configChecksum(physicalFunctions interface{}) string {
+	content, err := json.Marshal(physicalFunctions)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
 
-type RestartDevicePluginFunction func() error
+// vfDriversMatch returns true if bound drivers are the ones expected for requested amount of VFs, regardless of VFs order
+func vfDriversMatch(amount int, expectedDriver func(index int) string, boundDrivers []string) bool {
+	if len(boundDrivers) != amount {
+		return false
+	}
+	missing := map[string]int{}
+	for i := 0; i < amount; i++ {
+		missing[expectedDriver(i)]++
+	}
+	for _, driver := range boundDrivers {
+		if missing[driver] == 0 {
+			return false
+		}
+		missing[driver]--
+	}
+	return true
+}
 
-func pfBbConfigProcIsDead(log *logrus.Logger, pciAddr string) bool {
-	stdout, err := execCmd([]string{
+func pfBbConfigProcIsDead(ctx context.Context, log *logrus.Logger, pciAddr string) bool {
+	stdout, err := execCmd(ctx, []string{
 		"pgrep",
 		"--count",
 		"--full",
@@ -72,13 +138,24 @@ func isReady(p corev1.Pod) bool {
 	return false
 }
 
-func CreateManager(config *rest.Config, scheme *runtime.Scheme, namespace string, metricsPort int, HealthProbePort int, log *logrus.Logger) (manager.Manager, error) {
+// CreateManager returns manager of the daemon of the node; its cache keeps objects of the node only, so daemons don't
+// receive updates of every NodeConfig, Node and Pod of the cluster
+func CreateManager(config *rest.Config, scheme *runtime.Scheme, namespace, nodeName string, metricsPort int, HealthProbePort int, log *logrus.Logger) (manager.Manager, error) {
+	metricsAddr, err := utils.BindAddress(metricsPort)
+	if err != nil {
+		return nil, err
+	}
+	healthProbeAddr, err := utils.BindAddress(HealthProbePort)
+	if err != nil {
+		return nil, err
+	}
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:                 scheme,
-		MetricsBindAddress:     ":" + strconv.Itoa(metricsPort),
+		MetricsBindAddress:     metricsAddr,
 		LeaderElection:         false,
 		Namespace:              namespace,
-		HealthProbeBindAddress: ":" + strconv.Itoa(HealthProbePort),
+		HealthProbeBindAddress: healthProbeAddr,
+		NewCache:               cache.BuilderWithOptions(cache.Options{SelectorsByObject: nodeCacheSelectors(nodeName)}),
 	})
 	if err != nil {
 		return nil, err
@@ -95,6 +172,17 @@ func CreateManager(config *rest.Config, scheme *runtime.Scheme, namespace string
 	return mgr, nil
 }
 
+// nodeCacheSelectors limits cached NodeConfigs and Node to the ones of the node, and Pods to the ones running on the node
+func nodeCacheSelectors(nodeName string) cache.SelectorsByObject {
+	ofNode := cache.ObjectSelector{Field: fields.OneTermEqualSelector("metadata.name", nodeName)}
+	return cache.SelectorsByObject{
+		&fec.SriovFecNodeConfig{}:   ofNode,
+		&vrbv1.SriovVrbNodeConfig{}: ofNode,
+		&corev1.Node{}:              ofNode,
+		&corev1.Pod{}:               {Field: fields.OneTermEqualSelector("spec.nodeName", nodeName)},
+	}
+}
+
 func moduleParameterIsEnabled(moduleName, parameter string) error {
 	value, err := os.ReadFile("/sys/module/" + moduleName + "/parameters/" + parameter)
 	if err != nil {
@@ -111,6 +199,47 @@ func moduleParameterIsEnabled(moduleName, parameter string) error {
 	return nil
 }
 
+// readNodeCapabilities returns node's features which are validated before configuration, so the operator can validate
+// requested configuration in advance. Returns nil if capabilities cannot be determined.
+func readNodeCapabilities(ctx context.Context, log *logrus.Logger) *fec.NodeCapabilities {
+	cmdline, err := os.ReadFile(procCmdlineFilePath)
+	if err != nil {
+		log.WithError(err).Error("failed to read kernel parameters")
+		return nil
+	}
+	return &fec.NodeCapabilities{
+		IommuEnabled:     validateOrdinalKernelParams(string(cmdline)) == nil,
+		KernelLockdown:   isKernelLockdownEnabled(),
+		AvailableDrivers: availableDrivers(ctx, log),
+	}
+}
+
+// isKernelLockdownEnabled returns true if kernel lockdown allows only vfio-pci PF driver
+func isKernelLockdownEnabled() bool {
+	// missing lockdown file means that lockdown is not supported by the kernel
+	lockdown, err := os.ReadFile(sysLockdownFilePath)
+	return err == nil && !strings.Contains(string(lockdown), "[none]")
+}
+
+// availableDrivers returns known drivers which are loaded or can be loaded on the node
+func availableDrivers(ctx context.Context, log *logrus.Logger) []string {
+	var available []string
+	for _, driver := range utils.KnownDrivers {
+		if isDriverAvailable(ctx, driver, log) {
+			available = append(available, driver)
+		}
+	}
+	return available
+}
+
+var isDriverAvailable = func(ctx context.Context, driver string, log *logrus.Logger) bool {
+	if _, err := os.Stat(filepath.Join(sysBusPciDrivers, driver)); err == nil {
+		return true
+	}
+	_, err := hostCommander.Run(ctx, []string{"modprobe", "--dry-run", driver}, log)
+	return err == nil
+}
+
 func validateOrdinalKernelParams(cmdline string) error {
 	for _, param := range kernelParams {
 		if !strings.Contains(cmdline, param) {
@@ -10,18 +10,25 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/types"
 
 	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var (
@@ -37,10 +44,14 @@ type FecNodeConfigReconciler struct {
 	drainerAndExecute   DrainAndExecute
 	sriovfecconfigurer  Configurer
 	restartDevicePlugin RestartDevicePluginFunction
+	// recorder records Events on the NodeConfig, it's set up along with the controller
+	recorder record.EventRecorder
+	// driversVerifiedAt is the time drivers of PFs and VFs were last verified, once per spec.syncInterval
+	driversVerifiedAt time.Time
 }
 
 type Configurer interface {
-	ApplySpec(nodeConfig fec.SriovFecNodeConfigSpec) error
+	ApplySpec(ctx context.Context, nodeConfig fec.SriovFecNodeConfigSpec) error
 }
 
 /*****************************************************************************
@@ -48,44 +59,100 @@ type Configurer interface {
  * Description:
  *
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *FecNodeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.log.Infof("Reconcile(...) triggered by %s", req.NamespacedName.String())
 
-	sfnc, err := r.readNodeConfig(req.NamespacedName)
+	sfnc, err := r.readNodeConfig(ctx, req.NamespacedName)
 	if err != nil {
 		return requeueNowWithError(err)
 	}
 
-	if err := validateNodeConfig(sfnc.Spec); err != nil {
-		return requeueNowWithError(r.updateStatus(sfnc, metav1.ConditionFalse, ConfigurationFailed, err.Error()))
+	if sfnc.IsOrphaned() {
+		r.log.Info("SriovFecNodeConfig is orphaned - keeping current configuration until the operator renders it again")
+		return requeueLater()
 	}
 
-	detectedInventory, err := r.readExistingInventory()
+	ttl, err := sfnc.ManualOverrideTTL()
 	if err != nil {
+		r.log.WithError(err).Warn("manual override is ignored")
+	}
+	if remaining, err := manualOverride(ctx, r.Client, sfnc, &sfnc.Status.Conditions, ttl, time.Now()); err != nil {
 		return requeueNowWithError(err)
+	} else if remaining > 0 {
+		r.log.WithField("remaining", remaining.String()).Info("SriovFecNodeConfig is manually overridden - enforcement is suspended")
+		return ctrl.Result{RequeueAfter: min(remaining, resyncPeriod)}, nil
 	}
 
-	if isConfigurationOfNonExistingInventoryRequested(sfnc.Spec.PhysicalFunctions, detectedInventory) {
-		r.log.Info("requested configuration refers to not existing accelerator(s)")
-		return requeueLaterOrNowIfError(r.updateStatus(sfnc, metav1.ConditionFalse, ConfigurationFailed, "requested configuration refers to not existing accelerator"))
+	sfnc.Status.SelectedPFDrivers = selectPFDrivers(ctx, sfnc.Spec.PhysicalFunctions, r.log)
+
+	if err := validateNodeConfig(effectiveSpec(sfnc)); err != nil {
+		return requeueNowWithError(r.updateFailedStatus(ctx, sfnc, errclass.Wrap(errclass.Platform, err)))
 	}
 
-	if !r.isCardUpdateRequired(sfnc, detectedInventory) {
-		r.log.Info("SriovFec: Nothing to do")
+	detectedInventory, skipped, err := r.readExistingInventory(ctx, r.Client)
+	if err != nil {
+		return requeueNowWithError(err)
+	}
+
+	if err := r.handleHotplug(ctx, sfnc, detectedInventory, skipped); err != nil {
+		return requeueNowWithError(err)
+	}
+
+	// repeated disruptive configuration attempts are not made against accelerators reporting uncorrectable errors
+	if faulty, err := hardwareFaultFreeze(ctx, r.Client, sfnc, &sfnc.Status.Conditions, fecAcceleratorAddresses(detectedInventory)); err != nil {
+		return requeueNowWithError(err)
+	} else if len(faulty) != 0 {
+		r.log.WithField("accelerators", faulty).Warn("uncorrectable PCIe errors detected - configuration is frozen")
 		return requeueLater()
 	}
 
-	if r.isCardUpdateRequired(sfnc, detectedInventory) {
+	// configuration of accelerators missing on the node is skipped rather than failing the whole node, it's applied once
+	// they are back; the spec isn't written by the daemon from now on, only the status
+	present, missing := skipMissingPhysicalFunctions(sfnc.Spec.PhysicalFunctions, detectedInventory)
+	if len(missing) != 0 {
+		sfnc.Spec.PhysicalFunctions = present
+		reportMissingAccelerators(r.recorder, sfnc, missing, r.log)
+	}
 
-		if err := r.updateStatus(sfnc, metav1.ConditionFalse, ConfigurationInProgress, "Configuration started"); err != nil {
+	if isRolledBack(findOrCreateConfigurationStatusCondition(sfnc), sfnc.GetGeneration()) {
+		if err := r.reapplyLastKnownGoodIfOutdated(ctx, sfnc, detectedInventory); err != nil {
 			return requeueNowWithError(err)
 		}
+		r.log.Info("requested configuration was rolled back - waiting for a new one")
+		return requeueLater()
+	}
 
-		if err := r.configureNode(sfnc); err != nil {
+	if !r.isCardUpdateRequired(ctx, sfnc, detectedInventory) {
+		r.log.Info("SriovFec: Nothing to do")
+		if r.setPostRebootVerification(ctx, sfnc) {
+			return requeueLaterOrNowIfError(r.Status().Update(ctx, sfnc))
+		}
+		return requeueAfterSyncInterval(sfnc.Spec.SyncInterval)
+	}
+
+	if r.isAppliedConfigAdoptable(ctx, sfnc, detectedInventory) {
+		r.log.Info("requested configuration is already applied - adopting it without reconfiguration")
+		return requeueLaterOrNowIfError(r.updateStatus(ctx, sfnc, metav1.ConditionTrue, ConfigurationSucceeded, configurationAdoptedMessage))
+	}
+
+	if r.isCardUpdateRequired(ctx, sfnc, detectedInventory) {
+
+		// configuration still in progress was interrupted by restart of the daemon
+		resumed := findOrCreateConfigurationStatusCondition(sfnc).Reason == string(ConfigurationInProgress)
+		if err := r.updateStatus(ctx, sfnc, metav1.ConditionFalse, ConfigurationInProgress, "Configuration started"); err != nil {
+			return requeueNowWithError(err)
+		}
+
+		rolledBack, err := r.configureNode(r.withOperationJournal(ctx, sfnc, resumed), sfnc, detectedInventory)
+		if rolledBack {
+			r.log.WithError(err).Error("configuration failed - last-known-good configuration restored")
+			return requeueLaterOrNowIfError(r.updateRolledBackStatus(ctx, sfnc, err))
+		}
+		if err != nil {
 			r.log.WithError(err).Error("error occurred during configuring node")
-			return requeueNowWithError(r.updateStatus(sfnc, metav1.ConditionFalse, ConfigurationFailed, err.Error()))
+			return requeueNowWithError(r.updateFailedStatus(ctx, sfnc, err))
 		} else {
-			return requeueLaterOrNowIfError(r.updateStatus(sfnc, metav1.ConditionTrue, ConfigurationSucceeded, "Configured successfully"))
+			return requeueLaterOrNowIfError(r.updateStatus(ctx, sfnc, metav1.ConditionTrue, ConfigurationSucceeded, configuredMessage(missing)))
 		}
 	}
 
@@ -100,10 +167,10 @@ func (r *FecNodeConfigReconciler) Reconcile(_ context.Context, req ctrl.Request)
  * If invoked before manager's Start, it'll need a direct API client
  * (Manager's/Controller's client is cached and cache is not initialized yet).
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(c client.Client) error {
+func (r *FecNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(ctx context.Context, c client.Client) error {
 	SriovFecnodeConfig := &fec.SriovFecNodeConfig{}
 
-	err := c.Get(context.Background(), client.ObjectKey{Name: r.nodeNameRef.Name, Namespace: r.nodeNameRef.Namespace}, SriovFecnodeConfig)
+	err := c.Get(ctx, client.ObjectKey{Name: r.nodeNameRef.Name, Namespace: r.nodeNameRef.Namespace}, SriovFecnodeConfig)
 	if err == nil {
 		r.log.Info("already exists")
 		return nil
@@ -115,38 +182,53 @@ func (r *FecNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(c client.Client)
 
 	r.log.Infof("SriovFecNodeConfig{%s} not found - creating", r.nodeNameRef)
 
+	inv, skipped, err := r.readExistingInventory(ctx, c)
+	if err != nil {
+		return err
+	}
+
 	SriovFecnodeConfig = &fec.SriovFecNodeConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.nodeNameRef.Name,
 			Namespace: r.nodeNameRef.Namespace,
+			Labels:    utils.StandardLabels(utils.SriovFecNodeConfigName, r.nodeNameRef.Name),
 		},
 		Spec: fec.SriovFecNodeConfigSpec{
 			PhysicalFunctions: []fec.PhysicalFunctionConfigExt{},
 		},
 	}
 
-	if createErr := c.Create(context.Background(), SriovFecnodeConfig); createErr != nil {
-		r.log.WithError(createErr).Error("failed to create")
-		return createErr
+	condition := metav1.Condition{
+		Type:    ConditionConfigured,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(ConfigurationNotRequested),
+		Message: "",
 	}
 
-	meta.SetStatusCondition(&SriovFecnodeConfig.Status.Conditions, metav1.Condition{
-		Type:               ConditionConfigured,
-		Status:             metav1.ConditionFalse,
-		Reason:             string(ConfigurationNotRequested),
-		Message:            "",
-		ObservedGeneration: SriovFecnodeConfig.GetGeneration(),
-	})
+	// configured accelerators without NodeConfig mean that it was deleted by mistake - the configuration is kept
+	// until the operator renders NodeConfig again
+	if hasConfiguredAccelerators(inv) {
+		r.log.Info("accelerators remain configured - SriovFecNodeConfig is recreated as orphaned")
+		SriovFecnodeConfig.Annotations = map[string]string{fec.OrphanedAnnotation: "true"}
+		condition.Reason = string(ConfigurationOrphaned)
+		condition.Message = configurationOrphanedMessage
+	}
 
-	if inv, err := r.readExistingInventory(); err != nil {
-		return err
-	} else {
-		SriovFecnodeConfig.Status.Inventory = *inv
+	if createErr := c.Create(ctx, SriovFecnodeConfig); createErr != nil {
+		r.log.WithError(createErr).Error("failed to create")
+		return createErr
 	}
 
-	SriovFecnodeConfig.Status.PfBbConfVersion = r.getPfBbConfVersion()
+	condition.ObservedGeneration = SriovFecnodeConfig.GetGeneration()
+	SriovFecnodeConfig.Status.ObservedGeneration = SriovFecnodeConfig.GetGeneration()
+	meta.SetStatusCondition(&SriovFecnodeConfig.Status.Conditions, condition)
+	setStandardConditions(&SriovFecnodeConfig.Status.Conditions, condition, SriovFecnodeConfig.GetGeneration(), "")
+	SriovFecnodeConfig.Status.Inventory = *inv
+	SriovFecnodeConfig.Status.SkippedAccelerators = skipped
+
+	SriovFecnodeConfig.Status.PfBbConfVersion = r.getPfBbConfVersion(ctx)
 
-	if updateErr := c.Status().Update(context.Background(), SriovFecnodeConfig); updateErr != nil {
+	if updateErr := c.Status().Update(ctx, SriovFecnodeConfig); updateErr != nil {
 		r.log.WithError(updateErr).Error("failed to update cr status")
 		return updateErr
 	}
@@ -160,18 +242,78 @@ func (r *FecNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(c client.Client)
  *
  ****************************************************************************/
 func (r *FecNodeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor(eventRecorderName)
+	nodeConfig := &fec.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: r.nodeNameRef.Name, Namespace: r.nodeNameRef.Namespace}}
+	watcher := newAcceleratorsWatcher(nodeConfig, func() ([]string, error) {
+		inv, err := getSriovInventory(r.log)
+		if err != nil {
+			return nil, err
+		}
+		return fecAcceleratorAddresses(inv), nil
+	}, r.log)
+	if err := mgr.Add(watcher); err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&fec.SriovFecNodeConfig{}).
-		WithEventFilter(
+		For(&fec.SriovFecNodeConfig{}, builder.WithPredicates(
 			predicate.And(
 				resourceNamePredicate{
 					requiredName: r.nodeNameRef.Name,
 					log:          r.log,
 				},
-				predicate.GenerationChangedPredicate{},
+				// manual override annotations suspend and resume enforcement without changing the spec
+				predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}),
 			),
-		).Complete(r)
+		)).
+		Watches(&source.Kind{Type: &corev1.Node{}}, nodeBecameReadyHandler(r.nodeNameRef, r.log)).
+		Watches(&source.Channel{Source: watcher.events}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
+
+// updateFailedStatus reports failed configuration along with class of the error
+func (r *FecNodeConfigReconciler) updateFailedStatus(ctx context.Context, nc *fec.SriovFecNodeConfig, err error) error {
+	nc.Status.ErrorClass = string(errclass.Record(errclass.Daemon, err))
+	return r.updateStatus(ctx, nc, metav1.ConditionFalse, failureReason(err), err.Error())
+}
+
+// handleHotplug reports accelerators hot-plugged or removed at runtime in status inventory of the NodeConfig, so
+// the operator renders configuration for them. Device plugin is restarted to stop exposing resources of removed ones.
+func (r *FecNodeConfigReconciler) handleHotplug(ctx context.Context, nc *fec.SriovFecNodeConfig, detected *fec.NodeInventory, skipped []fec.SkippedAccelerator) error {
+	added, removed := acceleratorsChanges(fecAcceleratorAddresses(&nc.Status.Inventory), fecAcceleratorAddresses(detected))
+	if len(added)+len(removed) == 0 {
+		return nil
+	}
+
+	r.log.WithField("added", added).WithField("removed", removed).Info("accelerators changed - updating SriovFecNodeConfig inventory")
+	nc.Status.Inventory = *detected
+	nc.Status.SkippedAccelerators = skipped
+	if err := r.Status().Update(ctx, nc); err != nil {
+		r.log.WithError(err).Error("failed to update cr status")
+		return err
+	}
+
+	if len(removed) != 0 {
+		if err := r.restartDevicePlugin(ctx); err != nil {
+			r.log.WithError(err).Error("failed to restart device plugin after accelerators removal")
+			return err
+		}
+	}
+	return nil
+}
+
+func fecAcceleratorAddresses(inv *fec.NodeInventory) []string {
+	var addresses []string
+	for _, acc := range inv.SriovAccelerators {
+		addresses = append(addresses, acc.PCIAddress)
+	}
+	return addresses
+}
+
+// updateRolledBackStatus reports failed configuration, which was replaced with the last-known-good one
+func (r *FecNodeConfigReconciler) updateRolledBackStatus(ctx context.Context, nc *fec.SriovFecNodeConfig, err error) error {
+	nc.Status.ErrorClass = string(errclass.Record(errclass.Daemon, err))
+	return r.updateStatus(ctx, nc, metav1.ConditionFalse, ConfigurationRolledBack, "Configuration failed, last-known-good configuration restored: "+err.Error())
 }
 
 /*****************************************************************************
@@ -179,14 +321,15 @@ func (r *FecNodeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
  * Description:
  *
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) updateStatus(nc *fec.SriovFecNodeConfig, status metav1.ConditionStatus, reason ConfigurationConditionReason, msg string) error {
+func (r *FecNodeConfigReconciler) updateStatus(ctx context.Context, nc *fec.SriovFecNodeConfig, status metav1.ConditionStatus, reason ConfigurationConditionReason, msg string) error {
 	previousCondition := findOrCreateConfigurationStatusCondition(nc)
 
 	// SriovFecNodeConfig.generation is under K8S management
 	// metav1.Condition.observedGeneration is under this reconciler management.
-	// observedGeneration would be incremented then and only then when spec which comes with updated generation would be processed without any error.
+	// observedGeneration would be incremented then and only then when spec which comes with updated generation would be processed without any error,
+	// or when it failed and the last-known-good configuration was restored (it isn't applied again then).
 	determineGeneration := func() int64 {
-		if reason == ConfigurationSucceeded {
+		if reason == ConfigurationSucceeded || reason == ConfigurationRolledBack {
 			return nc.GetGeneration()
 		} else {
 			return previousCondition.ObservedGeneration
@@ -201,23 +344,44 @@ func (r *FecNodeConfigReconciler) updateStatus(nc *fec.SriovFecNodeConfig, statu
 		ObservedGeneration: determineGeneration(),
 	}
 
+	if reason != ConfigurationFailed && reason != ConfigurationRolledBack && reason != ConfigurationTimeout {
+		nc.Status.ErrorClass = ""
+	}
 	meta.SetStatusCondition(&nc.Status.Conditions, condition)
-	if inv, err := getSriovInventory(r.log); err != nil {
+	// status.observedGeneration tells the generation the outcome is reported for, even if it failed
+	if reason != ConfigurationInProgress {
+		nc.Status.ObservedGeneration = nc.GetGeneration()
+	}
+	setStandardConditions(&nc.Status.Conditions, condition, nc.GetGeneration(), nc.Status.ErrorClass)
+	if reason == ConfigurationSucceeded {
+		nc.Status.AppliedConfigChecksum = configChecksum(nc.Spec.PhysicalFunctions)
+		nc.Status.LastKnownGoodPhysicalFunctions = nc.Spec.PhysicalFunctions
+	}
+	nc.Status.PowerManagement = appliedPowerManagement(nc.Spec.PhysicalFunctions)
+	if inv, skipped, err := r.readExistingInventory(ctx, r.Client); err != nil {
 		r.log.WithError(err).
 			WithField("reason", condition.Reason).
 			WithField("message", condition.Message).
 			Error("failed to obtain sriov inventory for the node")
 	} else {
 		nc.Status.Inventory = *inv
+		nc.Status.SkippedAccelerators = skipped
+	}
+	nc.Status.Capabilities = readNodeCapabilities(ctx, r.log)
+	if reason != ConfigurationInProgress {
+		r.setPostRebootVerification(ctx, nc)
 	}
+	setUnsupportedCombination(&nc.Status.Conditions, nc.GetGeneration(), fecCombinations(nc), nc.AllowsUnsupported())
+	setWorkdirFallback(&nc.Status.Conditions, nc.GetGeneration())
 
-	if err := r.Status().Update(context.Background(), nc); err != nil {
+	if err := r.Status().Update(ctx, nc); err != nil {
 		return err
 	}
 
 	r.log.WithField("previous", previousCondition).
 		WithField("current", condition).
 		Infof("%s condition transition", ConditionConfigured)
+	recordConfigurationEvent(r.recorder, nc, previousCondition, condition)
 
 	return nil
 }
@@ -227,12 +391,19 @@ func (r *FecNodeConfigReconciler) updateStatus(nc *fec.SriovFecNodeConfig, statu
  * Description:
  *
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) readExistingInventory() (*fec.NodeInventory, error) {
+func (r *FecNodeConfigReconciler) readExistingInventory(ctx context.Context, c client.Reader) (*fec.NodeInventory, []fec.SkippedAccelerator, error) {
 	inv, err := getSriovInventory(r.log)
 	if err != nil {
 		r.log.WithError(err).Error("failed to obtain sriov inventory for the node")
+		return nil, nil, err
 	}
-	return inv, err
+	skipped, err := readSkippedDevices(ctx, c, r.nodeNameRef.Name)
+	if err != nil {
+		r.log.WithError(err).Error("failed to read accelerators skipped on the node")
+		return nil, nil, err
+	}
+	withAssetMetadata(ctx, c, r.nodeNameRef.Namespace, inv, r.log)
+	return inv, withoutSkippedAccelerators(inv, skipped), nil
 }
 
 /*****************************************************************************
@@ -240,10 +411,10 @@ func (r *FecNodeConfigReconciler) readExistingInventory() (*fec.NodeInventory, e
  * Description:
  *
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) readNodeConfig(nn types.NamespacedName) (nc *fec.SriovFecNodeConfig, err error) {
+func (r *FecNodeConfigReconciler) readNodeConfig(ctx context.Context, nn types.NamespacedName) (nc *fec.SriovFecNodeConfig, err error) {
 	getSriovFecNodeConfig := func() (*fec.SriovFecNodeConfig, error) {
 		sfnc := new(fec.SriovFecNodeConfig)
-		if err := r.Client.Get(context.TODO(), nn, sfnc); err != nil {
+		if err := r.Client.Get(ctx, nn, sfnc); err != nil {
 			return nil, err
 		}
 		return sfnc, nil
@@ -256,7 +427,7 @@ func (r *FecNodeConfigReconciler) readNodeConfig(nn types.NamespacedName) (nc *f
 		}
 
 		r.log.Info("SriovFecNodeConfig not found - creating")
-		if err := r.CreateEmptyNodeConfigIfNeeded(r.Client); err != nil {
+		if err := r.CreateEmptyNodeConfigIfNeeded(ctx, r.Client); err != nil {
 			r.log.WithError(err).Error("Couldn't create SriovFecNodeConfig")
 			return nil, err
 		}
@@ -274,38 +445,92 @@ func (r *FecNodeConfigReconciler) readNodeConfig(nn types.NamespacedName) (nc *f
  * Description:
  *
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) configureNode(nodeConfig *fec.SriovFecNodeConfig) error {
+func (r *FecNodeConfigReconciler) configureNode(ctx context.Context, nodeConfig *fec.SriovFecNodeConfig, inventory *fec.NodeInventory) (rolledBack bool, err error) {
 	var configurationError error
 
 	drainFunc := func(ctx context.Context) bool {
-		if err := r.sriovfecconfigurer.ApplySpec(nodeConfig.Spec); err != nil {
+		if err := r.sriovfecconfigurer.ApplySpec(ctx, effectiveSpec(nodeConfig)); err != nil {
 			r.log.WithError(err).Error("failed applying new PF/VF configuration")
 			configurationError = err
-			return true
+			if rolledBack = r.restoreLastKnownGood(ctx, nodeConfig, err); !rolledBack {
+				return true
+			}
 		}
 
-		configurationError = r.restartDevicePlugin()
+		if err := r.restartDevicePlugin(ctx); err != nil {
+			if configurationError != nil {
+				r.log.WithError(err).Error("failed to restart device plugin after rollback")
+			} else {
+				configurationError = err
+			}
+		}
 		return true
 	}
 
-	if err := r.drainerAndExecute(drainFunc, !nodeConfig.Spec.DrainSkip); err != nil {
-		return err
+	drain := !nodeConfig.Spec.DrainSkip
+	if drain && nodeConfig.Spec.DrainPolicy == fec.DrainIfDisruptive && !hasVFs(inventory) {
+		r.log.Info("no accelerator of the node has VFs - configuration is not disruptive, skipping drain")
+		drain = false
 	}
 
-	return configurationError
+	if err := r.drainerAndExecute(r.withDrainProgress(ctx, nodeConfig), drainFunc, drain); err != nil {
+		return false, errclass.Wrap(errclass.TransientInfra, err)
+	}
+
+	return rolledBack, errclass.Wrap(errclass.Device, configurationError)
 }
 
 /*****************************************************************************
- * Method: FecNodeConfigReconciler::
+ * Method: FecNodeConfigReconciler::restoreLastKnownGood
  * Description:
- *
+ * applies the last successfully applied configuration after configuration
+ * of a new spec failed because of the device; returns true if it was restored
  ****************************************************************************/
-func (r *FecNodeConfigReconciler) isCardUpdateRequired(nc *fec.SriovFecNodeConfig, detectedInventory *fec.NodeInventory) bool {
-	pciToVfsAmount := map[string]int{}
-	for _, physicalFunction := range nc.Spec.PhysicalFunctions {
-		pciToVfsAmount[physicalFunction.PCIAddress] = physicalFunction.VFAmount
+func (r *FecNodeConfigReconciler) restoreLastKnownGood(ctx context.Context, nodeConfig *fec.SriovFecNodeConfig, err error) bool {
+	lastKnownGood := nodeConfig.Status.LastKnownGoodPhysicalFunctions
+	if len(lastKnownGood) == 0 || !isRollbackApplicable(err) ||
+		configChecksum(lastKnownGood) == configChecksum(nodeConfig.Spec.PhysicalFunctions) {
+		return false
 	}
 
+	spec := nodeConfig.Spec.DeepCopy()
+	spec.PhysicalFunctions = withSelectedPFDrivers(lastKnownGood, nodeConfig.Status.SelectedPFDrivers)
+	if err := r.sriovfecconfigurer.ApplySpec(ctx, *spec); err != nil {
+		r.log.WithError(err).Error("failed restoring last-known-good PF/VF configuration")
+		return false
+	}
+	r.log.Info("last-known-good PF/VF configuration restored")
+	return true
+}
+
+/*****************************************************************************
+ * Method: FecNodeConfigReconciler::reapplyLastKnownGoodIfOutdated
+ * Description:
+ * applies the last-known-good configuration again when devices don't
+ * reflect it anymore (e.g. after reboot of the node), while the rolled back
+ * generation is still requested
+ ****************************************************************************/
+func (r *FecNodeConfigReconciler) reapplyLastKnownGoodIfOutdated(ctx context.Context, nc *fec.SriovFecNodeConfig, inventory *fec.NodeInventory) error {
+	lastKnownGood := nc.DeepCopy()
+	lastKnownGood.Spec.PhysicalFunctions = nc.Status.LastKnownGoodPhysicalFunctions
+	if len(lastKnownGood.Spec.PhysicalFunctions) == 0 || !r.isDeviceStateOutdated(ctx, lastKnownGood, inventory) {
+		return nil
+	}
+
+	r.log.Info("devices don't reflect last-known-good configuration - re-applying it")
+	if _, err := r.configureNode(ctx, lastKnownGood, inventory); err != nil {
+		r.log.WithError(err).Error("failed re-applying last-known-good configuration")
+		return err
+	}
+	return nil
+}
+
+/*****************************************************************************
+ * Method: FecNodeConfigReconciler::
+ * Description:
+ *
+ ****************************************************************************/
+func (r *FecNodeConfigReconciler) isCardUpdateRequired(ctx context.Context, nc *fec.SriovFecNodeConfig, detectedInventory *fec.NodeInventory) bool {
 	isGenerationChanged := func() bool {
 		observedGeneration := findOrCreateConfigurationStatusCondition(nc).ObservedGeneration
 		if nc.GetGeneration() != observedGeneration {
@@ -325,6 +550,19 @@ func (r *FecNodeConfigReconciler) isCardUpdateRequired(nc *fec.SriovFecNodeConfi
 		return false
 	}
 
+	return isGenerationChanged() || r.isDeviceStateOutdated(ctx, nc, detectedInventory)
+}
+
+/*****************************************************************************
+ * Method: FecNodeConfigReconciler::isDeviceStateOutdated
+ * Description:
+ * returns true if state of devices doesn't reflect requested spec
+ ****************************************************************************/
+func (r *FecNodeConfigReconciler) isDeviceStateOutdated(ctx context.Context, nc *fec.SriovFecNodeConfig, detectedInventory *fec.NodeInventory) bool {
+	pciToVfsAmount := map[string]int{}
+	for _, physicalFunction := range nc.Spec.PhysicalFunctions {
+		pciToVfsAmount[physicalFunction.PCIAddress] = physicalFunction.VFAmount
+	}
 	exposedInventoryOutdated := func() bool {
 		for _, accelerator := range detectedInventory.SriovAccelerators {
 			if len(accelerator.VFs) != pciToVfsAmount[accelerator.PCIAddress] {
@@ -339,9 +577,9 @@ func (r *FecNodeConfigReconciler) isCardUpdateRequired(nc *fec.SriovFecNodeConfi
 	}
 
 	bbDevConfigDaemonIsDead := func() bool {
-		for _, acc := range nc.Spec.PhysicalFunctions {
+		for _, acc := range withSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers) {
 			if strings.EqualFold(acc.PFDriver, utils.VFIO_PCI) {
-				if pfBbConfigProcIsDead(r.log, acc.PCIAddress) {
+				if pfBbConfigProcIsDead(ctx, r.log, acc.PCIAddress) {
 					r.log.WithField("pciAddress", acc.PCIAddress).
 						Info("pf-bb-config process for card is not running")
 					return true
@@ -351,7 +589,64 @@ func (r *FecNodeConfigReconciler) isCardUpdateRequired(nc *fec.SriovFecNodeConfi
 		return false
 	}
 
-	return isGenerationChanged() || exposedInventoryOutdated() || bbDevConfigDaemonIsDead()
+	// drivers of PFs and VFs, which may be changed manually in sysfs, are verified once per sync interval when periodic
+	// re-sync is requested, while the rest is verified on every resync
+	driversDrifted := func() bool {
+		if nc.Spec.SyncInterval == nil || time.Since(r.driversVerifiedAt) < nc.Spec.SyncInterval.Duration {
+			return false
+		}
+		r.driversVerifiedAt = time.Now()
+		return !r.isOutOfBandConfigMatching(nc, detectedInventory)
+	}
+
+	return exposedInventoryOutdated() || bbDevConfigDaemonIsDead() || driversDrifted()
+}
+
+/*****************************************************************************
+ * Method: FecNodeConfigReconciler::isAppliedConfigAdoptable
+ * Description:
+ * returns true if requested spec has been already applied (e.g. before
+ * operator upgrade, or out-of-band when adoption is requested) and devices
+ * still reflect it, so reconfiguration can be skipped
+ ****************************************************************************/
+func (r *FecNodeConfigReconciler) isAppliedConfigAdoptable(ctx context.Context, nc *fec.SriovFecNodeConfig, detectedInventory *fec.NodeInventory) bool {
+	appliedByOperator := nc.Status.AppliedConfigChecksum != "" && nc.Status.AppliedConfigChecksum == configChecksum(nc.Spec.PhysicalFunctions)
+	if !appliedByOperator && !(nc.IsAdoptionRequested() && r.isOutOfBandConfigMatching(nc, detectedInventory)) {
+		return false
+	}
+	return !r.isDeviceStateOutdated(ctx, nc, detectedInventory)
+}
+
+/*****************************************************************************
+ * Method: FecNodeConfigReconciler::isOutOfBandConfigMatching
+ * Description:
+ * returns true if drivers of PFs and VFs found on the node exactly match
+ * requested spec
+ ****************************************************************************/
+func (r *FecNodeConfigReconciler) isOutOfBandConfigMatching(nc *fec.SriovFecNodeConfig, detectedInventory *fec.NodeInventory) bool {
+	accelerators := map[string]fec.SriovAccelerator{}
+	for _, acc := range detectedInventory.SriovAccelerators {
+		accelerators[acc.PCIAddress] = acc
+	}
+
+	physicalFunctions := withSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers)
+	for i := range physicalFunctions {
+		pf := &physicalFunctions[i]
+		acc, ok := accelerators[pf.PCIAddress]
+		if !ok || !strings.EqualFold(acc.PFDriver, pf.PFDriver) {
+			r.log.WithField("pciAddress", pf.PCIAddress).Info("PF driver doesn't match requested one")
+			return false
+		}
+		var boundDrivers []string
+		for _, vf := range acc.VFs {
+			boundDrivers = append(boundDrivers, vf.Driver)
+		}
+		if !vfDriversMatch(pf.VFAmount, pf.VFDriverFor, boundDrivers) {
+			r.log.WithField("pciAddress", pf.PCIAddress).Info("VFs don't match requested ones")
+			return false
+		}
+	}
+	return true
 }
 
 /*****************************************************************************
@@ -367,10 +662,11 @@ func FecNewNodeConfigReconciler(k8sClient client.Client, drainer DrainAndExecute
 		return nil, err
 	}
 
+	log := utils.NewLogger()
 	return &FecNodeConfigReconciler{
-		Client:              k8sClient,
+		Client:              newRateLimitedStatusClient(k8sClient, log),
 		drainerAndExecute:   drainer,
-		log:                 utils.NewLogger(),
+		log:                 log,
 		nodeNameRef:         nodeNameRef,
 		sriovfecconfigurer:  sriovfecconfigurer,
 		restartDevicePlugin: restartDevicePluginFunction,
@@ -396,19 +692,20 @@ func findOrCreateConfigurationStatusCondition(nc *fec.SriovFecNodeConfig) metav1
 	return *configurationStatusCondition
 }
 
-// returns error if requested configuration refers to not existing inventory/accelerator
-func isConfigurationOfNonExistingInventoryRequested(requestedConfiguration []fec.PhysicalFunctionConfigExt, existingInventory *fec.NodeInventory) bool {
+// skipMissingPhysicalFunctions returns physical functions of the requested configuration which accelerators are present
+// in the inventory, and PCI addresses of the rest, e.g. accelerators lost by the node which configuration is retained
+func skipMissingPhysicalFunctions(requestedConfiguration []fec.PhysicalFunctionConfigExt, existingInventory *fec.NodeInventory) (present []fec.PhysicalFunctionConfigExt, missing []string) {
 OUTER:
 	for _, pf := range requestedConfiguration {
 		for _, acc := range existingInventory.SriovAccelerators {
 			if acc.PCIAddress == pf.PCIAddress {
+				present = append(present, pf)
 				continue OUTER
 			}
 		}
-
-		return true
+		missing = append(missing, pf.PCIAddress)
 	}
-	return false
+	return present, missing
 }
 
 func validateNodeConfig(nodeConfig fec.SriovFecNodeConfigSpec) error {
@@ -448,16 +745,16 @@ func validateNodeConfig(nodeConfig fec.SriovFecNodeConfigSpec) error {
 				}
 			}
 		default:
-			return fmt.Errorf("unknown driver '%s'", physFunc.PFDriver)
+			return errclass.New(errclass.Validation, "unknown driver '%s'", physFunc.PFDriver)
 		}
 	}
 	return nil
 }
 
-func (r *FecNodeConfigReconciler) getPfBbConfVersion() string {
+func (r *FecNodeConfigReconciler) getPfBbConfVersion(ctx context.Context) string {
 	pfConfigAppFilepath = "/sriov_workdir/pf_bb_config"
 	cmdString := fmt.Sprintf("%s version 2>/dev/null | sed -n 's/.*Version \\(\\S*\\) .*/\\1/p' | tr -d '\\n'", pfConfigAppFilepath)
-	cmd := exec.Command("bash", "-c", cmdString)
+	cmd := exec.CommandContext(ctx, "bash", "-c", cmdString)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	err := cmd.Run()
@@ -6,13 +6,17 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
 	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -25,6 +29,7 @@ var _ = Describe("FecNodeConfigReconciler.Reconcile", func() {
 
 	BeforeEach(func() {
 		scheme = runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).ToNot(HaveOccurred())
 		Expect(sriovv2.AddToScheme(scheme)).ToNot(HaveOccurred())
 		Expect(vrbv1.AddToScheme(scheme)).ToNot(HaveOccurred())
 	})
@@ -82,10 +87,10 @@ var _ = Describe("FecNodeConfigReconciler.Reconcile", func() {
 				log:                utils.NewLogger(),
 				nodeNameRef:        nodeNameRef,
 				sriovfecconfigurer: configurer,
-				drainerAndExecute: func(configurer func(ctx context.Context) bool, drain bool) error {
+				drainerAndExecute: func(_ context.Context, configurer func(ctx context.Context) bool, drain bool) error {
 					_ = configurer(context.TODO())
 					return nil
-				}, restartDevicePlugin: func() error {
+				}, restartDevicePlugin: func(context.Context) error {
 					return nil
 				}}
 			reconcileRequestes = ctrl.Request{NamespacedName: nodeNameRef}
@@ -140,6 +145,143 @@ var _ = Describe("FecNodeConfigReconciler.Reconcile", func() {
 			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
 			Expect(sfnc.Status.Inventory).ToNot(Equal(nodeInventory))
 		})
+
+		It("reports class of the error which failed configuration", func() {
+			originalLockdownPath := sysLockdownFilePath
+			defer func() { sysLockdownFilePath = originalLockdownPath }()
+			sysLockdownFilePath = filepath.Join(testTmpFolder, "lockdown")
+			Expect(os.WriteFile(sysLockdownFilePath, []byte("[none] integrity confidentiality"), 0600)).To(Succeed())
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			sfnc := new(sriovv2.SriovFecNodeConfig)
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+
+			sfnc.Generation++
+			sfnc.Spec.PhysicalFunctions = []sriovv2.PhysicalFunctionConfigExt{
+				{PCIAddress: "0000:99:00.0", PFDriver: "unknown-driver", VFDriver: "vfdriver", VFAmount: 1},
+			}
+			Expect(fakeClient.Update(context.TODO(), sfnc)).ToNot(HaveOccurred())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+			Expect(sfnc.FindCondition(ConditionConfigured).Reason).To(Equal(string(ConfigurationFailed)))
+			Expect(sfnc.Status.ErrorClass).To(Equal(string(errclass.Validation)))
+		})
+
+		It("skips configuration of accelerators missing on the node", func() {
+			originalLockdownPath := sysLockdownFilePath
+			defer func() { sysLockdownFilePath = originalLockdownPath }()
+			sysLockdownFilePath = filepath.Join(testTmpFolder, "lockdown")
+			Expect(os.WriteFile(sysLockdownFilePath, []byte("[none] integrity confidentiality"), 0600)).To(Succeed())
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			sfnc := new(sriovv2.SriovFecNodeConfig)
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+
+			sfnc.Generation++
+			sfnc.Spec.PhysicalFunctions = []sriovv2.PhysicalFunctionConfigExt{
+				{PCIAddress: "0000:99:00.0", PFDriver: utils.PCI_PF_STUB_DASH, VFDriver: "vfdriver", VFAmount: 1},
+			}
+			Expect(fakeClient.Update(context.TODO(), sfnc)).ToNot(HaveOccurred())
+
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+			Expect(sfnc.FindCondition(ConditionConfigured).Reason).To(Equal(string(ConfigurationSucceeded)))
+			Expect(sfnc.FindCondition(ConditionConfigured).Message).To(ContainSubstring("0000:99:00.0"))
+			Expect(sfnc.Status.ErrorClass).To(BeEmpty())
+		})
+
+		It("restores last-known-good configuration when configuration fails", func() {
+			originalLockdownPath := sysLockdownFilePath
+			defer func() { sysLockdownFilePath = originalLockdownPath }()
+			sysLockdownFilePath = filepath.Join(testTmpFolder, "lockdown")
+			Expect(os.WriteFile(sysLockdownFilePath, []byte("[none] integrity confidentiality"), 0600)).To(Succeed())
+
+			var applied []sriovv2.SriovFecNodeConfigSpec
+			reconciler.sriovfecconfigurer = testConfigurerProto{
+				configureNodeFunction: func(spec sriovv2.SriovFecNodeConfigSpec) error {
+					applied = append(applied, spec)
+					if spec.PhysicalFunctions[0].VFAmount == 2 {
+						return fmt.Errorf("pf_bb_config failed")
+					}
+					nodeInventory.SriovAccelerators[0].VFs = nil
+					for i := 0; i < spec.PhysicalFunctions[0].VFAmount; i++ {
+						nodeInventory.SriovAccelerators[0].VFs = append(nodeInventory.SriovAccelerators[0].VFs, sriovv2.VF{PCIAddress: fmt.Sprintf("0000:14:00.%d", i+1)})
+					}
+					return nil
+				},
+			}
+
+			_, err := reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			sfnc := new(sriovv2.SriovFecNodeConfig)
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+
+			pf := sriovv2.PhysicalFunctionConfigExt{PCIAddress: pciAddress, PFDriver: utils.PCI_PF_STUB_DASH, VFDriver: "vfdriver", VFAmount: 1}
+			sfnc.Generation++
+			sfnc.Spec.PhysicalFunctions = []sriovv2.PhysicalFunctionConfigExt{pf}
+			Expect(fakeClient.Update(context.TODO(), sfnc)).ToNot(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+			Expect(sfnc.Status.LastKnownGoodPhysicalFunctions).To(Equal([]sriovv2.PhysicalFunctionConfigExt{pf}))
+
+			pf.VFAmount = 2
+			sfnc.Generation++
+			sfnc.Spec.PhysicalFunctions = []sriovv2.PhysicalFunctionConfigExt{pf}
+			Expect(fakeClient.Update(context.TODO(), sfnc)).ToNot(HaveOccurred())
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+
+			Expect(applied).To(HaveLen(3))
+			Expect(applied[2].PhysicalFunctions[0].VFAmount).To(Equal(1))
+			condition := sfnc.FindCondition(ConditionConfigured)
+			Expect(condition.Reason).To(Equal(string(ConfigurationRolledBack)))
+			Expect(condition.Message).To(ContainSubstring("pf_bb_config failed"))
+			Expect(condition.ObservedGeneration).To(Equal(sfnc.Generation))
+			Expect(sfnc.Status.ErrorClass).To(Equal(string(errclass.Device)))
+
+			//rolled back generation is not applied again
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(HaveLen(3))
+
+			//last-known-good configuration is applied again when VFs are removed by reboot of the node
+			nodeInventory.SriovAccelerators[0].VFs = nil
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applied).To(HaveLen(4))
+			Expect(applied[3].PhysicalFunctions[0].VFAmount).To(Equal(1))
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+			Expect(sfnc.FindCondition(ConditionConfigured).Reason).To(Equal(string(ConfigurationRolledBack)))
+		})
+
+		It("keeps configuration when SriovFecNodeConfig was deleted", func() {
+			nodeInventory.SriovAccelerators[0].VFs = []sriovv2.VF{{PCIAddress: "0000:14:00.1", Driver: "vfDriver"}}
+			reconciler.sriovfecconfigurer = testConfigurerProto{
+				configureNodeFunction: func(sriovv2.SriovFecNodeConfigSpec) error {
+					Fail("configuration of orphaned SriovFecNodeConfig should not be applied")
+					return nil
+				},
+			}
+
+			//sfnc is recreated as orphaned, because accelerator remains configured
+			_, err := reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+			sfnc := new(sriovv2.SriovFecNodeConfig)
+			Expect(fakeClient.Get(context.TODO(), nodeNameRef, sfnc)).ToNot(HaveOccurred())
+			Expect(sfnc.IsOrphaned()).To(BeTrue())
+			Expect(sfnc.FindCondition(ConditionConfigured).Reason).To(Equal(string(ConfigurationOrphaned)))
+
+			//empty spec of orphaned sfnc is not applied
+			_, err = reconciler.Reconcile(context.TODO(), reconcileRequestes)
+			Expect(err).ToNot(HaveOccurred())
+		})
 	})
 })
 
@@ -147,6 +289,6 @@ type testConfigurerProto struct {
 	configureNodeFunction func(nodeConfig sriovv2.SriovFecNodeConfigSpec) error
 }
 
-func (t testConfigurerProto) ApplySpec(nodeConfig sriovv2.SriovFecNodeConfigSpec) error {
+func (t testConfigurerProto) ApplySpec(_ context.Context, nodeConfig sriovv2.SriovFecNodeConfigSpec) error {
 	return t.configureNodeFunction(nodeConfig)
 }
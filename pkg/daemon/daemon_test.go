@@ -24,6 +24,7 @@ import (
 	"github.com/sirupsen/logrus"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -104,7 +105,7 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 
 				nodeNameRef := types.NamespacedName{Namespace: _SUPPORTED_NAMESPACE, Name: _THIS_NODE_NAME}
 
-				drainer := func(operation func(ctx context.Context) bool, drain bool) error { return nil }
+				drainer := func(_ context.Context, operation func(ctx context.Context) bool, drain bool) error { return nil }
 
 				var err error
 				reconciler, err = FecNewNodeConfigReconciler(&onGetErrorReturningClient, drainer, nodeNameRef, nil, nil)
@@ -145,19 +146,19 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 
 					reconciler, err := FecNewNodeConfigReconciler(
 						k8sClient,
-						func(configure func(ctx context.Context) bool, drain bool) error {
+						func(_ context.Context, configure func(ctx context.Context) bool, drain bool) error {
 							configure(context.TODO())
 							return nil
 						},
 						nodeNameRef,
 						configurer,
-						func() error {
+						func(context.Context) error {
 							return nil
 						})
 
 					Expect(err).ToNot(HaveOccurred())
 
-					k8sManager, err := CreateManager(config, scheme.Scheme, _SUPPORTED_NAMESPACE, 0, 0, log)
+					k8sManager, err := CreateManager(config, scheme.Scheme, _SUPPORTED_NAMESPACE, _THIS_NODE_NAME, 0, 0, log)
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(reconciler.SetupWithManager(k8sManager)).ToNot(HaveOccurred())
@@ -166,7 +167,7 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 					Expect(k8sClient.Create(context.TODO(), &data.Node)).To(Succeed())
 
 					//initialize empty SriovFecNodeConfig
-					Expect(reconciler.CreateEmptyNodeConfigIfNeeded(k8sClient)).To(Succeed())
+					Expect(reconciler.CreateEmptyNodeConfigIfNeeded(context.TODO(), k8sClient)).To(Succeed())
 					go func() {
 						Expect(k8sManager.Start(context.TODO())).ToNot(HaveOccurred())
 					}()
@@ -222,7 +223,7 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 		Context("Negative scenarios", func() {
 
 			Context("Requested config/spec refers to non existing accelerator", func() {
-				It("spec/config should not be applied, error info should be exposed over configuration ccondition", func() {
+				It("configuration of the missing accelerator should be skipped without failing the node", func() {
 
 					//existing inventory
 					getSriovInventory = func(log *logrus.Logger) (*sriovv2.NodeInventory, error) {
@@ -245,9 +246,8 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 					Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(&data.SriovFecNodeConfig), res)).To(Succeed())
 					Expect(res).To(Not(BeNil()))
 					Expect(res.FindCondition(ConditionConfigured)).To(Not(BeNil()))
-					Expect(res.FindCondition(ConditionConfigured).Reason).To(Equal(string(ConfigurationFailed)))
-					Expect(res.FindCondition(ConditionConfigured).Status).To(Equal(metav1.ConditionFalse))
-					Expect(res.FindCondition(ConditionConfigured).Message).To(ContainSubstring("not existing accelerator"))
+					Expect(res.FindCondition(ConditionConfigured).Reason).ToNot(Equal(string(ConfigurationFailed)))
+					Expect(res.Status.ErrorClass).To(BeEmpty())
 				})
 			})
 
@@ -271,7 +271,7 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(k8sClient).ToNot(BeNil())
 
-					drainer := func(configure func(ctx context.Context) bool, drain bool) error {
+					drainer := func(_ context.Context, configure func(ctx context.Context) bool, drain bool) error {
 						configure(context.TODO())
 						return nil
 					}
@@ -289,7 +289,7 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 						},
 					}
 
-					k8sManager, err := CreateManager(config, scheme.Scheme, _SUPPORTED_NAMESPACE, 0, 0, log)
+					k8sManager, err := CreateManager(config, scheme.Scheme, _SUPPORTED_NAMESPACE, _THIS_NODE_NAME, 0, 0, log)
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(reconciler.SetupWithManager(k8sManager)).ToNot(HaveOccurred())
@@ -380,29 +380,56 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 
 		Expect(nodeConfig.Status.Conditions).To(BeEmpty())
 
-		Expect(reconciler.updateStatus(&nodeConfig, metav1.ConditionUnknown, ConfigurationNotRequested, "Unknown")).To(Succeed())
+		Expect(reconciler.updateStatus(context.TODO(), &nodeConfig, metav1.ConditionUnknown, ConfigurationNotRequested, "Unknown")).To(Succeed())
 
 		res := new(sriovv2.SriovFecNodeConfig)
 		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(&nodeConfig), res)).To(Succeed())
-		Expect(res.Status.Conditions).To(HaveLen(1))
+		// Configured condition is followed by standard Ready, Progressing, Degraded and Ignored conditions and
+		// UnsupportedCombination condition
+		Expect(res.Status.Conditions).To(HaveLen(6))
 		Expect(res.FindCondition(ConditionConfigured)).ToNot(BeNil())
 		Expect(res.FindCondition(ConditionConfigured).Reason).To(ContainSubstring("NotRequested"), "Condition.Reason")
 		Expect(res.FindCondition(ConditionConfigured).Message).To(ContainSubstring("Unknown"), "Condition.Message")
 		Expect(res.FindCondition(ConditionConfigured).Status).To(BeEquivalentTo(metav1.ConditionUnknown), "Condition.Status")
 
-		Expect(reconciler.updateStatus(&nodeConfig, metav1.ConditionTrue, ConfigurationSucceeded, string(ConfigurationSucceeded))).To(Succeed())
+		Expect(reconciler.updateStatus(context.TODO(), &nodeConfig, metav1.ConditionTrue, ConfigurationSucceeded, string(ConfigurationSucceeded))).To(Succeed())
 		res = new(sriovv2.SriovFecNodeConfig)
 		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(&nodeConfig), res)).To(Succeed())
-		Expect(res.Status.Conditions).To(HaveLen(1))
+		Expect(res.Status.Conditions).To(HaveLen(6))
 		Expect(res.FindCondition(ConditionConfigured)).ToNot(BeNil())
 		Expect(res.FindCondition(ConditionConfigured).Status).To(BeEquivalentTo(metav1.ConditionTrue), "Condition.Status")
+		Expect(meta.IsStatusConditionTrue(res.Status.Conditions, sriovv2.ReadyCondition)).To(BeTrue())
 		Expect(res.FindCondition(ConditionConfigured).Message).To(ContainSubstring("Succeeded"), "Condition.Message")
 		Expect(res.FindCondition(ConditionConfigured).Reason).To(ContainSubstring("Succeeded"), "Condition.Reason")
 	})
 
-	Describe("isConfigurationOfNonExistingInventoryRequested()", func() {
+	It("updateStatus() records observedGeneration once outcome of the configuration is known", func() {
+		nodeConfig := sriovv2.SriovFecNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       _THIS_NODE_NAME,
+				Namespace:  _SUPPORTED_NAMESPACE,
+				Generation: 3,
+			},
+		}
+
+		fakeClient := fake.NewClientBuilder().WithObjects(&nodeConfig).Build()
+		reconciler := FecNodeConfigReconciler{Client: fakeClient, log: log}
+
+		Expect(reconciler.updateStatus(context.TODO(), &nodeConfig, metav1.ConditionFalse, ConfigurationInProgress, "Configuration started")).To(Succeed())
+		res := new(sriovv2.SriovFecNodeConfig)
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(&nodeConfig), res)).To(Succeed())
+		Expect(res.Status.ObservedGeneration).To(BeZero())
+
+		Expect(reconciler.updateStatus(context.TODO(), &nodeConfig, metav1.ConditionFalse, ConfigurationFailed, "pf_bb_config failed")).To(Succeed())
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(&nodeConfig), res)).To(Succeed())
+		Expect(res.Status.ObservedGeneration).To(BeEquivalentTo(3))
+		// Configured condition keeps the generation which was applied last
+		Expect(res.FindCondition(ConditionConfigured).ObservedGeneration).To(BeZero())
+	})
+
+	Describe("skipMissingPhysicalFunctions()", func() {
 		When("requested config refers only to exiting inventory", func() {
-			It("error should not be returned", func() {
+			It("nothing should be skipped", func() {
 				requestedConfig := []sriovv2.PhysicalFunctionConfigExt{
 					{PCIAddress: "1"}, {PCIAddress: "2"}, {PCIAddress: "3"},
 				}
@@ -412,12 +439,14 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 						{PCIAddress: "1"}, {PCIAddress: "2"}, {PCIAddress: "3"}, {PCIAddress: "4"},
 					},
 				}
-				Expect(isConfigurationOfNonExistingInventoryRequested(requestedConfig, &inventory)).To(BeFalse())
+				present, missing := skipMissingPhysicalFunctions(requestedConfig, &inventory)
+				Expect(present).To(Equal(requestedConfig))
+				Expect(missing).To(BeEmpty())
 			})
 		})
 
 		When("requested config refers to not exiting inventory", func() {
-			It("error should be returned", func() {
+			It("missing accelerators should be skipped", func() {
 				requestedConfig := []sriovv2.PhysicalFunctionConfigExt{
 					{PCIAddress: "1"}, {PCIAddress: "99"}, {PCIAddress: "3"},
 				}
@@ -427,12 +456,14 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 						{PCIAddress: "1"}, {PCIAddress: "2"}, {PCIAddress: "3"}, {PCIAddress: "4"},
 					},
 				}
-				Expect(isConfigurationOfNonExistingInventoryRequested(requestedConfig, &inventory)).To(BeTrue())
+				present, missing := skipMissingPhysicalFunctions(requestedConfig, &inventory)
+				Expect(present).To(Equal([]sriovv2.PhysicalFunctionConfigExt{{PCIAddress: "1"}, {PCIAddress: "3"}}))
+				Expect(missing).To(Equal([]string{"99"}))
 			})
 		})
 
 		When("empty config requested", func() {
-			It("error should not be returned", func() {
+			It("nothing should be skipped", func() {
 				requestedConfig := []sriovv2.PhysicalFunctionConfigExt{}
 
 				inventory := sriovv2.NodeInventory{
@@ -443,13 +474,275 @@ var _ = Describe("FecNodeConfigReconciler", func() {
 						{PCIAddress: "4"},
 					},
 				}
-				Expect(isConfigurationOfNonExistingInventoryRequested(requestedConfig, &inventory)).To(BeFalse())
+				present, missing := skipMissingPhysicalFunctions(requestedConfig, &inventory)
+				Expect(present).To(BeEmpty())
+				Expect(missing).To(BeEmpty())
 			})
 		})
 	})
 
 })
 
+var _ = Describe("readNodeCapabilities()", func() {
+	var (
+		originalCmdlinePath, originalLockdownPath string
+		originalIsDriverAvailable                 func(context.Context, string, *logrus.Logger) bool
+	)
+
+	BeforeEach(func() {
+		originalCmdlinePath, originalLockdownPath = procCmdlineFilePath, sysLockdownFilePath
+		originalIsDriverAvailable = isDriverAvailable
+		sysLockdownFilePath = filepath.Join(testTmpFolder, "lockdown")
+		isDriverAvailable = func(_ context.Context, driver string, _ *logrus.Logger) bool { return driver == utils.VFIO_PCI }
+	})
+
+	AfterEach(func() {
+		procCmdlineFilePath, sysLockdownFilePath = originalCmdlinePath, originalLockdownPath
+		isDriverAvailable = originalIsDriverAvailable
+	})
+
+	It("reports IOMMU enabled with kernel parameters", func() {
+		procCmdlineFilePath = "testdata/cmdline_test"
+		Expect(readNodeCapabilities(context.TODO(), log)).To(Equal(&sriovv2.NodeCapabilities{IommuEnabled: true, AvailableDrivers: []string{utils.VFIO_PCI}}))
+
+		procCmdlineFilePath = "testdata/cmdline_test_missing_param"
+		Expect(readNodeCapabilities(context.TODO(), log).IommuEnabled).To(BeFalse())
+	})
+
+	It("reports kernel lockdown", func() {
+		procCmdlineFilePath = "testdata/cmdline_test"
+		Expect(os.WriteFile(sysLockdownFilePath, []byte("none [integrity] confidentiality"), 0600)).To(Succeed())
+		Expect(readNodeCapabilities(context.TODO(), log).KernelLockdown).To(BeTrue())
+
+		Expect(os.WriteFile(sysLockdownFilePath, []byte("[none] integrity confidentiality"), 0600)).To(Succeed())
+		Expect(readNodeCapabilities(context.TODO(), log).KernelLockdown).To(BeFalse())
+	})
+
+	It("returns nil when kernel parameters cannot be read", func() {
+		procCmdlineFilePath = "testdata/missing"
+		Expect(readNodeCapabilities(context.TODO(), log)).To(BeNil())
+	})
+})
+
+var _ = Describe("configureVFsMsixCount()", func() {
+	const (
+		pf = "0000:99:00.0"
+		vf = "0000:99:00.1"
+	)
+
+	var (
+		originalSysBusPciDevices string
+		configurator             *NodeConfigurator
+	)
+
+	BeforeEach(func() {
+		originalSysBusPciDevices = sysBusPciDevices
+		sysBusPciDevices = filepath.Join(testTmpFolder, "msix")
+		Expect(createFiles(filepath.Join(sysBusPciDevices, vf), vfMsixCountFile)).To(Succeed())
+		Expect(createFiles(filepath.Join(sysBusPciDevices, pf))).To(Succeed())
+		configurator = &NodeConfigurator{Log: log}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sysBusPciDevices)).To(Succeed())
+		sysBusPciDevices = originalSysBusPciDevices
+	})
+
+	It("writes MSI-X vectors count of VFs", func() {
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pf, vfTotalMsixFile), []byte("64\n"), 0600)).To(Succeed())
+		Expect(configurator.configureVFsMsixCount(pf, []string{vf}, 16)).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(sysBusPciDevices, vf, vfMsixCountFile))).To(BeEquivalentTo("16"))
+	})
+
+	It("fails when PF doesn't have enough MSI-X vectors", func() {
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pf, vfTotalMsixFile), []byte("8"), 0600)).To(Succeed())
+		Expect(configurator.configureVFsMsixCount(pf, []string{vf}, 16)).To(MatchError(ContainSubstring("exceeds 8 available")))
+	})
+
+	It("fails when PF driver doesn't support MSI-X configuration", func() {
+		Expect(configurator.configureVFsMsixCount(pf, []string{vf}, 16)).To(MatchError(ContainSubstring("does not support")))
+		Expect(configurator.configureVFsMsixCount(pf, []string{vf}, 0)).To(Succeed())
+	})
+})
+
+var _ = Describe("configurePowerManagement()", func() {
+	const pf = "0000:99:00.0"
+
+	var (
+		originalSysBusPciDevices string
+		configurator             *NodeConfigurator
+	)
+
+	BeforeEach(func() {
+		originalSysBusPciDevices = sysBusPciDevices
+		sysBusPciDevices = filepath.Join(testTmpFolder, "power")
+		Expect(createFiles(filepath.Join(sysBusPciDevices, pf, "power"), "control")).To(Succeed())
+		Expect(createFiles(filepath.Join(sysBusPciDevices, pf), d3coldAllowedFile)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pf, powerControlFile), []byte("auto\n"), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pf, d3coldAllowedFile), []byte("1\n"), 0600)).To(Succeed())
+		configurator = &NodeConfigurator{Log: log}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sysBusPciDevices)).To(Succeed())
+		sysBusPciDevices = originalSysBusPciDevices
+	})
+
+	It("disables requested power states and reports them", func() {
+		Expect(createFiles(filepath.Join(sysBusPciDevices, pf, aspmLinkDir), "l0s_aspm", "l1_aspm")).To(Succeed())
+
+		Expect(configurator.configurePowerManagement(pf, true, true, true)).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(sysBusPciDevices, pf, aspmLinkDir, "l1_aspm"))).To(BeEquivalentTo("0"))
+
+		applied := appliedPowerManagement([]sriovv2.PhysicalFunctionConfigExt{
+			{PCIAddress: pf, PowerManagement: &sriovv2.PowerManagement{DisableRuntimePM: true}},
+			{PCIAddress: "0000:98:00.0"},
+		})
+		Expect(applied).To(Equal([]sriovv2.AppliedPowerManagement{{PCIAddress: pf, RuntimePM: "on", ASPMDisabled: true, D3ColdAllowed: false}}))
+	})
+
+	It("leaves power states which are not disabled untouched", func() {
+		Expect(configurator.configurePowerManagement(pf, false, false, false)).To(Succeed())
+		runtimePM, aspmDisabled, d3ColdAllowed := readPowerManagement(pf)
+		Expect(runtimePM).To(Equal("auto"))
+		Expect(aspmDisabled).To(BeFalse())
+		Expect(d3ColdAllowed).To(BeTrue())
+	})
+
+	It("fails when ASPM link control is not supported", func() {
+		Expect(configurator.configurePowerManagement(pf, false, true, false)).To(MatchError(ContainSubstring("is not supported")))
+	})
+})
+
+var _ = Describe("Applied configuration adoption", func() {
+	var (
+		reconciler *FecNodeConfigReconciler
+		nc         *sriovv2.SriovFecNodeConfig
+		inventory  *sriovv2.NodeInventory
+	)
+
+	BeforeEach(func() {
+		reconciler = &FecNodeConfigReconciler{log: logrus.New()}
+		nc = &sriovv2.SriovFecNodeConfig{
+			Spec: sriovv2.SriovFecNodeConfigSpec{
+				PhysicalFunctions: []sriovv2.PhysicalFunctionConfigExt{
+					{PCIAddress: pciAddress, PFDriver: utils.PCI_PF_STUB_DASH, VFDriver: utils.VFIO_PCI, VFAmount: 1},
+				},
+			},
+		}
+		inventory = &sriovv2.NodeInventory{
+			SriovAccelerators: []sriovv2.SriovAccelerator{
+				{PCIAddress: pciAddress, VFs: []sriovv2.VF{{PCIAddress: "0000:14:00.2"}}},
+			},
+		}
+	})
+
+	It("adopts configuration which was already applied", func() {
+		nc.Status.AppliedConfigChecksum = configChecksum(nc.Spec.PhysicalFunctions)
+		Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeTrue())
+	})
+
+	It("does not adopt configuration which was never applied", func() {
+		Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeFalse())
+	})
+
+	It("does not adopt changed configuration", func() {
+		nc.Status.AppliedConfigChecksum = configChecksum(nc.Spec.PhysicalFunctions)
+		nc.Spec.PhysicalFunctions[0].VFDriver = utils.PCI_PF_STUB_DASH
+		Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeFalse())
+	})
+
+	It("does not adopt configuration when devices do not reflect it", func() {
+		nc.Status.AppliedConfigChecksum = configChecksum(nc.Spec.PhysicalFunctions)
+		inventory.SriovAccelerators[0].VFs = nil
+		Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeFalse())
+	})
+
+	Context("out-of-band configuration", func() {
+		BeforeEach(func() {
+			nc.Annotations = map[string]string{sriovv2.AdoptAnnotation: "true"}
+			inventory.SriovAccelerators[0].PFDriver = utils.PCI_PF_STUB_DASH
+			inventory.SriovAccelerators[0].VFs[0].Driver = utils.VFIO_PCI
+		})
+
+		It("adopts matching VFs when adoption is requested", func() {
+			Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeTrue())
+		})
+
+		It("does not adopt matching VFs when adoption is not requested", func() {
+			nc.Annotations = nil
+			Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeFalse())
+		})
+
+		It("does not adopt when PF is bound to other driver", func() {
+			inventory.SriovAccelerators[0].PFDriver = utils.VFIO_PCI
+			Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeFalse())
+		})
+
+		It("does not adopt when VF is bound to other driver", func() {
+			inventory.SriovAccelerators[0].VFs[0].Driver = utils.IGB_UIO
+			Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeFalse())
+		})
+
+		It("adopts VFs bound according to driver overrides", func() {
+			nc.Spec.PhysicalFunctions[0].VFAmount = 2
+			nc.Spec.PhysicalFunctions[0].VFDriverOverrides = []sriovv2.VFDriverOverride{{First: 0, Last: 0, Driver: utils.IGB_UIO}}
+			inventory.SriovAccelerators[0].VFs = []sriovv2.VF{
+				{PCIAddress: "0000:14:00.2", Driver: utils.VFIO_PCI},
+				{PCIAddress: "0000:14:00.1", Driver: utils.IGB_UIO},
+			}
+			Expect(reconciler.isAppliedConfigAdoptable(context.TODO(), nc, inventory)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Periodic re-sync", func() {
+	var (
+		reconciler *FecNodeConfigReconciler
+		nc         *sriovv2.SriovFecNodeConfig
+		inventory  *sriovv2.NodeInventory
+	)
+
+	BeforeEach(func() {
+		reconciler = &FecNodeConfigReconciler{log: logrus.New()}
+		nc = &sriovv2.SriovFecNodeConfig{
+			Spec: sriovv2.SriovFecNodeConfigSpec{
+				PhysicalFunctions: []sriovv2.PhysicalFunctionConfigExt{
+					{PCIAddress: pciAddress, PFDriver: utils.PCI_PF_STUB_DASH, VFDriver: utils.VFIO_PCI, VFAmount: 1},
+				},
+			},
+		}
+		inventory = &sriovv2.NodeInventory{
+			SriovAccelerators: []sriovv2.SriovAccelerator{{
+				PCIAddress: pciAddress,
+				PFDriver:   utils.PCI_PF_STUB_DASH,
+				VFs:        []sriovv2.VF{{PCIAddress: "0000:14:00.2", Driver: utils.IGB_UIO}},
+			}},
+		}
+	})
+
+	It("detects drift of drivers only when sync interval is set, once per the interval", func() {
+		Expect(reconciler.isDeviceStateOutdated(context.TODO(), nc, inventory)).To(BeFalse())
+
+		nc.Spec.SyncInterval = &metav1.Duration{Duration: 15 * time.Minute}
+		Expect(reconciler.isDeviceStateOutdated(context.TODO(), nc, inventory)).To(BeTrue())
+		Expect(reconciler.isDeviceStateOutdated(context.TODO(), nc, inventory)).To(BeFalse())
+
+		reconciler.driversVerifiedAt = time.Now().Add(-15 * time.Minute)
+		Expect(reconciler.isDeviceStateOutdated(context.TODO(), nc, inventory)).To(BeTrue())
+
+		inventory.SriovAccelerators[0].VFs[0].Driver = utils.VFIO_PCI
+		reconciler.driversVerifiedAt = time.Time{}
+		Expect(reconciler.isDeviceStateOutdated(context.TODO(), nc, inventory)).To(BeFalse())
+	})
+
+	It("requeues after sync interval or resync period, whichever is shorter", func() {
+		Expect(requeueAfterSyncInterval(nil)).To(Equal(reconcile.Result{RequeueAfter: resyncPeriod}))
+		Expect(requeueAfterSyncInterval(&metav1.Duration{Duration: 15 * time.Minute})).To(Equal(reconcile.Result{RequeueAfter: resyncPeriod}))
+		Expect(requeueAfterSyncInterval(&metav1.Duration{Duration: 30 * time.Second})).To(Equal(reconcile.Result{RequeueAfter: 30 * time.Second}))
+	})
+})
+
 type nodeRecocnilerWrapper struct {
 	*FecNodeConfigReconciler
 	reconcilingFunc func(ctx context.Context, req ctrl.Request) (ctrl.Result, error)
@@ -522,8 +815,9 @@ func (d *TestData) NodeConfigNS() string {
 	return d.SriovFecNodeConfig.Namespace
 }
 
-func initNodeConfiguratorRunExecCmd(f func([]string, *logrus.Logger) (string, error)) {
+func initNodeConfiguratorRunExecCmd(f func(context.Context, []string, *logrus.Logger) (string, error)) {
 	runExecCmd = f
+	hostCommander = HostCommanderFunc(f)
 }
 
 type runExecCmdMock struct {
@@ -544,7 +838,7 @@ func (r *runExecCmdMock) onCall(expected []string) *resultCatcher {
 	return &resultCatcher{toBeReturned: &tbr, mock: r}
 }
 
-func (r *runExecCmdMock) execute(args []string, l *logrus.Logger) (string, error) {
+func (r *runExecCmdMock) execute(_ context.Context, args []string, l *logrus.Logger) (string, error) {
 	l.Info("runExecCmdMock:", "command", args)
 	defer func() { r.executionCount++ }()
 
@@ -595,11 +889,11 @@ func FuzzIsCardUpdateRequired(f *testing.F) {
 		Client:      nil,
 		log:         &logrus.Logger{},
 		nodeNameRef: types.NamespacedName{},
-		drainerAndExecute: func(configurer func(ctx context.Context) bool, drain bool) error {
+		drainerAndExecute: func(_ context.Context, configurer func(ctx context.Context) bool, drain bool) error {
 			return nil
 		},
 		sriovfecconfigurer: nil,
-		restartDevicePlugin: func() error {
+		restartDevicePlugin: func(context.Context) error {
 			return nil
 		},
 	}
@@ -623,7 +917,7 @@ func FuzzIsCardUpdateRequired(f *testing.F) {
 				t.Errorf("Error: %v", icur)
 			}
 		}()
-		_ = icur.isCardUpdateRequired(&sfnc, &detectedInventory)
+		_ = icur.isCardUpdateRequired(context.TODO(), &sfnc, &detectedInventory)
 	})
 }
 
@@ -632,11 +926,11 @@ func FuzzVrbIsCardUpdateRequired(f *testing.F) {
 		Client:      nil,
 		log:         &logrus.Logger{},
 		nodeNameRef: types.NamespacedName{},
-		drainerAndExecute: func(configurer func(ctx context.Context) bool, drain bool) error {
+		drainerAndExecute: func(_ context.Context, configurer func(ctx context.Context) bool, drain bool) error {
 			return nil
 		},
 		vrbconfigurer: nil,
-		restartDevicePlugin: func() error {
+		restartDevicePlugin: func(context.Context) error {
 			return nil
 		},
 	}
@@ -660,7 +954,7 @@ func FuzzVrbIsCardUpdateRequired(f *testing.F) {
 				t.Errorf("Error: %v", vicur)
 			}
 		}()
-		_ = vicur.isCardUpdateRequired(&svnc, &detectedInventory)
+		_ = vicur.isCardUpdateRequired(context.TODO(), &svnc, &detectedInventory)
 	})
 }
 
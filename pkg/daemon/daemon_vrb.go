@@ -10,18 +10,25 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
 	"github.com/intel/sriov-fec-operator/pkg/common/utils"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/types"
 
 	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var (
@@ -37,10 +44,14 @@ type VrbNodeConfigReconciler struct {
 	drainerAndExecute   DrainAndExecute
 	vrbconfigurer       VrbConfigurer
 	restartDevicePlugin RestartDevicePluginFunction
+	// recorder records Events on the NodeConfig, it's set up along with the controller
+	recorder record.EventRecorder
+	// driversVerifiedAt is the time drivers of PFs and VFs were last verified, once per spec.syncInterval
+	driversVerifiedAt time.Time
 }
 
 type VrbConfigurer interface {
-	VrbApplySpec(nodeConfig vrbv1.SriovVrbNodeConfigSpec) error
+	VrbApplySpec(ctx context.Context, nodeConfig vrbv1.SriovVrbNodeConfigSpec) error
 }
 
 /*****************************************************************************
@@ -48,45 +59,101 @@ type VrbConfigurer interface {
  * Description:
  *
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *VrbNodeConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.log.Infof("VrbReconcile(...) triggered by %s", req.NamespacedName.String())
 
-	vrbnc, err := r.readNodeConfig(req.NamespacedName)
+	vrbnc, err := r.readNodeConfig(ctx, req.NamespacedName)
 
 	if err != nil {
 		return requeueNowWithError(err)
 	}
 
-	vrbdetectedInventory, err := r.readExistingInventory()
+	if vrbnc.IsOrphaned() {
+		r.log.Info("SriovVrbNodeConfig is orphaned - keeping current configuration until the operator renders it again")
+		return requeueLater()
+	}
+
+	ttl, err := vrbnc.ManualOverrideTTL()
 	if err != nil {
+		r.log.WithError(err).Warn("manual override is ignored")
+	}
+	if remaining, err := manualOverride(ctx, r.Client, vrbnc, &vrbnc.Status.Conditions, ttl, time.Now()); err != nil {
 		return requeueNowWithError(err)
+	} else if remaining > 0 {
+		r.log.WithField("remaining", remaining.String()).Info("SriovVrbNodeConfig is manually overridden - enforcement is suspended")
+		return ctrl.Result{RequeueAfter: min(remaining, resyncPeriod)}, nil
 	}
 
-	if err := validateVrbNodeConfig(vrbnc.Spec); err != nil {
-		return requeueNowWithError(r.updateStatus(vrbnc, metav1.ConditionFalse, ConfigurationFailed, err.Error()))
+	vrbnc.Status.SelectedPFDrivers = vrbSelectPFDrivers(ctx, vrbnc.Spec.PhysicalFunctions, r.log)
+
+	vrbdetectedInventory, skipped, err := r.readExistingInventory(ctx, r.Client)
+	if err != nil {
+		return requeueNowWithError(err)
 	}
 
-	if VrbisConfigurationOfNonExistingInventoryRequested(vrbnc.Spec.PhysicalFunctions, vrbdetectedInventory) {
-		r.log.Info("requested configuration refers to not existing accelerator(s)")
-		return requeueLaterOrNowIfError(r.updateStatus(vrbnc, metav1.ConditionFalse, ConfigurationFailed, "requested configuration refers to not existing accelerator"))
+	if err := r.handleHotplug(ctx, vrbnc, vrbdetectedInventory, skipped); err != nil {
+		return requeueNowWithError(err)
 	}
 
-	if !r.isCardUpdateRequired(vrbnc, vrbdetectedInventory) {
-		r.log.Info("SriovVrb: Nothing to do")
+	// repeated disruptive configuration attempts are not made against accelerators reporting uncorrectable errors
+	if faulty, err := hardwareFaultFreeze(ctx, r.Client, vrbnc, &vrbnc.Status.Conditions, vrbAcceleratorAddresses(vrbdetectedInventory)); err != nil {
+		return requeueNowWithError(err)
+	} else if len(faulty) != 0 {
+		r.log.WithField("accelerators", faulty).Warn("uncorrectable PCIe errors detected - configuration is frozen")
+		return requeueLater()
+	}
+
+	if err := validateVrbNodeConfig(vrbEffectiveSpec(vrbnc)); err != nil {
+		return requeueNowWithError(r.updateFailedStatus(ctx, vrbnc, errclass.Wrap(errclass.Platform, err)))
+	}
+
+	// configuration of accelerators missing on the node is skipped rather than failing the whole node, it's applied once
+	// they are back; the spec isn't written by the daemon from now on, only the status
+	present, missing := VrbskipMissingPhysicalFunctions(vrbnc.Spec.PhysicalFunctions, vrbdetectedInventory)
+	if len(missing) != 0 {
+		vrbnc.Spec.PhysicalFunctions = present
+		reportMissingAccelerators(r.recorder, vrbnc, missing, r.log)
+	}
+
+	if isRolledBack(VrbfindOrCreateConfigurationStatusCondition(vrbnc), vrbnc.GetGeneration()) {
+		if err := r.reapplyLastKnownGoodIfOutdated(ctx, vrbnc, vrbdetectedInventory); err != nil {
+			return requeueNowWithError(err)
+		}
+		r.log.Info("requested configuration was rolled back - waiting for a new one")
 		return requeueLater()
 	}
 
-	if r.isCardUpdateRequired(vrbnc, vrbdetectedInventory) {
+	if !r.isCardUpdateRequired(ctx, vrbnc, vrbdetectedInventory) {
+		r.log.Info("SriovVrb: Nothing to do")
+		if r.setPostRebootVerification(ctx, vrbnc) {
+			return requeueLaterOrNowIfError(r.Status().Update(ctx, vrbnc))
+		}
+		return requeueAfterSyncInterval(vrbnc.Spec.SyncInterval)
+	}
 
-		if err := r.updateStatus(vrbnc, metav1.ConditionFalse, ConfigurationInProgress, "Configuration started"); err != nil {
+	if r.isAppliedConfigAdoptable(ctx, vrbnc, vrbdetectedInventory) {
+		r.log.Info("requested configuration is already applied - adopting it without reconfiguration")
+		return requeueLaterOrNowIfError(r.updateStatus(ctx, vrbnc, metav1.ConditionTrue, ConfigurationSucceeded, configurationAdoptedMessage))
+	}
+
+	if r.isCardUpdateRequired(ctx, vrbnc, vrbdetectedInventory) {
+
+		// configuration still in progress was interrupted by restart of the daemon
+		resumed := VrbfindOrCreateConfigurationStatusCondition(vrbnc).Reason == string(ConfigurationInProgress)
+		if err := r.updateStatus(ctx, vrbnc, metav1.ConditionFalse, ConfigurationInProgress, "Configuration started"); err != nil {
 			return requeueNowWithError(err)
 		}
 
-		if err := r.configureNode(vrbnc); err != nil {
+		rolledBack, err := r.configureNode(r.withOperationJournal(ctx, vrbnc, resumed), vrbnc, vrbdetectedInventory)
+		if rolledBack {
+			r.log.WithError(err).Error("configuration failed - last-known-good configuration restored")
+			return requeueLaterOrNowIfError(r.updateRolledBackStatus(ctx, vrbnc, err))
+		}
+		if err != nil {
 			r.log.WithError(err).Error("error occurred during configuring node")
-			return requeueNowWithError(r.updateStatus(vrbnc, metav1.ConditionFalse, ConfigurationFailed, err.Error()))
+			return requeueNowWithError(r.updateFailedStatus(ctx, vrbnc, err))
 		} else {
-			return requeueLaterOrNowIfError(r.updateStatus(vrbnc, metav1.ConditionTrue, ConfigurationSucceeded, "Configured successfully"))
+			return requeueLaterOrNowIfError(r.updateStatus(ctx, vrbnc, metav1.ConditionTrue, ConfigurationSucceeded, configuredMessage(missing)))
 		}
 
 	}
@@ -99,10 +166,10 @@ func (r *VrbNodeConfigReconciler) Reconcile(_ context.Context, req ctrl.Request)
  * Description:
  *
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(c client.Client) error {
+func (r *VrbNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(ctx context.Context, c client.Client) error {
 	VrbnodeConfig := &vrbv1.SriovVrbNodeConfig{}
 
-	err := c.Get(context.Background(), client.ObjectKey{Name: r.nodeNameRef.Name, Namespace: r.nodeNameRef.Namespace}, VrbnodeConfig)
+	err := c.Get(ctx, client.ObjectKey{Name: r.nodeNameRef.Name, Namespace: r.nodeNameRef.Namespace}, VrbnodeConfig)
 	if err == nil {
 		r.log.Info("already exists")
 		return nil
@@ -114,38 +181,53 @@ func (r *VrbNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(c client.Client)
 
 	r.log.Infof("VrbnodeConfig{%s} not found - creating", r.nodeNameRef)
 
+	inv, skipped, err := r.readExistingInventory(ctx, c)
+	if err != nil {
+		return err
+	}
+
 	VrbnodeConfig = &vrbv1.SriovVrbNodeConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      r.nodeNameRef.Name,
 			Namespace: r.nodeNameRef.Namespace,
+			Labels:    utils.StandardLabels(utils.SriovVrbNodeConfigName, r.nodeNameRef.Name),
 		},
 		Spec: vrbv1.SriovVrbNodeConfigSpec{
 			PhysicalFunctions: []vrbv1.PhysicalFunctionConfigExt{},
 		},
 	}
 
-	if createErr := c.Create(context.Background(), VrbnodeConfig); createErr != nil {
-		r.log.WithError(createErr).Error("failed to create")
-		return createErr
+	condition := metav1.Condition{
+		Type:    ConditionConfigured,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(ConfigurationNotRequested),
+		Message: "",
 	}
 
-	meta.SetStatusCondition(&VrbnodeConfig.Status.Conditions, metav1.Condition{
-		Type:               ConditionConfigured,
-		Status:             metav1.ConditionFalse,
-		Reason:             string(ConfigurationNotRequested),
-		Message:            "",
-		ObservedGeneration: VrbnodeConfig.GetGeneration(),
-	})
+	// configured accelerators without NodeConfig mean that it was deleted by mistake - the configuration is kept
+	// until the operator renders NodeConfig again
+	if vrbHasConfiguredAccelerators(inv) {
+		r.log.Info("accelerators remain configured - SriovVrbNodeConfig is recreated as orphaned")
+		VrbnodeConfig.Annotations = map[string]string{vrbv1.OrphanedAnnotation: "true"}
+		condition.Reason = string(ConfigurationOrphaned)
+		condition.Message = configurationOrphanedMessage
+	}
 
-	if inv, err := r.readExistingInventory(); err != nil {
-		return err
-	} else {
-		VrbnodeConfig.Status.Inventory = *inv
+	if createErr := c.Create(ctx, VrbnodeConfig); createErr != nil {
+		r.log.WithError(createErr).Error("failed to create")
+		return createErr
 	}
 
-	VrbnodeConfig.Status.PfBbConfVersion = r.getVrbPfBbConfVersion()
+	condition.ObservedGeneration = VrbnodeConfig.GetGeneration()
+	VrbnodeConfig.Status.ObservedGeneration = VrbnodeConfig.GetGeneration()
+	meta.SetStatusCondition(&VrbnodeConfig.Status.Conditions, condition)
+	setStandardConditions(&VrbnodeConfig.Status.Conditions, condition, VrbnodeConfig.GetGeneration(), "")
+	VrbnodeConfig.Status.Inventory = *inv
+	VrbnodeConfig.Status.SkippedAccelerators = skipped
 
-	if updateErr := c.Status().Update(context.Background(), VrbnodeConfig); updateErr != nil {
+	VrbnodeConfig.Status.PfBbConfVersion = r.getVrbPfBbConfVersion(ctx)
+
+	if updateErr := c.Status().Update(ctx, VrbnodeConfig); updateErr != nil {
 		r.log.WithError(updateErr).Error("failed to update cr status")
 		return updateErr
 	}
@@ -159,18 +241,78 @@ func (r *VrbNodeConfigReconciler) CreateEmptyNodeConfigIfNeeded(c client.Client)
  *
  ****************************************************************************/
 func (r *VrbNodeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor(eventRecorderName)
+	nodeConfig := &vrbv1.SriovVrbNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: r.nodeNameRef.Name, Namespace: r.nodeNameRef.Namespace}}
+	watcher := newAcceleratorsWatcher(nodeConfig, func() ([]string, error) {
+		inv, err := VrbgetSriovInventory(r.log)
+		if err != nil {
+			return nil, err
+		}
+		return vrbAcceleratorAddresses(inv), nil
+	}, r.log)
+	if err := mgr.Add(watcher); err != nil {
+		return err
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&vrbv1.SriovVrbNodeConfig{}).
-		WithEventFilter(
+		For(&vrbv1.SriovVrbNodeConfig{}, builder.WithPredicates(
 			predicate.And(
 				resourceNamePredicate{
 					requiredName: r.nodeNameRef.Name,
 					log:          r.log,
 				},
-				predicate.GenerationChangedPredicate{},
+				// manual override annotations suspend and resume enforcement without changing the spec
+				predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}),
 			),
-		).Complete(r)
+		)).
+		Watches(&source.Kind{Type: &corev1.Node{}}, nodeBecameReadyHandler(r.nodeNameRef, r.log)).
+		Watches(&source.Channel{Source: watcher.events}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
+
+// updateFailedStatus reports failed configuration along with class of the error
+func (r *VrbNodeConfigReconciler) updateFailedStatus(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, err error) error {
+	nc.Status.ErrorClass = string(errclass.Record(errclass.Daemon, err))
+	return r.updateStatus(ctx, nc, metav1.ConditionFalse, failureReason(err), err.Error())
+}
+
+// handleHotplug reports accelerators hot-plugged or removed at runtime in status inventory of the NodeConfig, so
+// the operator renders configuration for them. Device plugin is restarted to stop exposing resources of removed ones.
+func (r *VrbNodeConfigReconciler) handleHotplug(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, detected *vrbv1.NodeInventory, skipped []vrbv1.SkippedAccelerator) error {
+	added, removed := acceleratorsChanges(vrbAcceleratorAddresses(&nc.Status.Inventory), vrbAcceleratorAddresses(detected))
+	if len(added)+len(removed) == 0 {
+		return nil
+	}
+
+	r.log.WithField("added", added).WithField("removed", removed).Info("accelerators changed - updating SriovVrbNodeConfig inventory")
+	nc.Status.Inventory = *detected
+	nc.Status.SkippedAccelerators = skipped
+	if err := r.Status().Update(ctx, nc); err != nil {
+		r.log.WithError(err).Error("failed to update cr status")
+		return err
+	}
+
+	if len(removed) != 0 {
+		if err := r.restartDevicePlugin(ctx); err != nil {
+			r.log.WithError(err).Error("failed to restart device plugin after accelerators removal")
+			return err
+		}
+	}
+	return nil
+}
+
+func vrbAcceleratorAddresses(inv *vrbv1.NodeInventory) []string {
+	var addresses []string
+	for _, acc := range inv.SriovAccelerators {
+		addresses = append(addresses, acc.PCIAddress)
+	}
+	return addresses
+}
+
+// updateRolledBackStatus reports failed configuration, which was replaced with the last-known-good one
+func (r *VrbNodeConfigReconciler) updateRolledBackStatus(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, err error) error {
+	nc.Status.ErrorClass = string(errclass.Record(errclass.Daemon, err))
+	return r.updateStatus(ctx, nc, metav1.ConditionFalse, ConfigurationRolledBack, "Configuration failed, last-known-good configuration restored: "+err.Error())
 }
 
 /*****************************************************************************
@@ -178,7 +320,7 @@ func (r *VrbNodeConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
  * Description:
  *
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) updateStatus(nc *vrbv1.SriovVrbNodeConfig,
+func (r *VrbNodeConfigReconciler) updateStatus(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig,
 	status metav1.ConditionStatus,
 	reason ConfigurationConditionReason, msg string) error {
 
@@ -186,9 +328,10 @@ func (r *VrbNodeConfigReconciler) updateStatus(nc *vrbv1.SriovVrbNodeConfig,
 
 	// SriovFecNodeConfig.generation is under K8S management
 	// metav1.Condition.observedGeneration is under this reconciler management.
-	// observedGeneration would be incremented then and only then when spec which comes with updated generation would be processed without any error.
+	// observedGeneration would be incremented then and only then when spec which comes with updated generation would be processed without any error,
+	// or when it failed and the last-known-good configuration was restored (it isn't applied again then).
 	determineGeneration := func() int64 {
-		if reason == ConfigurationSucceeded {
+		if reason == ConfigurationSucceeded || reason == ConfigurationRolledBack {
 			return nc.GetGeneration()
 		} else {
 			return previousCondition.ObservedGeneration
@@ -203,23 +346,44 @@ func (r *VrbNodeConfigReconciler) updateStatus(nc *vrbv1.SriovVrbNodeConfig,
 		ObservedGeneration: determineGeneration(),
 	}
 
+	if reason != ConfigurationFailed && reason != ConfigurationRolledBack && reason != ConfigurationTimeout {
+		nc.Status.ErrorClass = ""
+	}
 	meta.SetStatusCondition(&nc.Status.Conditions, condition)
-	if inv, err := VrbgetSriovInventory(r.log); err != nil {
+	// status.observedGeneration tells the generation the outcome is reported for, even if it failed
+	if reason != ConfigurationInProgress {
+		nc.Status.ObservedGeneration = nc.GetGeneration()
+	}
+	setStandardConditions(&nc.Status.Conditions, condition, nc.GetGeneration(), nc.Status.ErrorClass)
+	if reason == ConfigurationSucceeded {
+		nc.Status.AppliedConfigChecksum = configChecksum(nc.Spec.PhysicalFunctions)
+		nc.Status.LastKnownGoodPhysicalFunctions = nc.Spec.PhysicalFunctions
+	}
+	nc.Status.PowerManagement = vrbAppliedPowerManagement(nc.Spec.PhysicalFunctions)
+	if inv, skipped, err := r.readExistingInventory(ctx, r.Client); err != nil {
 		r.log.WithError(err).
 			WithField("reason", condition.Reason).
 			WithField("message", condition.Message).
 			Error("failed to obtain sriov inventory for the node")
 	} else {
 		nc.Status.Inventory = *inv
+		nc.Status.SkippedAccelerators = skipped
 	}
+	nc.Status.Capabilities = (*vrbv1.NodeCapabilities)(readNodeCapabilities(ctx, r.log))
+	if reason != ConfigurationInProgress {
+		r.setPostRebootVerification(ctx, nc)
+	}
+	setUnsupportedCombination(&nc.Status.Conditions, nc.GetGeneration(), vrbCombinations(nc), nc.AllowsUnsupported())
+	setWorkdirFallback(&nc.Status.Conditions, nc.GetGeneration())
 
-	if err := r.Status().Update(context.Background(), nc); err != nil {
+	if err := r.Status().Update(ctx, nc); err != nil {
 		return err
 	}
 
 	r.log.WithField("previous", previousCondition).
 		WithField("current", condition).
 		Infof("%s condition transition", ConditionConfigured)
+	recordConfigurationEvent(r.recorder, nc, previousCondition, condition)
 
 	return nil
 }
@@ -227,14 +391,22 @@ func (r *VrbNodeConfigReconciler) updateStatus(nc *vrbv1.SriovVrbNodeConfig,
 /*****************************************************************************
  * Method: VrbNodeConfigReconciler::readExistingInventory
  * Description:
- *
+ * returns inventory of the node without accelerators skipped by node's
+ * annotation, which are returned separately
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) readExistingInventory() (*vrbv1.NodeInventory, error) {
+func (r *VrbNodeConfigReconciler) readExistingInventory(ctx context.Context, c client.Reader) (*vrbv1.NodeInventory, []vrbv1.SkippedAccelerator, error) {
 	inv, err := VrbgetSriovInventory(r.log)
 	if err != nil {
 		r.log.WithError(err).Error("failed to obtain sriov inventory for the node")
+		return nil, nil, err
 	}
-	return inv, err
+	skipped, err := readSkippedDevices(ctx, c, r.nodeNameRef.Name)
+	if err != nil {
+		r.log.WithError(err).Error("failed to read accelerators skipped on the node")
+		return nil, nil, err
+	}
+	vrbWithAssetMetadata(ctx, c, r.nodeNameRef.Namespace, inv, r.log)
+	return inv, vrbWithoutSkippedAccelerators(inv, skipped), nil
 }
 
 /*****************************************************************************
@@ -242,10 +414,10 @@ func (r *VrbNodeConfigReconciler) readExistingInventory() (*vrbv1.NodeInventory,
  * Description:
  *
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) readNodeConfig(nn types.NamespacedName) (nc *vrbv1.SriovVrbNodeConfig, err error) {
+func (r *VrbNodeConfigReconciler) readNodeConfig(ctx context.Context, nn types.NamespacedName) (nc *vrbv1.SriovVrbNodeConfig, err error) {
 	getVrbNodeConfig := func() (*vrbv1.SriovVrbNodeConfig, error) {
 		vrbnc := new(vrbv1.SriovVrbNodeConfig)
-		if err := r.Client.Get(context.TODO(), nn, vrbnc); err != nil {
+		if err := r.Client.Get(ctx, nn, vrbnc); err != nil {
 			return nil, err
 		}
 		return vrbnc, nil
@@ -258,7 +430,7 @@ func (r *VrbNodeConfigReconciler) readNodeConfig(nn types.NamespacedName) (nc *v
 		}
 
 		r.log.Info("SriovVrbNodeConfig not found - creating")
-		if err := r.CreateEmptyNodeConfigIfNeeded(r.Client); err != nil {
+		if err := r.CreateEmptyNodeConfigIfNeeded(ctx, r.Client); err != nil {
 			r.log.WithError(err).Error("Couldn't create SriovVrbNodeConfig")
 			return nil, err
 		}
@@ -276,25 +448,84 @@ func (r *VrbNodeConfigReconciler) readNodeConfig(nn types.NamespacedName) (nc *v
  * Description:
  *
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) configureNode(nodeConfig *vrbv1.SriovVrbNodeConfig) error {
+func (r *VrbNodeConfigReconciler) configureNode(ctx context.Context, nodeConfig *vrbv1.SriovVrbNodeConfig, inventory *vrbv1.NodeInventory) (rolledBack bool, err error) {
 	var configurationError error
 
 	drainFunc := func(ctx context.Context) bool {
-		if err := r.vrbconfigurer.VrbApplySpec(nodeConfig.Spec); err != nil {
+		if err := r.vrbconfigurer.VrbApplySpec(ctx, vrbEffectiveSpec(nodeConfig)); err != nil {
 			r.log.WithError(err).Error("failed applying new PF/VF configuration")
 			configurationError = err
-			return true
+			if rolledBack = r.restoreLastKnownGood(ctx, nodeConfig, err); !rolledBack {
+				return true
+			}
 		}
 
-		configurationError = r.restartDevicePlugin()
+		if err := r.restartDevicePlugin(ctx); err != nil {
+			if configurationError != nil {
+				r.log.WithError(err).Error("failed to restart device plugin after rollback")
+			} else {
+				configurationError = err
+			}
+		}
 		return true
 	}
 
-	if err := r.drainerAndExecute(drainFunc, !nodeConfig.Spec.DrainSkip); err != nil {
-		return err
+	drain := !nodeConfig.Spec.DrainSkip
+	if drain && nodeConfig.Spec.DrainPolicy == vrbv1.DrainIfDisruptive && !vrbHasVFs(inventory) {
+		r.log.Info("no accelerator of the node has VFs - configuration is not disruptive, skipping drain")
+		drain = false
+	}
+
+	if err := r.drainerAndExecute(r.withDrainProgress(ctx, nodeConfig), drainFunc, drain); err != nil {
+		return false, errclass.Wrap(errclass.TransientInfra, err)
+	}
+
+	return rolledBack, errclass.Wrap(errclass.Device, configurationError)
+}
+
+/*****************************************************************************
+ * Method: VrbNodeConfigReconciler::restoreLastKnownGood
+ * Description:
+ * applies the last successfully applied configuration after configuration
+ * of a new spec failed because of the device; returns true if it was restored
+ ****************************************************************************/
+func (r *VrbNodeConfigReconciler) restoreLastKnownGood(ctx context.Context, nodeConfig *vrbv1.SriovVrbNodeConfig, err error) bool {
+	lastKnownGood := nodeConfig.Status.LastKnownGoodPhysicalFunctions
+	if len(lastKnownGood) == 0 || !isRollbackApplicable(err) ||
+		configChecksum(lastKnownGood) == configChecksum(nodeConfig.Spec.PhysicalFunctions) {
+		return false
 	}
 
-	return configurationError
+	spec := nodeConfig.Spec.DeepCopy()
+	spec.PhysicalFunctions = vrbWithSelectedPFDrivers(lastKnownGood, nodeConfig.Status.SelectedPFDrivers)
+	if err := r.vrbconfigurer.VrbApplySpec(ctx, *spec); err != nil {
+		r.log.WithError(err).Error("failed restoring last-known-good PF/VF configuration")
+		return false
+	}
+	r.log.Info("last-known-good PF/VF configuration restored")
+	return true
+}
+
+/*****************************************************************************
+ * Method: VrbNodeConfigReconciler::reapplyLastKnownGoodIfOutdated
+ * Description:
+ * applies the last-known-good configuration again when devices don't
+ * reflect it anymore (e.g. after reboot of the node), while the rolled back
+ * generation is still requested
+ ****************************************************************************/
+func (r *VrbNodeConfigReconciler) reapplyLastKnownGoodIfOutdated(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, inventory *vrbv1.NodeInventory) error {
+	lastKnownGood := nc.DeepCopy()
+	lastKnownGood.Spec.PhysicalFunctions = nc.Status.LastKnownGoodPhysicalFunctions
+	if len(lastKnownGood.Spec.PhysicalFunctions) == 0 || !r.isDeviceStateOutdated(ctx, lastKnownGood, inventory) {
+		return nil
+	}
+
+	r.log.Info("devices don't reflect last-known-good configuration - re-applying it")
+	if _, err := r.configureNode(ctx, lastKnownGood, inventory); err != nil {
+		r.log.WithError(err).Error("failed re-applying last-known-good configuration")
+		return err
+	}
+	return nil
 }
 
 /*****************************************************************************
@@ -302,12 +533,8 @@ func (r *VrbNodeConfigReconciler) configureNode(nodeConfig *vrbv1.SriovVrbNodeCo
  * Description:
  *
  ****************************************************************************/
-func (r *VrbNodeConfigReconciler) isCardUpdateRequired(nc *vrbv1.SriovVrbNodeConfig,
+func (r *VrbNodeConfigReconciler) isCardUpdateRequired(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig,
 	detectedInventory *vrbv1.NodeInventory) bool {
-	pciToVfsAmount := map[string]int{}
-	for _, physicalFunction := range nc.Spec.PhysicalFunctions {
-		pciToVfsAmount[physicalFunction.PCIAddress] = physicalFunction.VFAmount
-	}
 	isGenerationChanged := func() bool {
 		observedGeneration := VrbfindOrCreateConfigurationStatusCondition(nc).ObservedGeneration
 		if nc.GetGeneration() != observedGeneration {
@@ -327,6 +554,19 @@ func (r *VrbNodeConfigReconciler) isCardUpdateRequired(nc *vrbv1.SriovVrbNodeCon
 		return false
 	}
 
+	return isGenerationChanged() || r.isDeviceStateOutdated(ctx, nc, detectedInventory)
+}
+
+/*****************************************************************************
+ * Method: VrbNodeConfigReconciler::isDeviceStateOutdated
+ * Description:
+ * returns true if state of devices doesn't reflect requested spec
+ ****************************************************************************/
+func (r *VrbNodeConfigReconciler) isDeviceStateOutdated(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, detectedInventory *vrbv1.NodeInventory) bool {
+	pciToVfsAmount := map[string]int{}
+	for _, physicalFunction := range nc.Spec.PhysicalFunctions {
+		pciToVfsAmount[physicalFunction.PCIAddress] = physicalFunction.VFAmount
+	}
 	exposedInventoryOutdated := func() bool {
 		for _, accelerator := range detectedInventory.SriovAccelerators {
 			if len(accelerator.VFs) != pciToVfsAmount[accelerator.PCIAddress] {
@@ -341,9 +581,9 @@ func (r *VrbNodeConfigReconciler) isCardUpdateRequired(nc *vrbv1.SriovVrbNodeCon
 	}
 
 	bbDevConfigDaemonIsDead := func() bool {
-		for _, acc := range nc.Spec.PhysicalFunctions {
+		for _, acc := range vrbWithSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers) {
 			if strings.EqualFold(acc.PFDriver, utils.VFIO_PCI) {
-				if pfBbConfigProcIsDead(r.log, acc.PCIAddress) {
+				if pfBbConfigProcIsDead(ctx, r.log, acc.PCIAddress) {
 					r.log.WithField("pciAddress", acc.PCIAddress).
 						Info("pf-bb-config process for card is not running")
 					return true
@@ -353,7 +593,64 @@ func (r *VrbNodeConfigReconciler) isCardUpdateRequired(nc *vrbv1.SriovVrbNodeCon
 		return false
 	}
 
-	return isGenerationChanged() || exposedInventoryOutdated() || bbDevConfigDaemonIsDead()
+	// drivers of PFs and VFs, which may be changed manually in sysfs, are verified once per sync interval when periodic
+	// re-sync is requested, while the rest is verified on every resync
+	driversDrifted := func() bool {
+		if nc.Spec.SyncInterval == nil || time.Since(r.driversVerifiedAt) < nc.Spec.SyncInterval.Duration {
+			return false
+		}
+		r.driversVerifiedAt = time.Now()
+		return !r.isOutOfBandConfigMatching(nc, detectedInventory)
+	}
+
+	return exposedInventoryOutdated() || bbDevConfigDaemonIsDead() || driversDrifted()
+}
+
+/*****************************************************************************
+ * Method: VrbNodeConfigReconciler::isAppliedConfigAdoptable
+ * Description:
+ * returns true if requested spec has been already applied (e.g. before
+ * operator upgrade, or out-of-band when adoption is requested) and devices
+ * still reflect it, so reconfiguration can be skipped
+ ****************************************************************************/
+func (r *VrbNodeConfigReconciler) isAppliedConfigAdoptable(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, detectedInventory *vrbv1.NodeInventory) bool {
+	appliedByOperator := nc.Status.AppliedConfigChecksum != "" && nc.Status.AppliedConfigChecksum == configChecksum(nc.Spec.PhysicalFunctions)
+	if !appliedByOperator && !(nc.IsAdoptionRequested() && r.isOutOfBandConfigMatching(nc, detectedInventory)) {
+		return false
+	}
+	return !r.isDeviceStateOutdated(ctx, nc, detectedInventory)
+}
+
+/*****************************************************************************
+ * Method: VrbNodeConfigReconciler::isOutOfBandConfigMatching
+ * Description:
+ * returns true if drivers of PFs and VFs found on the node exactly match
+ * requested spec
+ ****************************************************************************/
+func (r *VrbNodeConfigReconciler) isOutOfBandConfigMatching(nc *vrbv1.SriovVrbNodeConfig, detectedInventory *vrbv1.NodeInventory) bool {
+	accelerators := map[string]vrbv1.SriovAccelerator{}
+	for _, acc := range detectedInventory.SriovAccelerators {
+		accelerators[acc.PCIAddress] = acc
+	}
+
+	physicalFunctions := vrbWithSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers)
+	for i := range physicalFunctions {
+		pf := &physicalFunctions[i]
+		acc, ok := accelerators[pf.PCIAddress]
+		if !ok || !strings.EqualFold(acc.PFDriver, pf.PFDriver) {
+			r.log.WithField("pciAddress", pf.PCIAddress).Info("PF driver doesn't match requested one")
+			return false
+		}
+		var boundDrivers []string
+		for _, vf := range acc.VFs {
+			boundDrivers = append(boundDrivers, vf.Driver)
+		}
+		if !vfDriversMatch(pf.VFAmount, pf.VFDriverFor, boundDrivers) {
+			r.log.WithField("pciAddress", pf.PCIAddress).Info("VFs don't match requested ones")
+			return false
+		}
+	}
+	return true
 }
 
 /*****************************************************************************
@@ -367,10 +664,11 @@ func VrbNewNodeConfigReconciler(k8sClient client.Client, drainer DrainAndExecute
 		return nil, err
 	}
 
+	log := utils.NewLogger()
 	return &VrbNodeConfigReconciler{
-		Client:              k8sClient,
+		Client:              newRateLimitedStatusClient(k8sClient, log),
 		drainerAndExecute:   drainer,
-		log:                 utils.NewLogger(),
+		log:                 log,
 		nodeNameRef:         nodeNameRef,
 		vrbconfigurer:       vrbconfigurer,
 		restartDevicePlugin: restartDevicePluginFunction,
@@ -397,24 +695,24 @@ func VrbfindOrCreateConfigurationStatusCondition(nc *vrbv1.SriovVrbNodeConfig) m
 }
 
 /*****************************************************************************
- * Function: VrbisConfigurationOfNonExistingInventoryRequested
- * Description:
- *
+ * Function: VrbskipMissingPhysicalFunctions
+ * Description: returns physical functions of the requested configuration
+ * which accelerators are present in the inventory, and PCI addresses of the rest
  ****************************************************************************/
-func VrbisConfigurationOfNonExistingInventoryRequested(
+func VrbskipMissingPhysicalFunctions(
 	requestedConfiguration []vrbv1.PhysicalFunctionConfigExt,
-	existingInventory *vrbv1.NodeInventory) bool {
+	existingInventory *vrbv1.NodeInventory) (present []vrbv1.PhysicalFunctionConfigExt, missing []string) {
 OUTER:
 	for _, pf := range requestedConfiguration {
 		for _, acc := range existingInventory.SriovAccelerators {
 			if acc.PCIAddress == pf.PCIAddress {
+				present = append(present, pf)
 				continue OUTER
 			}
 		}
-
-		return true
+		missing = append(missing, pf.PCIAddress)
 	}
-	return false
+	return present, missing
 }
 
 /*****************************************************************************
@@ -451,16 +749,16 @@ func validateVrbNodeConfig(nodeConfig vrbv1.SriovVrbNodeConfigSpec) error {
 				return err
 			}
 		default:
-			return fmt.Errorf("unknown driver '%s'", physFunc.PFDriver)
+			return errclass.New(errclass.Validation, "unknown driver '%s'", physFunc.PFDriver)
 		}
 	}
 	return nil
 }
 
-func (r *VrbNodeConfigReconciler) getVrbPfBbConfVersion() string {
+func (r *VrbNodeConfigReconciler) getVrbPfBbConfVersion(ctx context.Context) string {
 	pfConfigAppFilepath = "/sriov_workdir/pf_bb_config"
 	cmdString := fmt.Sprintf("%s version 2>/dev/null | sed -n 's/.*Version \\(\\S*\\) .*/\\1/p' | tr -d '\\n'", pfConfigAppFilepath)
-	cmd := exec.Command("bash", "-c", cmdString)
+	cmd := exec.CommandContext(ctx, "bash", "-c", cmdString)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	err := cmd.Run()
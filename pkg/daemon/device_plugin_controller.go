@@ -30,9 +30,9 @@ type DevicePluginController struct {
 	nodeNameRef types.NamespacedName
 }
 
-func (d *DevicePluginController) RestartDevicePlugin() error {
+func (d *DevicePluginController) RestartDevicePlugin(ctx context.Context) error {
 	pods := &corev1.PodList{}
-	err := d.List(context.TODO(), pods,
+	err := d.List(ctx, pods,
 		client.InNamespace(d.nodeNameRef.Namespace),
 		&client.MatchingLabels{"app": "sriov-device-plugin-daemonset"})
 
@@ -47,12 +47,12 @@ func (d *DevicePluginController) RestartDevicePlugin() error {
 		if pod.Spec.NodeName != d.nodeNameRef.Name {
 			continue
 		}
-		if err := d.Delete(context.TODO(), &pod, &client.DeleteOptions{}); err != nil {
+		if err := d.Delete(ctx, &pod, &client.DeleteOptions{}); err != nil {
 			return errors.Wrap(err, "failed to delete sriov-device-plugin-daemonset pod")
 		}
 
 		backoff := wait.Backoff{Steps: 300, Duration: 1 * time.Second, Factor: 1}
-		err = wait.ExponentialBackoff(backoff, d.waitForDevicePluginRestart(pod.Name))
+		err = wait.ExponentialBackoffWithContext(ctx, backoff, d.waitForDevicePluginRestart(ctx, pod.Name))
 		if err == wait.ErrWaitTimeout {
 			return fmt.Errorf("failed to restart sriov-device-plugin within specified time")
 		}
@@ -61,11 +61,11 @@ func (d *DevicePluginController) RestartDevicePlugin() error {
 	return nil
 }
 
-func (d *DevicePluginController) waitForDevicePluginRestart(oldPodName string) func() (bool, error) {
+func (d *DevicePluginController) waitForDevicePluginRestart(ctx context.Context, oldPodName string) func() (bool, error) {
 	return func() (bool, error) {
 		pods := &corev1.PodList{}
 
-		err := d.List(context.TODO(), pods,
+		err := d.List(ctx, pods,
 			client.InNamespace(d.nodeNameRef.Namespace),
 			&client.MatchingLabels{"app": "sriov-device-plugin-daemonset"})
 		if err != nil {
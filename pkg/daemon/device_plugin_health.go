@@ -0,0 +1,404 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	ConditionDevicePluginHealthy = "DevicePluginHealthy"
+	DevicePluginHealthy          = "Healthy"
+	DevicePluginDiverged         = "Diverged"
+
+	// ConditionStaleDevicePluginResources is set while node offers resources of the device plugin which no configured VF
+	// of the node matches, e.g. after deconfiguration of the accelerator
+	ConditionStaleDevicePluginResources = "StaleDevicePluginResources"
+	NoMatchingVFs                       = "NoMatchingVFs"
+
+	devicePluginConfigMapName         = "sriovdp-config"
+	devicePluginConfigKey             = "config.json"
+	defaultDevicePluginResourcePrefix = "intel.com"
+)
+
+var (
+	// devicePluginHealthGracePeriod is time advertised resources may diverge from configured VFs, e.g. while the device
+	// plugin restarts, before the divergence is reported
+	devicePluginHealthGracePeriod = 2 * time.Minute
+
+	restartUnhealthyDevicePluginEnv = utils.SRIOV_PREFIX + "RESTART_UNHEALTHY_DEVICE_PLUGIN"
+)
+
+// devicePluginResource is a resource of the device plugin's resourceList
+type devicePluginResource struct {
+	ResourceName   string `json:"resourceName"`
+	ResourcePrefix string `json:"resourcePrefix,omitempty"`
+	Selectors      struct {
+		Vendors      []string `json:"vendors"`
+		Devices      []string `json:"devices"`
+		Drivers      []string `json:"drivers"`
+		PCIAddresses []string `json:"pciAddresses"`
+	} `json:"selectors"`
+}
+
+// configuredVF is a VF configured by the daemon along with vendor of its accelerator
+type configuredVF struct {
+	fec.VF
+	VendorID string
+}
+
+func (r devicePluginResource) name() string {
+	prefix := r.ResourcePrefix
+	if prefix == "" {
+		prefix = defaultDevicePluginResourcePrefix
+	}
+	return prefix + "/" + r.ResourceName
+}
+
+// matches returns true if the VF is selected by the resource; as in the device plugin, selectors which are not set don't
+// restrict the resource and the VF has to match all the others, e.g. pciAddresses of VFs of a FecPool
+func (r devicePluginResource) matches(vf configuredVF) bool {
+	selects := func(selector []string, value string) bool {
+		return len(selector) == 0 || contains(selector, value)
+	}
+	return selects(r.Selectors.Vendors, vf.VendorID) && selects(r.Selectors.Devices, vf.DeviceID) &&
+		selects(r.Selectors.Drivers, vf.Driver) && selects(r.Selectors.PCIAddresses, vf.PCIAddress)
+}
+
+// devicePluginHealthReconciler compares VFs advertised by the device plugin in node's allocatable resources with VFs
+// configured by the daemon, and reports the result in DevicePluginHealthy condition of node's NodeConfigs
+type devicePluginHealthReconciler struct {
+	client.Client
+	log                 *logrus.Logger
+	nodeNameRef         types.NamespacedName
+	restartDevicePlugin RestartDevicePluginFunction
+	// restartOnDivergence enables restart of the device plugin when it doesn't advertise configured VFs
+	restartOnDivergence bool
+	// divergedSince is the time advertised resources started to diverge from configured VFs, zero if they match
+	divergedSince time.Time
+	// restartedFor are stale resources the device plugin was already restarted for, while node still offers them
+	restartedFor map[corev1.ResourceName]bool
+}
+
+// SetupDevicePluginHealth sets up reporting of device plugin's health; the device plugin is restarted when it
+// doesn't advertise configured VFs, if enabled with SRIOV_FEC_RESTART_UNHEALTHY_DEVICE_PLUGIN
+func SetupDevicePluginHealth(mgr ctrl.Manager, nodeNameRef types.NamespacedName, restartDevicePlugin RestartDevicePluginFunction, log *logrus.Logger) error {
+	r := &devicePluginHealthReconciler{
+		Client:              mgr.GetClient(),
+		log:                 log,
+		nodeNameRef:         nodeNameRef,
+		restartDevicePlugin: restartDevicePlugin,
+		restartOnDivergence: strings.EqualFold(os.Getenv(restartUnhealthyDevicePluginEnv), "true"),
+	}
+
+	isNodeConfigOfTheNode := predicate.NewPredicateFuncs(func(o client.Object) bool { return o.GetName() == nodeNameRef.Name })
+	isDevicePluginConfig := predicate.NewPredicateFuncs(func(o client.Object) bool { return o.GetName() == devicePluginConfigMapName })
+	toNodeConfigOfTheNode := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: nodeNameRef}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("device-plugin-health").
+		For(&fec.SriovFecNodeConfig{}, builder.WithPredicates(isNodeConfigOfTheNode)).
+		Watches(&source.Kind{Type: &vrbv1.SriovVrbNodeConfig{}}, toNodeConfigOfTheNode, builder.WithPredicates(isNodeConfigOfTheNode)).
+		Watches(&source.Kind{Type: &corev1.Node{}}, toNodeConfigOfTheNode, builder.WithPredicates(isNodeConfigOfTheNode)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, toNodeConfigOfTheNode, builder.WithPredicates(isDevicePluginConfig)).
+		Complete(r)
+}
+
+func (r *devicePluginHealthReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	resources, err := r.readDevicePluginResources(ctx)
+	if err != nil || resources == nil {
+		return reconcile.Result{}, err
+	}
+
+	node := new(corev1.Node)
+	if err := r.Get(ctx, client.ObjectKey{Name: r.nodeNameRef.Name}, node); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	fecNodeConfig, vrbNodeConfig, err := r.readNodeConfigs(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var vfs []configuredVF
+	if fecNodeConfig != nil {
+		if isConfigurationInProgress(fecNodeConfig.Status.Conditions) {
+			return reconcile.Result{RequeueAfter: devicePluginHealthGracePeriod}, nil
+		}
+		for _, acc := range fecNodeConfig.Status.Inventory.SriovAccelerators {
+			for _, vf := range acc.VFs {
+				vfs = append(vfs, configuredVF{VF: vf, VendorID: acc.VendorID})
+			}
+		}
+	}
+	if vrbNodeConfig != nil {
+		if isConfigurationInProgress(vrbNodeConfig.Status.Conditions) {
+			return reconcile.Result{RequeueAfter: devicePluginHealthGracePeriod}, nil
+		}
+		for _, acc := range vrbNodeConfig.Status.Inventory.SriovAccelerators {
+			for _, vf := range acc.VFs {
+				vfs = append(vfs, configuredVF{VF: fec.VF(vf), VendorID: acc.VendorID})
+			}
+		}
+	}
+
+	stale := staleDevicePluginResources(resources, vfs, node.Status)
+	if err := r.restartForStaleResources(ctx, stale); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// node offers stale resources until kubelet notices the restarted device plugin doesn't advertise them anymore
+	resources = slices.DeleteFunc(resources, func(resource devicePluginResource) bool {
+		return slices.ContainsFunc(stale, func(s devicePluginResource) bool { return s.name() == resource.name() })
+	})
+	divergences := devicePluginDivergences(resources, vfs, node.Status.Allocatable)
+	// health is nil within the grace period, so the reported health is kept
+	var health *metav1.Condition
+	result := reconcile.Result{}
+	if len(divergences) == 0 {
+		r.divergedSince = time.Time{}
+		health = &metav1.Condition{
+			Type:    ConditionDevicePluginHealthy,
+			Status:  metav1.ConditionTrue,
+			Reason:  DevicePluginHealthy,
+			Message: "Device plugin advertises all configured VFs",
+		}
+	} else {
+		if r.divergedSince.IsZero() {
+			r.divergedSince = time.Now()
+		}
+		if remaining := devicePluginHealthGracePeriod - time.Since(r.divergedSince); remaining > 0 {
+			result = reconcile.Result{RequeueAfter: remaining}
+		} else {
+			health = &metav1.Condition{
+				Type:    ConditionDevicePluginHealthy,
+				Status:  metav1.ConditionFalse,
+				Reason:  DevicePluginDiverged,
+				Message: strings.Join(divergences, "; "),
+			}
+			result = reconcile.Result{RequeueAfter: devicePluginHealthGracePeriod}
+		}
+	}
+	staleCondition := staleResourcesCondition(stale)
+
+	if fecNodeConfig != nil {
+		if err := r.setConditions(ctx, fecNodeConfig, &fecNodeConfig.Status.Conditions, health, staleCondition); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	if vrbNodeConfig != nil {
+		if err := r.setConditions(ctx, vrbNodeConfig, &vrbNodeConfig.Status.Conditions, health, staleCondition); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if health != nil && health.Status == metav1.ConditionFalse && r.restartOnDivergence && r.restartDevicePlugin != nil {
+		r.log.WithField("divergences", health.Message).Info("device plugin doesn't advertise configured VFs - restarting it")
+		r.divergedSince = time.Time{}
+		if err := r.restartDevicePlugin(ctx); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// readDevicePluginResources returns resources of the device plugin's config, nil if the device plugin is not deployed
+func (r *devicePluginHealthReconciler) readDevicePluginResources(ctx context.Context) ([]devicePluginResource, error) {
+	cm := new(corev1.ConfigMap)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.nodeNameRef.Namespace, Name: devicePluginConfigMapName}, cm); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	config := struct {
+		ResourceList []devicePluginResource `json:"resourceList"`
+	}{}
+	if err := json.Unmarshal([]byte(cm.Data[devicePluginConfigKey]), &config); err != nil {
+		r.log.WithError(err).Error("failed to parse device plugin's config")
+		return nil, nil
+	}
+	return config.ResourceList, nil
+}
+
+func (r *devicePluginHealthReconciler) readNodeConfigs(ctx context.Context) (*fec.SriovFecNodeConfig, *vrbv1.SriovVrbNodeConfig, error) {
+	fecNodeConfig := new(fec.SriovFecNodeConfig)
+	if err := r.Get(ctx, r.nodeNameRef, fecNodeConfig); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		fecNodeConfig = nil
+	}
+	vrbNodeConfig := new(vrbv1.SriovVrbNodeConfig)
+	if err := r.Get(ctx, r.nodeNameRef, vrbNodeConfig); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, nil, err
+		}
+		vrbNodeConfig = nil
+	}
+	return fecNodeConfig, vrbNodeConfig, nil
+}
+
+// setConditions updates status of the NodeConfig only if the conditions changed; health is kept when nil and
+// StaleDevicePluginResources condition is removed when stale is nil
+func (r *devicePluginHealthReconciler) setConditions(ctx context.Context, nc client.Object, conditions *[]metav1.Condition, health, stale *metav1.Condition) error {
+	changed := false
+	for _, condition := range []*metav1.Condition{health, stale} {
+		if condition == nil {
+			continue
+		}
+		current := meta.FindStatusCondition(*conditions, condition.Type)
+		if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+			continue
+		}
+		meta.SetStatusCondition(conditions, *condition)
+		r.log.WithField("condition", *condition).Infof("%s condition transition", condition.Type)
+		changed = true
+	}
+	if stale == nil && meta.FindStatusCondition(*conditions, ConditionStaleDevicePluginResources) != nil {
+		meta.RemoveStatusCondition(conditions, ConditionStaleDevicePluginResources)
+		r.log.Infof("%s condition removed", ConditionStaleDevicePluginResources)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.Status().Update(ctx, nc)
+}
+
+// staleResourcesCondition returns StaleDevicePluginResources condition listing stale resources, nil if there are none
+func staleResourcesCondition(stale []devicePluginResource) *metav1.Condition {
+	if len(stale) == 0 {
+		return nil
+	}
+	var names []string
+	for _, resource := range stale {
+		names = append(names, resource.name())
+	}
+	sort.Strings(names)
+	return &metav1.Condition{
+		Type:    ConditionStaleDevicePluginResources,
+		Status:  metav1.ConditionTrue,
+		Reason:  NoMatchingVFs,
+		Message: "Node offers resources no configured VF matches: " + strings.Join(names, ", "),
+	}
+}
+
+// devicePluginDivergences returns resources which amount advertised in node's allocatable resources differs from
+// amount of configured VFs matching selectors of the resource
+func devicePluginDivergences(resources []devicePluginResource, vfs []configuredVF, allocatable corev1.ResourceList) []string {
+	configured := map[string]int64{}
+	for _, resource := range resources {
+		if _, ok := configured[resource.name()]; !ok {
+			configured[resource.name()] = 0
+		}
+		for _, vf := range vfs {
+			if resource.matches(vf) {
+				configured[resource.name()]++
+			}
+		}
+	}
+
+	var divergences []string
+	for name, amount := range configured {
+		advertised := allocatable[corev1.ResourceName(name)]
+		if advertised.Value() != amount {
+			divergences = append(divergences, fmt.Sprintf("%s: advertised %d, configured %d", name, advertised.Value(), amount))
+		}
+	}
+	sort.Strings(divergences)
+	return divergences
+}
+
+// staleDevicePluginResources returns resources of the device plugin which no configured VF matches, while node still
+// offers them, e.g. after deconfiguration of the accelerator until kubelet notices the device plugin stopped advertising
+// them
+func staleDevicePluginResources(resources []devicePluginResource, vfs []configuredVF, status corev1.NodeStatus) []devicePluginResource {
+	var stale []devicePluginResource
+	for _, resource := range resources {
+		if slices.ContainsFunc(stale, func(s devicePluginResource) bool { return s.name() == resource.name() }) ||
+			slices.ContainsFunc(vfs, resource.matches) {
+			continue
+		}
+		name := corev1.ResourceName(resource.name())
+		capacity, allocatable := status.Capacity[name], status.Allocatable[name]
+		if !capacity.IsZero() || !allocatable.IsZero() {
+			stale = append(stale, resource)
+		}
+	}
+	return stale
+}
+
+// restartForStaleResources restarts the device plugin once for stale resources, so it stops advertising VFs which
+// don't exist anymore and kubelet removes the resources from the node. Entries of the resources are kept in the device
+// plugin's config, which is owned by the operator and shared by all nodes.
+func (r *devicePluginHealthReconciler) restartForStaleResources(ctx context.Context, stale []devicePluginResource) error {
+	if r.restartedFor == nil {
+		r.restartedFor = map[corev1.ResourceName]bool{}
+	}
+	restartNeeded := false
+	var names []corev1.ResourceName
+	for _, resource := range stale {
+		name := corev1.ResourceName(resource.name())
+		names = append(names, name)
+		restartNeeded = restartNeeded || !r.restartedFor[name]
+	}
+	for name := range r.restartedFor {
+		if !slices.Contains(names, name) {
+			delete(r.restartedFor, name)
+		}
+	}
+	if !restartNeeded || r.restartDevicePlugin == nil {
+		return nil
+	}
+	r.log.WithField("resources", names).Info("restarting device plugin advertising resources of deconfigured accelerators")
+	if err := r.restartDevicePlugin(ctx); err != nil {
+		return err
+	}
+	for _, name := range names {
+		r.restartedFor[name] = true
+	}
+	return nil
+}
+
+func isConfigurationInProgress(conditions []metav1.Condition) bool {
+	condition := meta.FindStatusCondition(conditions, ConditionConfigured)
+	return condition != nil && condition.Reason == string(ConfigurationInProgress)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+var _ = Describe("devicePluginHealthReconciler", func() {
+	const defaultDevicePluginConfig = `{"resourceList": [
+		{"resourceName": "intel_fec_acc100", "selectors": {"vendors": ["8086"], "devices": ["0d5d"], "drivers": ["vfio-pci"]}},
+		{"resourceName": "intel_fec_acc200", "selectors": {"vendors": ["8086"], "devices": ["57c1"], "drivers": ["vfio-pci"]}}
+	]}`
+
+	var (
+		devicePluginConfig string
+		nodeNameRef        = types.NamespacedName{Name: "node", Namespace: "ns"}
+		originGrace        time.Duration
+		restarts           int
+		reconciler         *devicePluginHealthReconciler
+		configCondition    metav1.Condition
+		nodeConfig         *fec.SriovFecNodeConfig
+	)
+
+	reconcileAndGetCondition := func(allocatable corev1.ResourceList) *metav1.Condition {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeNameRef.Name}, Status: corev1.NodeStatus{Allocatable: allocatable}}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: devicePluginConfigMapName, Namespace: nodeNameRef.Namespace},
+			Data:       map[string]string{devicePluginConfigKey: devicePluginConfig},
+		}
+		nodeConfig = &fec.SriovFecNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeNameRef.Name, Namespace: nodeNameRef.Namespace},
+			Status: fec.SriovFecNodeConfigStatus{
+				Conditions: []metav1.Condition{configCondition},
+				Inventory: fec.NodeInventory{SriovAccelerators: []fec.SriovAccelerator{{
+					PCIAddress: "0000:14:00.0",
+					VendorID:   "8086",
+					VFs: []fec.VF{
+						{PCIAddress: "0000:14:00.1", DeviceID: "0d5d", Driver: "vfio-pci"},
+						{PCIAddress: "0000:14:00.2", DeviceID: "0d5d", Driver: "vfio-pci"},
+					},
+				}}},
+			},
+		}
+		reconciler.Client = fake.NewClientBuilder().WithObjects(node, cm, nodeConfig).Build()
+
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nodeNameRef})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(nodeConfig), nodeConfig)).To(Succeed())
+		return meta.FindStatusCondition(nodeConfig.Status.Conditions, ConditionDevicePluginHealthy)
+	}
+
+	BeforeEach(func() {
+		Expect(fec.AddToScheme(scheme.Scheme)).To(Succeed())
+		Expect(vrbv1.AddToScheme(scheme.Scheme)).To(Succeed())
+		originGrace = devicePluginHealthGracePeriod
+		devicePluginHealthGracePeriod = 0
+		restarts = 0
+		devicePluginConfig = defaultDevicePluginConfig
+		configCondition = metav1.Condition{Type: ConditionConfigured, Status: metav1.ConditionTrue, Reason: string(ConfigurationSucceeded)}
+		reconciler = &devicePluginHealthReconciler{log: logrus.New(), nodeNameRef: nodeNameRef, restartDevicePlugin: func(context.Context) error {
+			restarts++
+			return nil
+		}}
+	})
+
+	AfterEach(func() {
+		devicePluginHealthGracePeriod = originGrace
+	})
+
+	It("reports healthy device plugin advertising all configured VFs", func() {
+		condition := reconcileAndGetCondition(corev1.ResourceList{"intel.com/intel_fec_acc100": resource.MustParse("2")})
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	})
+
+	It("counts VFs selected by PCI addresses of pools", func() {
+		devicePluginConfig = `{"resourceList": [
+			{"resourceName": "intel_fec_pool", "selectors": {"pciAddresses": ["0000:14:00.1"]}},
+			{"resourceName": "intel_fec_acc100", "selectors": {"devices": ["0d5d"], "pciAddresses": ["0000:14:00.2"]}},
+			{"resourceName": "intel_fec_acc200", "selectors": {"vendors": ["1234"], "devices": ["0d5d"]}}
+		]}`
+		condition := reconcileAndGetCondition(corev1.ResourceList{
+			"intel.com/intel_fec_pool":   resource.MustParse("1"),
+			"intel.com/intel_fec_acc100": resource.MustParse("1"),
+		})
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(restarts).To(BeZero())
+	})
+
+	It("reports divergence without restarting device plugin unless enabled", func() {
+		condition := reconcileAndGetCondition(corev1.ResourceList{"intel.com/intel_fec_acc100": resource.MustParse("1")})
+		Expect(condition.Reason).To(Equal(DevicePluginDiverged))
+		Expect(restarts).To(BeZero())
+	})
+
+	It("reports divergence and restarts device plugin when enabled", func() {
+		reconciler.restartOnDivergence = true
+		condition := reconcileAndGetCondition(corev1.ResourceList{})
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(DevicePluginDiverged))
+		Expect(condition.Message).To(Equal("intel.com/intel_fec_acc100: advertised 0, configured 2"))
+		Expect(restarts).To(Equal(1))
+	})
+
+	staleResources := corev1.ResourceList{
+		"intel.com/intel_fec_acc100": resource.MustParse("2"),
+		"intel.com/intel_fec_acc200": resource.MustParse("1"),
+	}
+
+	It("reports resources of deconfigured accelerators and restarts device plugin once, keeping its config", func() {
+		condition := reconcileAndGetCondition(staleResources)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		stale := meta.FindStatusCondition(nodeConfig.Status.Conditions, ConditionStaleDevicePluginResources)
+		Expect(stale).ToNot(BeNil())
+		Expect(stale.Reason).To(Equal(NoMatchingVFs))
+		Expect(stale.Message).To(Equal("Node offers resources no configured VF matches: intel.com/intel_fec_acc200"))
+		Expect(restarts).To(Equal(1))
+
+		cm := new(corev1.ConfigMap)
+		Expect(reconciler.Get(context.TODO(), client.ObjectKey{Namespace: nodeNameRef.Namespace, Name: devicePluginConfigMapName}, cm)).To(Succeed())
+		Expect(cm.Data[devicePluginConfigKey]).To(Equal(devicePluginConfig))
+
+		// node offers the resource until kubelet notices the device plugin stopped advertising it
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nodeNameRef})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restarts).To(Equal(1))
+	})
+
+	It("removes the stale resources condition once node doesn't offer them", func() {
+		reconcileAndGetCondition(staleResources)
+		Expect(meta.FindStatusCondition(nodeConfig.Status.Conditions, ConditionStaleDevicePluginResources)).ToNot(BeNil())
+
+		node := new(corev1.Node)
+		Expect(reconciler.Get(context.TODO(), client.ObjectKey{Name: nodeNameRef.Name}, node)).To(Succeed())
+		delete(node.Status.Allocatable, "intel.com/intel_fec_acc200")
+		Expect(reconciler.Status().Update(context.TODO(), node)).To(Succeed())
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nodeNameRef})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(nodeConfig), nodeConfig)).To(Succeed())
+		Expect(meta.FindStatusCondition(nodeConfig.Status.Conditions, ConditionStaleDevicePluginResources)).To(BeNil())
+	})
+
+	It("doesn't report divergence within grace period", func() {
+		devicePluginHealthGracePeriod = time.Hour
+		Expect(reconcileAndGetCondition(corev1.ResourceList{})).To(BeNil())
+	})
+
+	It("doesn't check device plugin while configuration is in progress", func() {
+		configCondition.Reason = string(ConfigurationInProgress)
+		Expect(reconcileAndGetCondition(corev1.ResourceList{})).To(BeNil())
+	})
+})
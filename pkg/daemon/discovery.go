@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+// DiscoveryPath is a path of the daemon's metrics server returning inventory of accelerators of the node
+const DiscoveryPath = "/discovery"
+
+// Discovery is a read-only view of accelerators of the node and their VFs, read from sysfs on each request
+type Discovery struct {
+	NodeName string                   `json:"nodeName"`
+	Fec      []fec.SriovAccelerator   `json:"fec"`
+	Vrb      []vrbv1.SriovAccelerator `json:"vrb"`
+}
+
+// discoveryHandler serves Discovery of the node; inventory functions are replaced in tests
+type discoveryHandler struct {
+	nodeName     string
+	log          *logrus.Logger
+	fecInventory func(log *logrus.Logger) (*fec.NodeInventory, error)
+	vrbInventory func(log *logrus.Logger) (*vrbv1.NodeInventory, error)
+}
+
+func newDiscoveryHandler(nodeName string, log *logrus.Logger) *discoveryHandler {
+	return &discoveryHandler{
+		nodeName:     nodeName,
+		log:          log,
+		fecInventory: GetSriovInventory,
+		vrbInventory: VrbGetSriovInventory,
+	}
+}
+
+func (h *discoveryHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	discovery := Discovery{NodeName: h.nodeName, Fec: []fec.SriovAccelerator{}, Vrb: []vrbv1.SriovAccelerator{}}
+	fecInventory, err := h.fecInventory(h.log)
+	if err != nil {
+		h.log.WithError(err).Error("failed to discover FEC accelerators")
+		http.Error(w, "failed to discover FEC accelerators: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	discovery.Fec = append(discovery.Fec, fecInventory.SriovAccelerators...)
+
+	vrbInventory, err := h.vrbInventory(h.log)
+	if err != nil {
+		h.log.WithError(err).Error("failed to discover VRB accelerators")
+		http.Error(w, "failed to discover VRB accelerators: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	discovery.Vrb = append(discovery.Vrb, vrbInventory.SriovAccelerators...)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(discovery); err != nil {
+		h.log.WithError(err).Error("failed to write discovery response")
+	}
+}
+
+// SetupDiscovery registers the discovery endpoint on the daemon's metrics server
+func SetupDiscovery(mgr manager.Manager, nodeName string, log *logrus.Logger) error {
+	if err := mgr.AddMetricsExtraHandler(DiscoveryPath, newDiscoveryHandler(nodeName, log)); err != nil {
+		log.WithError(err).Error("cannot register handler for discovery")
+		return err
+	}
+	log.WithField("path", DiscoveryPath).Info("registered discovery endpoint")
+	return nil
+}
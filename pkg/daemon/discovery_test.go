@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var _ = Describe("Discovery", func() {
+	var handler *discoveryHandler
+
+	BeforeEach(func() {
+		handler = newDiscoveryHandler("node1", utils.NewLogger())
+		handler.fecInventory = func(*logrus.Logger) (*fec.NodeInventory, error) {
+			return &fec.NodeInventory{SriovAccelerators: []fec.SriovAccelerator{{
+				PCIAddress: "0000:14:00.0", PFDriver: utils.VFIO_PCI, MaxVFs: 16,
+				VFs: []fec.VF{{PCIAddress: "0000:14:00.1", Driver: utils.VFIO_PCI}},
+			}}}, nil
+		}
+		handler.vrbInventory = func(*logrus.Logger) (*vrbv1.NodeInventory, error) {
+			return &vrbv1.NodeInventory{}, nil
+		}
+	})
+
+	It("returns accelerators of the node and their VFs", func() {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DiscoveryPath, nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		discovery := Discovery{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &discovery)).To(Succeed())
+		Expect(discovery.NodeName).To(Equal("node1"))
+		Expect(discovery.Fec).To(HaveLen(1))
+		Expect(discovery.Fec[0].VFs[0].PCIAddress).To(Equal("0000:14:00.1"))
+		Expect(discovery.Vrb).To(BeEmpty())
+		Expect(recorder.Body.String()).To(ContainSubstring(`"vrb":[]`))
+	})
+
+	It("rejects requests other than GET", func() {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, DiscoveryPath, nil))
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("reports failure of reading the inventory", func() {
+		handler.vrbInventory = func(*logrus.Logger) (*vrbv1.NodeInventory, error) {
+			return nil, errors.New("pci.ListDevices() returned 0 devices")
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DiscoveryPath, nil))
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body.String()).To(ContainSubstring("0 devices"))
+	})
+})
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/drainhelper"
+)
+
+// drainCoalescingWindow is a time the coordinator waits for other reconcilers before it drains the node
+var drainCoalescingWindow = 5 * time.Second
+
+type drainRequest struct {
+	ctx        context.Context
+	configurer func(ctx context.Context) bool
+	drain      bool
+	done       chan error
+}
+
+// drainCoordinator serializes configuration flows of all reconcilers running on the node. Requests submitted
+// while the node is about to be drained, or while it is drained, are executed together within a single drain
+// in order of submission, so e.g. FEC and VRB reconfiguration of the same node cause one drain cycle instead of two.
+// The batch is executed with context of its first request - reconcilers of the node share the manager's context;
+// progress of the drain is reported to reporters of all requests of the batch.
+type drainCoordinator struct {
+	drainer DrainAndExecute
+
+	mu      sync.Mutex
+	pending []drainRequest
+	running bool
+}
+
+// NewDrainCoordinator returns DrainAndExecute shared by all reconcilers of the node
+func NewDrainCoordinator(drainer DrainAndExecute) DrainAndExecute {
+	c := &drainCoordinator{drainer: drainer}
+	return c.DrainAndExecute
+}
+
+func (c *drainCoordinator) DrainAndExecute(ctx context.Context, configurer func(ctx context.Context) bool, drain bool) error {
+	request := drainRequest{ctx: ctx, configurer: configurer, drain: drain, done: make(chan error, 1)}
+
+	c.mu.Lock()
+	c.pending = append(c.pending, request)
+	start := !c.running
+	c.running = true
+	c.mu.Unlock()
+
+	if start {
+		go c.run()
+	}
+	select {
+	case err := <-request.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *drainCoordinator) run() {
+	time.Sleep(drainCoalescingWindow)
+	for {
+		c.mu.Lock()
+		batch := c.pending
+		c.pending = nil
+		if len(batch) == 0 {
+			c.running = false
+			c.mu.Unlock()
+			return
+		}
+		c.mu.Unlock()
+
+		drain := false
+		for _, request := range batch {
+			drain = drain || request.drain
+		}
+
+		ctx := drainhelper.WithProgressReporter(batch[0].ctx, func(progress drainhelper.Progress) {
+			for _, request := range batch {
+				if request.ctx.Err() == nil {
+					drainhelper.ReportProgress(request.ctx, progress)
+				}
+			}
+		})
+		err := c.drainer(ctx, func(ctx context.Context) bool {
+			uncordon := true
+			for _, request := range batch {
+				if request.ctx.Err() != nil {
+					continue
+				}
+				uncordon = request.configurer(ctx) && uncordon
+			}
+			return uncordon
+		}, drain)
+
+		for _, request := range batch {
+			request.done <- err
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/drainhelper"
+)
+
+var _ = Describe("drainCoordinator", func() {
+	var (
+		drains       int
+		drained      []bool
+		drainer      DrainAndExecute
+		originWindow time.Duration
+	)
+
+	BeforeEach(func() {
+		originWindow = drainCoalescingWindow
+		drainCoalescingWindow = 100 * time.Millisecond
+		drains, drained = 0, nil
+		drainer = NewDrainCoordinator(func(ctx context.Context, configurer func(ctx context.Context) bool, drain bool) error {
+			drains++
+			drained = append(drained, drain)
+			configurer(ctx)
+			return nil
+		})
+	})
+
+	AfterEach(func() {
+		drainCoalescingWindow = originWindow
+	})
+
+	It("executes concurrent requests within a single drain", func() {
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			executed []string
+		)
+		for _, name := range []string{"fec", "vrb"} {
+			name := name
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				Expect(drainer(context.TODO(), func(ctx context.Context) bool {
+					mu.Lock()
+					defer mu.Unlock()
+					executed = append(executed, name)
+					return true
+				}, name == "fec")).To(Succeed())
+			}()
+		}
+		wg.Wait()
+
+		Expect(drains).To(Equal(1))
+		Expect(drained).To(Equal([]bool{true}))
+		Expect(executed).To(ConsistOf("fec", "vrb"))
+	})
+
+	It("executes sequential requests within separate drains", func() {
+		noop := func(ctx context.Context) bool { return true }
+		Expect(drainer(context.TODO(), noop, false)).To(Succeed())
+		Expect(drainer(context.TODO(), noop, true)).To(Succeed())
+
+		Expect(drains).To(Equal(2))
+		Expect(drained).To(Equal([]bool{false, true}))
+	})
+
+	It("doesn't wait for nor execute request which context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		executed := make(chan struct{})
+		Expect(drainer(ctx, func(context.Context) bool {
+			close(executed)
+			return true
+		}, true)).To(MatchError(context.Canceled))
+		Consistently(executed, 3*drainCoalescingWindow).ShouldNot(BeClosed())
+	})
+	It("reports progress of the drain to all requests of the batch", func() {
+		drainer = NewDrainCoordinator(func(ctx context.Context, configurer func(ctx context.Context) bool, drain bool) error {
+			drainhelper.ReportProgress(ctx, drainhelper.Progress{Phase: drainhelper.DrainPhaseDrained, PodsEvicted: 2})
+			configurer(ctx)
+			return nil
+		})
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			reported = map[string]drainhelper.Progress{}
+		)
+		for _, name := range []string{"fec", "vrb"} {
+			name := name
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				ctx := drainhelper.WithProgressReporter(context.TODO(), func(progress drainhelper.Progress) {
+					mu.Lock()
+					defer mu.Unlock()
+					reported[name] = progress
+				})
+				Expect(drainer(ctx, func(context.Context) bool { return true }, true)).To(Succeed())
+			}()
+		}
+		wg.Wait()
+
+		Expect(reported).To(HaveLen(2))
+		Expect(reported["fec"].PodsEvicted).To(Equal(2))
+		Expect(reported["vrb"].Phase).To(Equal(drainhelper.DrainPhaseDrained))
+	})
+})
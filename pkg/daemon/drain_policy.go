@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+// hasVFs returns true if any accelerator of the inventory has VFs, so its configuration may disrupt workloads using them
+func hasVFs(inventory *fec.NodeInventory) bool {
+	for _, acc := range inventory.SriovAccelerators {
+		if len(acc.VFs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// vrbHasVFs returns true if any accelerator of the inventory has VFs, so its configuration may disrupt workloads using them
+func vrbHasVFs(inventory *vrbv1.NodeInventory) bool {
+	for _, acc := range inventory.SriovAccelerators {
+		if len(acc.VFs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DrainPolicy", func() {
+	var (
+		drained    []bool
+		reconciler *FecNodeConfigReconciler
+	)
+
+	BeforeEach(func() {
+		drained = nil
+		reconciler = &FecNodeConfigReconciler{
+			log: logrus.New(),
+			drainerAndExecute: func(ctx context.Context, configurer func(ctx context.Context) bool, drain bool) error {
+				drained = append(drained, drain)
+				configurer(ctx)
+				return nil
+			},
+			sriovfecconfigurer:  testConfigurerProto{configureNodeFunction: func(sriovv2.SriovFecNodeConfigSpec) error { return nil }},
+			restartDevicePlugin: func(context.Context) error { return nil },
+		}
+	})
+
+	configure := func(policy sriovv2.DrainPolicy, inventory sriovv2.NodeInventory) {
+		nc := &sriovv2.SriovFecNodeConfig{Spec: sriovv2.SriovFecNodeConfigSpec{DrainPolicy: policy}}
+		_, err := reconciler.configureNode(context.TODO(), nc, &inventory)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	withoutVFs := sriovv2.NodeInventory{SriovAccelerators: []sriovv2.SriovAccelerator{{PCIAddress: "0000:14:00.0"}}}
+	withVFs := sriovv2.NodeInventory{SriovAccelerators: []sriovv2.SriovAccelerator{
+		{PCIAddress: "0000:14:00.0", VFs: []sriovv2.VF{{PCIAddress: "0000:15:00.0"}}},
+	}}
+
+	It("drains the node before each configuration by default", func() {
+		configure("", withoutVFs)
+		configure("", withVFs)
+		Expect(drained).To(Equal([]bool{true, true}))
+	})
+
+	It("drains the node only when its accelerators have VFs under IfDisruptive policy", func() {
+		configure(sriovv2.DrainIfDisruptive, withoutVFs)
+		configure(sriovv2.DrainIfDisruptive, withVFs)
+		Expect(drained).To(Equal([]bool{false, true}))
+	})
+})
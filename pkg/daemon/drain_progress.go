@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/drainhelper"
+)
+
+// withDrainProgress returns a context reporting progress of the drain into status.drain of the NodeConfig. The status
+// is patched, so the NodeConfig is updated later with the resource version returned by the patch.
+func (r *FecNodeConfigReconciler) withDrainProgress(ctx context.Context, nc *fec.SriovFecNodeConfig) context.Context {
+	return drainhelper.WithProgressReporter(ctx, func(progress drainhelper.Progress) {
+		patched := nc.DeepCopy()
+		patched.Status.Drain = &fec.DrainStatus{
+			Phase:       progress.Phase,
+			StartedAt:   metav1.NewTime(progress.StartedAt),
+			PodsToEvict: progress.PodsToEvict,
+			PodsEvicted: progress.PodsEvicted,
+			PendingPods: progress.PendingPods,
+			Message:     progress.Message,
+		}
+		if err := r.Status().Patch(ctx, patched, client.MergeFrom(nc)); err != nil {
+			r.log.WithError(err).Warn("failed to report progress of the drain")
+			return
+		}
+		nc.Status.Drain = patched.Status.Drain
+		nc.ResourceVersion = patched.ResourceVersion
+	})
+}
+
+// withDrainProgress returns a context reporting progress of the drain into status.drain of the NodeConfig. The status
+// is patched, so the NodeConfig is updated later with the resource version returned by the patch.
+func (r *VrbNodeConfigReconciler) withDrainProgress(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig) context.Context {
+	return drainhelper.WithProgressReporter(ctx, func(progress drainhelper.Progress) {
+		patched := nc.DeepCopy()
+		patched.Status.Drain = &vrbv1.DrainStatus{
+			Phase:       progress.Phase,
+			StartedAt:   metav1.NewTime(progress.StartedAt),
+			PodsToEvict: progress.PodsToEvict,
+			PodsEvicted: progress.PodsEvicted,
+			PendingPods: progress.PendingPods,
+			Message:     progress.Message,
+		}
+		if err := r.Status().Patch(ctx, patched, client.MergeFrom(nc)); err != nil {
+			r.log.WithError(err).Warn("failed to report progress of the drain")
+			return
+		}
+		nc.Status.Drain = patched.Status.Drain
+		nc.ResourceVersion = patched.ResourceVersion
+	})
+}
+
+// ResumeInterruptedDrain returns a runnable which completes the drain interrupted by restart of the daemon, so the node
+// left cordoned is uncordoned even if none of reconcilers has configuration to apply; configurations submitted by
+// reconcilers meanwhile are applied within the same drain.
+func ResumeInterruptedDrain(drainer DrainAndExecute, isDrainInterrupted func(context.Context) (bool, error), log *logrus.Logger) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		interrupted, err := isDrainInterrupted(ctx)
+		if err != nil {
+			log.WithError(err).Error("failed to check if drain of the node was interrupted")
+			return nil
+		}
+		if !interrupted {
+			return nil
+		}
+		log.Info("resuming drain of the node interrupted by restart of the daemon")
+		if err := drainer(ctx, func(context.Context) bool { return true }, true); err != nil {
+			log.WithError(err).Error("failed to resume drain of the node")
+		}
+		return nil
+	}
+}
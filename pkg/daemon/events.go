@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// eventRecorderName is a component reported in Events recorded by the daemon
+const eventRecorderName = "sriov-fec-daemon"
+
+type configurationEvent struct {
+	eventType, reason string
+}
+
+// configurationEvents are Events recorded on NodeConfig keyed by reason of its Configured condition; other reasons,
+// e.g. NotRequested, are not milestones of the configuration
+var configurationEvents = map[string]configurationEvent{
+	string(ConfigurationInProgress): {corev1.EventTypeNormal, "ConfigurationStarted"},
+	string(ConfigurationSucceeded):  {corev1.EventTypeNormal, "ConfigurationSucceeded"},
+	string(ConfigurationFailed):     {corev1.EventTypeWarning, "ConfigurationFailed"},
+	string(ConfigurationRolledBack): {corev1.EventTypeWarning, "ConfigurationRolledBack"},
+	string(ConfigurationTimeout):    {corev1.EventTypeWarning, "ConfigurationTimeout"},
+}
+
+// recordConfigurationEvent records Event on NodeConfig when its Configured condition changes, so milestones and failures
+// of the configuration, e.g. failed pf_bb_config, are listed by kubectl describe instead of logs of the daemon
+func recordConfigurationEvent(recorder record.EventRecorder, nc runtime.Object, previous, current metav1.Condition) {
+	event, ok := configurationEvents[current.Reason]
+	if recorder == nil || !ok {
+		return
+	}
+	if previous.Reason == current.Reason && previous.Message == current.Message && previous.ObservedGeneration == current.ObservedGeneration {
+		return
+	}
+	recorder.Event(nc, event.eventType, event.reason, current.Message)
+}
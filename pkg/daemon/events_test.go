@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("Configuration events", func() {
+	condition := func(reason ConfigurationConditionReason, message string, generation int64) metav1.Condition {
+		return metav1.Condition{Type: ConditionConfigured, Reason: string(reason), Message: message, ObservedGeneration: generation}
+	}
+
+	It("records milestones and failures of the configuration once", func() {
+		recorder := record.NewFakeRecorder(10)
+		nc := &fec.SriovFecNodeConfig{}
+
+		recordConfigurationEvent(recorder, nc, condition(ConfigurationSucceeded, "Configured successfully", 1), condition(ConfigurationInProgress, "Configuration started", 1))
+		recordConfigurationEvent(recorder, nc, condition(ConfigurationInProgress, "Configuration started", 1), condition(ConfigurationFailed, "pf_bb_config failed on 0000:3b:00.0", 1))
+		recordConfigurationEvent(recorder, nc, condition(ConfigurationFailed, "pf_bb_config failed on 0000:3b:00.0", 1), condition(ConfigurationFailed, "pf_bb_config failed on 0000:3b:00.0", 1))
+		recordConfigurationEvent(recorder, nc, condition(ConfigurationNotRequested, "", 0), condition(ConfigurationNotRequested, "Inventory up to date", 0))
+		recordConfigurationEvent(nil, nc, condition(ConfigurationInProgress, "", 1), condition(ConfigurationSucceeded, "", 2))
+
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(Equal("Normal ConfigurationStarted Configuration started"))
+		Expect(<-recorder.Events).To(Equal("Warning ConfigurationFailed pf_bb_config failed on 0000:3b:00.0"))
+	})
+})
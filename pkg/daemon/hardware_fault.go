@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// ConditionHardwareFault is set on NodeConfig which configuration is frozen, because its accelerators reported
+// uncorrectable PCIe errors; its message lists the accelerators
+const ConditionHardwareFault = "HardwareFault"
+
+// aerUncorrectableCounters are sysfs files of AER statistics of the device and their lines with totals of
+// uncorrectable errors
+var aerUncorrectableCounters = map[string]string{
+	"aer_dev_fatal":    "TOTAL_ERR_FATAL",
+	"aer_dev_nonfatal": "TOTAL_ERR_NONFATAL",
+}
+
+// uncorrectableErrors returns number of uncorrectable AER errors reported by the accelerator since the node booted,
+// zero if the kernel doesn't report AER statistics of the device
+func uncorrectableErrors(pciAddress string) int64 {
+	var total int64
+	for file, counter := range aerUncorrectableCounters {
+		f, err := os.Open(filepath.Join(sysBusPciDevices, pciAddress, file))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && fields[0] == counter {
+				if value, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+					total += value
+				}
+			}
+		}
+		_ = f.Close()
+	}
+	return total
+}
+
+// hardwareFaultFreeze returns accelerators which reported uncorrectable errors since the freeze was lifted last time,
+// configuration of the NodeConfig is frozen until ClearHardwareFaultAnnotation records their errors as the baseline.
+// HardwareFault condition is set while the configuration is frozen. FEC and VRB NodeConfigs share the annotations.
+func hardwareFaultFreeze(ctx context.Context, c client.Client, nc client.Object, conditions *[]metav1.Condition, pciAddresses []string) ([]string, error) {
+	counters := map[string]int64{}
+	for _, pciAddress := range pciAddresses {
+		counters[pciAddress] = uncorrectableErrors(pciAddress)
+	}
+
+	annotations := maps.Clone(nc.GetAnnotations())
+	if annotations[fec.ClearHardwareFaultAnnotation] == "true" {
+		baseline, err := json.Marshal(counters)
+		if err != nil {
+			return nil, err
+		}
+		annotations[fec.HardwareFaultBaselineAnnotation] = string(baseline)
+		delete(annotations, fec.ClearHardwareFaultAnnotation)
+		if err := patchAnnotations(ctx, c, nc, annotations); err != nil {
+			return nil, err
+		}
+	}
+
+	// invalid baseline freezes accelerators with any error, until the freeze is lifted again
+	baseline := map[string]int64{}
+	_ = json.Unmarshal([]byte(annotations[fec.HardwareFaultBaselineAnnotation]), &baseline)
+
+	var faulty, messages []string
+	sort.Strings(pciAddresses)
+	for _, pciAddress := range pciAddresses {
+		recorded := baseline[pciAddress]
+		// counters are reset when the node reboots
+		if counters[pciAddress] < recorded {
+			recorded = 0
+		}
+		if counters[pciAddress] > recorded {
+			faulty = append(faulty, pciAddress)
+			messages = append(messages, fmt.Sprintf("%s (%d)", pciAddress, counters[pciAddress]-recorded))
+		}
+	}
+
+	if len(faulty) == 0 {
+		if meta.FindStatusCondition(*conditions, ConditionHardwareFault) != nil {
+			meta.RemoveStatusCondition(conditions, ConditionHardwareFault)
+			return nil, c.Status().Update(ctx, nc)
+		}
+		return nil, nil
+	}
+
+	message := fmt.Sprintf("uncorrectable PCIe errors reported by %s, configuration is frozen until %s annotation is set to true",
+		strings.Join(messages, ", "), fec.ClearHardwareFaultAnnotation)
+	if condition := meta.FindStatusCondition(*conditions, ConditionHardwareFault); condition == nil || condition.Message != message {
+		meta.SetStatusCondition(conditions, metav1.Condition{Type: ConditionHardwareFault, Status: metav1.ConditionTrue,
+			Reason: "UncorrectableErrors", Message: message, ObservedGeneration: nc.GetGeneration()})
+		if err := c.Status().Update(ctx, nc); err != nil {
+			return nil, err
+		}
+	}
+	return faulty, nil
+}
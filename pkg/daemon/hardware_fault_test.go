@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HardwareFault", func() {
+	const (
+		faulty  = "0000:3b:00.0"
+		healthy = "0000:3c:00.0"
+	)
+	var (
+		originalSysBusPciDevices string
+		c                        client.Client
+		nc                       *sriovv2.SriovFecNodeConfig
+	)
+
+	reportErrors := func(pciAddress string, fatal, nonFatal int) {
+		Expect(os.MkdirAll(filepath.Join(sysBusPciDevices, pciAddress), 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pciAddress, "aer_dev_fatal"),
+			[]byte(fmt.Sprintf("Undefined 0\nDLP %d\nTOTAL_ERR_FATAL %d\n", fatal, fatal)), 0600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pciAddress, "aer_dev_nonfatal"),
+			[]byte(fmt.Sprintf("Undefined 0\nTOTAL_ERR_NONFATAL %d\n", nonFatal)), 0600)).To(Succeed())
+	}
+
+	freeze := func() []string {
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+		frozen, err := hardwareFaultFreeze(context.TODO(), c, nc, &nc.Status.Conditions, []string{healthy, faulty})
+		Expect(err).ToNot(HaveOccurred())
+		return frozen
+	}
+
+	BeforeEach(func() {
+		originalSysBusPciDevices = sysBusPciDevices
+		sysBusPciDevices = filepath.Join(testTmpFolder, "aer")
+		reportErrors(healthy, 0, 0)
+
+		scheme := runtime.NewScheme()
+		Expect(sriovv2.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		nc = &sriovv2.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}}
+		Expect(c.Create(context.TODO(), nc)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sysBusPciDevices)).To(Succeed())
+		sysBusPciDevices = originalSysBusPciDevices
+	})
+
+	It("doesn't freeze accelerators without AER statistics or errors", func() {
+		Expect(freeze()).To(BeEmpty())
+		Expect(nc.Status.Conditions).To(BeEmpty())
+	})
+
+	It("freezes configuration until the fault is cleared", func() {
+		reportErrors(faulty, 1, 2)
+		Expect(freeze()).To(Equal([]string{faulty}))
+		condition := meta.FindStatusCondition(nc.Status.Conditions, ConditionHardwareFault)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Message).To(ContainSubstring(faulty + " (3)"))
+
+		nc.Annotations = map[string]string{sriovv2.ClearHardwareFaultAnnotation: "true"}
+		Expect(c.Update(context.TODO(), nc)).To(Succeed())
+		Expect(freeze()).To(BeEmpty())
+		Expect(nc.Annotations).ToNot(HaveKey(sriovv2.ClearHardwareFaultAnnotation))
+		Expect(nc.Annotations).To(HaveKeyWithValue(sriovv2.HardwareFaultBaselineAnnotation, `{"0000:3b:00.0":3,"0000:3c:00.0":0}`))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, ConditionHardwareFault)).To(BeNil())
+
+		By("new errors freeze the configuration again")
+		reportErrors(faulty, 1, 3)
+		Expect(freeze()).To(Equal([]string{faulty}))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, ConditionHardwareFault).Message).To(ContainSubstring(faulty + " (1)"))
+
+		By("errors are counted from zero after reboot")
+		reportErrors(faulty, 0, 1)
+		Expect(freeze()).To(Equal([]string{faulty}))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, ConditionHardwareFault).Message).To(ContainSubstring(faulty + " (1)"))
+	})
+})
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var (
+	hostCommanderEnv = utils.SRIOV_PREFIX + "HOST_COMMANDER"
+	hostRootEnv      = utils.SRIOV_PREFIX + "HOST_ROOT"
+
+	// hostCommander runs commands operating on the host, like loading kernel modules or configuring PCI devices.
+	// pf_bb_config is shipped with the daemon, so it's always run by runExecCmd in the daemon's container.
+	hostCommander HostCommander = containerCommander{}
+)
+
+const (
+	containerCommanderName = "container"
+	chrootCommanderName    = "chroot"
+	nsenterCommanderName   = "nsenter"
+
+	defaultHostRoot = "/host"
+)
+
+// HostCommander runs commands on the host of the daemon
+type HostCommander interface {
+	Run(ctx context.Context, args []string, log *logrus.Logger) (string, error)
+}
+
+// HostCommanderFunc is a function used as HostCommander
+type HostCommanderFunc func(ctx context.Context, args []string, log *logrus.Logger) (string, error)
+
+func (f HostCommanderFunc) Run(ctx context.Context, args []string, log *logrus.Logger) (string, error) {
+	return f(ctx, args, log)
+}
+
+// containerCommander runs commands in the daemon's container, which has host's /sys, /dev/vfio and /lib/modules mounted
+type containerCommander struct{}
+
+func (containerCommander) Run(ctx context.Context, args []string, log *logrus.Logger) (string, error) {
+	return execCmd(ctx, args, log)
+}
+
+// chrootCommander runs commands chrooted into host's root filesystem mounted into the daemon's container
+type chrootCommander struct {
+	root string
+}
+
+func (c chrootCommander) Run(ctx context.Context, args []string, log *logrus.Logger) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("cmd is empty")
+	}
+	return execCmd(ctx, c.wrap(args), log)
+}
+
+// wrap prefixes the command with chroot into host's root
+func (c chrootCommander) wrap(args []string) []string {
+	return append([]string{"chroot", c.root}, args...)
+}
+
+// nsenterCommander runs commands in namespaces of host's init process, so it requires the daemon to share host's PID
+// namespace. It's meant for hosts which root filesystem cannot be chrooted into.
+type nsenterCommander struct{}
+
+func (c nsenterCommander) Run(ctx context.Context, args []string, log *logrus.Logger) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("cmd is empty")
+	}
+	return execCmd(ctx, c.wrap(args), log)
+}
+
+// wrap prefixes the command with entering namespaces of host's init process
+func (nsenterCommander) wrap(args []string) []string {
+	return append([]string{"nsenter", "--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid", "--"}, args...)
+}
+
+// newHostCommander returns HostCommander of given name, host's root is used by chroot only
+func newHostCommander(name, hostRoot string) (HostCommander, error) {
+	switch name {
+	case "", containerCommanderName:
+		return containerCommander{}, nil
+	case chrootCommanderName:
+		if hostRoot == "" {
+			hostRoot = defaultHostRoot
+		}
+		return chrootCommander{root: hostRoot}, nil
+	case nsenterCommanderName:
+		return nsenterCommander{}, nil
+	default:
+		return nil, fmt.Errorf("unknown host commander %q, supported: %s, %s, %s", name, containerCommanderName, chrootCommanderName, nsenterCommanderName)
+	}
+}
+
+// ApplyHostCommander sets the way commands are run on the host, if configured in environment
+func ApplyHostCommander(log *logrus.Logger) error {
+	commander, err := newHostCommander(os.Getenv(hostCommanderEnv), os.Getenv(hostRootEnv))
+	if err != nil {
+		log.WithError(err).WithField(hostCommanderEnv, os.Getenv(hostCommanderEnv)).Error("invalid host commander")
+		return err
+	}
+	hostCommander = commander
+	log.WithField("hostCommander", fmt.Sprintf("%T", commander)).Info("host commander")
+	return nil
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("HostCommander", func() {
+	AfterEach(func() {
+		Expect(os.Unsetenv(hostCommanderEnv)).To(Succeed())
+		Expect(os.Unsetenv(hostRootEnv)).To(Succeed())
+		hostCommander = containerCommander{}
+	})
+
+	It("runs commands in the daemon's container by default", func() {
+		Expect(ApplyHostCommander(logrus.New())).To(Succeed())
+		Expect(hostCommander).To(Equal(containerCommander{}))
+
+		out, err := hostCommander.Run(context.TODO(), []string{"echo", "host"}, logrus.New())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out).To(Equal("host\n"))
+	})
+
+	It("chroots into host's root", func() {
+		Expect(os.Setenv(hostCommanderEnv, chrootCommanderName)).To(Succeed())
+		Expect(ApplyHostCommander(logrus.New())).To(Succeed())
+		Expect(hostCommander).To(Equal(chrootCommander{root: defaultHostRoot}))
+		Expect(chrootCommander{root: "/rootfs"}.wrap([]string{"modprobe", "vfio-pci"})).
+			To(Equal([]string{"chroot", "/rootfs", "modprobe", "vfio-pci"}))
+	})
+
+	It("enters namespaces of host's init process", func() {
+		Expect(os.Setenv(hostCommanderEnv, nsenterCommanderName)).To(Succeed())
+		Expect(ApplyHostCommander(logrus.New())).To(Succeed())
+		Expect(hostCommander).To(Equal(nsenterCommander{}))
+		Expect(nsenterCommander{}.wrap([]string{"setpci", "-v"})).
+			To(Equal([]string{"nsenter", "--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid", "--", "setpci", "-v"}))
+	})
+
+	It("rejects unknown commander and empty commands", func() {
+		Expect(os.Setenv(hostCommanderEnv, "ssh")).To(Succeed())
+		Expect(ApplyHostCommander(logrus.New())).ToNot(Succeed())
+		Expect(hostCommander).To(Equal(containerCommander{}))
+
+		_, err := chrootCommander{root: defaultHostRoot}.Run(context.TODO(), nil, logrus.New())
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("can be replaced by a fake", func() {
+		var executed [][]string
+		hostCommander = HostCommanderFunc(func(_ context.Context, args []string, _ *logrus.Logger) (string, error) {
+			executed = append(executed, args)
+			return "", nil
+		})
+
+		Expect(isDriverAvailable(context.TODO(), "not-existing-driver", logrus.New())).To(BeTrue())
+		Expect(executed).To(Equal([][]string{{"modprobe", "--dry-run", "not-existing-driver"}}))
+	})
+})
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// hotplugCheckPeriod is a period of checking whether accelerators were hot-plugged or removed
+var hotplugCheckPeriod = 10 * time.Second
+
+// acceleratorsWatcher enqueues NodeConfig of the node as soon as set of accelerators present on the node changes
+// (card hot-plugged, removed or appearing after PCIe rescan), so it's handled without waiting for resyncPeriod
+type acceleratorsWatcher struct {
+	// listAccelerators returns PCI addresses of accelerators present on the node
+	listAccelerators func() ([]string, error)
+	nodeConfig       client.Object
+	events           chan event.GenericEvent
+	log              *logrus.Logger
+}
+
+func newAcceleratorsWatcher(nodeConfig client.Object, listAccelerators func() ([]string, error), log *logrus.Logger) *acceleratorsWatcher {
+	return &acceleratorsWatcher{
+		listAccelerators: listAccelerators,
+		nodeConfig:       nodeConfig,
+		events:           make(chan event.GenericEvent),
+		log:              log,
+	}
+}
+
+// Start implements manager.Runnable
+func (w *acceleratorsWatcher) Start(ctx context.Context) error {
+	known, err := w.listAccelerators()
+	if err != nil {
+		w.log.WithError(err).Error("failed to list accelerators")
+	}
+
+	ticker := time.NewTicker(hotplugCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current, err := w.listAccelerators()
+			if err != nil {
+				w.log.WithError(err).Error("failed to list accelerators")
+				continue
+			}
+			added, removed := acceleratorsChanges(known, current)
+			if len(added)+len(removed) == 0 {
+				continue
+			}
+			w.log.WithField("added", added).WithField("removed", removed).Info("accelerators changed, enqueuing NodeConfig")
+			known = current
+			select {
+			case w.events <- event.GenericEvent{Object: w.nodeConfig}:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// acceleratorsChanges returns PCI addresses of accelerators added to and removed from the known ones
+func acceleratorsChanges(known, current []string) (added, removed []string) {
+	knownSet := map[string]bool{}
+	for _, pciAddress := range known {
+		knownSet[pciAddress] = true
+	}
+	currentSet := map[string]bool{}
+	for _, pciAddress := range current {
+		currentSet[pciAddress] = true
+		if !knownSet[pciAddress] {
+			added = append(added, pciAddress)
+		}
+	}
+	for _, pciAddress := range known {
+		if !currentSet[pciAddress] {
+			removed = append(removed, pciAddress)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("acceleratorsWatcher", func() {
+	var originPeriod time.Duration
+
+	BeforeEach(func() {
+		originPeriod = hotplugCheckPeriod
+		hotplugCheckPeriod = 10 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		hotplugCheckPeriod = originPeriod
+	})
+
+	It("enqueues NodeConfig when accelerators are hot-plugged or removed", func() {
+		var (
+			mu           sync.Mutex
+			accelerators = []string{"0000:14:00.0"}
+		)
+		nodeConfig := &fec.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "ns"}}
+		watcher := newAcceleratorsWatcher(nodeConfig, func() ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return accelerators, nil
+		}, logrus.New())
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go func() { _ = watcher.Start(ctx) }()
+
+		Consistently(watcher.events, 50*time.Millisecond).ShouldNot(Receive())
+
+		mu.Lock()
+		accelerators = []string{"0000:14:00.0", "0000:f7:00.0"}
+		mu.Unlock()
+		Eventually(watcher.events).Should(Receive())
+		Consistently(watcher.events, 50*time.Millisecond).ShouldNot(Receive())
+
+		mu.Lock()
+		accelerators = []string{"0000:f7:00.0"}
+		mu.Unlock()
+		Eventually(watcher.events).Should(Receive())
+	})
+
+	It("returns added and removed accelerators", func() {
+		added, removed := acceleratorsChanges([]string{"0000:14:00.0", "0000:f7:00.0"}, []string{"0000:f7:00.0", "0000:b0:00.0"})
+		Expect(added).To(Equal([]string{"0000:b0:00.0"}))
+		Expect(removed).To(Equal([]string{"0000:14:00.0"}))
+
+		added, removed = acceleratorsChanges([]string{"0000:14:00.0"}, []string{"0000:14:00.0"})
+		Expect(added).To(BeEmpty())
+		Expect(removed).To(BeEmpty())
+	})
+})
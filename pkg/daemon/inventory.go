@@ -47,12 +47,13 @@ func GetSriovInventory(log *logrus.Logger) (*sriovv2.NodeInventory, error) {
 		}
 
 		acc := sriovv2.SriovAccelerator{
-			VendorID:   device.Vendor.ID,
-			DeviceID:   device.Product.ID,
-			PCIAddress: device.Address,
-			PFDriver:   driver,
-			MaxVFs:     utils.GetSriovVFcapacity(device.Address),
-			VFs:        []sriovv2.VF{},
+			VendorID:     device.Vendor.ID,
+			DeviceID:     device.Product.ID,
+			PCIAddress:   device.Address,
+			PFDriver:     driver,
+			MaxVFs:       utils.GetSriovVFcapacity(device.Address),
+			SerialNumber: readSerialNumber(device.Address),
+			VFs:          []sriovv2.VF{},
 		}
 
 		vfs, err := utils.GetVFList(device.Address)
@@ -63,6 +64,7 @@ func GetSriovInventory(log *logrus.Logger) (*sriovv2.NodeInventory, error) {
 		for _, vf := range vfs {
 			vfInfo := sriovv2.VF{
 				PCIAddress: vf,
+				Index:      getVFIndex(vf, log),
 			}
 
 			driver, err := utils.GetDriverName(vf)
@@ -122,12 +124,13 @@ func VrbGetSriovInventory(log *logrus.Logger) (*vrbv1.NodeInventory, error) {
 		}
 
 		acc := vrbv1.SriovAccelerator{
-			VendorID:   device.Vendor.ID,
-			DeviceID:   device.Product.ID,
-			PCIAddress: device.Address,
-			PFDriver:   driver,
-			MaxVFs:     utils.GetSriovVFcapacity(device.Address),
-			VFs:        []vrbv1.VF{},
+			VendorID:     device.Vendor.ID,
+			DeviceID:     device.Product.ID,
+			PCIAddress:   device.Address,
+			PFDriver:     driver,
+			MaxVFs:       utils.GetSriovVFcapacity(device.Address),
+			SerialNumber: readSerialNumber(device.Address),
+			VFs:          []vrbv1.VF{},
 		}
 
 		vfs, err := utils.GetVFList(device.Address)
@@ -138,6 +141,7 @@ func VrbGetSriovInventory(log *logrus.Logger) (*vrbv1.NodeInventory, error) {
 		for _, vf := range vfs {
 			vfInfo := vrbv1.VF{
 				PCIAddress: vf,
+				Index:      getVFIndex(vf, log),
 			}
 
 			driver, err := utils.GetDriverName(vf)
@@ -185,3 +189,23 @@ func VrbisKnownDevice(device *pci.Device) bool {
 		device.Class.ID == VrbsupportedAccelerators.Class &&
 		device.Subclass.ID == VrbsupportedAccelerators.SubClass
 }
+
+// hasConfiguredAccelerators returns true if any accelerator of the inventory has VFs created
+func hasConfiguredAccelerators(inv *sriovv2.NodeInventory) bool {
+	for _, acc := range inv.SriovAccelerators {
+		if len(acc.VFs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// vrbHasConfiguredAccelerators returns true if any accelerator of the inventory has VFs created
+func vrbHasConfiguredAccelerators(inv *vrbv1.NodeInventory) bool {
+	for _, acc := range inv.SriovAccelerators {
+		if len(acc.VFs) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+//go:build lab
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// labCommander logs commands operating on the host instead of running them; drivers of emulated devices are expected
+// to be loaded in the lab VM already, and there is no PCI command register or power state to configure
+type labCommander struct{}
+
+func (labCommander) Run(_ context.Context, args []string, log *logrus.Logger) (string, error) {
+	log.WithField("cmd", args).Info("lab build - skipping command on the host")
+	return "", nil
+}
+
+// labPfBBConfig logs the config file rendered for pf_bb_config instead of running it, as emulated devices have no
+// queues to configure
+func labPfBBConfig(_ context.Context, args []string, log *logrus.Logger) (string, error) {
+	entry := log.WithField("cmd", args)
+	if i := slices.Index(args, "-c"); i >= 0 && i+1 < len(args) {
+		config, err := os.ReadFile(args[i+1])
+		if err != nil {
+			entry.WithError(err).Error("failed to read pf_bb_config config file")
+			return "", err
+		}
+		entry = entry.WithField("config", string(config))
+	}
+	entry.Info("lab build - skipping pf_bb_config")
+	return "", nil
+}
+
+// ApplyLabEnvironment replaces integration with the host by its lab counterparts, so the daemon built with lab tag only
+// creates VFs of emulated devices and renders their bbdev config, e.g. in kind or minikube VMs
+func ApplyLabEnvironment(log *logrus.Logger) error {
+	ApplyPfBBConfigWorkdir(log)
+	hostCommander = labCommander{}
+	runExecCmd = labPfBBConfig
+	downloadFile = func(string, string, string, *http.Client) error {
+		return errors.New("downloading FFT LUTs is not supported in lab build")
+	}
+	log.Info("lab build - commands on the host, pf_bb_config and downloads are disabled")
+	return nil
+}
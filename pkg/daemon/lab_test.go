@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+//go:build lab
+
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lab environment", func() {
+	It("skips commands on the host", func() {
+		Expect(labCommander{}.Run(context.TODO(), []string{"modprobe", "vfio-pci"}, log)).To(BeEmpty())
+	})
+
+	It("renders config of pf_bb_config without running it", func() {
+		cfg := filepath.Join(testTmpFolder, "lab.ini")
+		Expect(os.WriteFile(cfg, []byte("[MODE]\npf_mode_en = 0\n"), 0600)).To(Succeed())
+		defer os.Remove(cfg)
+
+		Expect(labPfBBConfig(context.TODO(), []string{"nice", "-n", "5", "pf_bb_config", "ACC100", "-c", cfg}, log)).To(BeEmpty())
+		_, err := labPfBBConfig(context.TODO(), []string{"pf_bb_config", "ACC100", "-c", filepath.Join(testTmpFolder, "missing.ini")}, log)
+		Expect(err).To(HaveOccurred())
+	})
+})
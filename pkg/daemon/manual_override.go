@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// ConditionManualOverride is set on NodeConfig which enforcement is suspended with ManualOverrideAnnotation, its message
+// tells when the enforcement resumes
+const ConditionManualOverride = "ManualOverride"
+
+// manualOverride records start of the manual override of NodeConfig and returns time left until it expires. Once the
+// override expires, or when it's not requested, its annotations and condition are removed and zero is returned.
+// FEC and VRB NodeConfigs share annotations of the override.
+func manualOverride(ctx context.Context, c client.Client, nc client.Object, conditions *[]metav1.Condition, ttl time.Duration, now time.Time) (time.Duration, error) {
+	annotations := maps.Clone(nc.GetAnnotations())
+	if ttl > 0 {
+		since, err := time.Parse(time.RFC3339, annotations[fec.ManualOverrideSinceAnnotation])
+		if err != nil {
+			since = now
+			annotations[fec.ManualOverrideSinceAnnotation] = now.Format(time.RFC3339)
+			if err := patchAnnotations(ctx, c, nc, annotations); err != nil {
+				return 0, err
+			}
+		}
+
+		if remaining := since.Add(ttl).Sub(now); remaining > 0 {
+			message := fmt.Sprintf("enforcement is suspended until %s", since.Add(ttl).Format(time.RFC3339))
+			if condition := meta.FindStatusCondition(*conditions, ConditionManualOverride); condition == nil || condition.Message != message {
+				meta.SetStatusCondition(conditions, metav1.Condition{Type: ConditionManualOverride, Status: metav1.ConditionTrue,
+					Reason: ConditionManualOverride, Message: message, ObservedGeneration: nc.GetGeneration()})
+				if err := c.Status().Update(ctx, nc); err != nil {
+					return 0, err
+				}
+			}
+			return remaining, nil
+		}
+	}
+
+	_, requested := annotations[fec.ManualOverrideAnnotation]
+	_, recorded := annotations[fec.ManualOverrideSinceAnnotation]
+	if requested || recorded {
+		delete(annotations, fec.ManualOverrideAnnotation)
+		delete(annotations, fec.ManualOverrideSinceAnnotation)
+		if err := patchAnnotations(ctx, c, nc, annotations); err != nil {
+			return 0, err
+		}
+	}
+	if meta.FindStatusCondition(*conditions, ConditionManualOverride) != nil {
+		meta.RemoveStatusCondition(conditions, ConditionManualOverride)
+		return 0, c.Status().Update(ctx, nc)
+	}
+	return 0, nil
+}
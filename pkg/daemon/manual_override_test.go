@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ManualOverride", func() {
+	var (
+		c   client.Client
+		nc  *sriovv2.SriovFecNodeConfig
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(sriovv2.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		nc = &sriovv2.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default",
+			Annotations: map[string]string{sriovv2.ManualOverrideAnnotation: "2h"}}}
+		Expect(c.Create(context.TODO(), nc)).To(Succeed())
+	})
+
+	override := func() time.Duration {
+		Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(nc), nc)).To(Succeed())
+		ttl, err := nc.ManualOverrideTTL()
+		Expect(err).ToNot(HaveOccurred())
+		remaining, err := manualOverride(context.TODO(), c, nc, &nc.Status.Conditions, ttl, now)
+		Expect(err).ToNot(HaveOccurred())
+		return remaining
+	}
+
+	It("records the override and counts it down", func() {
+		Expect(override()).To(Equal(2 * time.Hour))
+		Expect(nc.Annotations).To(HaveKeyWithValue(sriovv2.ManualOverrideSinceAnnotation, now.Format(time.RFC3339)))
+		Expect(meta.IsStatusConditionTrue(nc.Status.Conditions, ConditionManualOverride)).To(BeTrue())
+
+		now = now.Add(90 * time.Minute)
+		Expect(override()).To(Equal(30 * time.Minute))
+	})
+
+	It("resumes enforcement once the override expires", func() {
+		override()
+		now = now.Add(2 * time.Hour)
+		Expect(override()).To(BeZero())
+		Expect(nc.Annotations).ToNot(HaveKey(sriovv2.ManualOverrideAnnotation))
+		Expect(nc.Annotations).ToNot(HaveKey(sriovv2.ManualOverrideSinceAnnotation))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, ConditionManualOverride)).To(BeNil())
+	})
+
+	It("resumes enforcement when the override is removed", func() {
+		override()
+		delete(nc.Annotations, sriovv2.ManualOverrideAnnotation)
+		Expect(c.Update(context.TODO(), nc)).To(Succeed())
+		Expect(override()).To(BeZero())
+		Expect(nc.Annotations).ToNot(HaveKey(sriovv2.ManualOverrideSinceAnnotation))
+		Expect(meta.FindStatusCondition(nc.Status.Conditions, ConditionManualOverride)).To(BeNil())
+	})
+
+	It("rejects TTL exceeding the limit", func() {
+		nc.Annotations[sriovv2.ManualOverrideAnnotation] = "48h"
+		_, err := nc.ManualOverrideTTL()
+		Expect(err).To(HaveOccurred())
+	})
+})
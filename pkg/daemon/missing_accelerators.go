@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// reportMissingAccelerators logs and records Warning Event on the NodeConfig listing accelerators requested by its spec
+// which are missing on the node, so their configuration is skipped
+func reportMissingAccelerators(recorder record.EventRecorder, nc runtime.Object, missing []string, log *logrus.Logger) {
+	log.WithField("accelerators", missing).Warn("requested accelerators are missing on the node - their configuration is skipped")
+	if recorder != nil {
+		recorder.Event(nc, corev1.EventTypeWarning, "AcceleratorsMissing",
+			fmt.Sprintf("configuration of accelerators missing on the node is skipped: %s", strings.Join(missing, ", ")))
+	}
+}
+
+// configuredMessage is a message of Succeeded Configured condition, which lists accelerators skipped as missing
+func configuredMessage(missing []string) string {
+	if len(missing) == 0 {
+		return "Configured successfully"
+	}
+	return fmt.Sprintf("Configured successfully, accelerators missing on the node were skipped: %s", strings.Join(missing, ", "))
+}
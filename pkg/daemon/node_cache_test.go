@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("nodeCacheSelectors", func() {
+	It("caches objects of the node only", func() {
+		selectors := map[string]fields.Selector{}
+		for object, selector := range nodeCacheSelectors("worker") {
+			switch object.(type) {
+			case *sriovv2.SriovFecNodeConfig:
+				selectors["fec"] = selector.Field
+			case *vrbv1.SriovVrbNodeConfig:
+				selectors["vrb"] = selector.Field
+			case *corev1.Node:
+				selectors["node"] = selector.Field
+			case *corev1.Pod:
+				selectors["pod"] = selector.Field
+			}
+		}
+		Expect(selectors).To(HaveLen(4))
+		for _, kind := range []string{"fec", "vrb", "node"} {
+			Expect(selectors[kind].Matches(fields.Set{"metadata.name": "worker"})).To(BeTrue())
+			Expect(selectors[kind].Matches(fields.Set{"metadata.name": "other"})).To(BeFalse())
+		}
+		Expect(selectors["pod"].Matches(fields.Set{"spec.nodeName": "worker"})).To(BeTrue())
+		Expect(selectors["pod"].Matches(fields.Set{"spec.nodeName": "other"})).To(BeFalse())
+	})
+})
@@ -4,14 +4,17 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
 	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
 	sriovutils "github.com/intel/sriov-fec-operator/pkg/common/utils"
 	"github.com/k8snetworkplumbingwg/sriov-network-device-plugin/pkg/utils"
 	"github.com/sirupsen/logrus"
@@ -22,12 +25,15 @@ import (
 const (
 	vfNumFileDefault = "sriov_numvfs"
 	vfNumFileIgbUio  = "max_vfs"
+	vfMsixCountFile  = "sriov_vf_msix_count"
+	vfTotalMsixFile  = "sriov_vf_total_msix"
 )
 
 var (
 	runExecCmd       = execCmd
 	getVFconfigured  = utils.GetVFconfigured
 	getVFList        = utils.GetVFList
+	getVFID          = utils.GetVFID
 	workdir          = "/tmp"
 	sysBusPciDevices = "/sys/bus/pci/devices"
 	sysBusPciDrivers = "/sys/bus/pci/drivers"
@@ -47,13 +53,14 @@ type NodeConfigurator struct {
 	Log                  *logrus.Logger
 	nodeNameRef          types.NamespacedName
 	pfBBConfigController *pfBBConfigController
+	layouts              appliedLayouts
 }
 
-func (n *NodeConfigurator) loadModule(module string) error {
+func (n *NodeConfigurator) loadModule(ctx context.Context, module string) error {
 	if module == "" {
 		return fmt.Errorf("module cannot be empty string")
 	}
-	_, err := runExecCmd(append([]string{"modprobe", module}, appendMandatoryArgs(module)...), n.Log)
+	_, err := hostCommander.Run(ctx, append([]string{"modprobe", module}, appendMandatoryArgs(module)...), n.Log)
 	return err
 }
 
@@ -129,12 +136,12 @@ func (n *NodeConfigurator) bindDeviceToDriver(pciAddress, driver string) error {
 	return err
 }
 
-func (n *NodeConfigurator) configureCommandRegister(pciAddr string) error {
+func (n *NodeConfigurator) configureCommandRegister(ctx context.Context, pciAddr string) error {
 	// Configures PCI COMMAND register that enables
 	// 0X02 bit - PCI_COMMAND_MEMORY which is required for MMIO in pf-bb-config
 	// 0X04 bit - PCI_COMMAND_MASTER which required for PF to correctly manage VFs
 	cmd := []string{"setpci", "-v", "-s", pciAddr, "COMMAND=06"}
-	_, err := runExecCmd(cmd, n.Log)
+	_, err := hostCommander.Run(ctx, cmd, n.Log)
 	if err != nil {
 		n.Log.WithError(err).Error("failed to configure PCI command bridge for card: " + pciAddr)
 		return err
@@ -181,6 +188,42 @@ func (n *NodeConfigurator) changeAmountOfVFs(driver string, pfPCIAddress string,
 	return nil
 }
 
+// configureVFsMsixCount assigns given amount of MSI-X vectors to each VF of the PF. Kernel accepts the amount only
+// when VF is not bound to any driver, so VFs are unbound first.
+func (n *NodeConfigurator) configureVFsMsixCount(pfPCIAddress string, vfs []string, msixCount int) error {
+	if msixCount == 0 || len(vfs) == 0 {
+		return nil
+	}
+
+	totalPath := filepath.Join(sysBusPciDevices, pfPCIAddress, vfTotalMsixFile)
+	content, err := os.ReadFile(totalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errclass.New(errclass.Platform, "PF (%s) driver does not support configuration of VFs' MSI-X vectors", pfPCIAddress)
+		}
+		return fmt.Errorf("failed to read total amount of VFs' MSI-X vectors of PF (%s): %w", pfPCIAddress, err)
+	}
+	total, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return fmt.Errorf("failed to parse total amount of VFs' MSI-X vectors of PF (%s): %w", pfPCIAddress, err)
+	}
+	if requested := msixCount * len(vfs); requested > total {
+		return errclass.New(errclass.Validation, "requested %d MSI-X vectors for %d VFs exceeds %d available on PF (%s)", requested, len(vfs), total, pfPCIAddress)
+	}
+
+	for _, vf := range vfs {
+		if err := n.unbindIfBound(vf); err != nil {
+			return err
+		}
+		path := filepath.Join(sysBusPciDevices, vf, vfMsixCountFile)
+		if err := writeFileWithTimeout(path, strconv.Itoa(msixCount)); err != nil {
+			n.Log.WithError(err).WithField("vf", vf).WithField("msixCount", msixCount).Error("failed to set MSI-X vectors of VF")
+			return fmt.Errorf("failed to set MSI-X vectors (%d) of VF (%s): %w", msixCount, vf, err)
+		}
+	}
+	return nil
+}
+
 func (n *NodeConfigurator) flrReset(pfPCIAddress string) error {
 	n.Log.Infof("executing FLR for %s", pfPCIAddress)
 
@@ -192,10 +235,11 @@ func (n *NodeConfigurator) flrReset(pfPCIAddress string) error {
 	return nil
 }
 
-func (n *NodeConfigurator) cleanAcceleratorConfig(acc sriovv2.SriovAccelerator) error {
+func (n *NodeConfigurator) cleanAcceleratorConfig(ctx context.Context, acc sriovv2.SriovAccelerator) error {
 	n.Log.Infof("cleaning configuration on %s", acc.PCIAddress)
+	n.layouts.forget(acc.PCIAddress)
 
-	if err := n.pfBBConfigController.stopPfBBConfig(acc.PCIAddress); err != nil {
+	if err := n.pfBBConfigController.stopPfBBConfig(ctx, acc.PCIAddress); err != nil {
 		return err
 	}
 
@@ -214,10 +258,10 @@ func (n *NodeConfigurator) cleanAcceleratorConfig(acc sriovv2.SriovAccelerator)
 	return nil
 }
 
-func (n *NodeConfigurator) VrbcleanAcceleratorConfig(acc vrbv1.SriovAccelerator) error {
+func (n *NodeConfigurator) VrbcleanAcceleratorConfig(ctx context.Context, acc vrbv1.SriovAccelerator) error {
 	n.Log.Infof("cleaning configuration on %s", acc.PCIAddress)
 
-	if err := n.pfBBConfigController.stopPfBBConfig(acc.PCIAddress); err != nil {
+	if err := n.pfBBConfigController.stopPfBBConfig(ctx, acc.PCIAddress); err != nil {
 		return err
 	}
 
@@ -284,41 +328,77 @@ func VrbunbindVFs(nc *NodeConfigurator, acc vrbv1.SriovAccelerator) error {
 	return nil
 }
 
-func loadDrivers(nc *NodeConfigurator, pfDriver string, vfDriver string) error {
-	if err := nc.loadModule(pfDriver); err != nil {
+func loadDrivers(ctx context.Context, nc *NodeConfigurator, pfDriver string, vfDrivers ...string) error {
+	if err := nc.loadModule(ctx, pfDriver); err != nil {
 		nc.Log.WithField("driver", pfDriver).Info("failed to load module for PF driver")
-		return err
+		return errclass.Wrap(errclass.Platform, err)
 	}
 
-	if err := nc.loadModule(vfDriver); err != nil {
-		nc.Log.WithField("driver", vfDriver).Info("failed to load module for VF driver")
-		return err
+	for _, vfDriver := range vfDrivers {
+		if err := nc.loadModule(ctx, vfDriver); err != nil {
+			nc.Log.WithField("driver", vfDriver).Info("failed to load module for VF driver")
+			return errclass.Wrap(errclass.Platform, err)
+		}
 	}
 	return nil
 }
 
-func (n *NodeConfigurator) ApplySpec(nodeConfig sriovv2.SriovFecNodeConfigSpec) error {
+// vfIndex returns index of the VF within its PF or -1 if it cannot be determined
+func (n *NodeConfigurator) vfIndex(vfPCIAddress string) int {
+	if index := getVFIndex(vfPCIAddress, n.Log); index != nil {
+		return *index
+	}
+	return -1
+}
+
+// getVFIndex returns index of the VF within its PF or nil if it cannot be determined
+func getVFIndex(vfPCIAddress string, log *logrus.Logger) *int {
+	index, err := getVFID(vfPCIAddress)
+	if err != nil {
+		log.WithError(err).WithField("vf", vfPCIAddress).Warn("failed to determine VF index")
+		return nil
+	}
+	return &index
+}
+
+func (n *NodeConfigurator) ApplySpec(ctx context.Context, nodeConfig sriovv2.SriovFecNodeConfigSpec) error {
 	inv, err := getSriovInventory(n.Log)
 	if err != nil {
 		n.Log.WithError(err).Error("failed to obtain current sriov inventory")
 		return err
 	}
 
+	skipped, err := readSkippedDevices(ctx, n.Client, n.nodeNameRef.Name)
+	if err != nil {
+		n.Log.WithError(err).Error("failed to read accelerators skipped on the node")
+		return err
+	}
+	for _, acc := range withoutSkippedAccelerators(inv, skipped) {
+		n.Log.WithField("pci", acc.PCIAddress).Info("accelerator skipped by node's annotation")
+	}
+
 	n.Log.WithField("inventory", inv).Info("current node status")
 
+	// accelerators are configured in order of their PCI addresses, so operations are run in the same order each time
+	sort.Slice(inv.SriovAccelerators, func(i, j int) bool {
+		return inv.SriovAccelerators[i].PCIAddress < inv.SriovAccelerators[j].PCIAddress
+	})
 	for _, acc := range inv.SriovAccelerators {
 		requestedConfig := getMatchingConfiguration(acc.PCIAddress, nodeConfig.PhysicalFunctions)
 		if requestedConfig == nil {
 			if len(acc.VFs) > 0 {
 				n.Log.WithField("pci", acc.PCIAddress).WithField("driverName", acc.PFDriver).Info("zeroing VFs")
-				if err := n.cleanAcceleratorConfig(acc); err != nil {
+				acc := acc
+				if err := runOperation(ctx, OperationClean, acc.PCIAddress, nil, func() bool { return false }, func() error {
+					return n.cleanAcceleratorConfig(ctx, acc)
+				}); err != nil {
 					return err
 				}
 			}
 
 			continue
 		}
-		if err := n.configureAccelerator(acc, requestedConfig); err != nil {
+		if err := n.configureAccelerator(ctx, acc, requestedConfig); err != nil {
 			return err
 		}
 	}
@@ -326,28 +406,44 @@ func (n *NodeConfigurator) ApplySpec(nodeConfig sriovv2.SriovFecNodeConfigSpec)
 	return nil
 }
 
-func (n *NodeConfigurator) VrbApplySpec(nodeConfig vrbv1.SriovVrbNodeConfigSpec) error {
+func (n *NodeConfigurator) VrbApplySpec(ctx context.Context, nodeConfig vrbv1.SriovVrbNodeConfigSpec) error {
 	inv, err := VrbgetSriovInventory(n.Log)
 	if err != nil {
 		n.Log.WithError(err).Error("failed to obtain current sriov inventory")
 		return err
 	}
 
+	skipped, err := readSkippedDevices(ctx, n.Client, n.nodeNameRef.Name)
+	if err != nil {
+		n.Log.WithError(err).Error("failed to read accelerators skipped on the node")
+		return err
+	}
+	for _, acc := range vrbWithoutSkippedAccelerators(inv, skipped) {
+		n.Log.WithField("pci", acc.PCIAddress).Info("accelerator skipped by node's annotation")
+	}
+
 	n.Log.WithField("inventory", inv).Info("current node status")
 
+	// accelerators are configured in order of their PCI addresses, so operations are run in the same order each time
+	sort.Slice(inv.SriovAccelerators, func(i, j int) bool {
+		return inv.SriovAccelerators[i].PCIAddress < inv.SriovAccelerators[j].PCIAddress
+	})
 	for _, acc := range inv.SriovAccelerators {
 		requestedConfig := VrbgetMatchingConfiguration(acc.PCIAddress, nodeConfig.PhysicalFunctions)
 		if requestedConfig == nil {
 			if len(acc.VFs) > 0 {
 				n.Log.WithField("pci", acc.PCIAddress).WithField("driverName", acc.PFDriver).Info("zeroing VFs")
-				if err := n.VrbcleanAcceleratorConfig(acc); err != nil {
+				acc := acc
+				if err := runOperation(ctx, OperationClean, acc.PCIAddress, nil, func() bool { return false }, func() error {
+					return n.VrbcleanAcceleratorConfig(ctx, acc)
+				}); err != nil {
 					return err
 				}
 			}
 
 			continue
 		}
-		if err := n.VrbconfigureAccelerator(acc, requestedConfig); err != nil {
+		if err := n.VrbconfigureAccelerator(ctx, acc, requestedConfig); err != nil {
 			return err
 		}
 	}
@@ -355,90 +451,153 @@ func (n *NodeConfigurator) VrbApplySpec(nodeConfig vrbv1.SriovVrbNodeConfigSpec)
 	return nil
 }
 
-func (n *NodeConfigurator) configureAccelerator(acc sriovv2.SriovAccelerator, requestedConfig *sriovv2.PhysicalFunctionConfigExt) error {
+func (n *NodeConfigurator) configureAccelerator(ctx context.Context, acc sriovv2.SriovAccelerator, requestedConfig *sriovv2.PhysicalFunctionConfigExt) error {
 	n.Log.WithField("requestedConfig", requestedConfig).Info("configuring PF")
 
-	if err := n.cleanAcceleratorConfig(acc); err != nil {
-		return err
-	}
-
-	if err := loadDrivers(n, requestedConfig.PFDriver, requestedConfig.VFDriver); err != nil {
-		return err
-	}
-
-	if err := n.bindDeviceToDriver(requestedConfig.PCIAddress, requestedConfig.PFDriver); err != nil {
-		return err
-	}
-
-	if err := n.configureCommandRegister(requestedConfig.PCIAddress); err != nil {
-		return err
+	verified := func() bool {
+		return n.isPFStateVerified(ctx, acc.PCIAddress, requestedConfig.PFDriver, len(acc.VFs), requestedConfig.VFAmount)
 	}
-
-	if err := n.pfBBConfigController.initializePfBBConfig(acc, requestedConfig); err != nil {
-		return err
-	}
-
-	if err := n.changeAmountOfVFs(requestedConfig.PFDriver, requestedConfig.PCIAddress, requestedConfig.VFAmount); err != nil {
-		return err
+	var err error
+	if n.isQueueOnlyChange(acc, *requestedConfig) {
+		err = runOperation(ctx, OperationReconfigureQueues, acc.PCIAddress, requestedConfig, verified, func() error {
+			return n.reconfigureQueues(ctx, acc, requestedConfig)
+		})
+	} else {
+		err = runOperation(ctx, OperationConfigure, acc.PCIAddress, requestedConfig, verified, func() error {
+			return n.recreateAccelerator(ctx, acc, requestedConfig)
+		})
 	}
-
-	createdVfs, err := getVFList(acc.PCIAddress)
 	if err != nil {
-		n.Log.WithError(err).Error("failed to get list of newly created VFs")
+		n.layouts.forget(acc.PCIAddress)
 		return err
 	}
-
-	for _, vf := range createdVfs {
-		if err := n.bindDeviceToDriver(vf, requestedConfig.VFDriver); err != nil {
-			return err
-		}
-	}
-
+	n.layouts.record(acc.PCIAddress, vfLayout(*requestedConfig))
 	return nil
+}
 
+// recreateAccelerator cleans the accelerator and configures it from scratch, i.e. VFs are recreated
+func (n *NodeConfigurator) recreateAccelerator(ctx context.Context, acc sriovv2.SriovAccelerator, requestedConfig *sriovv2.PhysicalFunctionConfigExt) error {
+	var createdVfs []string
+	clean := func() error {
+		return n.cleanAcceleratorConfig(ctx, acc)
+	}
+	return n.configureWithTimeout(ctx, acc.PCIAddress, clean,
+		clean,
+		func() error {
+			return loadDrivers(ctx, n, requestedConfig.PFDriver, requestedConfig.VFDrivers()...)
+		},
+		func() error {
+			return n.releasePF(acc.PCIAddress, acc.PFDriver, requestedConfig.PFDriver)
+		},
+		func() error {
+			return n.bindDeviceToDriver(requestedConfig.PCIAddress, requestedConfig.PFDriver)
+		},
+		func() error {
+			return n.configureCommandRegister(ctx, requestedConfig.PCIAddress)
+		},
+		func() error {
+			return n.pfBBConfigController.initializePfBBConfig(ctx, acc, requestedConfig)
+		},
+		func() error {
+			return n.changeAmountOfVFs(requestedConfig.PFDriver, requestedConfig.PCIAddress, requestedConfig.VFAmount)
+		},
+		func() (err error) {
+			if createdVfs, err = getVFList(acc.PCIAddress); err != nil {
+				n.Log.WithError(err).Error("failed to get list of newly created VFs")
+			}
+			return err
+		},
+		func() error {
+			return n.configureVFsMsixCount(acc.PCIAddress, createdVfs, requestedConfig.VFMsixCount)
+		},
+		func() error {
+			for _, vf := range createdVfs {
+				if err := n.bindDeviceToDriver(vf, requestedConfig.VFDriverFor(n.vfIndex(vf))); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		func() error {
+			if pm := requestedConfig.PowerManagement; pm != nil {
+				return n.configurePowerManagement(acc.PCIAddress, pm.DisableRuntimePM, pm.DisableASPM, pm.DisableD3Cold)
+			}
+			return nil
+		},
+	)
 }
 
-func (n *NodeConfigurator) VrbconfigureAccelerator(acc vrbv1.SriovAccelerator, requestedConfig *vrbv1.PhysicalFunctionConfigExt) error {
+func (n *NodeConfigurator) VrbconfigureAccelerator(ctx context.Context, acc vrbv1.SriovAccelerator, requestedConfig *vrbv1.PhysicalFunctionConfigExt) error {
 	n.Log.WithField("requestedConfig", requestedConfig).Info("configuring PF")
 
-	if err := n.VrbcleanAcceleratorConfig(acc); err != nil {
-		return err
-	}
-
-	if err := loadDrivers(n, requestedConfig.PFDriver, requestedConfig.VFDriver); err != nil {
-		return err
-	}
-
-	if err := n.bindDeviceToDriver(requestedConfig.PCIAddress, requestedConfig.PFDriver); err != nil {
-		return err
-	}
-
-	if err := n.configureCommandRegister(requestedConfig.PCIAddress); err != nil {
-		return err
-	}
-
-	if err := n.pfBBConfigController.VrbinitializePfBBConfig(acc, requestedConfig); err != nil {
-		return err
-	}
-
-	if err := n.changeAmountOfVFs(requestedConfig.PFDriver, requestedConfig.PCIAddress, requestedConfig.VFAmount); err != nil {
-		return err
+	verified := func() bool {
+		return n.isPFStateVerified(ctx, acc.PCIAddress, requestedConfig.PFDriver, len(acc.VFs), requestedConfig.VFAmount)
 	}
+	return runOperation(ctx, OperationConfigure, acc.PCIAddress, requestedConfig, verified, func() error {
+		return n.VrbrecreateAccelerator(ctx, acc, requestedConfig)
+	})
+}
 
-	createdVfs, err := getVFList(acc.PCIAddress)
-	if err != nil {
-		n.Log.WithError(err).Error("failed to get list of newly created VFs")
-		return err
+// isPFStateVerified tells whether the accelerator is still in the state its completed configuration left it in: it
+// exposes the requested amount of VFs and, with vfio-pci PF driver, pf_bb_config serving it is running. pf_bb_config
+// runs in the daemon's container then, so it's gone after restart of the daemon even though the VFs are kept.
+func (n *NodeConfigurator) isPFStateVerified(ctx context.Context, pciAddress, pfDriver string, vfs, vfAmount int) bool {
+	if vfs != vfAmount {
+		return false
 	}
+	return !strings.EqualFold(pfDriver, sriovutils.VFIO_PCI) || !pfBbConfigProcIsDead(ctx, n.Log, pciAddress)
+}
 
-	for _, vf := range createdVfs {
-		if err := n.bindDeviceToDriver(vf, requestedConfig.VFDriver); err != nil {
+// VrbrecreateAccelerator cleans the accelerator and configures it from scratch, i.e. VFs are recreated
+func (n *NodeConfigurator) VrbrecreateAccelerator(ctx context.Context, acc vrbv1.SriovAccelerator, requestedConfig *vrbv1.PhysicalFunctionConfigExt) error {
+	var createdVfs []string
+	clean := func() error {
+		return n.VrbcleanAcceleratorConfig(ctx, acc)
+	}
+	return n.configureWithTimeout(ctx, acc.PCIAddress, clean,
+		clean,
+		func() error {
+			return loadDrivers(ctx, n, requestedConfig.PFDriver, requestedConfig.VFDrivers()...)
+		},
+		func() error {
+			return n.releasePF(acc.PCIAddress, acc.PFDriver, requestedConfig.PFDriver)
+		},
+		func() error {
+			return n.bindDeviceToDriver(requestedConfig.PCIAddress, requestedConfig.PFDriver)
+		},
+		func() error {
+			return n.configureCommandRegister(ctx, requestedConfig.PCIAddress)
+		},
+		func() error {
+			return n.pfBBConfigController.VrbinitializePfBBConfig(ctx, acc, requestedConfig)
+		},
+		func() error {
+			return n.changeAmountOfVFs(requestedConfig.PFDriver, requestedConfig.PCIAddress, requestedConfig.VFAmount)
+		},
+		func() (err error) {
+			if createdVfs, err = getVFList(acc.PCIAddress); err != nil {
+				n.Log.WithError(err).Error("failed to get list of newly created VFs")
+			}
 			return err
-		}
-	}
-
-	return nil
-
+		},
+		func() error {
+			return n.configureVFsMsixCount(acc.PCIAddress, createdVfs, requestedConfig.VFMsixCount)
+		},
+		func() error {
+			for _, vf := range createdVfs {
+				if err := n.bindDeviceToDriver(vf, requestedConfig.VFDriverFor(n.vfIndex(vf))); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		func() error {
+			if pm := requestedConfig.PowerManagement; pm != nil {
+				return n.configurePowerManagement(acc.PCIAddress, pm.DisableRuntimePM, pm.DisableASPM, pm.DisableD3Cold)
+			}
+			return nil
+		},
+	)
 }
 
 func getMatchingConfiguration(pciAddress string, configurations []sriovv2.PhysicalFunctionConfigExt) *sriovv2.PhysicalFunctionConfigExt {
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// nodeBecameReadyHandler enqueues NodeConfig of the node as soon as the node transitions from NotReady to Ready
+// (e.g. after reboot), so configuration is verified and reapplied without waiting for resyncPeriod
+func nodeBecameReadyHandler(nodeNameRef types.NamespacedName, log *logrus.Logger) handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldNode, ok := e.ObjectOld.(*corev1.Node)
+			if !ok || oldNode.Name != nodeNameRef.Name {
+				return
+			}
+			newNode, ok := e.ObjectNew.(*corev1.Node)
+			if !ok || isNodeReady(oldNode) || !isNodeReady(newNode) {
+				return
+			}
+			log.WithField("node", nodeNameRef.Name).Info("node became Ready, enqueuing NodeConfig")
+			q.Add(reconcile.Request{NamespacedName: nodeNameRef})
+		},
+	}
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("nodeBecameReadyHandler", func() {
+	nodeNameRef := types.NamespacedName{Name: "node1", Namespace: "default"}
+
+	node := func(name string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: ready},
+			}},
+		}
+	}
+
+	update := func(oldNode, newNode *corev1.Node) workqueue.RateLimitingInterface {
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		nodeBecameReadyHandler(nodeNameRef, log).Update(event.UpdateEvent{ObjectOld: oldNode, ObjectNew: newNode}, q)
+		return q
+	}
+
+	It("enqueues NodeConfig when node becomes Ready", func() {
+		q := update(node("node1", corev1.ConditionFalse), node("node1", corev1.ConditionTrue))
+		Expect(q.Len()).To(Equal(1))
+		item, _ := q.Get()
+		Expect(item).To(Equal(reconcile.Request{NamespacedName: nodeNameRef}))
+	})
+
+	It("ignores other updates", func() {
+		Expect(update(node("node1", corev1.ConditionTrue), node("node1", corev1.ConditionTrue)).Len()).To(BeZero())
+		Expect(update(node("node1", corev1.ConditionTrue), node("node1", corev1.ConditionUnknown)).Len()).To(BeZero())
+		Expect(update(node("node2", corev1.ConditionFalse), node("node2", corev1.ConditionTrue)).Len()).To(BeZero())
+	})
+})
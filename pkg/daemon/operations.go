@@ -0,0 +1,153 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+// Types of disruptive operations of accelerators
+const (
+	// OperationConfigure recreates VFs of the accelerator and runs pf_bb_config
+	OperationConfigure         = "Configure"
+	OperationReconfigureQueues = "ReconfigureQueues"
+	// OperationClean removes VFs of the accelerator which isn't configured anymore
+	OperationClean = "Clean"
+)
+
+// Phases of disruptive operations of accelerators
+const (
+	OperationStarted   = "Started"
+	OperationCompleted = "Completed"
+	OperationFailed    = "Failed"
+)
+
+// operationJournal records phases of disruptive operations of accelerators into status of the NodeConfig, so after
+// restart of the daemon operations of the interrupted configuration are told apart into completed and interrupted ones
+type operationJournal struct {
+	bootID string
+	// completed keeps keys of operations completed by the interrupted configuration, keyed by PCI address
+	completed map[string]string
+	record    func(fec.Operation)
+	log       *logrus.Logger
+}
+
+type operationJournalKey struct{}
+
+// newOperationJournal returns journal of the configuration of the NodeConfig with given operations. Completed operations
+// are trusted only when the configuration resumes the interrupted one, otherwise all operations are run, e.g. to revert
+// drift of the device state.
+func newOperationJournal(operations []fec.Operation, resumed bool, record func(fec.Operation), log *logrus.Logger) *operationJournal {
+	bootID, err := readBootID()
+	if err != nil {
+		log.WithError(err).Warn("failed to read boot ID, operations of the interrupted configuration are run again")
+		resumed = false
+	}
+	journal := &operationJournal{bootID: bootID, completed: map[string]string{}, record: record, log: log}
+	for _, op := range operations {
+		if resumed && op.Phase == OperationCompleted {
+			journal.completed[op.PCIAddress] = op.Key
+		}
+	}
+	return journal
+}
+
+// operationKey returns idempotency key of the operation, which is the same for the operation of the accelerator
+// requested with the same configuration within the same boot of the node
+func operationKey(bootID, opType, pciAddress string, config interface{}) string {
+	return fmt.Sprintf("%s-%s-%.16s", opType, pciAddress, configChecksum([]interface{}{bootID, config}))
+}
+
+// runOperation runs the disruptive operation of the accelerator, unless the journal of ctx reports it was completed
+// under the same idempotency key before the configuration was interrupted and verified confirms the accelerator is
+// still in the state the operation left it in. Phases of the operation are recorded in the journal; without journal
+// the operation is just run.
+func runOperation(ctx context.Context, opType, pciAddress string, config interface{}, verified func() bool, run func() error) error {
+	journal, ok := ctx.Value(operationJournalKey{}).(*operationJournal)
+	if !ok {
+		return run()
+	}
+
+	key := operationKey(journal.bootID, opType, pciAddress, config)
+	log := journal.log.WithField("pci", pciAddress).WithField("operation", opType).WithField("key", key)
+	if journal.completed[pciAddress] == key {
+		if verified() {
+			log.Info("operation was completed before the configuration was interrupted, skipping")
+			return nil
+		}
+		log.Info("operation was completed before the configuration was interrupted, but the accelerator's state changed since, running it again")
+	}
+
+	journal.record(fec.Operation{Key: key, Type: opType, PCIAddress: pciAddress, Phase: OperationStarted, Timestamp: metav1.Now()})
+	err := run()
+	phase := OperationCompleted
+	if err != nil {
+		phase = OperationFailed
+	}
+	journal.record(fec.Operation{Key: key, Type: opType, PCIAddress: pciAddress, Phase: phase, Timestamp: metav1.Now()})
+	return err
+}
+
+// withOperation returns operations with the last operation of the accelerator replaced with op, sorted by PCI address
+func withOperation(operations []fec.Operation, op fec.Operation) []fec.Operation {
+	updated := []fec.Operation{op}
+	for _, existing := range operations {
+		if existing.PCIAddress != op.PCIAddress {
+			updated = append(updated, existing)
+		}
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].PCIAddress < updated[j].PCIAddress })
+	return updated
+}
+
+// withOperationJournal returns a context recording disruptive operations into status.operations of the NodeConfig;
+// resumed tells whether the configuration resumes the one interrupted by restart of the daemon. The status is patched,
+// so the NodeConfig is updated later with the resource version returned by the patch.
+func (r *FecNodeConfigReconciler) withOperationJournal(ctx context.Context, nc *fec.SriovFecNodeConfig, resumed bool) context.Context {
+	journal := newOperationJournal(nc.Status.Operations, resumed, func(op fec.Operation) {
+		patched := nc.DeepCopy()
+		patched.Status.Operations = withOperation(nc.Status.Operations, op)
+		if err := r.Status().Patch(ctx, patched, client.MergeFrom(nc)); err != nil {
+			r.log.WithError(err).Warn("failed to record operation")
+			return
+		}
+		nc.Status.Operations = patched.Status.Operations
+		nc.ResourceVersion = patched.ResourceVersion
+	}, r.log)
+	return context.WithValue(ctx, operationJournalKey{}, journal)
+}
+
+// withOperationJournal returns a context recording disruptive operations into status.operations of the NodeConfig;
+// resumed tells whether the configuration resumes the one interrupted by restart of the daemon. The status is patched,
+// so the NodeConfig is updated later with the resource version returned by the patch.
+func (r *VrbNodeConfigReconciler) withOperationJournal(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig, resumed bool) context.Context {
+	var operations []fec.Operation
+	for _, op := range nc.Status.Operations {
+		operations = append(operations, fec.Operation(op))
+	}
+	journal := newOperationJournal(operations, resumed, func(op fec.Operation) {
+		patched := nc.DeepCopy()
+		patched.Status.Operations = nil
+		for _, updated := range withOperation(operations, op) {
+			patched.Status.Operations = append(patched.Status.Operations, vrbv1.Operation(updated))
+		}
+		if err := r.Status().Patch(ctx, patched, client.MergeFrom(nc)); err != nil {
+			r.log.WithError(err).Warn("failed to record operation")
+			return
+		}
+		operations = withOperation(operations, op)
+		nc.Status.Operations = patched.Status.Operations
+		nc.ResourceVersion = patched.ResourceVersion
+	}, r.log)
+	return context.WithValue(ctx, operationJournalKey{}, journal)
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OperationJournal", func() {
+	const pciAddress = "0000:3b:00.0"
+	var (
+		originBootIDFilePath string
+		reconciler           *FecNodeConfigReconciler
+		nc                   *sriovv2.SriovFecNodeConfig
+		config               *sriovv2.PhysicalFunctionConfigExt
+		runs                 int
+		verified             bool
+	)
+
+	run := func(resumed bool, result error) error {
+		ctx := reconciler.withOperationJournal(context.TODO(), nc, resumed)
+		return runOperation(ctx, OperationConfigure, pciAddress, config, func() bool { return verified }, func() error {
+			runs++
+			return result
+		})
+	}
+
+	stored := func() []sriovv2.Operation {
+		stored := new(sriovv2.SriovFecNodeConfig)
+		Expect(reconciler.Get(context.TODO(), client.ObjectKeyFromObject(nc), stored)).To(Succeed())
+		return stored.Status.Operations
+	}
+
+	BeforeEach(func() {
+		originBootIDFilePath = bootIDFilePath
+		bootIDFilePath = filepath.Join(testTmpFolder, "boot_id")
+		Expect(os.WriteFile(bootIDFilePath, []byte("boot-1\n"), 0644)).To(Succeed())
+
+		scheme := runtime.NewScheme()
+		Expect(sriovv2.AddToScheme(scheme)).To(Succeed())
+		reconciler = &FecNodeConfigReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), log: logrus.New()}
+		nc = &sriovv2.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}}
+		Expect(reconciler.Create(context.TODO(), nc)).To(Succeed())
+		config = &sriovv2.PhysicalFunctionConfigExt{PCIAddress: pciAddress, PFDriver: "vfio-pci", VFDriver: "vfio-pci", VFAmount: 2}
+		runs = 0
+		verified = true
+	})
+
+	AfterEach(func() {
+		bootIDFilePath = originBootIDFilePath
+	})
+
+	It("records the completed operation in status", func() {
+		Expect(run(false, nil)).To(Succeed())
+		Expect(runs).To(Equal(1))
+		operations := stored()
+		Expect(operations).To(HaveLen(1))
+		Expect(operations[0].Type).To(Equal(OperationConfigure))
+		Expect(operations[0].PCIAddress).To(Equal(pciAddress))
+		Expect(operations[0].Phase).To(Equal(OperationCompleted))
+		Expect(operations[0].Key).To(HavePrefix(OperationConfigure + "-" + pciAddress + "-"))
+	})
+
+	It("records the failed operation in status", func() {
+		Expect(run(false, fmt.Errorf("pf_bb_config failed"))).ToNot(Succeed())
+		Expect(stored()[0].Phase).To(Equal(OperationFailed))
+	})
+
+	It("skips the completed operation only when the interrupted configuration resumes", func() {
+		Expect(run(false, nil)).To(Succeed())
+		Expect(run(true, nil)).To(Succeed())
+		Expect(runs).To(Equal(1))
+
+		Expect(run(false, nil)).To(Succeed())
+		Expect(runs).To(Equal(2))
+	})
+
+	It("runs the completed operation again when the accelerator's state can't be verified", func() {
+		Expect(run(false, nil)).To(Succeed())
+		verified = false
+		Expect(run(true, nil)).To(Succeed())
+		Expect(runs).To(Equal(2))
+	})
+
+	It("runs the interrupted operation again", func() {
+		Expect(run(false, fmt.Errorf("daemon restarted"))).ToNot(Succeed())
+		Expect(run(true, nil)).To(Succeed())
+		Expect(runs).To(Equal(2))
+	})
+
+	It("runs the completed operation again when the configuration or the boot changed", func() {
+		Expect(run(false, nil)).To(Succeed())
+
+		config.VFAmount = 4
+		Expect(run(true, nil)).To(Succeed())
+		Expect(runs).To(Equal(2))
+
+		Expect(os.WriteFile(bootIDFilePath, []byte("boot-2\n"), 0644)).To(Succeed())
+		Expect(run(true, nil)).To(Succeed())
+		Expect(runs).To(Equal(3))
+	})
+
+	It("runs the operation without journal", func() {
+		Expect(runOperation(context.TODO(), OperationClean, pciAddress, nil, func() bool { return false }, func() error {
+			runs++
+			return nil
+		})).To(Succeed())
+		Expect(runs).To(Equal(1))
+		Expect(stored()).To(BeEmpty())
+	})
+})
@@ -326,9 +326,9 @@ func pollLogFile(pciAddr string, filePath string, searchStr string, log *logrus.
 	return file, nil
 }
 
-func StartPfBbConfigCli(nodeName string, ns string, directClient client.Client, cmd string, args []string, log *logrus.Logger) {
+func StartPfBbConfigCli(ctx context.Context, nodeName string, ns string, directClient client.Client, cmd string, args []string, log *logrus.Logger) {
 	nodeConfig := &fec.SriovFecNodeConfig{}
-	err := directClient.Get(context.Background(), client.ObjectKey{Name: nodeName, Namespace: ns}, nodeConfig)
+	err := directClient.Get(ctx, client.ObjectKey{Name: nodeName, Namespace: ns}, nodeConfig)
 	if err != nil {
 		log.WithError(err).WithField("nodeName", nodeName).WithField("namespace", ns).Error("failed to get SriovFecNodeConfig to run CLI command")
 		return
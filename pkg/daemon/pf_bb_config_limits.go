@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var (
+	pfBBConfigWorkdirEnv     = utils.SRIOV_PREFIX + "PF_BB_CONFIG_WORKDIR"
+	pfBBConfigNiceEnv        = utils.SRIOV_PREFIX + "PF_BB_CONFIG_NICE"
+	pfBBConfigCpusetEnv      = utils.SRIOV_PREFIX + "PF_BB_CONFIG_CPUSET"
+	pfBBConfigMemoryLimitEnv = utils.SRIOV_PREFIX + "PF_BB_CONFIG_MEMORY_LIMIT"
+
+	cpusetRegex = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+
+	// workdirFallback tells why the configured workdir is not used, it's empty when the configured workdir is used
+	workdirFallback string
+)
+
+// ConditionWorkdirFallback is set on NodeConfig when pf_bb_config's config files are kept in the default workdir
+// instead of the one configured with SRIOV_FEC_PF_BB_CONFIG_WORKDIR
+const ConditionWorkdirFallback = "WorkdirFallback"
+
+// pfBBConfigLimits are applied to spawned pf_bb_config processes, so they don't interfere with isolated RAN cores.
+// Limits are applied by wrapping pf_bb_config with nice, taskset and prlimit - they are inherited by pf_bb_config
+// also when it daemonizes.
+type pfBBConfigLimits struct {
+	nice        *int
+	cpuset      string
+	memoryLimit int64
+}
+
+// loadPfBBConfigLimits reads limits from environment, invalid values are reported and ignored
+func loadPfBBConfigLimits(log *logrus.Logger) pfBBConfigLimits {
+	limits := pfBBConfigLimits{}
+
+	if value := os.Getenv(pfBBConfigNiceEnv); value != "" {
+		nice, err := strconv.Atoi(value)
+		if err != nil || nice < -20 || nice > 19 {
+			log.WithField(pfBBConfigNiceEnv, value).Error("nice level has to be an integer in range [-20, 19], ignoring it")
+		} else {
+			limits.nice = &nice
+		}
+	}
+
+	if value := os.Getenv(pfBBConfigCpusetEnv); value != "" {
+		if !cpusetRegex.MatchString(value) {
+			log.WithField(pfBBConfigCpusetEnv, value).Error("cpuset has to be a list of CPUs, e.g. 0-1,4, ignoring it")
+		} else {
+			limits.cpuset = value
+		}
+	}
+
+	if value := os.Getenv(pfBBConfigMemoryLimitEnv); value != "" {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil || quantity.Value() <= 0 {
+			log.WithField(pfBBConfigMemoryLimitEnv, value).Error("memory limit has to be a positive quantity, e.g. 256Mi, ignoring it")
+		} else {
+			limits.memoryLimit = quantity.Value()
+		}
+	}
+
+	log.WithField("limits", limits.wrap(nil)).Info("pf_bb_config limits")
+	return limits
+}
+
+// wrap prefixes pf_bb_config command with commands applying the limits
+func (l pfBBConfigLimits) wrap(cmd []string) []string {
+	var wrapped []string
+	if l.nice != nil {
+		wrapped = append(wrapped, "nice", "-n", strconv.Itoa(*l.nice))
+	}
+	if l.cpuset != "" {
+		wrapped = append(wrapped, "taskset", "-c", l.cpuset)
+	}
+	if l.memoryLimit > 0 {
+		wrapped = append(wrapped, "prlimit", "--as="+strconv.FormatInt(l.memoryLimit, 10), "--")
+	}
+	return append(wrapped, cmd...)
+}
+
+// ApplyPfBBConfigWorkdir sets the directory keeping pf_bb_config's config files, if configured in environment. When
+// the directory can't be created, e.g. on read-only root filesystem of the daemon, the default workdir is kept and
+// WorkdirFallback condition is reported on NodeConfigs.
+func ApplyPfBBConfigWorkdir(log *logrus.Logger) {
+	dir := os.Getenv(pfBBConfigWorkdirEnv)
+	if dir == "" {
+		return
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.WithError(err).WithField("workdir", dir).WithField("default", workdir).
+			Error("failed to create pf_bb_config workdir, using the default one")
+		workdirFallback = fmt.Sprintf("%s is used instead of %s: %v", workdir, dir, err)
+		return
+	}
+	workdir = dir
+	log.WithField("workdir", dir).Info("pf_bb_config workdir")
+}
+
+// setWorkdirFallback reports in WorkdirFallback condition that the configured workdir is not used
+func setWorkdirFallback(conditions *[]metav1.Condition, generation int64) {
+	if workdirFallback == "" {
+		meta.RemoveStatusCondition(conditions, ConditionWorkdirFallback)
+		return
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{Type: ConditionWorkdirFallback, Status: metav1.ConditionTrue,
+		Reason: ConditionWorkdirFallback, Message: workdirFallback, ObservedGeneration: generation})
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("pf_bb_config limits", func() {
+	envs := []string{pfBBConfigWorkdirEnv, pfBBConfigNiceEnv, pfBBConfigCpusetEnv, pfBBConfigMemoryLimitEnv}
+
+	AfterEach(func() {
+		for _, env := range envs {
+			Expect(os.Unsetenv(env)).To(Succeed())
+		}
+	})
+
+	It("does not wrap pf_bb_config when limits are not configured", func() {
+		limits := loadPfBBConfigLimits(logrus.New())
+		Expect(limits.wrap([]string{"pf_bb_config", "ACC100"})).To(Equal([]string{"pf_bb_config", "ACC100"}))
+	})
+
+	It("wraps pf_bb_config with configured limits", func() {
+		Expect(os.Setenv(pfBBConfigNiceEnv, "10")).To(Succeed())
+		Expect(os.Setenv(pfBBConfigCpusetEnv, "0-1,4")).To(Succeed())
+		Expect(os.Setenv(pfBBConfigMemoryLimitEnv, "256Mi")).To(Succeed())
+
+		limits := loadPfBBConfigLimits(logrus.New())
+		Expect(limits.wrap([]string{"pf_bb_config", "ACC100"})).To(Equal([]string{
+			"nice", "-n", "10",
+			"taskset", "-c", "0-1,4",
+			"prlimit", "--as=268435456", "--",
+			"pf_bb_config", "ACC100",
+		}))
+	})
+
+	It("ignores invalid limits", func() {
+		Expect(os.Setenv(pfBBConfigNiceEnv, "42")).To(Succeed())
+		Expect(os.Setenv(pfBBConfigCpusetEnv, "all")).To(Succeed())
+		Expect(os.Setenv(pfBBConfigMemoryLimitEnv, "-1Gi")).To(Succeed())
+
+		limits := loadPfBBConfigLimits(logrus.New())
+		Expect(limits.wrap([]string{"pf_bb_config"})).To(Equal([]string{"pf_bb_config"}))
+	})
+
+	It("creates configured workdir", func() {
+		defaultWorkdir := workdir
+		defer func() { workdir = defaultWorkdir }()
+
+		dir := filepath.Join(os.TempDir(), "pf_bb_config_workdir_test")
+		defer os.RemoveAll(dir)
+		Expect(os.Setenv(pfBBConfigWorkdirEnv, dir)).To(Succeed())
+
+		ApplyPfBBConfigWorkdir(logrus.New())
+		Expect(workdir).To(Equal(dir))
+		Expect(dir).To(BeADirectory())
+
+		var conditions []metav1.Condition
+		setWorkdirFallback(&conditions, 1)
+		Expect(conditions).To(BeEmpty())
+	})
+
+	It("keeps the default workdir and reports it when configured workdir can't be created", func() {
+		defaultWorkdir := workdir
+		defer func() { workdir, workdirFallback = defaultWorkdir, "" }()
+
+		file := filepath.Join(os.TempDir(), "pf_bb_config_workdir_file")
+		Expect(os.WriteFile(file, nil, 0600)).To(Succeed())
+		defer os.Remove(file)
+		Expect(os.Setenv(pfBBConfigWorkdirEnv, filepath.Join(file, "workdir"))).To(Succeed())
+
+		ApplyPfBBConfigWorkdir(logrus.New())
+		Expect(workdir).To(Equal(defaultWorkdir))
+
+		var conditions []metav1.Condition
+		setWorkdirFallback(&conditions, 1)
+		Expect(conditions).To(HaveLen(1))
+		Expect(conditions[0].Type).To(Equal(ConditionWorkdirFallback))
+		Expect(conditions[0].Status).To(Equal(metav1.ConditionTrue))
+		Expect(conditions[0].Message).To(ContainSubstring(defaultWorkdir))
+	})
+})
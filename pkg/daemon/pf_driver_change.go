@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"path/filepath"
+)
+
+// releasePF unbinds the cleaned PF from its current driver, when other driver is requested, clears its driver_override
+// and resets it, so the requested driver is bound to a device without state left by the previous one
+func (n *NodeConfigurator) releasePF(pciAddress, boundDriver, requestedDriver string) error {
+	if boundDriver == "" || boundDriver == requestedDriver {
+		return nil
+	}
+	n.Log.WithField("pci", pciAddress).WithField("from", boundDriver).WithField("to", requestedDriver).Info("changing driver of PF")
+
+	if err := n.unbindIfBound(pciAddress); err != nil {
+		return err
+	}
+
+	driverOverridePath := filepath.Join(sysBusPciDevices, pciAddress, "driver_override")
+	if err := writeFileWithTimeout(driverOverridePath, "\n"); err != nil {
+		n.Log.WithError(err).WithField("path", driverOverridePath).Error("failed to clear driver override")
+		return err
+	}
+
+	return n.flrReset(pciAddress)
+}
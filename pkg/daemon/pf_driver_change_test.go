@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var _ = Describe("releasePF()", func() {
+	const pf = "0000:99:00.0"
+
+	var (
+		originalSysBusPciDevices string
+		configurator             *NodeConfigurator
+	)
+
+	BeforeEach(func() {
+		originalSysBusPciDevices = sysBusPciDevices
+		sysBusPciDevices = filepath.Join(testTmpFolder, "driver-change")
+		Expect(createFiles(filepath.Join(sysBusPciDevices, pf), "driver_override", "reset")).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(sysBusPciDevices, pf, "driver_override"), []byte(utils.PCI_PF_STUB_DASH), 0600)).To(Succeed())
+		configurator = &NodeConfigurator{Log: log}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sysBusPciDevices)).To(Succeed())
+		sysBusPciDevices = originalSysBusPciDevices
+	})
+
+	It("clears driver override and resets PF when other driver is requested", func() {
+		Expect(configurator.releasePF(pf, utils.PCI_PF_STUB_DASH, utils.VFIO_PCI)).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(sysBusPciDevices, pf, "driver_override"))).To(BeEquivalentTo("\n"))
+		Expect(os.ReadFile(filepath.Join(sysBusPciDevices, pf, "reset"))).To(BeEquivalentTo("1"))
+	})
+
+	It("leaves PF bound to the requested driver", func() {
+		Expect(configurator.releasePF(pf, utils.VFIO_PCI, utils.VFIO_PCI)).To(Succeed())
+		Expect(configurator.releasePF(pf, "", utils.VFIO_PCI)).To(Succeed())
+		Expect(os.ReadFile(filepath.Join(sysBusPciDevices, pf, "driver_override"))).To(BeEquivalentTo(utils.PCI_PF_STUB_DASH))
+		Expect(os.ReadFile(filepath.Join(sysBusPciDevices, pf, "reset"))).To(BeEmpty())
+	})
+})
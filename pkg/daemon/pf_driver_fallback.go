@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// selectPFDriver returns the first of PF drivers, in order of preference, which can be bound on the node, i.e. it's
+// loaded or can be loaded and kernel lockdown allows it. The requested driver is returned when none of them can be
+// bound, so validation of the node config reports it.
+func selectPFDriver(ctx context.Context, drivers []string, log *logrus.Logger) string {
+	lockdown := isKernelLockdownEnabled()
+	for _, driver := range drivers {
+		if lockdown && driver != utils.VFIO_PCI {
+			continue
+		}
+		if isDriverAvailable(ctx, driver, log) {
+			return driver
+		}
+	}
+	return drivers[0]
+}
+
+// selectPFDrivers returns drivers selected on the node instead of the requested ones for physical functions having
+// fallbacks; they are recorded in status.selectedPfDrivers, while the spec keeps the requested drivers
+func selectPFDrivers(ctx context.Context, physicalFunctions []fec.PhysicalFunctionConfigExt, log *logrus.Logger) []fec.SelectedPFDriver {
+	var selected []fec.SelectedPFDriver
+	for _, pf := range physicalFunctions {
+		if len(pf.PFDriverFallbacks) == 0 {
+			continue
+		}
+		if driver := selectPFDriver(ctx, pf.PFDrivers(), log); driver != pf.PFDriver {
+			log.WithField("pciAddress", pf.PCIAddress).WithField("requested", pf.PFDriver).WithField("selected", driver).
+				Info("requested PF driver is not available - using fallback")
+			selected = append(selected, fec.SelectedPFDriver{PCIAddress: pf.PCIAddress, Driver: driver})
+		}
+	}
+	return selected
+}
+
+// withSelectedPFDrivers returns a copy of physical functions bound to drivers selected on the node
+func withSelectedPFDrivers(physicalFunctions []fec.PhysicalFunctionConfigExt, selected []fec.SelectedPFDriver) []fec.PhysicalFunctionConfigExt {
+	if physicalFunctions == nil {
+		return nil
+	}
+	result := make([]fec.PhysicalFunctionConfigExt, len(physicalFunctions))
+	for i := range physicalFunctions {
+		physicalFunctions[i].DeepCopyInto(&result[i])
+		for _, s := range selected {
+			if s.PCIAddress == result[i].PCIAddress {
+				result[i].PFDriver = s.Driver
+			}
+		}
+	}
+	return result
+}
+
+// effectiveSpec returns a copy of the spec applied on the node, i.e. with PF drivers selected out of fallbacks
+func effectiveSpec(nc *fec.SriovFecNodeConfig) fec.SriovFecNodeConfigSpec {
+	spec := nc.Spec.DeepCopy()
+	spec.PhysicalFunctions = withSelectedPFDrivers(spec.PhysicalFunctions, nc.Status.SelectedPFDrivers)
+	return *spec
+}
+
+// vrbSelectPFDrivers returns drivers selected on the node instead of the requested ones for physical functions having
+// fallbacks; they are recorded in status.selectedPfDrivers, while the spec keeps the requested drivers
+func vrbSelectPFDrivers(ctx context.Context, physicalFunctions []vrbv1.PhysicalFunctionConfigExt, log *logrus.Logger) []vrbv1.SelectedPFDriver {
+	var selected []vrbv1.SelectedPFDriver
+	for _, pf := range physicalFunctions {
+		if len(pf.PFDriverFallbacks) == 0 {
+			continue
+		}
+		if driver := selectPFDriver(ctx, pf.PFDrivers(), log); driver != pf.PFDriver {
+			log.WithField("pciAddress", pf.PCIAddress).WithField("requested", pf.PFDriver).WithField("selected", driver).
+				Info("requested PF driver is not available - using fallback")
+			selected = append(selected, vrbv1.SelectedPFDriver{PCIAddress: pf.PCIAddress, Driver: driver})
+		}
+	}
+	return selected
+}
+
+// vrbWithSelectedPFDrivers returns a copy of physical functions bound to drivers selected on the node
+func vrbWithSelectedPFDrivers(physicalFunctions []vrbv1.PhysicalFunctionConfigExt, selected []vrbv1.SelectedPFDriver) []vrbv1.PhysicalFunctionConfigExt {
+	if physicalFunctions == nil {
+		return nil
+	}
+	result := make([]vrbv1.PhysicalFunctionConfigExt, len(physicalFunctions))
+	for i := range physicalFunctions {
+		physicalFunctions[i].DeepCopyInto(&result[i])
+		for _, s := range selected {
+			if s.PCIAddress == result[i].PCIAddress {
+				result[i].PFDriver = s.Driver
+			}
+		}
+	}
+	return result
+}
+
+// vrbEffectiveSpec returns a copy of the spec applied on the node, i.e. with PF drivers selected out of fallbacks
+func vrbEffectiveSpec(nc *vrbv1.SriovVrbNodeConfig) vrbv1.SriovVrbNodeConfigSpec {
+	spec := nc.Spec.DeepCopy()
+	spec.PhysicalFunctions = vrbWithSelectedPFDrivers(spec.PhysicalFunctions, nc.Status.SelectedPFDrivers)
+	return *spec
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var _ = Describe("selectPFDrivers()", func() {
+	var (
+		originalLockdownPath      string
+		originalIsDriverAvailable func(context.Context, string, *logrus.Logger) bool
+	)
+
+	BeforeEach(func() {
+		originalLockdownPath = sysLockdownFilePath
+		originalIsDriverAvailable = isDriverAvailable
+		sysLockdownFilePath = filepath.Join(testTmpFolder, "lockdown")
+		isDriverAvailable = func(_ context.Context, driver string, _ *logrus.Logger) bool {
+			return driver == utils.IGB_UIO || driver == utils.VFIO_PCI
+		}
+	})
+
+	AfterEach(func() {
+		sysLockdownFilePath = originalLockdownPath
+		isDriverAvailable = originalIsDriverAvailable
+		_ = os.Remove(filepath.Join(testTmpFolder, "lockdown"))
+	})
+
+	It("binds the first available fallback and reports it", func() {
+		pfs := []sriovv2.PhysicalFunctionConfigExt{
+			{PCIAddress: "0000:14:00.0", PFDriver: utils.PCI_PF_STUB_DASH, PFDriverFallbacks: []string{utils.IGB_UIO, utils.VFIO_PCI}},
+			{PCIAddress: "0000:15:00.0", PFDriver: utils.PCI_PF_STUB_DASH},
+			{PCIAddress: "0000:16:00.0", PFDriver: utils.VFIO_PCI, PFDriverFallbacks: []string{utils.IGB_UIO}},
+		}
+
+		selected := selectPFDrivers(context.TODO(), pfs, log)
+		Expect(selected).To(Equal([]sriovv2.SelectedPFDriver{{PCIAddress: "0000:14:00.0", Driver: utils.IGB_UIO}}))
+		// the spec keeps the requested driver, the selected one is applied to its copy
+		Expect(pfs[0].PFDriver).To(Equal(utils.PCI_PF_STUB_DASH))
+
+		nc := &sriovv2.SriovFecNodeConfig{Spec: sriovv2.SriovFecNodeConfigSpec{PhysicalFunctions: pfs}}
+		nc.Status.SelectedPFDrivers = selected
+		applied := effectiveSpec(nc).PhysicalFunctions
+		Expect(applied[0].PFDriver).To(Equal(utils.IGB_UIO))
+		Expect(applied[1].PFDriver).To(Equal(utils.PCI_PF_STUB_DASH))
+		Expect(applied[2].PFDriver).To(Equal(utils.VFIO_PCI))
+		Expect(nc.Spec.PhysicalFunctions[0].PFDriver).To(Equal(utils.PCI_PF_STUB_DASH))
+	})
+
+	It("skips drivers forbidden by kernel lockdown and keeps requested one if none can be bound", func() {
+		Expect(os.WriteFile(sysLockdownFilePath, []byte("none [integrity] confidentiality"), 0600)).To(Succeed())
+		pfs := []sriovv2.PhysicalFunctionConfigExt{
+			{PCIAddress: "0000:14:00.0", PFDriver: utils.PCI_PF_STUB_DASH, PFDriverFallbacks: []string{utils.IGB_UIO, utils.VFIO_PCI}},
+			{PCIAddress: "0000:15:00.0", PFDriver: utils.PCI_PF_STUB_DASH, PFDriverFallbacks: []string{utils.IGB_UIO}},
+		}
+
+		Expect(selectPFDrivers(context.TODO(), pfs, log)).To(Equal([]sriovv2.SelectedPFDriver{{PCIAddress: "0000:14:00.0", Driver: utils.VFIO_PCI}}))
+		Expect(pfs[1].PFDriver).To(Equal(utils.PCI_PF_STUB_DASH))
+	})
+})
@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+const (
+	powerControlFile  = "power/control"
+	d3coldAllowedFile = "d3cold_allowed"
+	aspmLinkDir       = "link"
+)
+
+// aspmLinkFiles control ASPM link power states of the device, only states supported by the link are exposed in sysfs
+var aspmLinkFiles = []string{"l0s_aspm", "l1_aspm", "l1_1_aspm", "l1_2_aspm", "l1_1_pcipm", "l1_2_pcipm"}
+
+// configurePowerManagement disables power states of the PF requested by its configuration
+func (n *NodeConfigurator) configurePowerManagement(pfPCIAddress string, disableRuntimePM, disableASPM, disableD3Cold bool) error {
+	if disableRuntimePM {
+		if err := writeFileWithTimeout(filepath.Join(sysBusPciDevices, pfPCIAddress, powerControlFile), "on"); err != nil {
+			return fmt.Errorf("failed to disable runtime power management of PF (%s): %w", pfPCIAddress, err)
+		}
+	}
+
+	if disableASPM {
+		linkDir := filepath.Join(sysBusPciDevices, pfPCIAddress, aspmLinkDir)
+		if _, err := os.Stat(linkDir); os.IsNotExist(err) {
+			return errclass.New(errclass.Platform, "ASPM link control of PF (%s) is not supported by the kernel", pfPCIAddress)
+		}
+		for _, file := range aspmLinkFiles {
+			path := filepath.Join(linkDir, file)
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				continue
+			}
+			if err := writeFileWithTimeout(path, "0"); err != nil {
+				return fmt.Errorf("failed to disable ASPM %s of PF (%s): %w", file, pfPCIAddress, err)
+			}
+		}
+	}
+
+	if disableD3Cold {
+		if err := writeFileWithTimeout(filepath.Join(sysBusPciDevices, pfPCIAddress, d3coldAllowedFile), "0"); err != nil {
+			return fmt.Errorf("failed to disable D3cold of PF (%s): %w", pfPCIAddress, err)
+		}
+	}
+
+	n.Log.WithField("pci", pfPCIAddress).WithField("disableRuntimePM", disableRuntimePM).WithField("disableASPM", disableASPM).
+		WithField("disableD3Cold", disableD3Cold).Info("power management configured")
+	return nil
+}
+
+// readPowerManagement reads back power management settings of the PF from sysfs
+func readPowerManagement(pfPCIAddress string) (runtimePM string, aspmDisabled, d3ColdAllowed bool) {
+	device := filepath.Join(sysBusPciDevices, pfPCIAddress)
+	if content, err := os.ReadFile(filepath.Join(device, powerControlFile)); err == nil {
+		runtimePM = strings.TrimSpace(string(content))
+	}
+	if content, err := os.ReadFile(filepath.Join(device, d3coldAllowedFile)); err == nil {
+		d3ColdAllowed = strings.TrimSpace(string(content)) == "1"
+	}
+
+	if _, err := os.Stat(filepath.Join(device, aspmLinkDir)); err == nil {
+		aspmDisabled = true
+		for _, file := range aspmLinkFiles {
+			content, err := os.ReadFile(filepath.Join(device, aspmLinkDir, file))
+			if err == nil && strings.TrimSpace(string(content)) != "0" {
+				aspmDisabled = false
+			}
+		}
+	}
+	return runtimePM, aspmDisabled, d3ColdAllowed
+}
+
+// appliedPowerManagement reports power management settings of PFs requesting it
+func appliedPowerManagement(physicalFunctions []fec.PhysicalFunctionConfigExt) []fec.AppliedPowerManagement {
+	var applied []fec.AppliedPowerManagement
+	for _, pf := range physicalFunctions {
+		if pf.PowerManagement == nil {
+			continue
+		}
+		runtimePM, aspmDisabled, d3ColdAllowed := readPowerManagement(pf.PCIAddress)
+		applied = append(applied, fec.AppliedPowerManagement{
+			PCIAddress:    pf.PCIAddress,
+			RuntimePM:     runtimePM,
+			ASPMDisabled:  aspmDisabled,
+			D3ColdAllowed: d3ColdAllowed,
+		})
+	}
+	return applied
+}
+
+// vrbAppliedPowerManagement reports power management settings of PFs requesting it
+func vrbAppliedPowerManagement(physicalFunctions []vrbv1.PhysicalFunctionConfigExt) []vrbv1.AppliedPowerManagement {
+	var applied []vrbv1.AppliedPowerManagement
+	for _, pf := range physicalFunctions {
+		if pf.PowerManagement == nil {
+			continue
+		}
+		runtimePM, aspmDisabled, d3ColdAllowed := readPowerManagement(pf.PCIAddress)
+		applied = append(applied, vrbv1.AppliedPowerManagement{
+			PCIAddress:    pf.PCIAddress,
+			RuntimePM:     runtimePM,
+			ASPMDisabled:  aspmDisabled,
+			D3ColdAllowed: d3ColdAllowed,
+		})
+	}
+	return applied
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"sync"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
+)
+
+// queueReconfigurableDevices are accelerators which pf_bb_config can reconfigure while their VFs exist
+var queueReconfigurableDevices = map[string]bool{"ACC100": true}
+
+// appliedLayouts keeps layouts of PFs configured by the daemon, keyed by PCI address. It's kept in memory only, so
+// PFs are fully reconfigured after restart of the daemon.
+type appliedLayouts struct {
+	sync.Mutex
+	layouts map[string]string
+}
+
+func (a *appliedLayouts) record(pciAddress, layout string) {
+	a.Lock()
+	defer a.Unlock()
+	if a.layouts == nil {
+		a.layouts = map[string]string{}
+	}
+	a.layouts[pciAddress] = layout
+}
+
+func (a *appliedLayouts) forget(pciAddress string) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.layouts, pciAddress)
+}
+
+func (a *appliedLayouts) get(pciAddress string) string {
+	a.Lock()
+	defer a.Unlock()
+	return a.layouts[pciAddress]
+}
+
+// vfLayout returns checksum of the PF configuration other than its queues, i.e. drivers, amount of VFs and their MSI-X
+// vectors and power management
+func vfLayout(pf sriovv2.PhysicalFunctionConfigExt) string {
+	pf.BBDevConfig = sriovv2.BBDevConfig{}
+	return configChecksum(pf)
+}
+
+// isQueueOnlyChange returns true if only queues of the accelerator are requested to change, so it can be reconfigured
+// without recreating its VFs: the layout applied by the daemon is requested again and VFs of the accelerator still
+// match it
+func (n *NodeConfigurator) isQueueOnlyChange(acc sriovv2.SriovAccelerator, requested sriovv2.PhysicalFunctionConfigExt) bool {
+	if !featuregates.Enabled(featuregates.QueueReconfiguration) || !queueReconfigurableDevices[supportedAccelerators.Devices[acc.DeviceID]] {
+		return false
+	}
+	if applied := n.layouts.get(acc.PCIAddress); applied == "" || applied != vfLayout(requested) {
+		return false
+	}
+	if acc.PFDriver != requested.PFDriver {
+		return false
+	}
+	var boundDrivers []string
+	for _, vf := range acc.VFs {
+		boundDrivers = append(boundDrivers, vf.Driver)
+	}
+	return vfDriversMatch(requested.VFAmount, requested.VFDriverFor, boundDrivers)
+}
+
+// reconfigureQueues runs pf_bb_config with requested queues against existing VFs, which are unbound from their drivers
+// meanwhile, so they are not used by workloads while the accelerator is reconfigured
+func (n *NodeConfigurator) reconfigureQueues(ctx context.Context, acc sriovv2.SriovAccelerator, requested *sriovv2.PhysicalFunctionConfigExt) error {
+	n.Log.WithField("pci", acc.PCIAddress).Info("only queues of PF changed - reconfiguring them without recreating VFs")
+
+	var vfs []string
+	clean := func() error {
+		return n.cleanAcceleratorConfig(ctx, acc)
+	}
+	return n.configureWithTimeout(ctx, acc.PCIAddress, clean,
+		func() (err error) {
+			if vfs, err = getVFList(acc.PCIAddress); err != nil {
+				n.Log.WithError(err).Error("failed to get list of VFs")
+			}
+			return err
+		},
+		func() error {
+			return unbindVFs(n, acc)
+		},
+		func() error {
+			return n.pfBBConfigController.stopPfBBConfig(ctx, acc.PCIAddress)
+		},
+		func() error {
+			return n.pfBBConfigController.initializePfBBConfig(ctx, acc, requested)
+		},
+		func() error {
+			for _, vf := range vfs {
+				if err := n.bindDeviceToDriver(vf, requested.VFDriverFor(n.vfIndex(vf))); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
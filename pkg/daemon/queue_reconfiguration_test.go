@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+
+	sriovv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/featuregates"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var _ = Describe("Queue reconfiguration", func() {
+	var (
+		configurator *NodeConfigurator
+		acc          sriovv2.SriovAccelerator
+		requested    sriovv2.PhysicalFunctionConfigExt
+		devices      map[string]string
+	)
+
+	BeforeEach(func() {
+		Expect(featuregates.Set(string(featuregates.QueueReconfiguration) + "=true")).To(Succeed())
+		devices = supportedAccelerators.Devices
+		supportedAccelerators.Devices = map[string]string{"0d5c": "ACC100", "57c0": "ACC200"}
+
+		configurator = &NodeConfigurator{Log: logrus.New()}
+		acc = sriovv2.SriovAccelerator{PCIAddress: "0000:14:00.0", DeviceID: "0d5c", PFDriver: utils.PCI_PF_STUB_DASH,
+			VFs: []sriovv2.VF{{PCIAddress: "0000:14:00.1", Driver: utils.VFIO_PCI}, {PCIAddress: "0000:14:00.2", Driver: utils.VFIO_PCI}}}
+		requested = sriovv2.PhysicalFunctionConfigExt{PCIAddress: "0000:14:00.0", PFDriver: utils.PCI_PF_STUB_DASH,
+			VFDriver: utils.VFIO_PCI, VFAmount: 2, BBDevConfig: sriovv2.BBDevConfig{ACC100: &sriovv2.ACC100BBDevConfig{NumVfBundles: 2}}}
+		configurator.layouts.record(acc.PCIAddress, vfLayout(requested))
+	})
+
+	AfterEach(func() {
+		Expect(featuregates.Set("")).To(Succeed())
+		supportedAccelerators.Devices = devices
+	})
+
+	It("ignores queues in layout of PF", func() {
+		changed := requested
+		changed.BBDevConfig = sriovv2.BBDevConfig{ACC100: &sriovv2.ACC100BBDevConfig{NumVfBundles: 1}}
+		Expect(vfLayout(changed)).To(Equal(vfLayout(requested)))
+
+		changed.VFAmount = 1
+		Expect(vfLayout(changed)).ToNot(Equal(vfLayout(requested)))
+	})
+
+	It("reconfigures only queues when layout applied by the daemon is requested again", func() {
+		Expect(configurator.isQueueOnlyChange(acc, requested)).To(BeTrue())
+
+		changed := requested
+		changed.VFAmount = 1
+		Expect(configurator.isQueueOnlyChange(acc, changed)).To(BeFalse())
+
+		configurator.layouts.forget(acc.PCIAddress)
+		Expect(configurator.isQueueOnlyChange(acc, requested)).To(BeFalse())
+	})
+
+	It("recreates VFs which don't match the applied layout anymore", func() {
+		acc.VFs[1].Driver = utils.IGB_UIO
+		Expect(configurator.isQueueOnlyChange(acc, requested)).To(BeFalse())
+	})
+
+	It("recreates VFs of accelerators other than ACC100 or when the gate is disabled", func() {
+		acc.DeviceID = "57c0"
+		configurator.layouts.record(acc.PCIAddress, vfLayout(requested))
+		Expect(configurator.isQueueOnlyChange(acc, requested)).To(BeFalse())
+
+		acc.DeviceID = "0d5c"
+		Expect(featuregates.Set(string(featuregates.QueueReconfiguration) + "=false")).To(Succeed())
+		Expect(configurator.isQueueOnlyChange(acc, requested)).To(BeFalse())
+	})
+})
@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+const (
+	ConditionPostRebootVerification = "PostRebootVerification"
+	PostRebootVerificationPassed    = "Passed"
+	PostRebootVerificationFailed    = "Failed"
+)
+
+var bootIDFilePath = "/proc/sys/kernel/random/boot_id"
+
+// verifiedPF is a requested physical function configuration verified after reboot of the node
+type verifiedPF struct {
+	pciAddress  string
+	pfDriver    string
+	vfAmount    int
+	vfDriverFor func(index int) string
+}
+
+// detectedPF is a physical function found on the node
+type detectedPF struct {
+	pfDriver  string
+	vfDrivers []string
+}
+
+// readBootID returns ID of the current boot of the node, it changes on every reboot
+func readBootID() (string, error) {
+	content, err := os.ReadFile(bootIDFilePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// postRebootVerification returns condition reporting whether requested configuration survived reboot of the node:
+// drivers are bound, VFs are present, pf_bb_config is running and its version didn't change
+func postRebootVerification(ctx context.Context, log *logrus.Logger, requested []verifiedPF, detected map[string]detectedPF,
+	pfBbConfVersionBefore, pfBbConfVersion string) metav1.Condition {
+
+	var failures []string
+	for _, pf := range requested {
+		acc, ok := detected[pf.pciAddress]
+		switch {
+		case !ok:
+			failures = append(failures, fmt.Sprintf("PF %s is missing", pf.pciAddress))
+			continue
+		case !strings.EqualFold(acc.pfDriver, pf.pfDriver):
+			failures = append(failures, fmt.Sprintf("PF %s is bound to '%s' instead of '%s'", pf.pciAddress, acc.pfDriver, pf.pfDriver))
+		case len(acc.vfDrivers) != pf.vfAmount:
+			failures = append(failures, fmt.Sprintf("PF %s exposes %d VFs instead of %d", pf.pciAddress, len(acc.vfDrivers), pf.vfAmount))
+		case !vfDriversMatch(pf.vfAmount, pf.vfDriverFor, acc.vfDrivers):
+			failures = append(failures, fmt.Sprintf("VFs of PF %s are not bound to requested drivers", pf.pciAddress))
+		}
+		if strings.EqualFold(pf.pfDriver, utils.VFIO_PCI) && pfBbConfigProcIsDead(ctx, log, pf.pciAddress) {
+			failures = append(failures, fmt.Sprintf("pf_bb_config is not running for PF %s", pf.pciAddress))
+		}
+	}
+
+	isKnown := func(version string) bool { return version != "" && version != "null" }
+	if isKnown(pfBbConfVersionBefore) && isKnown(pfBbConfVersion) && pfBbConfVersionBefore != pfBbConfVersion {
+		failures = append(failures, fmt.Sprintf("pf_bb_config version changed from %s to %s", pfBbConfVersionBefore, pfBbConfVersion))
+	}
+
+	if len(failures) != 0 {
+		return metav1.Condition{
+			Type:    ConditionPostRebootVerification,
+			Status:  metav1.ConditionFalse,
+			Reason:  PostRebootVerificationFailed,
+			Message: strings.Join(failures, "; "),
+		}
+	}
+	return metav1.Condition{
+		Type:    ConditionPostRebootVerification,
+		Status:  metav1.ConditionTrue,
+		Reason:  PostRebootVerificationPassed,
+		Message: fmt.Sprintf("Configuration of %d PF(s) verified after reboot", len(requested)),
+	}
+}
+
+// setPostRebootVerification verifies configuration of the node if it was rebooted since the last verification.
+// Boot ID is only recorded when it is unknown (e.g. the daemon runs on the node for the first time).
+// Returns true if status of the NodeConfig was changed.
+func (r *FecNodeConfigReconciler) setPostRebootVerification(ctx context.Context, nc *fec.SriovFecNodeConfig) bool {
+	bootID, err := readBootID()
+	if err != nil {
+		r.log.WithError(err).Error("failed to read boot ID of the node")
+		return false
+	}
+	if bootID == nc.Status.BootID {
+		return false
+	}
+	if nc.Status.BootID != "" {
+		var requested []verifiedPF
+		physicalFunctions := withSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers)
+		for i := range physicalFunctions {
+			pf := &physicalFunctions[i]
+			requested = append(requested, verifiedPF{pf.PCIAddress, pf.PFDriver, pf.VFAmount, pf.VFDriverFor})
+		}
+		detected := map[string]detectedPF{}
+		for _, acc := range nc.Status.Inventory.SriovAccelerators {
+			var vfDrivers []string
+			for _, vf := range acc.VFs {
+				vfDrivers = append(vfDrivers, vf.Driver)
+			}
+			detected[acc.PCIAddress] = detectedPF{acc.PFDriver, vfDrivers}
+		}
+
+		pfBbConfVersion := r.getPfBbConfVersion(ctx)
+		condition := postRebootVerification(ctx, r.log, requested, detected, nc.Status.PfBbConfVersion, pfBbConfVersion)
+		condition.ObservedGeneration = nc.GetGeneration()
+		meta.SetStatusCondition(&nc.Status.Conditions, condition)
+		if pfBbConfVersion != "null" {
+			nc.Status.PfBbConfVersion = pfBbConfVersion
+		}
+		r.log.WithField("condition", condition).Info("node reboot detected - configuration verified")
+	}
+	nc.Status.BootID = bootID
+	return true
+}
+
+// setPostRebootVerification verifies configuration of the node if it was rebooted since the last verification.
+// Boot ID is only recorded when it is unknown (e.g. the daemon runs on the node for the first time).
+// Returns true if status of the NodeConfig was changed.
+func (r *VrbNodeConfigReconciler) setPostRebootVerification(ctx context.Context, nc *vrbv1.SriovVrbNodeConfig) bool {
+	bootID, err := readBootID()
+	if err != nil {
+		r.log.WithError(err).Error("failed to read boot ID of the node")
+		return false
+	}
+	if bootID == nc.Status.BootID {
+		return false
+	}
+	if nc.Status.BootID != "" {
+		var requested []verifiedPF
+		physicalFunctions := vrbWithSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers)
+		for i := range physicalFunctions {
+			pf := &physicalFunctions[i]
+			requested = append(requested, verifiedPF{pf.PCIAddress, pf.PFDriver, pf.VFAmount, pf.VFDriverFor})
+		}
+		detected := map[string]detectedPF{}
+		for _, acc := range nc.Status.Inventory.SriovAccelerators {
+			var vfDrivers []string
+			for _, vf := range acc.VFs {
+				vfDrivers = append(vfDrivers, vf.Driver)
+			}
+			detected[acc.PCIAddress] = detectedPF{acc.PFDriver, vfDrivers}
+		}
+
+		pfBbConfVersion := r.getVrbPfBbConfVersion(ctx)
+		condition := postRebootVerification(ctx, r.log, requested, detected, nc.Status.PfBbConfVersion, pfBbConfVersion)
+		condition.ObservedGeneration = nc.GetGeneration()
+		meta.SetStatusCondition(&nc.Status.Conditions, condition)
+		if pfBbConfVersion != "null" {
+			nc.Status.PfBbConfVersion = pfBbConfVersion
+		}
+		r.log.WithField("condition", condition).Info("node reboot detected - configuration verified")
+	}
+	nc.Status.BootID = bootID
+	return true
+}
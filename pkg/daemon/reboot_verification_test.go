@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("postRebootVerification", func() {
+	log := logrus.New()
+	vfDriver := func(int) string { return "vfio-pci" }
+	requested := []verifiedPF{{pciAddress: "0000:14:00.1", pfDriver: "pci-pf-stub", vfAmount: 2, vfDriverFor: vfDriver}}
+
+	It("passes when configuration survived reboot", func() {
+		detected := map[string]detectedPF{"0000:14:00.1": {pfDriver: "pci-pf-stub", vfDrivers: []string{"vfio-pci", "vfio-pci"}}}
+		condition := postRebootVerification(context.TODO(), log, requested, detected, "v1", "v1")
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(PostRebootVerificationPassed))
+	})
+
+	It("reports all failures", func() {
+		detected := map[string]detectedPF{"0000:14:00.1": {pfDriver: "pci-pf-stub", vfDrivers: []string{"vfio-pci"}}}
+		condition := postRebootVerification(context.TODO(), log, requested, detected, "v1", "v2")
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(PostRebootVerificationFailed))
+		Expect(condition.Message).To(Equal("PF 0000:14:00.1 exposes 1 VFs instead of 2; pf_bb_config version changed from v1 to v2"))
+	})
+
+	It("reports missing PF", func() {
+		condition := postRebootVerification(context.TODO(), log, requested, map[string]detectedPF{}, "", "null")
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(Equal("PF 0000:14:00.1 is missing"))
+	})
+})
+
+var _ = Describe("FecNodeConfigReconciler.setPostRebootVerification()", func() {
+	var originBootIDFilePath string
+
+	BeforeEach(func() {
+		originBootIDFilePath = bootIDFilePath
+		bootIDFilePath = filepath.Join(testTmpFolder, "boot_id")
+		Expect(os.WriteFile(bootIDFilePath, []byte("boot-2\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		bootIDFilePath = originBootIDFilePath
+	})
+
+	It("only records boot ID when it was unknown", func() {
+		nc := new(fec.SriovFecNodeConfig)
+		r := &FecNodeConfigReconciler{log: logrus.New()}
+		Expect(r.setPostRebootVerification(context.TODO(), nc)).To(BeTrue())
+		Expect(nc.Status.BootID).To(Equal("boot-2"))
+		Expect(nc.Status.Conditions).To(BeEmpty())
+		Expect(r.setPostRebootVerification(context.TODO(), nc)).To(BeFalse())
+	})
+
+	It("verifies configuration when boot ID changed", func() {
+		nc := &fec.SriovFecNodeConfig{
+			Spec:   fec.SriovFecNodeConfigSpec{PhysicalFunctions: []fec.PhysicalFunctionConfigExt{{PCIAddress: "0000:14:00.1", PFDriver: "pci-pf-stub"}}},
+			Status: fec.SriovFecNodeConfigStatus{BootID: "boot-1"},
+		}
+		r := &FecNodeConfigReconciler{log: logrus.New()}
+		Expect(r.setPostRebootVerification(context.TODO(), nc)).To(BeTrue())
+		Expect(nc.Status.BootID).To(Equal("boot-2"))
+		condition := meta.FindStatusCondition(nc.Status.Conditions, ConditionPostRebootVerification)
+		Expect(condition).ToNot(BeNil())
+		Expect(condition.Reason).To(Equal(PostRebootVerificationFailed))
+		Expect(condition.Message).To(Equal("PF 0000:14:00.1 is missing"))
+	})
+})
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+// SkipDevicesAnnotation set on the Node lists comma separated PCI addresses of accelerators which are excluded
+// from management, e.g. card reserved for vendor diagnostic. Such accelerators are neither exposed in inventory
+// nor configured by the daemon.
+const SkipDevicesAnnotation = "sriovfec.intel.com/skip-devices"
+
+// readSkippedDevices returns PCI addresses listed in SkipDevicesAnnotation of the node
+func readSkippedDevices(ctx context.Context, c client.Reader, nodeName string) (map[string]bool, error) {
+	node := new(corev1.Node)
+	if err := c.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return parseSkippedDevices(node.GetAnnotations()[SkipDevicesAnnotation]), nil
+}
+
+func parseSkippedDevices(annotation string) map[string]bool {
+	skipped := map[string]bool{}
+	for _, pciAddress := range strings.Split(annotation, ",") {
+		if pciAddress = strings.ToLower(strings.TrimSpace(pciAddress)); pciAddress != "" {
+			skipped[pciAddress] = true
+		}
+	}
+	return skipped
+}
+
+// withoutSkippedAccelerators removes skipped accelerators from the inventory and returns them
+func withoutSkippedAccelerators(inv *fec.NodeInventory, skipped map[string]bool) []fec.SkippedAccelerator {
+	var skippedAccelerators []fec.SkippedAccelerator
+	managed := inv.SriovAccelerators[:0]
+	for _, acc := range inv.SriovAccelerators {
+		if skipped[strings.ToLower(acc.PCIAddress)] {
+			skippedAccelerators = append(skippedAccelerators, fec.SkippedAccelerator{PCIAddress: acc.PCIAddress, Reason: fec.SkippedByAnnotation})
+			continue
+		}
+		managed = append(managed, acc)
+	}
+	inv.SriovAccelerators = managed
+	return skippedAccelerators
+}
+
+// vrbWithoutSkippedAccelerators removes skipped accelerators from the inventory and returns them
+func vrbWithoutSkippedAccelerators(inv *vrbv1.NodeInventory, skipped map[string]bool) []vrbv1.SkippedAccelerator {
+	var skippedAccelerators []vrbv1.SkippedAccelerator
+	managed := inv.SriovAccelerators[:0]
+	for _, acc := range inv.SriovAccelerators {
+		if skipped[strings.ToLower(acc.PCIAddress)] {
+			skippedAccelerators = append(skippedAccelerators, vrbv1.SkippedAccelerator{PCIAddress: acc.PCIAddress, Reason: vrbv1.SkippedByAnnotation})
+			continue
+		}
+		managed = append(managed, acc)
+	}
+	inv.SriovAccelerators = managed
+	return skippedAccelerators
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("Skipped devices", func() {
+	It("parses PCI addresses listed in annotation", func() {
+		Expect(parseSkippedDevices(" 0000:14:00.0,0000:AB:00.0 ,,")).To(Equal(map[string]bool{
+			"0000:14:00.0": true,
+			"0000:ab:00.0": true,
+		}))
+		Expect(parseSkippedDevices("")).To(BeEmpty())
+	})
+
+	It("reads skipped devices from node's annotation", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        "node1",
+			Annotations: map[string]string{SkipDevicesAnnotation: "0000:14:00.0"},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(node).Build()
+
+		skipped, err := readSkippedDevices(context.TODO(), c, "node1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(skipped).To(HaveKey("0000:14:00.0"))
+
+		skipped, err = readSkippedDevices(context.TODO(), c, "missing-node")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(skipped).To(BeEmpty())
+	})
+
+	It("removes skipped accelerators from inventory", func() {
+		inv := &fec.NodeInventory{SriovAccelerators: []fec.SriovAccelerator{
+			{PCIAddress: "0000:14:00.0"},
+			{PCIAddress: "0000:ab:00.0"},
+		}}
+
+		skipped := withoutSkippedAccelerators(inv, parseSkippedDevices("0000:AB:00.0"))
+		Expect(skipped).To(Equal([]fec.SkippedAccelerator{{PCIAddress: "0000:ab:00.0", Reason: fec.SkippedByAnnotation}}))
+		Expect(inv.SriovAccelerators).To(HaveLen(1))
+		Expect(inv.SriovAccelerators[0].PCIAddress).To(Equal("0000:14:00.0"))
+	})
+})
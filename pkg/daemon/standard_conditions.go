@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+// setStandardConditions derives Ready, Progressing, Degraded and Ignored conditions of NodeConfig from its Configured
+// condition, which is kept for compatibility. errorClass is the class of failed configuration.
+func setStandardConditions(conditions *[]metav1.Condition, configured metav1.Condition, generation int64, errorClass string) {
+	set := func(conditionType string, status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(conditions, metav1.Condition{Type: conditionType, Status: status,
+			ObservedGeneration: generation, Reason: reason, Message: message})
+	}
+
+	ready, progressing := metav1.ConditionFalse, metav1.ConditionFalse
+	degraded, degradedReason, degradedMessage := metav1.ConditionFalse, "AsExpected", ""
+	ignored, ignoredReason, ignoredMessage := metav1.ConditionFalse, "Managed", ""
+
+	switch ConfigurationConditionReason(configured.Reason) {
+	case ConfigurationSucceeded, ConfigurationNotRequested:
+		ready = metav1.ConditionTrue
+	case ConfigurationInProgress:
+		progressing = metav1.ConditionTrue
+	case ConfigurationFailed, ConfigurationRolledBack, ConfigurationTimeout:
+		degraded, degradedReason, degradedMessage = metav1.ConditionTrue, errorClass, configured.Message
+		if degradedReason == "" {
+			degradedReason = configured.Reason
+		}
+	case ConfigurationOrphaned:
+		ignored, ignoredReason, ignoredMessage = metav1.ConditionTrue, configured.Reason, configured.Message
+	}
+
+	set(fec.ReadyCondition, ready, configured.Reason, configured.Message)
+	set(fec.ProgressingCondition, progressing, configured.Reason, "")
+	set(fec.DegradedCondition, degraded, degradedReason, degradedMessage)
+	set(fec.IgnoredCondition, ignored, ignoredReason, ignoredMessage)
+}
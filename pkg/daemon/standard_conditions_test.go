@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/errclass"
+)
+
+var _ = Describe("setStandardConditions", func() {
+	configured := func(reason ConfigurationConditionReason, message string) metav1.Condition {
+		return metav1.Condition{Type: ConditionConfigured, Status: metav1.ConditionFalse, Reason: string(reason), Message: message}
+	}
+
+	It("reports succeeded configuration as ready", func() {
+		var conditions []metav1.Condition
+		setStandardConditions(&conditions, configured(ConfigurationSucceeded, "Configured successfully"), 2, "")
+
+		Expect(meta.IsStatusConditionTrue(conditions, fec.ReadyCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(conditions, fec.ProgressingCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(conditions, fec.DegradedCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(conditions, fec.IgnoredCondition)).To(BeTrue())
+		Expect(meta.FindStatusCondition(conditions, fec.ReadyCondition).ObservedGeneration).To(BeEquivalentTo(2))
+	})
+
+	It("reports configuration in progress", func() {
+		var conditions []metav1.Condition
+		setStandardConditions(&conditions, configured(ConfigurationInProgress, ""), 1, "")
+
+		Expect(meta.IsStatusConditionFalse(conditions, fec.ReadyCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(conditions, fec.ProgressingCondition)).To(BeTrue())
+	})
+
+	It("reports failed configuration as degraded with class of the error", func() {
+		var conditions []metav1.Condition
+		setStandardConditions(&conditions, configured(ConfigurationFailed, "pf_bb_config failed"), 1, string(errclass.Device))
+
+		Expect(meta.IsStatusConditionFalse(conditions, fec.ReadyCondition)).To(BeTrue())
+		degraded := meta.FindStatusCondition(conditions, fec.DegradedCondition)
+		Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(string(errclass.Device)))
+		Expect(degraded.Message).To(Equal("pf_bb_config failed"))
+	})
+
+	It("reports orphaned NodeConfig as ignored", func() {
+		var conditions []metav1.Condition
+		setStandardConditions(&conditions, configured(ConfigurationOrphaned, configurationOrphanedMessage), 1, "")
+
+		Expect(meta.IsStatusConditionFalse(conditions, fec.ReadyCondition)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(conditions, fec.IgnoredCondition)).To(BeTrue())
+	})
+})
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var taintUnconfiguredNodesEnv = utils.SRIOV_PREFIX + "TAINT_UNCONFIGURED_NODES"
+
+var unconfiguredTaint = corev1.Taint{Key: utils.UNCONFIGURED_TAINT_KEY, Effect: corev1.TaintEffectNoSchedule}
+
+// startupTaintTimeout bounds time the node is kept tainted after startup of the daemon, so nodes which never get
+// configured (e.g. no ClusterConfig selects them or their configuration keeps failing) don't stay tainted forever
+var startupTaintTimeout = 30 * time.Minute
+
+// startupTaintReconciler removes the startup taint from the node as soon as its NodeConfigs report successful
+// configuration, so RAN workloads don't land on the node before FEC VFs exist
+type startupTaintReconciler struct {
+	client.Client
+	log         *logrus.Logger
+	nodeNameRef types.NamespacedName
+	// taintedAt is the time the node was tainted on startup of the daemon
+	taintedAt time.Time
+}
+
+// SetupStartupTaint taints the node which accelerators are not configured yet and sets up removal of the taint,
+// if enabled with SRIOV_FEC_TAINT_UNCONFIGURED_NODES. Taint is only added on daemon startup.
+func SetupStartupTaint(ctx context.Context, mgr ctrl.Manager, directClient client.Client, nodeNameRef types.NamespacedName, log *logrus.Logger) error {
+	if !strings.EqualFold(os.Getenv(taintUnconfiguredNodesEnv), "true") {
+		return nil
+	}
+
+	r := &startupTaintReconciler{Client: directClient, log: log, nodeNameRef: nodeNameRef, taintedAt: time.Now()}
+	configured, err := r.isNodeConfigured(ctx)
+	if err != nil {
+		return err
+	}
+	if !configured {
+		if err := r.setTaint(ctx, true); err != nil {
+			return err
+		}
+	}
+
+	r.Client = mgr.GetClient()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("startup-taint").
+		For(&fec.SriovFecNodeConfig{}, builder.WithPredicates(resourceNamePredicate{requiredName: nodeNameRef.Name, log: log})).
+		Watches(&source.Kind{Type: &vrbv1.SriovVrbNodeConfig{}}, &handler.EnqueueRequestForObject{},
+			builder.WithPredicates(resourceNamePredicate{requiredName: nodeNameRef.Name, log: log})).
+		Complete(r)
+}
+
+// Reconcile removes the taint once the node is configured or startupTaintTimeout elapses, whichever comes first
+func (r *startupTaintReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	if remaining := startupTaintTimeout - time.Since(r.taintedAt); remaining <= 0 {
+		r.log.WithField("timeout", startupTaintTimeout).Info("node is not configured within the startup taint timeout - removing the taint")
+	} else if configured, err := r.isNodeConfigured(ctx); err != nil {
+		return reconcile.Result{}, err
+	} else if !configured {
+		return reconcile.Result{RequeueAfter: remaining}, nil
+	}
+	return reconcile.Result{}, r.setTaint(ctx, false)
+}
+
+// isNodeConfigured returns true if all NodeConfigs of the node report successful configuration of their generation or
+// don't request configuration of any PF
+func (r *startupTaintReconciler) isNodeConfigured(ctx context.Context) (bool, error) {
+	type nodeConfigState struct {
+		conditions        []metav1.Condition
+		generation        int64
+		physicalFunctions int
+	}
+	var states []nodeConfigState
+
+	fecNodeConfig := new(fec.SriovFecNodeConfig)
+	if err := r.Get(ctx, r.nodeNameRef, fecNodeConfig); err == nil {
+		states = append(states, nodeConfigState{fecNodeConfig.Status.Conditions, fecNodeConfig.Generation, len(fecNodeConfig.Spec.PhysicalFunctions)})
+	} else if !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	vrbNodeConfig := new(vrbv1.SriovVrbNodeConfig)
+	if err := r.Get(ctx, r.nodeNameRef, vrbNodeConfig); err == nil {
+		states = append(states, nodeConfigState{vrbNodeConfig.Status.Conditions, vrbNodeConfig.Generation, len(vrbNodeConfig.Spec.PhysicalFunctions)})
+	} else if !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+
+	for _, state := range states {
+		if state.physicalFunctions == 0 {
+			continue
+		}
+		condition := meta.FindStatusCondition(state.conditions, ConditionConfigured)
+		if condition == nil || condition.Reason != string(ConfigurationSucceeded) || condition.ObservedGeneration != state.generation {
+			return false, nil
+		}
+	}
+	return len(states) != 0, nil
+}
+
+// setTaint adds or removes the startup taint of the node
+func (r *startupTaintReconciler) setTaint(ctx context.Context, tainted bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node := new(corev1.Node)
+		if err := r.Get(ctx, client.ObjectKey{Name: r.nodeNameRef.Name}, node); err != nil {
+			return err
+		}
+
+		var taints []corev1.Taint
+		for _, taint := range node.Spec.Taints {
+			if !taint.MatchTaint(&unconfiguredTaint) {
+				taints = append(taints, taint)
+			}
+		}
+		if tainted {
+			taints = append(taints, unconfiguredTaint)
+		}
+		if len(taints) == len(node.Spec.Taints) {
+			return nil
+		}
+
+		node.Spec.Taints = taints
+		if err := r.Update(ctx, node); err != nil {
+			return err
+		}
+		r.log.WithField("node", node.Name).WithField("tainted", tainted).Info("startup taint of the node updated")
+		return nil
+	})
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+var _ = Describe("startupTaintReconciler", func() {
+	var (
+		nodeNameRef = types.NamespacedName{Name: "node", Namespace: "ns"}
+		nodeConfig  *fec.SriovFecNodeConfig
+		reconciler  *startupTaintReconciler
+	)
+
+	taints := func() []corev1.Taint {
+		node := new(corev1.Node)
+		Expect(reconciler.Get(context.TODO(), client.ObjectKey{Name: nodeNameRef.Name}, node)).To(Succeed())
+		return node.Spec.Taints
+	}
+
+	BeforeEach(func() {
+		Expect(fec.AddToScheme(scheme.Scheme)).To(Succeed())
+		Expect(vrbv1.AddToScheme(scheme.Scheme)).To(Succeed())
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeNameRef.Name},
+			Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoExecute}}},
+		}
+		nodeConfig = &fec.SriovFecNodeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeNameRef.Name, Namespace: nodeNameRef.Namespace, Generation: 2},
+			Spec:       fec.SriovFecNodeConfigSpec{PhysicalFunctions: []fec.PhysicalFunctionConfigExt{{PCIAddress: "0000:14:00.0"}}},
+			Status: fec.SriovFecNodeConfigStatus{Conditions: []metav1.Condition{{
+				Type: ConditionConfigured, Reason: string(ConfigurationNotRequested), ObservedGeneration: 1,
+			}}},
+		}
+		reconciler = &startupTaintReconciler{
+			Client:      fake.NewClientBuilder().WithObjects(node, nodeConfig).Build(),
+			log:         logrus.New(),
+			nodeNameRef: nodeNameRef,
+			taintedAt:   time.Now(),
+		}
+		Expect(reconciler.setTaint(context.TODO(), true)).To(Succeed())
+	})
+
+	reconcileTaint := func() {
+		_, err := reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nodeNameRef})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("keeps the taint until NodeConfig reports successful configuration", func() {
+		configured, err := reconciler.isNodeConfigured(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configured).To(BeFalse())
+		Expect(reconciler.setTaint(context.TODO(), true)).To(Succeed())
+		Expect(taints()).To(HaveLen(2))
+		Expect(taints()).To(ContainElement(unconfiguredTaint))
+
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nodeNameRef})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taints()).To(ContainElement(unconfiguredTaint))
+
+		Expect(reconciler.Get(context.TODO(), nodeNameRef, nodeConfig)).To(Succeed())
+		nodeConfig.Status.Conditions[0].Reason = string(ConfigurationSucceeded)
+		nodeConfig.Status.Conditions[0].ObservedGeneration = nodeConfig.Generation
+		Expect(reconciler.Status().Update(context.TODO(), nodeConfig)).To(Succeed())
+
+		_, err = reconciler.Reconcile(context.TODO(), reconcile.Request{NamespacedName: nodeNameRef})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(taints()).To(Equal([]corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoExecute}}))
+	})
+
+	It("removes the taint when NodeConfig doesn't request configuration of any PF", func() {
+		Expect(reconciler.Get(context.TODO(), nodeNameRef, nodeConfig)).To(Succeed())
+		nodeConfig.Spec.PhysicalFunctions = nil
+		Expect(reconciler.Update(context.TODO(), nodeConfig)).To(Succeed())
+
+		reconcileTaint()
+		Expect(taints()).ToNot(ContainElement(unconfiguredTaint))
+	})
+
+	It("removes the taint when the node isn't configured within the timeout", func() {
+		reconcileTaint()
+		Expect(taints()).To(ContainElement(unconfiguredTaint))
+
+		reconciler.taintedAt = time.Now().Add(-startupTaintTimeout)
+		reconcileTaint()
+		Expect(taints()).ToNot(ContainElement(unconfiguredTaint))
+	})
+})
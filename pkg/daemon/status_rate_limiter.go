@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// statusUpdateInterval is the minimal interval between status updates of the same object; 0 disables rate limiting
+var statusUpdateInterval = 2 * time.Second
+
+// rateLimitedStatusClient is a client which status updates of an object are written at most once per
+// statusUpdateInterval, so a flapping daemon doesn't hammer the API server with status writes
+type rateLimitedStatusClient struct {
+	client.Client
+	writer *rateLimitedStatusWriter
+}
+
+func newRateLimitedStatusClient(c client.Client, log *logrus.Logger) client.Client {
+	return &rateLimitedStatusClient{
+		Client: c,
+		writer: &rateLimitedStatusWriter{client: c, log: log, objects: map[string]*statusWrites{}},
+	}
+}
+
+func (c *rateLimitedStatusClient) Status() client.StatusWriter {
+	return c.writer
+}
+
+// statusWrites tracks status writes of a single object
+type statusWrites struct {
+	lastWrite time.Time
+	// pending is the latest status update requested within the interval, written when the interval elapses
+	pending client.Object
+	// pendingCtx is the context of the pending update's request, the write outlives the request so it's not cancelled
+	// along with it
+	pendingCtx context.Context
+}
+
+// rateLimitedStatusWriter writes the first status update of an object immediately. Updates requested within
+// statusUpdateInterval after a write are coalesced - only the latest one is written when the interval elapses.
+type rateLimitedStatusWriter struct {
+	client  client.Client
+	log     *logrus.Logger
+	mu      sync.Mutex
+	objects map[string]*statusWrites
+}
+
+func (w *rateLimitedStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if statusUpdateInterval <= 0 {
+		return w.client.Status().Update(ctx, obj, opts...)
+	}
+
+	key := fmt.Sprintf("%T/%s", obj, client.ObjectKeyFromObject(obj))
+	now := time.Now()
+
+	w.mu.Lock()
+	writes, ok := w.objects[key]
+	if !ok {
+		writes = &statusWrites{}
+		w.objects[key] = writes
+	}
+	if writes.pending == nil && now.Sub(writes.lastWrite) >= statusUpdateInterval {
+		writes.lastWrite = now
+		w.mu.Unlock()
+		return w.client.Status().Update(ctx, obj, opts...)
+	}
+
+	if writes.pending == nil {
+		time.AfterFunc(writes.lastWrite.Add(statusUpdateInterval).Sub(now), func() { w.flush(key) })
+	}
+	writes.pending = obj.DeepCopyObject().(client.Object)
+	writes.pendingCtx = context.WithoutCancel(ctx)
+	w.mu.Unlock()
+
+	w.log.WithField("object", key).Debug("status update coalesced")
+	return nil
+}
+
+func (w *rateLimitedStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return w.client.Status().Patch(ctx, obj, patch, opts...)
+}
+
+// flush writes the pending status update of the object. The pending status is patched onto the latest version of the
+// object, because the object could have been updated since the status update was requested, while fields cleared
+// in the pending status have to be cleared in the written status too.
+func (w *rateLimitedStatusWriter) flush(key string) {
+	w.mu.Lock()
+	writes := w.objects[key]
+	pending, ctx := writes.pending, writes.pendingCtx
+	writes.pending = nil
+	writes.lastWrite = time.Now()
+	w.mu.Unlock()
+
+	if err := w.writePending(ctx, pending); client.IgnoreNotFound(err) != nil {
+		w.log.WithError(err).WithField("object", key).Error("failed to write coalesced status update - retrying")
+		w.mu.Lock()
+		if writes.pending == nil {
+			writes.pending, writes.pendingCtx = pending, ctx
+			time.AfterFunc(statusUpdateInterval, func() { w.flush(key) })
+		}
+		w.mu.Unlock()
+	}
+}
+
+// patchedStatusFields are status fields patched by other writers meanwhile - the operation journal, progress of the
+// drain and telemetry. The pending status is stale for them, so they're kept as they are in the latest version.
+var patchedStatusFields = []string{"operations", "drain", "utilization"}
+
+// writePending patches status of the latest version of the object with the pending status; the patch ignores the rest
+// of the pending object and it's rejected if the object is updated between the read and the write
+func (w *rateLimitedStatusWriter) writePending(ctx context.Context, pending client.Object) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := pending.DeepCopyObject().(client.Object)
+		if err := w.client.Get(ctx, client.ObjectKeyFromObject(pending), latest); err != nil {
+			return err
+		}
+		updated, err := withPendingStatus(latest, pending)
+		if err != nil {
+			return err
+		}
+		return w.client.Status().Patch(ctx, updated, client.MergeFromWithOptions(latest, client.MergeFromWithOptimisticLock{}))
+	})
+}
+
+// withPendingStatus returns the latest version of the object with status of the pending one, except patchedStatusFields
+func withPendingStatus(latest, pending client.Object) (client.Object, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(latest)
+	if err != nil {
+		return nil, err
+	}
+	pendingContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pending)
+	if err != nil {
+		return nil, err
+	}
+
+	status, _, err := unstructured.NestedMap(pendingContent, "status")
+	if err != nil {
+		return nil, err
+	}
+	if status == nil {
+		status = map[string]interface{}{}
+	}
+	latestStatus, _, err := unstructured.NestedMap(content, "status")
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range patchedStatusFields {
+		delete(status, field)
+		if value, ok := latestStatus[field]; ok {
+			status[field] = value
+		}
+	}
+	content["status"] = status
+
+	updated := reflect.New(reflect.TypeOf(latest).Elem()).Interface().(client.Object)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("rateLimitedStatusClient", func() {
+	var (
+		originInterval time.Duration
+		nodeConfig     *fec.SriovFecNodeConfig
+		fakeClient     client.Client
+		limited        client.Client
+	)
+
+	pfBbConfVersion := func() string {
+		nc := new(fec.SriovFecNodeConfig)
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(nodeConfig), nc)).To(Succeed())
+		return nc.Status.PfBbConfVersion
+	}
+
+	update := func(version string) {
+		nc := new(fec.SriovFecNodeConfig)
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(nodeConfig), nc)).To(Succeed())
+		nc.Status.PfBbConfVersion = version
+		Expect(limited.Status().Update(context.TODO(), nc)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		Expect(fec.AddToScheme(scheme.Scheme)).To(Succeed())
+		originInterval = statusUpdateInterval
+		statusUpdateInterval = 200 * time.Millisecond
+		nodeConfig = &fec.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: "ns"}}
+		fakeClient = fake.NewClientBuilder().WithObjects(nodeConfig).Build()
+		limited = newRateLimitedStatusClient(fakeClient, logrus.New())
+	})
+
+	AfterEach(func() {
+		statusUpdateInterval = originInterval
+	})
+
+	It("writes status updates requested within the interval once, with the latest status", func() {
+		update("v1")
+		Expect(pfBbConfVersion()).To(Equal("v1"))
+
+		update("v2")
+		update("v3")
+		Expect(pfBbConfVersion()).To(Equal("v1"))
+
+		Eventually(pfBbConfVersion).Should(Equal("v3"))
+		Consistently(pfBbConfVersion, 3*statusUpdateInterval).Should(Equal("v3"))
+	})
+
+	It("clears fields cleared by the latest coalesced status update", func() {
+		update("v1")
+		update("v2")
+		update("")
+		Eventually(pfBbConfVersion).Should(BeEmpty())
+	})
+
+	It("keeps status patched between the coalesced status update and its write", func() {
+		update("v1")
+		update("v2")
+
+		nc := new(fec.SriovFecNodeConfig)
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(nodeConfig), nc)).To(Succeed())
+		patched := nc.DeepCopy()
+		patched.Status.Operations = []fec.Operation{{PCIAddress: "0000:f7:00.0", Phase: OperationStarted}}
+		Expect(limited.Status().Patch(context.TODO(), patched, client.MergeFrom(nc))).To(Succeed())
+		nc = patched.DeepCopy()
+		patched.Status.Utilization = &fec.AcceleratorsUtilization{Percent: 42}
+		Expect(fakeClient.Status().Patch(context.TODO(), patched, client.MergeFrom(nc))).To(Succeed())
+
+		Eventually(pfBbConfVersion).Should(Equal("v2"))
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(nodeConfig), nc)).To(Succeed())
+		Expect(nc.Status.Operations).To(HaveLen(1))
+		Expect(nc.Status.Operations[0].Phase).To(Equal(OperationStarted))
+		Expect(nc.Status.Utilization).ToNot(BeNil())
+		Expect(nc.Status.Utilization.Percent).To(Equal(42))
+	})
+
+	It("writes status updates immediately when rate limiting is disabled", func() {
+		statusUpdateInterval = 0
+		update("v1")
+		update("v2")
+		Expect(pfBbConfVersion()).To(Equal("v2"))
+	})
+})
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
+)
+
+// setUnsupportedCombination reports combinations of accelerators, PF drivers and pf_bb_config version requested on the
+// node which are not listed in the support matrix in UnsupportedCombination condition. Configuration is applied anyway,
+// unsupported combinations are rejected at admission of ClusterConfigs.
+func setUnsupportedCombination(conditions *[]metav1.Condition, generation int64, combinations []supportmatrix.Combination, allowed bool) {
+	var unsupported []string
+	for _, combination := range combinations {
+		if err := supportmatrix.Check(combination); err != nil {
+			unsupported = append(unsupported, err.Error())
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               supportmatrix.ConditionUnsupportedCombination,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             supportmatrix.SupportedReason,
+	}
+	if len(unsupported) != 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = supportmatrix.UnsupportedReason
+		if allowed {
+			condition.Reason = supportmatrix.AllowedByAnnotationReason
+		}
+		condition.Message = strings.Join(unsupported, "; ")
+	}
+	meta.SetStatusCondition(conditions, condition)
+}
+
+// knownPfBbConfVersion returns pf_bb_config version reported by the daemon, or empty string if it isn't known
+func knownPfBbConfVersion(version string) string {
+	if version == "null" {
+		return ""
+	}
+	return version
+}
+
+// fecCombinations returns combinations requested by physical functions of the NodeConfig
+func fecCombinations(nc *fec.SriovFecNodeConfig) []supportmatrix.Combination {
+	var combinations []supportmatrix.Combination
+	for _, pf := range withSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers) {
+		for _, acc := range nc.Status.Inventory.SriovAccelerators {
+			if acc.PCIAddress == pf.PCIAddress {
+				combinations = append(combinations, supportmatrix.Combination{
+					Device:     supportedAccelerators.Devices[acc.DeviceID],
+					PFDriver:   pf.PFDriver,
+					PfBbConfig: knownPfBbConfVersion(nc.Status.PfBbConfVersion),
+				})
+			}
+		}
+	}
+	return combinations
+}
+
+// vrbCombinations returns combinations requested by physical functions of the NodeConfig
+func vrbCombinations(nc *vrbv1.SriovVrbNodeConfig) []supportmatrix.Combination {
+	var combinations []supportmatrix.Combination
+	for _, pf := range vrbWithSelectedPFDrivers(nc.Spec.PhysicalFunctions, nc.Status.SelectedPFDrivers) {
+		for _, acc := range nc.Status.Inventory.SriovAccelerators {
+			if acc.PCIAddress == pf.PCIAddress {
+				combinations = append(combinations, supportmatrix.Combination{
+					Device:     VrbsupportedAccelerators.Devices[acc.DeviceID],
+					PFDriver:   pf.PFDriver,
+					PfBbConfig: knownPfBbConfVersion(nc.Status.PfBbConfVersion),
+				})
+			}
+		}
+	}
+	return combinations
+}
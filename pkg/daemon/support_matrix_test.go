@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/intel/sriov-fec-operator/pkg/common/supportmatrix"
+)
+
+var _ = Describe("Support matrix", func() {
+	It("reports unsupported combinations in UnsupportedCombination condition", func() {
+		var conditions []metav1.Condition
+		supported := supportmatrix.Combination{Device: "ACC100", PFDriver: "vfio-pci", PfBbConfig: "24.03"}
+		unsupported := supportmatrix.Combination{Device: "ACC100", PFDriver: "vfio-pci", PfBbConfig: "21.11"}
+
+		setUnsupportedCombination(&conditions, 2, []supportmatrix.Combination{supported}, false)
+		condition := meta.FindStatusCondition(conditions, supportmatrix.ConditionUnsupportedCombination)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.ObservedGeneration).To(Equal(int64(2)))
+
+		setUnsupportedCombination(&conditions, 2, []supportmatrix.Combination{supported, unsupported}, false)
+		condition = meta.FindStatusCondition(conditions, supportmatrix.ConditionUnsupportedCombination)
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal(supportmatrix.UnsupportedReason))
+		Expect(condition.Message).To(ContainSubstring("pf_bb_config 21.11"))
+
+		setUnsupportedCombination(&conditions, 2, []supportmatrix.Combination{unsupported}, true)
+		condition = meta.FindStatusCondition(conditions, supportmatrix.ConditionUnsupportedCombination)
+		Expect(condition.Reason).To(Equal(supportmatrix.AllowedByAnnotationReason))
+	})
+
+	It("ignores unknown pf_bb_config version", func() {
+		Expect(knownPfBbConfVersion("null")).To(BeEmpty())
+		Expect(knownPfBbConfVersion("24.03")).To(Equal("24.03"))
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"strconv"
@@ -19,6 +20,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -29,15 +31,31 @@ const (
 	queueTypeLabel  = "queue_type"
 	engineIdLabel   = "engine_id"
 	statusLabel     = "status"
+
+	// utilizationReportThreshold is a minimal change of node's utilization (in percentage points) reported in NodeConfig's status,
+	// smaller changes are exposed by the metric only
+	utilizationReportThreshold = 5
 )
 
 type telemetryGatherer struct {
-	codeBlocksGauge, bytesGauge, engineGauge, vfStatusGauge, vfCountGauge *prometheus.GaugeVec
-	metricUpdates                                                         []func()
+	codeBlocksGauge, bytesGauge, engineGauge, vfStatusGauge, vfCountGauge, utilizationGauge *prometheus.GaugeVec
+	metricUpdates                                                                           []func()
+
+	// engineCounters keeps last value of code blocks counter of each engine, indexed by PF's PCI address
+	engineCounters map[string]map[string]float64
+	// busyEngines and engines are counted in current telemetry round, indexed by PF's PCI address
+	busyEngines, engines map[string]int
+	// utilization is a percentage of busy engines of PF calculated in the last telemetry round
+	utilization map[string]float64
 }
 
 func newTelemetryGatherer() *telemetryGatherer {
-	t := &telemetryGatherer{}
+	t := &telemetryGatherer{
+		engineCounters: map[string]map[string]float64{},
+		busyEngines:    map[string]int{},
+		engines:        map[string]int{},
+		utilization:    map[string]float64{},
+	}
 	t.codeBlocksGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "code_blocks_per_vfs",
 		Help: `number of code blocks processed by VF. 'pci_address' - represents unique BDF for VF. 'queue_type' - represents queue type for Vfs. Available values: '5GDL', '5GUL', 'FFT'`,
@@ -65,6 +83,11 @@ func newTelemetryGatherer() *telemetryGatherer {
 		Name: "vf_count",
 		Help: `describes number of configured VFs on card.'pci_address' - represents unique BDF for PF.'status' - represents current status of SriovFecNodeConfig. Available values: 'InProgress', 'Succeeded', 'Failed', 'Ignored'`,
 	}, []string{pciAddressLabel, statusLabel})
+
+	t.utilizationGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "accelerator_utilization_percent",
+		Help: `percentage of card's engines which processed code blocks since previous telemetry sample. 'pci_address' - represents unique BDF for PF`,
+	}, []string{pciAddressLabel})
 	return t
 }
 
@@ -80,9 +103,11 @@ func (t *telemetryGatherer) resetMetrics() {
 	t.bytesGauge.Reset()
 	t.codeBlocksGauge.Reset()
 	t.engineGauge.Reset()
+	t.utilizationGauge.Reset()
 }
 
 func (t *telemetryGatherer) updateMetrics() {
+	t.calculateUtilization()
 	t.resetMetrics()
 	for _, metricUpdate := range t.metricUpdates {
 		metricUpdate()
@@ -108,13 +133,58 @@ func (t *telemetryGatherer) updateBytes(opType, pciAddr string, value float64) {
 
 func (t *telemetryGatherer) updateEngines(opType, engineId, pciAddr string, value float64) {
 	t.queueMetric(t.engineGauge, map[string]string{queueTypeLabel: opType, engineIdLabel: engineId, pciAddressLabel: pciAddr}, value)
+	t.trackEngine(pciAddr, opType+"/"+engineId, value)
+}
+
+// trackEngine counts engine as busy if it processed any code block since previous telemetry round
+func (t *telemetryGatherer) trackEngine(pciAddr, engine string, value float64) {
+	counters, ok := t.engineCounters[pciAddr]
+	if !ok {
+		counters = map[string]float64{}
+		t.engineCounters[pciAddr] = counters
+	}
+	previous, known := counters[engine]
+	counters[engine] = value
+
+	t.engines[pciAddr]++
+	if known && value > previous {
+		t.busyEngines[pciAddr]++
+	}
+}
+
+// calculateUtilization closes telemetry round and calculates utilization of PFs which reported their engines
+func (t *telemetryGatherer) calculateUtilization() {
+	t.utilization = map[string]float64{}
+	for pciAddr, engines := range t.engines {
+		utilization := float64(t.busyEngines[pciAddr]) * 100 / float64(engines)
+		t.utilization[pciAddr] = utilization
+		t.queueMetric(t.utilizationGauge, map[string]string{pciAddressLabel: pciAddr}, utilization)
+	}
+	t.engines = map[string]int{}
+	t.busyEngines = map[string]int{}
+}
+
+// nodeUtilization returns average utilization of given PFs, false is returned if none of them reported telemetry
+func (t *telemetryGatherer) nodeUtilization(pciAddresses []string) (int, bool) {
+	var sum float64
+	var count int
+	for _, pciAddr := range pciAddresses {
+		if utilization, ok := t.utilization[pciAddr]; ok {
+			sum += utilization
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return int(math.Round(sum / float64(count))), true
 }
 
 func (t *telemetryGatherer) getGauges() []*prometheus.GaugeVec {
-	return []*prometheus.GaugeVec{t.codeBlocksGauge, t.bytesGauge, t.engineGauge, t.vfStatusGauge, t.vfCountGauge}
+	return []*prometheus.GaugeVec{t.codeBlocksGauge, t.bytesGauge, t.engineGauge, t.vfStatusGauge, t.vfCountGauge, t.utilizationGauge}
 }
 
-func StartTelemetryDaemon(mgr manager.Manager, nodeName string, ns string, directClient client.Client, log *logrus.Logger) {
+func StartTelemetryDaemon(ctx context.Context, mgr manager.Manager, nodeName string, ns string, directClient client.Client, log *logrus.Logger) {
 	reg := prometheus.NewRegistry()
 	telemetryGatherer := newTelemetryGatherer()
 	for _, collector := range telemetryGatherer.getGauges() {
@@ -129,7 +199,7 @@ func StartTelemetryDaemon(mgr manager.Manager, nodeName string, ns string, direc
 		os.Exit(1)
 	}
 	log.Info("registered Prometheus telemetry collectors and endpoint")
-	go getMetrics(nodeName, ns, directClient, log, telemetryGatherer)
+	go getMetrics(ctx, nodeName, ns, directClient, log, telemetryGatherer, newTelemetryPusher(reg, log))
 }
 
 func getFecMetrics(log *logrus.Logger, telemetryGatherer *telemetryGatherer, fecNodeConfig *fec.SriovFecNodeConfig) {
@@ -174,7 +244,7 @@ func getVrbMetrics(log *logrus.Logger, telemetryGatherer *telemetryGatherer, vrb
 	}
 }
 
-func getMetrics(nodeName, namespace string, c client.Client, log *logrus.Logger, telemetryGatherer *telemetryGatherer) {
+func getMetrics(ctx context.Context, nodeName, namespace string, c client.Client, log *logrus.Logger, telemetryGatherer *telemetryGatherer, pusher *telemetryPusher) {
 	sleepDuration := 15 * time.Second
 	sleepEnv := os.Getenv(utils.SRIOV_PREFIX + "METRIC_GATHER_INTERVAL")
 	if sleepEnv != "" {
@@ -187,12 +257,12 @@ func getMetrics(nodeName, namespace string, c client.Client, log *logrus.Logger,
 	}
 
 	utils.NewLogger().Info("metrics update loop will run every ", sleepDuration)
-	wait.Forever(func() {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
 		fecNodeConfig := &fec.SriovFecNodeConfig{}
 		vrbNodeConfig := &vrbv1.SriovVrbNodeConfig{}
 
-		fecNodeConfigErr := c.Get(context.Background(), client.ObjectKey{Name: nodeName, Namespace: namespace}, fecNodeConfig)
-		vrbNodeConfigErr := c.Get(context.Background(), client.ObjectKey{Name: nodeName, Namespace: namespace}, vrbNodeConfig)
+		fecNodeConfigErr := c.Get(ctx, client.ObjectKey{Name: nodeName, Namespace: namespace}, fecNodeConfig)
+		vrbNodeConfigErr := c.Get(ctx, client.ObjectKey{Name: nodeName, Namespace: namespace}, vrbNodeConfig)
 
 		if fecNodeConfigErr != nil && vrbNodeConfigErr != nil {
 			log.WithError(fecNodeConfigErr).WithField("nodeName", nodeName).WithField("namespace", namespace).Error("failed to get SriovFecNodeConfig to fetch telemetry")
@@ -209,9 +279,86 @@ func getMetrics(nodeName, namespace string, c client.Client, log *logrus.Logger,
 		}
 
 		telemetryGatherer.updateMetrics()
+
+		if fecNodeConfigErr == nil && len(fecNodeConfig.Spec.PhysicalFunctions) != 0 {
+			reportFecUtilization(ctx, c, log, telemetryGatherer, fecNodeConfig)
+		}
+
+		if vrbNodeConfigErr == nil && len(vrbNodeConfig.Spec.PhysicalFunctions) != 0 {
+			reportVrbUtilization(ctx, c, log, telemetryGatherer, vrbNodeConfig)
+		}
+
+		if pusher != nil {
+			if fecNodeConfigErr != nil {
+				fecNodeConfig = nil
+			}
+			if vrbNodeConfigErr != nil {
+				vrbNodeConfig = nil
+			}
+			pusher.pushIfDue(nodeName, fecNodeConfig, vrbNodeConfig)
+		}
 	}, sleepDuration)
 }
 
+// isUtilizationReportRequired returns true if utilization changed enough to be reported in NodeConfig's status
+func isUtilizationReportRequired(reported *int, current int) bool {
+	if reported == nil {
+		return true
+	}
+	diff := current - *reported
+	return diff >= utilizationReportThreshold || -diff >= utilizationReportThreshold
+}
+
+func reportFecUtilization(ctx context.Context, c client.Client, log *logrus.Logger, telemetryGatherer *telemetryGatherer, nc *fec.SriovFecNodeConfig) {
+	var pciAddresses []string
+	for _, acc := range nc.Status.Inventory.SriovAccelerators {
+		pciAddresses = append(pciAddresses, acc.PCIAddress)
+	}
+	utilization, ok := telemetryGatherer.nodeUtilization(pciAddresses)
+	if !ok {
+		return
+	}
+
+	var reported *int
+	if nc.Status.Utilization != nil {
+		reported = &nc.Status.Utilization.Percent
+	}
+	if !isUtilizationReportRequired(reported, utilization) {
+		return
+	}
+
+	patch := client.MergeFrom(nc.DeepCopy())
+	nc.Status.Utilization = &fec.AcceleratorsUtilization{Percent: utilization, LastUpdateTime: metav1.Now()}
+	if err := c.Status().Patch(ctx, nc, patch); err != nil {
+		log.WithError(err).Error("failed to report utilization in SriovFecNodeConfig")
+	}
+}
+
+func reportVrbUtilization(ctx context.Context, c client.Client, log *logrus.Logger, telemetryGatherer *telemetryGatherer, nc *vrbv1.SriovVrbNodeConfig) {
+	var pciAddresses []string
+	for _, acc := range nc.Status.Inventory.SriovAccelerators {
+		pciAddresses = append(pciAddresses, acc.PCIAddress)
+	}
+	utilization, ok := telemetryGatherer.nodeUtilization(pciAddresses)
+	if !ok {
+		return
+	}
+
+	var reported *int
+	if nc.Status.Utilization != nil {
+		reported = &nc.Status.Utilization.Percent
+	}
+	if !isUtilizationReportRequired(reported, utilization) {
+		return
+	}
+
+	patch := client.MergeFrom(nc.DeepCopy())
+	nc.Status.Utilization = &vrbv1.AcceleratorsUtilization{Percent: utilization, LastUpdateTime: metav1.Now()}
+	if err := c.Status().Patch(ctx, nc, patch); err != nil {
+		log.WithError(err).Error("failed to report utilization in SriovVrbNodeConfig")
+	}
+}
+
 func getTelemetry(pciAddr string, vfs []fec.VF, telemetryGatherer *telemetryGatherer, log *logrus.Logger) {
 	err := clearLog(pciAddr)
 	if err != nil {
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+var (
+	telemetryPushEndpointEnv = utils.SRIOV_PREFIX + "TELEMETRY_PUSH_ENDPOINT"
+	telemetryPushIntervalEnv = utils.SRIOV_PREFIX + "TELEMETRY_PUSH_INTERVAL"
+
+	// telemetryPushBackoff is used to retry a failed push before snapshots are kept for the next one
+	telemetryPushBackoff = wait.Backoff{Duration: time.Second, Factor: 2, Steps: 3}
+)
+
+const (
+	defaultTelemetryPushInterval = time.Minute
+	// maxBufferedTelemetrySnapshots limits snapshots kept while the endpoint is unreachable, the oldest ones are dropped
+	maxBufferedTelemetrySnapshots = 60
+	telemetryPushTimeout          = 10 * time.Second
+)
+
+// telemetrySnapshot is a compact state of node's accelerators pushed to the remote endpoint
+type telemetrySnapshot struct {
+	Node      string    `json:"node"`
+	Timestamp time.Time `json:"timestamp"`
+	// Configuration keeps reason of Configured condition of node's NodeConfigs, keyed by their kind
+	Configuration map[string]string `json:"configuration,omitempty"`
+	Metrics       []telemetrySample `json:"metrics,omitempty"`
+}
+
+type telemetrySample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+// telemetryPusher periodically POSTs telemetry snapshots as JSON array to the endpoint, for clusters without
+// Prometheus. Snapshots which cannot be delivered are buffered and sent along with the next ones.
+type telemetryPusher struct {
+	endpoint   string
+	interval   time.Duration
+	httpClient *http.Client
+	gatherer   prometheus.Gatherer
+	log        *logrus.Logger
+
+	buffered []telemetrySnapshot
+	lastPush time.Time
+}
+
+// newTelemetryPusher returns pusher configured with SRIOV_FEC_TELEMETRY_PUSH_ENDPOINT, nil if push is not enabled
+func newTelemetryPusher(gatherer prometheus.Gatherer, log *logrus.Logger) *telemetryPusher {
+	endpoint := os.Getenv(telemetryPushEndpointEnv)
+	if endpoint == "" {
+		return nil
+	}
+
+	interval := defaultTelemetryPushInterval
+	if value := os.Getenv(telemetryPushIntervalEnv); value != "" {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			log.WithField("value", value).Warnf("invalid %s, using default %v", telemetryPushIntervalEnv, defaultTelemetryPushInterval)
+		} else {
+			interval = d
+		}
+	}
+
+	log.WithField("endpoint", endpoint).WithField("interval", interval).Info("telemetry will be pushed to remote endpoint")
+	return &telemetryPusher{
+		endpoint:   endpoint,
+		interval:   interval,
+		httpClient: &http.Client{Timeout: telemetryPushTimeout},
+		gatherer:   gatherer,
+		log:        log,
+	}
+}
+
+// pushIfDue pushes snapshot of the node if the push interval elapsed since the last one
+func (p *telemetryPusher) pushIfDue(nodeName string, fecNodeConfig *fec.SriovFecNodeConfig, vrbNodeConfig *vrbv1.SriovVrbNodeConfig) {
+	if time.Since(p.lastPush) < p.interval {
+		return
+	}
+	p.lastPush = time.Now()
+
+	snapshot, err := p.snapshot(nodeName, fecNodeConfig, vrbNodeConfig)
+	if err != nil {
+		p.log.WithError(err).Error("failed to gather telemetry snapshot")
+		return
+	}
+	p.buffered = append(p.buffered, snapshot)
+	if len(p.buffered) > maxBufferedTelemetrySnapshots {
+		p.buffered = p.buffered[len(p.buffered)-maxBufferedTelemetrySnapshots:]
+	}
+
+	err = wait.ExponentialBackoff(telemetryPushBackoff, func() (bool, error) {
+		if err := p.post(p.buffered); err != nil {
+			p.log.WithError(err).WithField("endpoint", p.endpoint).Warn("failed to push telemetry")
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		p.log.WithField("buffered", len(p.buffered)).Error("telemetry not pushed, snapshots are kept for the next push")
+		return
+	}
+	p.buffered = nil
+}
+
+func (p *telemetryPusher) snapshot(nodeName string, fecNodeConfig *fec.SriovFecNodeConfig, vrbNodeConfig *vrbv1.SriovVrbNodeConfig) (telemetrySnapshot, error) {
+	snapshot := telemetrySnapshot{Node: nodeName, Timestamp: time.Now().UTC(), Configuration: map[string]string{}}
+	if fecNodeConfig != nil {
+		if condition := meta.FindStatusCondition(fecNodeConfig.Status.Conditions, ConditionConfigured); condition != nil {
+			snapshot.Configuration["SriovFecNodeConfig"] = condition.Reason
+		}
+	}
+	if vrbNodeConfig != nil {
+		if condition := meta.FindStatusCondition(vrbNodeConfig.Status.Conditions, ConditionConfigured); condition != nil {
+			snapshot.Configuration["SriovVrbNodeConfig"] = condition.Reason
+		}
+	}
+
+	families, err := p.gatherer.Gather()
+	if err != nil {
+		return snapshot, err
+	}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			sample := telemetrySample{Name: family.GetName(), Value: metric.GetGauge().GetValue(), Labels: map[string]string{}}
+			for _, label := range metric.GetLabel() {
+				sample.Labels[label.GetName()] = label.GetValue()
+			}
+			snapshot.Metrics = append(snapshot.Metrics, sample)
+		}
+	}
+	return snapshot, nil
+}
+
+func (p *telemetryPusher) post(snapshots []telemetrySnapshot) error {
+	body, err := json.Marshal(snapshots)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Post(p.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	fec "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+)
+
+var _ = Describe("telemetryPusher", func() {
+	var (
+		server        *httptest.Server
+		healthy       bool
+		received      [][]telemetrySnapshot
+		originBackoff wait.Backoff
+		pusher        *telemetryPusher
+		nodeConfig    *fec.SriovFecNodeConfig
+	)
+
+	BeforeEach(func() {
+		healthy, received = true, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !healthy {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var snapshots []telemetrySnapshot
+			Expect(json.NewDecoder(r.Body).Decode(&snapshots)).To(Succeed())
+			received = append(received, snapshots)
+		}))
+		originBackoff = telemetryPushBackoff
+		telemetryPushBackoff = wait.Backoff{Duration: time.Millisecond, Steps: 1}
+
+		reg := prometheus.NewRegistry()
+		gatherer := newTelemetryGatherer()
+		for _, collector := range gatherer.getGauges() {
+			reg.MustRegister(collector)
+		}
+		gatherer.updateVfCount("0000:14:00.0", "Succeeded", 2)
+		gatherer.updateMetrics()
+
+		Expect(os.Setenv(telemetryPushEndpointEnv, server.URL)).To(Succeed())
+		Expect(os.Setenv(telemetryPushIntervalEnv, "1ns")).To(Succeed())
+		pusher = newTelemetryPusher(reg, logrus.New())
+
+		nodeConfig = &fec.SriovFecNodeConfig{Status: fec.SriovFecNodeConfigStatus{Conditions: []metav1.Condition{
+			{Type: ConditionConfigured, Status: metav1.ConditionTrue, Reason: string(ConfigurationSucceeded)},
+		}}}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		telemetryPushBackoff = originBackoff
+		Expect(os.Unsetenv(telemetryPushEndpointEnv)).To(Succeed())
+		Expect(os.Unsetenv(telemetryPushIntervalEnv)).To(Succeed())
+	})
+
+	It("is not enabled without endpoint", func() {
+		Expect(os.Unsetenv(telemetryPushEndpointEnv)).To(Succeed())
+		Expect(newTelemetryPusher(prometheus.NewRegistry(), logrus.New())).To(BeNil())
+	})
+
+	It("pushes snapshot of the node", func() {
+		pusher.pushIfDue("node", nodeConfig, nil)
+
+		Expect(received).To(HaveLen(1))
+		Expect(received[0]).To(HaveLen(1))
+		snapshot := received[0][0]
+		Expect(snapshot.Node).To(Equal("node"))
+		Expect(snapshot.Configuration).To(Equal(map[string]string{"SriovFecNodeConfig": string(ConfigurationSucceeded)}))
+		Expect(snapshot.Metrics).To(ContainElement(telemetrySample{
+			Name:   "vf_count",
+			Labels: map[string]string{pciAddressLabel: "0000:14:00.0", statusLabel: "Succeeded"},
+			Value:  2,
+		}))
+	})
+
+	It("buffers snapshots while the endpoint is unavailable", func() {
+		healthy = false
+		pusher.pushIfDue("node", nodeConfig, nil)
+		pusher.pushIfDue("node", nodeConfig, nil)
+		Expect(received).To(BeEmpty())
+		Expect(pusher.buffered).To(HaveLen(2))
+
+		healthy = true
+		pusher.pushIfDue("node", nodeConfig, nil)
+		Expect(received).To(HaveLen(1))
+		Expect(received[0]).To(HaveLen(3))
+		Expect(pusher.buffered).To(BeEmpty())
+	})
+
+	It("drops the oldest snapshots when buffer is full", func() {
+		healthy = false
+		for i := 0; i < maxBufferedTelemetrySnapshots+5; i++ {
+			pusher.pushIfDue("node", nodeConfig, nil)
+		}
+		Expect(pusher.buffered).To(HaveLen(maxBufferedTelemetrySnapshots))
+	})
+
+	It("doesn't push before the interval elapses", func() {
+		pusher.interval = time.Hour
+		pusher.pushIfDue("node", nodeConfig, nil)
+		pusher.pushIfDue("node", nodeConfig, nil)
+		Expect(received).To(HaveLen(1))
+	})
+})
@@ -555,6 +555,38 @@ Fri Sep 16 10:42:33 2022:INFO:-  VF 3 RTE_BBDEV_DEV_CONFIGURED
 	})
 })
 
+var _ = Describe("Utilization", func() {
+	It("is calculated from engines which processed code blocks since previous round", func() {
+		tg := newTelemetryGatherer()
+		logger := utils.NewLogger()
+		parseEngines := func(values string) {
+			parseCounters("Fri Sep 13 10:49:25 2022:INFO:5GUL counters: Per Engine", "Tue Sep 13 10:49:25 2022:INFO:"+values, nil, "1111:00:00.0", tg, logger)
+			tg.updateMetrics()
+		}
+
+		parseEngines("10 10 10 10")
+		Expect(testutil.ToFloat64(tg.utilizationGauge.WithLabelValues("1111:00:00.0"))).To(BeZero())
+
+		parseEngines("15 10 11 10")
+		Expect(testutil.ToFloat64(tg.utilizationGauge.WithLabelValues("1111:00:00.0"))).To(Equal(float64(50)))
+
+		utilization, ok := tg.nodeUtilization([]string{"1111:00:00.0", "2222:00:00.0"})
+		Expect(ok).To(BeTrue())
+		Expect(utilization).To(Equal(50))
+
+		_, ok = tg.nodeUtilization([]string{"2222:00:00.0"})
+		Expect(ok).To(BeFalse())
+	})
+
+	It("is reported in status only when changed significantly", func() {
+		reported := 50
+		Expect(isUtilizationReportRequired(nil, 0)).To(BeTrue())
+		Expect(isUtilizationReportRequired(&reported, 53)).To(BeFalse())
+		Expect(isUtilizationReportRequired(&reported, 55)).To(BeTrue())
+		Expect(isUtilizationReportRequired(&reported, 45)).To(BeTrue())
+	})
+})
+
 type testHook struct {
 	expectedError        string
 	expectedErrorOccured bool
@@ -6,6 +6,7 @@ package daemon
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
 	"crypto/sha1"
 	"crypto/tls"
 	"crypto/x509"
@@ -21,6 +22,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -53,6 +56,23 @@ func requeueLater() (reconcile.Result, error) {
 	return reconcile.Result{RequeueAfter: resyncPeriod}, nil
 }
 
+// returns result indicating necessity of re-queuing Reconcile after sync interval requested by the NodeConfig or after
+// resyncPeriod, whichever is shorter, so the sync interval doesn't postpone verification of the node done every resync
+func requeueAfterSyncInterval(syncInterval *metav1.Duration) (reconcile.Result, error) {
+	if syncInterval != nil && syncInterval.Duration > 0 {
+		return reconcile.Result{RequeueAfter: min(syncInterval.Duration, resyncPeriod)}, nil
+	}
+	return requeueLater()
+}
+
+// patchAnnotations sets annotations of the object with a merge patch of its metadata only, so changes of the object made
+// in memory, e.g. of its spec, are not written
+func patchAnnotations(ctx context.Context, c client.Client, obj client.Object, annotations map[string]string) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	obj.SetAnnotations(annotations)
+	return c.Patch(ctx, obj, patch)
+}
+
 // returns result indicating necessity of re-queuing Reconcile(...) immediately; non-nil err will be logged by controller
 func requeueNowWithError(e error) (reconcile.Result, error) {
 	return reconcile.Result{Requeue: true}, e
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+// Package fecclient provides controller-runtime client of SriovFec and SriovVrb APIs for orchestrators managing FEC
+// accelerators through the operator. N3000 cards are configured with bbDevConfig.n3000 of SriovFecClusterConfig.
+package fecclient
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+// DefaultNamespace is the namespace the operator is installed to by default; ClusterConfigs and NodeConfigs are only
+// reconciled in the operator's namespace
+const DefaultNamespace = "vran-acceleration-operators"
+
+// AddToScheme adds SriovFec and SriovVrb APIs to the scheme
+func AddToScheme(s *runtime.Scheme) error {
+	for _, addToScheme := range []func(*runtime.Scheme) error{sriovfecv2.AddToScheme, vrbv1.AddToScheme} {
+		if err := addToScheme(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewScheme returns a scheme of Kubernetes built-in types, SriovFec and SriovVrb APIs
+func NewScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	utilruntime.Must(AddToScheme(s))
+	return s
+}
+
+// Client reads and writes SriovFec and SriovVrb objects in the operator's namespace
+type Client struct {
+	client.Client
+	Namespace string
+}
+
+// New returns Client of the operator installed in the namespace
+func New(config *rest.Config, namespace string) (*Client, error) {
+	c, err := client.New(config, client.Options{Scheme: NewScheme()})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: c, Namespace: namespace}, nil
+}
+
+// ListSriovFecClusterConfigs returns all SriovFecClusterConfigs of the operator
+func (c *Client) ListSriovFecClusterConfigs(ctx context.Context) ([]sriovfecv2.SriovFecClusterConfig, error) {
+	list := new(sriovfecv2.SriovFecClusterConfigList)
+	if err := c.List(ctx, list, client.InNamespace(c.Namespace)); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetSriovFecNodeConfig returns SriovFecNodeConfig of the node
+func (c *Client) GetSriovFecNodeConfig(ctx context.Context, nodeName string) (*sriovfecv2.SriovFecNodeConfig, error) {
+	nc := new(sriovfecv2.SriovFecNodeConfig)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: c.Namespace, Name: nodeName}, nc); err != nil {
+		return nil, err
+	}
+	return nc, nil
+}
+
+// ListSriovVrbClusterConfigs returns all SriovVrbClusterConfigs of the operator
+func (c *Client) ListSriovVrbClusterConfigs(ctx context.Context) ([]vrbv1.SriovVrbClusterConfig, error) {
+	list := new(vrbv1.SriovVrbClusterConfigList)
+	if err := c.List(ctx, list, client.InNamespace(c.Namespace)); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// GetSriovVrbNodeConfig returns SriovVrbNodeConfig of the node
+func (c *Client) GetSriovVrbNodeConfig(ctx context.Context, nodeName string) (*vrbv1.SriovVrbNodeConfig, error) {
+	nc := new(vrbv1.SriovVrbNodeConfig)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: c.Namespace, Name: nodeName}, nc); err != nil {
+		return nil, err
+	}
+	return nc, nil
+}
+
+// ApplySriovFecClusterConfig creates the ClusterConfig in the operator's namespace or updates spec of the existing one
+func (c *Client) ApplySriovFecClusterConfig(ctx context.Context, cc *sriovfecv2.SriovFecClusterConfig) error {
+	cc.Namespace = c.Namespace
+	current := new(sriovfecv2.SriovFecClusterConfig)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(cc), current); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return c.Create(ctx, cc)
+	}
+	current.Spec = cc.Spec
+	if err := c.Update(ctx, current); err != nil {
+		return err
+	}
+	current.DeepCopyInto(cc)
+	return nil
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package fecclient
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFecClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FecClient suite")
+}
+
+var _ = Describe("Client", func() {
+	var c *Client
+
+	BeforeEach(func() {
+		c = &Client{
+			Client: fake.NewClientBuilder().WithScheme(NewScheme()).WithObjects(
+				&sriovfecv2.SriovFecNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: DefaultNamespace}},
+				&vrbv1.SriovVrbNodeConfig{ObjectMeta: metav1.ObjectMeta{Name: "node", Namespace: DefaultNamespace}},
+				&sriovfecv2.SriovFecClusterConfig{ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "default"}},
+			).Build(),
+			Namespace: DefaultNamespace,
+		}
+	})
+
+	It("gets NodeConfigs of the node", func() {
+		Expect(c.GetSriovFecNodeConfig(context.TODO(), "node")).ToNot(BeNil())
+		Expect(c.GetSriovVrbNodeConfig(context.TODO(), "node")).ToNot(BeNil())
+		_, err := c.GetSriovFecNodeConfig(context.TODO(), "missing")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("creates ClusterConfig in operator's namespace and updates its spec", func() {
+		cc := &sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config"},
+			Spec:       sriovfecv2.SriovFecClusterConfigSpec{Priority: 1},
+		}
+		Expect(c.ApplySriovFecClusterConfig(context.TODO(), cc)).To(Succeed())
+
+		cc = &sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config"},
+			Spec:       sriovfecv2.SriovFecClusterConfigSpec{Priority: 2},
+		}
+		Expect(c.ApplySriovFecClusterConfig(context.TODO(), cc)).To(Succeed())
+
+		configs, err := c.ListSriovFecClusterConfigs(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configs).To(HaveLen(1))
+		Expect(configs[0].Namespace).To(Equal(DefaultNamespace))
+		Expect(configs[0].Spec.Priority).To(Equal(2))
+
+		vrbConfigs, err := c.ListSriovVrbClusterConfigs(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vrbConfigs).To(BeEmpty())
+	})
+})
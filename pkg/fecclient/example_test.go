@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package fecclient_test
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/fecclient"
+)
+
+// Configures ACC100 accelerators of nodes labeled as RAN nodes with a preset profile and reports whether
+// the configuration was applied to a node.
+func Example() {
+	c, err := fecclient.New(ctrl.GetConfigOrDie(), fecclient.DefaultNamespace)
+	if err != nil {
+		panic(err)
+	}
+
+	cc := &sriovfecv2.SriovFecClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "ran-acc100"},
+		Spec: sriovfecv2.SriovFecClusterConfigSpec{
+			Priority:            1,
+			NodeSelector:        map[string]string{"node-role.kubernetes.io/ran": ""},
+			AcceleratorSelector: sriovfecv2.AcceleratorSelector{DeviceID: "0d5c"},
+			PhysicalFunction: sriovfecv2.PhysicalFunctionConfig{
+				PFDriver:    "vfio-pci",
+				VFDriver:    "vfio-pci",
+				VFAmount:    16,
+				BBDevConfig: sriovfecv2.BBDevConfig{Profile: sriovfecv2.ProfileFlexRAN5GDefault},
+			},
+		},
+	}
+	if err := c.ApplySriovFecClusterConfig(context.TODO(), cc); err != nil {
+		panic(err)
+	}
+
+	nc, err := c.GetSriovFecNodeConfig(context.TODO(), "worker-0")
+	if err != nil {
+		panic(err)
+	}
+	if condition := nc.FindCondition("Configured"); condition != nil {
+		fmt.Println(condition.Reason, condition.Message)
+	}
+}
@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	controllers "github.com/intel/sriov-fec-operator/controllers/sriovfec"
+	vrbcontrollers "github.com/intel/sriov-fec-operator/controllers/sriovvrb"
+)
+
+// Result keeps objects rendered by the controllers from a snapshot of the cluster
+type Result struct {
+	SriovFecNodeConfigs    []sriovfecv2.SriovFecNodeConfig
+	SriovFecClusterConfigs []sriovfecv2.SriovFecClusterConfig
+	SriovVrbNodeConfigs    []vrbv1.SriovVrbNodeConfig
+	SriovVrbClusterConfigs []vrbv1.SriovVrbClusterConfig
+}
+
+// Load decodes objects from a stream of YAML or JSON documents, e.g. files collected by must-gather concatenated with
+// "---" separators; lists are expanded into their items. Objects of kinds unknown to the scheme are skipped.
+func Load(content []byte, scheme *runtime.Scheme, log *logrus.Logger) ([]client.Object, error) {
+	var objects []client.Object
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+	for {
+		u := new(unstructured.Unstructured)
+		if err := decoder.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+
+		var items []*unstructured.Unstructured
+		if u.IsList() {
+			err := u.EachListItem(func(o runtime.Object) error {
+				items = append(items, o.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			items = append(items, u)
+		}
+
+		for _, item := range items {
+			object, err := toTyped(item, scheme)
+			if err != nil {
+				return nil, err
+			}
+			if object == nil {
+				log.WithField("kind", item.GetKind()).WithField("name", item.GetName()).Warn("unknown kind - object is skipped")
+				continue
+			}
+			objects = append(objects, object)
+		}
+	}
+}
+
+// toTyped converts the object to its type registered in the scheme, nil is returned for unknown kinds
+func toTyped(u *unstructured.Unstructured, scheme *runtime.Scheme) (client.Object, error) {
+	gvk := u.GroupVersionKind()
+	if !scheme.Recognizes(gvk) {
+		return nil, nil
+	}
+	typed, err := scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, fmt.Errorf("failed to decode %s %s: %w", gvk.Kind, u.GetName(), err)
+	}
+	object, ok := typed.(client.Object)
+	if !ok {
+		return nil, nil
+	}
+	// versions of the recorded cluster are meaningless for the in-memory one
+	object.SetResourceVersion("")
+	object.SetManagedFields(nil)
+	return object, nil
+}
+
+// Run reconciles ClusterConfigs of the snapshot once, against an in-memory cluster keeping the objects, and returns
+// NodeConfigs and ClusterConfigs rendered by the controllers. Nothing runs on nodes, so NodeConfigs keep their recorded
+// status. NAMESPACE of the controllers has to be set to the namespace of the recorded operator.
+func Run(ctx context.Context, scheme *runtime.Scheme, objects []client.Object, log *logrus.Logger) (*Result, error) {
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: controllers.NAMESPACE, Name: "simulation"}}
+
+	fecReconciler := &controllers.SriovFecClusterConfigReconciler{Client: c, Log: log}
+	if _, err := fecReconciler.Reconcile(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to reconcile SriovFecClusterConfigs: %w", err)
+	}
+	vrbReconciler := &vrbcontrollers.SriovVrbClusterConfigReconciler{Client: c, Log: log}
+	if _, err := vrbReconciler.Reconcile(ctx, request); err != nil {
+		return nil, fmt.Errorf("failed to reconcile SriovVrbClusterConfigs: %w", err)
+	}
+
+	fecNodeConfigs := new(sriovfecv2.SriovFecNodeConfigList)
+	fecClusterConfigs := new(sriovfecv2.SriovFecClusterConfigList)
+	vrbNodeConfigs := new(vrbv1.SriovVrbNodeConfigList)
+	vrbClusterConfigs := new(vrbv1.SriovVrbClusterConfigList)
+	for _, list := range []client.ObjectList{fecNodeConfigs, fecClusterConfigs, vrbNodeConfigs, vrbClusterConfigs} {
+		if err := c.List(ctx, list, client.InNamespace(controllers.NAMESPACE)); err != nil {
+			return nil, err
+		}
+	}
+	return &Result{
+		SriovFecNodeConfigs:    fecNodeConfigs.Items,
+		SriovFecClusterConfigs: fecClusterConfigs.Items,
+		SriovVrbNodeConfigs:    vrbNodeConfigs.Items,
+		SriovVrbClusterConfigs: vrbClusterConfigs.Items,
+	}, nil
+}
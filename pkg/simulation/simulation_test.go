@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package simulation
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	controllers "github.com/intel/sriov-fec-operator/controllers/sriovfec"
+	vrbcontrollers "github.com/intel/sriov-fec-operator/controllers/sriovvrb"
+)
+
+const snapshot = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: edge
+    resourceVersion: "1234"
+    labels:
+      site: edge
+      fpga.intel.com/intel-accelerator-present: ""
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: core
+    labels:
+      site: core
+      fpga.intel.com/intel-accelerator-present: ""
+---
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: unknown
+  namespace: vran-acceleration-operators
+---
+apiVersion: sriovfec.intel.com/v2
+kind: SriovFecNodeConfig
+metadata:
+  name: edge
+  namespace: vran-acceleration-operators
+status:
+  inventory:
+    sriovAccelerators:
+    - deviceID: 0d5c
+      driver: vfio-pci
+      maxVirtualFunctions: 16
+      pciAddress: "0000:14:00.0"
+      vendorID: "8086"
+---
+apiVersion: sriovfec.intel.com/v2
+kind: SriovFecClusterConfig
+metadata:
+  name: config
+  namespace: vran-acceleration-operators
+spec:
+  nodeSelector:
+    site: edge
+  physicalFunction:
+    pfDriver: vfio-pci
+    vfDriver: vfio-pci
+    vfAmount: 2
+    bbDevConfig:
+      acc100:
+        pfMode: false
+        numVfBundles: 2
+        maxQueueSize: 1024
+        uplink4G: {numQueueGroups: 0, numAqsPerGroups: 16, aqDepthLog2: 4}
+        downlink4G: {numQueueGroups: 0, numAqsPerGroups: 16, aqDepthLog2: 4}
+        uplink5G: {numQueueGroups: 4, numAqsPerGroups: 16, aqDepthLog2: 4}
+        downlink5G: {numQueueGroups: 4, numAqsPerGroups: 16, aqDepthLog2: 4}
+`
+
+var _ = Describe("Simulation", func() {
+	var (
+		scheme *runtime.Scheme
+		log    *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+		utilruntime.Must(sriovfecv2.AddToScheme(scheme))
+		utilruntime.Must(vrbv1.AddToScheme(scheme))
+		log = logrus.New()
+		log.SetLevel(logrus.WarnLevel)
+		controllers.NAMESPACE = "vran-acceleration-operators"
+		vrbcontrollers.NAMESPACE = "vran-acceleration-operators"
+	})
+
+	It("loads objects of known kinds, expanding lists", func() {
+		objects, err := Load([]byte(snapshot), scheme, log)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(objects).To(HaveLen(4))
+		Expect(objects[0].GetName()).To(Equal("edge"))
+		Expect(objects[0].GetResourceVersion()).To(BeEmpty())
+		Expect(objects[2]).To(BeAssignableToTypeOf(&sriovfecv2.SriovFecNodeConfig{}))
+	})
+
+	It("fails on malformed documents", func() {
+		_, err := Load([]byte("kind: [Node"), scheme, log)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("renders NodeConfigs of the snapshot", func() {
+		objects, err := Load([]byte(snapshot), scheme, log)
+		Expect(err).ToNot(HaveOccurred())
+
+		result, err := Run(context.TODO(), scheme, objects, log)
+		Expect(err).ToNot(HaveOccurred())
+		// NodeConfigs are created by daemons, so the node without a recorded one has nothing rendered
+		Expect(result.SriovFecNodeConfigs).To(HaveLen(1))
+		nc := result.SriovFecNodeConfigs[0]
+		Expect(nc.Name).To(Equal("edge"))
+		Expect(nc.Spec.PhysicalFunctions).To(HaveLen(1))
+		Expect(nc.Spec.PhysicalFunctions[0].PCIAddress).To(Equal("0000:14:00.0"))
+		Expect(nc.Spec.PhysicalFunctions[0].VFAmount).To(Equal(2))
+		Expect(result.SriovFecClusterConfigs).To(HaveLen(1))
+		Expect(result.SriovVrbNodeConfigs).To(BeEmpty())
+	})
+})
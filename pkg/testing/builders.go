@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package testing
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+)
+
+// AcceleratorPresentLabel marks nodes considered by the operator
+const AcceleratorPresentLabel = "fpga.intel.com/intel-accelerator-present"
+
+// NewAcceleratedNode returns Node labeled as hosting an accelerator
+func NewAcceleratedNode(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				corev1.LabelHostname:    name,
+				AcceleratorPresentLabel: "",
+			},
+		},
+	}
+}
+
+// NewAccelerator returns Intel accelerator without any VF created
+func NewAccelerator(pciAddress, deviceID string, maxVFs int) sriovfecv2.SriovAccelerator {
+	return sriovfecv2.SriovAccelerator{
+		VendorID:   "8086",
+		DeviceID:   deviceID,
+		PCIAddress: pciAddress,
+		PFDriver:   utils.PCI_PF_STUB_DASH,
+		MaxVFs:     maxVFs,
+	}
+}
+
+// ClusterConfigBuilder builds SriovFecClusterConfigs, by default the one applying recommended ACC100 config
+// to every accelerator in the cluster
+type ClusterConfigBuilder struct {
+	cc sriovfecv2.SriovFecClusterConfig
+}
+
+func NewClusterConfig(name, namespace string) *ClusterConfigBuilder {
+	acc100 := sriovfecv2.RecommendedACC100BBDevConfig()
+	return &ClusterConfigBuilder{
+		cc: sriovfecv2.SriovFecClusterConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: sriovfecv2.SriovFecClusterConfigSpec{
+				PhysicalFunction: sriovfecv2.PhysicalFunctionConfig{
+					PFDriver:    utils.VFIO_PCI,
+					VFDriver:    utils.VFIO_PCI,
+					VFAmount:    acc100.NumVfBundles,
+					BBDevConfig: sriovfecv2.BBDevConfig{ACC100: acc100},
+				},
+			},
+		},
+	}
+}
+
+func (b *ClusterConfigBuilder) WithPriority(priority int) *ClusterConfigBuilder {
+	b.cc.Spec.Priority = priority
+	return b
+}
+
+func (b *ClusterConfigBuilder) WithNodeSelector(selector map[string]string) *ClusterConfigBuilder {
+	b.cc.Spec.NodeSelector = selector
+	return b
+}
+
+func (b *ClusterConfigBuilder) WithAcceleratorSelector(selector sriovfecv2.AcceleratorSelector) *ClusterConfigBuilder {
+	b.cc.Spec.AcceleratorSelector = selector
+	return b
+}
+
+func (b *ClusterConfigBuilder) WithDrivers(pfDriver, vfDriver string) *ClusterConfigBuilder {
+	b.cc.Spec.PhysicalFunction.PFDriver = pfDriver
+	b.cc.Spec.PhysicalFunction.VFDriver = vfDriver
+	return b
+}
+
+// WithBBDevConfig sets bbDevConfig together with vfAmount matching it
+func (b *ClusterConfigBuilder) WithBBDevConfig(config sriovfecv2.BBDevConfig, vfAmount int) *ClusterConfigBuilder {
+	b.cc.Spec.PhysicalFunction.BBDevConfig = config
+	b.cc.Spec.PhysicalFunction.VFAmount = vfAmount
+	return b
+}
+
+func (b *ClusterConfigBuilder) WithDrainSkip(drainSkip bool) *ClusterConfigBuilder {
+	b.cc.Spec.DrainSkip = &drainSkip
+	return b
+}
+
+// Build returns a copy of built SriovFecClusterConfig, so builder can be reused
+func (b *ClusterConfigBuilder) Build() *sriovfecv2.SriovFecClusterConfig {
+	return b.cc.DeepCopy()
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+/*
+Package testing provides a harness for integration tests of code using SriovFec and SriovVrb APIs.
+
+Environment starts envtest control plane with the operator's CRDs (generated by `make manifests`),
+FakeNodeDaemon imitates sriov-fec daemon of a node and builders create test objects:
+
+	env := testing.NewEnvironment("vran-acceleration-operators")
+	if err := env.Start(); err != nil {
+		...
+	}
+	defer env.Stop()
+
+	node := &testing.FakeNodeDaemon{
+		Client:    env.Client,
+		NodeName:  "node1",
+		Namespace: env.Namespace,
+		Inventory: sriovfecv2.NodeInventory{
+			SriovAccelerators: []sriovfecv2.SriovAccelerator{testing.NewAccelerator("0000:af:00.0", "0d5c", 16)},
+		},
+	}
+	_ = node.Register(ctx)
+	_ = env.Client.Create(ctx, testing.NewClusterConfig("config", env.Namespace).WithPriority(1).Build())
+	// ...run reconciler under test, then let the node "apply" propagated configuration
+	nc, _ := node.Sync(ctx)
+*/
+package testing
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package testing
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	vrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+)
+
+// Environment is a test control plane (etcd + kube-apiserver) with operator's CRDs installed
+type Environment struct {
+	// TestEnv can be customized before Start, e.g. to install webhooks
+	TestEnv *envtest.Environment
+	// Namespace is created on Start unless it already exists
+	Namespace string
+
+	// Config of the test control plane, available after Start
+	Config *rest.Config
+	// Client is a direct (not cached) client of the test control plane, available after Start
+	Client client.Client
+	// Scheme contains client-go, SriovFec and SriovVrb types
+	Scheme *k8sruntime.Scheme
+}
+
+// NewEnvironment returns Environment using CRDs from DefaultCRDDirectoryPath
+func NewEnvironment(namespace string) *Environment {
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sriovfecv2.AddToScheme(scheme))
+	utilruntime.Must(vrbv1.AddToScheme(scheme))
+
+	return &Environment{
+		TestEnv: &envtest.Environment{
+			CRDDirectoryPaths:     []string{DefaultCRDDirectoryPath()},
+			ErrorIfCRDPathMissing: true,
+		},
+		Namespace: namespace,
+		Scheme:    scheme,
+	}
+}
+
+// DefaultCRDDirectoryPath returns path of the CRDs generated by `make manifests` in this module
+func DefaultCRDDirectoryPath() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "crd", "bases")
+}
+
+// Start starts the test control plane, creates the client and the namespace
+func (e *Environment) Start() error {
+	e.TestEnv.Scheme = e.Scheme
+	cfg, err := e.TestEnv.Start()
+	if err != nil {
+		return err
+	}
+	e.Config = cfg
+
+	if e.Client, err = client.New(cfg, client.Options{Scheme: e.Scheme}); err != nil {
+		return err
+	}
+
+	if e.Namespace == "" {
+		return nil
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: e.Namespace}}
+	if err := e.Client.Create(context.TODO(), ns); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// Stop stops the test control plane
+func (e *Environment) Stop() error {
+	return e.TestEnv.Stop()
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/daemon"
+)
+
+// FakeNodeDaemon imitates sriov-fec daemon of a single node without touching any hardware.
+// It exposes Inventory in SriovFecNodeConfig's status and reports requested configuration as applied.
+type FakeNodeDaemon struct {
+	Client    client.Client
+	NodeName  string
+	Namespace string
+	// Inventory is the hardware of the node, VFs are created by Sync according to requested spec
+	Inventory sriovfecv2.NodeInventory
+}
+
+// Register creates accelerated Node and SriovFecNodeConfig exposing the inventory, as daemon does on startup
+func (d *FakeNodeDaemon) Register(ctx context.Context) error {
+	if err := d.Client.Create(ctx, NewAcceleratedNode(d.NodeName)); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	nc := &sriovfecv2.SriovFecNodeConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: d.NodeName, Namespace: d.Namespace},
+		Spec:       sriovfecv2.SriovFecNodeConfigSpec{PhysicalFunctions: []sriovfecv2.PhysicalFunctionConfigExt{}},
+	}
+	if err := d.Client.Create(ctx, nc); err != nil {
+		return err
+	}
+
+	meta.SetStatusCondition(&nc.Status.Conditions, metav1.Condition{
+		Type:    daemon.ConditionConfigured,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(daemon.ConfigurationNotRequested),
+		Message: "Inventory up to date",
+	})
+	nc.Status.Inventory = *d.Inventory.DeepCopy()
+	return d.Client.Status().Update(ctx, nc)
+}
+
+// Sync applies current spec of SriovFecNodeConfig to the inventory and reports it as succeeded
+func (d *FakeNodeDaemon) Sync(ctx context.Context) (*sriovfecv2.SriovFecNodeConfig, error) {
+	nc := new(sriovfecv2.SriovFecNodeConfig)
+	if err := d.Client.Get(ctx, client.ObjectKey{Name: d.NodeName, Namespace: d.Namespace}, nc); err != nil {
+		return nil, err
+	}
+
+	for i := range d.Inventory.SriovAccelerators {
+		acc := &d.Inventory.SriovAccelerators[i]
+		acc.VFs = nil
+		for _, pf := range nc.Spec.PhysicalFunctions {
+			if pf.PCIAddress != acc.PCIAddress {
+				continue
+			}
+			acc.PFDriver = pf.PFDriver
+			for vf := 0; vf < pf.VFAmount; vf++ {
+				address, err := vfPCIAddress(acc.PCIAddress, vf)
+				if err != nil {
+					return nil, err
+				}
+				acc.VFs = append(acc.VFs, sriovfecv2.VF{PCIAddress: address, Driver: pf.VFDriverFor(vf), DeviceID: acc.DeviceID})
+			}
+		}
+	}
+
+	meta.SetStatusCondition(&nc.Status.Conditions, metav1.Condition{
+		Type:               daemon.ConditionConfigured,
+		Status:             metav1.ConditionTrue,
+		Reason:             string(daemon.ConfigurationSucceeded),
+		Message:            "Configured successfully",
+		ObservedGeneration: nc.GetGeneration(),
+	})
+	nc.Status.Inventory = *d.Inventory.DeepCopy()
+	if err := d.Client.Status().Update(ctx, nc); err != nil {
+		return nil, err
+	}
+	return nc, nil
+}
+
+// vfPCIAddress returns address of VF as it is typically enumerated: on the bus following PF's one
+func vfPCIAddress(pfPCIAddress string, vf int) (string, error) {
+	parts := strings.Split(pfPCIAddress, ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid PCI address %s", pfPCIAddress)
+	}
+	bus, err := strconv.ParseUint(parts[1], 16, 8)
+	if err != nil {
+		return "", fmt.Errorf("invalid PCI address %s: %w", pfPCIAddress, err)
+	}
+	return fmt.Sprintf("%s:%02x:%02x.%d", parts[0], bus+1, vf/8, vf%8), nil
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package testing
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	"github.com/intel/sriov-fec-operator/pkg/daemon"
+)
+
+var _ = Describe("FakeNodeDaemon", func() {
+	var (
+		c    client.Client
+		node *FakeNodeDaemon
+	)
+
+	BeforeEach(func() {
+		c = fake.NewClientBuilder().WithScheme(NewEnvironment("").Scheme).Build()
+		node = &FakeNodeDaemon{
+			Client:    c,
+			NodeName:  "node1",
+			Namespace: "default",
+			Inventory: sriovfecv2.NodeInventory{
+				SriovAccelerators: []sriovfecv2.SriovAccelerator{
+					NewAccelerator("0000:af:00.0", "0d5c", 16),
+					NewAccelerator("0000:b1:00.0", "0d5c", 16),
+				},
+			},
+		}
+		Expect(node.Register(context.TODO())).To(Succeed())
+	})
+
+	It("registers accelerated node exposing its inventory", func() {
+		Expect(c.Get(context.TODO(), client.ObjectKey{Name: "node1"}, new(corev1.Node))).To(Succeed())
+
+		nc := new(sriovfecv2.SriovFecNodeConfig)
+		Expect(c.Get(context.TODO(), client.ObjectKey{Name: "node1", Namespace: "default"}, nc)).To(Succeed())
+		Expect(nc.Status.Inventory.SriovAccelerators).To(HaveLen(2))
+	})
+
+	It("applies requested spec", func() {
+		nc := new(sriovfecv2.SriovFecNodeConfig)
+		Expect(c.Get(context.TODO(), client.ObjectKey{Name: "node1", Namespace: "default"}, nc)).To(Succeed())
+		pf := NewClusterConfig("config", "default").Build().Spec.PhysicalFunction
+		nc.Spec.PhysicalFunctions = []sriovfecv2.PhysicalFunctionConfigExt{{
+			PCIAddress:        "0000:af:00.0",
+			PFDriver:          pf.PFDriver,
+			VFDriver:          pf.VFDriver,
+			VFAmount:          pf.VFAmount,
+			VFDriverOverrides: []sriovfecv2.VFDriverOverride{{First: 8, Last: 15, Driver: utils.PCI_PF_STUB_DASH}},
+			BBDevConfig:       pf.BBDevConfig,
+		}}
+		Expect(c.Update(context.TODO(), nc)).To(Succeed())
+
+		nc, err := node.Sync(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(nc.FindCondition(daemon.ConditionConfigured).Reason).To(Equal(string(daemon.ConfigurationSucceeded)))
+		configured := nc.Status.Inventory.SriovAccelerators[0]
+		Expect(configured.PFDriver).To(Equal(utils.VFIO_PCI))
+		Expect(configured.VFs).To(HaveLen(16))
+		Expect(configured.VFs[0]).To(Equal(sriovfecv2.VF{PCIAddress: "0000:b0:00.0", Driver: utils.VFIO_PCI, DeviceID: "0d5c"}))
+		Expect(configured.VFs[15]).To(Equal(sriovfecv2.VF{PCIAddress: "0000:b0:01.7", Driver: utils.PCI_PF_STUB_DASH, DeviceID: "0d5c"}))
+		Expect(nc.Status.Inventory.SriovAccelerators[1].VFs).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ClusterConfigBuilder", func() {
+	It("builds valid SriovFecClusterConfig", func() {
+		cc := NewClusterConfig("config", "default").
+			WithPriority(2).
+			WithNodeSelector(map[string]string{corev1.LabelHostname: "node1"}).
+			WithAcceleratorSelector(sriovfecv2.AcceleratorSelector{PCIAddress: "0000:af:00.0"}).
+			WithDrainSkip(true).
+			Build()
+
+		Expect(cc.ValidateCreate()).To(Succeed())
+		Expect(cc.Spec.Priority).To(Equal(2))
+		Expect(*cc.Spec.DrainSkip).To(BeTrue())
+	})
+})
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2020-2024 Intel Corporation
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	sriovfecv2 "github.com/intel/sriov-fec-operator/api/sriovfec/v2"
+	sriovvrbv1 "github.com/intel/sriov-fec-operator/api/sriovvrb/v1"
+	controllers "github.com/intel/sriov-fec-operator/controllers/sriovfec"
+	vrbcontrollers "github.com/intel/sriov-fec-operator/controllers/sriovvrb"
+	"github.com/intel/sriov-fec-operator/pkg/common/utils"
+	"github.com/intel/sriov-fec-operator/pkg/simulation"
+)
+
+// simulateCommand is a subcommand of the manager rendering NodeConfigs from a recorded snapshot of the cluster
+const simulateCommand = "simulate"
+
+// renderedObject is an object printed by the simulation, only the part set by the controllers is kept
+type renderedObject struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	Spec   interface{} `json:"spec,omitempty"`
+	Status interface{} `json:"status,omitempty"`
+}
+
+// runSimulate reconciles ClusterConfigs of a snapshot of the cluster, e.g. collected by must-gather, without a live
+// cluster and prints specs of rendered NodeConfigs and statuses of ClusterConfigs; it returns exit code of the command
+func runSimulate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet(simulateCommand, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	file := flags.String("f", "", "File with Nodes, ClusterConfigs, NodeConfigs and other objects of the operator, - reads it from stdin")
+	namespace := flags.String("n", "", "Namespace of the operator, defaults to namespace of the recorded configs or SRIOV_FEC_NAMESPACE")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: manager %s -f <file> [-n <namespace>]\n", simulateCommand)
+		fmt.Fprintln(stderr, "Prints NodeConfigs the operator would render from the recorded objects, no cluster is needed.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		flags.Usage()
+		return 2
+	}
+
+	var content []byte
+	var err error
+	if *file == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read %s: %v\n", *file, err)
+		return 1
+	}
+
+	log := utils.NewLogger()
+	log.SetOutput(stderr)
+	log.SetLevel(logrus.WarnLevel)
+
+	objects, err := simulation.Load(content, scheme, log)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to parse %s: %v\n", *file, err)
+		return 1
+	}
+
+	ns := firstNonEmpty(*namespace, recordedNamespace(objects), os.Getenv("SRIOV_FEC_NAMESPACE"))
+	if ns == "" {
+		fmt.Fprintln(stderr, "namespace of the operator is not set, use -n flag")
+		return 2
+	}
+	controllers.NAMESPACE = ns
+	vrbcontrollers.NAMESPACE = ns
+
+	result, err := simulation.Run(context.Background(), scheme, objects, log)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to simulate %s: %v\n", *file, err)
+		return 1
+	}
+
+	var rendered []renderedObject
+	for _, nc := range result.SriovFecNodeConfigs {
+		rendered = append(rendered, renderedObject{Kind: "SriovFecNodeConfig", Name: nc.Name, Spec: nc.Spec})
+	}
+	for _, nc := range result.SriovVrbNodeConfigs {
+		rendered = append(rendered, renderedObject{Kind: "SriovVrbNodeConfig", Name: nc.Name, Spec: nc.Spec})
+	}
+	for _, cc := range result.SriovFecClusterConfigs {
+		rendered = append(rendered, renderedObject{Kind: "SriovFecClusterConfig", Name: cc.Name, Status: cc.Status})
+	}
+	for _, cc := range result.SriovVrbClusterConfigs {
+		rendered = append(rendered, renderedObject{Kind: "SriovVrbClusterConfig", Name: cc.Name, Status: cc.Status})
+	}
+	for i, object := range rendered {
+		out, err := yaml.Marshal(object)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to print %s %s: %v\n", object.Kind, object.Name, err)
+			return 1
+		}
+		if i > 0 {
+			fmt.Fprintln(stdout, "---")
+		}
+		fmt.Fprint(stdout, string(out))
+	}
+	return 0
+}
+
+// recordedNamespace returns namespace of the first ClusterConfig or NodeConfig of the snapshot
+func recordedNamespace(objects []client.Object) string {
+	for _, object := range objects {
+		switch object.(type) {
+		case *sriovfecv2.SriovFecClusterConfig, *sriovfecv2.SriovFecNodeConfig,
+			*sriovvrbv1.SriovVrbClusterConfig, *sriovvrbv1.SriovVrbNodeConfig:
+			return object.GetNamespace()
+		}
+	}
+	return ""
+}